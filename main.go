@@ -3,48 +3,139 @@ package main
 import (
 	"context"
 	"fmt"
+	"net"
 	"net/http"
+	"strings"
+	"time"
 
 	"github.com/shivanshkc/ledgerkeep/src/configs"
 	"github.com/shivanshkc/ledgerkeep/src/database"
 	"github.com/shivanshkc/ledgerkeep/src/handlers"
 	"github.com/shivanshkc/ledgerkeep/src/logger"
 	"github.com/shivanshkc/ledgerkeep/src/middlewares"
+	"github.com/shivanshkc/ledgerkeep/src/oidc"
+	"github.com/shivanshkc/ledgerkeep/src/scheduler"
 
 	"github.com/gorilla/mux"
-	"go.mongodb.org/mongo-driver/bson"
-	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const (
+	// authModeBasic is the auth mode with the passwords of the users.
+	authModeBasic = "basic"
+	// authModeOIDC is the auth mode with the access tokens of an OpenID Connect issuer.
+	authModeOIDC = "oidc"
 )
 
 func main() {
 	conf := configs.Get()
 	log := logger.Get()
 
-	// Creating database indexes. This also initiates a connection with the database upon application startup.
-	go func() {
-		indexData := []mongo.IndexModel{
-			{Keys: bson.D{{Key: "account_id", Value: 1}}}, // Ascending B-tree index on "account_id".
-			{Keys: bson.D{{Key: "notes", Value: "text"}}}, // Text index on "notes".
-		}
+	// Initiating the storage backend upon application startup.
+	repos := database.GetRepositories()
 
-		err := database.CreateIndexOnTransactionField(context.Background(), indexData)
-		if err != nil {
-			panic(err)
-		}
-	}()
+	// Starting the scheduler of the recurring transactions.
+	if conf.Recurring.IntervalSec > 0 {
+		interval := time.Duration(conf.Recurring.IntervalSec) * time.Second
+		scheduler.New(repos.Users, repos.Recurring, repos.Rules, interval).Start(context.Background())
+	}
 
 	log.Info(context.Background(),
 		&logger.Entry{Payload: fmt.Sprintf("Server listening at: %s", conf.HTTPServer.Addr)})
 
 	// Starting the HTTP server.
-	if err := http.ListenAndServe(conf.HTTPServer.Addr, getHandler()); err != nil {
+	if err := http.ListenAndServe(conf.HTTPServer.Addr, getHandler(repos, getOIDCVerifier())); err != nil {
 		log.Error(context.Background(),
 			&logger.Entry{Payload: fmt.Errorf("failed to start http server: %w", err)})
 	}
 }
 
-func getHandler() http.Handler {
+// getOIDCVerifier provides the verifier of the access tokens of the configured OIDC issuer.
+// It returns nil in the basic auth mode. Panic is allowed here because auth is crucial to the application.
+func getOIDCVerifier() *oidc.Verifier {
+	conf := configs.Get()
+
+	switch conf.Auth.Mode {
+	// Basic auth is the default for backward compatibility with configs that do not specify a mode.
+	case authModeBasic, "":
+		return nil
+	case authModeOIDC:
+		keyRefreshInterval := time.Duration(conf.Auth.OIDC.KeyRefreshSec) * time.Second
+		return oidc.NewVerifier(conf.Auth.OIDC.Issuer, conf.Auth.OIDC.Audience, keyRefreshInterval)
+	default:
+		panic(fmt.Errorf("unknown auth mode: %s", conf.Auth.Mode))
+	}
+}
+
+// getRateLimiter provides the RateLimiter middleware with the configured limits.
+// Panic is allowed here because invalid lockouts would silently disable the protection against brute force attacks.
+func getRateLimiter() func(http.Handler) http.Handler {
+	conf := configs.Get()
+
+	lockout := conf.RateLimit.Lockout
+	if lockout.MaxFailures < 0 {
+		panic(fmt.Errorf("invalid lockout max_failures: %d", lockout.MaxFailures))
+	}
+	if lockout.MaxFailures > 0 && (lockout.BaseSec <= 0 || lockout.MaxSec < lockout.BaseSec) {
+		panic(fmt.Errorf("invalid lockout durations, base_sec should be positive and max_sec should be at least "+
+			"base_sec, got: base_sec %d, max_sec %d", lockout.BaseSec, lockout.MaxSec))
+	}
+
+	groups := make([]middlewares.RouteGroupLimits, len(conf.RateLimit.Groups))
+	for idx, group := range conf.RateLimit.Groups {
+		groups[idx] = middlewares.RouteGroupLimits{
+			Name:       group.Name,
+			PathPrefix: group.PathPrefix,
+			PerIP: middlewares.BucketLimit{
+				RequestsPerSec: group.PerIP.RequestsPerSec,
+				Burst:          group.PerIP.Burst,
+			},
+			PerCredential: middlewares.BucketLimit{
+				RequestsPerSec: group.PerCredential.RequestsPerSec,
+				Burst:          group.PerCredential.Burst,
+			},
+		}
+	}
+
+	trustedProxies := make([]*net.IPNet, len(conf.RateLimit.TrustedProxies))
+	for idx, proxy := range conf.RateLimit.TrustedProxies {
+		network, err := parseNetwork(proxy)
+		if err != nil {
+			panic(fmt.Errorf("invalid trusted proxy: %w", err))
+		}
+		trustedProxies[idx] = network
+	}
+
+	return middlewares.RateLimiter(groups, middlewares.LockoutPolicy{
+		MaxFailures:  lockout.MaxFailures,
+		BaseDuration: time.Duration(lockout.BaseSec) * time.Second,
+		MaxDuration:  time.Duration(lockout.MaxSec) * time.Second,
+	}, trustedProxies)
+}
+
+// parseNetwork parses a CIDR range, like "10.0.0.0/8", or a single IP address, which is a range of its own.
+func parseNetwork(value string) (*net.IPNet, error) {
+	if strings.Contains(value, "/") {
+		_, network, err := net.ParseCIDR(value)
+		if err != nil {
+			return nil, fmt.Errorf("error in net.ParseCIDR call: %w", err)
+		}
+		return network, nil
+	}
+
+	ip := net.ParseIP(value)
+	if ip == nil {
+		return nil, fmt.Errorf("invalid IP address: %s", value)
+	}
+	if ipv4 := ip.To4(); ipv4 != nil {
+		return &net.IPNet{IP: ipv4, Mask: net.CIDRMask(32, 32)}, nil
+	}
+	return &net.IPNet{IP: ip, Mask: net.CIDRMask(128, 128)}, nil
+}
+
+// getHandler provides the router of the application. The OIDC verifier is nil in the basic auth mode.
+func getHandler(repos *database.Repositories, verifier *oidc.Verifier) http.Handler {
 	router := mux.NewRouter()
+	handler := handlers.NewHandler(repos)
 
 	// Attaching global middlewares.
 	router.Use(middlewares.Recovery)
@@ -52,43 +143,172 @@ func getHandler() http.Handler {
 	router.Use(middlewares.AccessLogger)
 	router.Use(middlewares.CORS)
 
+	// Rate limiting middleware, which must see the responses of the Auth middleware.
+	router.Use(getRateLimiter())
 	// Auth middleware.
-	router.Use(middlewares.Auth)
+	router.Use(middlewares.Auth(repos.Users, repos.Tokens, verifier))
+	// Permissions middleware, which selects the ledger of the request.
+	router.Use(middlewares.Permissions(repos.Members))
 
-	router.HandleFunc("/api", handlers.BasicHandler).
+	router.HandleFunc("/api", handler.BasicHandler).
 		Methods(http.MethodGet, http.MethodOptions)
 
-	router.HandleFunc("/api/accounts", handlers.CreateAccountHandler).
+	router.HandleFunc("/api/users", handler.CreateUserHandler).
 		Methods(http.MethodPost, http.MethodOptions)
 
-	router.HandleFunc("/api/accounts", handlers.ListAccountsHandler).
+	router.HandleFunc("/api/users", handler.ListUsersHandler).
 		Methods(http.MethodGet, http.MethodOptions)
 
-	router.HandleFunc("/api/accounts/{account_id}", handlers.UpdateAccountHandler).
+	router.HandleFunc("/api/users/me", handler.GetCurrentUserHandler).
+		Methods(http.MethodGet, http.MethodOptions)
+
+	router.HandleFunc("/api/users/me", handler.UpdateCurrentUserHandler).
 		Methods(http.MethodPatch, http.MethodOptions)
 
-	router.HandleFunc("/api/accounts/{account_id}", handlers.DeleteAccountHandler).
+	router.HandleFunc("/api/tokens", handler.CreateTokenHandler).
+		Methods(http.MethodPost, http.MethodOptions)
+
+	router.HandleFunc("/api/tokens", handler.ListTokensHandler).
+		Methods(http.MethodGet, http.MethodOptions)
+
+	router.HandleFunc("/api/tokens/{token_id}", handler.DeleteTokenHandler).
 		Methods(http.MethodDelete, http.MethodOptions)
 
-	router.HandleFunc("/api/transactions", handlers.CreateTransactionHandler).
+	router.HandleFunc("/api/ledgers", handler.ListLedgersHandler).
+		Methods(http.MethodGet, http.MethodOptions)
+
+	router.HandleFunc("/api/members", handler.ListMembersHandler).
+		Methods(http.MethodGet, http.MethodOptions)
+
+	router.HandleFunc("/api/members", handler.CreateMemberHandler).
 		Methods(http.MethodPost, http.MethodOptions)
 
-	router.HandleFunc("/api/transactions/{transaction_id}", handlers.GetTransactionHandler).
+	router.HandleFunc("/api/members/{user_id}", handler.DeleteMemberHandler).
+		Methods(http.MethodDelete, http.MethodOptions)
+
+	router.HandleFunc("/api/accounts", handler.CreateAccountHandler).
+		Methods(http.MethodPost, http.MethodOptions)
+
+	router.HandleFunc("/api/accounts", handler.ListAccountsHandler).
+		Methods(http.MethodGet, http.MethodOptions)
+
+	router.HandleFunc("/api/accounts/{account_id}", handler.UpdateAccountHandler).
+		Methods(http.MethodPatch, http.MethodOptions)
+
+	router.HandleFunc("/api/accounts/{account_id}", handler.DeleteAccountHandler).
+		Methods(http.MethodDelete, http.MethodOptions)
+
+	router.HandleFunc("/api/transactions", handler.CreateTransactionHandler).
+		Methods(http.MethodPost, http.MethodOptions)
+
+	// This route is registered before the transaction ID routes, so that "duplicates" is not taken for an ID.
+	router.HandleFunc("/api/transactions/duplicates", handler.ListDuplicateTransactionsHandler).
 		Methods(http.MethodGet, http.MethodOptions)
 
-	router.HandleFunc("/api/transactions", handlers.ListTransactionsHandler).
+	router.HandleFunc("/api/transactions/{transaction_id}", handler.GetTransactionHandler).
 		Methods(http.MethodGet, http.MethodOptions)
 
-	router.HandleFunc("/api/transactions/{transaction_id}", handlers.UpdateTransactionHandler).
+	router.HandleFunc("/api/transactions", handler.ListTransactionsHandler).
+		Methods(http.MethodGet, http.MethodOptions)
+
+	router.HandleFunc("/api/transactions/{transaction_id}", handler.UpdateTransactionHandler).
 		Methods(http.MethodPatch, http.MethodOptions)
 
-	router.HandleFunc("/api/transactions/{transaction_id}", handlers.DeleteTransactionHandler).
+	router.HandleFunc("/api/transactions/{transaction_id}", handler.DeleteTransactionHandler).
 		Methods(http.MethodDelete, http.MethodOptions)
 
-	router.HandleFunc("/api/stats/budget", handlers.GetStatsBudgetHandler).
+	router.HandleFunc("/api/transfers", handler.CreateTransferHandler).
+		Methods(http.MethodPost, http.MethodOptions)
+
+	router.HandleFunc("/api/categories", handler.CreateCategoryHandler).
+		Methods(http.MethodPost, http.MethodOptions)
+
+	router.HandleFunc("/api/categories", handler.ListCategoriesHandler).
+		Methods(http.MethodGet, http.MethodOptions)
+
+	router.HandleFunc("/api/categories/{category_id}", handler.UpdateCategoryHandler).
+		Methods(http.MethodPatch, http.MethodOptions)
+
+	router.HandleFunc("/api/categories/{category_id}", handler.DeleteCategoryHandler).
+		Methods(http.MethodDelete, http.MethodOptions)
+
+	router.HandleFunc("/api/exchange-rates", handler.CreateExchangeRatesHandler).
+		Methods(http.MethodPost, http.MethodOptions)
+
+	router.HandleFunc("/api/exchange-rates/csv", handler.ImportExchangeRatesHandler).
+		Methods(http.MethodPost, http.MethodOptions)
+
+	router.HandleFunc("/api/exchange-rates", handler.ListExchangeRatesHandler).
+		Methods(http.MethodGet, http.MethodOptions)
+
+	router.HandleFunc("/api/exchange-rates/{rate_id}", handler.DeleteExchangeRateHandler).
+		Methods(http.MethodDelete, http.MethodOptions)
+
+	router.HandleFunc("/api/budget-plans", handler.CreateBudgetPlanHandler).
+		Methods(http.MethodPost, http.MethodOptions)
+
+	router.HandleFunc("/api/budget-plans", handler.ListBudgetPlansHandler).
+		Methods(http.MethodGet, http.MethodOptions)
+
+	router.HandleFunc("/api/budget-plans/{plan_id}", handler.DeleteBudgetPlanHandler).
+		Methods(http.MethodDelete, http.MethodOptions)
+
+	router.HandleFunc("/api/recurring-templates", handler.CreateRecurringTemplateHandler).
+		Methods(http.MethodPost, http.MethodOptions)
+
+	router.HandleFunc("/api/recurring-templates", handler.ListRecurringTemplatesHandler).
+		Methods(http.MethodGet, http.MethodOptions)
+
+	router.HandleFunc("/api/recurring-templates/{template_id}", handler.DeleteRecurringTemplateHandler).
+		Methods(http.MethodDelete, http.MethodOptions)
+
+	router.HandleFunc("/api/recurring-templates/{template_id}/occurrences", handler.PreviewRecurringTemplateHandler).
+		Methods(http.MethodGet, http.MethodOptions)
+
+	router.HandleFunc("/api/import-profiles", handler.CreateImportProfileHandler).
+		Methods(http.MethodPost, http.MethodOptions)
+
+	router.HandleFunc("/api/import-profiles", handler.ListImportProfilesHandler).
+		Methods(http.MethodGet, http.MethodOptions)
+
+	router.HandleFunc("/api/import-profiles/{profile_id}", handler.DeleteImportProfileHandler).
+		Methods(http.MethodDelete, http.MethodOptions)
+
+	router.HandleFunc("/api/imports/{format}", handler.ImportStatementHandler).
+		Methods(http.MethodPost, http.MethodOptions)
+
+	router.HandleFunc("/api/imports/journal/{format}", handler.ImportJournalHandler).
+		Methods(http.MethodPost, http.MethodOptions)
+
+	router.HandleFunc("/api/exports/transactions", handler.ExportTransactionsHandler).
+		Methods(http.MethodGet, http.MethodOptions)
+
+	router.HandleFunc("/api/exports/journal", handler.ExportJournalHandler).
+		Methods(http.MethodGet, http.MethodOptions)
+
+	router.HandleFunc("/api/rules", handler.CreateRuleHandler).
+		Methods(http.MethodPost, http.MethodOptions)
+
+	router.HandleFunc("/api/rules", handler.ListRulesHandler).
+		Methods(http.MethodGet, http.MethodOptions)
+
+	// This route is registered before the rule ID routes, so that "apply" is not taken for an ID.
+	router.HandleFunc("/api/rules/apply", handler.ApplyRulesHandler).
+		Methods(http.MethodPost, http.MethodOptions)
+
+	router.HandleFunc("/api/rules/{rule_id}", handler.DeleteRuleHandler).
+		Methods(http.MethodDelete, http.MethodOptions)
+
+	router.HandleFunc("/api/admin/backup", handler.CreateBackupHandler).
+		Methods(http.MethodGet, http.MethodOptions)
+
+	router.HandleFunc("/api/admin/restore", handler.RestoreBackupHandler).
+		Methods(http.MethodPost, http.MethodOptions)
+
+	router.HandleFunc("/api/stats/budget", handler.GetStatsBudgetHandler).
 		Methods(http.MethodGet, http.MethodOptions)
 
-	router.HandleFunc("/api/stats/balances", handlers.GetStatsBalancesHandler).
+	router.HandleFunc("/api/stats/balances", handler.GetStatsBalancesHandler).
 		Methods(http.MethodGet, http.MethodOptions)
 
 	return router
@@ -0,0 +1,2096 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"io/ioutil"
+	"mime/multipart"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shivanshkc/ledgerkeep/src/configs"
+	"github.com/shivanshkc/ledgerkeep/src/database"
+	"github.com/shivanshkc/ledgerkeep/src/middlewares"
+	"github.com/shivanshkc/ledgerkeep/src/models"
+	"github.com/shivanshkc/ledgerkeep/src/oidc"
+	"github.com/shivanshkc/ledgerkeep/src/utils/ctxutils"
+	"github.com/shivanshkc/ledgerkeep/src/utils/testutils"
+
+	"github.com/gorilla/mux"
+)
+
+func TestMain(m *testing.M) {
+	cleanup, err := testutils.UseConfigs(testutils.DefaultConfigs)
+	if err != nil {
+		panic(err)
+	}
+
+	code := m.Run()
+	cleanup()
+	os.Exit(code)
+}
+
+// testResponseBody is the schema of the response bodies as decoded by the tests.
+type testResponseBody struct {
+	StatusCode int             `json:"status_code"`
+	CustomCode string          `json:"custom_code"`
+	Data       json.RawMessage `json:"data"`
+}
+
+// doTestRequest sends an authenticated request to the handler and decodes the response body.
+func doTestRequest(t *testing.T, handler http.Handler, method, path, body string) *testResponseBody {
+	t.Helper()
+	return doTestRequestAs(t, handler, testutils.Username, testutils.Password, method, path, body)
+}
+
+// doTestRequestAs sends a request with the provided basic auth credentials to the handler and decodes the response
+// body.
+func doTestRequestAs(t *testing.T, handler http.Handler, username, password, method, path, body string) *testResponseBody {
+	t.Helper()
+	return doTestLedgerRequest(t, handler, username, password, "", method, path, body)
+}
+
+// doTestLedgerRequest is like doTestRequestAs, but it also selects the ledger of the request, unless it is empty.
+func doTestLedgerRequest(t *testing.T, handler http.Handler, username, password, ledgerID, method, path,
+	body string) *testResponseBody {
+	t.Helper()
+
+	request := httptest.NewRequest(method, path, strings.NewReader(body))
+	request.SetBasicAuth(username, password)
+	if ledgerID != "" {
+		request.Header.Set(middlewares.LedgerIDHeader, ledgerID)
+	}
+	return serveTestRequest(t, handler, request)
+}
+
+// doTestTokenRequest sends a request with the provided API token to the handler and decodes the response body.
+func doTestTokenRequest(t *testing.T, handler http.Handler, token, method, path, body string) *testResponseBody {
+	t.Helper()
+
+	request := httptest.NewRequest(method, path, strings.NewReader(body))
+	request.Header.Set("Authorization", "Bearer "+token)
+	return serveTestRequest(t, handler, request)
+}
+
+// serveTestRequest sends the request to the handler and decodes the response body.
+func serveTestRequest(t *testing.T, handler http.Handler, request *http.Request) *testResponseBody {
+	t.Helper()
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, request)
+
+	response := &testResponseBody{}
+	if err := json.Unmarshal(recorder.Body.Bytes(), response); err != nil {
+		t.Fatalf("failed to decode response of %s %s: %+v", request.Method, request.URL.Path, err)
+	}
+	return response
+}
+
+// defaultTenantContext provides a context with the tenant of the test user, which the repositories require.
+func defaultTenantContext() context.Context {
+	return ctxutils.PutTenantID(context.Background(), database.DefaultTenantID)
+}
+
+// doTestUpload uploads the file content, along with the form fields, as a multipart form to the handler,
+// and decodes the response body.
+func doTestUpload(t *testing.T, handler http.Handler, path string, fields map[string]string, content string) *testResponseBody {
+	t.Helper()
+
+	body := &bytes.Buffer{}
+	multipartWriter := multipart.NewWriter(body)
+	for name, value := range fields {
+		if err := multipartWriter.WriteField(name, value); err != nil {
+			t.Fatalf("failed to write form field: %+v", err)
+		}
+	}
+	fileWriter, err := multipartWriter.CreateFormFile("file", "upload")
+	if err != nil {
+		t.Fatalf("failed to create form file: %+v", err)
+	}
+	_, _ = fileWriter.Write([]byte(content))
+	_ = multipartWriter.Close()
+
+	request := httptest.NewRequest(http.MethodPost, path, body)
+	request.Header.Set("Content-Type", multipartWriter.FormDataContentType())
+	request.SetBasicAuth(testutils.Username, testutils.Password)
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, request)
+
+	response := &testResponseBody{}
+	if err := json.Unmarshal(recorder.Body.Bytes(), response); err != nil {
+		t.Fatalf("failed to decode response of POST %s: %+v", path, err)
+	}
+	return response
+}
+
+func TestAPIWithMemoryStorage(t *testing.T) {
+	handler := getHandler(database.NewMemoryRepositories(), nil)
+
+	response := doTestRequest(t, handler, http.MethodPost, "/api/accounts", `{"id":"bank","name":"Bank"}`)
+	if response.CustomCode != "ACCOUNT_CREATED" {
+		t.Fatalf("expected ACCOUNT_CREATED, got: %s", response.CustomCode)
+	}
+
+	response = doTestRequest(t, handler, http.MethodPost, "/api/accounts", `{"id":"bank","name":"Bank"}`)
+	if response.CustomCode != "ACCOUNT_ALREADY_EXISTS" {
+		t.Fatalf("expected ACCOUNT_ALREADY_EXISTS, got: %s", response.CustomCode)
+	}
+
+	response = doTestRequest(t, handler, http.MethodPost, "/api/transactions",
+		`{"amount":1000.1,"timestamp":100,"account_id":"bank","category":"earnings","notes":"Salary"}`)
+	if response.CustomCode != "TRANSACTION_CREATED" {
+		t.Fatalf("expected TRANSACTION_CREATED, got: %s", response.CustomCode)
+	}
+
+	response = doTestRequest(t, handler, http.MethodPost, "/api/transactions",
+		`{"amount":-250.05,"timestamp":200,"account_id":"bank","category":"essentials","notes":"Rent"}`)
+	if response.CustomCode != "TRANSACTION_CREATED" {
+		t.Fatalf("expected TRANSACTION_CREATED, got: %s", response.CustomCode)
+	}
+
+	var created struct {
+		ID string `json:"id"`
+	}
+	if err := json.Unmarshal(response.Data, &created); err != nil {
+		t.Fatalf("failed to decode created transaction: %+v", err)
+	}
+
+	// Listing transactions must provide closing balances.
+	response = doTestRequest(t, handler, http.MethodGet, "/api/transactions?sort_order=asc", "")
+	var transactions []struct {
+		ID         string  `json:"id"`
+		ClosingBal float64 `json:"closing_bal"`
+	}
+	if err := json.Unmarshal(response.Data, &transactions); err != nil {
+		t.Fatalf("failed to decode transactions: %+v", err)
+	}
+	if len(transactions) != 2 || transactions[0].ClosingBal != 1000.1 || transactions[1].ClosingBal != 750.05 {
+		t.Fatalf("unexpected transactions: %+v", transactions)
+	}
+
+	// Accounts in use cannot be deleted.
+	response = doTestRequest(t, handler, http.MethodDelete, "/api/accounts/bank", "")
+	if response.CustomCode != "ACCOUNT_IS_IN_USE" {
+		t.Fatalf("expected ACCOUNT_IS_IN_USE, got: %s", response.CustomCode)
+	}
+
+	response = doTestRequest(t, handler, http.MethodGet, "/api/stats/budget?start_time=0&end_time=1000", "")
+	var budget struct {
+		TotalIncome      float64 `json:"total_income"`
+		EssentialsActual float64 `json:"essentials_actual"`
+	}
+	if err := json.Unmarshal(response.Data, &budget); err != nil {
+		t.Fatalf("failed to decode budget: %+v", err)
+	}
+	if budget.TotalIncome != 1000.1 || budget.EssentialsActual != 250.05 {
+		t.Fatalf("unexpected budget: %+v", budget)
+	}
+
+	response = doTestRequest(t, handler, http.MethodDelete, "/api/transactions/"+created.ID, "")
+	if response.CustomCode != "TRANSACTION_DELETED" {
+		t.Fatalf("expected TRANSACTION_DELETED, got: %s", response.CustomCode)
+	}
+
+	response = doTestRequest(t, handler, http.MethodGet, "/api/transactions/"+created.ID, "")
+	if response.CustomCode != "TRANSACTION_NOT_FOUND" {
+		t.Fatalf("expected TRANSACTION_NOT_FOUND, got: %s", response.CustomCode)
+	}
+}
+
+func TestAPIWithMultipleCurrencies(t *testing.T) {
+	handler := getHandler(database.NewMemoryRepositories(), nil)
+
+	// Accounts are in the default currency (INR in tests), unless they specify one.
+	for _, body := range []string{
+		`{"id":"bank","name":"Bank"}`,
+		`{"id":"wallet","name":"Wallet","currency":"usd"}`,
+		`{"id":"yen","name":"Yen","currency":"JPY"}`,
+	} {
+		if response := doTestRequest(t, handler, http.MethodPost, "/api/accounts", body); response.CustomCode != "ACCOUNT_CREATED" {
+			t.Fatalf("expected ACCOUNT_CREATED, got: %s", response.CustomCode)
+		}
+	}
+
+	response := doTestRequest(t, handler, http.MethodPost, "/api/accounts", `{"id":"other","name":"Other","currency":"ABC"}`)
+	if response.CustomCode != "BAD_REQUEST" {
+		t.Fatalf("expected BAD_REQUEST for an invalid currency, got: %s", response.CustomCode)
+	}
+
+	// Yen has no decimal places.
+	response = doTestRequest(t, handler, http.MethodPost, "/api/transactions",
+		`{"amount":-10.5,"timestamp":1641081600,"account_id":"yen","category":"luxury"}`)
+	if response.CustomCode != "BAD_REQUEST" {
+		t.Fatalf("expected BAD_REQUEST for an invalid yen amount, got: %s", response.CustomCode)
+	}
+
+	response = doTestRequest(t, handler, http.MethodPost, "/api/transactions",
+		`{"amount":1000,"timestamp":1641081600,"account_id":"bank","category":"earnings"}`)
+	if response.CustomCode != "TRANSACTION_CREATED" {
+		t.Fatalf("expected TRANSACTION_CREATED, got: %s", response.CustomCode)
+	}
+
+	response = doTestRequest(t, handler, http.MethodPost, "/api/transactions",
+		`{"amount":-10,"timestamp":1641081600,"account_id":"wallet","category":"essentials"}`)
+	if response.CustomCode != "TRANSACTION_CREATED" {
+		t.Fatalf("expected TRANSACTION_CREATED, got: %s", response.CustomCode)
+	}
+
+	var created struct {
+		ID string `json:"id"`
+	}
+	if err := json.Unmarshal(response.Data, &created); err != nil {
+		t.Fatalf("failed to decode created transaction: %+v", err)
+	}
+
+	// A transaction cannot be moved to an account of another currency.
+	response = doTestRequest(t, handler, http.MethodPatch, "/api/transactions/"+created.ID, `{"account_id":"bank"}`)
+	if response.CustomCode != "BAD_REQUEST" {
+		t.Fatalf("expected BAD_REQUEST for a currency mismatch, got: %s", response.CustomCode)
+	}
+
+	// Reporting in one currency needs the exchange rates.
+	response = doTestRequest(t, handler, http.MethodGet, "/api/stats/budget?end_time=1643587200", "")
+	if response.CustomCode != "EXCHANGE_RATE_NOT_FOUND" {
+		t.Fatalf("expected EXCHANGE_RATE_NOT_FOUND, got: %s", response.CustomCode)
+	}
+
+	response = doTestRequest(t, handler, http.MethodPost, "/api/exchange-rates",
+		`{"rates":[{"base":"USD","quote":"INR","rate":"75.5","timestamp":0}]}`)
+	if response.CustomCode != "EXCHANGE_RATES_SAVED" {
+		t.Fatalf("expected EXCHANGE_RATES_SAVED, got: %s", response.CustomCode)
+	}
+
+	var budget struct {
+		Currency         string  `json:"currency"`
+		TotalIncome      float64 `json:"total_income"`
+		EssentialsActual float64 `json:"essentials_actual"`
+	}
+
+	response = doTestRequest(t, handler, http.MethodGet, "/api/stats/budget?end_time=1643587200", "")
+	if err := json.Unmarshal(response.Data, &budget); err != nil {
+		t.Fatalf("failed to decode budget: %+v", err)
+	}
+	if budget.Currency != "INR" || budget.TotalIncome != 1000 || budget.EssentialsActual != 755 {
+		t.Fatalf("unexpected budget: %+v", budget)
+	}
+
+	// The inverse rate is used for the other direction.
+	response = doTestRequest(t, handler, http.MethodGet, "/api/stats/budget?end_time=1643587200&currency=USD", "")
+	if err := json.Unmarshal(response.Data, &budget); err != nil {
+		t.Fatalf("failed to decode budget: %+v", err)
+	}
+	if budget.Currency != "USD" || budget.TotalIncome != 13.245 || budget.EssentialsActual != 10 {
+		t.Fatalf("unexpected budget: %+v", budget)
+	}
+
+	response = doTestRequest(t, handler, http.MethodGet, "/api/stats/balances", "")
+	var balances map[string]float64
+	if err := json.Unmarshal(response.Data, &balances); err != nil {
+		t.Fatalf("failed to decode balances: %+v", err)
+	}
+	if len(balances) != 1 || balances["1643587200"] != 245 {
+		t.Fatalf("unexpected balances: %+v", balances)
+	}
+}
+
+func TestImportExchangeRates(t *testing.T) {
+	handler := getHandler(database.NewMemoryRepositories(), nil)
+
+	body := &bytes.Buffer{}
+	multipartWriter := multipart.NewWriter(body)
+	fileWriter, err := multipartWriter.CreateFormFile("file", "rates.csv")
+	if err != nil {
+		t.Fatalf("failed to create form file: %+v", err)
+	}
+	_, _ = fileWriter.Write([]byte("date,base,quote,rate\n2022-01-01,EUR,INR,84.1\n2022-02-01,eur,inr,85.0250\n"))
+	_ = multipartWriter.Close()
+
+	request := httptest.NewRequest(http.MethodPost, "/api/exchange-rates/csv", body)
+	request.Header.Set("Content-Type", multipartWriter.FormDataContentType())
+	request.SetBasicAuth(testutils.Username, testutils.Password)
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, request)
+	if recorder.Code != http.StatusCreated {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusCreated, recorder.Code, recorder.Body.String())
+	}
+
+	response := doTestRequest(t, handler, http.MethodGet, "/api/exchange-rates?base=EUR", "")
+	var rates []struct {
+		Quote     string          `json:"quote"`
+		Rate      json.RawMessage `json:"rate"`
+		Timestamp int64           `json:"timestamp"`
+	}
+	if err := json.Unmarshal(response.Data, &rates); err != nil {
+		t.Fatalf("failed to decode rates: %+v", err)
+	}
+	if len(rates) != 2 || rates[1].Quote != "INR" || string(rates[1].Rate) != "85.025" || rates[1].Timestamp != 1643673600 {
+		t.Fatalf("unexpected rates: %+v", rates)
+	}
+}
+
+func TestAPIWithTransfers(t *testing.T) {
+	handler := getHandler(database.NewMemoryRepositories(), nil)
+
+	for _, body := range []string{
+		`{"id":"bank","name":"Bank"}`,
+		`{"id":"wallet","name":"Wallet"}`,
+		`{"id":"travel","name":"Travel","currency":"USD"}`,
+	} {
+		if response := doTestRequest(t, handler, http.MethodPost, "/api/accounts", body); response.CustomCode != "ACCOUNT_CREATED" {
+			t.Fatalf("expected ACCOUNT_CREATED, got: %s", response.CustomCode)
+		}
+	}
+
+	response := doTestRequest(t, handler, http.MethodPost, "/api/transactions",
+		`{"amount":1000,"timestamp":100,"account_id":"bank","category":"earnings"}`)
+	if response.CustomCode != "TRANSACTION_CREATED" {
+		t.Fatalf("expected TRANSACTION_CREATED, got: %s", response.CustomCode)
+	}
+
+	// Transfers between different currencies need the received amount.
+	response = doTestRequest(t, handler, http.MethodPost, "/api/transfers",
+		`{"amount":100,"timestamp":200,"from_account_id":"bank","to_account_id":"travel"}`)
+	if response.CustomCode != "BAD_REQUEST" {
+		t.Fatalf("expected BAD_REQUEST, got: %s", response.CustomCode)
+	}
+
+	response = doTestRequest(t, handler, http.MethodPost, "/api/transfers",
+		`{"amount":300,"timestamp":200,"from_account_id":"bank","to_account_id":"wallet","notes":"Cash"}`)
+	if response.CustomCode != "TRANSFER_CREATED" {
+		t.Fatalf("expected TRANSFER_CREATED, got: %s", response.CustomCode)
+	}
+
+	var created struct {
+		ID             string   `json:"id"`
+		TransactionIDs []string `json:"transaction_ids"`
+	}
+	if err := json.Unmarshal(response.Data, &created); err != nil {
+		t.Fatalf("failed to decode created transfer: %+v", err)
+	}
+	if len(created.TransactionIDs) != 2 {
+		t.Fatalf("unexpected transfer: %+v", created)
+	}
+
+	// Updating the credit leg updates the debit leg too.
+	response = doTestRequest(t, handler, http.MethodPatch, "/api/transactions/"+created.TransactionIDs[1],
+		`{"amount":250,"notes":"ATM"}`)
+	if response.CustomCode != "TRANSACTION_UPDATED" {
+		t.Fatalf("expected TRANSACTION_UPDATED, got: %s", response.CustomCode)
+	}
+
+	response = doTestRequest(t, handler, http.MethodGet, "/api/transactions/"+created.TransactionIDs[0], "")
+	var debitLeg struct {
+		Amount     float64 `json:"amount"`
+		Notes      string  `json:"notes"`
+		TransferID string  `json:"transfer_id"`
+	}
+	if err := json.Unmarshal(response.Data, &debitLeg); err != nil {
+		t.Fatalf("failed to decode transaction: %+v", err)
+	}
+	if debitLeg.Amount != -250 || debitLeg.Notes != "ATM" || debitLeg.TransferID != created.ID {
+		t.Fatalf("unexpected debit leg: %+v", debitLeg)
+	}
+
+	response = doTestRequest(t, handler, http.MethodPatch, "/api/transactions/"+created.TransactionIDs[1],
+		`{"category":"earnings"}`)
+	if response.CustomCode != "BAD_REQUEST" {
+		t.Fatalf("expected BAD_REQUEST for a category update, got: %s", response.CustomCode)
+	}
+
+	// Transfers are neither income nor expense.
+	response = doTestRequest(t, handler, http.MethodGet, "/api/stats/budget?start_time=0&end_time=1000", "")
+	var budget struct {
+		TotalIncome   float64 `json:"total_income"`
+		SavingsActual float64 `json:"savings_actual"`
+	}
+	if err := json.Unmarshal(response.Data, &budget); err != nil {
+		t.Fatalf("failed to decode budget: %+v", err)
+	}
+	if budget.TotalIncome != 1000 || budget.SavingsActual != 1000 {
+		t.Fatalf("unexpected budget: %+v", budget)
+	}
+
+	// Deleting the debit leg deletes the credit leg too.
+	response = doTestRequest(t, handler, http.MethodDelete, "/api/transactions/"+created.TransactionIDs[0], "")
+	if response.CustomCode != "TRANSACTION_DELETED" {
+		t.Fatalf("expected TRANSACTION_DELETED, got: %s", response.CustomCode)
+	}
+
+	response = doTestRequest(t, handler, http.MethodGet, "/api/transactions/"+created.TransactionIDs[1], "")
+	if response.CustomCode != "TRANSACTION_NOT_FOUND" {
+		t.Fatalf("expected TRANSACTION_NOT_FOUND, got: %s", response.CustomCode)
+	}
+}
+
+func TestAPIWithSplitTransactions(t *testing.T) {
+	handler := getHandler(database.NewMemoryRepositories(), nil)
+
+	if response := doTestRequest(t, handler, http.MethodPost, "/api/accounts", `{"id":"bank","name":"Bank"}`); response.CustomCode != "ACCOUNT_CREATED" {
+		t.Fatalf("expected ACCOUNT_CREATED, got: %s", response.CustomCode)
+	}
+
+	// The split lines should add up to the amount.
+	response := doTestRequest(t, handler, http.MethodPost, "/api/transactions",
+		`{"amount":-100,"timestamp":100,"account_id":"bank","splits":[
+			{"amount":-70,"category":"essentials"},{"amount":-20,"category":"luxury"}]}`)
+	if response.CustomCode != "BAD_REQUEST" {
+		t.Fatalf("expected BAD_REQUEST, got: %s", response.CustomCode)
+	}
+
+	response = doTestRequest(t, handler, http.MethodPost, "/api/transactions",
+		`{"amount":-100,"timestamp":100,"account_id":"bank","notes":"Supermarket","splits":[
+			{"amount":-70,"category":"Essentials","notes":"Groceries"},{"amount":-30,"category":"luxury"}]}`)
+	if response.CustomCode != "TRANSACTION_CREATED" {
+		t.Fatalf("expected TRANSACTION_CREATED, got: %s", response.CustomCode)
+	}
+
+	var created struct {
+		ID string `json:"id"`
+	}
+	if err := json.Unmarshal(response.Data, &created); err != nil {
+		t.Fatalf("failed to decode created transaction: %+v", err)
+	}
+
+	response = doTestRequest(t, handler, http.MethodPost, "/api/transactions",
+		`{"amount":1000,"timestamp":100,"account_id":"bank","category":"earnings"}`)
+	if response.CustomCode != "TRANSACTION_CREATED" {
+		t.Fatalf("expected TRANSACTION_CREATED, got: %s", response.CustomCode)
+	}
+
+	// Every split line counts in its own category.
+	response = doTestRequest(t, handler, http.MethodGet, "/api/stats/budget?start_time=0&end_time=1000", "")
+	var budget struct {
+		EssentialsActual float64 `json:"essentials_actual"`
+		LuxuryActual     float64 `json:"luxury_actual"`
+	}
+	if err := json.Unmarshal(response.Data, &budget); err != nil {
+		t.Fatalf("failed to decode budget: %+v", err)
+	}
+	if budget.EssentialsActual != 70 || budget.LuxuryActual != 30 {
+		t.Fatalf("unexpected budget: %+v", budget)
+	}
+
+	// The amount cannot change without the split lines.
+	response = doTestRequest(t, handler, http.MethodPatch, "/api/transactions/"+created.ID, `{"amount":-120}`)
+	if response.CustomCode != "BAD_REQUEST" {
+		t.Fatalf("expected BAD_REQUEST, got: %s", response.CustomCode)
+	}
+
+	response = doTestRequest(t, handler, http.MethodPatch, "/api/transactions/"+created.ID,
+		`{"amount":-120,"splits":[{"amount":-70,"category":"essentials"},{"amount":-50,"category":"investments"}]}`)
+	if response.CustomCode != "TRANSACTION_UPDATED" {
+		t.Fatalf("expected TRANSACTION_UPDATED, got: %s", response.CustomCode)
+	}
+
+	response = doTestRequest(t, handler, http.MethodGet, "/api/transactions/"+created.ID, "")
+	var transaction struct {
+		Category string `json:"category"`
+		Splits   []struct {
+			Amount   float64 `json:"amount"`
+			Category string  `json:"category"`
+		} `json:"splits"`
+	}
+	if err := json.Unmarshal(response.Data, &transaction); err != nil {
+		t.Fatalf("failed to decode transaction: %+v", err)
+	}
+	if transaction.Category != "split" || len(transaction.Splits) != 2 || transaction.Splits[1].Category != "investments" {
+		t.Fatalf("unexpected transaction: %+v", transaction)
+	}
+
+	// Removing the split lines requires a category.
+	response = doTestRequest(t, handler, http.MethodPatch, "/api/transactions/"+created.ID, `{"splits":[]}`)
+	if response.CustomCode != "BAD_REQUEST" {
+		t.Fatalf("expected BAD_REQUEST, got: %s", response.CustomCode)
+	}
+
+	response = doTestRequest(t, handler, http.MethodPatch, "/api/transactions/"+created.ID, `{"splits":[],"category":"essentials"}`)
+	if response.CustomCode != "TRANSACTION_UPDATED" {
+		t.Fatalf("expected TRANSACTION_UPDATED, got: %s", response.CustomCode)
+	}
+
+	response = doTestRequest(t, handler, http.MethodGet, "/api/stats/budget?start_time=0&end_time=1000", "")
+	if err := json.Unmarshal(response.Data, &budget); err != nil {
+		t.Fatalf("failed to decode budget: %+v", err)
+	}
+	if budget.EssentialsActual != 120 || budget.LuxuryActual != 0 {
+		t.Fatalf("unexpected budget: %+v", budget)
+	}
+}
+
+func TestAPIWithCategories(t *testing.T) {
+	handler := getHandler(database.NewMemoryRepositories(), nil)
+
+	if response := doTestRequest(t, handler, http.MethodPost, "/api/accounts", `{"id":"bank","name":"Bank"}`); response.CustomCode != "ACCOUNT_CREATED" {
+		t.Fatalf("expected ACCOUNT_CREATED, got: %s", response.CustomCode)
+	}
+
+	// The categories of the application are reserved.
+	response := doTestRequest(t, handler, http.MethodPost, "/api/categories",
+		`{"id":"transfer","name":"Transfer","kind":"both","budget_group":"ignorable"}`)
+	if response.CustomCode != "BAD_REQUEST" {
+		t.Fatalf("expected BAD_REQUEST, got: %s", response.CustomCode)
+	}
+
+	response = doTestRequest(t, handler, http.MethodPost, "/api/categories",
+		`{"id":"Rent","name":"Rent","kind":"Debit","budget_group":"essentials"}`)
+	if response.CustomCode != "CATEGORY_CREATED" {
+		t.Fatalf("expected CATEGORY_CREATED, got: %s", response.CustomCode)
+	}
+
+	response = doTestRequest(t, handler, http.MethodGet, "/api/categories", "")
+	var categories []struct {
+		ID   string `json:"id"`
+		Kind string `json:"kind"`
+	}
+	if err := json.Unmarshal(response.Data, &categories); err != nil {
+		t.Fatalf("failed to decode categories: %+v", err)
+	}
+	if len(categories) != 10 || categories[7].ID != "rent" || categories[7].Kind != "debit" {
+		t.Fatalf("unexpected categories: %+v", categories)
+	}
+
+	// The kind of the category decides the allowed sign of the amount.
+	response = doTestRequest(t, handler, http.MethodPost, "/api/transactions",
+		`{"amount":500,"timestamp":100,"account_id":"bank","category":"rent"}`)
+	if response.CustomCode != "BAD_REQUEST" {
+		t.Fatalf("expected BAD_REQUEST, got: %s", response.CustomCode)
+	}
+
+	response = doTestRequest(t, handler, http.MethodPost, "/api/transactions",
+		`{"amount":-500,"timestamp":100,"account_id":"bank","category":"rent"}`)
+	if response.CustomCode != "TRANSACTION_CREATED" {
+		t.Fatalf("expected TRANSACTION_CREATED, got: %s", response.CustomCode)
+	}
+
+	response = doTestRequest(t, handler, http.MethodPost, "/api/transactions",
+		`{"amount":1000,"timestamp":100,"account_id":"bank","category":"earnings"}`)
+	if response.CustomCode != "TRANSACTION_CREATED" {
+		t.Fatalf("expected TRANSACTION_CREATED, got: %s", response.CustomCode)
+	}
+
+	// The category counts in its budget group.
+	response = doTestRequest(t, handler, http.MethodGet, "/api/stats/budget?start_time=0&end_time=1000", "")
+	var budget struct {
+		TotalIncome      float64 `json:"total_income"`
+		EssentialsActual float64 `json:"essentials_actual"`
+	}
+	if err := json.Unmarshal(response.Data, &budget); err != nil {
+		t.Fatalf("failed to decode budget: %+v", err)
+	}
+	if budget.TotalIncome != 1000 || budget.EssentialsActual != 500 {
+		t.Fatalf("unexpected budget: %+v", budget)
+	}
+
+	// The kind of a category in use can only be widened.
+	response = doTestRequest(t, handler, http.MethodPatch, "/api/categories/rent", `{"kind":"credit"}`)
+	if response.CustomCode != "BAD_REQUEST" {
+		t.Fatalf("expected BAD_REQUEST, got: %s", response.CustomCode)
+	}
+
+	response = doTestRequest(t, handler, http.MethodPatch, "/api/categories/rent", `{"kind":"both","budget_group":"luxury"}`)
+	if response.CustomCode != "CATEGORY_UPDATED" {
+		t.Fatalf("expected CATEGORY_UPDATED, got: %s", response.CustomCode)
+	}
+
+	response = doTestRequest(t, handler, http.MethodGet, "/api/stats/budget?start_time=0&end_time=1000", "")
+	var luxuryBudget struct {
+		LuxuryActual float64 `json:"luxury_actual"`
+	}
+	if err := json.Unmarshal(response.Data, &luxuryBudget); err != nil {
+		t.Fatalf("failed to decode budget: %+v", err)
+	}
+	if luxuryBudget.LuxuryActual != 500 {
+		t.Fatalf("unexpected budget: %+v", luxuryBudget)
+	}
+
+	response = doTestRequest(t, handler, http.MethodDelete, "/api/categories/rent", "")
+	if response.CustomCode != "CATEGORY_IS_IN_USE" {
+		t.Fatalf("expected CATEGORY_IS_IN_USE, got: %s", response.CustomCode)
+	}
+
+	response = doTestRequest(t, handler, http.MethodDelete, "/api/categories/savings", "")
+	if response.CustomCode != "CATEGORY_DELETED" {
+		t.Fatalf("expected CATEGORY_DELETED, got: %s", response.CustomCode)
+	}
+
+	response = doTestRequest(t, handler, http.MethodPost, "/api/transactions",
+		`{"amount":-100,"timestamp":100,"account_id":"bank","category":"savings"}`)
+	if response.CustomCode != "BAD_REQUEST" {
+		t.Fatalf("expected BAD_REQUEST, got: %s", response.CustomCode)
+	}
+}
+
+func TestAPIWithBudgetPlans(t *testing.T) {
+	handler := getHandler(database.NewMemoryRepositories(), nil)
+
+	if response := doTestRequest(t, handler, http.MethodPost, "/api/accounts", `{"id":"bank","name":"Bank"}`); response.CustomCode != "ACCOUNT_CREATED" {
+		t.Fatalf("expected ACCOUNT_CREATED, got: %s", response.CustomCode)
+	}
+	for _, body := range []string{
+		`{"amount":1000,"timestamp":150,"account_id":"bank","category":"earnings","notes":"Salary"}`,
+		`{"amount":1000,"timestamp":250,"account_id":"bank","category":"earnings","notes":"Bonus"}`,
+	} {
+		if response := doTestRequest(t, handler, http.MethodPost, "/api/transactions", body); response.CustomCode != "TRANSACTION_CREATED" {
+			t.Fatalf("expected TRANSACTION_CREATED, got: %s", response.CustomCode)
+		}
+	}
+
+	response := doTestRequest(t, handler, http.MethodPost, "/api/budget-plans",
+		`{"name":"Frugal","valid_from":100,"valid_until":200,"allocations":[
+			{"budget_group":"essentials","percent":60},{"budget_group":"luxury","amount":50}]}`)
+	if response.CustomCode != "BUDGET_PLAN_CREATED" {
+		t.Fatalf("expected BUDGET_PLAN_CREATED, got: %s", response.CustomCode)
+	}
+
+	// Only one budget plan can be in effect at any time.
+	response = doTestRequest(t, handler, http.MethodPost, "/api/budget-plans",
+		`{"name":"Overlapping","valid_from":150,"allocations":[]}`)
+	if response.CustomCode != "BAD_REQUEST" {
+		t.Fatalf("expected BAD_REQUEST, got: %s", response.CustomCode)
+	}
+
+	type testBudget struct {
+		PlanName           string  `json:"plan_name"`
+		EssentialsExpected float64 `json:"essentials_expected"`
+		LuxuryExpected     float64 `json:"luxury_expected"`
+		SavingsExpected    float64 `json:"savings_expected"`
+	}
+
+	testCases := []struct {
+		path     string
+		expected testBudget
+	}{
+		// The plan in effect at the end of the period provides the expected amounts.
+		{
+			path:     "/api/stats/budget?start_time=100&end_time=199",
+			expected: testBudget{PlanName: "Frugal", EssentialsExpected: 600, LuxuryExpected: 50},
+		},
+		// The default contributions are used when no plan is in effect.
+		{
+			path:     "/api/stats/budget?start_time=200&end_time=299",
+			expected: testBudget{EssentialsExpected: 400, LuxuryExpected: 200, SavingsExpected: 200},
+		},
+	}
+
+	for _, testCase := range testCases {
+		response = doTestRequest(t, handler, http.MethodGet, testCase.path, "")
+		var budget testBudget
+		if err := json.Unmarshal(response.Data, &budget); err != nil {
+			t.Fatalf("failed to decode budget: %+v", err)
+		}
+		if budget != testCase.expected {
+			t.Errorf("expected %+v for %s, got: %+v", testCase.expected, testCase.path, budget)
+		}
+	}
+
+	response = doTestRequest(t, handler, http.MethodGet, "/api/budget-plans", "")
+	var plans []struct {
+		ID       string `json:"id"`
+		Currency string `json:"currency"`
+	}
+	if err := json.Unmarshal(response.Data, &plans); err != nil {
+		t.Fatalf("failed to decode budget plans: %+v", err)
+	}
+	if len(plans) != 1 || plans[0].Currency != "INR" {
+		t.Fatalf("unexpected budget plans: %+v", plans)
+	}
+
+	response = doTestRequest(t, handler, http.MethodDelete, "/api/budget-plans/"+plans[0].ID, "")
+	if response.CustomCode != "BUDGET_PLAN_DELETED" {
+		t.Fatalf("expected BUDGET_PLAN_DELETED, got: %s", response.CustomCode)
+	}
+}
+
+func TestAPIWithRecurringTemplates(t *testing.T) {
+	handler := getHandler(database.NewMemoryRepositories(), nil)
+
+	if response := doTestRequest(t, handler, http.MethodPost, "/api/accounts", `{"id":"bank","name":"Bank"}`); response.CustomCode != "ACCOUNT_CREATED" {
+		t.Fatalf("expected ACCOUNT_CREATED, got: %s", response.CustomCode)
+	}
+
+	invalidBodies := []string{
+		// The rule should be a valid recurrence rule.
+		`{"name":"Rent","amount":-500,"account_id":"bank","category":"essentials","rule":"FREQ=SOMETIMES","start_time":86400}`,
+		// The category should allow the sign of the amount.
+		`{"name":"Rent","amount":500,"account_id":"bank","category":"essentials","rule":"FREQ=WEEKLY","start_time":86400}`,
+		// The end time should not be before the start time.
+		`{"name":"Rent","amount":-500,"account_id":"bank","category":"essentials","rule":"FREQ=WEEKLY","start_time":86400,"end_time":100}`,
+	}
+	for _, body := range invalidBodies {
+		if response := doTestRequest(t, handler, http.MethodPost, "/api/recurring-templates", body); response.CustomCode != "BAD_REQUEST" {
+			t.Errorf("expected BAD_REQUEST for %s, got: %s", body, response.CustomCode)
+		}
+	}
+
+	response := doTestRequest(t, handler, http.MethodPost, "/api/recurring-templates",
+		`{"name":"Rent","amount":-500,"account_id":"bank","category":"essentials","rule":"FREQ=WEEKLY","start_time":86400}`)
+	if response.CustomCode != "RECURRING_TEMPLATE_CREATED" {
+		t.Fatalf("expected RECURRING_TEMPLATE_CREATED, got: %s", response.CustomCode)
+	}
+	var created struct {
+		ID string `json:"id"`
+	}
+	if err := json.Unmarshal(response.Data, &created); err != nil {
+		t.Fatalf("failed to decode recurring template ID: %+v", err)
+	}
+
+	// The template's account and category cannot be deleted while it exists.
+	if response := doTestRequest(t, handler, http.MethodDelete, "/api/accounts/bank", ""); response.CustomCode != "ACCOUNT_IS_IN_USE" {
+		t.Fatalf("expected ACCOUNT_IS_IN_USE, got: %s", response.CustomCode)
+	}
+
+	response = doTestRequest(t, handler, http.MethodGet, "/api/recurring-templates/"+created.ID+"/occurrences?limit=3", "")
+	var occurrences []int64
+	if err := json.Unmarshal(response.Data, &occurrences); err != nil {
+		t.Fatalf("failed to decode occurrences: %+v", err)
+	}
+	if len(occurrences) != 3 || occurrences[0] != 86400 || occurrences[2] != 86400+2*604800 {
+		t.Fatalf("unexpected occurrences: %+v", occurrences)
+	}
+
+	response = doTestRequest(t, handler, http.MethodGet, "/api/recurring-templates", "")
+	var templates []struct {
+		ID      string `json:"id"`
+		NextRun int64  `json:"next_run"`
+	}
+	if err := json.Unmarshal(response.Data, &templates); err != nil {
+		t.Fatalf("failed to decode recurring templates: %+v", err)
+	}
+	if len(templates) != 1 || templates[0].ID != created.ID || templates[0].NextRun != 86400 {
+		t.Fatalf("unexpected recurring templates: %+v", templates)
+	}
+
+	response = doTestRequest(t, handler, http.MethodDelete, "/api/recurring-templates/"+created.ID, "")
+	if response.CustomCode != "RECURRING_TEMPLATE_DELETED" {
+		t.Fatalf("expected RECURRING_TEMPLATE_DELETED, got: %s", response.CustomCode)
+	}
+	if response := doTestRequest(t, handler, http.MethodDelete, "/api/accounts/bank", ""); response.CustomCode != "ACCOUNT_DELETED" {
+		t.Fatalf("expected ACCOUNT_DELETED, got: %s", response.CustomCode)
+	}
+}
+
+func TestAPIWithCSVImport(t *testing.T) {
+	repos := database.NewMemoryRepositories()
+	handler := getHandler(repos, nil)
+
+	if response := doTestRequest(t, handler, http.MethodPost, "/api/accounts", `{"id":"bank","name":"Bank"}`); response.CustomCode != "ACCOUNT_CREATED" {
+		t.Fatalf("expected ACCOUNT_CREATED, got: %s", response.CustomCode)
+	}
+
+	// The profile of a bank with separate debit and credit columns, and European number formats.
+	response := doTestRequest(t, handler, http.MethodPost, "/api/import-profiles", `{
+		"name":"Euro Bank","account_id":"bank","delimiter":";","decimal_separator":",",
+		"date_column":"Booking Date","date_format":"02.01.2006","debit_column":"Debit","credit_column":"Credit",
+		"notes_column":"Description","credit_category":"earnings","debit_category":"essentials"}`)
+	if response.CustomCode != "IMPORT_PROFILE_CREATED" {
+		t.Fatalf("expected IMPORT_PROFILE_CREATED, got: %s", response.CustomCode)
+	}
+	var created struct {
+		ID string `json:"id"`
+	}
+	if err := json.Unmarshal(response.Data, &created); err != nil {
+		t.Fatalf("failed to decode import profile ID: %+v", err)
+	}
+
+	statement := "Booking Date;Description;Debit;Credit\n" +
+		"01.03.2022;Salary;;1.250,50\n" +
+		"02.03.2022;Groceries;42,10;\n" +
+		"2022-03-03;Bad date;10;\n"
+
+	// A dry run previews the valid rows and reports the invalid ones.
+	response = doTestUpload(t, handler, "/api/imports/csv", map[string]string{"profile_id": created.ID}, statement)
+	if response.CustomCode != "IMPORT_PREVIEWED" {
+		t.Fatalf("expected IMPORT_PREVIEWED, got: %s", response.CustomCode)
+	}
+	var preview struct {
+		Transactions []struct {
+			Amount   float64 `json:"amount"`
+			Category string  `json:"category"`
+			Notes    string  `json:"notes"`
+		} `json:"transactions"`
+		Errors []string `json:"errors"`
+	}
+	if err := json.Unmarshal(response.Data, &preview); err != nil {
+		t.Fatalf("failed to decode preview: %+v", err)
+	}
+	if len(preview.Transactions) != 2 || preview.Transactions[0].Amount != 1250.5 || preview.Transactions[1].Amount != -42.1 ||
+		preview.Transactions[1].Category != "essentials" || preview.Transactions[1].Notes != "Groceries" {
+		t.Fatalf("unexpected previewed transactions: %+v", preview.Transactions)
+	}
+	if len(preview.Errors) != 1 || !strings.HasPrefix(preview.Errors[0], "row 4:") {
+		t.Fatalf("unexpected row errors: %+v", preview.Errors)
+	}
+
+	// Nothing is saved while any of the rows is invalid.
+	fields := map[string]string{"profile_id": created.ID, "dry_run": "false"}
+	if response := doTestUpload(t, handler, "/api/imports/csv", fields, statement); response.CustomCode != "BAD_REQUEST" {
+		t.Fatalf("expected BAD_REQUEST, got: %s", response.CustomCode)
+	}
+
+	statement = strings.TrimSuffix(statement, "2022-03-03;Bad date;10;\n")
+	if response := doTestUpload(t, handler, "/api/imports/csv", fields, statement); response.CustomCode != "TRANSACTIONS_IMPORTED" {
+		t.Fatalf("expected TRANSACTIONS_IMPORTED, got: %s", response.CustomCode)
+	}
+
+	balances, err := repos.Accounts.GetAccountBalances(defaultTenantContext())
+	if err != nil {
+		t.Fatalf("unexpected error in GetAccountBalances: %+v", err)
+	}
+	if balances["bank"] != 12084000 {
+		t.Fatalf("unexpected balances: %+v", balances)
+	}
+
+	response = doTestRequest(t, handler, http.MethodDelete, "/api/import-profiles/"+created.ID, "")
+	if response.CustomCode != "IMPORT_PROFILE_DELETED" {
+		t.Fatalf("expected IMPORT_PROFILE_DELETED, got: %s", response.CustomCode)
+	}
+}
+
+func TestAPIWithOFXImport(t *testing.T) {
+	repos := database.NewMemoryRepositories()
+	handler := getHandler(repos, nil)
+
+	if response := doTestRequest(t, handler, http.MethodPost, "/api/accounts", `{"id":"card","name":"Card"}`); response.CustomCode != "ACCOUNT_CREATED" {
+		t.Fatalf("expected ACCOUNT_CREATED, got: %s", response.CustomCode)
+	}
+
+	statement := "OFXHEADER:100\nDATA:OFXSGML\nVERSION:102\n\n<OFX><CREDITCARDMSGSRSV1><CCSTMTTRNRS><CCSTMTRS><BANKTRANLIST>\n" +
+		"<STMTTRN><TRNTYPE>CREDIT<DTPOSTED>20220301<TRNAMT>100.00<FITID>A1<NAME>Refund</STMTTRN>\n" +
+		"<STMTTRN><TRNTYPE>DEBIT<DTPOSTED>20220302<TRNAMT>-42.10<FITID>A2<NAME>Groceries</STMTTRN>\n" +
+		"</BANKTRANLIST></CCSTMTRS></CCSTMTTRNRS></CREDITCARDMSGSRSV1></OFX>\n"
+	fields := map[string]string{"account_id": "card", "credit_category": "earnings", "debit_category": "essentials",
+		"dry_run": "false"}
+
+	if response := doTestUpload(t, handler, "/api/imports/mt940", fields, statement); response.CustomCode != "BAD_REQUEST" {
+		t.Fatalf("expected BAD_REQUEST, got: %s", response.CustomCode)
+	}
+
+	var imported struct {
+		IDs     []string `json:"ids"`
+		Skipped int      `json:"skipped"`
+	}
+	response := doTestUpload(t, handler, "/api/imports/ofx", fields, statement)
+	if response.CustomCode != "TRANSACTIONS_IMPORTED" {
+		t.Fatalf("expected TRANSACTIONS_IMPORTED, got: %s", response.CustomCode)
+	}
+	if err := json.Unmarshal(response.Data, &imported); err != nil || len(imported.IDs) != 2 || imported.Skipped != 0 {
+		t.Fatalf("unexpected import result: %+v, %+v", imported, err)
+	}
+
+	// Importing an overlapping statement again only saves the new transactions.
+	statement = strings.Replace(statement, "</BANKTRANLIST>",
+		"<STMTTRN><TRNTYPE>DEBIT<DTPOSTED>20220303<TRNAMT>-7.90<FITID>A3<NAME>Coffee</STMTTRN>\n</BANKTRANLIST>", 1)
+	response = doTestUpload(t, handler, "/api/imports/qfx", fields, statement)
+	if response.CustomCode != "TRANSACTIONS_IMPORTED" {
+		t.Fatalf("expected TRANSACTIONS_IMPORTED, got: %s", response.CustomCode)
+	}
+	if err := json.Unmarshal(response.Data, &imported); err != nil || len(imported.IDs) != 1 || imported.Skipped != 2 {
+		t.Fatalf("unexpected import result: %+v, %+v", imported, err)
+	}
+
+	// The QIF records get their own categories, if they exist.
+	qif := "!Type:CCard\nD03/04/2022\nT-15.00\nPCinema\nLLuxury:Movies\n^\nD03/05/2022\nT-5.00\nPBus\nLTravel\n^\n"
+	fields = map[string]string{"account_id": "card", "credit_category": "earnings", "debit_category": "essentials"}
+	response = doTestUpload(t, handler, "/api/imports/qif", fields, qif)
+	if response.CustomCode != "IMPORT_PREVIEWED" {
+		t.Fatalf("expected IMPORT_PREVIEWED, got: %s", response.CustomCode)
+	}
+	var preview struct {
+		Transactions []struct {
+			Category string `json:"category"`
+		} `json:"transactions"`
+	}
+	if err := json.Unmarshal(response.Data, &preview); err != nil || len(preview.Transactions) != 2 ||
+		preview.Transactions[0].Category != "luxury" || preview.Transactions[1].Category != "essentials" {
+		t.Fatalf("unexpected previewed transactions: %+v, %+v", preview.Transactions, err)
+	}
+
+	balances, err := repos.Accounts.GetAccountBalances(defaultTenantContext())
+	if err != nil {
+		t.Fatalf("unexpected error in GetAccountBalances: %+v", err)
+	}
+	if balances["card"] != 500000 {
+		t.Fatalf("unexpected balances: %+v", balances)
+	}
+}
+
+func TestAPIWithStatementBalances(t *testing.T) {
+	repos := database.NewMemoryRepositories()
+	handler := getHandler(repos, nil)
+
+	if response := doTestRequest(t, handler, http.MethodPost, "/api/accounts", `{"id":"business","name":"Business"}`); response.CustomCode != "ACCOUNT_CREATED" {
+		t.Fatalf("expected ACCOUNT_CREATED, got: %s", response.CustomCode)
+	}
+
+	type importResult struct {
+		Skipped      int `json:"skipped"`
+		BalanceCheck struct {
+			LedgerOpening float64  `json:"ledger_opening"`
+			LedgerClosing float64  `json:"ledger_closing"`
+			Mismatches    []string `json:"mismatches"`
+		} `json:"balance_check"`
+	}
+	fields := map[string]string{"account_id": "business", "credit_category": "earnings", "debit_category": "essentials",
+		"dry_run": "false"}
+
+	// The first statement of a new account reconciles with the ledger.
+	mt940 := ":20:STMT1\n:25:10020030/1234567\n:28C:1/1\n:60F:C220228EUR0,\n" +
+		":61:2203010301C1250,NTRFNONREF//REF-1\n:86:166?00CREDIT?20Invoice 42\n" +
+		":61:2203020302D42,10NMSCNONREF//REF-2\n:86:Groceries\n" +
+		":62F:C220302EUR1207,90\n-\n"
+	response := doTestUpload(t, handler, "/api/imports/mt940", fields, mt940)
+	if response.CustomCode != "TRANSACTIONS_IMPORTED" {
+		t.Fatalf("expected TRANSACTIONS_IMPORTED, got: %s", response.CustomCode)
+	}
+	var result importResult
+	if err := json.Unmarshal(response.Data, &result); err != nil || len(result.BalanceCheck.Mismatches) != 0 ||
+		result.BalanceCheck.LedgerClosing != 1207.9 {
+		t.Fatalf("unexpected import result: %+v, %+v", result, err)
+	}
+
+	// The next statement, as camt.053, misses a transaction that the ledger does not have.
+	camt := `<Document><BkToCstmrStmt><Stmt>
+		<Bal><Tp><CdOrPrtry><Cd>OPBD</Cd></CdOrPrtry></Tp><Amt Ccy="EUR">1200.00</Amt><CdtDbtInd>CRDT</CdtDbtInd></Bal>
+		<Bal><Tp><CdOrPrtry><Cd>CLBD</Cd></CdOrPrtry></Tp><Amt Ccy="EUR">1190.00</Amt><CdtDbtInd>CRDT</CdtDbtInd></Bal>
+		<Ntry><Amt Ccy="EUR">42.10</Amt><CdtDbtInd>DBIT</CdtDbtInd><BookgDt><Dt>2022-03-02</Dt></BookgDt>
+			<AcctSvcrRef>REF-2</AcctSvcrRef></Ntry>
+		<Ntry><Amt Ccy="EUR">10.00</Amt><CdtDbtInd>DBIT</CdtDbtInd><BookgDt><Dt>2022-03-03</Dt></BookgDt>
+			<AcctSvcrRef>REF-3</AcctSvcrRef><NtryDtls><TxDtls><RmtInf><Ustrd>Fees</Ustrd></RmtInf></TxDtls></NtryDtls></Ntry>
+	</Stmt></BkToCstmrStmt></Document>`
+	delete(fields, "dry_run")
+	response = doTestUpload(t, handler, "/api/imports/camt053", fields, camt)
+	if response.CustomCode != "IMPORT_PREVIEWED" {
+		t.Fatalf("expected IMPORT_PREVIEWED, got: %s", response.CustomCode)
+	}
+	result = importResult{}
+	if err := json.Unmarshal(response.Data, &result); err != nil || result.Skipped != 1 ||
+		result.BalanceCheck.LedgerOpening != 1250 || result.BalanceCheck.LedgerClosing != 1197.9 {
+		t.Fatalf("unexpected import result: %+v, %+v", result, err)
+	}
+	if mismatches := result.BalanceCheck.Mismatches; len(mismatches) != 3 ||
+		!strings.HasPrefix(mismatches[1], "opening balance of the statement is 1200") {
+		t.Fatalf("unexpected mismatches: %+v", mismatches)
+	}
+}
+
+func TestAPIWithDuplicateTransactions(t *testing.T) {
+	handler := getHandler(database.NewMemoryRepositories(), nil)
+
+	if response := doTestRequest(t, handler, http.MethodPost, "/api/accounts", `{"id":"bank","name":"Bank"}`); response.CustomCode != "ACCOUNT_CREATED" {
+		t.Fatalf("expected ACCOUNT_CREATED, got: %s", response.CustomCode)
+	}
+
+	body := `{"amount":-12.5,"timestamp":1646092800,"account_id":"bank","category":"essentials","notes":"Coffee"}`
+	if response := doTestRequest(t, handler, http.MethodPost, "/api/transactions", body); response.CustomCode != "TRANSACTION_CREATED" {
+		t.Fatalf("expected TRANSACTION_CREATED, got: %s", response.CustomCode)
+	}
+
+	// The same payment, a day later and with the notes of the bank.
+	body = `{"amount":-12.5,"timestamp":1646179200,"account_id":"bank","category":"essentials","notes":"COFFEE SHOP 42"}`
+	if response := doTestRequest(t, handler, http.MethodPost, "/api/transactions", body); response.CustomCode != "DUPLICATE_TRANSACTION" {
+		t.Fatalf("expected DUPLICATE_TRANSACTION, got: %s", response.CustomCode)
+	}
+
+	response := doTestRequest(t, handler, http.MethodPost, "/api/transactions?allow_duplicates=true", body)
+	if response.CustomCode != "TRANSACTION_CREATED" {
+		t.Fatalf("expected TRANSACTION_CREATED, got: %s", response.CustomCode)
+	}
+	var created struct {
+		ID           string   `json:"id"`
+		DuplicateIDs []string `json:"duplicate_ids"`
+	}
+	if err := json.Unmarshal(response.Data, &created); err != nil || len(created.DuplicateIDs) != 1 {
+		t.Fatalf("unexpected created transaction: %+v, %+v", created, err)
+	}
+
+	// The imports report the duplicates too.
+	statement := "OFXHEADER:100\n<OFX><BANKTRANLIST>\n" +
+		"<STMTTRN><DTPOSTED>20220302<TRNAMT>-12.50<FITID>C1<NAME>Coffee Shop</STMTTRN>\n" +
+		"</BANKTRANLIST></OFX>\n"
+	fields := map[string]string{"account_id": "bank", "credit_category": "earnings", "debit_category": "essentials",
+		"dry_run": "false"}
+	if response := doTestUpload(t, handler, "/api/imports/ofx", fields, statement); response.CustomCode != "DUPLICATE_TRANSACTION" {
+		t.Fatalf("expected DUPLICATE_TRANSACTION, got: %s", response.CustomCode)
+	}
+
+	response = doTestRequest(t, handler, http.MethodGet, "/api/transactions/duplicates", "")
+	if response.CustomCode != "DUPLICATES_LISTED" {
+		t.Fatalf("expected DUPLICATES_LISTED, got: %s", response.CustomCode)
+	}
+	var listed struct {
+		Groups [][]struct {
+			ID string `json:"id"`
+		} `json:"groups"`
+	}
+	if err := json.Unmarshal(response.Data, &listed); err != nil || len(listed.Groups) != 1 || len(listed.Groups[0]) != 2 ||
+		listed.Groups[0][1].ID != created.ID {
+		t.Fatalf("unexpected duplicate groups: %+v, %+v", listed, err)
+	}
+}
+
+func TestAPIWithRules(t *testing.T) {
+	handler := getHandler(database.NewMemoryRepositories(), nil)
+
+	if response := doTestRequest(t, handler, http.MethodPost, "/api/accounts", `{"id":"bank","name":"Bank"}`); response.CustomCode != "ACCOUNT_CREATED" {
+		t.Fatalf("expected ACCOUNT_CREATED, got: %s", response.CustomCode)
+	}
+
+	// A transaction from before the rule exists.
+	var existing struct {
+		ID string `json:"id"`
+	}
+	body := `{"amount":-25,"timestamp":1640995200,"account_id":"bank","category":"essentials","notes":"Uber airport"}`
+	response := doTestRequest(t, handler, http.MethodPost, "/api/transactions", body)
+	if err := json.Unmarshal(response.Data, &existing); err != nil || response.CustomCode != "TRANSACTION_CREATED" {
+		t.Fatalf("expected TRANSACTION_CREATED, got: %s, %+v", response.CustomCode, err)
+	}
+
+	if response := doTestRequest(t, handler, http.MethodPost, "/api/rules", `{"name":"Bad","notes_pattern":"(uber","set_category":"luxury"}`); response.CustomCode != "BAD_REQUEST" {
+		t.Fatalf("expected BAD_REQUEST, got: %s", response.CustomCode)
+	}
+
+	var rule struct {
+		ID string `json:"id"`
+	}
+	body = `{"name":"Uber","priority":1,"notes_pattern":"(?i)^uber (\\w+)$","max_amount":-0.01,"set_category":"luxury",` +
+		`"set_notes":"Uber: $1","add_tags":["Travel"]}`
+	response = doTestRequest(t, handler, http.MethodPost, "/api/rules", body)
+	if err := json.Unmarshal(response.Data, &rule); err != nil || response.CustomCode != "RULE_CREATED" {
+		t.Fatalf("expected RULE_CREATED, got: %s, %+v", response.CustomCode, err)
+	}
+
+	// The rule fills in the category of a new transaction.
+	var created struct {
+		ID string `json:"id"`
+	}
+	body = `{"amount":-10,"timestamp":1646092800,"account_id":"bank","notes":"UBER office"}`
+	response = doTestRequest(t, handler, http.MethodPost, "/api/transactions", body)
+	if err := json.Unmarshal(response.Data, &created); err != nil || response.CustomCode != "TRANSACTION_CREATED" {
+		t.Fatalf("expected TRANSACTION_CREATED, got: %s, %+v", response.CustomCode, err)
+	}
+
+	var transaction struct {
+		Category string   `json:"category"`
+		Notes    string   `json:"notes"`
+		Tags     []string `json:"tags"`
+	}
+	response = doTestRequest(t, handler, http.MethodGet, "/api/transactions/"+created.ID, "")
+	if err := json.Unmarshal(response.Data, &transaction); err != nil || transaction.Category != "luxury" ||
+		transaction.Notes != "Uber: office" || len(transaction.Tags) != 1 || transaction.Tags[0] != "travel" {
+		t.Fatalf("unexpected transaction: %+v, %+v", transaction, err)
+	}
+
+	// Re-running the rules is a dry run by default. The rewritten notes do not match the rule anymore, so only the
+	// existing transaction changes.
+	var applied struct {
+		Changes []struct {
+			ID       string `json:"id"`
+			Category *struct {
+				From string `json:"from"`
+				To   string `json:"to"`
+			} `json:"category"`
+		} `json:"changes"`
+	}
+	response = doTestRequest(t, handler, http.MethodPost, "/api/rules/apply?account_id=bank", "")
+	if err := json.Unmarshal(response.Data, &applied); err != nil || response.CustomCode != "RULES_PREVIEWED" {
+		t.Fatalf("expected RULES_PREVIEWED, got: %s, %+v", response.CustomCode, err)
+	}
+	if len(applied.Changes) != 1 || applied.Changes[0].ID != existing.ID || applied.Changes[0].Category == nil ||
+		applied.Changes[0].Category.From != "essentials" || applied.Changes[0].Category.To != "luxury" {
+		t.Fatalf("unexpected changes: %+v", applied.Changes)
+	}
+
+	response = doTestRequest(t, handler, http.MethodGet, "/api/transactions/"+existing.ID, "")
+	if err := json.Unmarshal(response.Data, &transaction); err != nil || transaction.Category != "essentials" {
+		t.Fatalf("unexpected transaction: %+v, %+v", transaction, err)
+	}
+
+	response = doTestRequest(t, handler, http.MethodPost, "/api/rules/apply?account_id=bank&dry_run=false", "")
+	if err := json.Unmarshal(response.Data, &applied); err != nil || response.CustomCode != "RULES_APPLIED" || len(applied.Changes) != 1 {
+		t.Fatalf("expected RULES_APPLIED, got: %s, %+v, %+v", response.CustomCode, applied.Changes, err)
+	}
+
+	response = doTestRequest(t, handler, http.MethodGet, "/api/transactions/"+existing.ID, "")
+	if err := json.Unmarshal(response.Data, &transaction); err != nil || transaction.Category != "luxury" ||
+		transaction.Notes != "Uber: airport" || len(transaction.Tags) != 1 {
+		t.Fatalf("unexpected transaction: %+v, %+v", transaction, err)
+	}
+
+	// The rules make no more changes once applied.
+	response = doTestRequest(t, handler, http.MethodPost, "/api/rules/apply", "")
+	if err := json.Unmarshal(response.Data, &applied); err != nil || len(applied.Changes) != 0 {
+		t.Fatalf("unexpected changes: %+v, %+v", applied.Changes, err)
+	}
+
+	if response := doTestRequest(t, handler, http.MethodDelete, "/api/rules/"+rule.ID, ""); response.CustomCode != "RULE_DELETED" {
+		t.Fatalf("expected RULE_DELETED, got: %s", response.CustomCode)
+	}
+	if response := doTestRequest(t, handler, http.MethodDelete, "/api/rules/"+rule.ID, ""); response.CustomCode != "RULE_NOT_FOUND" {
+		t.Fatalf("expected RULE_NOT_FOUND, got: %s", response.CustomCode)
+	}
+}
+
+func TestAPIWithExports(t *testing.T) {
+	handler := getHandler(database.NewMemoryRepositories(), nil)
+
+	for _, body := range []string{`{"id":"bank","name":"Bank"}`, `{"id":"wallet","name":"Wallet","currency":"USD"}`} {
+		if response := doTestRequest(t, handler, http.MethodPost, "/api/accounts", body); response.CustomCode != "ACCOUNT_CREATED" {
+			t.Fatalf("expected ACCOUNT_CREATED, got: %s", response.CustomCode)
+		}
+	}
+
+	for _, body := range []string{
+		`{"amount":1000,"timestamp":1646092800,"account_id":"bank","category":"earnings","notes":"Salary"}`,
+		`{"amount":-100.5,"timestamp":1646179200,"account_id":"bank","category":"essentials","notes":"Groceries, \"fresh\""}`,
+		`{"amount":-5,"timestamp":1646179200,"account_id":"wallet","category":"luxury","notes":"Coffee"}`,
+		`{"amount":-50,"timestamp":1646265600,"account_id":"bank","category":"luxury","notes":"Dinner","tags":["food","weekend"]}`,
+	} {
+		if response := doTestRequest(t, handler, http.MethodPost, "/api/transactions", body); response.CustomCode != "TRANSACTION_CREATED" {
+			t.Fatalf("expected TRANSACTION_CREATED, got: %s", response.CustomCode)
+		}
+	}
+
+	if response := doTestRequest(t, handler, http.MethodGet, "/api/exports/transactions?format=pdf", ""); response.CustomCode != "BAD_REQUEST" {
+		t.Fatalf("expected BAD_REQUEST, got: %s", response.CustomCode)
+	}
+
+	// CSV is the default format. The closing balances include the transactions that the filters leave out.
+	recorder := doTestExport(t, handler, "/api/exports/transactions?category=luxury")
+	if recorder.Header().Get("content-type") != "text/csv" ||
+		recorder.Header().Get("content-disposition") != `attachment; filename="transactions.csv"` {
+		t.Fatalf("unexpected headers: %+v", recorder.Header())
+	}
+	records, err := csv.NewReader(recorder.Body).ReadAll()
+	if err != nil || len(records) != 3 {
+		t.Fatalf("unexpected records: %+v, %+v", records, err)
+	}
+	if strings.Join(records[0], ",") != "id,date,timestamp,account_id,currency,category,amount,closing_bal,notes,tags,"+
+		"transfer_id,external_id" {
+		t.Fatalf("unexpected header: %+v", records[0])
+	}
+	if records[1][3] != "wallet" || records[1][4] != "USD" || records[1][6] != "-5" || records[1][7] != "-5" {
+		t.Fatalf("unexpected record: %+v", records[1])
+	}
+	if records[2][1] != "2022-03-03 00:00:00" || records[2][3] != "bank" || records[2][7] != "849.5" ||
+		records[2][9] != "food,weekend" {
+		t.Fatalf("unexpected record: %+v", records[2])
+	}
+
+	recorder = doTestExport(t, handler, "/api/exports/transactions?format=jsonl&account_id=bank&limit=1")
+	type exportedRow struct {
+		Notes      string  `json:"notes"`
+		ClosingBal float64 `json:"closing_bal"`
+	}
+	var rows []exportedRow
+	for _, line := range strings.Split(strings.TrimSpace(recorder.Body.String()), "\n") {
+		var row exportedRow
+		if err := json.Unmarshal([]byte(line), &row); err != nil {
+			t.Fatalf("failed to decode line %s: %+v", line, err)
+		}
+		rows = append(rows, row)
+	}
+	// The pagination params are ignored.
+	if len(rows) != 3 || rows[1].Notes != `Groceries, "fresh"` || rows[1].ClosingBal != 899.5 || rows[2].ClosingBal != 849.5 {
+		t.Fatalf("unexpected rows: %+v", rows)
+	}
+
+	recorder = doTestExport(t, handler, "/api/exports/transactions?format=xlsx")
+	archive, err := zip.NewReader(bytes.NewReader(recorder.Body.Bytes()), int64(recorder.Body.Len()))
+	if err != nil {
+		t.Fatalf("failed to open xlsx: %+v", err)
+	}
+	var sheet string
+	for _, file := range archive.File {
+		if file.Name != "xl/worksheets/sheet1.xml" {
+			continue
+		}
+		reader, err := file.Open()
+		if err != nil {
+			t.Fatalf("failed to open sheet: %+v", err)
+		}
+		content, _ := ioutil.ReadAll(reader)
+		_ = reader.Close()
+		sheet = string(content)
+	}
+	if !strings.Contains(sheet, `<row r="5">`) || strings.Contains(sheet, `<row r="6">`) ||
+		!strings.Contains(sheet, `<c r="H5"><v>849.5</v></c>`) {
+		t.Fatalf("unexpected sheet: %s", sheet)
+	}
+}
+
+// doTestExport sends an authenticated export request to the handler, and expects it to succeed.
+func doTestExport(t *testing.T, handler http.Handler, path string) *httptest.ResponseRecorder {
+	t.Helper()
+
+	request := httptest.NewRequest(http.MethodGet, path, nil)
+	request.SetBasicAuth(testutils.Username, testutils.Password)
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, request)
+
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("expected status 200 for %s, got: %d, %s", path, recorder.Code, recorder.Body.String())
+	}
+	return recorder
+}
+
+func TestAPIWithJournalExport(t *testing.T) {
+	handler := getHandler(database.NewMemoryRepositories(), nil)
+
+	for _, body := range []string{`{"id":"bank","name":"Bank"}`, `{"id":"travel","name":"Travel","currency":"USD"}`} {
+		if response := doTestRequest(t, handler, http.MethodPost, "/api/accounts", body); response.CustomCode != "ACCOUNT_CREATED" {
+			t.Fatalf("expected ACCOUNT_CREATED, got: %s", response.CustomCode)
+		}
+	}
+
+	for path, body := range map[string]string{
+		"/api/transactions": `{"amount":1000,"timestamp":1646128800,"account_id":"bank","category":"earnings","notes":"Salary"}`,
+		"/api/transfers": `{"amount":830,"to_amount":10,"timestamp":1646301600,"from_account_id":"bank",` +
+			`"to_account_id":"travel","notes":"Travel money"}`,
+	} {
+		if response := doTestRequest(t, handler, http.MethodPost, path, body); response.StatusCode != http.StatusCreated {
+			t.Fatalf("expected status 201 for %s, got: %s", path, response.CustomCode)
+		}
+	}
+
+	body := `{"amount":-100,"timestamp":1646215200,"account_id":"bank","notes":"Supermarket","splits":[` +
+		`{"amount":-70,"category":"essentials","notes":"Vegetables"},{"amount":-30,"category":"luxury"}]}`
+	if response := doTestRequest(t, handler, http.MethodPost, "/api/transactions", body); response.CustomCode != "TRANSACTION_CREATED" {
+		t.Fatalf("expected TRANSACTION_CREATED, got: %s", response.CustomCode)
+	}
+
+	if response := doTestRequest(t, handler, http.MethodGet, "/api/exports/journal?format=gnucash", ""); response.CustomCode != "BAD_REQUEST" {
+		t.Fatalf("expected BAD_REQUEST, got: %s", response.CustomCode)
+	}
+
+	recorder := doTestExport(t, handler, "/api/exports/journal?format=beancount")
+	if recorder.Header().Get("content-disposition") != `attachment; filename="ledgerkeep.beancount"` {
+		t.Fatalf("unexpected headers: %+v", recorder.Header())
+	}
+	for _, expected := range []string{
+		"2022-03-01 open Assets:Bank INR\n",
+		"2022-03-01 open Assets:Travel USD\n",
+		"2022-03-01 open Income:Earnings\n",
+		"2022-03-01 * \"Salary\"\n",
+		"  Assets:Bank  -100 INR\n  Expenses:Essentials  70 INR  ; Vegetables\n  Expenses:Luxury  30 INR\n",
+		"  Assets:Bank  -830 INR\n  Assets:Travel  10 USD @@ 830 INR\n",
+	} {
+		if !strings.Contains(recorder.Body.String(), expected) {
+			t.Fatalf("expected journal to contain %q, but got:\n%s", expected, recorder.Body.String())
+		}
+	}
+
+	// The balance before the start time is the opening balance. The legs of the transfers with the other accounts are
+	// balanced by the equity account.
+	recorder = doTestExport(t, handler, "/api/exports/journal?format=hledger&account_id=bank&start_time=1646200000")
+	journal := recorder.Body.String()
+	for _, expected := range []string{
+		"2022-03-02 * Opening balance\n    Assets:Bank  1000 INR\n    Equity:Opening-Balances  -1000 INR\n",
+		"    Assets:Bank  -830 INR\n    Equity:Transfers  830 INR\n",
+	} {
+		if !strings.Contains(journal, expected) {
+			t.Fatalf("expected journal to contain %q, but got:\n%s", expected, journal)
+		}
+	}
+	if strings.Contains(journal, "Salary") || strings.Contains(journal, "Assets:Travel") {
+		t.Fatalf("unexpected journal:\n%s", journal)
+	}
+}
+
+func TestAPIWithJournalImport(t *testing.T) {
+	repos := database.NewMemoryRepositories()
+	handler := getHandler(repos, nil)
+
+	// The existing accounts are reused.
+	body := `{"id":"assets-bank-checking","name":"Checking"}`
+	if response := doTestRequest(t, handler, http.MethodPost, "/api/accounts", body); response.CustomCode != "ACCOUNT_CREATED" {
+		t.Fatalf("expected ACCOUNT_CREATED, got: %s", response.CustomCode)
+	}
+
+	journal := "2022-01-01 open Assets:Bank:Checking INR\n" +
+		"2022-01-01 open Liabilities:Credit-Card INR\n" +
+		"\n" +
+		"2022-03-01 * \"ACME\" \"Salary\" #Work\n" +
+		"  Assets:Bank:Checking  1,000 INR\n" +
+		"  Income:Salary\n" +
+		"\n" +
+		"2022-03-02 * \"Store\" \"Groceries\"\n" +
+		"  Liabilities:Credit-Card  -100 INR\n" +
+		"  Expenses:Food  70 INR ; Vegetables\n" +
+		"  Expenses:Fun:Movies  30 INR\n" +
+		"\n" +
+		"2022-03-03 * \"Card bill\"\n" +
+		"  Assets:Bank:Checking  -100 INR\n" +
+		"  Liabilities:Credit-Card\n" +
+		"\n" +
+		"2022-03-04 price USD 83 INR\n"
+	fields := map[string]string{"mapping": `{"Income":"earnings","Expenses":"essentials","Expenses:Fun":"luxury"}`}
+
+	if response := doTestUpload(t, handler, "/api/imports/journal/gnucash", fields, journal); response.CustomCode != "BAD_REQUEST" {
+		t.Fatalf("expected BAD_REQUEST, got: %s", response.CustomCode)
+	}
+	badFields := map[string]string{"mapping": `{"Income":"salary"}`}
+	if response := doTestUpload(t, handler, "/api/imports/journal/beancount", badFields, journal); response.CustomCode != "BAD_REQUEST" {
+		t.Fatalf("expected BAD_REQUEST, got: %s", response.CustomCode)
+	}
+
+	var preview struct {
+		Transactions []struct {
+			AccountID  string   `json:"account_id"`
+			Category   string   `json:"category"`
+			Notes      string   `json:"notes"`
+			Tags       []string `json:"tags"`
+			TransferID string   `json:"transfer_id"`
+		} `json:"transactions"`
+		Errors   []string `json:"errors"`
+		Accounts []struct {
+			ID       string `json:"id"`
+			Name     string `json:"name"`
+			Currency string `json:"currency"`
+		} `json:"accounts"`
+		Untranslated []string `json:"untranslated"`
+	}
+	response := doTestUpload(t, handler, "/api/imports/journal/beancount", fields, journal)
+	if response.CustomCode != "IMPORT_PREVIEWED" {
+		t.Fatalf("expected IMPORT_PREVIEWED, got: %s", response.CustomCode)
+	}
+	if err := json.Unmarshal(response.Data, &preview); err != nil || len(preview.Transactions) != 4 || len(preview.Errors) != 0 {
+		t.Fatalf("unexpected preview: %+v, %+v", preview, err)
+	}
+	salary, groceries, bill := preview.Transactions[0], preview.Transactions[1], preview.Transactions[2]
+	if salary.AccountID != "assets-bank-checking" || salary.Category != "earnings" || salary.Notes != "ACME - Salary" ||
+		len(salary.Tags) != 1 || salary.Tags[0] != "work" {
+		t.Fatalf("unexpected transaction: %+v", salary)
+	}
+	if groceries.AccountID != "liabilities-credit-card" || groceries.Category != "split" {
+		t.Fatalf("unexpected transaction: %+v", groceries)
+	}
+	if bill.Category != "transfer" || bill.TransferID == "" || bill.TransferID != preview.Transactions[3].TransferID {
+		t.Fatalf("unexpected transfer: %+v, %+v", bill, preview.Transactions[3])
+	}
+	if len(preview.Accounts) != 1 || preview.Accounts[0].ID != "liabilities-credit-card" ||
+		preview.Accounts[0].Name != "Credit-Card" || preview.Accounts[0].Currency != "INR" {
+		t.Fatalf("unexpected accounts: %+v", preview.Accounts)
+	}
+	if len(preview.Untranslated) != 1 || preview.Untranslated[0] != "line 17: price directive is not translated" {
+		t.Fatalf("unexpected untranslated directives: %+v", preview.Untranslated)
+	}
+
+	// The postings to the accounts that are not mapped are errors.
+	fields["mapping"] = `{"Income":"earnings","Expenses:Food":"essentials"}`
+	fields["dry_run"] = "false"
+	if response = doTestUpload(t, handler, "/api/imports/journal/beancount", fields, journal); response.CustomCode != "BAD_REQUEST" {
+		t.Fatalf("expected BAD_REQUEST, got: %s", response.CustomCode)
+	}
+
+	fields["mapping"] = `{"Income":"earnings","Expenses":"essentials","Expenses:Fun":"luxury"}`
+	response = doTestUpload(t, handler, "/api/imports/journal/beancount", fields, journal)
+	if response.CustomCode != "TRANSACTIONS_IMPORTED" {
+		t.Fatalf("expected TRANSACTIONS_IMPORTED, got: %s", response.CustomCode)
+	}
+	var imported struct {
+		IDs        []string `json:"ids"`
+		AccountIDs []string `json:"account_ids"`
+	}
+	if err := json.Unmarshal(response.Data, &imported); err != nil || len(imported.IDs) != 4 ||
+		len(imported.AccountIDs) != 1 || imported.AccountIDs[0] != "liabilities-credit-card" {
+		t.Fatalf("unexpected import result: %+v, %+v", imported, err)
+	}
+
+	balances, err := repos.Accounts.GetAccountBalances(defaultTenantContext())
+	if err != nil {
+		t.Fatalf("unexpected error in GetAccountBalances: %+v", err)
+	}
+	if balances["assets-bank-checking"] != 9000000 || balances["liabilities-credit-card"] != 0 {
+		t.Fatalf("unexpected balances: %+v", balances)
+	}
+
+	// A ledger-cli journal, in an account of another currency. The repeated import looks like a duplicate.
+	journal = "2022/03/05 Coffee\n    Assets:Travel Wallet    $4.50\n    Income:Refunds\n"
+	fields = map[string]string{"mapping": `{"Income":"refunds"}`, "dry_run": "false"}
+	for _, expected := range []string{"TRANSACTIONS_IMPORTED", "DUPLICATE_TRANSACTION"} {
+		response = doTestUpload(t, handler, "/api/imports/journal/ledger", fields, journal)
+		if response.CustomCode != expected {
+			t.Fatalf("expected %s, got: %s", expected, response.CustomCode)
+		}
+	}
+
+	account, err := repos.Accounts.GetAccount(defaultTenantContext(), "assets-travel-wallet")
+	if err != nil || account.Name != "Travel Wallet" || account.Currency != "USD" {
+		t.Fatalf("unexpected account: %+v, %+v", account, err)
+	}
+}
+
+func TestAPIWithBackups(t *testing.T) {
+	repos := database.NewMemoryRepositories()
+	handler := getHandler(repos, nil)
+
+	for _, body := range []string{`{"id":"bank","name":"Bank"}`, `{"id":"travel","name":"Travel","currency":"USD"}`} {
+		if response := doTestRequest(t, handler, http.MethodPost, "/api/accounts", body); response.CustomCode != "ACCOUNT_CREATED" {
+			t.Fatalf("expected ACCOUNT_CREATED, got: %s", response.CustomCode)
+		}
+	}
+
+	for path, body := range map[string]string{
+		"/api/transactions": `{"amount":-100,"timestamp":1646215200,"account_id":"bank","notes":"Supermarket","splits":[` +
+			`{"amount":-70,"category":"essentials"},{"amount":-30,"category":"luxury"}]}`,
+		"/api/transfers": `{"amount":830,"to_amount":10,"timestamp":1646301600,"from_account_id":"bank",` +
+			`"to_account_id":"travel","notes":"Travel money"}`,
+		"/api/rules": `{"name":"Groceries","priority":1,"notes_pattern":"(?i)market","set_category":"essentials"}`,
+	} {
+		if response := doTestRequest(t, handler, http.MethodPost, path, body); response.StatusCode != http.StatusCreated {
+			t.Fatalf("expected status 201 for %s, got: %s", path, response.CustomCode)
+		}
+	}
+
+	recorder := doTestExport(t, handler, "/api/admin/backup")
+	if recorder.Header().Get("content-type") != "application/zip" ||
+		!strings.HasPrefix(recorder.Header().Get("content-disposition"), `attachment; filename="ledgerkeep-backup-`) {
+		t.Fatalf("unexpected headers: %+v", recorder.Header())
+	}
+	backup := recorder.Body.String()
+
+	// The backup is restored into another ledger, which has data already.
+	restoredRepos := database.NewMemoryRepositories()
+	restoredHandler := getHandler(restoredRepos, nil)
+	if response := doTestRequest(t, restoredHandler, http.MethodPost, "/api/accounts", `{"id":"cash","name":"Cash"}`); response.CustomCode != "ACCOUNT_CREATED" {
+		t.Fatalf("expected ACCOUNT_CREATED, got: %s", response.CustomCode)
+	}
+
+	if response := doTestUpload(t, restoredHandler, "/api/admin/restore", nil, "not a backup"); response.CustomCode != "BAD_REQUEST" {
+		t.Fatalf("expected BAD_REQUEST, got: %s", response.CustomCode)
+	}
+	if response := doTestUpload(t, restoredHandler, "/api/admin/restore", nil, backup); response.CustomCode != "LEDGER_NOT_EMPTY" {
+		t.Fatalf("expected LEDGER_NOT_EMPTY, got: %s", response.CustomCode)
+	}
+
+	response := doTestUpload(t, restoredHandler, "/api/admin/restore", map[string]string{"overwrite": "true"}, backup)
+	if response.CustomCode != "LEDGER_RESTORED" {
+		t.Fatalf("expected LEDGER_RESTORED, got: %s", response.CustomCode)
+	}
+	var counts map[string]int
+	if err := json.Unmarshal(response.Data, &counts); err != nil || counts["accounts"] != 2 || counts["transactions"] != 3 ||
+		counts["rules"] != 1 || counts["categories"] != 9 {
+		t.Fatalf("unexpected restore result: %+v, %+v", counts, err)
+	}
+
+	ctx := defaultTenantContext()
+	if _, err := restoredRepos.Accounts.GetAccount(ctx, "cash"); err == nil {
+		t.Fatalf("expected the account to be replaced")
+	}
+
+	balances, err := repos.Accounts.GetAccountBalances(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error in GetAccountBalances: %+v", err)
+	}
+	restoredBalances, err := restoredRepos.Accounts.GetAccountBalances(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error in GetAccountBalances: %+v", err)
+	}
+	if len(restoredBalances) != 2 || restoredBalances["bank"] != balances["bank"] ||
+		restoredBalances["travel"] != balances["travel"] {
+		t.Fatalf("unexpected balances: %+v, expected: %+v", restoredBalances, balances)
+	}
+
+	// The IDs are kept, so the transactions can be fetched by the IDs of the original ledger.
+	var listed []struct {
+		ID     string            `json:"id"`
+		Splits []json.RawMessage `json:"splits"`
+	}
+	response = doTestRequest(t, handler, http.MethodGet, "/api/transactions?category=split", "")
+	if err := json.Unmarshal(response.Data, &listed); err != nil || len(listed) != 1 {
+		t.Fatalf("unexpected transactions: %+v, %+v", listed, err)
+	}
+	transaction, err := restoredRepos.Transactions.GetTransaction(ctx, listed[0].ID)
+	if err != nil || len(transaction.Splits) != 2 || transaction.Amount != -1000000 {
+		t.Fatalf("unexpected transaction: %+v, %+v", transaction, err)
+	}
+}
+
+func TestAPIWithUsers(t *testing.T) {
+	handler := getHandler(database.NewMemoryRepositories(), nil)
+
+	response := doTestRequest(t, handler, http.MethodPost, "/api/users", `{"id":"alice","password":"alice-pass"}`)
+	if response.CustomCode != "USER_CREATED" {
+		t.Fatalf("expected USER_CREATED, got: %s", response.CustomCode)
+	}
+	if strings.Contains(string(response.Data), "password") {
+		t.Fatalf("expected no password in the response, got: %s", response.Data)
+	}
+
+	response = doTestRequest(t, handler, http.MethodPost, "/api/users", `{"id":"alice","password":"alice-pass"}`)
+	if response.CustomCode != "USER_ALREADY_EXISTS" {
+		t.Fatalf("expected USER_ALREADY_EXISTS, got: %s", response.CustomCode)
+	}
+	response = doTestRequest(t, handler, http.MethodPost, "/api/users", `{"id":"bob","password":"short"}`)
+	if response.CustomCode != "BAD_REQUEST" {
+		t.Fatalf("expected BAD_REQUEST, got: %s", response.CustomCode)
+	}
+
+	// Only the admins can manage the users.
+	response = doTestRequestAs(t, handler, "alice", "alice-pass", http.MethodGet, "/api/users", "")
+	if response.CustomCode != "FORBIDDEN" {
+		t.Fatalf("expected FORBIDDEN, got: %s", response.CustomCode)
+	}
+	response = doTestRequestAs(t, handler, "alice", "alice-pass", http.MethodPost, "/api/users",
+		`{"id":"bob","password":"bob-password"}`)
+	if response.CustomCode != "FORBIDDEN" {
+		t.Fatalf("expected FORBIDDEN, got: %s", response.CustomCode)
+	}
+
+	for _, credentials := range [][2]string{{"alice", "wrong-pass"}, {"bob", "bob-password"}} {
+		response = doTestRequestAs(t, handler, credentials[0], credentials[1], http.MethodGet, "/api/accounts", "")
+		if response.CustomCode != "UNAUTHORIZED" {
+			t.Fatalf("expected UNAUTHORIZED for %s, got: %s", credentials[0], response.CustomCode)
+		}
+	}
+
+	// The ledgers of the users are separate, even if they use the same account IDs.
+	for _, credentials := range [][2]string{{testutils.Username, testutils.Password}, {"alice", "alice-pass"}} {
+		response = doTestRequestAs(t, handler, credentials[0], credentials[1], http.MethodPost, "/api/accounts",
+			`{"id":"bank","name":"Bank"}`)
+		if response.CustomCode != "ACCOUNT_CREATED" {
+			t.Fatalf("expected ACCOUNT_CREATED for %s, got: %s", credentials[0], response.CustomCode)
+		}
+	}
+
+	response = doTestRequest(t, handler, http.MethodPost, "/api/transactions",
+		`{"amount":-250,"timestamp":200,"account_id":"bank","category":"essentials","notes":"Rent"}`)
+	if response.CustomCode != "TRANSACTION_CREATED" {
+		t.Fatalf("expected TRANSACTION_CREATED, got: %s", response.CustomCode)
+	}
+	var created struct {
+		ID string `json:"id"`
+	}
+	if err := json.Unmarshal(response.Data, &created); err != nil {
+		t.Fatalf("failed to decode created transaction: %+v", err)
+	}
+
+	response = doTestRequestAs(t, handler, "alice", "alice-pass", http.MethodGet, "/api/transactions/"+created.ID, "")
+	if response.CustomCode != "TRANSACTION_NOT_FOUND" {
+		t.Fatalf("expected TRANSACTION_NOT_FOUND, got: %s", response.CustomCode)
+	}
+	response = doTestRequestAs(t, handler, "alice", "alice-pass", http.MethodGet, "/api/transactions", "")
+	if string(response.Data) != "[]" {
+		t.Fatalf("expected no transactions, got: %s", response.Data)
+	}
+
+	// The new ledger has the default categories.
+	response = doTestRequestAs(t, handler, "alice", "alice-pass", http.MethodPost, "/api/transactions",
+		`{"amount":-10,"timestamp":300,"account_id":"bank","category":"luxury","notes":"Cinema"}`)
+	if response.CustomCode != "TRANSACTION_CREATED" {
+		t.Fatalf("expected TRANSACTION_CREATED, got: %s", response.CustomCode)
+	}
+
+	response = doTestRequestAs(t, handler, "alice", "alice-pass", http.MethodPatch, "/api/users/me",
+		`{"password":"new-alice-pass"}`)
+	if response.CustomCode != "USER_UPDATED" {
+		t.Fatalf("expected USER_UPDATED, got: %s", response.CustomCode)
+	}
+	response = doTestRequestAs(t, handler, "alice", "alice-pass", http.MethodGet, "/api/users/me", "")
+	if response.CustomCode != "UNAUTHORIZED" {
+		t.Fatalf("expected UNAUTHORIZED with the old password, got: %s", response.CustomCode)
+	}
+
+	response = doTestRequestAs(t, handler, "alice", "new-alice-pass", http.MethodGet, "/api/users/me", "")
+	var user struct {
+		ID      string `json:"id"`
+		IsAdmin bool   `json:"is_admin"`
+	}
+	if err := json.Unmarshal(response.Data, &user); err != nil {
+		t.Fatalf("failed to decode user: %+v", err)
+	}
+	if user.ID != "alice" || user.IsAdmin {
+		t.Fatalf("unexpected user: %+v", user)
+	}
+
+	response = doTestRequest(t, handler, http.MethodGet, "/api/users", "")
+	var users []struct {
+		ID string `json:"id"`
+	}
+	if err := json.Unmarshal(response.Data, &users); err != nil {
+		t.Fatalf("failed to decode users: %+v", err)
+	}
+	if len(users) != 2 || users[0].ID != "alice" || users[1].ID != testutils.Username {
+		t.Fatalf("unexpected users: %+v", users)
+	}
+}
+
+func TestAPIWithSharedLedgers(t *testing.T) {
+	handler := getHandler(database.NewMemoryRepositories(), nil)
+
+	for _, body := range []string{`{"id":"alice","password":"alice-pass"}`, `{"id":"bob","password":"bob-password"}`} {
+		response := doTestRequest(t, handler, http.MethodPost, "/api/users", body)
+		if response.CustomCode != "USER_CREATED" {
+			t.Fatalf("expected USER_CREATED, got: %s", response.CustomCode)
+		}
+	}
+
+	response := doTestRequestAs(t, handler, "alice", "alice-pass", http.MethodGet, "/api/ledgers", "")
+	var ledgers []struct {
+		LedgerID string `json:"ledger_id"`
+		Role     string `json:"role"`
+	}
+	if err := json.Unmarshal(response.Data, &ledgers); err != nil {
+		t.Fatalf("failed to decode ledgers: %+v", err)
+	}
+	if len(ledgers) != 1 || ledgers[0].Role != "owner" {
+		t.Fatalf("unexpected ledgers: %+v", ledgers)
+	}
+	aliceLedger := ledgers[0].LedgerID
+
+	response = doTestRequestAs(t, handler, "alice", "alice-pass", http.MethodPost, "/api/accounts",
+		`{"id":"bank","name":"Bank"}`)
+	if response.CustomCode != "ACCOUNT_CREATED" {
+		t.Fatalf("expected ACCOUNT_CREATED, got: %s", response.CustomCode)
+	}
+
+	// The ledgers that are not shared are forbidden.
+	response = doTestLedgerRequest(t, handler, "bob", "bob-password", aliceLedger, http.MethodGet, "/api/accounts", "")
+	if response.CustomCode != "FORBIDDEN" {
+		t.Fatalf("expected FORBIDDEN, got: %s", response.CustomCode)
+	}
+
+	response = doTestRequestAs(t, handler, "alice", "alice-pass", http.MethodPost, "/api/members",
+		`{"user_id":"alice","role":"viewer"}`)
+	if response.CustomCode != "BAD_REQUEST" {
+		t.Fatalf("expected BAD_REQUEST, got: %s", response.CustomCode)
+	}
+	response = doTestRequestAs(t, handler, "alice", "alice-pass", http.MethodPost, "/api/members",
+		`{"user_id":"bob","role":"viewer"}`)
+	if response.CustomCode != "MEMBER_CREATED" {
+		t.Fatalf("expected MEMBER_CREATED, got: %s", response.CustomCode)
+	}
+
+	// Viewers can read, but not write.
+	response = doTestLedgerRequest(t, handler, "bob", "bob-password", aliceLedger, http.MethodGet, "/api/accounts", "")
+	if !strings.Contains(string(response.Data), `"bank"`) {
+		t.Fatalf("expected the accounts of alice, got: %s", response.Data)
+	}
+	response = doTestLedgerRequest(t, handler, "bob", "bob-password", aliceLedger, http.MethodGet,
+		"/api/stats/budget?start_time=0&end_time=1000", "")
+	if response.CustomCode != "BUDGET_FETCHED" {
+		t.Fatalf("expected BUDGET_FETCHED, got: %s", response.CustomCode)
+	}
+
+	transaction := `{"amount":-25,"timestamp":200,"account_id":"bank","category":"essentials","notes":"Groceries"}`
+	response = doTestLedgerRequest(t, handler, "bob", "bob-password", aliceLedger, http.MethodPost, "/api/transactions",
+		transaction)
+	if response.CustomCode != "FORBIDDEN" {
+		t.Fatalf("expected FORBIDDEN, got: %s", response.CustomCode)
+	}
+
+	// Only the owners can manage the members.
+	response = doTestLedgerRequest(t, handler, "bob", "bob-password", aliceLedger, http.MethodDelete, "/api/members/bob",
+		"")
+	if response.CustomCode != "FORBIDDEN" {
+		t.Fatalf("expected FORBIDDEN, got: %s", response.CustomCode)
+	}
+
+	// Only the owners can download the backups, which have all the data of the ledger.
+	response = doTestLedgerRequest(t, handler, "bob", "bob-password", aliceLedger, http.MethodGet, "/api/admin/backup",
+		"")
+	if response.CustomCode != "FORBIDDEN" {
+		t.Fatalf("expected FORBIDDEN, got: %s", response.CustomCode)
+	}
+
+	response = doTestRequestAs(t, handler, "alice", "alice-pass", http.MethodDelete, "/api/members/bob", "")
+	if response.CustomCode != "MEMBER_DELETED" {
+		t.Fatalf("expected MEMBER_DELETED, got: %s", response.CustomCode)
+	}
+	response = doTestRequestAs(t, handler, "alice", "alice-pass", http.MethodPost, "/api/members",
+		`{"user_id":"bob","role":"editor"}`)
+	if response.CustomCode != "MEMBER_CREATED" {
+		t.Fatalf("expected MEMBER_CREATED, got: %s", response.CustomCode)
+	}
+
+	// Editors can write into the shared ledger, and not into their own.
+	response = doTestLedgerRequest(t, handler, "bob", "bob-password", aliceLedger, http.MethodPost, "/api/transactions",
+		transaction)
+	if response.CustomCode != "TRANSACTION_CREATED" {
+		t.Fatalf("expected TRANSACTION_CREATED, got: %s", response.CustomCode)
+	}
+	response = doTestLedgerRequest(t, handler, "bob", "bob-password", aliceLedger, http.MethodGet, "/api/admin/backup",
+		"")
+	if response.CustomCode != "FORBIDDEN" {
+		t.Fatalf("expected FORBIDDEN for the backup of an editor, got: %s", response.CustomCode)
+	}
+	response = doTestRequestAs(t, handler, "bob", "bob-password", http.MethodGet, "/api/transactions", "")
+	if string(response.Data) != "[]" {
+		t.Fatalf("expected no transactions in the ledger of bob, got: %s", response.Data)
+	}
+
+	response = doTestRequestAs(t, handler, "bob", "bob-password", http.MethodGet, "/api/ledgers", "")
+	if err := json.Unmarshal(response.Data, &ledgers); err != nil {
+		t.Fatalf("failed to decode ledgers: %+v", err)
+	}
+	if len(ledgers) != 2 || ledgers[1].LedgerID != aliceLedger || ledgers[1].Role != "editor" {
+		t.Fatalf("unexpected ledgers: %+v", ledgers)
+	}
+
+	response = doTestRequestAs(t, handler, "alice", "alice-pass", http.MethodDelete, "/api/members/bob", "")
+	if response.CustomCode != "MEMBER_DELETED" {
+		t.Fatalf("expected MEMBER_DELETED, got: %s", response.CustomCode)
+	}
+	response = doTestLedgerRequest(t, handler, "bob", "bob-password", aliceLedger, http.MethodGet, "/api/transactions",
+		"")
+	if response.CustomCode != "FORBIDDEN" {
+		t.Fatalf("expected FORBIDDEN after the revocation, got: %s", response.CustomCode)
+	}
+}
+
+func TestRoutePermissions(t *testing.T) {
+	router, ok := getHandler(database.NewMemoryRepositories(), nil).(*mux.Router)
+	if !ok {
+		t.Fatalf("expected the handler to be a router")
+	}
+
+	// Every route must have a permission, or the Permissions middleware forbids it.
+	err := router.Walk(func(route *mux.Route, _ *mux.Router, _ []*mux.Route) error {
+		pathTemplate, err := route.GetPathTemplate()
+		if err != nil {
+			return err
+		}
+		methods, err := route.GetMethods()
+		if err != nil {
+			return err
+		}
+		for _, method := range methods {
+			if method == http.MethodOptions {
+				continue
+			}
+			if _, exists := middlewares.RoutePermission(method, pathTemplate); !exists {
+				t.Errorf("no permission for the route: %s %s", method, pathTemplate)
+			}
+			if scope := middlewares.RouteScope(method, pathTemplate); scope != "" && !isTokenScope(scope) {
+				t.Errorf("unknown scope %s for the route: %s %s", scope, method, pathTemplate)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("failed to walk the routes: %+v", err)
+	}
+}
+
+// isTokenScope checks if the scope is one of the scopes that the API tokens can have.
+func isTokenScope(scope string) bool {
+	for _, tokenScope := range models.TokenScopes {
+		if scope == tokenScope {
+			return true
+		}
+	}
+	return false
+}
+
+func TestAPIWithTokens(t *testing.T) {
+	handler := getHandler(database.NewMemoryRepositories(), nil)
+
+	response := doTestRequest(t, handler, http.MethodPost, "/api/tokens", `{"name":"Phone","scopes":["admin"]}`)
+	if response.CustomCode != "BAD_REQUEST" {
+		t.Fatalf("expected BAD_REQUEST, got: %s", response.CustomCode)
+	}
+	response = doTestRequest(t, handler, http.MethodPost, "/api/tokens",
+		`{"name":"Phone","scopes":["stats:read"],"expires_at":1}`)
+	if response.CustomCode != "BAD_REQUEST" {
+		t.Fatalf("expected BAD_REQUEST, got: %s", response.CustomCode)
+	}
+
+	response = doTestRequest(t, handler, http.MethodPost, "/api/tokens",
+		`{"name":"Phone","scopes":["transactions:write","accounts:read"]}`)
+	if response.CustomCode != "TOKEN_CREATED" {
+		t.Fatalf("expected TOKEN_CREATED, got: %s", response.CustomCode)
+	}
+	var created struct {
+		ID    string `json:"id"`
+		Token string `json:"token"`
+	}
+	if err := json.Unmarshal(response.Data, &created); err != nil {
+		t.Fatalf("failed to decode created token: %+v", err)
+	}
+
+	response = doTestRequest(t, handler, http.MethodPost, "/api/accounts", `{"id":"bank","name":"Bank"}`)
+	if response.CustomCode != "ACCOUNT_CREATED" {
+		t.Fatalf("expected ACCOUNT_CREATED, got: %s", response.CustomCode)
+	}
+
+	// The token can use the routes of its scopes, and a write scope includes the read routes.
+	response = doTestTokenRequest(t, handler, created.Token, http.MethodPost, "/api/transactions",
+		`{"amount":-25,"timestamp":200,"account_id":"bank","category":"essentials","notes":"Groceries"}`)
+	if response.CustomCode != "TRANSACTION_CREATED" {
+		t.Fatalf("expected TRANSACTION_CREATED, got: %s", response.CustomCode)
+	}
+	response = doTestTokenRequest(t, handler, created.Token, http.MethodGet, "/api/transactions", "")
+	if !strings.Contains(string(response.Data), "Groceries") {
+		t.Fatalf("expected the created transaction, got: %s", response.Data)
+	}
+	response = doTestTokenRequest(t, handler, created.Token, http.MethodGet, "/api/accounts", "")
+	if response.CustomCode != "ACCOUNTS_LISTED" {
+		t.Fatalf("expected ACCOUNTS_LISTED, got: %s", response.CustomCode)
+	}
+
+	// The other routes are forbidden, including the ones that cannot be used with any token.
+	for _, route := range [][2]string{
+		{http.MethodPost, "/api/accounts"}, {http.MethodGet, "/api/categories"}, {http.MethodGet, "/api/tokens"},
+		{http.MethodPatch, "/api/users/me"},
+	} {
+		response = doTestTokenRequest(t, handler, created.Token, route[0], route[1], `{"id":"cash","name":"Cash"}`)
+		if response.CustomCode != "FORBIDDEN" {
+			t.Fatalf("expected FORBIDDEN for %s %s, got: %s", route[0], route[1], response.CustomCode)
+		}
+	}
+
+	response = doTestTokenRequest(t, handler, "lk_unknown", http.MethodGet, "/api/transactions", "")
+	if response.CustomCode != "UNAUTHORIZED" {
+		t.Fatalf("expected UNAUTHORIZED, got: %s", response.CustomCode)
+	}
+
+	// The list has the last use, but not the token itself.
+	response = doTestRequest(t, handler, http.MethodGet, "/api/tokens", "")
+	if strings.Contains(string(response.Data), created.Token) {
+		t.Fatalf("expected no token in the list, got: %s", response.Data)
+	}
+	var tokens []struct {
+		ID         string   `json:"id"`
+		Scopes     []string `json:"scopes"`
+		LastUsedAt int64    `json:"last_used_at"`
+	}
+	if err := json.Unmarshal(response.Data, &tokens); err != nil {
+		t.Fatalf("failed to decode tokens: %+v", err)
+	}
+	if len(tokens) != 1 || tokens[0].ID != created.ID || len(tokens[0].Scopes) != 2 || tokens[0].LastUsedAt == 0 {
+		t.Fatalf("unexpected tokens: %+v", tokens)
+	}
+
+	response = doTestRequest(t, handler, http.MethodDelete, "/api/tokens/"+created.ID, "")
+	if response.CustomCode != "TOKEN_DELETED" {
+		t.Fatalf("expected TOKEN_DELETED, got: %s", response.CustomCode)
+	}
+	response = doTestTokenRequest(t, handler, created.Token, http.MethodGet, "/api/transactions", "")
+	if response.CustomCode != "UNAUTHORIZED" {
+		t.Fatalf("expected UNAUTHORIZED after the revocation, got: %s", response.CustomCode)
+	}
+}
+
+func TestAPIWithOIDC(t *testing.T) {
+	issuer, err := testutils.NewOIDCIssuer()
+	if err != nil {
+		t.Fatalf("failed to start the issuer: %+v", err)
+	}
+	defer issuer.Close()
+
+	handler := getHandler(database.NewMemoryRepositories(), oidc.NewVerifier(issuer.URL(), "ledgerkeep", 0))
+
+	signToken := func(claims map[string]interface{}) string {
+		claims["iss"], claims["aud"], claims["exp"] = issuer.URL(), "ledgerkeep", time.Now().Add(time.Hour).Unix()
+		token, err := issuer.SignToken(claims)
+		if err != nil {
+			t.Fatalf("failed to sign the token: %+v", err)
+		}
+		return token
+	}
+
+	// The subject of the admin is the username of the configs.
+	adminToken := signToken(map[string]interface{}{"sub": testutils.Username})
+	response := doTestTokenRequest(t, handler, adminToken, http.MethodPost, "/api/users",
+		`{"id":"alice@example.com","password":"alice-pass"}`)
+	if response.CustomCode != "USER_CREATED" {
+		t.Fatalf("expected USER_CREATED, got: %s", response.CustomCode)
+	}
+
+	// The verified email is preferred over the subject.
+	aliceToken := signToken(map[string]interface{}{
+		"sub": "alice-subject", "email": "alice@example.com", "email_verified": true,
+	})
+	response = doTestTokenRequest(t, handler, aliceToken, http.MethodGet, "/api/users/me", "")
+	var user struct {
+		ID string `json:"id"`
+	}
+	if err := json.Unmarshal(response.Data, &user); err != nil {
+		t.Fatalf("failed to decode user: %+v", err)
+	}
+	if user.ID != "alice@example.com" {
+		t.Fatalf("unexpected user: %+v", user)
+	}
+
+	invalidTokens := map[string]string{
+		"unverified email": signToken(map[string]interface{}{
+			"sub": "alice-subject", "email": "alice@example.com", "email_verified": false,
+		}),
+		"email without email_verified": signToken(map[string]interface{}{
+			"sub": "alice-subject", "email": "alice@example.com",
+		}),
+		"unknown user": signToken(map[string]interface{}{"sub": "bob"}),
+	}
+	wrongAudience, err := issuer.SignToken(map[string]interface{}{
+		"iss": issuer.URL(), "aud": "other", "sub": testutils.Username, "exp": time.Now().Add(time.Hour).Unix(),
+	})
+	if err != nil {
+		t.Fatalf("failed to sign the token: %+v", err)
+	}
+	invalidTokens["wrong audience"] = wrongAudience
+
+	for name, token := range invalidTokens {
+		response = doTestTokenRequest(t, handler, token, http.MethodGet, "/api/users/me", "")
+		if response.CustomCode != "UNAUTHORIZED" {
+			t.Fatalf("expected UNAUTHORIZED for the %s token, got: %s", name, response.CustomCode)
+		}
+	}
+
+	// The passwords are not accepted in the OIDC mode, but the API tokens are.
+	response = doTestRequestAs(t, handler, "alice@example.com", "alice-pass", http.MethodGet, "/api/users/me", "")
+	if response.CustomCode != "UNAUTHORIZED" {
+		t.Fatalf("expected UNAUTHORIZED, got: %s", response.CustomCode)
+	}
+
+	response = doTestTokenRequest(t, handler, aliceToken, http.MethodPost, "/api/tokens",
+		`{"name":"Script","scopes":["users:read"]}`)
+	var created struct {
+		Token string `json:"token"`
+	}
+	if err := json.Unmarshal(response.Data, &created); err != nil {
+		t.Fatalf("failed to decode created token: %+v", err)
+	}
+	response = doTestTokenRequest(t, handler, created.Token, http.MethodGet, "/api/users/me", "")
+	if response.CustomCode != "USER_FETCHED" {
+		t.Fatalf("expected USER_FETCHED, got: %s", response.CustomCode)
+	}
+}
+
+// doTestRequestFrom sends a GET request from the client IP address with the basic auth credentials, and provides the
+// recorded response.
+func doTestRequestFrom(handler http.Handler, clientIP, username, password, path string) *httptest.ResponseRecorder {
+	request := httptest.NewRequest(http.MethodGet, path, nil)
+	request.RemoteAddr = net.JoinHostPort(clientIP, "1234")
+	request.SetBasicAuth(username, password)
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, request)
+	return recorder
+}
+
+func TestAPIWithRateLimits(t *testing.T) {
+	groups := []middlewares.RouteGroupLimits{
+		{
+			Name:          "exports",
+			PathPrefix:    "/api/exports",
+			PerIP:         middlewares.BucketLimit{RequestsPerSec: 0.001, Burst: 2},
+			PerCredential: middlewares.BucketLimit{RequestsPerSec: 0.001, Burst: 3},
+		},
+	}
+	lockout := middlewares.LockoutPolicy{MaxFailures: 3, BaseDuration: time.Minute, MaxDuration: time.Hour}
+	handler := middlewares.RateLimiter(groups, lockout, nil)(getHandler(database.NewMemoryRepositories(), nil))
+
+	exportPath := "/api/exports/transactions?format=csv"
+	for i := 0; i < 2; i++ {
+		recorder := doTestRequestFrom(handler, "10.0.0.1", testutils.Username, testutils.Password, exportPath)
+		if recorder.Code != http.StatusOK {
+			t.Fatalf("expected status 200, got: %d", recorder.Code)
+		}
+	}
+
+	// The bucket of the client IP address is empty, and it refills one token in 1000 seconds. The bucket refills a
+	// little in between the requests, as they take real time, so Retry-After may be a few seconds shorter.
+	recorder := doTestRequestFrom(handler, "10.0.0.1", testutils.Username, testutils.Password, exportPath)
+	retryAfter, _ := strconv.Atoi(recorder.Header().Get("Retry-After"))
+	if recorder.Code != http.StatusTooManyRequests || retryAfter < 900 || retryAfter > 1000 {
+		t.Fatalf("expected status 429 with Retry-After about 1000, got: %d, %s", recorder.Code,
+			recorder.Header().Get("Retry-After"))
+	}
+
+	// The other groups of routes are not limited, and the other clients have their own buckets.
+	recorder = doTestRequestFrom(handler, "10.0.0.1", testutils.Username, testutils.Password, "/api/accounts")
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got: %d", recorder.Code)
+	}
+	recorder = doTestRequestFrom(handler, "10.0.0.2", testutils.Username, testutils.Password, exportPath)
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got: %d", recorder.Code)
+	}
+
+	// The bucket of the credentials is shared by all the clients.
+	recorder = doTestRequestFrom(handler, "10.0.0.3", testutils.Username, testutils.Password, exportPath)
+	if recorder.Code != http.StatusTooManyRequests {
+		t.Fatalf("expected status 429, got: %d", recorder.Code)
+	}
+
+	// The repeated 401 responses lock out the client, even with the right password.
+	for i := 0; i < 3; i++ {
+		recorder = doTestRequestFrom(handler, "10.0.0.4", "alice", "wrong-pass", "/api/accounts")
+		if recorder.Code != http.StatusUnauthorized {
+			t.Fatalf("expected status 401, got: %d", recorder.Code)
+		}
+	}
+	recorder = doTestRequestFrom(handler, "10.0.0.4", testutils.Username, testutils.Password, "/api/accounts")
+	if recorder.Code != http.StatusTooManyRequests || recorder.Header().Get("Retry-After") != "60" {
+		t.Fatalf("expected status 429 with Retry-After 60, got: %d, %s", recorder.Code,
+			recorder.Header().Get("Retry-After"))
+	}
+
+	// The credentials are locked out too, from any client.
+	recorder = doTestRequestFrom(handler, "10.0.0.5", "alice", "alice-pass", "/api/accounts")
+	if recorder.Code != http.StatusTooManyRequests {
+		t.Fatalf("expected status 429, got: %d", recorder.Code)
+	}
+	response := &testResponseBody{}
+	if err := json.Unmarshal(recorder.Body.Bytes(), response); err != nil {
+		t.Fatalf("failed to decode response: %+v", err)
+	}
+	if response.CustomCode != "TOO_MANY_REQUESTS" {
+		t.Fatalf("expected TOO_MANY_REQUESTS, got: %s", response.CustomCode)
+	}
+}
+
+func TestGetRateLimiterWithInvalidConfigs(t *testing.T) {
+	rateLimit := &configs.Get().RateLimit
+	lockout := &rateLimit.Lockout
+	original := *lockout
+	defer func() { *lockout, rateLimit.TrustedProxies = original, nil }()
+
+	// The lockouts of zero seconds, which a missing max_sec leads to, would never lock anyone out.
+	invalidLockouts := map[string][3]int{
+		"negative max failures":  {-1, 30, 60},
+		"missing base duration":  {5, 0, 60},
+		"missing max duration":   {5, 30, 0},
+		"max shorter than base":  {5, 60, 30},
+		"negative base duration": {5, -30, 60},
+	}
+	for name, values := range invalidLockouts {
+		lockout.MaxFailures, lockout.BaseSec, lockout.MaxSec = values[0], values[1], values[2]
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("expected a panic for the %s", name)
+				}
+			}()
+			getRateLimiter()
+		}()
+	}
+
+	// The durations do not matter if the lockouts are disabled.
+	lockout.MaxFailures, lockout.BaseSec, lockout.MaxSec = 0, 0, 0
+	getRateLimiter()
+
+	for _, proxy := range []string{"10.0.0.0/33", "localhost", "10.0.0"} {
+		rateLimit.TrustedProxies = []string{"10.0.0.1", "10.1.0.0/16", "::1", proxy}
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("expected a panic for the trusted proxy %s", proxy)
+				}
+			}()
+			getRateLimiter()
+		}()
+	}
+}
@@ -0,0 +1,88 @@
+// Package backups writes and reads the backups of a ledger as portable archives.
+//
+// An archive is a ZIP file with a JSON Lines file for every section of the ledger, like the accounts or the
+// transactions, and a manifest that tells the version of the format along with the record counts and the SHA-256
+// checksums of the sections. The records are the JSON objects of the models, so the archives do not depend on the
+// storage backend that they were taken from or are restored into.
+package backups
+
+import (
+	"errors"
+	"fmt"
+)
+
+// These are the identifiers and the current version of the archive format.
+const (
+	// Format identifies the archives of ledgerkeep in their manifests.
+	Format = "ledgerkeep-backup"
+	// Version is the version of the archive format. It changes whenever an archive of the new format cannot be read
+	// correctly by the code of the old one.
+	Version = 1
+)
+
+// ManifestName is the name of the manifest file in the archives.
+const ManifestName = "manifest.json"
+
+// These are the sections of the ledger, in the order that they are written in.
+const (
+	SectionAccounts           = "accounts"
+	SectionCategories         = "categories"
+	SectionExchangeRates      = "exchange_rates"
+	SectionBudgetPlans        = "budget_plans"
+	SectionRecurringTemplates = "recurring_templates"
+	SectionImportProfiles     = "import_profiles"
+	SectionRules              = "rules"
+	SectionTransactions       = "transactions"
+)
+
+// Sections are all the sections of the ledger. Every archive has all of them, even if some are empty.
+var Sections = []string{
+	SectionAccounts,
+	SectionCategories,
+	SectionExchangeRates,
+	SectionBudgetPlans,
+	SectionRecurringTemplates,
+	SectionImportProfiles,
+	SectionRules,
+	SectionTransactions,
+}
+
+// MaxDataSize is the maximum total size of the uncompressed sections of an archive that can be read. It guards the
+// reader against the archives that expand beyond any size of a real ledger.
+const MaxDataSize = 1 << 30
+
+// Manifest is the schema of the manifest file of an archive.
+type Manifest struct {
+	// Format is always the Format constant.
+	Format string `json:"format"`
+	// Version is the version of the archive format.
+	Version int `json:"version"`
+	// CreatedAt is the time of the backup, in epoch seconds.
+	CreatedAt int64 `json:"created_at"`
+	// Files describe the section files of the archive.
+	Files []*ManifestFile `json:"files"`
+}
+
+// ManifestFile describes a section file of an archive.
+type ManifestFile struct {
+	// Name of the file in the archive, which is the name of its section with the ".jsonl" extension.
+	Name string `json:"name"`
+	// Records is the number of the records, or the lines, of the file.
+	Records int `json:"records"`
+	// SHA256 is the hex encoded SHA-256 checksum of the file.
+	SHA256 string `json:"sha256"`
+}
+
+// Errors of the archives that cannot be read.
+var (
+	ErrInvalidArchive    = errors.New("backup should be a ZIP archive with a manifest")
+	ErrUnsupportedFormat = fmt.Errorf("backup should be of the format %s, up to version %d", Format, Version)
+	ErrChecksumMismatch  = errors.New("checksum of the file does not match the manifest")
+	ErrRecordsMismatch   = errors.New("number of records of the file does not match the manifest")
+	ErrTooLarge          = fmt.Errorf("backup should have at most %d bytes of data", MaxDataSize)
+)
+
+// fileName provides the name of the file of the section.
+func fileName(section string) string {
+	return section + ".jsonl"
+}
@@ -0,0 +1,172 @@
+package backups
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/shivanshkc/ledgerkeep/src/models"
+)
+
+func TestWriteAndRead(t *testing.T) {
+	createdAt := time.Unix(1650000000, 0)
+	archive := writeTestArchive(t, createdAt)
+
+	data, manifest, err := Read(bytes.NewReader(archive), int64(len(archive)))
+	if err != nil {
+		t.Fatalf("unexpected error in Read: %+v", err)
+	}
+
+	if manifest.Format != Format || manifest.Version != Version || manifest.CreatedAt != createdAt.Unix() ||
+		len(manifest.Files) != len(Sections) {
+		t.Fatalf("unexpected manifest: %+v", manifest)
+	}
+
+	if len(data.Accounts) != 2 || data.Accounts[1].ID != "cash" || len(data.Categories) != 1 ||
+		len(data.ExchangeRates) != 0 || len(data.Rules) != 0 {
+		t.Fatalf("unexpected data: %+v", data)
+	}
+
+	if len(data.Transactions) != 1 {
+		t.Fatalf("expected 1 transaction, got: %d", len(data.Transactions))
+	}
+	transaction := data.Transactions[0]
+	if transaction.ID != "62a1b2c3d4e5f6a7b8c9d0e1" || transaction.Amount != -12345 || transaction.ClosingBal != 0 ||
+		len(transaction.Splits) != 2 || transaction.Splits[1].Category != "fun" {
+		t.Fatalf("unexpected transaction: %+v", transaction)
+	}
+}
+
+func TestReadInvalid(t *testing.T) {
+	archive := writeTestArchive(t, time.Now())
+
+	// The checksum catches the sections that were altered.
+	tampered := rewriteTestArchive(t, archive, func(name string, content []byte) []byte {
+		if name == fileName(SectionAccounts) {
+			return bytes.Replace(content, []byte("Cash"), []byte("Cask"), 1)
+		}
+		return content
+	})
+	if _, _, err := Read(bytes.NewReader(tampered), int64(len(tampered))); !errors.Is(err, ErrChecksumMismatch) {
+		t.Fatalf("expected ErrChecksumMismatch, got: %+v", err)
+	}
+
+	// The record count catches the sections that were cut short, before the checksum does.
+	truncated := rewriteTestArchive(t, archive, func(name string, content []byte) []byte {
+		if name == fileName(SectionAccounts) {
+			return content[:bytes.IndexByte(content, '\n')+1]
+		}
+		return content
+	})
+	if _, _, err := Read(bytes.NewReader(truncated), int64(len(truncated))); !errors.Is(err, ErrRecordsMismatch) {
+		t.Fatalf("expected ErrRecordsMismatch, got: %+v", err)
+	}
+
+	withoutManifest := rewriteTestArchive(t, archive, func(name string, content []byte) []byte {
+		if name == ManifestName {
+			return nil
+		}
+		return content
+	})
+	if _, _, err := Read(bytes.NewReader(withoutManifest), int64(len(withoutManifest))); !errors.Is(err,
+		ErrInvalidArchive) {
+		t.Fatalf("expected ErrInvalidArchive, got: %+v", err)
+	}
+
+	newerVersion := rewriteTestArchive(t, archive, func(name string, content []byte) []byte {
+		if name == ManifestName {
+			return bytes.Replace(content, []byte(`"version": 1`), []byte(`"version": 2`), 1)
+		}
+		return content
+	})
+	if _, _, err := Read(bytes.NewReader(newerVersion), int64(len(newerVersion))); !errors.Is(err,
+		ErrUnsupportedFormat) {
+		t.Fatalf("expected ErrUnsupportedFormat, got: %+v", err)
+	}
+
+	if _, _, err := Read(bytes.NewReader([]byte("not a zip")), 9); !errors.Is(err, ErrInvalidArchive) {
+		t.Fatalf("expected ErrInvalidArchive, got: %+v", err)
+	}
+}
+
+// writeTestArchive writes an archive with a few records, leaving some sections out.
+func writeTestArchive(t *testing.T, createdAt time.Time) []byte {
+	t.Helper()
+
+	buffer := &bytes.Buffer{}
+	writer := NewWriter(buffer, createdAt)
+
+	records := map[string][]interface{}{
+		SectionAccounts: {
+			&models.AccountDTO{ID: "bank", Name: "Bank", Currency: "EUR"},
+			&models.AccountDTO{ID: "cash", Name: "Cash"},
+		},
+		SectionCategories: {&models.CategoryDTO{ID: "food", Name: "Food", Kind: "expense"}},
+		SectionTransactions: {&models.TransactionDTO{
+			ID: "62a1b2c3d4e5f6a7b8c9d0e1", Amount: -12345, AccountID: "bank", Category: "split", ClosingBal: 500,
+			Splits: []*models.SplitDTO{{Amount: -10000, Category: "food"}, {Amount: -2345, Category: "fun"}},
+		}},
+	}
+
+	for _, section := range []string{SectionAccounts, SectionCategories, SectionTransactions} {
+		if err := writer.Section(section); err != nil {
+			t.Fatalf("unexpected error in Section: %+v", err)
+		}
+		for _, record := range records[section] {
+			if err := writer.Write(record); err != nil {
+				t.Fatalf("unexpected error in Write: %+v", err)
+			}
+		}
+	}
+
+	if err := writer.Close(); err != nil {
+		t.Fatalf("unexpected error in Close: %+v", err)
+	}
+	return buffer.Bytes()
+}
+
+// rewriteTestArchive copies the archive with the contents of its files changed by the edit function. The files whose
+// new content is nil are left out.
+func rewriteTestArchive(t *testing.T, archive []byte, edit func(name string, content []byte) []byte) []byte {
+	t.Helper()
+
+	reader, err := zip.NewReader(bytes.NewReader(archive), int64(len(archive)))
+	if err != nil {
+		t.Fatalf("unexpected error in zip.NewReader: %+v", err)
+	}
+
+	buffer := &bytes.Buffer{}
+	writer := zip.NewWriter(buffer)
+
+	for _, file := range reader.File {
+		content, err := file.Open()
+		if err != nil {
+			t.Fatalf("unexpected error in Open: %+v", err)
+		}
+		original, err := ioutil.ReadAll(content)
+		if err != nil {
+			t.Fatalf("unexpected error in ReadAll: %+v", err)
+		}
+
+		edited := edit(file.Name, original)
+		if edited == nil {
+			continue
+		}
+
+		fileWriter, err := writer.Create(file.Name)
+		if err != nil {
+			t.Fatalf("unexpected error in Create: %+v", err)
+		}
+		if _, err := fileWriter.Write(edited); err != nil {
+			t.Fatalf("unexpected error in Write: %+v", err)
+		}
+	}
+
+	if err := writer.Close(); err != nil {
+		t.Fatalf("unexpected error in Close: %+v", err)
+	}
+	return buffer.Bytes()
+}
@@ -0,0 +1,189 @@
+package backups
+
+import (
+	"archive/zip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/shivanshkc/ledgerkeep/src/models"
+)
+
+// maxManifestSize is the maximum size of the manifest of an archive in bytes.
+const maxManifestSize = 1 << 20
+
+// Read reads and validates a backup archive. Every section of the archive should match the record count and the
+// checksum of the manifest, so the archives that were cut short or altered are never read.
+func Read(reader io.ReaderAt, size int64) (*models.LedgerData, *Manifest, error) {
+	archive, err := zip.NewReader(reader, size)
+	if err != nil {
+		return nil, nil, ErrInvalidArchive
+	}
+
+	files := make(map[string]*zip.File, len(archive.File))
+	for _, file := range archive.File {
+		files[file.Name] = file
+	}
+
+	manifest, err := readManifest(files[ManifestName])
+	if err != nil {
+		return nil, nil, err
+	}
+
+	manifestFiles := make(map[string]*ManifestFile, len(manifest.Files))
+	for _, file := range manifest.Files {
+		if file != nil {
+			manifestFiles[file.Name] = file
+		}
+	}
+
+	data := &models.LedgerData{}
+	// remaining is the size of the data that can still be read.
+	remaining := int64(MaxDataSize)
+
+	for _, section := range Sections {
+		name := fileName(section)
+		expected, file := manifestFiles[name], files[name]
+		if expected == nil || file == nil {
+			return nil, nil, fmt.Errorf("%w: %s is missing", ErrInvalidArchive, name)
+		}
+
+		read, err := readSection(file, section, expected, data, remaining)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s: %w", name, err)
+		}
+		remaining -= read
+	}
+
+	return data, manifest, nil
+}
+
+// readManifest reads the manifest file of an archive, and checks that the archive is of a supported format.
+func readManifest(file *zip.File) (*Manifest, error) {
+	if file == nil {
+		return nil, ErrInvalidArchive
+	}
+
+	content, err := file.Open()
+	if err != nil {
+		return nil, ErrInvalidArchive
+	}
+	defer func() { _ = content.Close() }()
+
+	manifest := &Manifest{}
+	if err := json.NewDecoder(io.LimitReader(content, maxManifestSize)).Decode(manifest); err != nil {
+		return nil, ErrInvalidArchive
+	}
+
+	if manifest.Format != Format || manifest.Version < 1 || manifest.Version > Version {
+		return nil, ErrUnsupportedFormat
+	}
+	return manifest, nil
+}
+
+// readSection reads the records of a section file into the data, and checks them against the manifest. It provides the
+// number of the bytes that were read, which should not be more than the limit.
+func readSection(file *zip.File, section string, expected *ManifestFile, data *models.LedgerData,
+	limit int64) (int64, error) {
+	content, err := file.Open()
+	if err != nil {
+		return 0, ErrInvalidArchive
+	}
+	defer func() { _ = content.Close() }()
+
+	// Reading one byte more than the limit tells if the file is beyond it.
+	limited := &io.LimitedReader{R: content, N: limit + 1}
+	checksum := sha256.New()
+	decoder := json.NewDecoder(io.TeeReader(limited, checksum))
+
+	records := 0
+	for {
+		err := decodeRecord(decoder, section, data)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			if limited.N <= 0 {
+				return 0, ErrTooLarge
+			}
+			return 0, fmt.Errorf("record %d: %w", records+1, err)
+		}
+		records++
+	}
+
+	// Anything after the last record is a part of the checksum too.
+	if _, err := io.Copy(checksum, limited); err != nil {
+		return 0, ErrInvalidArchive
+	}
+	if limited.N <= 0 {
+		return 0, ErrTooLarge
+	}
+
+	if records != expected.Records {
+		return 0, ErrRecordsMismatch
+	}
+	if hex.EncodeToString(checksum.Sum(nil)) != expected.SHA256 {
+		return 0, ErrChecksumMismatch
+	}
+	return limit + 1 - limited.N, nil
+}
+
+// decodeRecord decodes the next record of the section into the data. It returns io.EOF if there are no more records.
+func decodeRecord(decoder *json.Decoder, section string, data *models.LedgerData) error {
+	switch section {
+	case SectionAccounts:
+		record := &models.AccountDTO{}
+		if err := decoder.Decode(record); err != nil {
+			return err
+		}
+		data.Accounts = append(data.Accounts, record)
+	case SectionCategories:
+		record := &models.CategoryDTO{}
+		if err := decoder.Decode(record); err != nil {
+			return err
+		}
+		data.Categories = append(data.Categories, record)
+	case SectionExchangeRates:
+		record := &models.ExchangeRateDTO{}
+		if err := decoder.Decode(record); err != nil {
+			return err
+		}
+		data.ExchangeRates = append(data.ExchangeRates, record)
+	case SectionBudgetPlans:
+		record := &models.BudgetPlanDTO{}
+		if err := decoder.Decode(record); err != nil {
+			return err
+		}
+		data.BudgetPlans = append(data.BudgetPlans, record)
+	case SectionRecurringTemplates:
+		record := &models.RecurringTemplateDTO{}
+		if err := decoder.Decode(record); err != nil {
+			return err
+		}
+		data.RecurringTemplates = append(data.RecurringTemplates, record)
+	case SectionImportProfiles:
+		record := &models.ImportProfileDTO{}
+		if err := decoder.Decode(record); err != nil {
+			return err
+		}
+		data.ImportProfiles = append(data.ImportProfiles, record)
+	case SectionRules:
+		record := &models.RuleDTO{}
+		if err := decoder.Decode(record); err != nil {
+			return err
+		}
+		data.Rules = append(data.Rules, record)
+	case SectionTransactions:
+		record := &models.TransactionDTO{}
+		if err := decoder.Decode(record); err != nil {
+			return err
+		}
+		data.Transactions = append(data.Transactions, record)
+	default:
+		return fmt.Errorf("unknown section: %s", section)
+	}
+	return nil
+}
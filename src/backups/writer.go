@@ -0,0 +1,148 @@
+package backups
+
+import (
+	"archive/zip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"time"
+
+	"github.com/shivanshkc/ledgerkeep/src/models"
+)
+
+// errNoSection is returned for the records that are written before any section.
+var errNoSection = errors.New("records should be written after their section")
+
+// transactionRecord is the record of a transaction in an archive. The closing balance of a transaction is calculated,
+// not stored, so it is left out. The field shadows that of the embedded transaction, and is always nil.
+type transactionRecord struct {
+	*models.TransactionDTO
+	ClosingBal *struct{} `json:"closing_bal,omitempty"`
+}
+
+// Writer writes a backup archive, one section at a time. The records of every section are written after the section
+// is started with the Section method.
+type Writer struct {
+	zip      *zip.Writer
+	manifest *Manifest
+
+	// current is the file of the section that is being written, if any.
+	current *ManifestFile
+	hash    hash.Hash
+	encoder *json.Encoder
+}
+
+// NewWriter provides a new Writer that writes the archive of a backup taken at the provided time.
+func NewWriter(writer io.Writer, createdAt time.Time) *Writer {
+	return &Writer{
+		zip:      zip.NewWriter(writer),
+		manifest: &Manifest{Format: Format, Version: Version, CreatedAt: createdAt.Unix()},
+	}
+}
+
+// Section starts the section with the provided name, which is one of the Sections, and finishes the previous one.
+func (w *Writer) Section(name string) error {
+	w.finishSection()
+
+	if !isSection(name) {
+		return fmt.Errorf("unknown section: %s", name)
+	}
+	if w.hasSection(name) {
+		return fmt.Errorf("section is written already: %s", name)
+	}
+
+	file, err := w.zip.Create(fileName(name))
+	if err != nil {
+		return fmt.Errorf("failed to create archive file: %w", err)
+	}
+
+	w.current = &ManifestFile{Name: fileName(name)}
+	w.hash = sha256.New()
+	w.encoder = json.NewEncoder(io.MultiWriter(file, w.hash))
+	w.encoder.SetEscapeHTML(false)
+	return nil
+}
+
+// Write writes a record of the current section, which is a model of the section, like a *models.AccountDTO.
+func (w *Writer) Write(record interface{}) error {
+	if w.current == nil {
+		return errNoSection
+	}
+
+	if transaction, isTransaction := record.(*models.TransactionDTO); isTransaction {
+		record = &transactionRecord{TransactionDTO: transaction}
+	}
+	if err := w.encoder.Encode(record); err != nil {
+		return fmt.Errorf("failed to write record: %w", err)
+	}
+
+	w.current.Records++
+	return nil
+}
+
+// Close finishes the archive. The sections that were not written are written empty, so an archive always has all the
+// sections, and the manifest is written last, as it tells the checksums of all of them.
+func (w *Writer) Close() error {
+	for _, section := range Sections {
+		if !w.hasSection(section) {
+			if err := w.Section(section); err != nil {
+				return err
+			}
+		}
+	}
+	w.finishSection()
+
+	file, err := w.zip.Create(ManifestName)
+	if err != nil {
+		return fmt.Errorf("failed to create archive file: %w", err)
+	}
+
+	encoder := json.NewEncoder(file)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(w.manifest); err != nil {
+		return fmt.Errorf("failed to write manifest: %w", err)
+	}
+
+	if err := w.zip.Close(); err != nil {
+		return fmt.Errorf("failed to close archive: %w", err)
+	}
+	return nil
+}
+
+// finishSection adds the file of the current section, if any, to the manifest.
+func (w *Writer) finishSection() {
+	if w.current == nil {
+		return
+	}
+
+	w.current.SHA256 = hex.EncodeToString(w.hash.Sum(nil))
+	w.manifest.Files = append(w.manifest.Files, w.current)
+	w.current, w.hash, w.encoder = nil, nil, nil
+}
+
+// hasSection checks if the section was written, or is being written.
+func (w *Writer) hasSection(section string) bool {
+	if w.current != nil && w.current.Name == fileName(section) {
+		return true
+	}
+	for _, file := range w.manifest.Files {
+		if file.Name == fileName(section) {
+			return true
+		}
+	}
+	return false
+}
+
+// isSection checks if the name is one of the Sections.
+func isSection(name string) bool {
+	for _, section := range Sections {
+		if section == name {
+			return true
+		}
+	}
+	return false
+}
@@ -12,12 +12,59 @@ type Model struct {
 
 	// Auth is the model of authentication configs.
 	Auth struct {
-		// Username for basic auth.
+		// Username of the admin user, which is created upon startup if it does not exist.
 		Username string `mapstructure:"username"`
-		// Password for basic auth.
+		// Password of the admin user. It is only used to create the user, so changing it later has no effect.
 		Password string `mapstructure:"password"`
+		// Mode is "basic" for the passwords of the users, or "oidc" for the access tokens of an OpenID Connect issuer.
+		// The API tokens of the users work in both modes.
+		Mode string `mapstructure:"mode"`
+
+		// OIDC is the model of the OpenID Connect configs, which are only used in the "oidc" mode.
+		OIDC struct {
+			// Issuer is the URL of the issuer, which serves the discovery document with the location of its keys.
+			Issuer string `mapstructure:"issuer"`
+			// Audience is what the access tokens must have in their "aud" claim. Empty skips the check.
+			Audience string `mapstructure:"audience"`
+			// KeyRefreshSec is the interval in seconds at which the keys of the issuer are fetched again.
+			// They are also fetched when a token is signed by an unknown key, which happens after a rotation.
+			KeyRefreshSec int `mapstructure:"key_refresh_sec"`
+		} `mapstructure:"oidc"`
 	} `mapstructure:"auth"`
 
+	// RateLimit is the model of the rate limiting configs.
+	RateLimit struct {
+		// Groups are the groups of routes, each with its own limits. A request is limited by the first group whose
+		// path prefix matches it. The requests that match no group are not limited.
+		Groups []struct {
+			// Name identifies the group.
+			Name string `mapstructure:"name"`
+			// PathPrefix is what the paths of the routes of the group start with.
+			PathPrefix string `mapstructure:"path_prefix"`
+			// PerIP limits the requests of every client IP address.
+			PerIP BucketLimitModel `mapstructure:"per_ip"`
+			// PerCredential limits the requests of every username or bearer token, from any client IP address.
+			PerCredential BucketLimitModel `mapstructure:"per_credential"`
+		} `mapstructure:"groups"`
+
+		// TrustedProxies are the IP addresses or the CIDR ranges, like "10.0.0.0/8", of the reverse proxies in front
+		// of the application. The client IP addresses are read from the x-real-ip and x-forwarded-for headers only for
+		// the requests that come from them.
+		TrustedProxies []string `mapstructure:"trusted_proxies"`
+
+		// Lockout is the model of the lockouts of the clients and the credentials after repeated 401 responses.
+		Lockout struct {
+			// MaxFailures is the number of 401 responses after which the lockouts start. Zero disables them.
+			MaxFailures int `mapstructure:"max_failures"`
+			// BaseSec is the duration in seconds of the first lockout. Every further failure doubles it.
+			// It must be positive if the lockouts are enabled.
+			BaseSec int `mapstructure:"base_sec"`
+			// MaxSec is the maximum duration in seconds of a lockout.
+			// It must be at least BaseSec if the lockouts are enabled.
+			MaxSec int `mapstructure:"max_sec"`
+		} `mapstructure:"lockout"`
+	} `mapstructure:"rate_limit"`
+
 	// HTTPServer is the model of the HTTP Server configs.
 	HTTPServer struct {
 		// Addr is the address of the HTTP server.
@@ -30,6 +77,35 @@ type Model struct {
 		Level string `mapstructure:"level"`
 	} `mapstructure:"logger"`
 
+	// Currency is the model of the currency configs.
+	Currency struct {
+		// Default is the ISO 4217 code of the currency of accounts that do not specify one.
+		// It is also the default reporting currency of the stats APIs.
+		Default string `mapstructure:"default"`
+	} `mapstructure:"currency"`
+
+	// Recurring is the model of the recurring transactions configs.
+	Recurring struct {
+		// IntervalSec is the interval in seconds at which the due recurring transactions are created.
+		// Zero disables the scheduler, which leaves the creation of the recurring transactions to other instances.
+		IntervalSec int `mapstructure:"interval_sec"`
+	} `mapstructure:"recurring"`
+
+	// Duplicates is the model of the duplicate transaction detection configs.
+	Duplicates struct {
+		// WindowSec is the max difference in seconds between the timestamps of duplicate transactions.
+		WindowSec int64 `mapstructure:"window_sec"`
+		// MinNotesSimilarity is the min similarity, from 0 to 1, of the notes of duplicate transactions.
+		// Zero ignores the notes.
+		MinNotesSimilarity float64 `mapstructure:"min_notes_similarity"`
+	} `mapstructure:"duplicates"`
+
+	// Storage is the model of the storage backend configs.
+	Storage struct {
+		// Driver is the name of the storage backend. It can be "mongo", "postgres", "sqlite" or "memory".
+		Driver string `mapstructure:"driver"`
+	} `mapstructure:"storage"`
+
 	// Mongo is the model of the MongoDB configs.
 	Mongo struct {
 		// Addr of the MongoDB deployment.
@@ -39,4 +115,26 @@ type Model struct {
 		// DatabaseName is the name of the logical database in MongoDB.
 		DatabaseName string `mapstructure:"database_name"`
 	} `mapstructure:"mongo"`
+
+	// SQLite is the model of the SQLite configs.
+	SQLite struct {
+		// Path of the SQLite database file. It is created if it does not exist.
+		Path string `mapstructure:"path"`
+	} `mapstructure:"sqlite"`
+
+	// Postgres is the model of the PostgreSQL configs.
+	Postgres struct {
+		// Addr is the connection URL of the PostgreSQL database.
+		Addr string `mapstructure:"addr"`
+		// OperationTimeoutSec is the timeout in seconds for connecting with PostgreSQL.
+		OperationTimeoutSec int `mapstructure:"operation_timeout_sec"`
+	} `mapstructure:"postgres"`
+}
+
+// BucketLimitModel is the model of the limits of a token bucket.
+type BucketLimitModel struct {
+	// RequestsPerSec is the rate at which the bucket refills. Zero disables the limit.
+	RequestsPerSec float64 `mapstructure:"requests_per_sec"`
+	// Burst is the number of requests that can be made at once.
+	Burst int `mapstructure:"burst"`
 }
@@ -2,18 +2,33 @@ package database
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"time"
 
 	"github.com/shivanshkc/ledgerkeep/src/configs"
-	"github.com/shivanshkc/ledgerkeep/src/database/mongodb"
-
-	"go.mongodb.org/mongo-driver/mongo"
+	"github.com/shivanshkc/ledgerkeep/src/models"
+	"github.com/shivanshkc/ledgerkeep/src/utils/authutils"
+	"github.com/shivanshkc/ledgerkeep/src/utils/ctxutils"
+	"github.com/shivanshkc/ledgerkeep/src/utils/errutils"
 )
 
-const (
-	accountsCollectionName     = "accounts"
-	transactionsCollectionName = "transactions"
-)
+// DefaultTenantID is the tenant of the admin user of the configs. It holds all the data from before the introduction
+// of users, which had no tenants.
+const DefaultTenantID = "default"
+
+// errMissingTenant is returned by the tenant scoped storage operations if the context has no tenant.
+var errMissingTenant = errors.New("the context has no tenant")
+
+// getTenantID provides the ID of the tenant of the context, whose data the storage operations can access.
+// It fails if there is no tenant, so an operation can never access the data of all the tenants by mistake.
+func getTenantID(ctx context.Context) (string, error) {
+	tenantID := ctxutils.GetTenantID(ctx)
+	if tenantID == "" {
+		return "", errMissingTenant
+	}
+	return tenantID, nil
+}
 
 // ListTransactionsParams is the schema of params required by the ListTransactions operation.
 type ListTransactionsParams struct {
@@ -33,21 +48,106 @@ type ListTransactionsParams struct {
 	ExcludeCount bool
 }
 
-// getAccountsCollection provides the accounts mongoDB collection.
-func getAccountsCollection() *mongo.Collection {
-	conf := configs.Get()
-	return mongodb.GetClient().Database(conf.Mongo.DatabaseName).Collection(accountsCollectionName)
+// CategoryTotals is the schema of the aggregated amounts of the transactions of an account in a category.
+type CategoryTotals struct {
+	// AccountID is the ID of the account of the transactions.
+	AccountID string
+	// Category of the transactions.
+	Category string
+	// Credit is the sum of all positive amounts.
+	Credit models.Money
+	// Debit is the sum of all negative amounts.
+	Debit models.Money
 }
 
-// getTransactionsCollection provides the transactions mongoDB collection.
-func getTransactionsCollection() *mongo.Collection {
-	conf := configs.Get()
-	return mongodb.GetClient().Database(conf.Mongo.DatabaseName).Collection(transactionsCollectionName)
+// getTransactionLines provides the split lines of the transaction.
+// A transaction without splits is a single line of its full amount and category.
+func getTransactionLines(transaction *models.TransactionDTO) []*models.SplitDTO {
+	if len(transaction.Splits) > 0 {
+		return transaction.Splits
+	}
+	return []*models.SplitDTO{{Amount: transaction.Amount, Category: transaction.Category, Notes: transaction.Notes}}
+}
+
+// getDefaultCategories provides the categories that a new ledger starts with.
+func getDefaultCategories() []*models.CategoryDTO {
+	return []*models.CategoryDTO{
+		{ID: "earnings", Name: "Earnings", Kind: models.CategoryKindCredit, BudgetGroup: models.BudgetGroupIncome},
+		{ID: "essentials", Name: "Essentials", Kind: models.CategoryKindDebit, BudgetGroup: models.BudgetGroupEssentials},
+		{ID: "ignorable", Name: "Ignorable", Kind: models.CategoryKindBoth, BudgetGroup: models.BudgetGroupIgnorable},
+		{ID: "investments", Name: "Investments", Kind: models.CategoryKindDebit, BudgetGroup: models.BudgetGroupInvestments},
+		{ID: "luxury", Name: "Luxury", Kind: models.CategoryKindDebit, BudgetGroup: models.BudgetGroupLuxury},
+		{ID: "petty", Name: "Petty", Kind: models.CategoryKindCredit, BudgetGroup: models.BudgetGroupIncome},
+		{ID: "refunds", Name: "Refunds", Kind: models.CategoryKindCredit, BudgetGroup: models.BudgetGroupIncome},
+		{ID: "returns", Name: "Returns", Kind: models.CategoryKindCredit, BudgetGroup: models.BudgetGroupIncome},
+		{ID: "savings", Name: "Savings", Kind: models.CategoryKindDebit, BudgetGroup: models.BudgetGroupSavings},
+	}
 }
 
-// getTimeoutContext provides the timeout context for database operations.
-func getTimeoutContext(parent context.Context) (context.Context, context.CancelFunc) {
+// SeedDefaultCategories creates the default categories for the tenant of the context if it has no categories at all.
+// This keeps a new ledger, as well as a ledger from before the introduction of categories, usable right away.
+func SeedDefaultCategories(ctx context.Context, categories CategoryRepository) error {
+	existing, err := categories.ListCategories(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list categories: %w", err)
+	}
+	if len(existing) > 0 {
+		return nil
+	}
+
+	for _, category := range getDefaultCategories() {
+		err := categories.InsertCategory(ctx, category)
+		// Another instance of the application may be seeding the categories at the same time.
+		var errHTTP *errutils.HTTPError
+		if errors.As(err, &errHTTP) && errHTTP.CustomCode == errutils.CategoryAlreadyExists().CustomCode {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("failed to insert default category %s: %w", category.ID, err)
+		}
+	}
+	return nil
+}
+
+// seedAdminUser creates the admin user with the credentials of the configs, if it does not exist, and seeds the
+// default categories of its ledger. The admin user owns the DefaultTenantID.
+//
+// The configured password is only used to create the user, so a password that was changed later is kept.
+func seedAdminUser(ctx context.Context, repos *Repositories) error {
 	conf := configs.Get()
-	timeoutDuration := time.Duration(conf.Mongo.OperationTimeoutSec) * time.Second
-	return context.WithTimeout(parent, timeoutDuration)
+
+	_, err := repos.Users.GetUser(ctx, conf.Auth.Username)
+	var errHTTP *errutils.HTTPError
+	if errors.As(err, &errHTTP) && errHTTP.CustomCode == errutils.UserNotFound().CustomCode {
+		err = insertAdminUser(ctx, repos.Users, conf.Auth.Username, conf.Auth.Password)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to seed admin user: %w", err)
+	}
+
+	return SeedDefaultCategories(ctxutils.PutTenantID(ctx, DefaultTenantID), repos.Categories)
+}
+
+// insertAdminUser creates the admin user of the DefaultTenantID with the provided credentials.
+func insertAdminUser(ctx context.Context, users UserRepository, username string, password string) error {
+	passwordHash, err := authutils.HashPassword(password)
+	if err != nil {
+		return err
+	}
+
+	user := &models.UserDTO{
+		ID:           username,
+		PasswordHash: passwordHash,
+		IsAdmin:      true,
+		TenantID:     DefaultTenantID,
+		CreatedAt:    time.Now().Unix(),
+	}
+
+	err = users.InsertUser(ctx, user)
+	// Another instance of the application may be creating the user at the same time.
+	var errHTTP *errutils.HTTPError
+	if errors.As(err, &errHTTP) && errHTTP.CustomCode == errutils.UserAlreadyExists().CustomCode {
+		return nil
+	}
+	return err
 }
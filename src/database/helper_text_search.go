@@ -0,0 +1,75 @@
+package database
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// textSearchWordRegexp splits a text into the words that a text search can match.
+var textSearchWordRegexp = regexp.MustCompile(`[\p{L}\p{N}]+`)
+
+// textSearch is a parsed search string of the MongoDB $text operator.
+//
+// As per the $text semantics, the search is case-insensitive, a term prefixed by a hyphen excludes all texts that
+// contain it, texts must contain all the quoted phrases if there are any, and otherwise must contain at least one
+// of the terms.
+type textSearch struct {
+	// Phrases are the quoted parts of the search string.
+	Phrases []string
+	// Terms are the words of the search string that are neither quoted nor negated.
+	Terms []string
+	// NegatedTerms are the words of the search string that are prefixed by a hyphen.
+	NegatedTerms []string
+}
+
+// parseTextSearch parses the search string of a MongoDB $text operator. All values are lower-cased.
+func parseTextSearch(search string) *textSearch {
+	parsed := &textSearch{}
+	search = strings.ToLower(search)
+
+	// Separating the quoted phrases from the rest of the search string.
+	for {
+		start := strings.Index(search, `"`)
+		if start < 0 {
+			break
+		}
+		end := strings.Index(search[start+1:], `"`)
+		if end < 0 {
+			break
+		}
+		// Empty phrases are ignored as they cannot restrict the search.
+		if phrase := strings.TrimSpace(search[start+1 : start+1+end]); phrase != "" {
+			parsed.Phrases = append(parsed.Phrases, phrase)
+		}
+		search = search[:start] + " " + search[start+1+end+1:]
+	}
+
+	for _, token := range strings.Fields(search) {
+		isNegated := strings.HasPrefix(token, "-")
+		for _, word := range textSearchWordRegexp.FindAllString(token, -1) {
+			if isNegated {
+				parsed.NegatedTerms = append(parsed.NegatedTerms, word)
+			} else {
+				parsed.Terms = append(parsed.Terms, word)
+			}
+		}
+	}
+
+	return parsed
+}
+
+// getTextSearchString extracts the search string out of a $text filter condition.
+func getTextSearchString(condition interface{}) (string, error) {
+	conditionMap, ok := condition.(map[string]interface{})
+	if !ok {
+		return "", fmt.Errorf("$text condition should be a map, but was %T", condition)
+	}
+
+	search, ok := conditionMap["$search"].(string)
+	if !ok {
+		return "", fmt.Errorf("$text condition should have a string $search value")
+	}
+
+	return search, nil
+}
@@ -0,0 +1,225 @@
+package database
+
+import (
+	"context"
+
+	"github.com/shivanshkc/ledgerkeep/src/models"
+)
+
+// AccountRepository represents the storage operations for accounts.
+type AccountRepository interface {
+	// InsertAccount creates a new account.
+	InsertAccount(ctx context.Context, account *models.AccountDTO) error
+	// IsAccountExists returns true if the account with the provided ID exists.
+	IsAccountExists(ctx context.Context, accountID string) (bool, error)
+	// GetAccount returns the account with the provided ID.
+	GetAccount(ctx context.Context, accountID string) (*models.AccountDTO, error)
+	// IsAccountUsed returns true if even a single transaction is using the provided account.
+	IsAccountUsed(ctx context.Context, accountID string) (bool, error)
+	// ListAccounts provides a list of all accounts.
+	ListAccounts(ctx context.Context) ([]*models.AccountDTO, error)
+	// GetAccountBalances provides a map of account IDs to their balance.
+	GetAccountBalances(ctx context.Context) (map[string]models.Money, error)
+	// UpdateAccount updates the account with the provided ID.
+	UpdateAccount(ctx context.Context, accountID string, updates map[string]interface{}) error
+	// DeleteAccount deletes the account with the provided ID.
+	DeleteAccount(ctx context.Context, accountID string) error
+}
+
+// TransactionRepository represents the storage operations for transactions.
+type TransactionRepository interface {
+	// InsertTransaction creates a new transaction and returns its ID.
+	InsertTransaction(ctx context.Context, transaction *models.TransactionDTO) (string, error)
+	// GetTransaction returns the transaction with the provided ID.
+	GetTransaction(ctx context.Context, transactionID string) (*models.TransactionDTO, error)
+	// ListTransactions lists all the transactions that match the provided filter, pagination and sort params.
+	// It also returns the total count of the matching transactions, unless params.ExcludeCount is true.
+	ListTransactions(ctx context.Context, params *ListTransactionsParams) ([]*models.TransactionDTO, int, error)
+	// StreamTransactions provides a cursor over all the transactions that match the provided filter and sort params,
+	// so they can be read one at a time. The pagination params and params.ExcludeCount are ignored, and the split
+	// lines are not loaded.
+	StreamTransactions(ctx context.Context, params *ListTransactionsParams) (TransactionCursor, error)
+	// GetCategoryTotals aggregates the amounts of the transactions that match the filter,
+	// grouped by account and category.
+	GetCategoryTotals(ctx context.Context, filter map[string]interface{}) ([]*CategoryTotals, error)
+	// UpdateTransaction updates the transaction with the provided ID.
+	UpdateTransaction(ctx context.Context, transactionID string, updates map[string]interface{}) error
+	// DeleteTransaction deletes the transaction with the provided ID.
+	DeleteTransaction(ctx context.Context, transactionID string) error
+
+	// InsertTransactions creates all the provided transactions atomically and returns their IDs in the same order.
+	InsertTransactions(ctx context.Context, transactions []*models.TransactionDTO) ([]string, error)
+	// InsertTransfer creates all the legs of a transfer atomically and returns their IDs in the same order.
+	// The legs must carry the ID of the transfer.
+	InsertTransfer(ctx context.Context, legs []*models.TransactionDTO) ([]string, error)
+	// UpdateTransactions applies the updates of all the provided transactions atomically.
+	// The updates are keyed by transaction IDs.
+	UpdateTransactions(ctx context.Context, updates map[string]map[string]interface{}) error
+	// DeleteTransfer deletes all the legs of the transfer with the provided ID atomically.
+	DeleteTransfer(ctx context.Context, transferID string) error
+}
+
+// TransactionCursor iterates over the transactions of a query without loading all of them into memory.
+// It should always be closed.
+type TransactionCursor interface {
+	// Next reads the next transaction. It returns false once there are no more transactions, or upon an error.
+	Next(ctx context.Context) bool
+	// Transaction provides the transaction that the last Next call read.
+	Transaction() *models.TransactionDTO
+	// Err provides the error that stopped the cursor, if any.
+	Err() error
+	// Close releases the resources of the cursor.
+	Close(ctx context.Context) error
+}
+
+// CategoryRepository represents the storage operations for categories.
+type CategoryRepository interface {
+	// InsertCategory creates a new category.
+	InsertCategory(ctx context.Context, category *models.CategoryDTO) error
+	// GetCategory returns the category with the provided ID.
+	GetCategory(ctx context.Context, categoryID string) (*models.CategoryDTO, error)
+	// ListCategories provides a list of all categories in ascending order of their IDs.
+	ListCategories(ctx context.Context) ([]*models.CategoryDTO, error)
+	// IsCategoryUsed returns true if even a single transaction or split line is using the provided category.
+	IsCategoryUsed(ctx context.Context, categoryID string) (bool, error)
+	// UpdateCategory updates the category with the provided ID.
+	UpdateCategory(ctx context.Context, categoryID string, updates map[string]interface{}) error
+	// DeleteCategory deletes the category with the provided ID.
+	DeleteCategory(ctx context.Context, categoryID string) error
+}
+
+// ExchangeRateRepository represents the storage operations for exchange rates.
+type ExchangeRateRepository interface {
+	// PutExchangeRates saves all the provided exchange rates.
+	// A rate replaces the existing rate of the same currency pair and timestamp, if any.
+	PutExchangeRates(ctx context.Context, rates []*models.ExchangeRateDTO) error
+	// ListExchangeRates lists the exchange rates of the provided currency pair in ascending order of their timestamps.
+	// An empty base or quote currency matches all currencies.
+	ListExchangeRates(ctx context.Context, base string, quote string) ([]*models.ExchangeRateDTO, error)
+	// DeleteExchangeRate deletes the exchange rate with the provided ID.
+	DeleteExchangeRate(ctx context.Context, rateID string) error
+}
+
+// BudgetPlanRepository represents the storage operations for budget plans.
+type BudgetPlanRepository interface {
+	// InsertBudgetPlan creates a new budget plan and returns its ID.
+	InsertBudgetPlan(ctx context.Context, plan *models.BudgetPlanDTO) (string, error)
+	// ListBudgetPlans provides a list of all budget plans in ascending order of their validity start times.
+	ListBudgetPlans(ctx context.Context) ([]*models.BudgetPlanDTO, error)
+	// DeleteBudgetPlan deletes the budget plan with the provided ID.
+	DeleteBudgetPlan(ctx context.Context, planID string) error
+}
+
+// RecurringTemplateRepository represents the storage operations for recurring transaction templates.
+type RecurringTemplateRepository interface {
+	// InsertRecurringTemplate creates a new recurring template and returns its ID.
+	InsertRecurringTemplate(ctx context.Context, template *models.RecurringTemplateDTO) (string, error)
+	// GetRecurringTemplate returns the recurring template with the provided ID.
+	GetRecurringTemplate(ctx context.Context, templateID string) (*models.RecurringTemplateDTO, error)
+	// ListRecurringTemplates provides a list of all recurring templates in ascending order of their IDs.
+	ListRecurringTemplates(ctx context.Context) ([]*models.RecurringTemplateDTO, error)
+	// DeleteRecurringTemplate deletes the recurring template with the provided ID.
+	// The transactions that were materialized from it are kept.
+	DeleteRecurringTemplate(ctx context.Context, templateID string) error
+	// AdvanceRecurringTemplate inserts the materialized transactions of the template and moves its next run time from
+	// currentNextRun to nextRun.
+	//
+	// It returns false, without inserting anything, if the next run time of the template is not currentNextRun anymore.
+	// That happens when another run has materialized the same occurrences already, which makes the runs idempotent.
+	AdvanceRecurringTemplate(ctx context.Context, templateID string, currentNextRun int64, nextRun int64,
+		transactions []*models.TransactionDTO) (bool, error)
+}
+
+// ImportProfileRepository represents the storage operations for import profiles.
+type ImportProfileRepository interface {
+	// InsertImportProfile creates a new import profile and returns its ID.
+	InsertImportProfile(ctx context.Context, profile *models.ImportProfileDTO) (string, error)
+	// GetImportProfile returns the import profile with the provided ID.
+	GetImportProfile(ctx context.Context, profileID string) (*models.ImportProfileDTO, error)
+	// ListImportProfiles provides a list of all import profiles in ascending order of their IDs.
+	ListImportProfiles(ctx context.Context) ([]*models.ImportProfileDTO, error)
+	// DeleteImportProfile deletes the import profile with the provided ID.
+	DeleteImportProfile(ctx context.Context, profileID string) error
+}
+
+// RuleRepository represents the storage operations for categorization rules.
+type RuleRepository interface {
+	// InsertRule creates a new rule and returns its ID.
+	InsertRule(ctx context.Context, rule *models.RuleDTO) (string, error)
+	// ListRules provides a list of all rules in ascending order of their priorities, and then their IDs.
+	ListRules(ctx context.Context) ([]*models.RuleDTO, error)
+	// DeleteRule deletes the rule with the provided ID.
+	DeleteRule(ctx context.Context, ruleID string) error
+}
+
+// BackupRepository represents the storage operations for the backups of the whole ledger.
+type BackupRepository interface {
+	// ReplaceLedger replaces all the data of the ledger with the provided data atomically. The IDs of the provided
+	// data are kept as they are. The databases that cannot replace it atomically refuse it with TransactionsNotSupported.
+	ReplaceLedger(ctx context.Context, data *models.LedgerData) error
+}
+
+// UserRepository represents the storage operations for users.
+//
+// Unlike the other repositories, it is not scoped to a tenant, as the users have to be looked up to find their tenants.
+type UserRepository interface {
+	// InsertUser creates a new user.
+	InsertUser(ctx context.Context, user *models.UserDTO) error
+	// GetUser returns the user with the provided ID.
+	GetUser(ctx context.Context, userID string) (*models.UserDTO, error)
+	// ListUsers provides a list of all users in ascending order of their IDs.
+	ListUsers(ctx context.Context) ([]*models.UserDTO, error)
+	// UpdateUser updates the user with the provided ID.
+	UpdateUser(ctx context.Context, userID string, updates map[string]interface{}) error
+}
+
+// MemberRepository represents the storage operations for the memberships of the shared ledgers.
+//
+// Like the UserRepository, it is not scoped to a tenant, as the memberships decide which tenants a user can access.
+type MemberRepository interface {
+	// InsertMember shares the ledger of the membership with its user.
+	InsertMember(ctx context.Context, member *models.MemberDTO) error
+	// GetMember returns the membership of the user in the ledger.
+	GetMember(ctx context.Context, ledgerID string, userID string) (*models.MemberDTO, error)
+	// ListLedgerMembers provides all the memberships of the ledger in ascending order of the user IDs.
+	ListLedgerMembers(ctx context.Context, ledgerID string) ([]*models.MemberDTO, error)
+	// ListUserMemberships provides all the memberships of the user in ascending order of the ledger IDs.
+	ListUserMemberships(ctx context.Context, userID string) ([]*models.MemberDTO, error)
+	// DeleteMember revokes the membership of the user in the ledger.
+	DeleteMember(ctx context.Context, ledgerID string, userID string) error
+}
+
+// TokenRepository represents the storage operations for the API tokens.
+//
+// Like the UserRepository, it is not scoped to a tenant, as the tokens belong to the users.
+type TokenRepository interface {
+	// InsertToken inserts a new API token.
+	InsertToken(ctx context.Context, token *models.TokenDTO) error
+	// GetTokenByHash returns the API token with the provided hash.
+	GetTokenByHash(ctx context.Context, tokenHash string) (*models.TokenDTO, error)
+	// ListUserTokens provides all the API tokens of the user in ascending order of their IDs.
+	ListUserTokens(ctx context.Context, userID string) ([]*models.TokenDTO, error)
+	// UpdateTokenLastUsed sets the time of the last use of the API token.
+	UpdateTokenLastUsed(ctx context.Context, tokenID string, lastUsedAt int64) error
+	// DeleteToken revokes the API token of the user.
+	DeleteToken(ctx context.Context, userID string, tokenID string) error
+}
+
+// Repositories groups together all the repositories of a storage backend.
+//
+// All the repositories, other than the Users, the Members and the Tokens, only access the data of the tenant of the
+// context, which is put in it by ctxutils.PutTenantID. They fail if the context has no tenant.
+type Repositories struct {
+	Accounts      AccountRepository
+	Transactions  TransactionRepository
+	Categories    CategoryRepository
+	ExchangeRates ExchangeRateRepository
+	BudgetPlans   BudgetPlanRepository
+	Recurring     RecurringTemplateRepository
+	Imports       ImportProfileRepository
+	Rules         RuleRepository
+	Backups       BackupRepository
+	Users         UserRepository
+	Members       MemberRepository
+	Tokens        TokenRepository
+}
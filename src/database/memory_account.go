@@ -0,0 +1,177 @@
+package database
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/shivanshkc/ledgerkeep/src/models"
+	"github.com/shivanshkc/ledgerkeep/src/utils/errutils"
+)
+
+// memoryAccountRepository implements AccountRepository using the in-memory store.
+type memoryAccountRepository struct {
+	stores *memoryStores
+}
+
+func (m *memoryAccountRepository) InsertAccount(ctx context.Context, account *models.AccountDTO) error {
+	store, err := m.stores.get(ctx)
+	if err != nil {
+		return err
+	}
+
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	// Account ID is the primary key, so it cannot be duplicated.
+	if store.findAccountIndex(account.ID) >= 0 {
+		return errutils.AccountAlreadyExists()
+	}
+
+	accountCopy := *account
+	store.accounts = append(store.accounts, &accountCopy)
+	return nil
+}
+
+func (m *memoryAccountRepository) IsAccountExists(ctx context.Context, accountID string) (bool, error) {
+	store, err := m.stores.get(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	store.mutex.RLock()
+	defer store.mutex.RUnlock()
+
+	return store.findAccountIndex(accountID) >= 0, nil
+}
+
+func (m *memoryAccountRepository) GetAccount(ctx context.Context, accountID string) (*models.AccountDTO, error) {
+	store, err := m.stores.get(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	store.mutex.RLock()
+	defer store.mutex.RUnlock()
+
+	index := store.findAccountIndex(accountID)
+	if index < 0 {
+		return nil, errutils.AccountNotFound()
+	}
+
+	accountCopy := *store.accounts[index]
+	return &accountCopy, nil
+}
+
+func (m *memoryAccountRepository) IsAccountUsed(ctx context.Context, accountID string) (bool, error) {
+	store, err := m.stores.get(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	store.mutex.RLock()
+	defer store.mutex.RUnlock()
+
+	for _, tx := range store.transactions {
+		if tx.AccountID == accountID {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (m *memoryAccountRepository) ListAccounts(ctx context.Context) ([]*models.AccountDTO, error) {
+	store, err := m.stores.get(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	store.mutex.RLock()
+	defer store.mutex.RUnlock()
+
+	results := make([]*models.AccountDTO, len(store.accounts))
+	for idx, acc := range store.accounts {
+		accountCopy := *acc
+		results[idx] = &accountCopy
+	}
+
+	return results, nil
+}
+
+func (m *memoryAccountRepository) GetAccountBalances(ctx context.Context) (map[string]models.Money, error) {
+	store, err := m.stores.get(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	store.mutex.RLock()
+	defer store.mutex.RUnlock()
+
+	// Like the MongoDB $group stage, this includes every account ID used by a transaction,
+	// whether the account exists or not.
+	balanceMap := map[string]models.Money{}
+	for _, tx := range store.transactions {
+		balanceMap[tx.AccountID] += tx.Amount
+	}
+
+	return balanceMap, nil
+}
+
+func (m *memoryAccountRepository) UpdateAccount(ctx context.Context, accountID string, updates map[string]interface{}) error {
+	store, err := m.stores.get(ctx)
+	if err != nil {
+		return err
+	}
+
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	index := store.findAccountIndex(accountID)
+	if index < 0 {
+		return errutils.AccountNotFound()
+	}
+
+	// Validating and applying the updates on a copy, so a failure does not leave a partial update behind.
+	accountCopy := *store.accounts[index]
+	for field, value := range updates {
+		if field != "name" {
+			return fmt.Errorf("unsupported update field: %s", field)
+		}
+		name, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("invalid value type %T for field: %s", value, field)
+		}
+		accountCopy.Name = name
+	}
+
+	store.accounts[index] = &accountCopy
+	return nil
+}
+
+func (m *memoryAccountRepository) DeleteAccount(ctx context.Context, accountID string) error {
+	store, err := m.stores.get(ctx)
+	if err != nil {
+		return err
+	}
+
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	index := store.findAccountIndex(accountID)
+	if index < 0 {
+		return errutils.AccountNotFound()
+	}
+
+	store.accounts = append(store.accounts[:index], store.accounts[index+1:]...)
+	return nil
+}
+
+// findAccountIndex provides the index of the account in the store. It returns -1 if the account does not exist.
+// The caller must hold the store's lock.
+func (s *memoryStore) findAccountIndex(accountID string) int {
+	for idx, acc := range s.accounts {
+		if acc.ID == accountID {
+			return idx
+		}
+	}
+	return -1
+}
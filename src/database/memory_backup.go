@@ -0,0 +1,80 @@
+package database
+
+import (
+	"context"
+
+	"github.com/shivanshkc/ledgerkeep/src/models"
+)
+
+// memoryBackupRepository implements BackupRepository using the in-memory store.
+type memoryBackupRepository struct {
+	stores *memoryStores
+}
+
+func (m *memoryBackupRepository) ReplaceLedger(ctx context.Context, data *models.LedgerData) error {
+	store, err := m.stores.get(ctx)
+	if err != nil {
+		return err
+	}
+
+	// All the data is copied before taking the lock, so the store is replaced at once.
+	accounts := make([]*models.AccountDTO, len(data.Accounts))
+	for idx, account := range data.Accounts {
+		accountCopy := *account
+		accounts[idx] = &accountCopy
+	}
+
+	transactions := make(map[string]*models.TransactionDTO, len(data.Transactions))
+	for _, transaction := range data.Transactions {
+		txCopy := copyTransaction(transaction)
+		txCopy.ClosingBal = 0
+		transactions[txCopy.ID] = &txCopy
+	}
+
+	categories := make(map[string]*models.CategoryDTO, len(data.Categories))
+	for _, category := range data.Categories {
+		categoryCopy := *category
+		categories[categoryCopy.ID] = &categoryCopy
+	}
+
+	exchangeRates := make(map[string]*models.ExchangeRateDTO, len(data.ExchangeRates))
+	for _, rate := range data.ExchangeRates {
+		rateCopy := *rate
+		exchangeRates[rateCopy.ID] = &rateCopy
+	}
+
+	budgetPlans := make(map[string]*models.BudgetPlanDTO, len(data.BudgetPlans))
+	for _, plan := range data.BudgetPlans {
+		budgetPlans[plan.ID] = copyBudgetPlan(plan)
+	}
+
+	recurringTemplates := make(map[string]*models.RecurringTemplateDTO, len(data.RecurringTemplates))
+	for _, template := range data.RecurringTemplates {
+		templateCopy := *template
+		recurringTemplates[templateCopy.ID] = &templateCopy
+	}
+
+	importProfiles := make(map[string]*models.ImportProfileDTO, len(data.ImportProfiles))
+	for _, profile := range data.ImportProfiles {
+		profileCopy := *profile
+		importProfiles[profileCopy.ID] = &profileCopy
+	}
+
+	rules := make(map[string]*models.RuleDTO, len(data.Rules))
+	for _, rule := range data.Rules {
+		rules[rule.ID] = copyRule(rule)
+	}
+
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	store.accounts = accounts
+	store.transactions = transactions
+	store.categories = categories
+	store.exchangeRates = exchangeRates
+	store.budgetPlans = budgetPlans
+	store.recurringTemplates = recurringTemplates
+	store.importProfiles = importProfiles
+	store.rules = rules
+	return nil
+}
@@ -0,0 +1,96 @@
+package database
+
+import (
+	"context"
+	"sort"
+
+	"github.com/shivanshkc/ledgerkeep/src/models"
+	"github.com/shivanshkc/ledgerkeep/src/utils/errutils"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// memoryBudgetPlanRepository implements BudgetPlanRepository using the in-memory store.
+type memoryBudgetPlanRepository struct {
+	stores *memoryStores
+}
+
+func (m *memoryBudgetPlanRepository) InsertBudgetPlan(ctx context.Context, plan *models.BudgetPlanDTO) (string, error) {
+	store, err := m.stores.get(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	planCopy := copyBudgetPlan(plan)
+	// Budget plan IDs are ObjectIDs, just like the ones generated by MongoDB.
+	planCopy.ID = primitive.NewObjectID().Hex()
+
+	store.budgetPlans[planCopy.ID] = planCopy
+	return planCopy.ID, nil
+}
+
+func (m *memoryBudgetPlanRepository) ListBudgetPlans(ctx context.Context) ([]*models.BudgetPlanDTO, error) {
+	store, err := m.stores.get(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	store.mutex.RLock()
+	defer store.mutex.RUnlock()
+
+	results := make([]*models.BudgetPlanDTO, 0, len(store.budgetPlans))
+	for _, plan := range store.budgetPlans {
+		results = append(results, copyBudgetPlan(plan))
+	}
+
+	// Sorting by validity start time, and then by ID for a stable order.
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].ValidFrom != results[j].ValidFrom {
+			return results[i].ValidFrom < results[j].ValidFrom
+		}
+		return results[i].ID < results[j].ID
+	})
+
+	return results, nil
+}
+
+func (m *memoryBudgetPlanRepository) DeleteBudgetPlan(ctx context.Context, planID string) error {
+	store, err := m.stores.get(ctx)
+	if err != nil {
+		return err
+	}
+
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	if _, exists := store.budgetPlans[planID]; !exists {
+		return errutils.BudgetPlanNotFound()
+	}
+
+	delete(store.budgetPlans, planID)
+	return nil
+}
+
+// copyBudgetPlan provides a deep copy of the budget plan, so the stored plans are never shared with the callers.
+func copyBudgetPlan(plan *models.BudgetPlanDTO) *models.BudgetPlanDTO {
+	planCopy := *plan
+	planCopy.Allocations = make([]*models.BudgetAllocationDTO, len(plan.Allocations))
+
+	for idx, allocation := range plan.Allocations {
+		allocationCopy := *allocation
+		if allocation.Percent != nil {
+			percent := *allocation.Percent
+			allocationCopy.Percent = &percent
+		}
+		if allocation.Amount != nil {
+			amount := *allocation.Amount
+			allocationCopy.Amount = &amount
+		}
+		planCopy.Allocations[idx] = &allocationCopy
+	}
+
+	return &planCopy
+}
@@ -0,0 +1,148 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/shivanshkc/ledgerkeep/src/models"
+	"github.com/shivanshkc/ledgerkeep/src/utils/errutils"
+)
+
+// memoryCategoryRepository implements CategoryRepository using the in-memory store.
+type memoryCategoryRepository struct {
+	stores *memoryStores
+}
+
+func (m *memoryCategoryRepository) InsertCategory(ctx context.Context, category *models.CategoryDTO) error {
+	store, err := m.stores.get(ctx)
+	if err != nil {
+		return err
+	}
+
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	if _, exists := store.categories[category.ID]; exists {
+		return errutils.CategoryAlreadyExists()
+	}
+
+	categoryCopy := *category
+	store.categories[categoryCopy.ID] = &categoryCopy
+	return nil
+}
+
+func (m *memoryCategoryRepository) GetCategory(ctx context.Context, categoryID string) (*models.CategoryDTO, error) {
+	store, err := m.stores.get(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	store.mutex.RLock()
+	defer store.mutex.RUnlock()
+
+	category, exists := store.categories[categoryID]
+	if !exists {
+		return nil, errutils.CategoryNotFound()
+	}
+
+	categoryCopy := *category
+	return &categoryCopy, nil
+}
+
+func (m *memoryCategoryRepository) ListCategories(ctx context.Context) ([]*models.CategoryDTO, error) {
+	store, err := m.stores.get(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	store.mutex.RLock()
+	defer store.mutex.RUnlock()
+
+	results := make([]*models.CategoryDTO, 0, len(store.categories))
+	for _, category := range store.categories {
+		categoryCopy := *category
+		results = append(results, &categoryCopy)
+	}
+
+	sort.Slice(results, func(i, j int) bool { return results[i].ID < results[j].ID })
+	return results, nil
+}
+
+func (m *memoryCategoryRepository) IsCategoryUsed(ctx context.Context, categoryID string) (bool, error) {
+	store, err := m.stores.get(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	store.mutex.RLock()
+	defer store.mutex.RUnlock()
+
+	for _, tx := range store.transactions {
+		if tx.Category == categoryID {
+			return true, nil
+		}
+		for _, split := range tx.Splits {
+			if split.Category == categoryID {
+				return true, nil
+			}
+		}
+	}
+
+	return false, nil
+}
+
+func (m *memoryCategoryRepository) UpdateCategory(ctx context.Context, categoryID string, updates map[string]interface{}) error {
+	store, err := m.stores.get(ctx)
+	if err != nil {
+		return err
+	}
+
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	category, exists := store.categories[categoryID]
+	if !exists {
+		return errutils.CategoryNotFound()
+	}
+
+	// Applying the updates on a copy, so a failure does not leave a partial update behind.
+	categoryCopy := *category
+	for field, value := range updates {
+		strValue, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("invalid value type %T for field: %s", value, field)
+		}
+
+		switch field {
+		case "name":
+			categoryCopy.Name = strValue
+		case "kind":
+			categoryCopy.Kind = strValue
+		case "budget_group":
+			categoryCopy.BudgetGroup = strValue
+		default:
+			return fmt.Errorf("unsupported update field: %s", field)
+		}
+	}
+
+	store.categories[categoryID] = &categoryCopy
+	return nil
+}
+
+func (m *memoryCategoryRepository) DeleteCategory(ctx context.Context, categoryID string) error {
+	store, err := m.stores.get(ctx)
+	if err != nil {
+		return err
+	}
+
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	if _, exists := store.categories[categoryID]; !exists {
+		return errutils.CategoryNotFound()
+	}
+
+	delete(store.categories, categoryID)
+	return nil
+}
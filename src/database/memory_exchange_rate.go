@@ -0,0 +1,96 @@
+package database
+
+import (
+	"context"
+	"sort"
+
+	"github.com/shivanshkc/ledgerkeep/src/models"
+	"github.com/shivanshkc/ledgerkeep/src/utils/errutils"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// memoryExchangeRateRepository implements ExchangeRateRepository using the in-memory store.
+type memoryExchangeRateRepository struct {
+	stores *memoryStores
+}
+
+func (m *memoryExchangeRateRepository) PutExchangeRates(ctx context.Context, rates []*models.ExchangeRateDTO) error {
+	store, err := m.stores.get(ctx)
+	if err != nil {
+		return err
+	}
+
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	for _, rate := range rates {
+		// The currency pair and timestamp are unique, so an existing rate is replaced, but keeps its ID.
+		if existing := store.findExchangeRate(rate.Base, rate.Quote, rate.Timestamp); existing != nil {
+			existing.Rate = rate.Rate
+			continue
+		}
+
+		rateCopy := *rate
+		rateCopy.ID = primitive.NewObjectID().Hex()
+		store.exchangeRates[rateCopy.ID] = &rateCopy
+	}
+
+	return nil
+}
+
+func (m *memoryExchangeRateRepository) ListExchangeRates(ctx context.Context, base string, quote string) ([]*models.ExchangeRateDTO, error) {
+	store, err := m.stores.get(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	store.mutex.RLock()
+	defer store.mutex.RUnlock()
+
+	results := []*models.ExchangeRateDTO{}
+	for _, rate := range store.exchangeRates {
+		if (base == "" || rate.Base == base) && (quote == "" || rate.Quote == quote) {
+			rateCopy := *rate
+			results = append(results, &rateCopy)
+		}
+	}
+
+	// Sorting by timestamp, and then by ID for a stable order.
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Timestamp != results[j].Timestamp {
+			return results[i].Timestamp < results[j].Timestamp
+		}
+		return results[i].ID < results[j].ID
+	})
+
+	return results, nil
+}
+
+func (m *memoryExchangeRateRepository) DeleteExchangeRate(ctx context.Context, rateID string) error {
+	store, err := m.stores.get(ctx)
+	if err != nil {
+		return err
+	}
+
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	if _, exists := store.exchangeRates[rateID]; !exists {
+		return errutils.ExchangeRateNotFound()
+	}
+
+	delete(store.exchangeRates, rateID)
+	return nil
+}
+
+// findExchangeRate finds the stored exchange rate of the currency pair and timestamp. It is nil if it does not exist.
+// The caller must hold the store's lock.
+func (s *memoryStore) findExchangeRate(base string, quote string, timestamp int64) *models.ExchangeRateDTO {
+	for _, rate := range s.exchangeRates {
+		if rate.Base == base && rate.Quote == quote && rate.Timestamp == timestamp {
+			return rate
+		}
+	}
+	return nil
+}
@@ -0,0 +1,87 @@
+package database
+
+import (
+	"context"
+	"sort"
+
+	"github.com/shivanshkc/ledgerkeep/src/models"
+	"github.com/shivanshkc/ledgerkeep/src/utils/errutils"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// memoryImportProfileRepository implements ImportProfileRepository using the in-memory store.
+type memoryImportProfileRepository struct {
+	stores *memoryStores
+}
+
+func (m *memoryImportProfileRepository) InsertImportProfile(ctx context.Context, profile *models.ImportProfileDTO) (string, error) {
+	store, err := m.stores.get(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	// Import profile IDs are ObjectIDs, just like the ones generated by MongoDB.
+	profileCopy := *profile
+	profileCopy.ID = primitive.NewObjectID().Hex()
+
+	store.importProfiles[profileCopy.ID] = &profileCopy
+	return profileCopy.ID, nil
+}
+
+func (m *memoryImportProfileRepository) GetImportProfile(ctx context.Context, profileID string) (*models.ImportProfileDTO, error) {
+	store, err := m.stores.get(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	store.mutex.RLock()
+	defer store.mutex.RUnlock()
+
+	profile, exists := store.importProfiles[profileID]
+	if !exists {
+		return nil, errutils.ImportProfileNotFound()
+	}
+
+	profileCopy := *profile
+	return &profileCopy, nil
+}
+
+func (m *memoryImportProfileRepository) ListImportProfiles(ctx context.Context) ([]*models.ImportProfileDTO, error) {
+	store, err := m.stores.get(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	store.mutex.RLock()
+	defer store.mutex.RUnlock()
+
+	results := make([]*models.ImportProfileDTO, 0, len(store.importProfiles))
+	for _, profile := range store.importProfiles {
+		profileCopy := *profile
+		results = append(results, &profileCopy)
+	}
+
+	sort.Slice(results, func(i, j int) bool { return results[i].ID < results[j].ID })
+	return results, nil
+}
+
+func (m *memoryImportProfileRepository) DeleteImportProfile(ctx context.Context, profileID string) error {
+	store, err := m.stores.get(ctx)
+	if err != nil {
+		return err
+	}
+
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	if _, exists := store.importProfiles[profileID]; !exists {
+		return errutils.ImportProfileNotFound()
+	}
+
+	delete(store.importProfiles, profileID)
+	return nil
+}
@@ -0,0 +1,93 @@
+package database
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/shivanshkc/ledgerkeep/src/models"
+	"github.com/shivanshkc/ledgerkeep/src/utils/errutils"
+)
+
+// memoryMemberKey identifies a membership in the memoryMemberRepository.
+type memoryMemberKey struct {
+	ledgerID string
+	userID   string
+}
+
+// memoryMemberRepository implements MemberRepository in memory.
+// The memberships are not kept in the memoryStores, because they do not belong to any one tenant.
+type memoryMemberRepository struct {
+	// mutex guards the members map.
+	mutex *sync.RWMutex
+	// members is a map of the ledger and user IDs to the memberships.
+	members map[memoryMemberKey]*models.MemberDTO
+}
+
+func (m *memoryMemberRepository) InsertMember(ctx context.Context, member *models.MemberDTO) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	// A user can only have one membership per ledger.
+	key := memoryMemberKey{ledgerID: member.LedgerID, userID: member.UserID}
+	if _, exists := m.members[key]; exists {
+		return errutils.MemberAlreadyExists()
+	}
+
+	memberCopy := *member
+	m.members[key] = &memberCopy
+	return nil
+}
+
+func (m *memoryMemberRepository) GetMember(ctx context.Context, ledgerID string, userID string) (*models.MemberDTO, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	member, exists := m.members[memoryMemberKey{ledgerID: ledgerID, userID: userID}]
+	if !exists {
+		return nil, errutils.MemberNotFound()
+	}
+
+	memberCopy := *member
+	return &memberCopy, nil
+}
+
+func (m *memoryMemberRepository) ListLedgerMembers(ctx context.Context, ledgerID string) ([]*models.MemberDTO, error) {
+	results := m.filterMembers(func(member *models.MemberDTO) bool { return member.LedgerID == ledgerID })
+	sort.Slice(results, func(i, j int) bool { return results[i].UserID < results[j].UserID })
+	return results, nil
+}
+
+func (m *memoryMemberRepository) ListUserMemberships(ctx context.Context, userID string) ([]*models.MemberDTO, error) {
+	results := m.filterMembers(func(member *models.MemberDTO) bool { return member.UserID == userID })
+	sort.Slice(results, func(i, j int) bool { return results[i].LedgerID < results[j].LedgerID })
+	return results, nil
+}
+
+func (m *memoryMemberRepository) DeleteMember(ctx context.Context, ledgerID string, userID string) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	key := memoryMemberKey{ledgerID: ledgerID, userID: userID}
+	if _, exists := m.members[key]; !exists {
+		return errutils.MemberNotFound()
+	}
+
+	delete(m.members, key)
+	return nil
+}
+
+// filterMembers provides copies of all the memberships that match the provided function.
+func (m *memoryMemberRepository) filterMembers(matches func(member *models.MemberDTO) bool) []*models.MemberDTO {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	results := []*models.MemberDTO{}
+	for _, member := range m.members {
+		if matches(member) {
+			memberCopy := *member
+			results = append(results, &memberCopy)
+		}
+	}
+	return results
+}
@@ -0,0 +1,119 @@
+package database
+
+import (
+	"context"
+	"sort"
+
+	"github.com/shivanshkc/ledgerkeep/src/models"
+	"github.com/shivanshkc/ledgerkeep/src/utils/errutils"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// memoryRecurringTemplateRepository implements RecurringTemplateRepository using the in-memory store.
+type memoryRecurringTemplateRepository struct {
+	stores *memoryStores
+}
+
+func (m *memoryRecurringTemplateRepository) InsertRecurringTemplate(ctx context.Context,
+	template *models.RecurringTemplateDTO) (string, error) {
+	store, err := m.stores.get(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	// Recurring template IDs are ObjectIDs, just like the ones generated by MongoDB.
+	templateCopy := *template
+	templateCopy.ID = primitive.NewObjectID().Hex()
+
+	store.recurringTemplates[templateCopy.ID] = &templateCopy
+	return templateCopy.ID, nil
+}
+
+func (m *memoryRecurringTemplateRepository) GetRecurringTemplate(ctx context.Context, templateID string) (*models.RecurringTemplateDTO, error) {
+	store, err := m.stores.get(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	store.mutex.RLock()
+	defer store.mutex.RUnlock()
+
+	template, exists := store.recurringTemplates[templateID]
+	if !exists {
+		return nil, errutils.RecurringTemplateNotFound()
+	}
+
+	templateCopy := *template
+	return &templateCopy, nil
+}
+
+func (m *memoryRecurringTemplateRepository) ListRecurringTemplates(ctx context.Context) ([]*models.RecurringTemplateDTO, error) {
+	store, err := m.stores.get(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	store.mutex.RLock()
+	defer store.mutex.RUnlock()
+
+	results := make([]*models.RecurringTemplateDTO, 0, len(store.recurringTemplates))
+	for _, template := range store.recurringTemplates {
+		templateCopy := *template
+		results = append(results, &templateCopy)
+	}
+
+	sort.Slice(results, func(i, j int) bool { return results[i].ID < results[j].ID })
+	return results, nil
+}
+
+func (m *memoryRecurringTemplateRepository) DeleteRecurringTemplate(ctx context.Context, templateID string) error {
+	store, err := m.stores.get(ctx)
+	if err != nil {
+		return err
+	}
+
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	if _, exists := store.recurringTemplates[templateID]; !exists {
+		return errutils.RecurringTemplateNotFound()
+	}
+
+	delete(store.recurringTemplates, templateID)
+	return nil
+}
+
+func (m *memoryRecurringTemplateRepository) AdvanceRecurringTemplate(ctx context.Context, templateID string,
+	currentNextRun int64, nextRun int64, transactions []*models.TransactionDTO) (bool, error) {
+	store, err := m.stores.get(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	template, exists := store.recurringTemplates[templateID]
+	if !exists {
+		return false, errutils.RecurringTemplateNotFound()
+	}
+	if template.NextRun != currentNextRun {
+		return false, nil
+	}
+
+	for _, transaction := range transactions {
+		// Transaction IDs are ObjectIDs, just like the ones generated by MongoDB.
+		txCopy := copyTransaction(transaction)
+		txCopy.ID = primitive.NewObjectID().Hex()
+		txCopy.ClosingBal = 0
+
+		store.transactions[txCopy.ID] = &txCopy
+	}
+
+	template.NextRun = nextRun
+	return true, nil
+}
@@ -0,0 +1,417 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/shivanshkc/ledgerkeep/src/models"
+)
+
+// memoryStore holds all the data of the in-memory storage backend.
+// Data is lost as soon as the application exits, so it is only suitable for local development and tests.
+type memoryStore struct {
+	// mutex guards all the fields below.
+	mutex *sync.RWMutex
+	// accounts are kept in a slice to preserve their insertion order, like MongoDB's natural order.
+	accounts []*models.AccountDTO
+	// transactions is a map of transaction IDs to transactions.
+	transactions map[string]*models.TransactionDTO
+	// categories is a map of category IDs to categories.
+	categories map[string]*models.CategoryDTO
+	// exchangeRates is a map of exchange rate IDs to exchange rates.
+	exchangeRates map[string]*models.ExchangeRateDTO
+	// budgetPlans is a map of budget plan IDs to budget plans.
+	budgetPlans map[string]*models.BudgetPlanDTO
+	// recurringTemplates is a map of recurring template IDs to recurring templates.
+	recurringTemplates map[string]*models.RecurringTemplateDTO
+	// importProfiles is a map of import profile IDs to import profiles.
+	importProfiles map[string]*models.ImportProfileDTO
+	// rules is a map of rule IDs to categorization rules.
+	rules map[string]*models.RuleDTO
+}
+
+// memoryStores holds the memoryStore of every tenant.
+type memoryStores struct {
+	// mutex guards the stores map, but not the stores themselves, which have their own locks.
+	mutex  *sync.Mutex
+	stores map[string]*memoryStore
+}
+
+// get provides the memoryStore of the tenant of the context. The store of a new tenant is created empty.
+func (m *memoryStores) get(ctx context.Context) (*memoryStore, error) {
+	tenantID, err := getTenantID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	store, exists := m.stores[tenantID]
+	if !exists {
+		store = &memoryStore{
+			mutex:              &sync.RWMutex{},
+			transactions:       map[string]*models.TransactionDTO{},
+			categories:         map[string]*models.CategoryDTO{},
+			exchangeRates:      map[string]*models.ExchangeRateDTO{},
+			budgetPlans:        map[string]*models.BudgetPlanDTO{},
+			recurringTemplates: map[string]*models.RecurringTemplateDTO{},
+			importProfiles:     map[string]*models.ImportProfileDTO{},
+			rules:              map[string]*models.RuleDTO{},
+		}
+		m.stores[tenantID] = store
+	}
+	return store, nil
+}
+
+// NewMemoryRepositories provides new Repositories that keep all the data in memory.
+// Panic is allowed here because storage is crucial to the application.
+func NewMemoryRepositories() *Repositories {
+	stores := &memoryStores{mutex: &sync.Mutex{}, stores: map[string]*memoryStore{}}
+
+	repos := &Repositories{
+		Accounts:      &memoryAccountRepository{stores: stores},
+		Transactions:  &memoryTransactionRepository{stores: stores},
+		Categories:    &memoryCategoryRepository{stores: stores},
+		ExchangeRates: &memoryExchangeRateRepository{stores: stores},
+		BudgetPlans:   &memoryBudgetPlanRepository{stores: stores},
+		Recurring:     &memoryRecurringTemplateRepository{stores: stores},
+		Imports:       &memoryImportProfileRepository{stores: stores},
+		Rules:         &memoryRuleRepository{stores: stores},
+		Backups:       &memoryBackupRepository{stores: stores},
+		Users:         &memoryUserRepository{mutex: &sync.RWMutex{}, users: map[string]*models.UserDTO{}},
+		Members:       &memoryMemberRepository{mutex: &sync.RWMutex{}, members: map[memoryMemberKey]*models.MemberDTO{}},
+		Tokens:        &memoryTokenRepository{mutex: &sync.RWMutex{}, tokens: map[string]*models.TokenDTO{}},
+	}
+
+	// A new store starts with the admin user, and the default categories of its ledger.
+	if err := seedAdminUser(context.Background(), repos); err != nil {
+		panic(err)
+	}
+
+	return repos
+}
+
+// matchesFilter checks if the provided transaction satisfies the provided MongoDB style filter.
+//
+// Only the subset of the MongoDB query language that the handlers use is supported, which is:
+// implicit equality, the $eq, $ne, $gt, $gte, $lt and $lte operators, and the $text operator.
+func matchesFilter(transaction *models.TransactionDTO, filter map[string]interface{}) (bool, error) {
+	for field, condition := range filter {
+		// Handling the full text search.
+		if field == "$text" {
+			search, err := getTextSearchString(condition)
+			if err != nil {
+				return false, err
+			}
+			if !matchesTextSearch(transaction.Notes, search) {
+				return false, nil
+			}
+			continue
+		}
+
+		value, exists := getTransactionField(transaction, field)
+		if !exists {
+			return false, fmt.Errorf("unsupported filter field: %s", field)
+		}
+
+		// If the condition is not an operator map, it is an implicit equality check.
+		operators, isOperatorMap := condition.(map[string]interface{})
+		if !isOperatorMap {
+			operators = map[string]interface{}{"$eq": condition}
+		}
+
+		for operator, operand := range operators {
+			matches, err := matchesOperator(value, operator, operand)
+			if err != nil {
+				return false, err
+			}
+			if !matches {
+				return false, nil
+			}
+		}
+	}
+
+	return true, nil
+}
+
+// matchesOperator checks if the "value" satisfies the comparison operator against the "operand".
+func matchesOperator(value interface{}, operator string, operand interface{}) (bool, error) {
+	comparison, err := compareValues(value, operand)
+	if err != nil {
+		return false, err
+	}
+
+	switch operator {
+	case "$eq":
+		return comparison == 0, nil
+	case "$ne":
+		return comparison != 0, nil
+	case "$gt":
+		return comparison > 0, nil
+	case "$gte":
+		return comparison >= 0, nil
+	case "$lt":
+		return comparison < 0, nil
+	case "$lte":
+		return comparison <= 0, nil
+	default:
+		return false, fmt.Errorf("unsupported filter operator: %s", operator)
+	}
+}
+
+// compareValues compares two numbers or two strings.
+// It returns -1 if a < b, 0 if a == b and +1 if a > b.
+func compareValues(a interface{}, b interface{}) (int, error) {
+	if aStr, ok := a.(string); ok {
+		bStr, ok := b.(string)
+		if !ok {
+			return 0, fmt.Errorf("cannot compare string with %T", b)
+		}
+		return strings.Compare(aStr, bStr), nil
+	}
+
+	// Integers, which include Money amounts, are compared exactly.
+	aInt, aIsInt := toInt64(a)
+	bInt, bIsInt := toInt64(b)
+	if aIsInt && bIsInt {
+		switch {
+		case aInt < bInt:
+			return -1, nil
+		case aInt > bInt:
+			return 1, nil
+		default:
+			return 0, nil
+		}
+	}
+
+	aNum, aOk := toFloat64(a)
+	bNum, bOk := toFloat64(b)
+	if !aOk || !bOk {
+		return 0, fmt.Errorf("cannot compare %T with %T", a, b)
+	}
+
+	switch {
+	case aNum < bNum:
+		return -1, nil
+	case aNum > bNum:
+		return 1, nil
+	default:
+		return 0, nil
+	}
+}
+
+// matchesTextSearch checks if the text matches the search string as per the MongoDB $text operator semantics.
+// Unlike MongoDB, no stemming or stop-word removal is performed.
+func matchesTextSearch(text string, search string) bool {
+	parsed := parseTextSearch(search)
+	text = strings.ToLower(text)
+
+	// Collecting the words of the text for term matching.
+	words := map[string]struct{}{}
+	for _, word := range textSearchWordRegexp.FindAllString(text, -1) {
+		words[word] = struct{}{}
+	}
+
+	// Any negated term disqualifies the text.
+	for _, term := range parsed.NegatedTerms {
+		if _, exists := words[term]; exists {
+			return false
+		}
+	}
+
+	// If there are phrases, all of them must be present and the terms are irrelevant.
+	if len(parsed.Phrases) > 0 {
+		for _, phrase := range parsed.Phrases {
+			if !strings.Contains(text, phrase) {
+				return false
+			}
+		}
+		return true
+	}
+
+	// Otherwise, any one of the terms must be present.
+	for _, term := range parsed.Terms {
+		if _, exists := words[term]; exists {
+			return true
+		}
+	}
+	return false
+}
+
+// getTransactionField provides the value of a transaction field by its database name.
+func getTransactionField(transaction *models.TransactionDTO, field string) (interface{}, bool) {
+	switch field {
+	case "_id":
+		return transaction.ID, true
+	case "amount":
+		return transaction.Amount, true
+	case "timestamp":
+		return transaction.Timestamp, true
+	case "account_id":
+		return transaction.AccountID, true
+	case "category":
+		return transaction.Category, true
+	case "notes":
+		return transaction.Notes, true
+	case "transfer_id":
+		return transaction.TransferID, true
+	case "external_id":
+		return transaction.ExternalID, true
+	case "tags":
+		return transaction.Tags, true
+	case "splits":
+		return transaction.Splits, true
+	default:
+		return nil, false
+	}
+}
+
+// setTransactionField sets the value of a transaction field by its database name.
+func setTransactionField(transaction *models.TransactionDTO, field string, value interface{}) error {
+	var ok bool
+
+	switch field {
+	case "amount":
+		var amount int64
+		amount, ok = toInt64(value)
+		transaction.Amount = models.Money(amount)
+	case "timestamp":
+		transaction.Timestamp, ok = toInt64(value)
+	case "account_id":
+		transaction.AccountID, ok = value.(string)
+	case "category":
+		transaction.Category, ok = value.(string)
+	case "notes":
+		transaction.Notes, ok = value.(string)
+	case "transfer_id":
+		transaction.TransferID, ok = value.(string)
+	case "external_id":
+		transaction.ExternalID, ok = value.(string)
+	case "tags":
+		var tags models.Tags
+		tags, ok = value.(models.Tags)
+		transaction.Tags = append(models.Tags(nil), tags...)
+	case "splits":
+		var splits []*models.SplitDTO
+		splits, ok = value.([]*models.SplitDTO)
+		transaction.Splits = copySplits(splits)
+	default:
+		return fmt.Errorf("unsupported update field: %s", field)
+	}
+
+	if !ok {
+		return fmt.Errorf("invalid value type %T for field: %s", value, field)
+	}
+	return nil
+}
+
+// projectTransaction provides a copy of the transaction that only contains the required fields.
+// The ID is always included, like in MongoDB projections. If no fields are specified, all fields are included.
+func projectTransaction(transaction *models.TransactionDTO, requiredFields []string) *models.TransactionDTO {
+	if len(requiredFields) == 0 {
+		txCopy := copyTransaction(transaction)
+		return &txCopy
+	}
+
+	projected := &models.TransactionDTO{ID: transaction.ID}
+	for _, field := range requiredFields {
+		if value, exists := getTransactionField(transaction, field); exists {
+			_ = setTransactionField(projected, field, value)
+		}
+	}
+
+	return projected
+}
+
+// copyTransaction provides a copy of the transaction that shares no data with it.
+func copyTransaction(transaction *models.TransactionDTO) models.TransactionDTO {
+	txCopy := *transaction
+	txCopy.Splits = copySplits(transaction.Splits)
+	txCopy.Tags = append(models.Tags(nil), transaction.Tags...)
+	return txCopy
+}
+
+// copySplits provides a copy of the split lines that shares no data with them. It returns nil if there are no lines.
+func copySplits(splits []*models.SplitDTO) []*models.SplitDTO {
+	if len(splits) == 0 {
+		return nil
+	}
+
+	splitsCopy := make([]*models.SplitDTO, len(splits))
+	for idx, split := range splits {
+		splitCopy := *split
+		splitsCopy[idx] = &splitCopy
+	}
+	return splitsCopy
+}
+
+// sortTransactions sorts the transactions by the provided field, and then by their IDs, in the provided order.
+func sortTransactions(transactions []*models.TransactionDTO, sortField string, sortOrder int) error {
+	var sortErr error
+
+	sort.SliceStable(transactions, func(i, j int) bool {
+		comparison := 0
+		// Sorting by the sort field first, if provided.
+		if sortField != "" {
+			iValue, iExists := getTransactionField(transactions[i], sortField)
+			jValue, jExists := getTransactionField(transactions[j], sortField)
+			if !iExists || !jExists {
+				sortErr = fmt.Errorf("unsupported sort field: %s", sortField)
+				return false
+			}
+			comparison, _ = compareValues(iValue, jValue)
+		}
+
+		// The ID is always the second sort field. ObjectID hex strings sort in the order of their creation.
+		if comparison == 0 {
+			comparison = strings.Compare(transactions[i].ID, transactions[j].ID)
+		}
+
+		if sortOrder < 0 {
+			return comparison > 0
+		}
+		return comparison < 0
+	})
+
+	return sortErr
+}
+
+// toFloat64 converts any numeric value to float64. The boolean is false if the value is not numeric.
+func toFloat64(value interface{}) (float64, bool) {
+	switch asserted := value.(type) {
+	case float64:
+		return asserted, true
+	case float32:
+		return float64(asserted), true
+	case int:
+		return float64(asserted), true
+	case int32:
+		return float64(asserted), true
+	case int64:
+		return float64(asserted), true
+	case models.Money:
+		return float64(asserted), true
+	default:
+		return 0, false
+	}
+}
+
+// toInt64 converts any integer value, including Money, to int64. The boolean is false if the value is not an integer.
+func toInt64(value interface{}) (int64, bool) {
+	switch asserted := value.(type) {
+	case int:
+		return int64(asserted), true
+	case int32:
+		return int64(asserted), true
+	case int64:
+		return asserted, true
+	case models.Money:
+		return int64(asserted), true
+	default:
+		return 0, false
+	}
+}
@@ -0,0 +1,89 @@
+package database
+
+import (
+	"context"
+	"sort"
+
+	"github.com/shivanshkc/ledgerkeep/src/models"
+	"github.com/shivanshkc/ledgerkeep/src/utils/errutils"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// memoryRuleRepository implements RuleRepository using the in-memory store.
+type memoryRuleRepository struct {
+	stores *memoryStores
+}
+
+func (m *memoryRuleRepository) InsertRule(ctx context.Context, rule *models.RuleDTO) (string, error) {
+	store, err := m.stores.get(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	// Rule IDs are ObjectIDs, just like the ones generated by MongoDB.
+	ruleCopy := copyRule(rule)
+	ruleCopy.ID = primitive.NewObjectID().Hex()
+
+	store.rules[ruleCopy.ID] = ruleCopy
+	return ruleCopy.ID, nil
+}
+
+func (m *memoryRuleRepository) ListRules(ctx context.Context) ([]*models.RuleDTO, error) {
+	store, err := m.stores.get(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	store.mutex.RLock()
+	defer store.mutex.RUnlock()
+
+	results := make([]*models.RuleDTO, 0, len(store.rules))
+	for _, rule := range store.rules {
+		results = append(results, copyRule(rule))
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Priority != results[j].Priority {
+			return results[i].Priority < results[j].Priority
+		}
+		return results[i].ID < results[j].ID
+	})
+	return results, nil
+}
+
+func (m *memoryRuleRepository) DeleteRule(ctx context.Context, ruleID string) error {
+	store, err := m.stores.get(ctx)
+	if err != nil {
+		return err
+	}
+
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	if _, exists := store.rules[ruleID]; !exists {
+		return errutils.RuleNotFound()
+	}
+
+	delete(store.rules, ruleID)
+	return nil
+}
+
+// copyRule provides a copy of the rule that shares no data with it.
+func copyRule(rule *models.RuleDTO) *models.RuleDTO {
+	ruleCopy := *rule
+	if rule.MinAmount != nil {
+		minAmount := *rule.MinAmount
+		ruleCopy.MinAmount = &minAmount
+	}
+	if rule.MaxAmount != nil {
+		maxAmount := *rule.MaxAmount
+		ruleCopy.MaxAmount = &maxAmount
+	}
+	ruleCopy.Weekdays = append([]string(nil), rule.Weekdays...)
+	ruleCopy.AddTags = append(models.Tags(nil), rule.AddTags...)
+	return &ruleCopy
+}
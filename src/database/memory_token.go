@@ -0,0 +1,88 @@
+package database
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/shivanshkc/ledgerkeep/src/models"
+	"github.com/shivanshkc/ledgerkeep/src/utils/errutils"
+)
+
+// memoryTokenRepository implements TokenRepository in memory.
+// The tokens are not kept in the memoryStores, because they do not belong to any tenant.
+type memoryTokenRepository struct {
+	// mutex guards the tokens map.
+	mutex *sync.RWMutex
+	// tokens is a map of token IDs to tokens.
+	tokens map[string]*models.TokenDTO
+}
+
+func (m *memoryTokenRepository) InsertToken(ctx context.Context, token *models.TokenDTO) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.tokens[token.ID] = copyToken(token)
+	return nil
+}
+
+func (m *memoryTokenRepository) GetTokenByHash(ctx context.Context, tokenHash string) (*models.TokenDTO, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	for _, token := range m.tokens {
+		if token.TokenHash == tokenHash {
+			return copyToken(token), nil
+		}
+	}
+	return nil, errutils.TokenNotFound()
+}
+
+func (m *memoryTokenRepository) ListUserTokens(ctx context.Context, userID string) ([]*models.TokenDTO, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	results := []*models.TokenDTO{}
+	for _, token := range m.tokens {
+		if token.UserID == userID {
+			results = append(results, copyToken(token))
+		}
+	}
+
+	sort.Slice(results, func(i, j int) bool { return results[i].ID < results[j].ID })
+	return results, nil
+}
+
+func (m *memoryTokenRepository) UpdateTokenLastUsed(ctx context.Context, tokenID string, lastUsedAt int64) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	token, exists := m.tokens[tokenID]
+	if !exists {
+		return errutils.TokenNotFound()
+	}
+
+	token.LastUsedAt = lastUsedAt
+	return nil
+}
+
+func (m *memoryTokenRepository) DeleteToken(ctx context.Context, userID string, tokenID string) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	// The tokens of the other users are no different from the ones that do not exist.
+	token, exists := m.tokens[tokenID]
+	if !exists || token.UserID != userID {
+		return errutils.TokenNotFound()
+	}
+
+	delete(m.tokens, tokenID)
+	return nil
+}
+
+// copyToken provides a copy of the token that does not share its scopes.
+func copyToken(token *models.TokenDTO) *models.TokenDTO {
+	tokenCopy := *token
+	tokenCopy.Scopes = append([]string{}, token.Scopes...)
+	return &tokenCopy
+}
@@ -0,0 +1,325 @@
+package database
+
+import (
+	"context"
+
+	"github.com/shivanshkc/ledgerkeep/src/models"
+	"github.com/shivanshkc/ledgerkeep/src/utils/errutils"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// memoryTransactionRepository implements TransactionRepository using the in-memory store.
+type memoryTransactionRepository struct {
+	stores *memoryStores
+}
+
+func (m *memoryTransactionRepository) InsertTransaction(ctx context.Context, transaction *models.TransactionDTO) (string, error) {
+	store, err := m.stores.get(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	// Transaction IDs are ObjectIDs, just like the ones generated by MongoDB.
+	txCopy := copyTransaction(transaction)
+	txCopy.ID = primitive.NewObjectID().Hex()
+	txCopy.ClosingBal = 0
+
+	store.transactions[txCopy.ID] = &txCopy
+	return txCopy.ID, nil
+}
+
+func (m *memoryTransactionRepository) GetTransaction(ctx context.Context, transactionID string) (*models.TransactionDTO, error) {
+	store, err := m.stores.get(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	store.mutex.RLock()
+	defer store.mutex.RUnlock()
+
+	transaction, exists := store.transactions[transactionID]
+	if !exists {
+		return nil, errutils.TransactionNotFound()
+	}
+
+	txCopy := copyTransaction(transaction)
+	return &txCopy, nil
+}
+
+func (m *memoryTransactionRepository) ListTransactions(ctx context.Context, params *ListTransactionsParams) ([]*models.TransactionDTO, int, error) {
+	store, err := m.stores.get(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	store.mutex.RLock()
+	defer store.mutex.RUnlock()
+
+	// Filtering the transactions.
+	var matched []*models.TransactionDTO
+	for _, tx := range store.transactions {
+		matches, err := matchesFilter(tx, params.Filter)
+		if err != nil {
+			return nil, 0, err
+		}
+		if matches {
+			matched = append(matched, tx)
+		}
+	}
+
+	if err := sortTransactions(matched, params.SortField, params.SortOrder); err != nil {
+		return nil, 0, err
+	}
+
+	// The count is calculated before pagination, just like MongoDB's CountDocuments.
+	var count int
+	if !params.ExcludeCount {
+		count = len(matched)
+	}
+
+	// Applying skip and limit. A zero limit means no limit, just like in MongoDB.
+	if params.PaginationSkip >= len(matched) {
+		matched = nil
+	} else if params.PaginationSkip > 0 {
+		matched = matched[params.PaginationSkip:]
+	}
+	if params.PaginationLimit > 0 && params.PaginationLimit < len(matched) {
+		matched = matched[:params.PaginationLimit]
+	}
+
+	// Copying the results so the callers cannot modify the store.
+	results := make([]*models.TransactionDTO, len(matched))
+	for idx, tx := range matched {
+		results[idx] = projectTransaction(tx, params.RequiredFields)
+	}
+
+	return results, count, nil
+}
+
+func (m *memoryTransactionRepository) StreamTransactions(ctx context.Context, params *ListTransactionsParams) (TransactionCursor, error) {
+	// The store is in memory anyway, so the cursor iterates over a list of all the matching transactions.
+	transactions, _, err := m.ListTransactions(ctx, &ListTransactionsParams{
+		Filter:         params.Filter,
+		RequiredFields: params.RequiredFields,
+		SortField:      params.SortField,
+		SortOrder:      params.SortOrder,
+		ExcludeCount:   true,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, transaction := range transactions {
+		transaction.Splits = nil
+	}
+	return &memoryTransactionCursor{transactions: transactions, position: -1}, nil
+}
+
+func (m *memoryTransactionRepository) GetCategoryTotals(ctx context.Context, filter map[string]interface{}) ([]*CategoryTotals, error) {
+	store, err := m.stores.get(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	store.mutex.RLock()
+	defer store.mutex.RUnlock()
+
+	type groupKey struct{ accountID, category string }
+
+	totalsMap := map[groupKey]*CategoryTotals{}
+	for _, tx := range store.transactions {
+		matches, err := matchesFilter(tx, filter)
+		if err != nil {
+			return nil, err
+		}
+		if !matches {
+			continue
+		}
+
+		// Every split line counts in its own category.
+		for _, line := range getTransactionLines(tx) {
+			key := groupKey{accountID: tx.AccountID, category: line.Category}
+			totals, exists := totalsMap[key]
+			if !exists {
+				totals = &CategoryTotals{AccountID: tx.AccountID, Category: line.Category}
+				totalsMap[key] = totals
+			}
+
+			if line.Amount > 0 {
+				totals.Credit += line.Amount
+			} else {
+				totals.Debit += line.Amount
+			}
+		}
+	}
+
+	results := make([]*CategoryTotals, 0, len(totalsMap))
+	for _, totals := range totalsMap {
+		results = append(results, totals)
+	}
+
+	return results, nil
+}
+
+func (m *memoryTransactionRepository) UpdateTransaction(ctx context.Context, transactionID string, updates map[string]interface{}) error {
+	store, err := m.stores.get(ctx)
+	if err != nil {
+		return err
+	}
+
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	transaction, exists := store.transactions[transactionID]
+	if !exists {
+		return errutils.TransactionNotFound()
+	}
+
+	// Applying the updates on a copy, so a failure does not leave a partial update behind.
+	txCopy := copyTransaction(transaction)
+	for field, value := range updates {
+		if err := setTransactionField(&txCopy, field, value); err != nil {
+			return err
+		}
+	}
+
+	store.transactions[transactionID] = &txCopy
+	return nil
+}
+
+func (m *memoryTransactionRepository) DeleteTransaction(ctx context.Context, transactionID string) error {
+	store, err := m.stores.get(ctx)
+	if err != nil {
+		return err
+	}
+
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	if _, exists := store.transactions[transactionID]; !exists {
+		return errutils.TransactionNotFound()
+	}
+
+	delete(store.transactions, transactionID)
+	return nil
+}
+
+func (m *memoryTransactionRepository) InsertTransactions(ctx context.Context,
+	transactions []*models.TransactionDTO) ([]string, error) {
+	store, err := m.stores.get(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	ids := make([]string, len(transactions))
+	for idx, transaction := range transactions {
+		// Transaction IDs are ObjectIDs, just like the ones generated by MongoDB.
+		txCopy := copyTransaction(transaction)
+		txCopy.ID = primitive.NewObjectID().Hex()
+		txCopy.ClosingBal = 0
+
+		store.transactions[txCopy.ID] = &txCopy
+		ids[idx] = txCopy.ID
+	}
+
+	return ids, nil
+}
+
+func (m *memoryTransactionRepository) InsertTransfer(ctx context.Context, legs []*models.TransactionDTO) ([]string, error) {
+	// The legs are inserted like any other transactions.
+	return m.InsertTransactions(ctx, legs)
+}
+
+func (m *memoryTransactionRepository) UpdateTransactions(ctx context.Context, updates map[string]map[string]interface{}) error {
+	store, err := m.stores.get(ctx)
+	if err != nil {
+		return err
+	}
+
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	// Applying all the updates on copies first, so a failure does not leave a partial update behind.
+	updated := make(map[string]*models.TransactionDTO, len(updates))
+	for transactionID, txUpdates := range updates {
+		transaction, exists := store.transactions[transactionID]
+		if !exists {
+			return errutils.TransactionNotFound()
+		}
+
+		txCopy := copyTransaction(transaction)
+		for field, value := range txUpdates {
+			if err := setTransactionField(&txCopy, field, value); err != nil {
+				return err
+			}
+		}
+		updated[transactionID] = &txCopy
+	}
+
+	for transactionID, transaction := range updated {
+		store.transactions[transactionID] = transaction
+	}
+	return nil
+}
+
+func (m *memoryTransactionRepository) DeleteTransfer(ctx context.Context, transferID string) error {
+	store, err := m.stores.get(ctx)
+	if err != nil {
+		return err
+	}
+
+	// Transactions that are not legs of a transfer have an empty transfer ID.
+	if transferID == "" {
+		return errutils.TransactionNotFound()
+	}
+
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	var deleted bool
+	for transactionID, transaction := range store.transactions {
+		if transaction.TransferID == transferID {
+			delete(store.transactions, transactionID)
+			deleted = true
+		}
+	}
+
+	if !deleted {
+		return errutils.TransactionNotFound()
+	}
+	return nil
+}
+
+// memoryTransactionCursor implements TransactionCursor over a list of transactions.
+type memoryTransactionCursor struct {
+	transactions []*models.TransactionDTO
+	position     int
+}
+
+func (m *memoryTransactionCursor) Next(ctx context.Context) bool {
+	if m.position+1 >= len(m.transactions) {
+		return false
+	}
+	m.position++
+	return true
+}
+
+func (m *memoryTransactionCursor) Transaction() *models.TransactionDTO {
+	return m.transactions[m.position]
+}
+
+func (m *memoryTransactionCursor) Err() error {
+	return nil
+}
+
+func (m *memoryTransactionCursor) Close(ctx context.Context) error {
+	m.transactions = nil
+	return nil
+}
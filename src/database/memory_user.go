@@ -0,0 +1,91 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/shivanshkc/ledgerkeep/src/models"
+	"github.com/shivanshkc/ledgerkeep/src/utils/errutils"
+)
+
+// memoryUserRepository implements UserRepository in memory.
+// The users are not kept in the memoryStores, because they do not belong to any tenant.
+type memoryUserRepository struct {
+	// mutex guards the users map.
+	mutex *sync.RWMutex
+	// users is a map of user IDs to users.
+	users map[string]*models.UserDTO
+}
+
+func (m *memoryUserRepository) InsertUser(ctx context.Context, user *models.UserDTO) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	// User ID is the primary key, so it cannot be duplicated.
+	if _, exists := m.users[user.ID]; exists {
+		return errutils.UserAlreadyExists()
+	}
+
+	userCopy := *user
+	m.users[userCopy.ID] = &userCopy
+	return nil
+}
+
+func (m *memoryUserRepository) GetUser(ctx context.Context, userID string) (*models.UserDTO, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	user, exists := m.users[userID]
+	if !exists {
+		return nil, errutils.UserNotFound()
+	}
+
+	userCopy := *user
+	return &userCopy, nil
+}
+
+func (m *memoryUserRepository) ListUsers(ctx context.Context) ([]*models.UserDTO, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	results := make([]*models.UserDTO, 0, len(m.users))
+	for _, user := range m.users {
+		userCopy := *user
+		results = append(results, &userCopy)
+	}
+
+	sort.Slice(results, func(i, j int) bool { return results[i].ID < results[j].ID })
+	return results, nil
+}
+
+func (m *memoryUserRepository) UpdateUser(ctx context.Context, userID string, updates map[string]interface{}) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	user, exists := m.users[userID]
+	if !exists {
+		return errutils.UserNotFound()
+	}
+
+	// Validating and applying the updates on a copy, so a failure does not leave a partial update behind.
+	userCopy := *user
+	for field, value := range updates {
+		var ok bool
+		switch field {
+		case "password_hash":
+			userCopy.PasswordHash, ok = value.(string)
+		case "is_admin":
+			userCopy.IsAdmin, ok = value.(bool)
+		default:
+			return fmt.Errorf("unsupported update field: %s", field)
+		}
+		if !ok {
+			return fmt.Errorf("invalid value type %T for field: %s", value, field)
+		}
+	}
+
+	m.users[userID] = &userCopy
+	return nil
+}
@@ -13,15 +13,22 @@ import (
 	"go.mongodb.org/mongo-driver/mongo"
 )
 
-// InsertAccount creates a new account in the database.
-func InsertAccount(ctx context.Context, account *models.AccountDTO) error {
+// mongoAccountRepository implements AccountRepository using MongoDB.
+type mongoAccountRepository struct{}
+
+func (m *mongoAccountRepository) InsertAccount(ctx context.Context, account *models.AccountDTO) error {
 	log := logger.Get()
 
+	collection, err := getAccountsCollection(ctx)
+	if err != nil {
+		return err
+	}
+
 	// Creating timeout context for the database call.
 	callCtx, cancelFunc := getTimeoutContext(ctx)
 	defer cancelFunc()
 
-	if _, err := getAccountsCollection().InsertOne(callCtx, account); err != nil {
+	if _, err := collection.InsertOne(callCtx, account); err != nil {
 		// Checking if the error is a duplicate key error (already exists error).
 		if mongo.IsDuplicateKeyError(err) {
 			return errutils.AccountAlreadyExists()
@@ -34,15 +41,19 @@ func InsertAccount(ctx context.Context, account *models.AccountDTO) error {
 	return nil
 }
 
-// IsAccountExists returns true if the account with the provided ID exists.
-func IsAccountExists(ctx context.Context, accountID string) (bool, error) {
+func (m *mongoAccountRepository) IsAccountExists(ctx context.Context, accountID string) (bool, error) {
 	log := logger.Get()
 
+	collection, err := getAccountsCollection(ctx)
+	if err != nil {
+		return false, err
+	}
+
 	// Creating timeout context for the database call.
 	callCtx, cancelFunc := getTimeoutContext(ctx)
 	defer cancelFunc()
 
-	if err := getAccountsCollection().FindOne(callCtx, bson.M{"_id": accountID}).Err(); err != nil {
+	if err := collection.FindOne(callCtx, bson.M{"_id": accountID}).Err(); err != nil {
 		// Handling the no account found scenario.
 		if errors.Is(err, mongo.ErrNoDocuments) {
 			return false, nil
@@ -55,15 +66,53 @@ func IsAccountExists(ctx context.Context, accountID string) (bool, error) {
 	return true, nil
 }
 
-// IsAccountUsed returns true if even a single transaction is using the provided account, otherwise it returns false.
-func IsAccountUsed(ctx context.Context, accountID string) (bool, error) {
+func (m *mongoAccountRepository) GetAccount(ctx context.Context, accountID string) (*models.AccountDTO, error) {
 	log := logger.Get()
 
+	collection, err := getAccountsCollection(ctx)
+	if err != nil {
+		return nil, err
+	}
+
 	// Creating timeout context for the database call.
 	callCtx, cancelFunc := getTimeoutContext(ctx)
 	defer cancelFunc()
 
-	count, err := getTransactionsCollection().CountDocuments(callCtx, bson.M{"account_id": accountID})
+	result := collection.FindOne(callCtx, bson.M{"_id": accountID})
+	if err := result.Err(); err != nil {
+		// Handling the not-exists case.
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, errutils.AccountNotFound()
+		}
+
+		err = fmt.Errorf("mongodb FindOne error: %w", err)
+		log.Error(ctx, &logger.Entry{Payload: err})
+		return nil, err
+	}
+
+	var account *models.AccountDTO
+	if err := result.Decode(&account); err != nil {
+		err = fmt.Errorf("mongodb Decode error: %w", err)
+		log.Error(ctx, &logger.Entry{Payload: err})
+		return nil, err
+	}
+
+	return account, nil
+}
+
+func (m *mongoAccountRepository) IsAccountUsed(ctx context.Context, accountID string) (bool, error) {
+	log := logger.Get()
+
+	collection, err := getTransactionsCollection(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	// Creating timeout context for the database call.
+	callCtx, cancelFunc := getTimeoutContext(ctx)
+	defer cancelFunc()
+
+	count, err := collection.CountDocuments(callCtx, bson.M{"account_id": accountID})
 	if err != nil {
 		err = fmt.Errorf("mongodb CountDocuments error: %w", err)
 		log.Error(ctx, &logger.Entry{Payload: err})
@@ -73,15 +122,19 @@ func IsAccountUsed(ctx context.Context, accountID string) (bool, error) {
 	return count != 0, nil
 }
 
-// ListAccounts provides a list of all accounts.
-func ListAccounts(ctx context.Context) ([]*models.AccountDTO, error) {
+func (m *mongoAccountRepository) ListAccounts(ctx context.Context) ([]*models.AccountDTO, error) {
 	log := logger.Get()
 
+	collection, err := getAccountsCollection(ctx)
+	if err != nil {
+		return nil, err
+	}
+
 	// Creating timeout context for the database call.
 	callCtx, cancelFunc := getTimeoutContext(ctx)
 	defer cancelFunc()
 
-	cursor, err := getAccountsCollection().Find(callCtx, bson.M{})
+	cursor, err := collection.Find(callCtx, bson.M{})
 	if err != nil {
 		err = fmt.Errorf("mongodb Find error: %w", err)
 		log.Error(ctx, &logger.Entry{Payload: err})
@@ -98,10 +151,14 @@ func ListAccounts(ctx context.Context) ([]*models.AccountDTO, error) {
 	return results, nil
 }
 
-// GetAccountBalances provides a map of account IDs to their balance.
-func GetAccountBalances(ctx context.Context) (map[string]float64, error) {
+func (m *mongoAccountRepository) GetAccountBalances(ctx context.Context) (map[string]models.Money, error) {
 	log := logger.Get()
 
+	collection, err := getTransactionsCollection(ctx)
+	if err != nil {
+		return nil, err
+	}
+
 	// Creating timeout context for the database call.
 	callCtx, cancelFunc := getTimeoutContext(ctx)
 	defer cancelFunc()
@@ -116,7 +173,7 @@ func GetAccountBalances(ctx context.Context) (map[string]float64, error) {
 	}}
 
 	// Database call.
-	cursor, err := getTransactionsCollection().Aggregate(callCtx, mongo.Pipeline{groupStage})
+	cursor, err := collection.Aggregate(callCtx, mongo.Pipeline{groupStage})
 	if err != nil {
 		err = fmt.Errorf("mongodb Aggregate error: %w", err)
 		log.Error(ctx, &logger.Entry{Payload: err})
@@ -124,8 +181,8 @@ func GetAccountBalances(ctx context.Context) (map[string]float64, error) {
 	}
 
 	var results []struct {
-		ID      string  `json:"id" bson:"_id"`
-		Balance float64 `json:"balance" bson:"balance"`
+		ID      string       `json:"id" bson:"_id"`
+		Balance models.Money `json:"balance" bson:"balance"`
 	}
 
 	if err := cursor.All(ctx, &results); err != nil {
@@ -134,7 +191,7 @@ func GetAccountBalances(ctx context.Context) (map[string]float64, error) {
 		return nil, err
 	}
 
-	balanceMap := map[string]float64{}
+	balanceMap := map[string]models.Money{}
 	for _, value := range results {
 		balanceMap[value.ID] = value.Balance
 	}
@@ -142,10 +199,14 @@ func GetAccountBalances(ctx context.Context) (map[string]float64, error) {
 	return balanceMap, nil
 }
 
-// UpdateAccount updates an account in the database.
-func UpdateAccount(ctx context.Context, accountID string, updates map[string]interface{}) error {
+func (m *mongoAccountRepository) UpdateAccount(ctx context.Context, accountID string, updates map[string]interface{}) error {
 	log := logger.Get()
 
+	collection, err := getAccountsCollection(ctx)
+	if err != nil {
+		return err
+	}
+
 	// Creating timeout context for the database call.
 	callCtx, cancelFunc := getTimeoutContext(ctx)
 	defer cancelFunc()
@@ -153,7 +214,7 @@ func UpdateAccount(ctx context.Context, accountID string, updates map[string]int
 	// Wrapping the updates with $set operator of mongodb.
 	updates = bson.M{"$set": updates}
 
-	result, err := getAccountsCollection().UpdateOne(callCtx, bson.M{"_id": accountID}, updates)
+	result, err := collection.UpdateOne(callCtx, bson.M{"_id": accountID}, updates)
 	if err != nil {
 		err = fmt.Errorf("mongodb UpdateOne error: %w", err)
 		log.Error(ctx, &logger.Entry{Payload: err})
@@ -166,15 +227,19 @@ func UpdateAccount(ctx context.Context, accountID string, updates map[string]int
 	return nil
 }
 
-// DeleteAccount deletes an account in the database.
-func DeleteAccount(ctx context.Context, accountID string) error {
+func (m *mongoAccountRepository) DeleteAccount(ctx context.Context, accountID string) error {
 	log := logger.Get()
 
+	collection, err := getAccountsCollection(ctx)
+	if err != nil {
+		return err
+	}
+
 	// Creating timeout context for the database call.
 	callCtx, cancelFunc := getTimeoutContext(ctx)
 	defer cancelFunc()
 
-	result, err := getAccountsCollection().DeleteOne(callCtx, bson.M{"_id": accountID})
+	result, err := collection.DeleteOne(callCtx, bson.M{"_id": accountID})
 	if err != nil {
 		err = fmt.Errorf("mongodb DeleteOne error: %w", err)
 		log.Error(ctx, &logger.Entry{Payload: err})
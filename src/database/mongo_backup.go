@@ -0,0 +1,185 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/shivanshkc/ledgerkeep/src/logger"
+	"github.com/shivanshkc/ledgerkeep/src/models"
+	"github.com/shivanshkc/ledgerkeep/src/utils/errutils"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// mongoNamespaceExistsCode is the code of the error for creating a collection that exists already.
+const mongoNamespaceExistsCode = 48
+
+// mongoRestoredTransaction is the document of a restored transaction. Transactions are stored with ObjectIDs, unlike
+// the other entities, so the ID field shadows that of the embedded transaction.
+type mongoRestoredTransaction struct {
+	ID                    primitive.ObjectID `bson:"_id"`
+	models.TransactionDTO `bson:",inline"`
+}
+
+// mongoBackupRepository implements BackupRepository using MongoDB.
+type mongoBackupRepository struct{}
+
+// ReplaceLedger deletes the existing data and inserts the new one in a single multi-document transaction.
+//
+// MongoDB supports such transactions only on replica sets and sharded clusters. The restore is refused on the other
+// deployments, as replacing the collections one at a time could leave a ledger that is only partially restored.
+// A ledger that is too large for the transaction limits of the deployment is not replaced at all.
+func (m *mongoBackupRepository) ReplaceLedger(ctx context.Context, data *models.LedgerData) error {
+	database, err := getTenantDatabase(ctx)
+	if err != nil {
+		return err
+	}
+
+	documents := map[string][]interface{}{
+		accountsCollectionName:       {},
+		transactionsCollectionName:   {},
+		categoriesCollectionName:     {},
+		exchangeRatesCollectionName:  {},
+		budgetPlansCollectionName:    {},
+		recurringCollectionName:      {},
+		importProfilesCollectionName: {},
+		rulesCollectionName:          {},
+	}
+
+	for _, account := range data.Accounts {
+		documents[accountsCollectionName] = append(documents[accountsCollectionName], account)
+	}
+	for _, transaction := range data.Transactions {
+		transactionID, err := primitive.ObjectIDFromHex(transaction.ID)
+		if err != nil {
+			return errutils.BadRequest().AddErrors(fmt.Errorf("invalid transaction ID: %s", transaction.ID))
+		}
+		documents[transactionsCollectionName] = append(documents[transactionsCollectionName],
+			&mongoRestoredTransaction{ID: transactionID, TransactionDTO: *transaction})
+	}
+	for _, category := range data.Categories {
+		documents[categoriesCollectionName] = append(documents[categoriesCollectionName], category)
+	}
+	for _, rate := range data.ExchangeRates {
+		documents[exchangeRatesCollectionName] = append(documents[exchangeRatesCollectionName], rate)
+	}
+	for _, plan := range data.BudgetPlans {
+		documents[budgetPlansCollectionName] = append(documents[budgetPlansCollectionName], plan)
+	}
+	for _, template := range data.RecurringTemplates {
+		documents[recurringCollectionName] = append(documents[recurringCollectionName], template)
+	}
+	for _, profile := range data.ImportProfiles {
+		documents[importProfilesCollectionName] = append(documents[importProfilesCollectionName], profile)
+	}
+	for _, rule := range data.Rules {
+		documents[rulesCollectionName] = append(documents[rulesCollectionName], rule)
+	}
+
+	// Nothing is created for a restore that would be refused anyway.
+	supported, err := mongoSupportsTransactions(ctx)
+	if err != nil {
+		return err
+	}
+	if !supported {
+		return errutils.TransactionsNotSupported()
+	}
+
+	// The collections and their indexes are created before the transaction, so the unique indexes are enforced
+	// within it, and so it works on the MongoDB versions that cannot create collections in transactions.
+	if err := m.createCollections(ctx, database, documents); err != nil {
+		return err
+	}
+	if err := createMongoIndexes(ctx, database); err != nil {
+		return err
+	}
+
+	// The existing data is deleted and the new one is inserted atomically.
+	err = runInMongoTransaction(ctx, func(sessCtx mongo.SessionContext) error {
+		for name, collectionDocs := range documents {
+			if err := m.replaceCollection(sessCtx, database.Collection(name), collectionDocs); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+
+	// The data of a backup is validated before it is restored, so it cannot have any duplicates, but it is checked
+	// just like the SQL backends do.
+	if mongo.IsDuplicateKeyError(err) {
+		return errutils.Conflict()
+	}
+	return err
+}
+
+// createCollections creates the collections with the provided names that do not exist yet.
+func (m *mongoBackupRepository) createCollections(ctx context.Context, database *mongo.Database,
+	documents map[string][]interface{}) error {
+	log := logger.Get()
+
+	// Creating timeout context for the database calls.
+	callCtx, cancelFunc := getTimeoutContext(ctx)
+	defer cancelFunc()
+
+	names, err := database.ListCollectionNames(callCtx, bson.D{})
+	if err != nil {
+		err = fmt.Errorf("mongodb ListCollectionNames error: %w", err)
+		log.Error(ctx, &logger.Entry{Payload: err})
+		return err
+	}
+
+	existing := make(map[string]bool, len(names))
+	for _, name := range names {
+		existing[name] = true
+	}
+
+	for name := range documents {
+		if existing[name] {
+			continue
+		}
+		// The indexes, which are created in the background, may have created the collection in the meantime.
+		var commandErr mongo.CommandError
+		err := database.CreateCollection(callCtx, name)
+		if errors.As(err, &commandErr) && commandErr.HasErrorCode(mongoNamespaceExistsCode) {
+			continue
+		}
+		if err != nil {
+			err = fmt.Errorf("mongodb CreateCollection error: %w", err)
+			log.Error(ctx, &logger.Entry{Payload: err})
+			return err
+		}
+	}
+
+	return nil
+}
+
+// replaceCollection replaces all the documents of the collection with the provided ones.
+// The context must carry the session of the transaction.
+func (m *mongoBackupRepository) replaceCollection(ctx context.Context, collection *mongo.Collection,
+	documents []interface{}) error {
+	log := logger.Get()
+
+	// Creating timeout context for the database calls.
+	callCtx, cancelFunc := getTimeoutContext(ctx)
+	defer cancelFunc()
+
+	if _, err := collection.DeleteMany(callCtx, bson.D{}); err != nil {
+		err = fmt.Errorf("mongodb DeleteMany error: %w", err)
+		log.Error(ctx, &logger.Entry{Payload: err})
+		return err
+	}
+
+	// InsertMany does not accept an empty list of documents.
+	if len(documents) == 0 {
+		return nil
+	}
+	if _, err := collection.InsertMany(callCtx, documents); err != nil {
+		err = fmt.Errorf("mongodb InsertMany error: %w", err)
+		log.Error(ctx, &logger.Entry{Payload: err})
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,97 @@
+package database
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/shivanshkc/ledgerkeep/src/logger"
+	"github.com/shivanshkc/ledgerkeep/src/models"
+	"github.com/shivanshkc/ledgerkeep/src/utils/errutils"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// mongoBudgetPlanRepository implements BudgetPlanRepository using MongoDB.
+type mongoBudgetPlanRepository struct{}
+
+func (m *mongoBudgetPlanRepository) InsertBudgetPlan(ctx context.Context, plan *models.BudgetPlanDTO) (string, error) {
+	log := logger.Get()
+
+	collection, err := getBudgetPlansCollection(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	// The plan is embedded as a whole, so it is saved atomically along with its allocations.
+	planCopy := *plan
+	planCopy.ID = primitive.NewObjectID().Hex()
+
+	// Creating timeout context for the database call.
+	callCtx, cancelFunc := getTimeoutContext(ctx)
+	defer cancelFunc()
+
+	if _, err := collection.InsertOne(callCtx, &planCopy); err != nil {
+		err = fmt.Errorf("mongodb InsertOne error: %w", err)
+		log.Error(ctx, &logger.Entry{Payload: err})
+		return "", err
+	}
+
+	return planCopy.ID, nil
+}
+
+func (m *mongoBudgetPlanRepository) ListBudgetPlans(ctx context.Context) ([]*models.BudgetPlanDTO, error) {
+	log := logger.Get()
+
+	collection, err := getBudgetPlansCollection(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Creating timeout context for the database call.
+	callCtx, cancelFunc := getTimeoutContext(ctx)
+	defer cancelFunc()
+
+	opts := options.Find().SetSort(bson.D{{Key: "valid_from", Value: 1}, {Key: "_id", Value: 1}})
+	cursor, err := collection.Find(callCtx, bson.M{}, opts)
+	if err != nil {
+		err = fmt.Errorf("mongodb Find error: %w", err)
+		log.Error(ctx, &logger.Entry{Payload: err})
+		return nil, err
+	}
+
+	results := []*models.BudgetPlanDTO{}
+	if err := cursor.All(ctx, &results); err != nil {
+		err = fmt.Errorf("mongodb cursor.All error: %w", err)
+		log.Error(ctx, &logger.Entry{Payload: err})
+		return nil, err
+	}
+
+	return results, nil
+}
+
+func (m *mongoBudgetPlanRepository) DeleteBudgetPlan(ctx context.Context, planID string) error {
+	log := logger.Get()
+
+	collection, err := getBudgetPlansCollection(ctx)
+	if err != nil {
+		return err
+	}
+
+	// Creating timeout context for the database call.
+	callCtx, cancelFunc := getTimeoutContext(ctx)
+	defer cancelFunc()
+
+	result, err := collection.DeleteOne(callCtx, bson.M{"_id": planID})
+	if err != nil {
+		err = fmt.Errorf("mongodb DeleteOne error: %w", err)
+		log.Error(ctx, &logger.Entry{Payload: err})
+		return err
+	}
+
+	if result.DeletedCount == 0 {
+		return errutils.BudgetPlanNotFound()
+	}
+	return nil
+}
@@ -0,0 +1,183 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/shivanshkc/ledgerkeep/src/logger"
+	"github.com/shivanshkc/ledgerkeep/src/models"
+	"github.com/shivanshkc/ledgerkeep/src/utils/errutils"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// mongoCategoryRepository implements CategoryRepository using MongoDB.
+type mongoCategoryRepository struct{}
+
+func (m *mongoCategoryRepository) InsertCategory(ctx context.Context, category *models.CategoryDTO) error {
+	log := logger.Get()
+
+	collection, err := getCategoriesCollection(ctx)
+	if err != nil {
+		return err
+	}
+
+	// Creating timeout context for the database call.
+	callCtx, cancelFunc := getTimeoutContext(ctx)
+	defer cancelFunc()
+
+	if _, err := collection.InsertOne(callCtx, category); err != nil {
+		// Checking if the error is a duplicate key error (already exists error).
+		if mongo.IsDuplicateKeyError(err) {
+			return errutils.CategoryAlreadyExists()
+		}
+		err = fmt.Errorf("mongodb InsertOne error: %w", err)
+		log.Error(ctx, &logger.Entry{Payload: err})
+		return err
+	}
+
+	return nil
+}
+
+func (m *mongoCategoryRepository) GetCategory(ctx context.Context, categoryID string) (*models.CategoryDTO, error) {
+	log := logger.Get()
+
+	collection, err := getCategoriesCollection(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Creating timeout context for the database call.
+	callCtx, cancelFunc := getTimeoutContext(ctx)
+	defer cancelFunc()
+
+	result := collection.FindOne(callCtx, bson.M{"_id": categoryID})
+	if err := result.Err(); err != nil {
+		// Handling the not-exists case.
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, errutils.CategoryNotFound()
+		}
+
+		err = fmt.Errorf("mongodb FindOne error: %w", err)
+		log.Error(ctx, &logger.Entry{Payload: err})
+		return nil, err
+	}
+
+	var category *models.CategoryDTO
+	if err := result.Decode(&category); err != nil {
+		err = fmt.Errorf("mongodb Decode error: %w", err)
+		log.Error(ctx, &logger.Entry{Payload: err})
+		return nil, err
+	}
+
+	return category, nil
+}
+
+func (m *mongoCategoryRepository) ListCategories(ctx context.Context) ([]*models.CategoryDTO, error) {
+	log := logger.Get()
+
+	collection, err := getCategoriesCollection(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Creating timeout context for the database call.
+	callCtx, cancelFunc := getTimeoutContext(ctx)
+	defer cancelFunc()
+
+	opts := options.Find().SetSort(bson.D{{Key: "_id", Value: 1}})
+	cursor, err := collection.Find(callCtx, bson.M{}, opts)
+	if err != nil {
+		err = fmt.Errorf("mongodb Find error: %w", err)
+		log.Error(ctx, &logger.Entry{Payload: err})
+		return nil, err
+	}
+
+	results := []*models.CategoryDTO{}
+	if err := cursor.All(ctx, &results); err != nil {
+		err = fmt.Errorf("mongodb cursor.All error: %w", err)
+		log.Error(ctx, &logger.Entry{Payload: err})
+		return nil, err
+	}
+
+	return results, nil
+}
+
+func (m *mongoCategoryRepository) IsCategoryUsed(ctx context.Context, categoryID string) (bool, error) {
+	log := logger.Get()
+
+	collection, err := getTransactionsCollection(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	// Creating timeout context for the database call.
+	callCtx, cancelFunc := getTimeoutContext(ctx)
+	defer cancelFunc()
+
+	filter := bson.M{"$or": bson.A{bson.M{"category": categoryID}, bson.M{"splits.category": categoryID}}}
+	count, err := collection.CountDocuments(callCtx, filter, options.Count().SetLimit(1))
+	if err != nil {
+		err = fmt.Errorf("mongodb CountDocuments error: %w", err)
+		log.Error(ctx, &logger.Entry{Payload: err})
+		return false, err
+	}
+
+	return count != 0, nil
+}
+
+func (m *mongoCategoryRepository) UpdateCategory(ctx context.Context, categoryID string, updates map[string]interface{}) error {
+	log := logger.Get()
+
+	collection, err := getCategoriesCollection(ctx)
+	if err != nil {
+		return err
+	}
+
+	// Creating timeout context for the database call.
+	callCtx, cancelFunc := getTimeoutContext(ctx)
+	defer cancelFunc()
+
+	// Wrapping the updates with $set operator of mongodb.
+	updates = bson.M{"$set": updates}
+
+	result, err := collection.UpdateOne(callCtx, bson.M{"_id": categoryID}, updates)
+	if err != nil {
+		err = fmt.Errorf("mongodb UpdateOne error: %w", err)
+		log.Error(ctx, &logger.Entry{Payload: err})
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return errutils.CategoryNotFound()
+	}
+	return nil
+}
+
+func (m *mongoCategoryRepository) DeleteCategory(ctx context.Context, categoryID string) error {
+	log := logger.Get()
+
+	collection, err := getCategoriesCollection(ctx)
+	if err != nil {
+		return err
+	}
+
+	// Creating timeout context for the database call.
+	callCtx, cancelFunc := getTimeoutContext(ctx)
+	defer cancelFunc()
+
+	result, err := collection.DeleteOne(callCtx, bson.M{"_id": categoryID})
+	if err != nil {
+		err = fmt.Errorf("mongodb DeleteOne error: %w", err)
+		log.Error(ctx, &logger.Entry{Payload: err})
+		return err
+	}
+
+	if result.DeletedCount == 0 {
+		return errutils.CategoryNotFound()
+	}
+	return nil
+}
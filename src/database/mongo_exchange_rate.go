@@ -0,0 +1,119 @@
+package database
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/shivanshkc/ledgerkeep/src/logger"
+	"github.com/shivanshkc/ledgerkeep/src/models"
+	"github.com/shivanshkc/ledgerkeep/src/utils/errutils"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// mongoExchangeRateRepository implements ExchangeRateRepository using MongoDB.
+type mongoExchangeRateRepository struct{}
+
+func (m *mongoExchangeRateRepository) PutExchangeRates(ctx context.Context, rates []*models.ExchangeRateDTO) error {
+	log := logger.Get()
+
+	collection, err := getExchangeRatesCollection(ctx)
+	if err != nil {
+		return err
+	}
+
+	// Nothing to write. BulkWrite does not accept an empty list of models.
+	if len(rates) == 0 {
+		return nil
+	}
+
+	// The currency pair and timestamp are unique, so an existing rate is replaced, but keeps its ID.
+	writeModels := make([]mongo.WriteModel, len(rates))
+	for idx, rate := range rates {
+		writeModels[idx] = mongo.NewUpdateOneModel().
+			SetFilter(bson.M{"base": rate.Base, "quote": rate.Quote, "timestamp": rate.Timestamp}).
+			SetUpdate(bson.M{
+				"$set":         bson.M{"rate": rate.Rate},
+				"$setOnInsert": bson.M{"_id": primitive.NewObjectID().Hex()},
+			}).
+			SetUpsert(true)
+	}
+
+	// Creating timeout context for the database call.
+	callCtx, cancelFunc := getTimeoutContext(ctx)
+	defer cancelFunc()
+
+	if _, err := collection.BulkWrite(callCtx, writeModels); err != nil {
+		err = fmt.Errorf("mongodb BulkWrite error: %w", err)
+		log.Error(ctx, &logger.Entry{Payload: err})
+		return err
+	}
+
+	return nil
+}
+
+func (m *mongoExchangeRateRepository) ListExchangeRates(ctx context.Context, base string, quote string) ([]*models.ExchangeRateDTO, error) {
+	log := logger.Get()
+
+	collection, err := getExchangeRatesCollection(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	filter := bson.M{}
+	if base != "" {
+		filter["base"] = base
+	}
+	if quote != "" {
+		filter["quote"] = quote
+	}
+
+	// Creating timeout context for the database call.
+	callCtx, cancelFunc := getTimeoutContext(ctx)
+	defer cancelFunc()
+
+	opts := options.Find().SetSort(bson.D{{Key: "timestamp", Value: 1}, {Key: "_id", Value: 1}})
+	cursor, err := collection.Find(callCtx, filter, opts)
+	if err != nil {
+		err = fmt.Errorf("mongodb Find error: %w", err)
+		log.Error(ctx, &logger.Entry{Payload: err})
+		return nil, err
+	}
+
+	results := []*models.ExchangeRateDTO{}
+	if err := cursor.All(ctx, &results); err != nil {
+		err = fmt.Errorf("mongodb cursor.All error: %w", err)
+		log.Error(ctx, &logger.Entry{Payload: err})
+		return nil, err
+	}
+
+	return results, nil
+}
+
+func (m *mongoExchangeRateRepository) DeleteExchangeRate(ctx context.Context, rateID string) error {
+	log := logger.Get()
+
+	collection, err := getExchangeRatesCollection(ctx)
+	if err != nil {
+		return err
+	}
+
+	// Creating timeout context for the database call.
+	callCtx, cancelFunc := getTimeoutContext(ctx)
+	defer cancelFunc()
+
+	result, err := collection.DeleteOne(callCtx, bson.M{"_id": rateID})
+	if err != nil {
+		err = fmt.Errorf("mongodb DeleteOne error: %w", err)
+		log.Error(ctx, &logger.Entry{Payload: err})
+		return err
+	}
+
+	if result.DeletedCount == 0 {
+		return errutils.ExchangeRateNotFound()
+	}
+	return nil
+}
@@ -0,0 +1,133 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/shivanshkc/ledgerkeep/src/logger"
+	"github.com/shivanshkc/ledgerkeep/src/models"
+	"github.com/shivanshkc/ledgerkeep/src/utils/errutils"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// mongoImportProfileRepository implements ImportProfileRepository using MongoDB.
+type mongoImportProfileRepository struct{}
+
+func (m *mongoImportProfileRepository) InsertImportProfile(ctx context.Context, profile *models.ImportProfileDTO) (string, error) {
+	log := logger.Get()
+
+	collection, err := getImportProfilesCollection(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	// Import profile IDs are ObjectID hex strings, just like the IDs of the other collections.
+	profileCopy := *profile
+	profileCopy.ID = primitive.NewObjectID().Hex()
+
+	// Creating timeout context for the database call.
+	callCtx, cancelFunc := getTimeoutContext(ctx)
+	defer cancelFunc()
+
+	if _, err := collection.InsertOne(callCtx, &profileCopy); err != nil {
+		err = fmt.Errorf("mongodb InsertOne error: %w", err)
+		log.Error(ctx, &logger.Entry{Payload: err})
+		return "", err
+	}
+
+	return profileCopy.ID, nil
+}
+
+func (m *mongoImportProfileRepository) GetImportProfile(ctx context.Context, profileID string) (*models.ImportProfileDTO, error) {
+	log := logger.Get()
+
+	collection, err := getImportProfilesCollection(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Creating timeout context for the database call.
+	callCtx, cancelFunc := getTimeoutContext(ctx)
+	defer cancelFunc()
+
+	result := collection.FindOne(callCtx, bson.M{"_id": profileID})
+	if err := result.Err(); err != nil {
+		// Handling the not-exists case.
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, errutils.ImportProfileNotFound()
+		}
+
+		err = fmt.Errorf("mongodb FindOne error: %w", err)
+		log.Error(ctx, &logger.Entry{Payload: err})
+		return nil, err
+	}
+
+	var profile *models.ImportProfileDTO
+	if err := result.Decode(&profile); err != nil {
+		err = fmt.Errorf("mongodb Decode error: %w", err)
+		log.Error(ctx, &logger.Entry{Payload: err})
+		return nil, err
+	}
+
+	return profile, nil
+}
+
+func (m *mongoImportProfileRepository) ListImportProfiles(ctx context.Context) ([]*models.ImportProfileDTO, error) {
+	log := logger.Get()
+
+	collection, err := getImportProfilesCollection(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Creating timeout context for the database call.
+	callCtx, cancelFunc := getTimeoutContext(ctx)
+	defer cancelFunc()
+
+	opts := options.Find().SetSort(bson.D{{Key: "_id", Value: 1}})
+	cursor, err := collection.Find(callCtx, bson.M{}, opts)
+	if err != nil {
+		err = fmt.Errorf("mongodb Find error: %w", err)
+		log.Error(ctx, &logger.Entry{Payload: err})
+		return nil, err
+	}
+
+	results := []*models.ImportProfileDTO{}
+	if err := cursor.All(ctx, &results); err != nil {
+		err = fmt.Errorf("mongodb cursor.All error: %w", err)
+		log.Error(ctx, &logger.Entry{Payload: err})
+		return nil, err
+	}
+
+	return results, nil
+}
+
+func (m *mongoImportProfileRepository) DeleteImportProfile(ctx context.Context, profileID string) error {
+	log := logger.Get()
+
+	collection, err := getImportProfilesCollection(ctx)
+	if err != nil {
+		return err
+	}
+
+	// Creating timeout context for the database call.
+	callCtx, cancelFunc := getTimeoutContext(ctx)
+	defer cancelFunc()
+
+	result, err := collection.DeleteOne(callCtx, bson.M{"_id": profileID})
+	if err != nil {
+		err = fmt.Errorf("mongodb DeleteOne error: %w", err)
+		log.Error(ctx, &logger.Entry{Payload: err})
+		return err
+	}
+
+	if result.DeletedCount == 0 {
+		return errutils.ImportProfileNotFound()
+	}
+	return nil
+}
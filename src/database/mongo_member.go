@@ -0,0 +1,123 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/shivanshkc/ledgerkeep/src/logger"
+	"github.com/shivanshkc/ledgerkeep/src/models"
+	"github.com/shivanshkc/ledgerkeep/src/utils/errutils"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// mongoMemberRepository implements MemberRepository using MongoDB.
+// The memberships are kept in the configured database, as they do not belong to any one tenant.
+type mongoMemberRepository struct{}
+
+func (m *mongoMemberRepository) InsertMember(ctx context.Context, member *models.MemberDTO) error {
+	log := logger.Get()
+
+	// Creating timeout context for the database call.
+	callCtx, cancelFunc := getTimeoutContext(ctx)
+	defer cancelFunc()
+
+	if _, err := getMembersCollection().InsertOne(callCtx, member); err != nil {
+		// Checking if the error is a duplicate key error (already exists error).
+		if mongo.IsDuplicateKeyError(err) {
+			return errutils.MemberAlreadyExists()
+		}
+		err = fmt.Errorf("mongodb InsertOne error: %w", err)
+		log.Error(ctx, &logger.Entry{Payload: err})
+		return err
+	}
+
+	return nil
+}
+
+func (m *mongoMemberRepository) GetMember(ctx context.Context, ledgerID string, userID string) (*models.MemberDTO, error) {
+	log := logger.Get()
+
+	// Creating timeout context for the database call.
+	callCtx, cancelFunc := getTimeoutContext(ctx)
+	defer cancelFunc()
+
+	result := getMembersCollection().FindOne(callCtx, bson.M{"ledger_id": ledgerID, "user_id": userID})
+	if err := result.Err(); err != nil {
+		// Handling the not-exists case.
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, errutils.MemberNotFound()
+		}
+
+		err = fmt.Errorf("mongodb FindOne error: %w", err)
+		log.Error(ctx, &logger.Entry{Payload: err})
+		return nil, err
+	}
+
+	var member *models.MemberDTO
+	if err := result.Decode(&member); err != nil {
+		err = fmt.Errorf("mongodb Decode error: %w", err)
+		log.Error(ctx, &logger.Entry{Payload: err})
+		return nil, err
+	}
+
+	return member, nil
+}
+
+func (m *mongoMemberRepository) ListLedgerMembers(ctx context.Context, ledgerID string) ([]*models.MemberDTO, error) {
+	return m.listMembers(ctx, bson.M{"ledger_id": ledgerID}, "user_id")
+}
+
+func (m *mongoMemberRepository) ListUserMemberships(ctx context.Context, userID string) ([]*models.MemberDTO, error) {
+	return m.listMembers(ctx, bson.M{"user_id": userID}, "ledger_id")
+}
+
+func (m *mongoMemberRepository) DeleteMember(ctx context.Context, ledgerID string, userID string) error {
+	log := logger.Get()
+
+	// Creating timeout context for the database call.
+	callCtx, cancelFunc := getTimeoutContext(ctx)
+	defer cancelFunc()
+
+	result, err := getMembersCollection().DeleteOne(callCtx, bson.M{"ledger_id": ledgerID, "user_id": userID})
+	if err != nil {
+		err = fmt.Errorf("mongodb DeleteOne error: %w", err)
+		log.Error(ctx, &logger.Entry{Payload: err})
+		return err
+	}
+
+	if result.DeletedCount == 0 {
+		return errutils.MemberNotFound()
+	}
+	return nil
+}
+
+// listMembers provides the memberships that match the filter, in ascending order of the sort field.
+func (m *mongoMemberRepository) listMembers(ctx context.Context, filter bson.M, sortField string) ([]*models.MemberDTO,
+	error) {
+	log := logger.Get()
+
+	// Creating timeout context for the database call.
+	callCtx, cancelFunc := getTimeoutContext(ctx)
+	defer cancelFunc()
+
+	opts := options.Find().SetSort(bson.D{{Key: sortField, Value: 1}})
+	cursor, err := getMembersCollection().Find(callCtx, filter, opts)
+	if err != nil {
+		err = fmt.Errorf("mongodb Find error: %w", err)
+		log.Error(ctx, &logger.Entry{Payload: err})
+		return nil, err
+	}
+
+	results := []*models.MemberDTO{}
+	if err := cursor.All(ctx, &results); err != nil {
+		err = fmt.Errorf("mongodb cursor.All error: %w", err)
+		log.Error(ctx, &logger.Entry{Payload: err})
+		return nil, err
+	}
+
+	return results, nil
+}
@@ -0,0 +1,184 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/shivanshkc/ledgerkeep/src/logger"
+	"github.com/shivanshkc/ledgerkeep/src/models"
+	"github.com/shivanshkc/ledgerkeep/src/utils/errutils"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// mongoRecurringTemplateRepository implements RecurringTemplateRepository using MongoDB.
+type mongoRecurringTemplateRepository struct{}
+
+func (m *mongoRecurringTemplateRepository) InsertRecurringTemplate(ctx context.Context,
+	template *models.RecurringTemplateDTO) (string, error) {
+	log := logger.Get()
+
+	collection, err := getRecurringTemplatesCollection(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	// Recurring template IDs are ObjectID hex strings, just like the IDs of the other collections.
+	templateCopy := *template
+	templateCopy.ID = primitive.NewObjectID().Hex()
+
+	// Creating timeout context for the database call.
+	callCtx, cancelFunc := getTimeoutContext(ctx)
+	defer cancelFunc()
+
+	if _, err := collection.InsertOne(callCtx, &templateCopy); err != nil {
+		err = fmt.Errorf("mongodb InsertOne error: %w", err)
+		log.Error(ctx, &logger.Entry{Payload: err})
+		return "", err
+	}
+
+	return templateCopy.ID, nil
+}
+
+func (m *mongoRecurringTemplateRepository) GetRecurringTemplate(ctx context.Context, templateID string) (*models.RecurringTemplateDTO, error) {
+	log := logger.Get()
+
+	collection, err := getRecurringTemplatesCollection(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Creating timeout context for the database call.
+	callCtx, cancelFunc := getTimeoutContext(ctx)
+	defer cancelFunc()
+
+	result := collection.FindOne(callCtx, bson.M{"_id": templateID})
+	if err := result.Err(); err != nil {
+		// Handling the not-exists case.
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, errutils.RecurringTemplateNotFound()
+		}
+
+		err = fmt.Errorf("mongodb FindOne error: %w", err)
+		log.Error(ctx, &logger.Entry{Payload: err})
+		return nil, err
+	}
+
+	var template *models.RecurringTemplateDTO
+	if err := result.Decode(&template); err != nil {
+		err = fmt.Errorf("mongodb Decode error: %w", err)
+		log.Error(ctx, &logger.Entry{Payload: err})
+		return nil, err
+	}
+
+	return template, nil
+}
+
+func (m *mongoRecurringTemplateRepository) ListRecurringTemplates(ctx context.Context) ([]*models.RecurringTemplateDTO, error) {
+	log := logger.Get()
+
+	collection, err := getRecurringTemplatesCollection(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Creating timeout context for the database call.
+	callCtx, cancelFunc := getTimeoutContext(ctx)
+	defer cancelFunc()
+
+	opts := options.Find().SetSort(bson.D{{Key: "_id", Value: 1}})
+	cursor, err := collection.Find(callCtx, bson.M{}, opts)
+	if err != nil {
+		err = fmt.Errorf("mongodb Find error: %w", err)
+		log.Error(ctx, &logger.Entry{Payload: err})
+		return nil, err
+	}
+
+	results := []*models.RecurringTemplateDTO{}
+	if err := cursor.All(ctx, &results); err != nil {
+		err = fmt.Errorf("mongodb cursor.All error: %w", err)
+		log.Error(ctx, &logger.Entry{Payload: err})
+		return nil, err
+	}
+
+	return results, nil
+}
+
+func (m *mongoRecurringTemplateRepository) DeleteRecurringTemplate(ctx context.Context, templateID string) error {
+	log := logger.Get()
+
+	collection, err := getRecurringTemplatesCollection(ctx)
+	if err != nil {
+		return err
+	}
+
+	// Creating timeout context for the database call.
+	callCtx, cancelFunc := getTimeoutContext(ctx)
+	defer cancelFunc()
+
+	result, err := collection.DeleteOne(callCtx, bson.M{"_id": templateID})
+	if err != nil {
+		err = fmt.Errorf("mongodb DeleteOne error: %w", err)
+		log.Error(ctx, &logger.Entry{Payload: err})
+		return err
+	}
+
+	if result.DeletedCount == 0 {
+		return errutils.RecurringTemplateNotFound()
+	}
+	return nil
+}
+
+// AdvanceRecurringTemplate moves the next run time with a conditional update, and inserts the transactions, in a
+// multi-document transaction. Only one of the concurrent runs can move the next run time, and a failure of either
+// write undoes both, so the occurrences are never materialized twice.
+//
+// MongoDB supports such transactions only on replica sets and sharded clusters. The other deployments get the
+// TransactionsNotSupported error.
+func (m *mongoRecurringTemplateRepository) AdvanceRecurringTemplate(ctx context.Context, templateID string,
+	currentNextRun int64, nextRun int64, transactions []*models.TransactionDTO) (bool, error) {
+	log := logger.Get()
+
+	templatesCollection, err := getRecurringTemplatesCollection(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	var advanced bool
+	err = runInMongoTransaction(ctx, func(sessCtx mongo.SessionContext) error {
+		// Creating timeout context for the database call.
+		callCtx, cancelFunc := getTimeoutContext(sessCtx)
+		defer cancelFunc()
+
+		filter := bson.M{"_id": templateID, "next_run": currentNextRun}
+		result, err := templatesCollection.UpdateOne(callCtx, filter, bson.M{"$set": bson.M{"next_run": nextRun}})
+		if err != nil {
+			err = fmt.Errorf("mongodb UpdateOne error: %w", err)
+			log.Error(ctx, &logger.Entry{Payload: err})
+			return err
+		}
+
+		// Telling apart a missing template from one that has been advanced already.
+		if result.MatchedCount == 0 {
+			advanced = false
+			_, err := m.GetRecurringTemplate(sessCtx, templateID)
+			return err
+		}
+
+		advanced = true
+		// Nothing to insert. InsertMany does not accept an empty list of documents.
+		if len(transactions) == 0 {
+			return nil
+		}
+		_, err = insertMongoTransactions(sessCtx, transactions)
+		return err
+	})
+	if err != nil {
+		return false, err
+	}
+	return advanced, nil
+}
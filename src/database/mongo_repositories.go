@@ -0,0 +1,393 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/shivanshkc/ledgerkeep/src/configs"
+	"github.com/shivanshkc/ledgerkeep/src/database/mongodb"
+	"github.com/shivanshkc/ledgerkeep/src/logger"
+	"github.com/shivanshkc/ledgerkeep/src/utils/ctxutils"
+	"github.com/shivanshkc/ledgerkeep/src/utils/errutils"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	accountsCollectionName       = "accounts"
+	transactionsCollectionName   = "transactions"
+	categoriesCollectionName     = "categories"
+	exchangeRatesCollectionName  = "exchange_rates"
+	budgetPlansCollectionName    = "budget_plans"
+	recurringCollectionName      = "recurring_templates"
+	importProfilesCollectionName = "import_profiles"
+	rulesCollectionName          = "rules"
+	usersCollectionName          = "users"
+	membersCollectionName        = "ledger_members"
+	tokensCollectionName         = "api_tokens"
+)
+
+// mongoIndexedDatabases keeps the names of the databases whose indexes are created, or being created.
+var mongoIndexedDatabases = &sync.Map{}
+
+var (
+	// mongoTopologyMutex guards mongoTransactionsSupported.
+	mongoTopologyMutex = &sync.Mutex{}
+	// mongoTransactionsSupported tells if the deployment supports multi-document transactions. It is nil until known.
+	mongoTransactionsSupported *bool
+)
+
+// newMongoRepositories provides the Repositories backed by MongoDB.
+// Panic is allowed here because storage is crucial to the application.
+func newMongoRepositories() *Repositories {
+	// The amounts must be migrated before any transaction is read or written.
+	// This also initiates a connection with the database upon application startup.
+	if err := migrateMongoAmounts(context.Background()); err != nil {
+		panic(err)
+	}
+
+	repos := &Repositories{
+		Accounts:      &mongoAccountRepository{},
+		Transactions:  &mongoTransactionRepository{},
+		Categories:    &mongoCategoryRepository{},
+		ExchangeRates: &mongoExchangeRateRepository{},
+		BudgetPlans:   &mongoBudgetPlanRepository{},
+		Recurring:     &mongoRecurringTemplateRepository{},
+		Imports:       &mongoImportProfileRepository{},
+		Rules:         &mongoRuleRepository{},
+		Backups:       &mongoBackupRepository{},
+		Users:         &mongoUserRepository{},
+		Members:       &mongoMemberRepository{},
+		Tokens:        &mongoTokenRepository{},
+	}
+
+	// Creating the indexes of the collections that are not scoped to a tenant.
+	go func() {
+		if err := createMongoMemberIndexes(context.Background()); err != nil {
+			panic(err)
+		}
+		if err := createMongoTokenIndexes(context.Background()); err != nil {
+			panic(err)
+		}
+	}()
+
+	// The admin user and its categories are required to use the application.
+	if err := seedAdminUser(context.Background(), repos); err != nil {
+		panic(err)
+	}
+
+	return repos
+}
+
+// createMongoIndexes creates all the indexes required by the MongoDB repositories in the provided tenant database.
+func createMongoIndexes(ctx context.Context, database *mongo.Database) error {
+	log := logger.Get()
+
+	callCtx, cancelFunc := getTimeoutContext(ctx)
+	defer cancelFunc()
+
+	indexData := []mongo.IndexModel{
+		{Keys: bson.D{{Key: "account_id", Value: 1}}}, // Ascending B-tree index on "account_id".
+		{Keys: bson.D{{Key: "notes", Value: "text"}}}, // Text index on "notes".
+		// Sparse index on "transfer_id", which only the legs of transfers have.
+		{Keys: bson.D{{Key: "transfer_id", Value: 1}}, Options: options.Index().SetSparse(true)},
+		// Sparse index on "external_id", which only the imported transactions have.
+		{Keys: bson.D{{Key: "external_id", Value: 1}}, Options: options.Index().SetSparse(true)},
+	}
+
+	// Creating the indexes.
+	if _, err := database.Collection(transactionsCollectionName).Indexes().CreateMany(callCtx, indexData); err != nil {
+		err = fmt.Errorf("mongodb Indexes.CreateMany error: %w", err)
+		log.Error(ctx, &logger.Entry{Payload: err})
+		return err
+	}
+
+	// An exchange rate is unique for its currency pair and timestamp.
+	exchangeRateIndex := mongo.IndexModel{
+		Keys:    bson.D{{Key: "base", Value: 1}, {Key: "quote", Value: 1}, {Key: "timestamp", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	}
+
+	if _, err := database.Collection(exchangeRatesCollectionName).Indexes().CreateOne(callCtx, exchangeRateIndex); err != nil {
+		err = fmt.Errorf("mongodb Indexes.CreateOne error: %w", err)
+		log.Error(ctx, &logger.Entry{Payload: err})
+		return err
+	}
+
+	return nil
+}
+
+// createMongoMemberIndexes creates the indexes of the ledger members collection.
+func createMongoMemberIndexes(ctx context.Context) error {
+	log := logger.Get()
+
+	callCtx, cancelFunc := getTimeoutContext(ctx)
+	defer cancelFunc()
+
+	indexData := []mongo.IndexModel{
+		// A user can only have one membership per ledger.
+		{
+			Keys:    bson.D{{Key: "ledger_id", Value: 1}, {Key: "user_id", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{Keys: bson.D{{Key: "user_id", Value: 1}}}, // Ascending B-tree index on "user_id".
+	}
+
+	if _, err := getMembersCollection().Indexes().CreateMany(callCtx, indexData); err != nil {
+		err = fmt.Errorf("mongodb Indexes.CreateMany error: %w", err)
+		log.Error(ctx, &logger.Entry{Payload: err})
+		return err
+	}
+
+	return nil
+}
+
+// createMongoTokenIndexes creates the indexes of the API tokens collection.
+func createMongoTokenIndexes(ctx context.Context) error {
+	log := logger.Get()
+
+	callCtx, cancelFunc := getTimeoutContext(ctx)
+	defer cancelFunc()
+
+	indexData := []mongo.IndexModel{
+		// The tokens are looked up by their hashes.
+		{Keys: bson.D{{Key: "token_hash", Value: 1}}, Options: options.Index().SetUnique(true)},
+		{Keys: bson.D{{Key: "user_id", Value: 1}}}, // Ascending B-tree index on "user_id".
+	}
+
+	if _, err := getTokensCollection().Indexes().CreateMany(callCtx, indexData); err != nil {
+		err = fmt.Errorf("mongodb Indexes.CreateMany error: %w", err)
+		log.Error(ctx, &logger.Entry{Payload: err})
+		return err
+	}
+
+	return nil
+}
+
+// migrateMongoAmounts converts the float64 amounts of the transactions, which were stored before the introduction of
+// models.Money, into integer Money units. It is idempotent, because the converted amounts are no longer doubles.
+//
+// Such transactions can only exist in the database of the default tenant, as tenants were introduced later.
+func migrateMongoAmounts(ctx context.Context) error {
+	log := logger.Get()
+
+	transactions, err := getTransactionsCollection(ctxutils.PutTenantID(ctx, DefaultTenantID))
+	if err != nil {
+		return err
+	}
+
+	callCtx, cancelFunc := getTimeoutContext(ctx)
+	defer cancelFunc()
+
+	// The multiplier is the number of Money units in one currency unit. Rounding removes the float64 noise.
+	filter := bson.M{"amount": bson.M{"$type": "double"}}
+	updates := mongo.Pipeline{{{Key: "$set", Value: bson.D{{Key: "amount", Value: bson.D{{Key: "$toLong", Value: bson.D{
+		{Key: "$round", Value: bson.A{bson.D{{Key: "$multiply", Value: bson.A{"$amount", 10000}}}, 0}},
+	}}}}}}}}
+
+	result, err := transactions.UpdateMany(callCtx, filter, updates)
+	if err != nil {
+		err = fmt.Errorf("mongodb UpdateMany error: %w", err)
+		log.Error(ctx, &logger.Entry{Payload: err})
+		return err
+	}
+
+	if result.ModifiedCount > 0 {
+		log.Info(ctx, &logger.Entry{Payload: fmt.Sprintf("Migrated the amounts of %d transactions.", result.ModifiedCount)})
+	}
+	return nil
+}
+
+// getTenantDatabase provides the mongoDB database of the tenant in the context.
+//
+// Every tenant has a database of its own, so no query can ever reach the documents of another tenant. The default
+// tenant uses the configured database, which keeps the data that existed before the introduction of tenants.
+func getTenantDatabase(ctx context.Context) (*mongo.Database, error) {
+	tenantID, err := getTenantID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	databaseName := configs.Get().Mongo.DatabaseName
+	if tenantID != DefaultTenantID {
+		databaseName = databaseName + "_" + tenantID
+	}
+
+	database := mongodb.GetClient().Database(databaseName)
+
+	// The indexes are created in the background, once for every tenant database.
+	if _, indexed := mongoIndexedDatabases.LoadOrStore(databaseName, true); !indexed {
+		go func() {
+			if err := createMongoIndexes(context.Background(), database); err != nil {
+				// The next call will try again.
+				mongoIndexedDatabases.Delete(databaseName)
+			}
+		}()
+	}
+
+	return database, nil
+}
+
+// getAccountsCollection provides the accounts mongoDB collection of the tenant in the context.
+func getAccountsCollection(ctx context.Context) (*mongo.Collection, error) {
+	database, err := getTenantDatabase(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return database.Collection(accountsCollectionName), nil
+}
+
+// getTransactionsCollection provides the transactions mongoDB collection of the tenant in the context.
+func getTransactionsCollection(ctx context.Context) (*mongo.Collection, error) {
+	database, err := getTenantDatabase(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return database.Collection(transactionsCollectionName), nil
+}
+
+// getCategoriesCollection provides the categories mongoDB collection of the tenant in the context.
+func getCategoriesCollection(ctx context.Context) (*mongo.Collection, error) {
+	database, err := getTenantDatabase(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return database.Collection(categoriesCollectionName), nil
+}
+
+// getExchangeRatesCollection provides the exchange rates mongoDB collection of the tenant in the context.
+func getExchangeRatesCollection(ctx context.Context) (*mongo.Collection, error) {
+	database, err := getTenantDatabase(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return database.Collection(exchangeRatesCollectionName), nil
+}
+
+// getBudgetPlansCollection provides the budget plans mongoDB collection of the tenant in the context.
+func getBudgetPlansCollection(ctx context.Context) (*mongo.Collection, error) {
+	database, err := getTenantDatabase(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return database.Collection(budgetPlansCollectionName), nil
+}
+
+// getRecurringTemplatesCollection provides the recurring templates mongoDB collection of the tenant in the context.
+func getRecurringTemplatesCollection(ctx context.Context) (*mongo.Collection, error) {
+	database, err := getTenantDatabase(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return database.Collection(recurringCollectionName), nil
+}
+
+// getImportProfilesCollection provides the import profiles mongoDB collection of the tenant in the context.
+func getImportProfilesCollection(ctx context.Context) (*mongo.Collection, error) {
+	database, err := getTenantDatabase(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return database.Collection(importProfilesCollectionName), nil
+}
+
+// getRulesCollection provides the categorization rules mongoDB collection of the tenant in the context.
+func getRulesCollection(ctx context.Context) (*mongo.Collection, error) {
+	database, err := getTenantDatabase(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return database.Collection(rulesCollectionName), nil
+}
+
+// getUsersCollection provides the users mongoDB collection. The users are not scoped to any tenant.
+func getUsersCollection() *mongo.Collection {
+	conf := configs.Get()
+	return mongodb.GetClient().Database(conf.Mongo.DatabaseName).Collection(usersCollectionName)
+}
+
+// getMembersCollection provides the ledger members mongoDB collection. The memberships are not scoped to any tenant.
+func getMembersCollection() *mongo.Collection {
+	conf := configs.Get()
+	return mongodb.GetClient().Database(conf.Mongo.DatabaseName).Collection(membersCollectionName)
+}
+
+// getTokensCollection provides the API tokens mongoDB collection. The tokens are not scoped to any tenant.
+func getTokensCollection() *mongo.Collection {
+	conf := configs.Get()
+	return mongodb.GetClient().Database(conf.Mongo.DatabaseName).Collection(tokensCollectionName)
+}
+
+// runInMongoTransaction runs the function in a multi-document transaction, whose session is carried by the context
+// that the function gets. The function may run more than once, as the transactions with transient errors are retried.
+//
+// MongoDB supports such transactions only on replica sets and sharded clusters. The other deployments get the
+// TransactionsNotSupported error, as writing the documents one at a time could leave only some of them written.
+func runInMongoTransaction(ctx context.Context, txFunc func(sessCtx mongo.SessionContext) error) error {
+	log := logger.Get()
+
+	supported, err := mongoSupportsTransactions(ctx)
+	if err != nil {
+		return err
+	}
+	if !supported {
+		return errutils.TransactionsNotSupported()
+	}
+
+	session, err := mongodb.GetClient().StartSession()
+	if err != nil {
+		err = fmt.Errorf("mongodb StartSession error: %w", err)
+		log.Error(ctx, &logger.Entry{Payload: err})
+		return err
+	}
+	defer session.EndSession(ctx)
+
+	_, err = session.WithTransaction(ctx, func(sessCtx mongo.SessionContext) (interface{}, error) {
+		return nil, txFunc(sessCtx)
+	})
+	return err
+}
+
+// mongoSupportsTransactions checks if the MongoDB deployment is a replica set or a sharded cluster. The deployment
+// is checked once, as it does not change while the application runs.
+func mongoSupportsTransactions(ctx context.Context) (bool, error) {
+	log := logger.Get()
+
+	mongoTopologyMutex.Lock()
+	defer mongoTopologyMutex.Unlock()
+
+	if mongoTransactionsSupported != nil {
+		return *mongoTransactionsSupported, nil
+	}
+
+	// Creating timeout context for the database call.
+	callCtx, cancelFunc := getTimeoutContext(ctx)
+	defer cancelFunc()
+
+	// The members of replica sets provide their set names, and the routers of sharded clusters the "isdbgrid" message.
+	var result struct {
+		SetName string `bson:"setName"`
+		Msg     string `bson:"msg"`
+	}
+	command := bson.D{{Key: "isMaster", Value: 1}}
+	if err := mongodb.GetClient().Database("admin").RunCommand(callCtx, command).Decode(&result); err != nil {
+		err = fmt.Errorf("mongodb isMaster error: %w", err)
+		log.Error(ctx, &logger.Entry{Payload: err})
+		return false, err
+	}
+
+	supported := result.SetName != "" || result.Msg == "isdbgrid"
+	mongoTransactionsSupported = &supported
+	return supported, nil
+}
+
+// getTimeoutContext provides the timeout context for database operations.
+func getTimeoutContext(parent context.Context) (context.Context, context.CancelFunc) {
+	conf := configs.Get()
+	timeoutDuration := time.Duration(conf.Mongo.OperationTimeoutSec) * time.Second
+	return context.WithTimeout(parent, timeoutDuration)
+}
@@ -0,0 +1,97 @@
+package database
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/shivanshkc/ledgerkeep/src/logger"
+	"github.com/shivanshkc/ledgerkeep/src/models"
+	"github.com/shivanshkc/ledgerkeep/src/utils/errutils"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// mongoRuleRepository implements RuleRepository using MongoDB.
+type mongoRuleRepository struct{}
+
+func (m *mongoRuleRepository) InsertRule(ctx context.Context, rule *models.RuleDTO) (string, error) {
+	log := logger.Get()
+
+	collection, err := getRulesCollection(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	// Rule IDs are ObjectID hex strings, just like the IDs of the other collections.
+	ruleCopy := *rule
+	ruleCopy.ID = primitive.NewObjectID().Hex()
+
+	// Creating timeout context for the database call.
+	callCtx, cancelFunc := getTimeoutContext(ctx)
+	defer cancelFunc()
+
+	if _, err := collection.InsertOne(callCtx, &ruleCopy); err != nil {
+		err = fmt.Errorf("mongodb InsertOne error: %w", err)
+		log.Error(ctx, &logger.Entry{Payload: err})
+		return "", err
+	}
+
+	return ruleCopy.ID, nil
+}
+
+func (m *mongoRuleRepository) ListRules(ctx context.Context) ([]*models.RuleDTO, error) {
+	log := logger.Get()
+
+	collection, err := getRulesCollection(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Creating timeout context for the database call.
+	callCtx, cancelFunc := getTimeoutContext(ctx)
+	defer cancelFunc()
+
+	opts := options.Find().SetSort(bson.D{{Key: "priority", Value: 1}, {Key: "_id", Value: 1}})
+	cursor, err := collection.Find(callCtx, bson.M{}, opts)
+	if err != nil {
+		err = fmt.Errorf("mongodb Find error: %w", err)
+		log.Error(ctx, &logger.Entry{Payload: err})
+		return nil, err
+	}
+
+	results := []*models.RuleDTO{}
+	if err := cursor.All(ctx, &results); err != nil {
+		err = fmt.Errorf("mongodb cursor.All error: %w", err)
+		log.Error(ctx, &logger.Entry{Payload: err})
+		return nil, err
+	}
+
+	return results, nil
+}
+
+func (m *mongoRuleRepository) DeleteRule(ctx context.Context, ruleID string) error {
+	log := logger.Get()
+
+	collection, err := getRulesCollection(ctx)
+	if err != nil {
+		return err
+	}
+
+	// Creating timeout context for the database call.
+	callCtx, cancelFunc := getTimeoutContext(ctx)
+	defer cancelFunc()
+
+	result, err := collection.DeleteOne(callCtx, bson.M{"_id": ruleID})
+	if err != nil {
+		err = fmt.Errorf("mongodb DeleteOne error: %w", err)
+		log.Error(ctx, &logger.Entry{Payload: err})
+		return err
+	}
+
+	if result.DeletedCount == 0 {
+		return errutils.RuleNotFound()
+	}
+	return nil
+}
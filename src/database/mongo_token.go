@@ -0,0 +1,130 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/shivanshkc/ledgerkeep/src/logger"
+	"github.com/shivanshkc/ledgerkeep/src/models"
+	"github.com/shivanshkc/ledgerkeep/src/utils/errutils"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// mongoTokenRepository implements TokenRepository using MongoDB.
+// The tokens are kept in the configured database, as they do not belong to any tenant.
+type mongoTokenRepository struct{}
+
+func (m *mongoTokenRepository) InsertToken(ctx context.Context, token *models.TokenDTO) error {
+	log := logger.Get()
+
+	// Creating timeout context for the database call.
+	callCtx, cancelFunc := getTimeoutContext(ctx)
+	defer cancelFunc()
+
+	if _, err := getTokensCollection().InsertOne(callCtx, token); err != nil {
+		err = fmt.Errorf("mongodb InsertOne error: %w", err)
+		log.Error(ctx, &logger.Entry{Payload: err})
+		return err
+	}
+
+	return nil
+}
+
+func (m *mongoTokenRepository) GetTokenByHash(ctx context.Context, tokenHash string) (*models.TokenDTO, error) {
+	log := logger.Get()
+
+	// Creating timeout context for the database call.
+	callCtx, cancelFunc := getTimeoutContext(ctx)
+	defer cancelFunc()
+
+	result := getTokensCollection().FindOne(callCtx, bson.M{"token_hash": tokenHash})
+	if err := result.Err(); err != nil {
+		// Handling the not-exists case.
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, errutils.TokenNotFound()
+		}
+
+		err = fmt.Errorf("mongodb FindOne error: %w", err)
+		log.Error(ctx, &logger.Entry{Payload: err})
+		return nil, err
+	}
+
+	var token *models.TokenDTO
+	if err := result.Decode(&token); err != nil {
+		err = fmt.Errorf("mongodb Decode error: %w", err)
+		log.Error(ctx, &logger.Entry{Payload: err})
+		return nil, err
+	}
+
+	return token, nil
+}
+
+func (m *mongoTokenRepository) ListUserTokens(ctx context.Context, userID string) ([]*models.TokenDTO, error) {
+	log := logger.Get()
+
+	// Creating timeout context for the database call.
+	callCtx, cancelFunc := getTimeoutContext(ctx)
+	defer cancelFunc()
+
+	opts := options.Find().SetSort(bson.D{{Key: "_id", Value: 1}})
+	cursor, err := getTokensCollection().Find(callCtx, bson.M{"user_id": userID}, opts)
+	if err != nil {
+		err = fmt.Errorf("mongodb Find error: %w", err)
+		log.Error(ctx, &logger.Entry{Payload: err})
+		return nil, err
+	}
+
+	results := []*models.TokenDTO{}
+	if err := cursor.All(ctx, &results); err != nil {
+		err = fmt.Errorf("mongodb cursor.All error: %w", err)
+		log.Error(ctx, &logger.Entry{Payload: err})
+		return nil, err
+	}
+
+	return results, nil
+}
+
+func (m *mongoTokenRepository) UpdateTokenLastUsed(ctx context.Context, tokenID string, lastUsedAt int64) error {
+	log := logger.Get()
+
+	// Creating timeout context for the database call.
+	callCtx, cancelFunc := getTimeoutContext(ctx)
+	defer cancelFunc()
+
+	update := bson.M{"$set": bson.M{"last_used_at": lastUsedAt}}
+	result, err := getTokensCollection().UpdateOne(callCtx, bson.M{"_id": tokenID}, update)
+	if err != nil {
+		err = fmt.Errorf("mongodb UpdateOne error: %w", err)
+		log.Error(ctx, &logger.Entry{Payload: err})
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return errutils.TokenNotFound()
+	}
+	return nil
+}
+
+func (m *mongoTokenRepository) DeleteToken(ctx context.Context, userID string, tokenID string) error {
+	log := logger.Get()
+
+	// Creating timeout context for the database call.
+	callCtx, cancelFunc := getTimeoutContext(ctx)
+	defer cancelFunc()
+
+	result, err := getTokensCollection().DeleteOne(callCtx, bson.M{"_id": tokenID, "user_id": userID})
+	if err != nil {
+		err = fmt.Errorf("mongodb DeleteOne error: %w", err)
+		log.Error(ctx, &logger.Entry{Payload: err})
+		return err
+	}
+
+	if result.DeletedCount == 0 {
+		return errutils.TokenNotFound()
+	}
+	return nil
+}
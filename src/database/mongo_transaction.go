@@ -0,0 +1,568 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/shivanshkc/ledgerkeep/src/logger"
+	"github.com/shivanshkc/ledgerkeep/src/models"
+	"github.com/shivanshkc/ledgerkeep/src/utils/errutils"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"golang.org/x/sync/errgroup"
+)
+
+// mongoTransactionRepository implements TransactionRepository using MongoDB.
+type mongoTransactionRepository struct{}
+
+func (m *mongoTransactionRepository) InsertTransaction(ctx context.Context, transaction *models.TransactionDTO) (string, error) {
+	log := logger.Get()
+
+	collection, err := getTransactionsCollection(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	// Creating timeout context for the database call.
+	callCtx, cancelFunc := getTimeoutContext(ctx)
+	defer cancelFunc()
+
+	result, err := collection.InsertOne(callCtx, transaction)
+	if err != nil {
+		err = fmt.Errorf("mongodb InsertOne error: %w", err)
+		log.Error(ctx, &logger.Entry{Payload: err})
+		return "", err
+	}
+
+	// MongoDB generates an ObjectID for the inserted document.
+	insertedID, ok := result.InsertedID.(primitive.ObjectID)
+	if !ok {
+		err := fmt.Errorf("unexpected inserted ID type: %T", result.InsertedID)
+		log.Error(ctx, &logger.Entry{Payload: err})
+		return "", err
+	}
+
+	return insertedID.Hex(), nil
+}
+
+func (m *mongoTransactionRepository) GetTransaction(ctx context.Context, transactionIDStr string) (*models.TransactionDTO, error) {
+	log := logger.Get()
+
+	collection, err := getTransactionsCollection(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// An ID that is not a valid ObjectID cannot belong to any transaction.
+	transactionID, err := primitive.ObjectIDFromHex(transactionIDStr)
+	if err != nil {
+		return nil, errutils.TransactionNotFound()
+	}
+
+	// Creating timeout context for the database call.
+	callCtx, cancelFunc := getTimeoutContext(ctx)
+	defer cancelFunc()
+
+	result := collection.FindOne(callCtx, bson.M{"_id": transactionID})
+	if err := result.Err(); err != nil {
+		// Handling the not-exists case.
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, errutils.TransactionNotFound()
+		}
+
+		err = fmt.Errorf("mongodb FindOne error: %w", err)
+		log.Error(ctx, &logger.Entry{Payload: err})
+		return nil, err
+	}
+
+	var transaction *models.TransactionDTO
+	if err := result.Decode(&transaction); err != nil {
+		err = fmt.Errorf("mongodb Decode error: %w", err)
+		log.Error(ctx, &logger.Entry{Payload: err})
+		return nil, err
+	}
+
+	return transaction, nil
+}
+
+func (m *mongoTransactionRepository) ListTransactions(ctx context.Context, params *ListTransactionsParams) ([]*models.TransactionDTO, int, error) {
+	log := logger.Get()
+
+	collection, err := getTransactionsCollection(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	errs, errCtx := errgroup.WithContext(ctx)
+	// We need to fetch the list of transactions as well as the total count for pagination purposes.
+	// Both these calls will be in parallel.
+	// These channels will be used to process the calls.
+	transactionsChan := make(chan []*models.TransactionDTO, 1)
+	countChan := make(chan int64, 1)
+
+	// Sending a parallel call to database for counting transactions.
+	errs.Go(func() error {
+		// Closing the channels upon function return.
+		defer close(countChan)
+		// If the count is not required, we don't query the DB.
+		if params.ExcludeCount {
+			countChan <- 0
+			return nil
+		}
+
+		// Creating timeout context for the database call.
+		callCtx, cancelFunc := getTimeoutContext(errCtx)
+		defer cancelFunc()
+
+		count, err := collection.CountDocuments(callCtx, params.Filter)
+		if err != nil {
+			return fmt.Errorf("mongodb CountDocuments error: %w", err)
+		}
+		countChan <- count
+		return nil
+	})
+
+	// Sending another parallel call for listing transactions.
+	errs.Go(func() error {
+		// Closing the channels upon function return.
+		defer close(transactionsChan)
+
+		opts := options.Find()
+		// Setting the projection value to only include the specified fields.
+		projectionBson := bson.D{}
+		for _, field := range params.RequiredFields {
+			projectionBson = append(projectionBson, bson.E{Key: field, Value: 1})
+		}
+		opts.SetProjection(projectionBson)                                                 // Specifying the fields to include.
+		opts.SetLimit(int64(params.PaginationLimit)).SetSkip(int64(params.PaginationSkip)) // Specifying limit and skip.
+
+		// Specifying sortField and sortOrder.
+		opts.SetSort(bson.D{
+			{Key: params.SortField, Value: params.SortOrder},
+			// The second sortField is always ID, which takes the same order as the other field.
+			// This brings better consistency in transaction ordering and is a must for closing balance's calculation.
+			{Key: "_id", Value: params.SortOrder},
+		})
+
+		// Creating timeout context for the database call.
+		callCtx, cancelFunc := getTimeoutContext(errCtx)
+		defer cancelFunc()
+
+		cursor, err := collection.Find(callCtx, params.Filter, opts)
+		if err != nil {
+			return fmt.Errorf("mongodb Find error: %w", err)
+		}
+
+		// Decoding the transaction list into known slice type.
+		var transactions []*models.TransactionDTO
+		if err := cursor.All(ctx, &transactions); err != nil {
+			return fmt.Errorf("mongodb cursor.All error: %w", err)
+		}
+		transactionsChan <- transactions
+		return nil
+	})
+
+	// Checking for errors.
+	if err := errs.Wait(); err != nil {
+		log.Error(ctx, &logger.Entry{Payload: err})
+		return nil, 0, fmt.Errorf("error in one of the goroutines: %w", err)
+	}
+
+	// Retrieving the call results.
+	transactions := <-transactionsChan
+	count := <-countChan
+
+	return transactions, int(count), nil
+}
+
+func (m *mongoTransactionRepository) StreamTransactions(ctx context.Context, params *ListTransactionsParams) (TransactionCursor, error) {
+	log := logger.Get()
+
+	collection, err := getTransactionsCollection(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// The split lines are not streamed, like in the SQL backends.
+	projectionBson := bson.D{{Key: "splits", Value: 0}}
+	if requiredFields, _ := excludeSplitsField(params.RequiredFields); len(requiredFields) > 0 {
+		projectionBson = bson.D{}
+		for _, field := range requiredFields {
+			projectionBson = append(projectionBson, bson.E{Key: field, Value: 1})
+		}
+	}
+
+	opts := options.Find().SetProjection(projectionBson).SetSort(bson.D{
+		{Key: params.SortField, Value: params.SortOrder},
+		// The second sortField is always ID, which takes the same order as the other field.
+		{Key: "_id", Value: params.SortOrder},
+	})
+
+	// The cursor is read for as long as the caller needs, so the operation timeout does not apply to it.
+	cursor, err := collection.Find(ctx, params.Filter, opts)
+	if err != nil {
+		err = fmt.Errorf("mongodb Find error: %w", err)
+		log.Error(ctx, &logger.Entry{Payload: err})
+		return nil, err
+	}
+
+	return &mongoTransactionCursor{cursor: cursor}, nil
+}
+
+func (m *mongoTransactionRepository) GetCategoryTotals(ctx context.Context, filter map[string]interface{}) ([]*CategoryTotals, error) {
+	log := logger.Get()
+
+	collection, err := getTransactionsCollection(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Creating timeout context for the database call.
+	callCtx, cancelFunc := getTimeoutContext(ctx)
+	defer cancelFunc()
+
+	// The $match stage does not accept a nil filter.
+	if filter == nil {
+		filter = bson.M{}
+	}
+
+	// This query aggregates (account_id, category) -> credit and debit sums.
+	matchStage := bson.D{{Key: "$match", Value: filter}}
+	// Every split line counts in its own category. A transaction without splits is a single line.
+	hasSplits := bson.D{{Key: "$gt", Value: bson.A{
+		bson.D{{Key: "$size", Value: bson.D{{Key: "$ifNull", Value: bson.A{"$splits", bson.A{}}}}}}, 0,
+	}}}
+	linesStage := bson.D{{
+		Key: "$project",
+		Value: bson.D{
+			{Key: "account_id", Value: 1},
+			{Key: "lines", Value: bson.D{{Key: "$cond", Value: bson.A{
+				hasSplits,
+				"$splits",
+				bson.A{bson.D{{Key: "amount", Value: "$amount"}, {Key: "category", Value: "$category"}}},
+			}}}},
+		},
+	}}
+	unwindStage := bson.D{{Key: "$unwind", Value: "$lines"}}
+	groupStage := bson.D{{
+		Key: "$group",
+		Value: bson.D{
+			{Key: "_id", Value: bson.D{{Key: "account_id", Value: "$account_id"}, {Key: "category", Value: "$lines.category"}}},
+			{Key: "credit", Value: bson.D{{Key: "$sum", Value: bson.D{{Key: "$cond", Value: bson.A{
+				bson.D{{Key: "$gt", Value: bson.A{"$lines.amount", 0}}}, "$lines.amount", 0,
+			}}}}}},
+			{Key: "debit", Value: bson.D{{Key: "$sum", Value: bson.D{{Key: "$cond", Value: bson.A{
+				bson.D{{Key: "$lt", Value: bson.A{"$lines.amount", 0}}}, "$lines.amount", 0,
+			}}}}}},
+		},
+	}}
+
+	// Database call.
+	pipeline := mongo.Pipeline{matchStage, linesStage, unwindStage, groupStage}
+	cursor, err := collection.Aggregate(callCtx, pipeline)
+	if err != nil {
+		err = fmt.Errorf("mongodb Aggregate error: %w", err)
+		log.Error(ctx, &logger.Entry{Payload: err})
+		return nil, err
+	}
+
+	var results []struct {
+		ID struct {
+			AccountID string `bson:"account_id"`
+			Category  string `bson:"category"`
+		} `bson:"_id"`
+		Credit models.Money `bson:"credit"`
+		Debit  models.Money `bson:"debit"`
+	}
+
+	if err := cursor.All(ctx, &results); err != nil {
+		err = fmt.Errorf("mongodb cursor.All error: %w", err)
+		log.Error(ctx, &logger.Entry{Payload: err})
+		return nil, err
+	}
+
+	totals := make([]*CategoryTotals, len(results))
+	for idx, value := range results {
+		totals[idx] = &CategoryTotals{
+			AccountID: value.ID.AccountID,
+			Category:  value.ID.Category,
+			Credit:    value.Credit,
+			Debit:     value.Debit,
+		}
+	}
+
+	return totals, nil
+}
+
+func (m *mongoTransactionRepository) UpdateTransaction(ctx context.Context, transactionIDStr string, updates map[string]interface{}) error {
+	log := logger.Get()
+
+	collection, err := getTransactionsCollection(ctx)
+	if err != nil {
+		return err
+	}
+
+	// An ID that is not a valid ObjectID cannot belong to any transaction.
+	transactionID, err := primitive.ObjectIDFromHex(transactionIDStr)
+	if err != nil {
+		return errutils.TransactionNotFound()
+	}
+
+	// Creating timeout context for the database call.
+	callCtx, cancelFunc := getTimeoutContext(ctx)
+	defer cancelFunc()
+
+	// Wrapping the updates with $set operator of mongodb.
+	updates = bson.M{"$set": updates}
+
+	result, err := collection.UpdateOne(callCtx, bson.M{"_id": transactionID}, updates)
+	if err != nil {
+		err = fmt.Errorf("mongodb UpdateOne error: %w", err)
+		log.Error(ctx, &logger.Entry{Payload: err})
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return errutils.TransactionNotFound()
+	}
+	return nil
+}
+
+func (m *mongoTransactionRepository) DeleteTransaction(ctx context.Context, transactionIDStr string) error {
+	log := logger.Get()
+
+	collection, err := getTransactionsCollection(ctx)
+	if err != nil {
+		return err
+	}
+
+	// An ID that is not a valid ObjectID cannot belong to any transaction.
+	transactionID, err := primitive.ObjectIDFromHex(transactionIDStr)
+	if err != nil {
+		return errutils.TransactionNotFound()
+	}
+
+	// Creating timeout context for the database call.
+	callCtx, cancelFunc := getTimeoutContext(ctx)
+	defer cancelFunc()
+
+	result, err := collection.DeleteOne(callCtx, bson.M{"_id": transactionID})
+	if err != nil {
+		err = fmt.Errorf("mongodb DeleteOne error: %w", err)
+		log.Error(ctx, &logger.Entry{Payload: err})
+		return err
+	}
+
+	if result.DeletedCount == 0 {
+		return errutils.TransactionNotFound()
+	}
+	return nil
+}
+
+// InsertTransactions inserts all the transactions in a multi-document transaction, which MongoDB supports only on
+// replica sets and sharded clusters. The other deployments get the TransactionsNotSupported error.
+func (m *mongoTransactionRepository) InsertTransactions(ctx context.Context,
+	transactions []*models.TransactionDTO) ([]string, error) {
+	// InsertMany does not accept an empty list of documents.
+	if len(transactions) == 0 {
+		return []string{}, nil
+	}
+
+	var ids []string
+	err := runInMongoTransaction(ctx, func(sessCtx mongo.SessionContext) error {
+		var err error
+		ids, err = insertMongoTransactions(sessCtx, transactions)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+// InsertTransfer inserts all the legs in a multi-document transaction, so either all of them exist or none does.
+// MongoDB supports such transactions only on replica sets and sharded clusters. The other deployments get the
+// TransactionsNotSupported error.
+func (m *mongoTransactionRepository) InsertTransfer(ctx context.Context, legs []*models.TransactionDTO) ([]string, error) {
+	var ids []string
+	err := runInMongoTransaction(ctx, func(sessCtx mongo.SessionContext) error {
+		var err error
+		ids, err = insertMongoTransactions(sessCtx, legs)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+// UpdateTransactions checks the existence of all the transactions and applies all the updates in a multi-document
+// transaction, which MongoDB supports only on replica sets and sharded clusters. The other deployments get the
+// TransactionsNotSupported error.
+func (m *mongoTransactionRepository) UpdateTransactions(ctx context.Context, updates map[string]map[string]interface{}) error {
+	log := logger.Get()
+
+	collection, err := getTransactionsCollection(ctx)
+	if err != nil {
+		return err
+	}
+
+	writeModels := make([]mongo.WriteModel, 0, len(updates))
+	transactionIDs := make([]primitive.ObjectID, 0, len(updates))
+
+	for transactionIDStr, txUpdates := range updates {
+		// An ID that is not a valid ObjectID cannot belong to any transaction.
+		transactionID, err := primitive.ObjectIDFromHex(transactionIDStr)
+		if err != nil {
+			return errutils.TransactionNotFound()
+		}
+
+		transactionIDs = append(transactionIDs, transactionID)
+		writeModels = append(writeModels, mongo.NewUpdateOneModel().
+			SetFilter(bson.M{"_id": transactionID}).
+			SetUpdate(bson.M{"$set": txUpdates}))
+	}
+
+	return runInMongoTransaction(ctx, func(sessCtx mongo.SessionContext) error {
+		// Creating timeout context for the database calls.
+		callCtx, cancelFunc := getTimeoutContext(sessCtx)
+		defer cancelFunc()
+
+		count, err := collection.CountDocuments(callCtx, bson.M{"_id": bson.M{"$in": transactionIDs}})
+		if err != nil {
+			err = fmt.Errorf("mongodb CountDocuments error: %w", err)
+			log.Error(ctx, &logger.Entry{Payload: err})
+			return err
+		}
+		if int(count) != len(transactionIDs) {
+			return errutils.TransactionNotFound()
+		}
+
+		if _, err := collection.BulkWrite(callCtx, writeModels); err != nil {
+			err = fmt.Errorf("mongodb BulkWrite error: %w", err)
+			log.Error(ctx, &logger.Entry{Payload: err})
+			return err
+		}
+		return nil
+	})
+}
+
+// DeleteTransfer deletes all the legs in a multi-document transaction, which MongoDB supports only on replica sets and
+// sharded clusters. The other deployments get the TransactionsNotSupported error.
+func (m *mongoTransactionRepository) DeleteTransfer(ctx context.Context, transferID string) error {
+	log := logger.Get()
+
+	collection, err := getTransactionsCollection(ctx)
+	if err != nil {
+		return err
+	}
+
+	// Transactions that are not legs of a transfer do not have a transfer ID.
+	if transferID == "" {
+		return errutils.TransactionNotFound()
+	}
+
+	return runInMongoTransaction(ctx, func(sessCtx mongo.SessionContext) error {
+		// Creating timeout context for the database call.
+		callCtx, cancelFunc := getTimeoutContext(sessCtx)
+		defer cancelFunc()
+
+		result, err := collection.DeleteMany(callCtx, bson.M{"transfer_id": transferID})
+		if err != nil {
+			err = fmt.Errorf("mongodb DeleteMany error: %w", err)
+			log.Error(ctx, &logger.Entry{Payload: err})
+			return err
+		}
+
+		if result.DeletedCount == 0 {
+			return errutils.TransactionNotFound()
+		}
+		return nil
+	})
+}
+
+// insertMongoTransactions inserts the transactions with a single InsertMany call, and provides their IDs in the same
+// order. The context should carry the session of a transaction, so a failure midway leaves nothing inserted.
+func insertMongoTransactions(ctx context.Context, transactions []*models.TransactionDTO) ([]string, error) {
+	log := logger.Get()
+
+	collection, err := getTransactionsCollection(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Creating timeout context for the database call.
+	callCtx, cancelFunc := getTimeoutContext(ctx)
+	defer cancelFunc()
+
+	documents := make([]interface{}, len(transactions))
+	for idx, transaction := range transactions {
+		documents[idx] = transaction
+	}
+
+	result, err := collection.InsertMany(callCtx, documents)
+	if err != nil {
+		err = fmt.Errorf("mongodb InsertMany error: %w", err)
+		log.Error(ctx, &logger.Entry{Payload: err})
+		return nil, err
+	}
+
+	// MongoDB generates an ObjectID for every inserted document.
+	ids := make([]string, len(result.InsertedIDs))
+	for idx, insertedID := range result.InsertedIDs {
+		objectID, ok := insertedID.(primitive.ObjectID)
+		if !ok {
+			err := fmt.Errorf("unexpected inserted ID type: %T", insertedID)
+			log.Error(ctx, &logger.Entry{Payload: err})
+			return nil, err
+		}
+		ids[idx] = objectID.Hex()
+	}
+
+	return ids, nil
+}
+
+// mongoTransactionCursor implements TransactionCursor over a MongoDB cursor.
+type mongoTransactionCursor struct {
+	cursor      *mongo.Cursor
+	transaction *models.TransactionDTO
+	err         error
+}
+
+func (m *mongoTransactionCursor) Next(ctx context.Context) bool {
+	if m.err != nil || !m.cursor.Next(ctx) {
+		return false
+	}
+
+	transaction := &models.TransactionDTO{}
+	if err := m.cursor.Decode(transaction); err != nil {
+		m.err = fmt.Errorf("mongodb cursor.Decode error: %w", err)
+		logger.Get().Error(ctx, &logger.Entry{Payload: m.err})
+		return false
+	}
+
+	m.transaction = transaction
+	return true
+}
+
+func (m *mongoTransactionCursor) Transaction() *models.TransactionDTO {
+	return m.transaction
+}
+
+func (m *mongoTransactionCursor) Err() error {
+	if m.err != nil {
+		return m.err
+	}
+	if err := m.cursor.Err(); err != nil {
+		return fmt.Errorf("mongodb cursor error: %w", err)
+	}
+	return nil
+}
+
+func (m *mongoTransactionCursor) Close(ctx context.Context) error {
+	return m.cursor.Close(ctx)
+}
@@ -0,0 +1,116 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/shivanshkc/ledgerkeep/src/logger"
+	"github.com/shivanshkc/ledgerkeep/src/models"
+	"github.com/shivanshkc/ledgerkeep/src/utils/errutils"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// mongoUserRepository implements UserRepository using MongoDB.
+// The users are kept in the configured database, as they do not belong to any tenant.
+type mongoUserRepository struct{}
+
+func (m *mongoUserRepository) InsertUser(ctx context.Context, user *models.UserDTO) error {
+	log := logger.Get()
+
+	// Creating timeout context for the database call.
+	callCtx, cancelFunc := getTimeoutContext(ctx)
+	defer cancelFunc()
+
+	if _, err := getUsersCollection().InsertOne(callCtx, user); err != nil {
+		// Checking if the error is a duplicate key error (already exists error).
+		if mongo.IsDuplicateKeyError(err) {
+			return errutils.UserAlreadyExists()
+		}
+		err = fmt.Errorf("mongodb InsertOne error: %w", err)
+		log.Error(ctx, &logger.Entry{Payload: err})
+		return err
+	}
+
+	return nil
+}
+
+func (m *mongoUserRepository) GetUser(ctx context.Context, userID string) (*models.UserDTO, error) {
+	log := logger.Get()
+
+	// Creating timeout context for the database call.
+	callCtx, cancelFunc := getTimeoutContext(ctx)
+	defer cancelFunc()
+
+	result := getUsersCollection().FindOne(callCtx, bson.M{"_id": userID})
+	if err := result.Err(); err != nil {
+		// Handling the not-exists case.
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, errutils.UserNotFound()
+		}
+
+		err = fmt.Errorf("mongodb FindOne error: %w", err)
+		log.Error(ctx, &logger.Entry{Payload: err})
+		return nil, err
+	}
+
+	var user *models.UserDTO
+	if err := result.Decode(&user); err != nil {
+		err = fmt.Errorf("mongodb Decode error: %w", err)
+		log.Error(ctx, &logger.Entry{Payload: err})
+		return nil, err
+	}
+
+	return user, nil
+}
+
+func (m *mongoUserRepository) ListUsers(ctx context.Context) ([]*models.UserDTO, error) {
+	log := logger.Get()
+
+	// Creating timeout context for the database call.
+	callCtx, cancelFunc := getTimeoutContext(ctx)
+	defer cancelFunc()
+
+	opts := options.Find().SetSort(bson.D{{Key: "_id", Value: 1}})
+	cursor, err := getUsersCollection().Find(callCtx, bson.M{}, opts)
+	if err != nil {
+		err = fmt.Errorf("mongodb Find error: %w", err)
+		log.Error(ctx, &logger.Entry{Payload: err})
+		return nil, err
+	}
+
+	results := []*models.UserDTO{}
+	if err := cursor.All(ctx, &results); err != nil {
+		err = fmt.Errorf("mongodb cursor.All error: %w", err)
+		log.Error(ctx, &logger.Entry{Payload: err})
+		return nil, err
+	}
+
+	return results, nil
+}
+
+func (m *mongoUserRepository) UpdateUser(ctx context.Context, userID string, updates map[string]interface{}) error {
+	log := logger.Get()
+
+	// Creating timeout context for the database call.
+	callCtx, cancelFunc := getTimeoutContext(ctx)
+	defer cancelFunc()
+
+	// Wrapping the updates with $set operator of mongodb.
+	updates = bson.M{"$set": updates}
+
+	result, err := getUsersCollection().UpdateOne(callCtx, bson.M{"_id": userID}, updates)
+	if err != nil {
+		err = fmt.Errorf("mongodb UpdateOne error: %w", err)
+		log.Error(ctx, &logger.Entry{Payload: err})
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return errutils.UserNotFound()
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/shivanshkc/ledgerkeep/src/configs"
+	"github.com/shivanshkc/ledgerkeep/src/logger"
+
+	// Registers the "pgx" driver for database/sql.
+	_ "github.com/jackc/pgx/v4/stdlib"
+)
+
+var (
+	clientOnce      = &sync.Once{}
+	clientSingleton *sql.DB
+)
+
+// GetClient returns the PostgreSQL client singleton.
+func GetClient() *sql.DB {
+	clientOnce.Do(func() {
+		logger.Get().Info(context.Background(), &logger.Entry{Payload: "Attempting connection with PostgreSQL..."})
+		clientSingleton = getClient()
+		logger.Get().Info(context.Background(), &logger.Entry{Payload: "Connected with PostgreSQL."})
+	})
+
+	return clientSingleton
+}
+
+// getClient is a pure function (except for configs) to generate a PostgreSQL client.
+func getClient() *sql.DB {
+	conf := configs.Get()
+	timeoutDuration := time.Duration(conf.Postgres.OperationTimeoutSec) * time.Second
+
+	client, err := Open(conf.Postgres.Addr, timeoutDuration)
+	if err != nil {
+		panic(err)
+	}
+
+	return client
+}
+
+// Open connects with the PostgreSQL database at the provided address, which is a connection URL or DSN.
+func Open(addr string, timeout time.Duration) (*sql.DB, error) {
+	client, err := sql.Open("pgx", addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create postgres client: %w", err)
+	}
+
+	pingCtx, pingCancelFunc := context.WithTimeout(context.Background(), timeout)
+	defer pingCancelFunc()
+
+	if err := client.PingContext(pingCtx); err != nil {
+		return nil, fmt.Errorf("failed to ping postgres: %w", err)
+	}
+
+	return client, nil
+}
@@ -0,0 +1,442 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/shivanshkc/ledgerkeep/src/database/postgres"
+
+	"github.com/jackc/pgconn"
+)
+
+const (
+	// postgresUniqueViolation is the PostgreSQL error code for unique constraint violations.
+	postgresUniqueViolation = "23505"
+	// postgresForeignKeyViolation is the PostgreSQL error code for foreign key constraint violations.
+	postgresForeignKeyViolation = "23503"
+)
+
+// postgresMigrations are the versioned schema changes of the PostgreSQL backend.
+// Applied migrations must never be modified. Schema changes should always be added as new migrations.
+var postgresMigrations = []sqlMigration{
+	{
+		Version:     1,
+		Description: "create accounts and transactions tables",
+		Statements: []string{
+			// The seq column keeps the accounts in their insertion order.
+			`CREATE TABLE accounts (
+				seq  BIGSERIAL NOT NULL,
+				id   TEXT      PRIMARY KEY,
+				name TEXT      NOT NULL
+			)`,
+			// The account_id foreign key makes sure that a transaction always belongs to an existing account,
+			// and that an account cannot be deleted while it has transactions.
+			`CREATE TABLE transactions (
+				id         TEXT             PRIMARY KEY,
+				amount     DOUBLE PRECISION NOT NULL,
+				timestamp  BIGINT           NOT NULL,
+				account_id TEXT             NOT NULL REFERENCES accounts (id) ON UPDATE RESTRICT ON DELETE RESTRICT,
+				category   TEXT             NOT NULL,
+				notes      TEXT             NOT NULL
+			)`,
+			`CREATE INDEX transactions_account_id_idx ON transactions (account_id)`,
+			`CREATE INDEX transactions_timestamp_idx ON transactions (timestamp)`,
+		},
+	},
+	{
+		Version:     2,
+		Description: "add full text search on transaction notes",
+		Statements: []string{
+			`ALTER TABLE transactions
+				ADD COLUMN notes_tsv TSVECTOR GENERATED ALWAYS AS (to_tsvector('english', notes)) STORED`,
+			`CREATE INDEX transactions_notes_tsv_idx ON transactions USING GIN (notes_tsv)`,
+		},
+	},
+	{
+		Version:     3,
+		Description: "store transaction amounts as integer money units",
+		Statements: []string{
+			// There are 10000 money units in one currency unit. Rounding removes the float noise.
+			`ALTER TABLE transactions ALTER COLUMN amount TYPE BIGINT USING ROUND(amount * 10000)`,
+		},
+	},
+	{
+		Version:     4,
+		Description: "add account currencies and exchange rates",
+		Statements: []string{
+			// Accounts created before this migration get an empty currency, which stands for the default currency.
+			`ALTER TABLE accounts ADD COLUMN currency TEXT NOT NULL DEFAULT ''`,
+			// Rates are kept as decimal strings, so they are stored without any loss.
+			`CREATE TABLE exchange_rates (
+				id        TEXT    PRIMARY KEY,
+				base      TEXT    NOT NULL,
+				quote     TEXT    NOT NULL,
+				rate      TEXT    NOT NULL,
+				timestamp BIGINT  NOT NULL,
+				UNIQUE (base, quote, timestamp)
+			)`,
+		},
+	},
+	{
+		Version:     5,
+		Description: "link the transactions of transfers",
+		Statements: []string{
+			// Transactions that are not legs of a transfer have an empty transfer ID.
+			`ALTER TABLE transactions ADD COLUMN transfer_id TEXT NOT NULL DEFAULT ''`,
+			`CREATE INDEX transactions_transfer_id_idx ON transactions (transfer_id)`,
+		},
+	},
+	{
+		Version:     6,
+		Description: "add split lines of transactions",
+		Statements: []string{
+			// The position keeps the split lines in their original order.
+			`CREATE TABLE transaction_splits (
+				transaction_id TEXT    NOT NULL REFERENCES transactions (id) ON DELETE CASCADE,
+				position       INTEGER NOT NULL,
+				amount         BIGINT  NOT NULL,
+				category       TEXT    NOT NULL,
+				notes          TEXT    NOT NULL,
+				PRIMARY KEY (transaction_id, position)
+			)`,
+		},
+	},
+	{
+		Version:     7,
+		Description: "create categories table",
+		Statements: []string{
+			// The default categories are created by the application, as they are the same for all backends.
+			`CREATE TABLE categories (
+				id           TEXT PRIMARY KEY,
+				name         TEXT NOT NULL,
+				kind         TEXT NOT NULL,
+				budget_group TEXT NOT NULL
+			)`,
+		},
+	},
+	{
+		Version:     8,
+		Description: "create budget plans tables",
+		Statements: []string{
+			`CREATE TABLE budget_plans (
+				id          TEXT    PRIMARY KEY,
+				name        TEXT    NOT NULL,
+				currency    TEXT    NOT NULL,
+				valid_from  BIGINT  NOT NULL,
+				valid_until BIGINT  NOT NULL
+			)`,
+			// Exactly one of percent and amount is set for every allocation.
+			`CREATE TABLE budget_plan_allocations (
+				plan_id      TEXT    NOT NULL REFERENCES budget_plans (id) ON DELETE CASCADE,
+				position     INTEGER NOT NULL,
+				budget_group TEXT    NOT NULL,
+				percent      BIGINT,
+				amount       BIGINT,
+				PRIMARY KEY (plan_id, position)
+			)`,
+		},
+	},
+	{
+		Version:     9,
+		Description: "create recurring templates table",
+		Statements: []string{
+			`CREATE TABLE recurring_templates (
+				id         TEXT    PRIMARY KEY,
+				name       TEXT    NOT NULL,
+				amount     BIGINT  NOT NULL,
+				account_id TEXT    NOT NULL,
+				category   TEXT    NOT NULL,
+				notes      TEXT    NOT NULL,
+				rule       TEXT    NOT NULL,
+				start_time BIGINT  NOT NULL,
+				end_time   BIGINT  NOT NULL,
+				next_run   BIGINT  NOT NULL
+			)`,
+		},
+	},
+	{
+		Version:     10,
+		Description: "create import profiles table",
+		Statements: []string{
+			// Optional columns of a profile are stored as empty strings.
+			`CREATE TABLE import_profiles (
+				id                TEXT PRIMARY KEY,
+				name              TEXT NOT NULL,
+				account_id        TEXT NOT NULL,
+				delimiter         TEXT NOT NULL,
+				decimal_separator TEXT NOT NULL,
+				date_column       TEXT NOT NULL,
+				date_format       TEXT NOT NULL,
+				amount_column     TEXT NOT NULL,
+				debit_column      TEXT NOT NULL,
+				credit_column     TEXT NOT NULL,
+				notes_column      TEXT NOT NULL,
+				category_column   TEXT NOT NULL,
+				credit_category   TEXT NOT NULL,
+				debit_category    TEXT NOT NULL
+			)`,
+		},
+	},
+	{
+		Version:     11,
+		Description: "add external IDs of imported transactions",
+		Statements: []string{
+			// Transactions that are not imported from bank statements have an empty external ID.
+			`ALTER TABLE transactions ADD COLUMN external_id TEXT NOT NULL DEFAULT ''`,
+			`CREATE INDEX transactions_external_id_idx ON transactions (account_id, external_id)`,
+		},
+	},
+	{
+		Version:     12,
+		Description: "add transaction tags and categorization rules",
+		Statements: []string{
+			// Tags are stored as a JSON array, which is an empty string for the transactions without tags.
+			`ALTER TABLE transactions ADD COLUMN tags TEXT NOT NULL DEFAULT ''`,
+			// Absent amount bounds are NULL. The weekdays are comma separated, and the tags are stored like above.
+			`CREATE TABLE rules (
+				id            TEXT    PRIMARY KEY,
+				name          TEXT    NOT NULL,
+				priority      BIGINT  NOT NULL,
+				notes_pattern TEXT    NOT NULL,
+				min_amount    BIGINT ,
+				max_amount    BIGINT ,
+				account_id    TEXT    NOT NULL,
+				weekdays      TEXT    NOT NULL,
+				set_category  TEXT    NOT NULL,
+				set_notes     TEXT    NOT NULL,
+				add_tags      TEXT    NOT NULL
+			)`,
+		},
+	},
+	{
+		Version:     13,
+		Description: "add users and the tenants of the ledger data",
+		Statements: []string{
+			`CREATE TABLE users (
+				id            TEXT    PRIMARY KEY,
+				password_hash TEXT    NOT NULL,
+				is_admin      BOOLEAN NOT NULL,
+				tenant_id     TEXT    NOT NULL,
+				created_at    BIGINT  NOT NULL
+			)`,
+			// The existing data belongs to the "default" tenant, which is the tenant of the admin user of the configs.
+			// The defaults are dropped afterwards, so a row can never be created without a tenant.
+			`ALTER TABLE accounts ADD COLUMN tenant_id TEXT NOT NULL DEFAULT 'default'`,
+			`ALTER TABLE transactions ADD COLUMN tenant_id TEXT NOT NULL DEFAULT 'default'`,
+			`ALTER TABLE categories ADD COLUMN tenant_id TEXT NOT NULL DEFAULT 'default'`,
+			`ALTER TABLE exchange_rates ADD COLUMN tenant_id TEXT NOT NULL DEFAULT 'default'`,
+			`ALTER TABLE budget_plans ADD COLUMN tenant_id TEXT NOT NULL DEFAULT 'default'`,
+			`ALTER TABLE recurring_templates ADD COLUMN tenant_id TEXT NOT NULL DEFAULT 'default'`,
+			`ALTER TABLE import_profiles ADD COLUMN tenant_id TEXT NOT NULL DEFAULT 'default'`,
+			`ALTER TABLE rules ADD COLUMN tenant_id TEXT NOT NULL DEFAULT 'default'`,
+			`ALTER TABLE accounts ALTER COLUMN tenant_id DROP DEFAULT`,
+			`ALTER TABLE transactions ALTER COLUMN tenant_id DROP DEFAULT`,
+			`ALTER TABLE categories ALTER COLUMN tenant_id DROP DEFAULT`,
+			`ALTER TABLE exchange_rates ALTER COLUMN tenant_id DROP DEFAULT`,
+			`ALTER TABLE budget_plans ALTER COLUMN tenant_id DROP DEFAULT`,
+			`ALTER TABLE recurring_templates ALTER COLUMN tenant_id DROP DEFAULT`,
+			`ALTER TABLE import_profiles ALTER COLUMN tenant_id DROP DEFAULT`,
+			`ALTER TABLE rules ALTER COLUMN tenant_id DROP DEFAULT`,
+			// The IDs of the accounts and the categories are chosen by the users, so they are unique per tenant.
+			// A transaction can only belong to an account of its own tenant.
+			`ALTER TABLE transactions DROP CONSTRAINT transactions_account_id_fkey`,
+			`ALTER TABLE accounts DROP CONSTRAINT accounts_pkey`,
+			`ALTER TABLE accounts ADD PRIMARY KEY (tenant_id, id)`,
+			`ALTER TABLE transactions ADD FOREIGN KEY (tenant_id, account_id) REFERENCES accounts (tenant_id, id)
+				ON UPDATE RESTRICT ON DELETE RESTRICT`,
+			`ALTER TABLE categories DROP CONSTRAINT categories_pkey`,
+			`ALTER TABLE categories ADD PRIMARY KEY (tenant_id, id)`,
+			`ALTER TABLE exchange_rates DROP CONSTRAINT exchange_rates_base_quote_timestamp_key`,
+			`ALTER TABLE exchange_rates ADD UNIQUE (tenant_id, base, quote, timestamp)`,
+			`CREATE INDEX transactions_tenant_id_idx ON transactions (tenant_id, timestamp)`,
+		},
+	},
+	{
+		Version:     14,
+		Description: "create ledger members table",
+		Statements: []string{
+			`CREATE TABLE ledger_members (
+				ledger_id  TEXT   NOT NULL,
+				user_id    TEXT   NOT NULL REFERENCES users (id),
+				role       TEXT   NOT NULL,
+				created_at BIGINT NOT NULL,
+				PRIMARY KEY (ledger_id, user_id)
+			)`,
+			`CREATE INDEX ledger_members_user_id_idx ON ledger_members (user_id)`,
+		},
+	},
+	{
+		Version:     15,
+		Description: "create api tokens table",
+		Statements: []string{
+			`CREATE TABLE api_tokens (
+				id           TEXT   PRIMARY KEY,
+				user_id      TEXT   NOT NULL REFERENCES users (id),
+				name         TEXT   NOT NULL,
+				scopes       TEXT   NOT NULL,
+				token_hash   TEXT   NOT NULL UNIQUE,
+				expires_at   BIGINT NOT NULL,
+				last_used_at BIGINT NOT NULL,
+				created_at   BIGINT NOT NULL
+			)`,
+			`CREATE INDEX api_tokens_user_id_idx ON api_tokens (user_id)`,
+		},
+	},
+	{
+		Version:     16,
+		Description: "scope the ledger data IDs to their tenants",
+		Statements: []string{
+			// A restored backup keeps its IDs, so the same IDs may exist in many tenants. The split lines and the
+			// allocations get the tenants of their transactions and plans, so they can refer to them.
+			`ALTER TABLE transaction_splits DROP CONSTRAINT transaction_splits_transaction_id_fkey`,
+			`ALTER TABLE transaction_splits ADD COLUMN tenant_id TEXT`,
+			`UPDATE transaction_splits SET tenant_id = transactions.tenant_id FROM transactions
+				WHERE transactions.id = transaction_splits.transaction_id`,
+			`ALTER TABLE transaction_splits ALTER COLUMN tenant_id SET NOT NULL`,
+			`ALTER TABLE transaction_splits DROP CONSTRAINT transaction_splits_pkey`,
+			`ALTER TABLE transaction_splits ADD PRIMARY KEY (tenant_id, transaction_id, position)`,
+			`ALTER TABLE transactions DROP CONSTRAINT transactions_pkey`,
+			`ALTER TABLE transactions ADD PRIMARY KEY (tenant_id, id)`,
+			`ALTER TABLE transaction_splits ADD FOREIGN KEY (tenant_id, transaction_id)
+				REFERENCES transactions (tenant_id, id) ON DELETE CASCADE`,
+			`ALTER TABLE budget_plan_allocations DROP CONSTRAINT budget_plan_allocations_plan_id_fkey`,
+			`ALTER TABLE budget_plan_allocations ADD COLUMN tenant_id TEXT`,
+			`UPDATE budget_plan_allocations SET tenant_id = budget_plans.tenant_id FROM budget_plans
+				WHERE budget_plans.id = budget_plan_allocations.plan_id`,
+			`ALTER TABLE budget_plan_allocations ALTER COLUMN tenant_id SET NOT NULL`,
+			`ALTER TABLE budget_plan_allocations DROP CONSTRAINT budget_plan_allocations_pkey`,
+			`ALTER TABLE budget_plan_allocations ADD PRIMARY KEY (tenant_id, plan_id, position)`,
+			`ALTER TABLE budget_plans DROP CONSTRAINT budget_plans_pkey`,
+			`ALTER TABLE budget_plans ADD PRIMARY KEY (tenant_id, id)`,
+			`ALTER TABLE budget_plan_allocations ADD FOREIGN KEY (tenant_id, plan_id)
+				REFERENCES budget_plans (tenant_id, id) ON DELETE CASCADE`,
+			`ALTER TABLE exchange_rates DROP CONSTRAINT exchange_rates_pkey`,
+			`ALTER TABLE exchange_rates ADD PRIMARY KEY (tenant_id, id)`,
+			`ALTER TABLE recurring_templates DROP CONSTRAINT recurring_templates_pkey`,
+			`ALTER TABLE recurring_templates ADD PRIMARY KEY (tenant_id, id)`,
+			`ALTER TABLE import_profiles DROP CONSTRAINT import_profiles_pkey`,
+			`ALTER TABLE import_profiles ADD PRIMARY KEY (tenant_id, id)`,
+			`ALTER TABLE rules DROP CONSTRAINT rules_pkey`,
+			`ALTER TABLE rules ADD PRIMARY KEY (tenant_id, id)`,
+		},
+	},
+}
+
+// newPostgresRepositories provides the Repositories backed by the configured PostgreSQL database.
+// Panic is allowed here because storage is crucial to the application.
+func newPostgresRepositories() *Repositories {
+	repos, err := newPostgresRepositoriesWithDB(context.Background(), postgres.GetClient())
+	if err != nil {
+		panic(err)
+	}
+	return repos
+}
+
+// newPostgresRepositoriesWithDB migrates the provided PostgreSQL database and provides the Repositories backed by it.
+func newPostgresRepositoriesWithDB(ctx context.Context, db *sql.DB) (*Repositories, error) {
+	if err := runSQLMigrations(ctx, db, postgresMigrations); err != nil {
+		return nil, fmt.Errorf("failed to migrate postgres database: %w", err)
+	}
+
+	dialect := &postgresDialect{}
+
+	repos := &Repositories{
+		Accounts:      &sqlAccountRepository{db: db, dialect: dialect},
+		Transactions:  &sqlTransactionRepository{db: db, dialect: dialect},
+		Categories:    &sqlCategoryRepository{db: db, dialect: dialect},
+		ExchangeRates: &sqlExchangeRateRepository{db: db, dialect: dialect},
+		BudgetPlans:   &sqlBudgetPlanRepository{db: db, dialect: dialect},
+		Recurring:     &sqlRecurringTemplateRepository{db: db, dialect: dialect},
+		Imports:       &sqlImportProfileRepository{db: db, dialect: dialect},
+		Rules:         &sqlRuleRepository{db: db, dialect: dialect},
+		Backups:       &sqlBackupRepository{db: db, dialect: dialect},
+		Users:         &sqlUserRepository{db: db, dialect: dialect},
+		Members:       &sqlMemberRepository{db: db, dialect: dialect},
+		Tokens:        &sqlTokenRepository{db: db, dialect: dialect},
+	}
+
+	if err := seedAdminUser(ctx, repos); err != nil {
+		return nil, fmt.Errorf("failed to seed postgres admin user: %w", err)
+	}
+
+	return repos, nil
+}
+
+// postgresDialect implements sqlDialect for PostgreSQL.
+type postgresDialect struct{}
+
+func (p *postgresDialect) name() string {
+	return "postgres"
+}
+
+func (p *postgresDialect) rebind(query string) string {
+	return rebindNumbered(query)
+}
+
+// textSearchClause translates a parsed $text search into a tsquery on the transaction notes.
+// The tsqueries are combined in SQL, so the user input never has to be escaped as tsquery syntax.
+func (p *postgresDialect) textSearchClause(search *textSearch) (string, []interface{}) {
+	var positives []string
+	var args []interface{}
+
+	// If there are phrases, all of them must be present and the terms are irrelevant.
+	if len(search.Phrases) > 0 {
+		for _, phrase := range search.Phrases {
+			positives = append(positives, "phraseto_tsquery('english', ?)")
+			args = append(args, phrase)
+		}
+	} else {
+		for _, term := range search.Terms {
+			positives = append(positives, "plainto_tsquery('english', ?)")
+			args = append(args, term)
+		}
+	}
+
+	// Without any positive terms, nothing can match, just like in MongoDB.
+	if len(positives) == 0 {
+		return "FALSE", nil
+	}
+
+	separator := " || "
+	if len(search.Phrases) > 0 {
+		separator = " && "
+	}
+	query := strings.Join(positives, separator)
+
+	if len(search.NegatedTerms) > 0 {
+		negatives := make([]string, len(search.NegatedTerms))
+		for idx, term := range search.NegatedTerms {
+			negatives[idx] = "plainto_tsquery('english', ?)"
+			args = append(args, term)
+		}
+		query = fmt.Sprintf("(%s) && !!(%s)", query, strings.Join(negatives, " || "))
+	}
+
+	return fmt.Sprintf("notes_tsv @@ (%s)", query), args
+}
+
+func (p *postgresDialect) noLimit() interface{} {
+	// PostgreSQL treats a NULL limit as no limit.
+	return nil
+}
+
+func (p *postgresDialect) accountsOrderColumn() string {
+	return "seq"
+}
+
+func (p *postgresDialect) isUniqueViolation(err error) bool {
+	return isPostgresError(err, postgresUniqueViolation)
+}
+
+func (p *postgresDialect) isForeignKeyViolation(err error) bool {
+	return isPostgresError(err, postgresForeignKeyViolation)
+}
+
+// isPostgresError checks if the error is a PostgreSQL error with the provided code.
+func isPostgresError(err error, code string) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == code
+}
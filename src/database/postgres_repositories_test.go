@@ -0,0 +1,123 @@
+package database
+
+import (
+	"database/sql"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/shivanshkc/ledgerkeep/src/database/postgres"
+	"github.com/shivanshkc/ledgerkeep/src/models"
+	"github.com/shivanshkc/ledgerkeep/src/utils/errutils"
+)
+
+// postgresTestAddrEnv is the environment variable that holds the address of a disposable PostgreSQL test database.
+// All ledgerkeep tables in that database are dropped by the tests.
+const postgresTestAddrEnv = "LEDGERKEEP_TEST_POSTGRES_ADDR"
+
+func TestPostgresForeignKeys(t *testing.T) {
+	db := openPostgresTestDB(t)
+	defer func() { _ = db.Close() }()
+
+	ctx := tenantContext(DefaultTenantID)
+	repos, err := newPostgresRepositoriesWithDB(ctx, db)
+	if err != nil {
+		t.Fatalf("failed to create postgres repositories: %+v", err)
+	}
+
+	// The constraint violations must surface as the same errors that the handlers return.
+	tx := &models.TransactionDTO{Amount: -10, Timestamp: 1, AccountID: "missing", Category: "luxury"}
+	if _, err := repos.Transactions.InsertTransaction(ctx, tx); !isHTTPError(err, errutils.AccountNotFound()) {
+		t.Fatalf("expected ACCOUNT_NOT_FOUND, got: %+v", err)
+	}
+
+	if err := repos.Accounts.InsertAccount(ctx, &models.AccountDTO{ID: "acc"}); err != nil {
+		t.Fatalf("unexpected error in InsertAccount: %+v", err)
+	}
+	if err := repos.Accounts.InsertAccount(ctx, &models.AccountDTO{ID: "acc"}); !isHTTPError(err, errutils.AccountAlreadyExists()) {
+		t.Fatalf("expected ACCOUNT_ALREADY_EXISTS, got: %+v", err)
+	}
+
+	tx.AccountID = "acc"
+	id, err := repos.Transactions.InsertTransaction(ctx, tx)
+	if err != nil {
+		t.Fatalf("unexpected error in InsertTransaction: %+v", err)
+	}
+
+	err = repos.Transactions.UpdateTransaction(ctx, id, map[string]interface{}{"account_id": "missing"})
+	if !isHTTPError(err, errutils.AccountNotFound()) {
+		t.Fatalf("expected ACCOUNT_NOT_FOUND, got: %+v", err)
+	}
+	if err := repos.Accounts.DeleteAccount(ctx, "acc"); !isHTTPError(err, errutils.AccountIsInUse()) {
+		t.Fatalf("expected ACCOUNT_IS_IN_USE, got: %+v", err)
+	}
+}
+
+func TestPostgresTextSearchClause(t *testing.T) {
+	testCases := []struct {
+		search         string
+		expectedClause string
+		expectedArgs   []interface{}
+	}{
+		{
+			search:         "rent salary",
+			expectedClause: "notes_tsv @@ (plainto_tsquery('english', ?) || plainto_tsquery('english', ?))",
+			expectedArgs:   []interface{}{"rent", "salary"},
+		},
+		{
+			search:         `"monthly rent" salary`,
+			expectedClause: "notes_tsv @@ (phraseto_tsquery('english', ?))",
+			expectedArgs:   []interface{}{"monthly rent"},
+		},
+		{
+			search:         "rent -deposit",
+			expectedClause: "notes_tsv @@ ((plainto_tsquery('english', ?)) && !!(plainto_tsquery('english', ?)))",
+			expectedArgs:   []interface{}{"rent", "deposit"},
+		},
+		// Without positive terms, nothing can match.
+		{search: "-deposit", expectedClause: "FALSE", expectedArgs: nil},
+	}
+
+	for _, testCase := range testCases {
+		clause, args := (&postgresDialect{}).textSearchClause(parseTextSearch(testCase.search))
+		if clause != testCase.expectedClause || !reflect.DeepEqual(args, testCase.expectedArgs) {
+			t.Errorf("search %q: expected %q %+v, got %q %+v",
+				testCase.search, testCase.expectedClause, testCase.expectedArgs, clause, args)
+		}
+	}
+}
+
+func TestRebindNumbered(t *testing.T) {
+	query := "SELECT id FROM transactions WHERE amount > ? AND account_id = ? LIMIT ?"
+	expected := "SELECT id FROM transactions WHERE amount > $1 AND account_id = $2 LIMIT $3"
+
+	if rebound := rebindNumbered(query); rebound != expected {
+		t.Fatalf("expected %q, got %q", expected, rebound)
+	}
+}
+
+// openPostgresTestDB connects with the test database and drops all ledgerkeep tables from it.
+// The test is skipped if no test database is configured.
+func openPostgresTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+
+	addr := os.Getenv(postgresTestAddrEnv)
+	if addr == "" {
+		t.Skipf("%s is not set", postgresTestAddrEnv)
+	}
+
+	db, err := postgres.Open(addr, 10*time.Second)
+	if err != nil {
+		t.Fatalf("failed to open postgres database: %+v", err)
+	}
+
+	if _, err := db.Exec(`DROP TABLE IF EXISTS transaction_splits, transactions, accounts, exchange_rates, categories,
+		budget_plan_allocations, budget_plans, recurring_templates, import_profiles, rules, api_tokens, ledger_members,
+		users, schema_migrations`); err != nil {
+		_ = db.Close()
+		t.Fatalf("failed to reset postgres database: %+v", err)
+	}
+
+	return db
+}
@@ -0,0 +1,1336 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"math"
+	"path/filepath"
+	"testing"
+
+	"github.com/shivanshkc/ledgerkeep/src/database/sqlite"
+	"github.com/shivanshkc/ledgerkeep/src/models"
+	"github.com/shivanshkc/ledgerkeep/src/utils/ctxutils"
+	"github.com/shivanshkc/ledgerkeep/src/utils/errutils"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestAccountRepository(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, repos *Repositories) {
+		ctx := tenantContext(DefaultTenantID)
+
+		if err := repos.Accounts.InsertAccount(ctx, &models.AccountDTO{ID: "bank", Name: "Bank", Currency: "EUR"}); err != nil {
+			t.Fatalf("unexpected error in InsertAccount: %+v", err)
+		}
+
+		// Duplicate account IDs must conflict.
+		err := repos.Accounts.InsertAccount(ctx, &models.AccountDTO{ID: "bank", Name: "Other"})
+		if !isHTTPError(err, errutils.AccountAlreadyExists()) {
+			t.Fatalf("expected ACCOUNT_ALREADY_EXISTS, got: %+v", err)
+		}
+
+		if err := repos.Accounts.UpdateAccount(ctx, "bank", map[string]interface{}{"name": "My Bank"}); err != nil {
+			t.Fatalf("unexpected error in UpdateAccount: %+v", err)
+		}
+		if err := repos.Accounts.UpdateAccount(ctx, "cash", map[string]interface{}{"name": "Cash"}); !isHTTPError(err, errutils.AccountNotFound()) {
+			t.Fatalf("expected ACCOUNT_NOT_FOUND, got: %+v", err)
+		}
+
+		accounts, err := repos.Accounts.ListAccounts(ctx)
+		if err != nil {
+			t.Fatalf("unexpected error in ListAccounts: %+v", err)
+		}
+		if len(accounts) != 1 || accounts[0].Name != "My Bank" || accounts[0].Currency != "EUR" {
+			t.Fatalf("unexpected accounts: %+v", accounts)
+		}
+
+		account, err := repos.Accounts.GetAccount(ctx, "bank")
+		if err != nil {
+			t.Fatalf("unexpected error in GetAccount: %+v", err)
+		}
+		if account.Name != "My Bank" || account.Currency != "EUR" {
+			t.Fatalf("unexpected account: %+v", account)
+		}
+		if _, err := repos.Accounts.GetAccount(ctx, "cash"); !isHTTPError(err, errutils.AccountNotFound()) {
+			t.Fatalf("expected ACCOUNT_NOT_FOUND, got: %+v", err)
+		}
+
+		// An account with a transaction is in use.
+		txID, err := repos.Transactions.InsertTransaction(ctx, &models.TransactionDTO{Amount: 10, AccountID: "bank"})
+		if err != nil {
+			t.Fatalf("unexpected error in InsertTransaction: %+v", err)
+		}
+		if isUsed, _ := repos.Accounts.IsAccountUsed(ctx, "bank"); !isUsed {
+			t.Fatalf("expected account to be in use")
+		}
+		if err := repos.Transactions.DeleteTransaction(ctx, txID); err != nil {
+			t.Fatalf("unexpected error in DeleteTransaction: %+v", err)
+		}
+
+		if err := repos.Accounts.DeleteAccount(ctx, "bank"); err != nil {
+			t.Fatalf("unexpected error in DeleteAccount: %+v", err)
+		}
+		if exists, _ := repos.Accounts.IsAccountExists(ctx, "bank"); exists {
+			t.Fatalf("expected account to be deleted")
+		}
+	})
+}
+
+func TestGetAccountBalances(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, repos *Repositories) {
+		ctx := tenantContext(DefaultTenantID)
+		insertTestAccounts(t, repos, "bank", "cash")
+
+		for _, tx := range []*models.TransactionDTO{
+			{Amount: 100, AccountID: "bank"},
+			{Amount: -30, AccountID: "bank"},
+			{Amount: 5, AccountID: "cash"},
+		} {
+			if _, err := repos.Transactions.InsertTransaction(ctx, tx); err != nil {
+				t.Fatalf("unexpected error in InsertTransaction: %+v", err)
+			}
+		}
+
+		balances, err := repos.Accounts.GetAccountBalances(ctx)
+		if err != nil {
+			t.Fatalf("unexpected error in GetAccountBalances: %+v", err)
+		}
+		if balances["bank"] != 70 || balances["cash"] != 5 || len(balances) != 2 {
+			t.Fatalf("unexpected balances: %+v", balances)
+		}
+	})
+}
+
+func TestTransactionRepository(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, repos *Repositories) {
+		ctx := tenantContext(DefaultTenantID)
+		insertTestAccounts(t, repos, "bank")
+
+		id, err := repos.Transactions.InsertTransaction(ctx, &models.TransactionDTO{Amount: -10, AccountID: "bank", Category: "luxury"})
+		if err != nil {
+			t.Fatalf("unexpected error in InsertTransaction: %+v", err)
+		}
+		if !primitive.IsValidObjectID(id) {
+			t.Fatalf("expected an ObjectID, got: %s", id)
+		}
+
+		if err := repos.Transactions.UpdateTransaction(ctx, id, map[string]interface{}{"amount": models.Money(-205000), "timestamp": int64(42)}); err != nil {
+			t.Fatalf("unexpected error in UpdateTransaction: %+v", err)
+		}
+
+		transaction, err := repos.Transactions.GetTransaction(ctx, id)
+		if err != nil {
+			t.Fatalf("unexpected error in GetTransaction: %+v", err)
+		}
+		if transaction.Amount != -205000 || transaction.Timestamp != 42 || transaction.Category != "luxury" {
+			t.Fatalf("unexpected transaction: %+v", transaction)
+		}
+
+		if err := repos.Transactions.DeleteTransaction(ctx, id); err != nil {
+			t.Fatalf("unexpected error in DeleteTransaction: %+v", err)
+		}
+		if _, err := repos.Transactions.GetTransaction(ctx, id); !isHTTPError(err, errutils.TransactionNotFound()) {
+			t.Fatalf("expected TRANSACTION_NOT_FOUND, got: %+v", err)
+		}
+	})
+}
+
+func TestTransferRepository(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, repos *Repositories) {
+		ctx := tenantContext(DefaultTenantID)
+		insertTestAccounts(t, repos, "bank", "wallet")
+
+		transferID := primitive.NewObjectID().Hex()
+		ids, err := repos.Transactions.InsertTransfer(ctx, []*models.TransactionDTO{
+			{Amount: -10, AccountID: "bank", Category: "transfer", TransferID: transferID},
+			{Amount: 10, AccountID: "wallet", Category: "transfer", TransferID: transferID},
+		})
+		if err != nil {
+			t.Fatalf("unexpected error in InsertTransfer: %+v", err)
+		}
+		if len(ids) != 2 || !primitive.IsValidObjectID(ids[0]) || !primitive.IsValidObjectID(ids[1]) {
+			t.Fatalf("expected two ObjectIDs, got: %+v", ids)
+		}
+
+		// The legs can be found through the transfer ID.
+		legs, _, err := repos.Transactions.ListTransactions(ctx, &ListTransactionsParams{
+			Filter: map[string]interface{}{"transfer_id": transferID}, SortField: "amount", SortOrder: 1, ExcludeCount: true,
+		})
+		if err != nil {
+			t.Fatalf("unexpected error in ListTransactions: %+v", err)
+		}
+		if len(legs) != 2 || legs[0].ID != ids[0] || legs[1].ID != ids[1] || legs[1].TransferID != transferID {
+			t.Fatalf("unexpected legs: %+v", legs)
+		}
+
+		// A missing transaction fails all the updates.
+		err = repos.Transactions.UpdateTransactions(ctx, map[string]map[string]interface{}{
+			ids[0]:                        {"amount": models.Money(-20)},
+			primitive.NewObjectID().Hex(): {"amount": models.Money(20)},
+		})
+		if !isHTTPError(err, errutils.TransactionNotFound()) {
+			t.Fatalf("expected TRANSACTION_NOT_FOUND, got: %+v", err)
+		}
+		if leg, err := repos.Transactions.GetTransaction(ctx, ids[0]); err != nil || leg.Amount != -10 {
+			t.Fatalf("expected the leg to be unchanged, got: %+v, %+v", leg, err)
+		}
+
+		err = repos.Transactions.UpdateTransactions(ctx, map[string]map[string]interface{}{
+			ids[0]: {"amount": models.Money(-30), "notes": "moved"},
+			ids[1]: {"amount": models.Money(30), "notes": "moved"},
+		})
+		if err != nil {
+			t.Fatalf("unexpected error in UpdateTransactions: %+v", err)
+		}
+
+		balances, err := repos.Accounts.GetAccountBalances(ctx)
+		if err != nil {
+			t.Fatalf("unexpected error in GetAccountBalances: %+v", err)
+		}
+		if balances["bank"] != -30 || balances["wallet"] != 30 {
+			t.Fatalf("unexpected balances: %+v", balances)
+		}
+
+		if err := repos.Transactions.DeleteTransfer(ctx, transferID); err != nil {
+			t.Fatalf("unexpected error in DeleteTransfer: %+v", err)
+		}
+		for _, id := range ids {
+			if _, err := repos.Transactions.GetTransaction(ctx, id); !isHTTPError(err, errutils.TransactionNotFound()) {
+				t.Fatalf("expected TRANSACTION_NOT_FOUND, got: %+v", err)
+			}
+		}
+
+		if err := repos.Transactions.DeleteTransfer(ctx, transferID); !isHTTPError(err, errutils.TransactionNotFound()) {
+			t.Fatalf("expected TRANSACTION_NOT_FOUND, got: %+v", err)
+		}
+	})
+}
+
+func TestSplitTransactions(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, repos *Repositories) {
+		ctx := tenantContext(DefaultTenantID)
+		insertTestAccounts(t, repos, "bank")
+
+		id, err := repos.Transactions.InsertTransaction(ctx, &models.TransactionDTO{
+			Amount: -100, AccountID: "bank", Category: "split",
+			Splits: []*models.SplitDTO{
+				{Amount: -70, Category: "essentials", Notes: "Groceries"},
+				{Amount: -30, Category: "luxury", Notes: "Chocolate"},
+			},
+		})
+		if err != nil {
+			t.Fatalf("unexpected error in InsertTransaction: %+v", err)
+		}
+		if _, err := repos.Transactions.InsertTransaction(ctx, &models.TransactionDTO{
+			Amount: -5, AccountID: "bank", Category: "luxury",
+		}); err != nil {
+			t.Fatalf("unexpected error in InsertTransaction: %+v", err)
+		}
+
+		transaction, err := repos.Transactions.GetTransaction(ctx, id)
+		if err != nil {
+			t.Fatalf("unexpected error in GetTransaction: %+v", err)
+		}
+		if len(transaction.Splits) != 2 || *transaction.Splits[0] != (models.SplitDTO{Amount: -70, Category: "essentials", Notes: "Groceries"}) {
+			t.Fatalf("unexpected splits: %+v", transaction.Splits)
+		}
+
+		// Every split line counts in its own category.
+		totals, err := repos.Transactions.GetCategoryTotals(ctx, nil)
+		if err != nil {
+			t.Fatalf("unexpected error in GetCategoryTotals: %+v", err)
+		}
+		debits := map[string]models.Money{}
+		for _, total := range totals {
+			debits[total.Category] = total.Debit
+		}
+		if len(debits) != 2 || debits["essentials"] != -70 || debits["luxury"] != -35 {
+			t.Fatalf("unexpected totals: %+v", debits)
+		}
+
+		// Updating only the split lines replaces them.
+		err = repos.Transactions.UpdateTransaction(ctx, id, map[string]interface{}{"splits": []*models.SplitDTO{
+			{Amount: -40, Category: "essentials"}, {Amount: -60, Category: "investments"},
+		}})
+		if err != nil {
+			t.Fatalf("unexpected error in UpdateTransaction: %+v", err)
+		}
+
+		transactions, _, err := repos.Transactions.ListTransactions(ctx, &ListTransactionsParams{
+			Filter: map[string]interface{}{"_id": id}, SortField: "timestamp", SortOrder: 1,
+		})
+		if err != nil {
+			t.Fatalf("unexpected error in ListTransactions: %+v", err)
+		}
+		if len(transactions) != 1 || len(transactions[0].Splits) != 2 || transactions[0].Splits[1].Category != "investments" {
+			t.Fatalf("unexpected transactions: %+v", transactions)
+		}
+
+		// An empty list removes the split lines.
+		err = repos.Transactions.UpdateTransaction(ctx, id, map[string]interface{}{
+			"category": "essentials", "splits": []*models.SplitDTO{},
+		})
+		if err != nil {
+			t.Fatalf("unexpected error in UpdateTransaction: %+v", err)
+		}
+
+		totals, err = repos.Transactions.GetCategoryTotals(ctx, nil)
+		if err != nil {
+			t.Fatalf("unexpected error in GetCategoryTotals: %+v", err)
+		}
+		debits = map[string]models.Money{}
+		for _, total := range totals {
+			debits[total.Category] = total.Debit
+		}
+		if len(debits) != 2 || debits["essentials"] != -100 || debits["luxury"] != -5 {
+			t.Fatalf("unexpected totals: %+v", debits)
+		}
+
+		if err := repos.Transactions.UpdateTransaction(ctx, primitive.NewObjectID().Hex(), map[string]interface{}{
+			"splits": []*models.SplitDTO{},
+		}); !isHTTPError(err, errutils.TransactionNotFound()) {
+			t.Fatalf("expected TRANSACTION_NOT_FOUND, got: %+v", err)
+		}
+
+		if err := repos.Transactions.DeleteTransaction(ctx, id); err != nil {
+			t.Fatalf("unexpected error in DeleteTransaction: %+v", err)
+		}
+	})
+}
+
+func TestCategoryRepository(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, repos *Repositories) {
+		ctx := tenantContext(DefaultTenantID)
+		insertTestAccounts(t, repos, "bank")
+
+		// Every backend starts with the default categories.
+		categories, err := repos.Categories.ListCategories(ctx)
+		if err != nil {
+			t.Fatalf("unexpected error in ListCategories: %+v", err)
+		}
+		if len(categories) != len(getDefaultCategories()) || categories[0].ID != "earnings" {
+			t.Fatalf("unexpected categories: %+v", categories)
+		}
+
+		category := &models.CategoryDTO{
+			ID: "rent", Name: "Rent", Kind: models.CategoryKindDebit, BudgetGroup: models.BudgetGroupEssentials,
+		}
+		if err := repos.Categories.InsertCategory(ctx, category); err != nil {
+			t.Fatalf("unexpected error in InsertCategory: %+v", err)
+		}
+		if err := repos.Categories.InsertCategory(ctx, category); !isHTTPError(err, errutils.CategoryAlreadyExists()) {
+			t.Fatalf("expected CATEGORY_ALREADY_EXISTS, got: %+v", err)
+		}
+
+		err = repos.Categories.UpdateCategory(ctx, "rent", map[string]interface{}{"kind": models.CategoryKindBoth})
+		if err != nil {
+			t.Fatalf("unexpected error in UpdateCategory: %+v", err)
+		}
+		err = repos.Categories.UpdateCategory(ctx, "unknown", map[string]interface{}{"name": "Unknown"})
+		if !isHTTPError(err, errutils.CategoryNotFound()) {
+			t.Fatalf("expected CATEGORY_NOT_FOUND, got: %+v", err)
+		}
+
+		fetched, err := repos.Categories.GetCategory(ctx, "rent")
+		if err != nil {
+			t.Fatalf("unexpected error in GetCategory: %+v", err)
+		}
+		if *fetched != (models.CategoryDTO{
+			ID: "rent", Name: "Rent", Kind: models.CategoryKindBoth, BudgetGroup: models.BudgetGroupEssentials,
+		}) {
+			t.Fatalf("unexpected category: %+v", fetched)
+		}
+
+		// A category is in use by the split lines too.
+		id, err := repos.Transactions.InsertTransaction(ctx, &models.TransactionDTO{
+			Amount: -100, AccountID: "bank", Category: "split",
+			Splits: []*models.SplitDTO{{Amount: -70, Category: "rent"}, {Amount: -30, Category: "luxury"}},
+		})
+		if err != nil {
+			t.Fatalf("unexpected error in InsertTransaction: %+v", err)
+		}
+		for _, categoryID := range []string{"rent", "luxury"} {
+			if isUsed, err := repos.Categories.IsCategoryUsed(ctx, categoryID); err != nil || !isUsed {
+				t.Fatalf("expected %s to be used, got: %t, %+v", categoryID, isUsed, err)
+			}
+		}
+		if isUsed, err := repos.Categories.IsCategoryUsed(ctx, "savings"); err != nil || isUsed {
+			t.Fatalf("expected savings to be unused, got: %t, %+v", isUsed, err)
+		}
+
+		if err := repos.Transactions.DeleteTransaction(ctx, id); err != nil {
+			t.Fatalf("unexpected error in DeleteTransaction: %+v", err)
+		}
+		if isUsed, err := repos.Categories.IsCategoryUsed(ctx, "rent"); err != nil || isUsed {
+			t.Fatalf("expected rent to be unused, got: %t, %+v", isUsed, err)
+		}
+
+		if err := repos.Categories.DeleteCategory(ctx, "rent"); err != nil {
+			t.Fatalf("unexpected error in DeleteCategory: %+v", err)
+		}
+		if _, err := repos.Categories.GetCategory(ctx, "rent"); !isHTTPError(err, errutils.CategoryNotFound()) {
+			t.Fatalf("expected CATEGORY_NOT_FOUND, got: %+v", err)
+		}
+		if err := repos.Categories.DeleteCategory(ctx, "rent"); !isHTTPError(err, errutils.CategoryNotFound()) {
+			t.Fatalf("expected CATEGORY_NOT_FOUND, got: %+v", err)
+		}
+	})
+}
+
+func TestGetCategoryTotals(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, repos *Repositories) {
+		ctx := tenantContext(DefaultTenantID)
+
+		insertTestAccounts(t, repos, "bank", "cash")
+
+		for _, tx := range []*models.TransactionDTO{
+			{Amount: 100, Timestamp: 1, AccountID: "bank", Category: "salary"},
+			{Amount: -30, Timestamp: 2, AccountID: "bank", Category: "essentials"},
+			{Amount: 5, Timestamp: 3, AccountID: "bank", Category: "essentials"},
+			{Amount: -7, Timestamp: 3, AccountID: "cash", Category: "essentials"},
+			{Amount: -40, Timestamp: 4, AccountID: "bank", Category: "essentials"},
+		} {
+			if _, err := repos.Transactions.InsertTransaction(ctx, tx); err != nil {
+				t.Fatalf("unexpected error in InsertTransaction: %+v", err)
+			}
+		}
+
+		totals, err := repos.Transactions.GetCategoryTotals(ctx, map[string]interface{}{
+			"timestamp": map[string]interface{}{"$lte": int64(3)},
+		})
+		if err != nil {
+			t.Fatalf("unexpected error in GetCategoryTotals: %+v", err)
+		}
+
+		expected := map[CategoryTotals]bool{
+			{AccountID: "bank", Category: "salary", Credit: 100, Debit: 0}:     true,
+			{AccountID: "bank", Category: "essentials", Credit: 5, Debit: -30}: true,
+			{AccountID: "cash", Category: "essentials", Credit: 0, Debit: -7}:  true,
+		}
+		if len(totals) != len(expected) {
+			t.Fatalf("expected %d groups, got %d", len(expected), len(totals))
+		}
+		for _, group := range totals {
+			if !expected[*group] {
+				t.Errorf("unexpected group: %+v", group)
+			}
+		}
+	})
+}
+
+func TestExchangeRateRepository(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, repos *Repositories) {
+		ctx := tenantContext(DefaultTenantID)
+
+		rates := []*models.ExchangeRateDTO{
+			{Base: "USD", Quote: "INR", Rate: "75.5", Timestamp: 200},
+			{Base: "USD", Quote: "INR", Rate: "74", Timestamp: 100},
+			{Base: "EUR", Quote: "INR", Rate: "83.25", Timestamp: 100},
+		}
+		if err := repos.ExchangeRates.PutExchangeRates(ctx, rates); err != nil {
+			t.Fatalf("unexpected error in PutExchangeRates: %+v", err)
+		}
+
+		// A rate of the same pair and timestamp replaces the existing one.
+		replacement := []*models.ExchangeRateDTO{{Base: "USD", Quote: "INR", Rate: "76", Timestamp: 200}}
+		if err := repos.ExchangeRates.PutExchangeRates(ctx, replacement); err != nil {
+			t.Fatalf("unexpected error in PutExchangeRates: %+v", err)
+		}
+
+		listed, err := repos.ExchangeRates.ListExchangeRates(ctx, "USD", "")
+		if err != nil {
+			t.Fatalf("unexpected error in ListExchangeRates: %+v", err)
+		}
+		if len(listed) != 2 || listed[0].Rate != "74" || listed[1].Rate != "76" || listed[1].ID == "" {
+			t.Fatalf("unexpected rates: %+v", listed)
+		}
+
+		if err := repos.ExchangeRates.DeleteExchangeRate(ctx, listed[0].ID); err != nil {
+			t.Fatalf("unexpected error in DeleteExchangeRate: %+v", err)
+		}
+		err = repos.ExchangeRates.DeleteExchangeRate(ctx, listed[0].ID)
+		if !isHTTPError(err, errutils.ExchangeRateNotFound()) {
+			t.Fatalf("expected EXCHANGE_RATE_NOT_FOUND, got: %+v", err)
+		}
+
+		all, err := repos.ExchangeRates.ListExchangeRates(ctx, "", "")
+		if err != nil {
+			t.Fatalf("unexpected error in ListExchangeRates: %+v", err)
+		}
+		if len(all) != 2 {
+			t.Fatalf("expected 2 rates, got %d", len(all))
+		}
+	})
+}
+
+func TestBudgetPlanRepository(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, repos *Repositories) {
+		ctx := tenantContext(DefaultTenantID)
+		percent, amount := int64(40), models.Money(5000000)
+
+		plans, err := repos.BudgetPlans.ListBudgetPlans(ctx)
+		if err != nil {
+			t.Fatalf("unexpected error in ListBudgetPlans: %+v", err)
+		}
+		if len(plans) != 0 {
+			t.Fatalf("expected no budget plans, got: %+v", plans)
+		}
+
+		laterID, err := repos.BudgetPlans.InsertBudgetPlan(ctx, &models.BudgetPlanDTO{
+			Name: "Later", Currency: "INR", ValidFrom: 200,
+			Allocations: []*models.BudgetAllocationDTO{
+				{BudgetGroup: models.BudgetGroupEssentials, Percent: &percent},
+				{BudgetGroup: models.BudgetGroupLuxury, Amount: &amount},
+			},
+		})
+		if err != nil {
+			t.Fatalf("unexpected error in InsertBudgetPlan: %+v", err)
+		}
+		if _, err := repos.BudgetPlans.InsertBudgetPlan(ctx, &models.BudgetPlanDTO{
+			Name: "Earlier", Currency: "USD", ValidFrom: 100, ValidUntil: 200,
+			Allocations: []*models.BudgetAllocationDTO{},
+		}); err != nil {
+			t.Fatalf("unexpected error in InsertBudgetPlan: %+v", err)
+		}
+
+		plans, err = repos.BudgetPlans.ListBudgetPlans(ctx)
+		if err != nil {
+			t.Fatalf("unexpected error in ListBudgetPlans: %+v", err)
+		}
+		if len(plans) != 2 || plans[0].Name != "Earlier" || len(plans[0].Allocations) != 0 {
+			t.Fatalf("unexpected budget plans: %+v", plans)
+		}
+
+		later := plans[1]
+		if later.ID != laterID || later.ValidUntil != 0 || len(later.Allocations) != 2 {
+			t.Fatalf("unexpected budget plan: %+v", later)
+		}
+		if allocation := later.Allocations[0]; allocation.Percent == nil || *allocation.Percent != 40 || allocation.Amount != nil {
+			t.Fatalf("unexpected allocation: %+v", allocation)
+		}
+		if allocation := later.Allocations[1]; allocation.Amount == nil || *allocation.Amount != amount || allocation.Percent != nil {
+			t.Fatalf("unexpected allocation: %+v", allocation)
+		}
+
+		if err := repos.BudgetPlans.DeleteBudgetPlan(ctx, laterID); err != nil {
+			t.Fatalf("unexpected error in DeleteBudgetPlan: %+v", err)
+		}
+		if err := repos.BudgetPlans.DeleteBudgetPlan(ctx, laterID); !isHTTPError(err, errutils.BudgetPlanNotFound()) {
+			t.Fatalf("expected BUDGET_PLAN_NOT_FOUND, got: %+v", err)
+		}
+	})
+}
+
+func TestRecurringTemplateRepository(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, repos *Repositories) {
+		ctx := tenantContext(DefaultTenantID)
+		insertTestAccounts(t, repos, "bank")
+
+		templateID, err := repos.Recurring.InsertRecurringTemplate(ctx, &models.RecurringTemplateDTO{
+			Name: "Rent", Amount: -500, AccountID: "bank", Category: "essentials", Notes: "Rent",
+			Rule: "FREQ=MONTHLY", StartTime: 100, NextRun: 100,
+		})
+		if err != nil {
+			t.Fatalf("unexpected error in InsertRecurringTemplate: %+v", err)
+		}
+
+		template, err := repos.Recurring.GetRecurringTemplate(ctx, templateID)
+		if err != nil {
+			t.Fatalf("unexpected error in GetRecurringTemplate: %+v", err)
+		}
+		if template.ID != templateID || template.Rule != "FREQ=MONTHLY" || template.NextRun != 100 || template.EndTime != 0 {
+			t.Fatalf("unexpected recurring template: %+v", template)
+		}
+
+		transactions := []*models.TransactionDTO{
+			{Amount: -500, Timestamp: 100, AccountID: "bank", Category: "essentials", Notes: "Rent"},
+			{Amount: -500, Timestamp: 200, AccountID: "bank", Category: "essentials", Notes: "Rent"},
+		}
+		advanced, err := repos.Recurring.AdvanceRecurringTemplate(ctx, templateID, 100, 300, transactions)
+		if err != nil || !advanced {
+			t.Fatalf("expected the template to be advanced, got: %t, %+v", advanced, err)
+		}
+
+		// A stale next run means that the template was already advanced, so nothing should change.
+		advanced, err = repos.Recurring.AdvanceRecurringTemplate(ctx, templateID, 100, 300, transactions)
+		if err != nil || advanced {
+			t.Fatalf("expected the template to not be advanced, got: %t, %+v", advanced, err)
+		}
+
+		created, _, err := repos.Transactions.ListTransactions(ctx, &ListTransactionsParams{
+			Filter: map[string]interface{}{"account_id": "bank"}, SortField: "timestamp", SortOrder: 1, ExcludeCount: true,
+		})
+		if err != nil {
+			t.Fatalf("unexpected error in ListTransactions: %+v", err)
+		}
+		if len(created) != 2 || created[0].Timestamp != 100 || created[1].Timestamp != 200 {
+			t.Fatalf("unexpected transactions: %+v", created)
+		}
+
+		templates, err := repos.Recurring.ListRecurringTemplates(ctx)
+		if err != nil {
+			t.Fatalf("unexpected error in ListRecurringTemplates: %+v", err)
+		}
+		if len(templates) != 1 || templates[0].NextRun != 300 {
+			t.Fatalf("unexpected recurring templates: %+v", templates)
+		}
+
+		if err := repos.Recurring.DeleteRecurringTemplate(ctx, templateID); err != nil {
+			t.Fatalf("unexpected error in DeleteRecurringTemplate: %+v", err)
+		}
+		if err := repos.Recurring.DeleteRecurringTemplate(ctx, templateID); !isHTTPError(err, errutils.RecurringTemplateNotFound()) {
+			t.Fatalf("expected RECURRING_TEMPLATE_NOT_FOUND, got: %+v", err)
+		}
+		_, err = repos.Recurring.AdvanceRecurringTemplate(ctx, templateID, 300, 400, nil)
+		if !isHTTPError(err, errutils.RecurringTemplateNotFound()) {
+			t.Fatalf("expected RECURRING_TEMPLATE_NOT_FOUND, got: %+v", err)
+		}
+	})
+}
+
+func TestInsertTransactions(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, repos *Repositories) {
+		ctx := tenantContext(DefaultTenantID)
+		insertTestAccounts(t, repos, "bank")
+
+		ids, err := repos.Transactions.InsertTransactions(ctx, []*models.TransactionDTO{
+			{Amount: 500, Timestamp: 100, AccountID: "bank", Category: "earnings", Notes: "Salary"},
+			{Amount: -20, Timestamp: 200, AccountID: "bank", Category: "essentials", Notes: "Groceries", ExternalID: "A2",
+				Tags: models.Tags{"food"}},
+		})
+		if err != nil {
+			t.Fatalf("unexpected error in InsertTransactions: %+v", err)
+		}
+		if len(ids) != 2 || !primitive.IsValidObjectID(ids[0]) || !primitive.IsValidObjectID(ids[1]) {
+			t.Fatalf("expected two ObjectIDs, got: %+v", ids)
+		}
+
+		transaction, err := repos.Transactions.GetTransaction(ctx, ids[1])
+		if err != nil {
+			t.Fatalf("unexpected error in GetTransaction: %+v", err)
+		}
+		if transaction.Amount != -20 || transaction.Notes != "Groceries" || transaction.ExternalID != "A2" ||
+			len(transaction.Tags) != 1 || transaction.Tags[0] != "food" {
+			t.Fatalf("unexpected transaction: %+v", transaction)
+		}
+
+		// The tags are replaced as a whole, and the transactions without tags have none.
+		err = repos.Transactions.UpdateTransaction(ctx, ids[0], map[string]interface{}{"tags": models.Tags{"work", "bonus"}})
+		if err != nil {
+			t.Fatalf("unexpected error in UpdateTransaction: %+v", err)
+		}
+		tagged, _, err := repos.Transactions.ListTransactions(ctx, &ListTransactionsParams{
+			Filter:         map[string]interface{}{"account_id": "bank"},
+			RequiredFields: []string{"tags"},
+			SortField:      "timestamp",
+			SortOrder:      1,
+			ExcludeCount:   true,
+		})
+		if err != nil {
+			t.Fatalf("unexpected error in ListTransactions: %+v", err)
+		}
+		if len(tagged) != 2 || len(tagged[0].Tags) != 2 || tagged[0].Tags[1] != "bonus" || tagged[1].Tags[0] != "food" {
+			t.Fatalf("unexpected tagged transactions: %+v", tagged)
+		}
+
+		// Only the transactions with an external ID are listed.
+		imported, _, err := repos.Transactions.ListTransactions(ctx, &ListTransactionsParams{
+			Filter:         map[string]interface{}{"account_id": "bank", "external_id": map[string]interface{}{"$gt": ""}},
+			RequiredFields: []string{"external_id"},
+			ExcludeCount:   true,
+		})
+		if err != nil {
+			t.Fatalf("unexpected error in ListTransactions: %+v", err)
+		}
+		if len(imported) != 1 || imported[0].ID != ids[1] || imported[0].ExternalID != "A2" {
+			t.Fatalf("unexpected imported transactions: %+v", imported)
+		}
+	})
+}
+
+func TestImportProfileRepository(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, repos *Repositories) {
+		ctx := tenantContext(DefaultTenantID)
+
+		profileID, err := repos.Imports.InsertImportProfile(ctx, &models.ImportProfileDTO{
+			Name: "Bank", AccountID: "bank", Delimiter: ";", DecimalSeparator: ",", DateColumn: "Date",
+			DateFormat: "02.01.2006", DebitColumn: "Debit", CreditColumn: "Credit", NotesColumn: "Notes",
+			CreditCategory: "earnings", DebitCategory: "essentials",
+		})
+		if err != nil {
+			t.Fatalf("unexpected error in InsertImportProfile: %+v", err)
+		}
+
+		profile, err := repos.Imports.GetImportProfile(ctx, profileID)
+		if err != nil {
+			t.Fatalf("unexpected error in GetImportProfile: %+v", err)
+		}
+		if profile.ID != profileID || profile.Delimiter != ";" || profile.DateFormat != "02.01.2006" ||
+			profile.AmountColumn != "" || profile.DebitCategory != "essentials" {
+			t.Fatalf("unexpected import profile: %+v", profile)
+		}
+
+		profiles, err := repos.Imports.ListImportProfiles(ctx)
+		if err != nil {
+			t.Fatalf("unexpected error in ListImportProfiles: %+v", err)
+		}
+		if len(profiles) != 1 || profiles[0].ID != profileID {
+			t.Fatalf("unexpected import profiles: %+v", profiles)
+		}
+
+		if err := repos.Imports.DeleteImportProfile(ctx, profileID); err != nil {
+			t.Fatalf("unexpected error in DeleteImportProfile: %+v", err)
+		}
+		if _, err := repos.Imports.GetImportProfile(ctx, profileID); !isHTTPError(err, errutils.ImportProfileNotFound()) {
+			t.Fatalf("expected IMPORT_PROFILE_NOT_FOUND, got: %+v", err)
+		}
+	})
+}
+
+func TestStreamTransactions(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, repos *Repositories) {
+		ctx := tenantContext(DefaultTenantID)
+		insertTestAccounts(t, repos, "bank", "card")
+
+		if _, err := repos.Transactions.InsertTransactions(ctx, []*models.TransactionDTO{
+			{Amount: -20, Timestamp: 300, AccountID: "bank", Category: "essentials", Notes: "Groceries"},
+			{Amount: 500, Timestamp: 100, AccountID: "bank", Category: "earnings", Notes: "Salary"},
+			{Amount: -10, Timestamp: 200, AccountID: "card", Category: "luxury", Notes: "Cinema"},
+		}); err != nil {
+			t.Fatalf("unexpected error in InsertTransactions: %+v", err)
+		}
+		splitID, err := repos.Transactions.InsertTransaction(ctx, &models.TransactionDTO{
+			Amount: -30, Timestamp: 400, AccountID: "bank", Category: "split", Notes: "Shopping",
+			Splits: []*models.SplitDTO{{Amount: -10, Category: "essentials"}, {Amount: -20, Category: "luxury"}},
+		})
+		if err != nil {
+			t.Fatalf("unexpected error in InsertTransaction: %+v", err)
+		}
+
+		cursor, err := repos.Transactions.StreamTransactions(ctx, &ListTransactionsParams{
+			Filter: map[string]interface{}{"account_id": "bank"}, SortField: "timestamp", SortOrder: 1,
+		})
+		if err != nil {
+			t.Fatalf("unexpected error in StreamTransactions: %+v", err)
+		}
+		defer func() { _ = cursor.Close(ctx) }()
+
+		var streamed []*models.TransactionDTO
+		for cursor.Next(ctx) {
+			streamed = append(streamed, cursor.Transaction())
+		}
+		if err := cursor.Err(); err != nil {
+			t.Fatalf("unexpected cursor error: %+v", err)
+		}
+
+		if len(streamed) != 3 || streamed[0].Notes != "Salary" || streamed[1].Amount != -20 || streamed[2].ID != splitID {
+			t.Fatalf("unexpected streamed transactions: %+v", streamed)
+		}
+		// The split lines are not streamed.
+		if streamed[2].Category != "split" || streamed[2].Splits != nil {
+			t.Fatalf("unexpected streamed transaction: %+v", streamed[2])
+		}
+	})
+}
+
+func TestRuleRepository(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, repos *Repositories) {
+		ctx := tenantContext(DefaultTenantID)
+		minAmount := models.Money(-1000000)
+
+		laterID, err := repos.Rules.InsertRule(ctx, &models.RuleDTO{
+			Name: "Groceries", Priority: 20, NotesPattern: "(?i)market", MinAmount: &minAmount,
+			Weekdays: []string{"SA", "SU"}, SetCategory: "essentials", AddTags: models.Tags{"food"},
+		})
+		if err != nil {
+			t.Fatalf("unexpected error in InsertRule: %+v", err)
+		}
+		if _, err := repos.Rules.InsertRule(ctx, &models.RuleDTO{
+			Name: "Salary", Priority: 10, AccountID: "bank", SetNotes: "Salary",
+		}); err != nil {
+			t.Fatalf("unexpected error in InsertRule: %+v", err)
+		}
+
+		rules, err := repos.Rules.ListRules(ctx)
+		if err != nil {
+			t.Fatalf("unexpected error in ListRules: %+v", err)
+		}
+		if len(rules) != 2 || rules[0].Name != "Salary" || rules[0].MinAmount != nil || len(rules[0].Weekdays) != 0 {
+			t.Fatalf("unexpected rules: %+v", rules)
+		}
+
+		later := rules[1]
+		if later.ID != laterID || later.MinAmount == nil || *later.MinAmount != minAmount || later.MaxAmount != nil ||
+			len(later.Weekdays) != 2 || later.Weekdays[1] != "SU" || len(later.AddTags) != 1 || later.AddTags[0] != "food" {
+			t.Fatalf("unexpected rule: %+v", later)
+		}
+
+		if err := repos.Rules.DeleteRule(ctx, laterID); err != nil {
+			t.Fatalf("unexpected error in DeleteRule: %+v", err)
+		}
+		if err := repos.Rules.DeleteRule(ctx, laterID); !isHTTPError(err, errutils.RuleNotFound()) {
+			t.Fatalf("expected RULE_NOT_FOUND, got: %+v", err)
+		}
+	})
+}
+
+func TestReplaceLedger(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, repos *Repositories) {
+		ctx := tenantContext(DefaultTenantID)
+		insertTestAccounts(t, repos, "old")
+		if _, err := repos.Transactions.InsertTransaction(ctx, &models.TransactionDTO{
+			Amount: -100, AccountID: "old", Category: "essentials",
+		}); err != nil {
+			t.Fatalf("unexpected error in InsertTransaction: %+v", err)
+		}
+
+		percent := int64(100)
+		transactionID := primitive.NewObjectID().Hex()
+		data := &models.LedgerData{
+			Accounts:   []*models.AccountDTO{{ID: "new", Name: "New", Currency: "EUR"}, {ID: "cash", Name: "Cash"}},
+			Categories: []*models.CategoryDTO{{ID: "food", Name: "Food", Kind: "expense"}},
+			BudgetPlans: []*models.BudgetPlanDTO{{
+				ID: primitive.NewObjectID().Hex(), Name: "Plan", Currency: "EUR", ValidFrom: 1,
+				Allocations: []*models.BudgetAllocationDTO{{BudgetGroup: "needs", Percent: &percent}},
+			}},
+			Rules: []*models.RuleDTO{{ID: primitive.NewObjectID().Hex(), Name: "Rule", SetCategory: "food"}},
+			Transactions: []*models.TransactionDTO{{
+				ID: transactionID, Amount: -300, Timestamp: 10, AccountID: "new", Category: "split",
+				Splits: []*models.SplitDTO{{Amount: -100, Category: "food"}, {Amount: -200, Category: "food"}},
+			}},
+		}
+
+		if err := repos.Backups.ReplaceLedger(ctx, data); err != nil {
+			t.Fatalf("unexpected error in ReplaceLedger: %+v", err)
+		}
+
+		accounts, err := repos.Accounts.ListAccounts(ctx)
+		if err != nil {
+			t.Fatalf("unexpected error in ListAccounts: %+v", err)
+		}
+		if len(accounts) != 2 || accounts[0].ID != "new" || accounts[0].Currency != "EUR" || accounts[1].ID != "cash" {
+			t.Fatalf("unexpected accounts: %+v", accounts)
+		}
+
+		categories, err := repos.Categories.ListCategories(ctx)
+		if err != nil {
+			t.Fatalf("unexpected error in ListCategories: %+v", err)
+		}
+		if len(categories) != 1 || categories[0].ID != "food" {
+			t.Fatalf("unexpected categories: %+v", categories)
+		}
+
+		transaction, err := repos.Transactions.GetTransaction(ctx, transactionID)
+		if err != nil {
+			t.Fatalf("unexpected error in GetTransaction: %+v", err)
+		}
+		if transaction.Amount != -300 || len(transaction.Splits) != 2 || transaction.Splits[1].Amount != -200 {
+			t.Fatalf("unexpected transaction: %+v", transaction)
+		}
+
+		balances, err := repos.Accounts.GetAccountBalances(ctx)
+		if err != nil {
+			t.Fatalf("unexpected error in GetAccountBalances: %+v", err)
+		}
+		if len(balances) != 1 || balances["new"] != -300 {
+			t.Fatalf("unexpected balances: %+v", balances)
+		}
+
+		plans, err := repos.BudgetPlans.ListBudgetPlans(ctx)
+		if err != nil {
+			t.Fatalf("unexpected error in ListBudgetPlans: %+v", err)
+		}
+		if len(plans) != 1 || plans[0].ID != data.BudgetPlans[0].ID || len(plans[0].Allocations) != 1 {
+			t.Fatalf("unexpected budget plans: %+v", plans)
+		}
+
+		rules, err := repos.Rules.ListRules(ctx)
+		if err != nil {
+			t.Fatalf("unexpected error in ListRules: %+v", err)
+		}
+		if len(rules) != 1 || rules[0].ID != data.Rules[0].ID {
+			t.Fatalf("unexpected rules: %+v", rules)
+		}
+	})
+}
+
+func TestReplaceLedgerAcrossTenants(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, repos *Repositories) {
+		ctxA, ctxB := tenantContext("tenant-a"), tenantContext("tenant-b")
+
+		// A backup of one tenant is restored into another, so both have the same IDs.
+		percent := int64(100)
+		transactionID := primitive.NewObjectID().Hex()
+		newData := func() *models.LedgerData {
+			return &models.LedgerData{
+				Accounts:   []*models.AccountDTO{{ID: "cash", Name: "Cash"}},
+				Categories: []*models.CategoryDTO{{ID: "food", Name: "Food", Kind: "expense"}},
+				ExchangeRates: []*models.ExchangeRateDTO{{
+					ID: "000000000000000000000001", Base: "USD", Quote: "EUR", Rate: "0.9", Timestamp: 1,
+				}},
+				BudgetPlans: []*models.BudgetPlanDTO{{
+					ID: "000000000000000000000002", Name: "Plan", Currency: "EUR", ValidFrom: 1,
+					Allocations: []*models.BudgetAllocationDTO{{BudgetGroup: "needs", Percent: &percent}},
+				}},
+				RecurringTemplates: []*models.RecurringTemplateDTO{{
+					ID: "000000000000000000000003", Name: "Rent", Amount: -100, AccountID: "cash", Category: "food",
+				}},
+				ImportProfiles: []*models.ImportProfileDTO{{ID: "000000000000000000000004", Name: "Bank",
+					AccountID: "cash"}},
+				Rules: []*models.RuleDTO{{ID: "000000000000000000000005", Name: "Rule", SetCategory: "food"}},
+				Transactions: []*models.TransactionDTO{{
+					ID: transactionID, Amount: -300, Timestamp: 10, AccountID: "cash", Category: "split",
+					Splits: []*models.SplitDTO{{Amount: -100, Category: "food"}, {Amount: -200, Category: "food"}},
+				}},
+			}
+		}
+
+		if err := repos.Backups.ReplaceLedger(ctxA, newData()); err != nil {
+			t.Fatalf("unexpected error in ReplaceLedger: %+v", err)
+		}
+		if err := repos.Backups.ReplaceLedger(ctxB, newData()); err != nil {
+			t.Fatalf("unexpected error in ReplaceLedger of another tenant: %+v", err)
+		}
+
+		// Emptying the ledger of one tenant does not touch the same IDs of the other.
+		if err := repos.Backups.ReplaceLedger(ctxB, &models.LedgerData{}); err != nil {
+			t.Fatalf("unexpected error in ReplaceLedger with no data: %+v", err)
+		}
+
+		transaction, err := repos.Transactions.GetTransaction(ctxA, transactionID)
+		if err != nil {
+			t.Fatalf("unexpected error in GetTransaction: %+v", err)
+		}
+		if len(transaction.Splits) != 2 || transaction.Splits[1].Amount != -200 {
+			t.Fatalf("unexpected transaction: %+v", transaction)
+		}
+		if _, err := repos.Transactions.GetTransaction(ctxB, transactionID); !isHTTPError(err,
+			errutils.TransactionNotFound()) {
+			t.Fatalf("expected TRANSACTION_NOT_FOUND, got: %+v", err)
+		}
+
+		rates, err := repos.ExchangeRates.ListExchangeRates(ctxA, "", "")
+		if err != nil || len(rates) != 1 {
+			t.Fatalf("unexpected exchange rates: %+v, error: %+v", rates, err)
+		}
+		plans, err := repos.BudgetPlans.ListBudgetPlans(ctxA)
+		if err != nil || len(plans) != 1 || len(plans[0].Allocations) != 1 {
+			t.Fatalf("unexpected budget plans: %+v, error: %+v", plans, err)
+		}
+		templates, err := repos.Recurring.ListRecurringTemplates(ctxA)
+		if err != nil || len(templates) != 1 {
+			t.Fatalf("unexpected recurring templates: %+v, error: %+v", templates, err)
+		}
+		profiles, err := repos.Imports.ListImportProfiles(ctxA)
+		if err != nil || len(profiles) != 1 {
+			t.Fatalf("unexpected import profiles: %+v, error: %+v", profiles, err)
+		}
+		rules, err := repos.Rules.ListRules(ctxA)
+		if err != nil || len(rules) != 1 {
+			t.Fatalf("unexpected rules: %+v, error: %+v", rules, err)
+		}
+
+		// The split lines of the other tenant are not counted, even while it has the same transaction IDs.
+		if err := repos.Backups.ReplaceLedger(ctxB, newData()); err != nil {
+			t.Fatalf("unexpected error in ReplaceLedger of another tenant: %+v", err)
+		}
+		totals, err := repos.Transactions.GetCategoryTotals(ctxA, nil)
+		if err != nil {
+			t.Fatalf("unexpected error in GetCategoryTotals: %+v", err)
+		}
+		if len(totals) != 1 || totals[0].Category != "food" || totals[0].Debit != -300 {
+			t.Fatalf("unexpected category totals: %+v", totals)
+		}
+	})
+}
+
+func TestUserRepository(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, repos *Repositories) {
+		ctx := context.Background()
+
+		// The admin user of the configs is created along with the repositories.
+		admin, err := repos.Users.GetUser(ctx, "test-user")
+		if err != nil {
+			t.Fatalf("unexpected error in GetUser: %+v", err)
+		}
+		if !admin.IsAdmin || admin.TenantID != DefaultTenantID || admin.PasswordHash == "test-pass" {
+			t.Fatalf("unexpected admin user: %+v", admin)
+		}
+
+		user := &models.UserDTO{ID: "alice", PasswordHash: "hash", TenantID: "tenant-a", CreatedAt: 1}
+		if err := repos.Users.InsertUser(ctx, user); err != nil {
+			t.Fatalf("unexpected error in InsertUser: %+v", err)
+		}
+		if err := repos.Users.InsertUser(ctx, user); !isHTTPError(err, errutils.UserAlreadyExists()) {
+			t.Fatalf("expected USER_ALREADY_EXISTS, got: %+v", err)
+		}
+
+		if err := repos.Users.UpdateUser(ctx, "alice", map[string]interface{}{"password_hash": "new-hash"}); err != nil {
+			t.Fatalf("unexpected error in UpdateUser: %+v", err)
+		}
+		err = repos.Users.UpdateUser(ctx, "bob", map[string]interface{}{"password_hash": "new-hash"})
+		if !isHTTPError(err, errutils.UserNotFound()) {
+			t.Fatalf("expected USER_NOT_FOUND, got: %+v", err)
+		}
+
+		users, err := repos.Users.ListUsers(ctx)
+		if err != nil {
+			t.Fatalf("unexpected error in ListUsers: %+v", err)
+		}
+		if len(users) != 2 || users[0].ID != "alice" || users[0].PasswordHash != "new-hash" ||
+			users[0].TenantID != "tenant-a" || users[1].ID != "test-user" {
+			t.Fatalf("unexpected users: %+v", users)
+		}
+
+		if _, err := repos.Users.GetUser(ctx, "bob"); !isHTTPError(err, errutils.UserNotFound()) {
+			t.Fatalf("expected USER_NOT_FOUND, got: %+v", err)
+		}
+	})
+}
+
+func TestMemberRepository(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, repos *Repositories) {
+		ctx := context.Background()
+
+		for _, userID := range []string{"alice", "bob"} {
+			user := &models.UserDTO{ID: userID, PasswordHash: "hash", TenantID: "tenant-" + userID}
+			if err := repos.Users.InsertUser(ctx, user); err != nil {
+				t.Fatalf("unexpected error in InsertUser: %+v", err)
+			}
+		}
+
+		members := []*models.MemberDTO{
+			{LedgerID: "tenant-alice", UserID: "bob", Role: models.RoleViewer, CreatedAt: 1},
+			{LedgerID: DefaultTenantID, UserID: "bob", Role: models.RoleEditor, CreatedAt: 2},
+			{LedgerID: DefaultTenantID, UserID: "alice", Role: models.RoleOwner, CreatedAt: 3},
+		}
+		for _, member := range members {
+			if err := repos.Members.InsertMember(ctx, member); err != nil {
+				t.Fatalf("unexpected error in InsertMember: %+v", err)
+			}
+		}
+		if err := repos.Members.InsertMember(ctx, members[0]); !isHTTPError(err, errutils.MemberAlreadyExists()) {
+			t.Fatalf("expected MEMBER_ALREADY_EXISTS, got: %+v", err)
+		}
+
+		member, err := repos.Members.GetMember(ctx, "tenant-alice", "bob")
+		if err != nil {
+			t.Fatalf("unexpected error in GetMember: %+v", err)
+		}
+		if member.Role != models.RoleViewer || member.CreatedAt != 1 {
+			t.Fatalf("unexpected member: %+v", member)
+		}
+		if _, err := repos.Members.GetMember(ctx, "tenant-bob", "alice"); !isHTTPError(err, errutils.MemberNotFound()) {
+			t.Fatalf("expected MEMBER_NOT_FOUND, got: %+v", err)
+		}
+
+		ledgerMembers, err := repos.Members.ListLedgerMembers(ctx, DefaultTenantID)
+		if err != nil {
+			t.Fatalf("unexpected error in ListLedgerMembers: %+v", err)
+		}
+		if len(ledgerMembers) != 2 || ledgerMembers[0].UserID != "alice" || ledgerMembers[1].UserID != "bob" {
+			t.Fatalf("unexpected ledger members: %+v", ledgerMembers)
+		}
+
+		memberships, err := repos.Members.ListUserMemberships(ctx, "bob")
+		if err != nil {
+			t.Fatalf("unexpected error in ListUserMemberships: %+v", err)
+		}
+		if len(memberships) != 2 || memberships[0].LedgerID != DefaultTenantID || memberships[1].LedgerID != "tenant-alice" {
+			t.Fatalf("unexpected memberships: %+v", memberships)
+		}
+
+		if err := repos.Members.DeleteMember(ctx, "tenant-alice", "bob"); err != nil {
+			t.Fatalf("unexpected error in DeleteMember: %+v", err)
+		}
+		if err := repos.Members.DeleteMember(ctx, "tenant-alice", "bob"); !isHTTPError(err, errutils.MemberNotFound()) {
+			t.Fatalf("expected MEMBER_NOT_FOUND, got: %+v", err)
+		}
+	})
+}
+
+func TestTokenRepository(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, repos *Repositories) {
+		ctx := context.Background()
+
+		for _, userID := range []string{"alice", "bob"} {
+			user := &models.UserDTO{ID: userID, PasswordHash: "hash", TenantID: "tenant-" + userID}
+			if err := repos.Users.InsertUser(ctx, user); err != nil {
+				t.Fatalf("unexpected error in InsertUser: %+v", err)
+			}
+		}
+
+		tokens := []*models.TokenDTO{
+			{ID: "token-2", UserID: "alice", Name: "Phone", Scopes: []string{"transactions:write"}, TokenHash: "hash-2",
+				CreatedAt: 2},
+			{ID: "token-1", UserID: "alice", Name: "Script", Scopes: []string{"stats:read", "accounts:read"},
+				TokenHash: "hash-1", ExpiresAt: 100, CreatedAt: 1},
+			{ID: "token-3", UserID: "bob", Name: "Script", Scopes: []string{"stats:read"}, TokenHash: "hash-3",
+				CreatedAt: 3},
+		}
+		for _, token := range tokens {
+			if err := repos.Tokens.InsertToken(ctx, token); err != nil {
+				t.Fatalf("unexpected error in InsertToken: %+v", err)
+			}
+		}
+
+		token, err := repos.Tokens.GetTokenByHash(ctx, "hash-1")
+		if err != nil {
+			t.Fatalf("unexpected error in GetTokenByHash: %+v", err)
+		}
+		if token.ID != "token-1" || token.Name != "Script" || len(token.Scopes) != 2 || token.Scopes[1] != "accounts:read" ||
+			token.ExpiresAt != 100 {
+			t.Fatalf("unexpected token: %+v", token)
+		}
+		if _, err := repos.Tokens.GetTokenByHash(ctx, "hash-4"); !isHTTPError(err, errutils.TokenNotFound()) {
+			t.Fatalf("expected TOKEN_NOT_FOUND, got: %+v", err)
+		}
+
+		if err := repos.Tokens.UpdateTokenLastUsed(ctx, "token-2", 50); err != nil {
+			t.Fatalf("unexpected error in UpdateTokenLastUsed: %+v", err)
+		}
+
+		userTokens, err := repos.Tokens.ListUserTokens(ctx, "alice")
+		if err != nil {
+			t.Fatalf("unexpected error in ListUserTokens: %+v", err)
+		}
+		if len(userTokens) != 2 || userTokens[0].ID != "token-1" || userTokens[1].ID != "token-2" ||
+			userTokens[1].LastUsedAt != 50 {
+			t.Fatalf("unexpected tokens: %+v", userTokens)
+		}
+
+		// The tokens can only be revoked by their own users.
+		if err := repos.Tokens.DeleteToken(ctx, "bob", "token-1"); !isHTTPError(err, errutils.TokenNotFound()) {
+			t.Fatalf("expected TOKEN_NOT_FOUND, got: %+v", err)
+		}
+		if err := repos.Tokens.DeleteToken(ctx, "alice", "token-1"); err != nil {
+			t.Fatalf("unexpected error in DeleteToken: %+v", err)
+		}
+		if _, err := repos.Tokens.GetTokenByHash(ctx, "hash-1"); !isHTTPError(err, errutils.TokenNotFound()) {
+			t.Fatalf("expected TOKEN_NOT_FOUND, got: %+v", err)
+		}
+	})
+}
+
+func TestTenantIsolation(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, repos *Repositories) {
+		ctxA, ctxB := tenantContext("tenant-a"), tenantContext("tenant-b")
+
+		// Without a tenant, no data can be accessed at all.
+		if _, err := repos.Accounts.ListAccounts(context.Background()); !errors.Is(err, errMissingTenant) {
+			t.Fatalf("expected the missing tenant error, got: %+v", err)
+		}
+
+		// The account IDs are chosen by the users, so every tenant can have the same ones.
+		for _, ctx := range []context.Context{ctxA, ctxB} {
+			if err := repos.Accounts.InsertAccount(ctx, &models.AccountDTO{ID: "bank", Name: "Bank"}); err != nil {
+				t.Fatalf("unexpected error in InsertAccount: %+v", err)
+			}
+			if err := repos.Categories.InsertCategory(ctx, &models.CategoryDTO{ID: "food", Kind: "expense"}); err != nil {
+				t.Fatalf("unexpected error in InsertCategory: %+v", err)
+			}
+		}
+
+		txID, err := repos.Transactions.InsertTransaction(ctxA, &models.TransactionDTO{
+			Amount: -100, AccountID: "bank", Category: "food",
+		})
+		if err != nil {
+			t.Fatalf("unexpected error in InsertTransaction: %+v", err)
+		}
+
+		// The transaction of tenant A cannot be read, changed or deleted by tenant B, even with its ID.
+		if _, err := repos.Transactions.GetTransaction(ctxB, txID); !isHTTPError(err, errutils.TransactionNotFound()) {
+			t.Fatalf("expected TRANSACTION_NOT_FOUND, got: %+v", err)
+		}
+		err = repos.Transactions.UpdateTransaction(ctxB, txID, map[string]interface{}{"notes": "stolen"})
+		if !isHTTPError(err, errutils.TransactionNotFound()) {
+			t.Fatalf("expected TRANSACTION_NOT_FOUND, got: %+v", err)
+		}
+		if err := repos.Transactions.DeleteTransaction(ctxB, txID); !isHTTPError(err, errutils.TransactionNotFound()) {
+			t.Fatalf("expected TRANSACTION_NOT_FOUND, got: %+v", err)
+		}
+
+		transactions, count, err := repos.Transactions.ListTransactions(ctxB, &ListTransactionsParams{
+			Filter: map[string]interface{}{}, SortField: "timestamp", SortOrder: 1,
+		})
+		if err != nil {
+			t.Fatalf("unexpected error in ListTransactions: %+v", err)
+		}
+		if len(transactions) != 0 || count != 0 {
+			t.Fatalf("expected no transactions for tenant B, got: %+v", transactions)
+		}
+
+		balances, err := repos.Accounts.GetAccountBalances(ctxB)
+		if err != nil {
+			t.Fatalf("unexpected error in GetAccountBalances: %+v", err)
+		}
+		if len(balances) != 0 {
+			t.Fatalf("expected no balances for tenant B, got: %+v", balances)
+		}
+		if isUsed, _ := repos.Categories.IsCategoryUsed(ctxB, "food"); isUsed {
+			t.Fatalf("expected the category of tenant B to be unused")
+		}
+
+		// Replacing the ledger of tenant B leaves that of tenant A untouched.
+		if err := repos.Backups.ReplaceLedger(ctxB, &models.LedgerData{}); err != nil {
+			t.Fatalf("unexpected error in ReplaceLedger: %+v", err)
+		}
+		if _, err := repos.Transactions.GetTransaction(ctxA, txID); err != nil {
+			t.Fatalf("unexpected error in GetTransaction: %+v", err)
+		}
+		if exists, _ := repos.Accounts.IsAccountExists(ctxA, "bank"); !exists {
+			t.Fatalf("expected the account of tenant A to exist")
+		}
+		if exists, _ := repos.Accounts.IsAccountExists(ctxB, "bank"); exists {
+			t.Fatalf("expected the account of tenant B to be deleted")
+		}
+	})
+}
+
+func TestListTransactions(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, repos *Repositories) {
+		ctx := tenantContext(DefaultTenantID)
+		insertTestAccounts(t, repos, "bank", "cash")
+
+		for _, tx := range []*models.TransactionDTO{
+			{Amount: -10, Timestamp: 300, AccountID: "bank", Category: "essentials", Notes: "Groceries at the market"},
+			{Amount: 500, Timestamp: 100, AccountID: "bank", Category: "earnings", Notes: "Salary"},
+			{Amount: -25, Timestamp: 200, AccountID: "cash", Category: "luxury", Notes: "Movie tickets"},
+			{Amount: -40, Timestamp: 200, AccountID: "bank", Category: "luxury", Notes: "Dinner at the market"},
+		} {
+			if _, err := repos.Transactions.InsertTransaction(ctx, tx); err != nil {
+				t.Fatalf("unexpected error in InsertTransaction: %+v", err)
+			}
+		}
+
+		testCases := []struct {
+			name          string
+			params        *ListTransactionsParams
+			expectedNotes []string
+			expectedCount int
+		}{
+			{
+				name:          "sort by timestamp with ID as tie breaker",
+				params:        &ListTransactionsParams{SortField: "timestamp", SortOrder: 1, PaginationLimit: math.MaxInt64},
+				expectedNotes: []string{"Salary", "Movie tickets", "Dinner at the market", "Groceries at the market"},
+				expectedCount: 4,
+			},
+			{
+				name: "amount range and account filter",
+				params: &ListTransactionsParams{
+					Filter:          map[string]interface{}{"amount": map[string]interface{}{"$lte": models.Money(-10)}, "account_id": "bank"},
+					SortField:       "amount",
+					SortOrder:       -1,
+					PaginationLimit: 10,
+				},
+				expectedNotes: []string{"Groceries at the market", "Dinner at the market"},
+				expectedCount: 2,
+			},
+			{
+				name:          "skip and limit",
+				params:        &ListTransactionsParams{SortField: "timestamp", SortOrder: -1, PaginationLimit: 2, PaginationSkip: 1},
+				expectedNotes: []string{"Dinner at the market", "Movie tickets"},
+				expectedCount: 4,
+			},
+			{
+				name: "text search",
+				params: &ListTransactionsParams{
+					Filter:          map[string]interface{}{"$text": map[string]interface{}{"$search": "MARKET salary -dinner"}},
+					SortField:       "timestamp",
+					SortOrder:       1,
+					PaginationLimit: 10,
+				},
+				expectedNotes: []string{"Salary", "Groceries at the market"},
+				expectedCount: 2,
+			},
+			{
+				name: "excluded count",
+				params: &ListTransactionsParams{
+					Filter:          map[string]interface{}{"category": "luxury"},
+					SortField:       "timestamp",
+					SortOrder:       1,
+					PaginationLimit: 10,
+					ExcludeCount:    true,
+				},
+				expectedNotes: []string{"Movie tickets", "Dinner at the market"},
+				expectedCount: 0,
+			},
+		}
+
+		for _, testCase := range testCases {
+			transactions, count, err := repos.Transactions.ListTransactions(ctx, testCase.params)
+			if err != nil {
+				t.Fatalf("%s: unexpected error: %+v", testCase.name, err)
+			}
+			if count != testCase.expectedCount {
+				t.Errorf("%s: expected count %d, got %d", testCase.name, testCase.expectedCount, count)
+			}
+			if len(transactions) != len(testCase.expectedNotes) {
+				t.Errorf("%s: expected %d transactions, got %d", testCase.name, len(testCase.expectedNotes), len(transactions))
+				continue
+			}
+			for idx, tx := range transactions {
+				if tx.Notes != testCase.expectedNotes[idx] {
+					t.Errorf("%s: expected %q at index %d, got %q", testCase.name, testCase.expectedNotes[idx], idx, tx.Notes)
+				}
+			}
+		}
+	})
+}
+
+// forEachBackend runs the test function against the empty repositories of every backend that runs without a server.
+// The PostgreSQL backend is included when a test database is configured, see openPostgresTestDB.
+func forEachBackend(t *testing.T, testFunc func(t *testing.T, repos *Repositories)) {
+	t.Helper()
+
+	t.Run("memory", func(t *testing.T) {
+		testFunc(t, NewMemoryRepositories())
+	})
+
+	t.Run("sqlite", func(t *testing.T) {
+		db, err := sqlite.Open(filepath.Join(t.TempDir(), "test.db"))
+		if err != nil {
+			t.Fatalf("failed to open sqlite database: %+v", err)
+		}
+		defer func() { _ = db.Close() }()
+
+		repos, err := newSQLiteRepositoriesWithDB(context.Background(), db)
+		if err != nil {
+			t.Fatalf("failed to create sqlite repositories: %+v", err)
+		}
+		testFunc(t, repos)
+	})
+
+	t.Run("postgres", func(t *testing.T) {
+		db := openPostgresTestDB(t)
+		defer func() { _ = db.Close() }()
+
+		repos, err := newPostgresRepositoriesWithDB(context.Background(), db)
+		if err != nil {
+			t.Fatalf("failed to create postgres repositories: %+v", err)
+		}
+		testFunc(t, repos)
+	})
+}
+
+// insertTestAccounts creates the accounts with the provided IDs, so transactions can refer to them.
+func insertTestAccounts(t *testing.T, repos *Repositories, accountIDs ...string) {
+	t.Helper()
+
+	for _, accountID := range accountIDs {
+		if err := repos.Accounts.InsertAccount(tenantContext(DefaultTenantID), &models.AccountDTO{ID: accountID}); err != nil {
+			t.Fatalf("unexpected error in InsertAccount: %+v", err)
+		}
+	}
+}
+
+// tenantContext provides a context with the provided tenant, which the tenant-scoped repositories require.
+func tenantContext(tenantID string) context.Context {
+	return ctxutils.PutTenantID(context.Background(), tenantID)
+}
+
+// isHTTPError checks if the error is an HTTPError with the same custom code as the expected one.
+func isHTTPError(err error, expected *errutils.HTTPError) bool {
+	var errHTTP *errutils.HTTPError
+	return errors.As(err, &errHTTP) && errHTTP.CustomCode == expected.CustomCode
+}
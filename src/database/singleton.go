@@ -0,0 +1,49 @@
+package database
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/shivanshkc/ledgerkeep/src/configs"
+)
+
+const (
+	// driverMongo is the name of the MongoDB storage backend.
+	driverMongo = "mongo"
+	// driverPostgres is the name of the PostgreSQL storage backend.
+	driverPostgres = "postgres"
+	// driverSQLite is the name of the SQLite storage backend.
+	driverSQLite = "sqlite"
+	// driverMemory is the name of the in-memory storage backend.
+	driverMemory = "memory"
+)
+
+var (
+	// repositoriesOnce ensures the singleton is instantiated only once.
+	repositoriesOnce = &sync.Once{}
+	// repositoriesSingleton points to the singleton value.
+	repositoriesSingleton *Repositories
+)
+
+// GetRepositories provides the Repositories singleton for the configured storage backend.
+// Panic is allowed here because storage is crucial to the application.
+func GetRepositories() *Repositories {
+	repositoriesOnce.Do(func() {
+		conf := configs.Get()
+
+		switch conf.Storage.Driver {
+		// MongoDB is the default for backward compatibility with configs that do not specify a driver.
+		case driverMongo, "":
+			repositoriesSingleton = newMongoRepositories()
+		case driverPostgres:
+			repositoriesSingleton = newPostgresRepositories()
+		case driverSQLite:
+			repositoriesSingleton = newSQLiteRepositories()
+		case driverMemory:
+			repositoriesSingleton = NewMemoryRepositories()
+		default:
+			panic(fmt.Errorf("unknown storage driver: %s", conf.Storage.Driver))
+		}
+	})
+	return repositoriesSingleton
+}
@@ -0,0 +1,227 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/shivanshkc/ledgerkeep/src/logger"
+	"github.com/shivanshkc/ledgerkeep/src/models"
+	"github.com/shivanshkc/ledgerkeep/src/utils/errutils"
+)
+
+// sqlAccountRepository implements AccountRepository using a SQL database.
+type sqlAccountRepository struct {
+	db      *sql.DB
+	dialect sqlDialect
+}
+
+func (s *sqlAccountRepository) InsertAccount(ctx context.Context, account *models.AccountDTO) error {
+	log := logger.Get()
+
+	tenantID, err := getTenantID(ctx)
+	if err != nil {
+		return err
+	}
+
+	query := s.dialect.rebind("INSERT INTO accounts (tenant_id, id, name, currency) VALUES (?, ?, ?, ?)")
+	if _, err := s.db.ExecContext(ctx, query, tenantID, account.ID, account.Name, account.Currency); err != nil {
+		// Checking if the error is a primary key violation (already exists error).
+		if s.dialect.isUniqueViolation(err) {
+			return errutils.AccountAlreadyExists()
+		}
+		err = fmt.Errorf("%s ExecContext error: %w", s.dialect.name(), err)
+		log.Error(ctx, &logger.Entry{Payload: err})
+		return err
+	}
+
+	return nil
+}
+
+func (s *sqlAccountRepository) IsAccountExists(ctx context.Context, accountID string) (bool, error) {
+	log := logger.Get()
+
+	tenantID, err := getTenantID(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	var exists bool
+	query := s.dialect.rebind("SELECT EXISTS (SELECT 1 FROM accounts WHERE tenant_id = ? AND id = ?)")
+	if err := s.db.QueryRowContext(ctx, query, tenantID, accountID).Scan(&exists); err != nil {
+		err = fmt.Errorf("%s QueryRowContext error: %w", s.dialect.name(), err)
+		log.Error(ctx, &logger.Entry{Payload: err})
+		return false, err
+	}
+
+	return exists, nil
+}
+
+func (s *sqlAccountRepository) GetAccount(ctx context.Context, accountID string) (*models.AccountDTO, error) {
+	log := logger.Get()
+
+	tenantID, err := getTenantID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	query := s.dialect.rebind("SELECT id, name, currency FROM accounts WHERE tenant_id = ? AND id = ?")
+
+	account := &models.AccountDTO{}
+	row := s.db.QueryRowContext(ctx, query, tenantID, accountID)
+	if err := row.Scan(&account.ID, &account.Name, &account.Currency); err != nil {
+		// Handling the not-exists case.
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errutils.AccountNotFound()
+		}
+		err = fmt.Errorf("%s QueryRowContext error: %w", s.dialect.name(), err)
+		log.Error(ctx, &logger.Entry{Payload: err})
+		return nil, err
+	}
+
+	return account, nil
+}
+
+func (s *sqlAccountRepository) IsAccountUsed(ctx context.Context, accountID string) (bool, error) {
+	log := logger.Get()
+
+	tenantID, err := getTenantID(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	var used bool
+	query := s.dialect.rebind("SELECT EXISTS (SELECT 1 FROM transactions WHERE tenant_id = ? AND account_id = ?)")
+	if err := s.db.QueryRowContext(ctx, query, tenantID, accountID).Scan(&used); err != nil {
+		err = fmt.Errorf("%s QueryRowContext error: %w", s.dialect.name(), err)
+		log.Error(ctx, &logger.Entry{Payload: err})
+		return false, err
+	}
+
+	return used, nil
+}
+
+func (s *sqlAccountRepository) ListAccounts(ctx context.Context) ([]*models.AccountDTO, error) {
+	log := logger.Get()
+
+	tenantID, err := getTenantID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	query := s.dialect.rebind(fmt.Sprintf("SELECT id, name, currency FROM accounts WHERE tenant_id = ? ORDER BY %s",
+		s.dialect.accountsOrderColumn()))
+	rows, err := s.db.QueryContext(ctx, query, tenantID)
+	if err != nil {
+		err = fmt.Errorf("%s QueryContext error: %w", s.dialect.name(), err)
+		log.Error(ctx, &logger.Entry{Payload: err})
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	results := []*models.AccountDTO{}
+	for rows.Next() {
+		account := &models.AccountDTO{}
+		if err := rows.Scan(&account.ID, &account.Name, &account.Currency); err != nil {
+			err = fmt.Errorf("%s rows.Scan error: %w", s.dialect.name(), err)
+			log.Error(ctx, &logger.Entry{Payload: err})
+			return nil, err
+		}
+		results = append(results, account)
+	}
+
+	if err := rows.Err(); err != nil {
+		err = fmt.Errorf("%s rows.Err error: %w", s.dialect.name(), err)
+		log.Error(ctx, &logger.Entry{Payload: err})
+		return nil, err
+	}
+
+	return results, nil
+}
+
+func (s *sqlAccountRepository) GetAccountBalances(ctx context.Context) (map[string]models.Money, error) {
+	log := logger.Get()
+
+	tenantID, err := getTenantID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	query := s.dialect.rebind(`SELECT account_id, CAST(SUM(amount) AS BIGINT) FROM transactions WHERE tenant_id = ?
+		GROUP BY account_id`)
+	rows, err := s.db.QueryContext(ctx, query, tenantID)
+	if err != nil {
+		err = fmt.Errorf("%s QueryContext error: %w", s.dialect.name(), err)
+		log.Error(ctx, &logger.Entry{Payload: err})
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	balanceMap := map[string]models.Money{}
+	for rows.Next() {
+		var accountID string
+		var balance models.Money
+		if err := rows.Scan(&accountID, &balance); err != nil {
+			err = fmt.Errorf("%s rows.Scan error: %w", s.dialect.name(), err)
+			log.Error(ctx, &logger.Entry{Payload: err})
+			return nil, err
+		}
+		balanceMap[accountID] = balance
+	}
+
+	if err := rows.Err(); err != nil {
+		err = fmt.Errorf("%s rows.Err error: %w", s.dialect.name(), err)
+		log.Error(ctx, &logger.Entry{Payload: err})
+		return nil, err
+	}
+
+	return balanceMap, nil
+}
+
+func (s *sqlAccountRepository) UpdateAccount(ctx context.Context, accountID string, updates map[string]interface{}) error {
+	log := logger.Get()
+
+	tenantID, err := getTenantID(ctx)
+	if err != nil {
+		return err
+	}
+
+	setClause, args, err := buildSQLUpdateClause(updates, sqlUpdatableAccountColumns)
+	if err != nil {
+		return err
+	}
+
+	query := s.dialect.rebind(fmt.Sprintf("UPDATE accounts %s WHERE tenant_id = ? AND id = ?", setClause))
+	result, err := s.db.ExecContext(ctx, query, append(args, tenantID, accountID)...)
+	if err != nil {
+		err = fmt.Errorf("%s ExecContext error: %w", s.dialect.name(), err)
+		log.Error(ctx, &logger.Entry{Payload: err})
+		return err
+	}
+
+	return checkRowsAffected(result, errutils.AccountNotFound())
+}
+
+func (s *sqlAccountRepository) DeleteAccount(ctx context.Context, accountID string) error {
+	log := logger.Get()
+
+	tenantID, err := getTenantID(ctx)
+	if err != nil {
+		return err
+	}
+
+	query := s.dialect.rebind("DELETE FROM accounts WHERE tenant_id = ? AND id = ?")
+	result, err := s.db.ExecContext(ctx, query, tenantID, accountID)
+	if err != nil {
+		// Transactions refer to their accounts through a foreign key, if the database enforces it.
+		if s.dialect.isForeignKeyViolation(err) {
+			return errutils.AccountIsInUse()
+		}
+		err = fmt.Errorf("%s ExecContext error: %w", s.dialect.name(), err)
+		log.Error(ctx, &logger.Entry{Payload: err})
+		return err
+	}
+
+	return checkRowsAffected(result, errutils.AccountNotFound())
+}
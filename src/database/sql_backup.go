@@ -0,0 +1,144 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/shivanshkc/ledgerkeep/src/logger"
+	"github.com/shivanshkc/ledgerkeep/src/models"
+	"github.com/shivanshkc/ledgerkeep/src/utils/errutils"
+)
+
+// sqlLedgerTables are all the tables of the ledger data that have a tenant, in an order that deletes the referencing
+// rows before the referenced ones. The split lines and the budget plan allocations are deleted along with their
+// transactions and budget plans by the foreign keys.
+var sqlLedgerTables = []string{
+	"transactions",
+	"budget_plans",
+	"rules",
+	"import_profiles",
+	"recurring_templates",
+	"exchange_rates",
+	"categories",
+	"accounts",
+}
+
+// sqlBackupRepository implements BackupRepository using a SQL database.
+type sqlBackupRepository struct {
+	db      *sql.DB
+	dialect sqlDialect
+}
+
+func (s *sqlBackupRepository) ReplaceLedger(ctx context.Context, data *models.LedgerData) error {
+	log := logger.Get()
+	txRepo := &sqlTransactionRepository{db: s.db, dialect: s.dialect}
+
+	tenantID, err := getTenantID(ctx)
+	if err != nil {
+		return err
+	}
+
+	// The existing data is deleted and the new one is inserted atomically.
+	return txRepo.runInTx(ctx, func(dbTx *sql.Tx) error {
+		exec := func(query string, args ...interface{}) error {
+			if _, err := dbTx.ExecContext(ctx, s.dialect.rebind(query), args...); err != nil {
+				// The IDs are unique per tenant, so only the duplicates within the data itself conflict.
+				if s.dialect.isUniqueViolation(err) {
+					return errutils.Conflict()
+				}
+				err = fmt.Errorf("%s ExecContext error: %w", s.dialect.name(), err)
+				log.Error(ctx, &logger.Entry{Payload: err})
+				return err
+			}
+			return nil
+		}
+
+		for _, table := range sqlLedgerTables {
+			if err := exec("DELETE FROM "+table+" WHERE tenant_id = ?", tenantID); err != nil {
+				return err
+			}
+		}
+
+		// The accounts are inserted in their order, which the sequence column keeps.
+		for _, account := range data.Accounts {
+			if err := exec("INSERT INTO accounts (tenant_id, id, name, currency) VALUES (?, ?, ?, ?)", tenantID,
+				account.ID, account.Name, account.Currency); err != nil {
+				return err
+			}
+		}
+
+		for _, category := range data.Categories {
+			if err := exec(
+				"INSERT INTO categories (tenant_id, id, name, kind, budget_group) VALUES (?, ?, ?, ?, ?)",
+				tenantID, category.ID, category.Name, category.Kind, category.BudgetGroup); err != nil {
+				return err
+			}
+		}
+
+		for _, rate := range data.ExchangeRates {
+			if err := exec(`INSERT INTO exchange_rates (tenant_id, id, base, quote, rate, timestamp)
+				VALUES (?, ?, ?, ?, ?, ?)`, tenantID, rate.ID, rate.Base, rate.Quote, rate.Rate, rate.Timestamp); err != nil {
+				return err
+			}
+		}
+
+		for _, plan := range data.BudgetPlans {
+			if err := exec(`INSERT INTO budget_plans (tenant_id, id, name, currency, valid_from, valid_until)
+				VALUES (?, ?, ?, ?, ?, ?)`, tenantID, plan.ID, plan.Name, plan.Currency, plan.ValidFrom,
+				plan.ValidUntil); err != nil {
+				return err
+			}
+			for position, allocation := range plan.Allocations {
+				if err := exec(`INSERT INTO budget_plan_allocations
+					(tenant_id, plan_id, position, budget_group, percent, amount) VALUES (?, ?, ?, ?, ?, ?)`,
+					tenantID, plan.ID, position, allocation.BudgetGroup, allocation.Percent, allocation.Amount); err != nil {
+					return err
+				}
+			}
+		}
+
+		for _, template := range data.RecurringTemplates {
+			if err := exec(fmt.Sprintf(
+				"INSERT INTO recurring_templates (tenant_id, %s) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)",
+				sqlRecurringTemplateColumns), tenantID, template.ID, template.Name, template.Amount, template.AccountID,
+				template.Category, template.Notes, template.Rule, template.StartTime, template.EndTime,
+				template.NextRun); err != nil {
+				return err
+			}
+		}
+
+		for _, profile := range data.ImportProfiles {
+			if err := exec(fmt.Sprintf(
+				"INSERT INTO import_profiles (tenant_id, %s) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)",
+				sqlImportProfileColumns), tenantID, profile.ID, profile.Name, profile.AccountID, profile.Delimiter,
+				profile.DecimalSeparator, profile.DateColumn, profile.DateFormat, profile.AmountColumn,
+				profile.DebitColumn, profile.CreditColumn, profile.NotesColumn, profile.CategoryColumn,
+				profile.CreditCategory, profile.DebitCategory); err != nil {
+				return err
+			}
+		}
+
+		for _, rule := range data.Rules {
+			if err := exec(fmt.Sprintf("INSERT INTO rules (tenant_id, %s) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)",
+				sqlRuleColumns), tenantID, rule.ID, rule.Name, rule.Priority, rule.NotesPattern, rule.MinAmount,
+				rule.MaxAmount, rule.AccountID, strings.Join(rule.Weekdays, ","), rule.SetCategory, rule.SetNotes,
+				rule.AddTags); err != nil {
+				return err
+			}
+		}
+
+		for _, transaction := range data.Transactions {
+			err := txRepo.insertTransaction(ctx, dbTx, transaction.ID, transaction)
+			if s.dialect.isUniqueViolation(err) {
+				return errutils.Conflict()
+			}
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
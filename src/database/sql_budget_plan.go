@@ -0,0 +1,160 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/shivanshkc/ledgerkeep/src/logger"
+	"github.com/shivanshkc/ledgerkeep/src/models"
+	"github.com/shivanshkc/ledgerkeep/src/utils/errutils"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// sqlBudgetPlanRepository implements BudgetPlanRepository using a SQL database.
+type sqlBudgetPlanRepository struct {
+	db      *sql.DB
+	dialect sqlDialect
+}
+
+func (s *sqlBudgetPlanRepository) InsertBudgetPlan(ctx context.Context, plan *models.BudgetPlanDTO) (string, error) {
+	log := logger.Get()
+
+	tenantID, err := getTenantID(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	// The plan and its allocations are saved atomically.
+	dbTx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		err = fmt.Errorf("%s BeginTx error: %w", s.dialect.name(), err)
+		log.Error(ctx, &logger.Entry{Payload: err})
+		return "", err
+	}
+	defer func() { _ = dbTx.Rollback() }()
+
+	// Budget plan IDs are ObjectIDs, just like the ones generated by MongoDB.
+	planID := primitive.NewObjectID().Hex()
+
+	query := s.dialect.rebind(`INSERT INTO budget_plans (tenant_id, id, name, currency, valid_from, valid_until)
+		VALUES (?, ?, ?, ?, ?, ?)`)
+	if _, err := dbTx.ExecContext(ctx, query, tenantID, planID, plan.Name, plan.Currency, plan.ValidFrom,
+		plan.ValidUntil); err != nil {
+		err = fmt.Errorf("%s ExecContext error: %w", s.dialect.name(), err)
+		log.Error(ctx, &logger.Entry{Payload: err})
+		return "", err
+	}
+
+	// The position keeps the allocations in their original order.
+	query = s.dialect.rebind(`INSERT INTO budget_plan_allocations
+		(tenant_id, plan_id, position, budget_group, percent, amount) VALUES (?, ?, ?, ?, ?, ?)`)
+	for position, allocation := range plan.Allocations {
+		if _, err := dbTx.ExecContext(ctx, query, tenantID, planID, position, allocation.BudgetGroup, allocation.Percent,
+			allocation.Amount); err != nil {
+			err = fmt.Errorf("%s ExecContext error: %w", s.dialect.name(), err)
+			log.Error(ctx, &logger.Entry{Payload: err})
+			return "", err
+		}
+	}
+
+	if err := dbTx.Commit(); err != nil {
+		err = fmt.Errorf("%s Commit error: %w", s.dialect.name(), err)
+		log.Error(ctx, &logger.Entry{Payload: err})
+		return "", err
+	}
+
+	return planID, nil
+}
+
+func (s *sqlBudgetPlanRepository) ListBudgetPlans(ctx context.Context) ([]*models.BudgetPlanDTO, error) {
+	log := logger.Get()
+
+	tenantID, err := getTenantID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	query := s.dialect.rebind(
+		"SELECT id, name, currency, valid_from, valid_until FROM budget_plans WHERE tenant_id = ? ORDER BY valid_from, id")
+	rows, err := s.db.QueryContext(ctx, query, tenantID)
+	if err != nil {
+		err = fmt.Errorf("%s QueryContext error: %w", s.dialect.name(), err)
+		log.Error(ctx, &logger.Entry{Payload: err})
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	results := []*models.BudgetPlanDTO{}
+	plansByID := map[string]*models.BudgetPlanDTO{}
+	for rows.Next() {
+		plan := &models.BudgetPlanDTO{Allocations: []*models.BudgetAllocationDTO{}}
+		if err := rows.Scan(&plan.ID, &plan.Name, &plan.Currency, &plan.ValidFrom, &plan.ValidUntil); err != nil {
+			err = fmt.Errorf("%s rows.Scan error: %w", s.dialect.name(), err)
+			log.Error(ctx, &logger.Entry{Payload: err})
+			return nil, err
+		}
+		results = append(results, plan)
+		plansByID[plan.ID] = plan
+	}
+
+	if err := rows.Err(); err != nil {
+		err = fmt.Errorf("%s rows.Err error: %w", s.dialect.name(), err)
+		log.Error(ctx, &logger.Entry{Payload: err})
+		return nil, err
+	}
+
+	// There are only a few budget plans, so all of their allocations are loaded at once.
+	query = s.dialect.rebind(`SELECT plan_id, budget_group, percent, amount FROM budget_plan_allocations
+		WHERE tenant_id = ? ORDER BY plan_id, position`)
+	allocationRows, err := s.db.QueryContext(ctx, query, tenantID)
+	if err != nil {
+		err = fmt.Errorf("%s QueryContext error: %w", s.dialect.name(), err)
+		log.Error(ctx, &logger.Entry{Payload: err})
+		return nil, err
+	}
+	defer func() { _ = allocationRows.Close() }()
+
+	for allocationRows.Next() {
+		var planID string
+		allocation := &models.BudgetAllocationDTO{}
+		if err := allocationRows.Scan(&planID, &allocation.BudgetGroup, &allocation.Percent,
+			&allocation.Amount); err != nil {
+			err = fmt.Errorf("%s rows.Scan error: %w", s.dialect.name(), err)
+			log.Error(ctx, &logger.Entry{Payload: err})
+			return nil, err
+		}
+		if plan, exists := plansByID[planID]; exists {
+			plan.Allocations = append(plan.Allocations, allocation)
+		}
+	}
+
+	if err := allocationRows.Err(); err != nil {
+		err = fmt.Errorf("%s rows.Err error: %w", s.dialect.name(), err)
+		log.Error(ctx, &logger.Entry{Payload: err})
+		return nil, err
+	}
+
+	return results, nil
+}
+
+func (s *sqlBudgetPlanRepository) DeleteBudgetPlan(ctx context.Context, planID string) error {
+	log := logger.Get()
+
+	tenantID, err := getTenantID(ctx)
+	if err != nil {
+		return err
+	}
+
+	// The allocations are deleted along with the plan by the foreign key.
+	query := s.dialect.rebind("DELETE FROM budget_plans WHERE tenant_id = ? AND id = ?")
+	result, err := s.db.ExecContext(ctx, query, tenantID, planID)
+	if err != nil {
+		err = fmt.Errorf("%s ExecContext error: %w", s.dialect.name(), err)
+		log.Error(ctx, &logger.Entry{Payload: err})
+		return err
+	}
+
+	return checkRowsAffected(result, errutils.BudgetPlanNotFound())
+}
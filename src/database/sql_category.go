@@ -0,0 +1,166 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/shivanshkc/ledgerkeep/src/logger"
+	"github.com/shivanshkc/ledgerkeep/src/models"
+	"github.com/shivanshkc/ledgerkeep/src/utils/errutils"
+)
+
+// sqlCategoryRepository implements CategoryRepository using a SQL database.
+type sqlCategoryRepository struct {
+	db      *sql.DB
+	dialect sqlDialect
+}
+
+func (s *sqlCategoryRepository) InsertCategory(ctx context.Context, category *models.CategoryDTO) error {
+	log := logger.Get()
+
+	tenantID, err := getTenantID(ctx)
+	if err != nil {
+		return err
+	}
+
+	query := s.dialect.rebind("INSERT INTO categories (tenant_id, id, name, kind, budget_group) VALUES (?, ?, ?, ?, ?)")
+	if _, err := s.db.ExecContext(ctx, query, tenantID, category.ID, category.Name, category.Kind,
+		category.BudgetGroup); err != nil {
+		// Checking if the error is a primary key violation (already exists error).
+		if s.dialect.isUniqueViolation(err) {
+			return errutils.CategoryAlreadyExists()
+		}
+		err = fmt.Errorf("%s ExecContext error: %w", s.dialect.name(), err)
+		log.Error(ctx, &logger.Entry{Payload: err})
+		return err
+	}
+
+	return nil
+}
+
+func (s *sqlCategoryRepository) GetCategory(ctx context.Context, categoryID string) (*models.CategoryDTO, error) {
+	log := logger.Get()
+
+	tenantID, err := getTenantID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	query := s.dialect.rebind("SELECT id, name, kind, budget_group FROM categories WHERE tenant_id = ? AND id = ?")
+
+	category := &models.CategoryDTO{}
+	if err := s.db.QueryRowContext(ctx, query, tenantID, categoryID).Scan(&category.ID, &category.Name, &category.Kind,
+		&category.BudgetGroup); err != nil {
+		// Handling the not-exists case.
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errutils.CategoryNotFound()
+		}
+		err = fmt.Errorf("%s QueryRowContext error: %w", s.dialect.name(), err)
+		log.Error(ctx, &logger.Entry{Payload: err})
+		return nil, err
+	}
+
+	return category, nil
+}
+
+func (s *sqlCategoryRepository) ListCategories(ctx context.Context) ([]*models.CategoryDTO, error) {
+	log := logger.Get()
+
+	tenantID, err := getTenantID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	query := s.dialect.rebind("SELECT id, name, kind, budget_group FROM categories WHERE tenant_id = ? ORDER BY id")
+	rows, err := s.db.QueryContext(ctx, query, tenantID)
+	if err != nil {
+		err = fmt.Errorf("%s QueryContext error: %w", s.dialect.name(), err)
+		log.Error(ctx, &logger.Entry{Payload: err})
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	results := []*models.CategoryDTO{}
+	for rows.Next() {
+		category := &models.CategoryDTO{}
+		if err := rows.Scan(&category.ID, &category.Name, &category.Kind, &category.BudgetGroup); err != nil {
+			err = fmt.Errorf("%s rows.Scan error: %w", s.dialect.name(), err)
+			log.Error(ctx, &logger.Entry{Payload: err})
+			return nil, err
+		}
+		results = append(results, category)
+	}
+
+	if err := rows.Err(); err != nil {
+		err = fmt.Errorf("%s rows.Err error: %w", s.dialect.name(), err)
+		log.Error(ctx, &logger.Entry{Payload: err})
+		return nil, err
+	}
+
+	return results, nil
+}
+
+func (s *sqlCategoryRepository) IsCategoryUsed(ctx context.Context, categoryID string) (bool, error) {
+	log := logger.Get()
+
+	tenantID, err := getTenantID(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	var used bool
+	query := s.dialect.rebind(`SELECT EXISTS (SELECT 1 FROM transactions WHERE tenant_id = ? AND category = ?)
+		OR EXISTS (SELECT 1 FROM transaction_splits WHERE tenant_id = ? AND category = ?)`)
+	if err := s.db.QueryRowContext(ctx, query, tenantID, categoryID, tenantID, categoryID).Scan(&used); err != nil {
+		err = fmt.Errorf("%s QueryRowContext error: %w", s.dialect.name(), err)
+		log.Error(ctx, &logger.Entry{Payload: err})
+		return false, err
+	}
+
+	return used, nil
+}
+
+func (s *sqlCategoryRepository) UpdateCategory(ctx context.Context, categoryID string, updates map[string]interface{}) error {
+	log := logger.Get()
+
+	tenantID, err := getTenantID(ctx)
+	if err != nil {
+		return err
+	}
+
+	setClause, args, err := buildSQLUpdateClause(updates, sqlUpdatableCategoryColumns)
+	if err != nil {
+		return err
+	}
+
+	query := s.dialect.rebind(fmt.Sprintf("UPDATE categories %s WHERE tenant_id = ? AND id = ?", setClause))
+	result, err := s.db.ExecContext(ctx, query, append(args, tenantID, categoryID)...)
+	if err != nil {
+		err = fmt.Errorf("%s ExecContext error: %w", s.dialect.name(), err)
+		log.Error(ctx, &logger.Entry{Payload: err})
+		return err
+	}
+
+	return checkRowsAffected(result, errutils.CategoryNotFound())
+}
+
+func (s *sqlCategoryRepository) DeleteCategory(ctx context.Context, categoryID string) error {
+	log := logger.Get()
+
+	tenantID, err := getTenantID(ctx)
+	if err != nil {
+		return err
+	}
+
+	query := s.dialect.rebind("DELETE FROM categories WHERE tenant_id = ? AND id = ?")
+	result, err := s.db.ExecContext(ctx, query, tenantID, categoryID)
+	if err != nil {
+		err = fmt.Errorf("%s ExecContext error: %w", s.dialect.name(), err)
+		log.Error(ctx, &logger.Entry{Payload: err})
+		return err
+	}
+
+	return checkRowsAffected(result, errutils.CategoryNotFound())
+}
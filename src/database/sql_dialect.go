@@ -0,0 +1,43 @@
+package database
+
+import (
+	"strconv"
+	"strings"
+)
+
+// sqlDialect describes the differences between the SQL databases that the SQL repositories support.
+type sqlDialect interface {
+	// name of the database, for error messages.
+	name() string
+	// rebind converts the "?" placeholders of the query into the placeholders of the database.
+	rebind(query string) string
+	// textSearchClause translates a parsed $text search into a condition on the transaction notes.
+	textSearchClause(search *textSearch) (string, []interface{})
+	// noLimit is the LIMIT value that does not limit the number of rows.
+	noLimit() interface{}
+	// accountsOrderColumn is the column that keeps the accounts in their insertion order.
+	accountsOrderColumn() string
+	// isUniqueViolation checks if the error is a primary key or unique constraint violation.
+	isUniqueViolation(err error) bool
+	// isForeignKeyViolation checks if the error is a foreign key constraint violation.
+	isForeignKeyViolation(err error) bool
+}
+
+// rebindNumbered converts the "?" placeholders of the query into numbered placeholders like $1, $2 and so on.
+// Queries are built from constants and never contain a literal "?", so all of them are placeholders.
+func rebindNumbered(query string) string {
+	builder := &strings.Builder{}
+	builder.Grow(len(query) + 8)
+
+	argIndex := 0
+	for _, char := range query {
+		if char != '?' {
+			builder.WriteRune(char)
+			continue
+		}
+		argIndex++
+		builder.WriteString("$" + strconv.Itoa(argIndex))
+	}
+
+	return builder.String()
+}
@@ -0,0 +1,131 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/shivanshkc/ledgerkeep/src/logger"
+	"github.com/shivanshkc/ledgerkeep/src/models"
+	"github.com/shivanshkc/ledgerkeep/src/utils/errutils"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// sqlExchangeRateRepository implements ExchangeRateRepository using a SQL database.
+type sqlExchangeRateRepository struct {
+	db      *sql.DB
+	dialect sqlDialect
+}
+
+func (s *sqlExchangeRateRepository) PutExchangeRates(ctx context.Context, rates []*models.ExchangeRateDTO) error {
+	log := logger.Get()
+
+	tenantID, err := getTenantID(ctx)
+	if err != nil {
+		return err
+	}
+
+	// All the rates are saved atomically.
+	dbTx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		err = fmt.Errorf("%s BeginTx error: %w", s.dialect.name(), err)
+		log.Error(ctx, &logger.Entry{Payload: err})
+		return err
+	}
+	defer func() { _ = dbTx.Rollback() }()
+
+	// The currency pair and timestamp are unique for a tenant, so an existing rate is replaced, but keeps its ID.
+	query := s.dialect.rebind(`INSERT INTO exchange_rates (tenant_id, id, base, quote, rate, timestamp)
+		VALUES (?, ?, ?, ?, ?, ?) ON CONFLICT (tenant_id, base, quote, timestamp) DO UPDATE SET rate = excluded.rate`)
+
+	for _, rate := range rates {
+		// Exchange rate IDs are ObjectIDs, just like the ones generated by MongoDB.
+		rateID := primitive.NewObjectID().Hex()
+		if _, err := dbTx.ExecContext(ctx, query, tenantID, rateID, rate.Base, rate.Quote, rate.Rate,
+			rate.Timestamp); err != nil {
+			err = fmt.Errorf("%s ExecContext error: %w", s.dialect.name(), err)
+			log.Error(ctx, &logger.Entry{Payload: err})
+			return err
+		}
+	}
+
+	if err := dbTx.Commit(); err != nil {
+		err = fmt.Errorf("%s Commit error: %w", s.dialect.name(), err)
+		log.Error(ctx, &logger.Entry{Payload: err})
+		return err
+	}
+
+	return nil
+}
+
+func (s *sqlExchangeRateRepository) ListExchangeRates(ctx context.Context, base string, quote string) ([]*models.ExchangeRateDTO, error) {
+	log := logger.Get()
+
+	tenantID, err := getTenantID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	conditions := []string{"tenant_id = ?"}
+	args := []interface{}{tenantID}
+	if base != "" {
+		conditions = append(conditions, "base = ?")
+		args = append(args, base)
+	}
+	if quote != "" {
+		conditions = append(conditions, "quote = ?")
+		args = append(args, quote)
+	}
+
+	query := s.dialect.rebind(fmt.Sprintf(
+		"SELECT id, base, quote, rate, timestamp FROM exchange_rates WHERE %s ORDER BY timestamp, id",
+		strings.Join(conditions, " AND ")))
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		err = fmt.Errorf("%s QueryContext error: %w", s.dialect.name(), err)
+		log.Error(ctx, &logger.Entry{Payload: err})
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	results := []*models.ExchangeRateDTO{}
+	for rows.Next() {
+		rate := &models.ExchangeRateDTO{}
+		if err := rows.Scan(&rate.ID, &rate.Base, &rate.Quote, &rate.Rate, &rate.Timestamp); err != nil {
+			err = fmt.Errorf("%s rows.Scan error: %w", s.dialect.name(), err)
+			log.Error(ctx, &logger.Entry{Payload: err})
+			return nil, err
+		}
+		results = append(results, rate)
+	}
+
+	if err := rows.Err(); err != nil {
+		err = fmt.Errorf("%s rows.Err error: %w", s.dialect.name(), err)
+		log.Error(ctx, &logger.Entry{Payload: err})
+		return nil, err
+	}
+
+	return results, nil
+}
+
+func (s *sqlExchangeRateRepository) DeleteExchangeRate(ctx context.Context, rateID string) error {
+	log := logger.Get()
+
+	tenantID, err := getTenantID(ctx)
+	if err != nil {
+		return err
+	}
+
+	query := s.dialect.rebind("DELETE FROM exchange_rates WHERE tenant_id = ? AND id = ?")
+	result, err := s.db.ExecContext(ctx, query, tenantID, rateID)
+	if err != nil {
+		err = fmt.Errorf("%s ExecContext error: %w", s.dialect.name(), err)
+		log.Error(ctx, &logger.Entry{Payload: err})
+		return err
+	}
+
+	return checkRowsAffected(result, errutils.ExchangeRateNotFound())
+}
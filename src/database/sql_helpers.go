@@ -0,0 +1,275 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/shivanshkc/ledgerkeep/src/models"
+)
+
+// sqlTransactionColumns maps the database names of the transaction fields to their SQL columns.
+var sqlTransactionColumns = map[string]string{
+	"_id":         "id",
+	"amount":      "amount",
+	"timestamp":   "timestamp",
+	"account_id":  "account_id",
+	"category":    "category",
+	"notes":       "notes",
+	"transfer_id": "transfer_id",
+	"external_id": "external_id",
+	"tags":        "tags",
+}
+
+// sqlUpdatableTransactionColumns maps the database names of the updatable transaction fields to their SQL columns.
+var sqlUpdatableTransactionColumns = map[string]string{
+	"amount":     "amount",
+	"timestamp":  "timestamp",
+	"account_id": "account_id",
+	"category":   "category",
+	"notes":      "notes",
+	"tags":       "tags",
+}
+
+// sqlInsertTransactionQuery inserts a transaction. Its arguments are provided by getSQLInsertTransactionArgs.
+const sqlInsertTransactionQuery = `INSERT INTO transactions
+	(tenant_id, id, amount, timestamp, account_id, category, notes, transfer_id, external_id, tags)
+	VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+
+// sqlUpdatableAccountColumns maps the database names of the updatable account fields to their SQL columns.
+var sqlUpdatableAccountColumns = map[string]string{"name": "name"}
+
+// sqlUpdatableCategoryColumns maps the database names of the updatable category fields to their SQL columns.
+var sqlUpdatableCategoryColumns = map[string]string{
+	"name":         "name",
+	"kind":         "kind",
+	"budget_group": "budget_group",
+}
+
+// sqlComparisonOperators maps the supported MongoDB comparison operators to their SQL counterparts.
+var sqlComparisonOperators = map[string]string{
+	"$eq":  "=",
+	"$ne":  "<>",
+	"$gt":  ">",
+	"$gte": ">=",
+	"$lt":  "<",
+	"$lte": "<=",
+}
+
+// textSearchClauseFunc translates a parsed $text search into a SQL condition and its arguments.
+// It is provided by each SQL backend as full text search differs between SQL databases.
+type textSearchClauseFunc func(search *textSearch) (string, []interface{})
+
+// buildSQLWhereClause translates a MongoDB style transaction filter into a SQL WHERE clause and its arguments.
+// The clause uses "?" placeholders. It always limits the transactions to the provided tenant, even if the filter has
+// no conditions.
+//
+// The same subset of the MongoDB query language is supported as by the in-memory backend.
+func buildSQLWhereClause(tenantID string, filter map[string]interface{},
+	textSearchClause textSearchClauseFunc) (string, []interface{}, error) {
+	// Sorting the fields so the same filter always produces the same query.
+	fields := make([]string, 0, len(filter))
+	for field := range filter {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	conditions := []string{"tenant_id = ?"}
+	args := []interface{}{tenantID}
+
+	for _, field := range fields {
+		condition := filter[field]
+
+		// Handling the full text search.
+		if field == "$text" {
+			search, err := getTextSearchString(condition)
+			if err != nil {
+				return "", nil, err
+			}
+			textCondition, textArgs := textSearchClause(parseTextSearch(search))
+			conditions = append(conditions, textCondition)
+			args = append(args, textArgs...)
+			continue
+		}
+
+		column, exists := sqlTransactionColumns[field]
+		if !exists {
+			return "", nil, fmt.Errorf("unsupported filter field: %s", field)
+		}
+
+		// If the condition is not an operator map, it is an implicit equality check.
+		operators, isOperatorMap := condition.(map[string]interface{})
+		if !isOperatorMap {
+			operators = map[string]interface{}{"$eq": condition}
+		}
+
+		// Sorting the operators for the same reason as the fields.
+		operatorNames := make([]string, 0, len(operators))
+		for operator := range operators {
+			operatorNames = append(operatorNames, operator)
+		}
+		sort.Strings(operatorNames)
+
+		for _, operator := range operatorNames {
+			sqlOperator, exists := sqlComparisonOperators[operator]
+			if !exists {
+				return "", nil, fmt.Errorf("unsupported filter operator: %s", operator)
+			}
+			conditions = append(conditions, fmt.Sprintf("%s %s ?", column, sqlOperator))
+			args = append(args, operators[operator])
+		}
+	}
+
+	return "WHERE " + strings.Join(conditions, " AND "), args, nil
+}
+
+// buildSQLOrderByClause creates the ORDER BY clause for listing transactions.
+// The second sort column is always the ID, which takes the same order as the first one.
+func buildSQLOrderByClause(sortField string, sortOrder int) (string, error) {
+	direction := "ASC"
+	if sortOrder < 0 {
+		direction = "DESC"
+	}
+
+	if sortField == "" {
+		return fmt.Sprintf("ORDER BY id %s", direction), nil
+	}
+
+	column, exists := sqlTransactionColumns[sortField]
+	if !exists {
+		return "", fmt.Errorf("unsupported sort field: %s", sortField)
+	}
+
+	return fmt.Sprintf("ORDER BY %s %s, id %s", column, direction, direction), nil
+}
+
+// getSQLTransactionColumns provides the SQL columns for the required transaction fields.
+// The ID is always included, like in MongoDB projections. If no fields are specified, all columns are included.
+func getSQLTransactionColumns(requiredFields []string) ([]string, error) {
+	if len(requiredFields) == 0 {
+		requiredFields = []string{"amount", "timestamp", "account_id", "category", "notes", "transfer_id", "external_id",
+			"tags"}
+	}
+
+	columns := []string{"id"}
+	for _, field := range requiredFields {
+		column, exists := sqlTransactionColumns[field]
+		if !exists {
+			return nil, fmt.Errorf("unsupported projection field: %s", field)
+		}
+		if column != "id" {
+			columns = append(columns, column)
+		}
+	}
+
+	return columns, nil
+}
+
+// getTransactionScanTargets provides the pointers to the transaction fields that correspond to the columns.
+func getTransactionScanTargets(transaction *models.TransactionDTO, columns []string) []interface{} {
+	targets := make([]interface{}, len(columns))
+	for idx, column := range columns {
+		switch column {
+		case "id":
+			targets[idx] = &transaction.ID
+		case "amount":
+			targets[idx] = &transaction.Amount
+		case "timestamp":
+			targets[idx] = &transaction.Timestamp
+		case "account_id":
+			targets[idx] = &transaction.AccountID
+		case "category":
+			targets[idx] = &transaction.Category
+		case "notes":
+			targets[idx] = &transaction.Notes
+		case "transfer_id":
+			targets[idx] = &transaction.TransferID
+		case "external_id":
+			targets[idx] = &transaction.ExternalID
+		case "tags":
+			targets[idx] = &transaction.Tags
+		}
+	}
+	return targets
+}
+
+// getSQLInsertTransactionArgs provides the arguments of the sqlInsertTransactionQuery.
+func getSQLInsertTransactionArgs(tenantID string, transactionID string,
+	transaction *models.TransactionDTO) []interface{} {
+	return []interface{}{tenantID, transactionID, transaction.Amount, transaction.Timestamp, transaction.AccountID,
+		transaction.Category, transaction.Notes, transaction.TransferID, transaction.ExternalID, transaction.Tags}
+}
+
+// excludeSplitsField removes the split lines from the required transaction fields, as they are not a column.
+// The boolean is true if the split lines are required, which is also the case if no fields are specified.
+func excludeSplitsField(requiredFields []string) ([]string, bool) {
+	if len(requiredFields) == 0 {
+		return nil, true
+	}
+
+	fields := make([]string, 0, len(requiredFields))
+	var withSplits bool
+	for _, field := range requiredFields {
+		if field == "splits" {
+			withSplits = true
+			continue
+		}
+		fields = append(fields, field)
+	}
+
+	// An empty list would include all the columns, while only the ID is required.
+	if len(fields) == 0 {
+		fields = []string{"_id"}
+	}
+	return fields, withSplits
+}
+
+// sqlPlaceholders provides a comma separated list of the provided number of "?" placeholders.
+func sqlPlaceholders(count int) string {
+	placeholders := make([]string, count)
+	for idx := range placeholders {
+		placeholders[idx] = "?"
+	}
+	return strings.Join(placeholders, ", ")
+}
+
+// buildSQLUpdateClause translates the updates map into a SQL SET clause and its arguments.
+func buildSQLUpdateClause(updates map[string]interface{}, allowedColumns map[string]string) (string, []interface{}, error) {
+	if len(updates) == 0 {
+		return "", nil, fmt.Errorf("no updates provided")
+	}
+
+	fields := make([]string, 0, len(updates))
+	for field := range updates {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	assignments := make([]string, len(fields))
+	args := make([]interface{}, len(fields))
+
+	for idx, field := range fields {
+		column, exists := allowedColumns[field]
+		if !exists {
+			return "", nil, fmt.Errorf("unsupported update field: %s", field)
+		}
+		assignments[idx] = fmt.Sprintf("%s = ?", column)
+		args[idx] = updates[field]
+	}
+
+	return "SET " + strings.Join(assignments, ", "), args, nil
+}
+
+// checkRowsAffected returns the notFoundErr if the SQL statement did not affect any rows.
+func checkRowsAffected(result sql.Result, notFoundErr error) error {
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error in RowsAffected: %w", err)
+	}
+
+	if affected == 0 {
+		return notFoundErr
+	}
+	return nil
+}
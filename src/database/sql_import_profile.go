@@ -0,0 +1,143 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/shivanshkc/ledgerkeep/src/logger"
+	"github.com/shivanshkc/ledgerkeep/src/models"
+	"github.com/shivanshkc/ledgerkeep/src/utils/errutils"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// sqlImportProfileColumns are the columns of the import_profiles table, in the order of scanImportProfile.
+const sqlImportProfileColumns = "id, name, account_id, delimiter, decimal_separator, date_column, date_format, " +
+	"amount_column, debit_column, credit_column, notes_column, category_column, credit_category, debit_category"
+
+// sqlImportProfileRepository implements ImportProfileRepository using a SQL database.
+type sqlImportProfileRepository struct {
+	db      *sql.DB
+	dialect sqlDialect
+}
+
+func (s *sqlImportProfileRepository) InsertImportProfile(ctx context.Context, profile *models.ImportProfileDTO) (string, error) {
+	log := logger.Get()
+
+	tenantID, err := getTenantID(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	// Import profile IDs are ObjectIDs, just like the ones generated by MongoDB.
+	profileID := primitive.NewObjectID().Hex()
+
+	query := s.dialect.rebind(fmt.Sprintf(
+		"INSERT INTO import_profiles (tenant_id, %s) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)",
+		sqlImportProfileColumns))
+	if _, err := s.db.ExecContext(ctx, query, tenantID, profileID, profile.Name, profile.AccountID, profile.Delimiter,
+		profile.DecimalSeparator, profile.DateColumn, profile.DateFormat, profile.AmountColumn, profile.DebitColumn,
+		profile.CreditColumn, profile.NotesColumn, profile.CategoryColumn, profile.CreditCategory,
+		profile.DebitCategory); err != nil {
+		err = fmt.Errorf("%s ExecContext error: %w", s.dialect.name(), err)
+		log.Error(ctx, &logger.Entry{Payload: err})
+		return "", err
+	}
+
+	return profileID, nil
+}
+
+func (s *sqlImportProfileRepository) GetImportProfile(ctx context.Context, profileID string) (*models.ImportProfileDTO, error) {
+	log := logger.Get()
+
+	tenantID, err := getTenantID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	query := s.dialect.rebind(fmt.Sprintf("SELECT %s FROM import_profiles WHERE tenant_id = ? AND id = ?",
+		sqlImportProfileColumns))
+
+	profile, err := scanImportProfile(s.db.QueryRowContext(ctx, query, tenantID, profileID))
+	if err != nil {
+		// Handling the not-exists case.
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errutils.ImportProfileNotFound()
+		}
+		err = fmt.Errorf("%s QueryRowContext error: %w", s.dialect.name(), err)
+		log.Error(ctx, &logger.Entry{Payload: err})
+		return nil, err
+	}
+
+	return profile, nil
+}
+
+func (s *sqlImportProfileRepository) ListImportProfiles(ctx context.Context) ([]*models.ImportProfileDTO, error) {
+	log := logger.Get()
+
+	tenantID, err := getTenantID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	query := s.dialect.rebind(fmt.Sprintf("SELECT %s FROM import_profiles WHERE tenant_id = ? ORDER BY id",
+		sqlImportProfileColumns))
+	rows, err := s.db.QueryContext(ctx, query, tenantID)
+	if err != nil {
+		err = fmt.Errorf("%s QueryContext error: %w", s.dialect.name(), err)
+		log.Error(ctx, &logger.Entry{Payload: err})
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	results := []*models.ImportProfileDTO{}
+	for rows.Next() {
+		profile, err := scanImportProfile(rows)
+		if err != nil {
+			err = fmt.Errorf("%s rows.Scan error: %w", s.dialect.name(), err)
+			log.Error(ctx, &logger.Entry{Payload: err})
+			return nil, err
+		}
+		results = append(results, profile)
+	}
+
+	if err := rows.Err(); err != nil {
+		err = fmt.Errorf("%s rows.Err error: %w", s.dialect.name(), err)
+		log.Error(ctx, &logger.Entry{Payload: err})
+		return nil, err
+	}
+
+	return results, nil
+}
+
+func (s *sqlImportProfileRepository) DeleteImportProfile(ctx context.Context, profileID string) error {
+	log := logger.Get()
+
+	tenantID, err := getTenantID(ctx)
+	if err != nil {
+		return err
+	}
+
+	query := s.dialect.rebind("DELETE FROM import_profiles WHERE tenant_id = ? AND id = ?")
+	result, err := s.db.ExecContext(ctx, query, tenantID, profileID)
+	if err != nil {
+		err = fmt.Errorf("%s ExecContext error: %w", s.dialect.name(), err)
+		log.Error(ctx, &logger.Entry{Payload: err})
+		return err
+	}
+
+	return checkRowsAffected(result, errutils.ImportProfileNotFound())
+}
+
+// scanImportProfile scans a row of the sqlImportProfileColumns into an import profile.
+func scanImportProfile(row sqlRowScanner) (*models.ImportProfileDTO, error) {
+	profile := &models.ImportProfileDTO{}
+	if err := row.Scan(&profile.ID, &profile.Name, &profile.AccountID, &profile.Delimiter, &profile.DecimalSeparator,
+		&profile.DateColumn, &profile.DateFormat, &profile.AmountColumn, &profile.DebitColumn, &profile.CreditColumn,
+		&profile.NotesColumn, &profile.CategoryColumn, &profile.CreditCategory, &profile.DebitCategory); err != nil {
+		return nil, err
+	}
+	return profile, nil
+}
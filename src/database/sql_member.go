@@ -0,0 +1,125 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/shivanshkc/ledgerkeep/src/logger"
+	"github.com/shivanshkc/ledgerkeep/src/models"
+	"github.com/shivanshkc/ledgerkeep/src/utils/errutils"
+)
+
+// sqlMemberColumns are the columns of the ledger_members table, in the order of scanMember.
+const sqlMemberColumns = "ledger_id, user_id, role, created_at"
+
+// sqlMemberRepository implements MemberRepository using a SQL database.
+type sqlMemberRepository struct {
+	db      *sql.DB
+	dialect sqlDialect
+}
+
+func (s *sqlMemberRepository) InsertMember(ctx context.Context, member *models.MemberDTO) error {
+	log := logger.Get()
+
+	query := s.dialect.rebind(fmt.Sprintf("INSERT INTO ledger_members (%s) VALUES (?, ?, ?, ?)", sqlMemberColumns))
+	if _, err := s.db.ExecContext(ctx, query, member.LedgerID, member.UserID, member.Role,
+		member.CreatedAt); err != nil {
+		// Checking if the error is a primary key violation (already exists error).
+		if s.dialect.isUniqueViolation(err) {
+			return errutils.MemberAlreadyExists()
+		}
+		err = fmt.Errorf("%s ExecContext error: %w", s.dialect.name(), err)
+		log.Error(ctx, &logger.Entry{Payload: err})
+		return err
+	}
+
+	return nil
+}
+
+func (s *sqlMemberRepository) GetMember(ctx context.Context, ledgerID string, userID string) (*models.MemberDTO, error) {
+	log := logger.Get()
+
+	query := s.dialect.rebind(fmt.Sprintf("SELECT %s FROM ledger_members WHERE ledger_id = ? AND user_id = ?",
+		sqlMemberColumns))
+
+	member, err := scanMember(s.db.QueryRowContext(ctx, query, ledgerID, userID))
+	if err != nil {
+		// Handling the not-exists case.
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errutils.MemberNotFound()
+		}
+		err = fmt.Errorf("%s QueryRowContext error: %w", s.dialect.name(), err)
+		log.Error(ctx, &logger.Entry{Payload: err})
+		return nil, err
+	}
+
+	return member, nil
+}
+
+func (s *sqlMemberRepository) ListLedgerMembers(ctx context.Context, ledgerID string) ([]*models.MemberDTO, error) {
+	query := fmt.Sprintf("SELECT %s FROM ledger_members WHERE ledger_id = ? ORDER BY user_id", sqlMemberColumns)
+	return s.listMembers(ctx, query, ledgerID)
+}
+
+func (s *sqlMemberRepository) ListUserMemberships(ctx context.Context, userID string) ([]*models.MemberDTO, error) {
+	query := fmt.Sprintf("SELECT %s FROM ledger_members WHERE user_id = ? ORDER BY ledger_id", sqlMemberColumns)
+	return s.listMembers(ctx, query, userID)
+}
+
+func (s *sqlMemberRepository) DeleteMember(ctx context.Context, ledgerID string, userID string) error {
+	log := logger.Get()
+
+	query := s.dialect.rebind("DELETE FROM ledger_members WHERE ledger_id = ? AND user_id = ?")
+	result, err := s.db.ExecContext(ctx, query, ledgerID, userID)
+	if err != nil {
+		err = fmt.Errorf("%s ExecContext error: %w", s.dialect.name(), err)
+		log.Error(ctx, &logger.Entry{Payload: err})
+		return err
+	}
+
+	return checkRowsAffected(result, errutils.MemberNotFound())
+}
+
+// listMembers runs the query, which selects the sqlMemberColumns, and provides the resulting memberships.
+func (s *sqlMemberRepository) listMembers(ctx context.Context, query string, args ...interface{}) ([]*models.MemberDTO,
+	error) {
+	log := logger.Get()
+
+	rows, err := s.db.QueryContext(ctx, s.dialect.rebind(query), args...)
+	if err != nil {
+		err = fmt.Errorf("%s QueryContext error: %w", s.dialect.name(), err)
+		log.Error(ctx, &logger.Entry{Payload: err})
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	results := []*models.MemberDTO{}
+	for rows.Next() {
+		member, err := scanMember(rows)
+		if err != nil {
+			err = fmt.Errorf("%s rows.Scan error: %w", s.dialect.name(), err)
+			log.Error(ctx, &logger.Entry{Payload: err})
+			return nil, err
+		}
+		results = append(results, member)
+	}
+
+	if err := rows.Err(); err != nil {
+		err = fmt.Errorf("%s rows.Err error: %w", s.dialect.name(), err)
+		log.Error(ctx, &logger.Entry{Payload: err})
+		return nil, err
+	}
+
+	return results, nil
+}
+
+// scanMember scans a row of the sqlMemberColumns into a membership.
+func scanMember(row sqlRowScanner) (*models.MemberDTO, error) {
+	member := &models.MemberDTO{}
+	if err := row.Scan(&member.LedgerID, &member.UserID, &member.Role, &member.CreatedAt); err != nil {
+		return nil, err
+	}
+	return member, nil
+}
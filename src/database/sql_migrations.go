@@ -0,0 +1,96 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/shivanshkc/ledgerkeep/src/logger"
+)
+
+// sqlMigration is a versioned change to the schema of a SQL database.
+type sqlMigration struct {
+	// Version is the schema version after the migration. Versions must start at 1 and increase by 1.
+	Version int
+	// Description is a short summary of the migration for the logs.
+	Description string
+	// Statements are executed in order, all within a single database transaction.
+	Statements []string
+}
+
+// runSQLMigrations brings the database schema up-to-date by applying all the migrations that are newer than
+// the current schema version. The applied versions are recorded in the "schema_migrations" table.
+func runSQLMigrations(ctx context.Context, db *sql.DB, migrations []sqlMigration) error {
+	log := logger.Get()
+
+	createTableQuery := `CREATE TABLE IF NOT EXISTS schema_migrations (
+		version    INTEGER PRIMARY KEY,
+		applied_at BIGINT NOT NULL
+	)`
+
+	if _, err := db.ExecContext(ctx, createTableQuery); err != nil {
+		return fmt.Errorf("failed to create schema_migrations table: %w", err)
+	}
+
+	for idx, migration := range migrations {
+		if migration.Version != idx+1 {
+			return fmt.Errorf("migration at index %d has version %d, expected %d", idx, migration.Version, idx+1)
+		}
+
+		applied, err := applySQLMigration(ctx, db, migration)
+		if err != nil {
+			return fmt.Errorf("failed to apply migration %d (%s): %w", migration.Version, migration.Description, err)
+		}
+
+		if applied {
+			log.Info(ctx, &logger.Entry{
+				Payload: fmt.Sprintf("Applied migration %d: %s", migration.Version, migration.Description),
+			})
+		}
+	}
+
+	return nil
+}
+
+// applySQLMigration applies the migration in a database transaction, unless it is already applied.
+// It returns true if the migration was applied by this call.
+func applySQLMigration(ctx context.Context, db *sql.DB, migration sqlMigration) (bool, error) {
+	dbTx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, fmt.Errorf("error in BeginTx: %w", err)
+	}
+	// Rollback is a no-op if the transaction is committed.
+	defer func() { _ = dbTx.Rollback() }()
+
+	// The version check happens inside the transaction, so concurrent runners do not apply the same migration.
+	var currentVersion int
+	if err := dbTx.QueryRowContext(ctx, "SELECT COALESCE(MAX(version), 0) FROM schema_migrations").
+		Scan(&currentVersion); err != nil {
+		return false, fmt.Errorf("failed to read the current schema version: %w", err)
+	}
+
+	if currentVersion >= migration.Version {
+		return false, nil
+	}
+
+	for _, statement := range migration.Statements {
+		if _, err := dbTx.ExecContext(ctx, statement); err != nil {
+			return false, fmt.Errorf("error in ExecContext: %w", err)
+		}
+	}
+
+	// Only integers are involved here, so the query does not depend on the placeholder style of the database.
+	recordQuery := fmt.Sprintf("INSERT INTO schema_migrations (version, applied_at) VALUES (%d, %d)",
+		migration.Version, time.Now().Unix())
+
+	if _, err := dbTx.ExecContext(ctx, recordQuery); err != nil {
+		return false, fmt.Errorf("failed to record the migration: %w", err)
+	}
+
+	if err := dbTx.Commit(); err != nil {
+		return false, fmt.Errorf("error in Commit: %w", err)
+	}
+
+	return true, nil
+}
@@ -0,0 +1,215 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/shivanshkc/ledgerkeep/src/logger"
+	"github.com/shivanshkc/ledgerkeep/src/models"
+	"github.com/shivanshkc/ledgerkeep/src/utils/errutils"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// sqlRecurringTemplateColumns are the columns of the recurring_templates table, in the order of scanRecurringTemplate.
+const sqlRecurringTemplateColumns = "id, name, amount, account_id, category, notes, rule, start_time, end_time, next_run"
+
+// sqlRecurringTemplateRepository implements RecurringTemplateRepository using a SQL database.
+type sqlRecurringTemplateRepository struct {
+	db      *sql.DB
+	dialect sqlDialect
+}
+
+func (s *sqlRecurringTemplateRepository) InsertRecurringTemplate(ctx context.Context,
+	template *models.RecurringTemplateDTO) (string, error) {
+	log := logger.Get()
+
+	tenantID, err := getTenantID(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	// Recurring template IDs are ObjectIDs, just like the ones generated by MongoDB.
+	templateID := primitive.NewObjectID().Hex()
+
+	query := s.dialect.rebind(fmt.Sprintf(
+		"INSERT INTO recurring_templates (tenant_id, %s) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)",
+		sqlRecurringTemplateColumns))
+	if _, err := s.db.ExecContext(ctx, query, tenantID, templateID, template.Name, template.Amount, template.AccountID,
+		template.Category, template.Notes, template.Rule, template.StartTime, template.EndTime,
+		template.NextRun); err != nil {
+		err = fmt.Errorf("%s ExecContext error: %w", s.dialect.name(), err)
+		log.Error(ctx, &logger.Entry{Payload: err})
+		return "", err
+	}
+
+	return templateID, nil
+}
+
+func (s *sqlRecurringTemplateRepository) GetRecurringTemplate(ctx context.Context, templateID string) (*models.RecurringTemplateDTO, error) {
+	log := logger.Get()
+
+	tenantID, err := getTenantID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	query := s.dialect.rebind(fmt.Sprintf(
+		"SELECT %s FROM recurring_templates WHERE tenant_id = ? AND id = ?", sqlRecurringTemplateColumns))
+
+	template, err := scanRecurringTemplate(s.db.QueryRowContext(ctx, query, tenantID, templateID))
+	if err != nil {
+		// Handling the not-exists case.
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errutils.RecurringTemplateNotFound()
+		}
+		err = fmt.Errorf("%s QueryRowContext error: %w", s.dialect.name(), err)
+		log.Error(ctx, &logger.Entry{Payload: err})
+		return nil, err
+	}
+
+	return template, nil
+}
+
+func (s *sqlRecurringTemplateRepository) ListRecurringTemplates(ctx context.Context) ([]*models.RecurringTemplateDTO, error) {
+	log := logger.Get()
+
+	tenantID, err := getTenantID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	query := s.dialect.rebind(fmt.Sprintf(
+		"SELECT %s FROM recurring_templates WHERE tenant_id = ? ORDER BY id", sqlRecurringTemplateColumns))
+	rows, err := s.db.QueryContext(ctx, query, tenantID)
+	if err != nil {
+		err = fmt.Errorf("%s QueryContext error: %w", s.dialect.name(), err)
+		log.Error(ctx, &logger.Entry{Payload: err})
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	results := []*models.RecurringTemplateDTO{}
+	for rows.Next() {
+		template, err := scanRecurringTemplate(rows)
+		if err != nil {
+			err = fmt.Errorf("%s rows.Scan error: %w", s.dialect.name(), err)
+			log.Error(ctx, &logger.Entry{Payload: err})
+			return nil, err
+		}
+		results = append(results, template)
+	}
+
+	if err := rows.Err(); err != nil {
+		err = fmt.Errorf("%s rows.Err error: %w", s.dialect.name(), err)
+		log.Error(ctx, &logger.Entry{Payload: err})
+		return nil, err
+	}
+
+	return results, nil
+}
+
+func (s *sqlRecurringTemplateRepository) DeleteRecurringTemplate(ctx context.Context, templateID string) error {
+	log := logger.Get()
+
+	tenantID, err := getTenantID(ctx)
+	if err != nil {
+		return err
+	}
+
+	query := s.dialect.rebind("DELETE FROM recurring_templates WHERE tenant_id = ? AND id = ?")
+	result, err := s.db.ExecContext(ctx, query, tenantID, templateID)
+	if err != nil {
+		err = fmt.Errorf("%s ExecContext error: %w", s.dialect.name(), err)
+		log.Error(ctx, &logger.Entry{Payload: err})
+		return err
+	}
+
+	return checkRowsAffected(result, errutils.RecurringTemplateNotFound())
+}
+
+// AdvanceRecurringTemplate moves the next run time and inserts the transactions in a single database transaction.
+// The conditional update of the next run time makes the concurrent runs wait for each other, so only one of them
+// inserts the transactions.
+func (s *sqlRecurringTemplateRepository) AdvanceRecurringTemplate(ctx context.Context, templateID string,
+	currentNextRun int64, nextRun int64, transactions []*models.TransactionDTO) (bool, error) {
+	log := logger.Get()
+
+	tenantID, err := getTenantID(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	dbTx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		err = fmt.Errorf("%s BeginTx error: %w", s.dialect.name(), err)
+		log.Error(ctx, &logger.Entry{Payload: err})
+		return false, err
+	}
+	defer func() { _ = dbTx.Rollback() }()
+
+	query := s.dialect.rebind(
+		"UPDATE recurring_templates SET next_run = ? WHERE tenant_id = ? AND id = ? AND next_run = ?")
+	result, err := dbTx.ExecContext(ctx, query, nextRun, tenantID, templateID, currentNextRun)
+	if err != nil {
+		err = fmt.Errorf("%s ExecContext error: %w", s.dialect.name(), err)
+		log.Error(ctx, &logger.Entry{Payload: err})
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		err = fmt.Errorf("%s RowsAffected error: %w", s.dialect.name(), err)
+		log.Error(ctx, &logger.Entry{Payload: err})
+		return false, err
+	}
+
+	// Telling apart a missing template from one that has been advanced already.
+	if affected == 0 {
+		var exists bool
+		query := s.dialect.rebind("SELECT EXISTS (SELECT 1 FROM recurring_templates WHERE tenant_id = ? AND id = ?)")
+		if err := dbTx.QueryRowContext(ctx, query, tenantID, templateID).Scan(&exists); err != nil {
+			err = fmt.Errorf("%s QueryRowContext error: %w", s.dialect.name(), err)
+			log.Error(ctx, &logger.Entry{Payload: err})
+			return false, err
+		}
+		if !exists {
+			return false, errutils.RecurringTemplateNotFound()
+		}
+		return false, nil
+	}
+
+	// The transactions are inserted exactly like the ones of the InsertTransaction operation.
+	transactionRepo := &sqlTransactionRepository{db: s.db, dialect: s.dialect}
+	for _, transaction := range transactions {
+		transactionID := primitive.NewObjectID().Hex()
+		if err := transactionRepo.insertTransaction(ctx, dbTx, transactionID, transaction); err != nil {
+			return false, err
+		}
+	}
+
+	if err := dbTx.Commit(); err != nil {
+		err = fmt.Errorf("%s Commit error: %w", s.dialect.name(), err)
+		log.Error(ctx, &logger.Entry{Payload: err})
+		return false, err
+	}
+
+	return true, nil
+}
+
+// sqlRowScanner is implemented by both *sql.Row and *sql.Rows.
+type sqlRowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanRecurringTemplate scans a row of the sqlRecurringTemplateColumns into a recurring template.
+func scanRecurringTemplate(row sqlRowScanner) (*models.RecurringTemplateDTO, error) {
+	template := &models.RecurringTemplateDTO{}
+	if err := row.Scan(&template.ID, &template.Name, &template.Amount, &template.AccountID, &template.Category,
+		&template.Notes, &template.Rule, &template.StartTime, &template.EndTime, &template.NextRun); err != nil {
+		return nil, err
+	}
+	return template, nil
+}
@@ -0,0 +1,120 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/shivanshkc/ledgerkeep/src/logger"
+	"github.com/shivanshkc/ledgerkeep/src/models"
+	"github.com/shivanshkc/ledgerkeep/src/utils/errutils"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// sqlRuleColumns are the columns of the rules table, in the order of scanRule.
+const sqlRuleColumns = "id, name, priority, notes_pattern, min_amount, max_amount, account_id, weekdays, " +
+	"set_category, set_notes, add_tags"
+
+// sqlRuleRepository implements RuleRepository using a SQL database.
+type sqlRuleRepository struct {
+	db      *sql.DB
+	dialect sqlDialect
+}
+
+func (s *sqlRuleRepository) InsertRule(ctx context.Context, rule *models.RuleDTO) (string, error) {
+	log := logger.Get()
+
+	tenantID, err := getTenantID(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	// Rule IDs are ObjectIDs, just like the ones generated by MongoDB.
+	ruleID := primitive.NewObjectID().Hex()
+
+	query := s.dialect.rebind(fmt.Sprintf(
+		"INSERT INTO rules (tenant_id, %s) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)", sqlRuleColumns))
+	if _, err := s.db.ExecContext(ctx, query, tenantID, ruleID, rule.Name, rule.Priority, rule.NotesPattern, rule.MinAmount,
+		rule.MaxAmount, rule.AccountID, strings.Join(rule.Weekdays, ","), rule.SetCategory, rule.SetNotes,
+		rule.AddTags); err != nil {
+		err = fmt.Errorf("%s ExecContext error: %w", s.dialect.name(), err)
+		log.Error(ctx, &logger.Entry{Payload: err})
+		return "", err
+	}
+
+	return ruleID, nil
+}
+
+func (s *sqlRuleRepository) ListRules(ctx context.Context) ([]*models.RuleDTO, error) {
+	log := logger.Get()
+
+	tenantID, err := getTenantID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	query := s.dialect.rebind(fmt.Sprintf("SELECT %s FROM rules WHERE tenant_id = ? ORDER BY priority, id",
+		sqlRuleColumns))
+	rows, err := s.db.QueryContext(ctx, query, tenantID)
+	if err != nil {
+		err = fmt.Errorf("%s QueryContext error: %w", s.dialect.name(), err)
+		log.Error(ctx, &logger.Entry{Payload: err})
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	results := []*models.RuleDTO{}
+	for rows.Next() {
+		rule, err := scanRule(rows)
+		if err != nil {
+			err = fmt.Errorf("%s rows.Scan error: %w", s.dialect.name(), err)
+			log.Error(ctx, &logger.Entry{Payload: err})
+			return nil, err
+		}
+		results = append(results, rule)
+	}
+
+	if err := rows.Err(); err != nil {
+		err = fmt.Errorf("%s rows.Err error: %w", s.dialect.name(), err)
+		log.Error(ctx, &logger.Entry{Payload: err})
+		return nil, err
+	}
+
+	return results, nil
+}
+
+func (s *sqlRuleRepository) DeleteRule(ctx context.Context, ruleID string) error {
+	log := logger.Get()
+
+	tenantID, err := getTenantID(ctx)
+	if err != nil {
+		return err
+	}
+
+	query := s.dialect.rebind("DELETE FROM rules WHERE tenant_id = ? AND id = ?")
+	result, err := s.db.ExecContext(ctx, query, tenantID, ruleID)
+	if err != nil {
+		err = fmt.Errorf("%s ExecContext error: %w", s.dialect.name(), err)
+		log.Error(ctx, &logger.Entry{Payload: err})
+		return err
+	}
+
+	return checkRowsAffected(result, errutils.RuleNotFound())
+}
+
+// scanRule scans a row of the sqlRuleColumns into a rule.
+func scanRule(row sqlRowScanner) (*models.RuleDTO, error) {
+	rule := &models.RuleDTO{}
+	var weekdays string
+	if err := row.Scan(&rule.ID, &rule.Name, &rule.Priority, &rule.NotesPattern, &rule.MinAmount, &rule.MaxAmount,
+		&rule.AccountID, &weekdays, &rule.SetCategory, &rule.SetNotes, &rule.AddTags); err != nil {
+		return nil, err
+	}
+
+	if weekdays != "" {
+		rule.Weekdays = strings.Split(weekdays, ",")
+	}
+	return rule, nil
+}
@@ -0,0 +1,128 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/shivanshkc/ledgerkeep/src/logger"
+	"github.com/shivanshkc/ledgerkeep/src/models"
+	"github.com/shivanshkc/ledgerkeep/src/utils/errutils"
+)
+
+// sqlTokenColumns are the columns of the api_tokens table, in the order of scanToken.
+const sqlTokenColumns = "id, user_id, name, scopes, token_hash, expires_at, last_used_at, created_at"
+
+// sqlTokenRepository implements TokenRepository using a SQL database.
+type sqlTokenRepository struct {
+	db      *sql.DB
+	dialect sqlDialect
+}
+
+func (s *sqlTokenRepository) InsertToken(ctx context.Context, token *models.TokenDTO) error {
+	log := logger.Get()
+
+	query := s.dialect.rebind(fmt.Sprintf("INSERT INTO api_tokens (%s) VALUES (?, ?, ?, ?, ?, ?, ?, ?)",
+		sqlTokenColumns))
+	if _, err := s.db.ExecContext(ctx, query, token.ID, token.UserID, token.Name, strings.Join(token.Scopes, ","),
+		token.TokenHash, token.ExpiresAt, token.LastUsedAt, token.CreatedAt); err != nil {
+		err = fmt.Errorf("%s ExecContext error: %w", s.dialect.name(), err)
+		log.Error(ctx, &logger.Entry{Payload: err})
+		return err
+	}
+
+	return nil
+}
+
+func (s *sqlTokenRepository) GetTokenByHash(ctx context.Context, tokenHash string) (*models.TokenDTO, error) {
+	log := logger.Get()
+
+	query := s.dialect.rebind(fmt.Sprintf("SELECT %s FROM api_tokens WHERE token_hash = ?", sqlTokenColumns))
+
+	token, err := scanToken(s.db.QueryRowContext(ctx, query, tokenHash))
+	if err != nil {
+		// Handling the not-exists case.
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errutils.TokenNotFound()
+		}
+		err = fmt.Errorf("%s QueryRowContext error: %w", s.dialect.name(), err)
+		log.Error(ctx, &logger.Entry{Payload: err})
+		return nil, err
+	}
+
+	return token, nil
+}
+
+func (s *sqlTokenRepository) ListUserTokens(ctx context.Context, userID string) ([]*models.TokenDTO, error) {
+	log := logger.Get()
+
+	query := s.dialect.rebind(fmt.Sprintf("SELECT %s FROM api_tokens WHERE user_id = ? ORDER BY id", sqlTokenColumns))
+	rows, err := s.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		err = fmt.Errorf("%s QueryContext error: %w", s.dialect.name(), err)
+		log.Error(ctx, &logger.Entry{Payload: err})
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	results := []*models.TokenDTO{}
+	for rows.Next() {
+		token, err := scanToken(rows)
+		if err != nil {
+			err = fmt.Errorf("%s rows.Scan error: %w", s.dialect.name(), err)
+			log.Error(ctx, &logger.Entry{Payload: err})
+			return nil, err
+		}
+		results = append(results, token)
+	}
+
+	if err := rows.Err(); err != nil {
+		err = fmt.Errorf("%s rows.Err error: %w", s.dialect.name(), err)
+		log.Error(ctx, &logger.Entry{Payload: err})
+		return nil, err
+	}
+
+	return results, nil
+}
+
+func (s *sqlTokenRepository) UpdateTokenLastUsed(ctx context.Context, tokenID string, lastUsedAt int64) error {
+	log := logger.Get()
+
+	query := s.dialect.rebind("UPDATE api_tokens SET last_used_at = ? WHERE id = ?")
+	result, err := s.db.ExecContext(ctx, query, lastUsedAt, tokenID)
+	if err != nil {
+		err = fmt.Errorf("%s ExecContext error: %w", s.dialect.name(), err)
+		log.Error(ctx, &logger.Entry{Payload: err})
+		return err
+	}
+
+	return checkRowsAffected(result, errutils.TokenNotFound())
+}
+
+func (s *sqlTokenRepository) DeleteToken(ctx context.Context, userID string, tokenID string) error {
+	log := logger.Get()
+
+	query := s.dialect.rebind("DELETE FROM api_tokens WHERE id = ? AND user_id = ?")
+	result, err := s.db.ExecContext(ctx, query, tokenID, userID)
+	if err != nil {
+		err = fmt.Errorf("%s ExecContext error: %w", s.dialect.name(), err)
+		log.Error(ctx, &logger.Entry{Payload: err})
+		return err
+	}
+
+	return checkRowsAffected(result, errutils.TokenNotFound())
+}
+
+// scanToken scans a row of the sqlTokenColumns into a token.
+func scanToken(row sqlRowScanner) (*models.TokenDTO, error) {
+	token := &models.TokenDTO{}
+	var scopes string
+	if err := row.Scan(&token.ID, &token.UserID, &token.Name, &scopes, &token.TokenHash, &token.ExpiresAt,
+		&token.LastUsedAt, &token.CreatedAt); err != nil {
+		return nil, err
+	}
+	token.Scopes = strings.Split(scopes, ",")
+	return token, nil
+}
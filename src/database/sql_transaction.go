@@ -0,0 +1,595 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/shivanshkc/ledgerkeep/src/logger"
+	"github.com/shivanshkc/ledgerkeep/src/models"
+	"github.com/shivanshkc/ledgerkeep/src/utils/errutils"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// sqlSplitsBatchSize is the maximum number of transactions whose split lines are loaded by a single query.
+const sqlSplitsBatchSize = 500
+
+// sqlTransactionRepository implements TransactionRepository using a SQL database.
+type sqlTransactionRepository struct {
+	db      *sql.DB
+	dialect sqlDialect
+}
+
+func (s *sqlTransactionRepository) InsertTransaction(ctx context.Context, transaction *models.TransactionDTO) (string, error) {
+	// Transaction IDs are ObjectIDs, just like the ones generated by MongoDB.
+	transactionID := primitive.NewObjectID().Hex()
+
+	// The transaction and its split lines are inserted atomically.
+	err := s.runInTx(ctx, func(dbTx *sql.Tx) error {
+		return s.insertTransaction(ctx, dbTx, transactionID, transaction)
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return transactionID, nil
+}
+
+func (s *sqlTransactionRepository) GetTransaction(ctx context.Context, transactionID string) (*models.TransactionDTO, error) {
+	log := logger.Get()
+
+	tenantID, err := getTenantID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	query := s.dialect.rebind(
+		`SELECT id, amount, timestamp, account_id, category, notes, transfer_id, external_id, tags
+		FROM transactions WHERE tenant_id = ? AND id = ?`)
+
+	transaction := &models.TransactionDTO{}
+	if err := s.db.QueryRowContext(ctx, query, tenantID, transactionID).Scan(&transaction.ID, &transaction.Amount,
+		&transaction.Timestamp, &transaction.AccountID, &transaction.Category, &transaction.Notes,
+		&transaction.TransferID, &transaction.ExternalID, &transaction.Tags); err != nil {
+		// Handling the not-exists case.
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errutils.TransactionNotFound()
+		}
+		err = fmt.Errorf("%s QueryRowContext error: %w", s.dialect.name(), err)
+		log.Error(ctx, &logger.Entry{Payload: err})
+		return nil, err
+	}
+
+	if err := s.loadSplits(ctx, []*models.TransactionDTO{transaction}); err != nil {
+		return nil, err
+	}
+
+	return transaction, nil
+}
+
+func (s *sqlTransactionRepository) ListTransactions(ctx context.Context, params *ListTransactionsParams) ([]*models.TransactionDTO, int, error) {
+	log := logger.Get()
+
+	tenantID, err := getTenantID(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	whereClause, whereArgs, err := buildSQLWhereClause(tenantID, params.Filter, s.dialect.textSearchClause)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	orderByClause, err := buildSQLOrderByClause(params.SortField, params.SortOrder)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// Split lines are kept in their own table, so they are loaded separately.
+	requiredFields, withSplits := excludeSplitsField(params.RequiredFields)
+
+	columns, err := getSQLTransactionColumns(requiredFields)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// A zero limit means no limit, just like in MongoDB.
+	var limit interface{} = int64(params.PaginationLimit)
+	if params.PaginationLimit == 0 {
+		limit = s.dialect.noLimit()
+	}
+
+	query := s.dialect.rebind(fmt.Sprintf("SELECT %s FROM transactions %s %s LIMIT ? OFFSET ?",
+		strings.Join(columns, ", "), whereClause, orderByClause))
+
+	rows, err := s.db.QueryContext(ctx, query, append(whereArgs, limit, params.PaginationSkip)...)
+	if err != nil {
+		err = fmt.Errorf("%s QueryContext error: %w", s.dialect.name(), err)
+		log.Error(ctx, &logger.Entry{Payload: err})
+		return nil, 0, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	transactions := []*models.TransactionDTO{}
+	for rows.Next() {
+		transaction := &models.TransactionDTO{}
+		if err := rows.Scan(getTransactionScanTargets(transaction, columns)...); err != nil {
+			err = fmt.Errorf("%s rows.Scan error: %w", s.dialect.name(), err)
+			log.Error(ctx, &logger.Entry{Payload: err})
+			return nil, 0, err
+		}
+		transactions = append(transactions, transaction)
+	}
+
+	if err := rows.Err(); err != nil {
+		err = fmt.Errorf("%s rows.Err error: %w", s.dialect.name(), err)
+		log.Error(ctx, &logger.Entry{Payload: err})
+		return nil, 0, err
+	}
+
+	if withSplits {
+		if err := s.loadSplits(ctx, transactions); err != nil {
+			return nil, 0, err
+		}
+	}
+
+	// If the count is not required, we don't query the DB.
+	if params.ExcludeCount {
+		return transactions, 0, nil
+	}
+
+	var count int
+	countQuery := s.dialect.rebind(fmt.Sprintf("SELECT COUNT(*) FROM transactions %s", whereClause))
+	if err := s.db.QueryRowContext(ctx, countQuery, whereArgs...).Scan(&count); err != nil {
+		err = fmt.Errorf("%s QueryRowContext error: %w", s.dialect.name(), err)
+		log.Error(ctx, &logger.Entry{Payload: err})
+		return nil, 0, err
+	}
+
+	return transactions, count, nil
+}
+
+func (s *sqlTransactionRepository) StreamTransactions(ctx context.Context, params *ListTransactionsParams) (TransactionCursor, error) {
+	log := logger.Get()
+
+	tenantID, err := getTenantID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	whereClause, whereArgs, err := buildSQLWhereClause(tenantID, params.Filter, s.dialect.textSearchClause)
+	if err != nil {
+		return nil, err
+	}
+
+	orderByClause, err := buildSQLOrderByClause(params.SortField, params.SortOrder)
+	if err != nil {
+		return nil, err
+	}
+
+	// The split lines are not streamed, as they are kept in their own table.
+	requiredFields, _ := excludeSplitsField(params.RequiredFields)
+
+	columns, err := getSQLTransactionColumns(requiredFields)
+	if err != nil {
+		return nil, err
+	}
+
+	query := s.dialect.rebind(fmt.Sprintf("SELECT %s FROM transactions %s %s",
+		strings.Join(columns, ", "), whereClause, orderByClause))
+
+	rows, err := s.db.QueryContext(ctx, query, whereArgs...)
+	if err != nil {
+		err = fmt.Errorf("%s QueryContext error: %w", s.dialect.name(), err)
+		log.Error(ctx, &logger.Entry{Payload: err})
+		return nil, err
+	}
+
+	return &sqlTransactionCursor{rows: rows, columns: columns, dialect: s.dialect}, nil
+}
+
+func (s *sqlTransactionRepository) GetCategoryTotals(ctx context.Context, filter map[string]interface{}) ([]*CategoryTotals, error) {
+	log := logger.Get()
+
+	tenantID, err := getTenantID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	whereClause, whereArgs, err := buildSQLWhereClause(tenantID, filter, s.dialect.textSearchClause)
+	if err != nil {
+		return nil, err
+	}
+
+	// Every split line counts in its own category. A transaction without splits is a single line.
+	// The filter is applied before joining the split lines, as they have some column names in common.
+	query := s.dialect.rebind(fmt.Sprintf(`SELECT account_id, category,
+		CAST(COALESCE(SUM(CASE WHEN amount > 0 THEN amount ELSE 0 END), 0) AS BIGINT),
+		CAST(COALESCE(SUM(CASE WHEN amount < 0 THEN amount ELSE 0 END), 0) AS BIGINT)
+		FROM (
+			SELECT t.account_id, COALESCE(s.category, t.category) AS category, COALESCE(s.amount, t.amount) AS amount
+			FROM (SELECT tenant_id, id, account_id, category, amount FROM transactions %s) AS t
+			LEFT JOIN transaction_splits AS s ON s.tenant_id = t.tenant_id AND s.transaction_id = t.id
+		) AS lines GROUP BY account_id, category`, whereClause))
+
+	rows, err := s.db.QueryContext(ctx, query, whereArgs...)
+	if err != nil {
+		err = fmt.Errorf("%s QueryContext error: %w", s.dialect.name(), err)
+		log.Error(ctx, &logger.Entry{Payload: err})
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	results := []*CategoryTotals{}
+	for rows.Next() {
+		totals := &CategoryTotals{}
+		if err := rows.Scan(&totals.AccountID, &totals.Category, &totals.Credit, &totals.Debit); err != nil {
+			err = fmt.Errorf("%s rows.Scan error: %w", s.dialect.name(), err)
+			log.Error(ctx, &logger.Entry{Payload: err})
+			return nil, err
+		}
+		results = append(results, totals)
+	}
+
+	if err := rows.Err(); err != nil {
+		err = fmt.Errorf("%s rows.Err error: %w", s.dialect.name(), err)
+		log.Error(ctx, &logger.Entry{Payload: err})
+		return nil, err
+	}
+
+	return results, nil
+}
+
+func (s *sqlTransactionRepository) UpdateTransaction(ctx context.Context, transactionID string, updates map[string]interface{}) error {
+	// The transaction and its split lines are updated atomically.
+	return s.runInTx(ctx, func(dbTx *sql.Tx) error {
+		return s.updateTransaction(ctx, dbTx, transactionID, updates)
+	})
+}
+
+func (s *sqlTransactionRepository) DeleteTransaction(ctx context.Context, transactionID string) error {
+	log := logger.Get()
+
+	tenantID, err := getTenantID(ctx)
+	if err != nil {
+		return err
+	}
+
+	// The split lines are deleted along with the transaction by the foreign key.
+	query := s.dialect.rebind("DELETE FROM transactions WHERE tenant_id = ? AND id = ?")
+	result, err := s.db.ExecContext(ctx, query, tenantID, transactionID)
+	if err != nil {
+		err = fmt.Errorf("%s ExecContext error: %w", s.dialect.name(), err)
+		log.Error(ctx, &logger.Entry{Payload: err})
+		return err
+	}
+
+	return checkRowsAffected(result, errutils.TransactionNotFound())
+}
+
+func (s *sqlTransactionRepository) InsertTransactions(ctx context.Context,
+	transactions []*models.TransactionDTO) ([]string, error) {
+	ids := make([]string, len(transactions))
+
+	// All the transactions are inserted atomically.
+	err := s.runInTx(ctx, func(dbTx *sql.Tx) error {
+		for idx, transaction := range transactions {
+			// Transaction IDs are ObjectIDs, just like the ones generated by MongoDB.
+			ids[idx] = primitive.NewObjectID().Hex()
+			if err := s.insertTransaction(ctx, dbTx, ids[idx], transaction); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return ids, nil
+}
+
+func (s *sqlTransactionRepository) InsertTransfer(ctx context.Context, legs []*models.TransactionDTO) ([]string, error) {
+	// The legs are inserted like any other transactions.
+	return s.InsertTransactions(ctx, legs)
+}
+
+func (s *sqlTransactionRepository) UpdateTransactions(ctx context.Context, updates map[string]map[string]interface{}) error {
+	// Sorting the IDs so concurrent updates lock the rows in the same order.
+	transactionIDs := make([]string, 0, len(updates))
+	for transactionID := range updates {
+		transactionIDs = append(transactionIDs, transactionID)
+	}
+	sort.Strings(transactionIDs)
+
+	// All the transactions are updated atomically.
+	return s.runInTx(ctx, func(dbTx *sql.Tx) error {
+		for _, transactionID := range transactionIDs {
+			if err := s.updateTransaction(ctx, dbTx, transactionID, updates[transactionID]); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (s *sqlTransactionRepository) DeleteTransfer(ctx context.Context, transferID string) error {
+	log := logger.Get()
+
+	tenantID, err := getTenantID(ctx)
+	if err != nil {
+		return err
+	}
+
+	// Transactions that are not legs of a transfer have an empty transfer ID.
+	if transferID == "" {
+		return errutils.TransactionNotFound()
+	}
+
+	// A single statement deletes all the legs atomically.
+	query := s.dialect.rebind("DELETE FROM transactions WHERE tenant_id = ? AND transfer_id = ?")
+	result, err := s.db.ExecContext(ctx, query, tenantID, transferID)
+	if err != nil {
+		err = fmt.Errorf("%s ExecContext error: %w", s.dialect.name(), err)
+		log.Error(ctx, &logger.Entry{Payload: err})
+		return err
+	}
+
+	return checkRowsAffected(result, errutils.TransactionNotFound())
+}
+
+// runInTx runs the provided function in a database transaction, which is committed only if the function succeeds.
+func (s *sqlTransactionRepository) runInTx(ctx context.Context, txFunc func(dbTx *sql.Tx) error) error {
+	log := logger.Get()
+
+	dbTx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		err = fmt.Errorf("%s BeginTx error: %w", s.dialect.name(), err)
+		log.Error(ctx, &logger.Entry{Payload: err})
+		return err
+	}
+	defer func() { _ = dbTx.Rollback() }()
+
+	if err := txFunc(dbTx); err != nil {
+		return err
+	}
+
+	if err := dbTx.Commit(); err != nil {
+		err = fmt.Errorf("%s Commit error: %w", s.dialect.name(), err)
+		log.Error(ctx, &logger.Entry{Payload: err})
+		return err
+	}
+
+	return nil
+}
+
+// insertTransaction inserts the transaction and its split lines with the provided ID.
+func (s *sqlTransactionRepository) insertTransaction(ctx context.Context, dbTx *sql.Tx, transactionID string,
+	transaction *models.TransactionDTO) error {
+	log := logger.Get()
+
+	tenantID, err := getTenantID(ctx)
+	if err != nil {
+		return err
+	}
+
+	query := s.dialect.rebind(sqlInsertTransactionQuery)
+	args := getSQLInsertTransactionArgs(tenantID, transactionID, transaction)
+	if _, err := dbTx.ExecContext(ctx, query, args...); err != nil {
+		// Transactions refer to their accounts through a foreign key, if the database enforces it.
+		if s.dialect.isForeignKeyViolation(err) {
+			return errutils.AccountNotFound()
+		}
+		err = fmt.Errorf("%s ExecContext error: %w", s.dialect.name(), err)
+		log.Error(ctx, &logger.Entry{Payload: err})
+		return err
+	}
+
+	return s.insertSplits(ctx, dbTx, tenantID, transactionID, transaction.Splits)
+}
+
+// updateTransaction updates the transaction with the provided ID.
+// If the updates contain the split lines, they replace all the existing split lines of the transaction.
+func (s *sqlTransactionRepository) updateTransaction(ctx context.Context, dbTx *sql.Tx, transactionID string,
+	updates map[string]interface{}) error {
+	log := logger.Get()
+
+	tenantID, err := getTenantID(ctx)
+	if err != nil {
+		return err
+	}
+
+	// Separating the split lines from the updates of the transactions table.
+	columnUpdates := make(map[string]interface{}, len(updates))
+	var splits []*models.SplitDTO
+	var splitsUpdated bool
+	for field, value := range updates {
+		if field != "splits" {
+			columnUpdates[field] = value
+			continue
+		}
+
+		if splits, splitsUpdated = value.([]*models.SplitDTO); !splitsUpdated {
+			return fmt.Errorf("invalid value type %T for field: %s", value, field)
+		}
+	}
+
+	if len(columnUpdates) > 0 || !splitsUpdated {
+		setClause, args, err := buildSQLUpdateClause(columnUpdates, sqlUpdatableTransactionColumns)
+		if err != nil {
+			return err
+		}
+
+		query := s.dialect.rebind(fmt.Sprintf("UPDATE transactions %s WHERE tenant_id = ? AND id = ?", setClause))
+		result, err := dbTx.ExecContext(ctx, query, append(args, tenantID, transactionID)...)
+		if err != nil {
+			// Transactions refer to their accounts through a foreign key, if the database enforces it.
+			if s.dialect.isForeignKeyViolation(err) {
+				return errutils.AccountNotFound()
+			}
+			err = fmt.Errorf("%s ExecContext error: %w", s.dialect.name(), err)
+			log.Error(ctx, &logger.Entry{Payload: err})
+			return err
+		}
+
+		if err := checkRowsAffected(result, errutils.TransactionNotFound()); err != nil {
+			return err
+		}
+	} else {
+		// Only the split lines are updated, so the existence of the transaction is checked separately.
+		var count int
+		query := s.dialect.rebind("SELECT COUNT(*) FROM transactions WHERE tenant_id = ? AND id = ?")
+		if err := dbTx.QueryRowContext(ctx, query, tenantID, transactionID).Scan(&count); err != nil {
+			err = fmt.Errorf("%s QueryRowContext error: %w", s.dialect.name(), err)
+			log.Error(ctx, &logger.Entry{Payload: err})
+			return err
+		}
+		if count == 0 {
+			return errutils.TransactionNotFound()
+		}
+	}
+
+	if !splitsUpdated {
+		return nil
+	}
+
+	query := s.dialect.rebind("DELETE FROM transaction_splits WHERE tenant_id = ? AND transaction_id = ?")
+	if _, err := dbTx.ExecContext(ctx, query, tenantID, transactionID); err != nil {
+		err = fmt.Errorf("%s ExecContext error: %w", s.dialect.name(), err)
+		log.Error(ctx, &logger.Entry{Payload: err})
+		return err
+	}
+
+	return s.insertSplits(ctx, dbTx, tenantID, transactionID, splits)
+}
+
+// insertSplits inserts the split lines of the transaction with the provided tenant and ID, in their order.
+func (s *sqlTransactionRepository) insertSplits(ctx context.Context, dbTx *sql.Tx, tenantID string,
+	transactionID string, splits []*models.SplitDTO) error {
+	log := logger.Get()
+
+	query := s.dialect.rebind(`INSERT INTO transaction_splits
+		(tenant_id, transaction_id, position, amount, category, notes) VALUES (?, ?, ?, ?, ?, ?)`)
+
+	for position, split := range splits {
+		if _, err := dbTx.ExecContext(ctx, query, tenantID, transactionID, position, split.Amount, split.Category,
+			split.Notes); err != nil {
+			err = fmt.Errorf("%s ExecContext error: %w", s.dialect.name(), err)
+			log.Error(ctx, &logger.Entry{Payload: err})
+			return err
+		}
+	}
+
+	return nil
+}
+
+// loadSplits loads the split lines of all the provided transactions, which belong to the tenant of the context,
+// into them.
+func (s *sqlTransactionRepository) loadSplits(ctx context.Context, transactions []*models.TransactionDTO) error {
+	log := logger.Get()
+
+	tenantID, err := getTenantID(ctx)
+	if err != nil {
+		return err
+	}
+
+	transactionMap := make(map[string]*models.TransactionDTO, len(transactions))
+	for _, transaction := range transactions {
+		transactionMap[transaction.ID] = transaction
+	}
+
+	// Loading in batches to keep the number of query parameters in check.
+	for start := 0; start < len(transactions); start += sqlSplitsBatchSize {
+		end := start + sqlSplitsBatchSize
+		if end > len(transactions) {
+			end = len(transactions)
+		}
+
+		args := make([]interface{}, 0, end-start+1)
+		args = append(args, tenantID)
+		for _, transaction := range transactions[start:end] {
+			args = append(args, transaction.ID)
+		}
+
+		query := s.dialect.rebind(fmt.Sprintf(`SELECT transaction_id, amount, category, notes FROM transaction_splits
+			WHERE tenant_id = ? AND transaction_id IN (%s) ORDER BY transaction_id, position`,
+			sqlPlaceholders(len(args)-1)))
+
+		rows, err := s.db.QueryContext(ctx, query, args...)
+		if err != nil {
+			err = fmt.Errorf("%s QueryContext error: %w", s.dialect.name(), err)
+			log.Error(ctx, &logger.Entry{Payload: err})
+			return err
+		}
+
+		for rows.Next() {
+			var transactionID string
+			split := &models.SplitDTO{}
+			if err := rows.Scan(&transactionID, &split.Amount, &split.Category, &split.Notes); err != nil {
+				_ = rows.Close()
+				err = fmt.Errorf("%s rows.Scan error: %w", s.dialect.name(), err)
+				log.Error(ctx, &logger.Entry{Payload: err})
+				return err
+			}
+			transaction := transactionMap[transactionID]
+			transaction.Splits = append(transaction.Splits, split)
+		}
+
+		err = rows.Err()
+		_ = rows.Close()
+		if err != nil {
+			err = fmt.Errorf("%s rows.Err error: %w", s.dialect.name(), err)
+			log.Error(ctx, &logger.Entry{Payload: err})
+			return err
+		}
+	}
+
+	return nil
+}
+
+// sqlTransactionCursor implements TransactionCursor over the rows of a SQL query.
+type sqlTransactionCursor struct {
+	rows        *sql.Rows
+	columns     []string
+	dialect     sqlDialect
+	transaction *models.TransactionDTO
+	err         error
+}
+
+func (s *sqlTransactionCursor) Next(ctx context.Context) bool {
+	if s.err != nil || !s.rows.Next() {
+		return false
+	}
+
+	transaction := &models.TransactionDTO{}
+	if err := s.rows.Scan(getTransactionScanTargets(transaction, s.columns)...); err != nil {
+		s.err = fmt.Errorf("%s rows.Scan error: %w", s.dialect.name(), err)
+		logger.Get().Error(ctx, &logger.Entry{Payload: s.err})
+		return false
+	}
+
+	s.transaction = transaction
+	return true
+}
+
+func (s *sqlTransactionCursor) Transaction() *models.TransactionDTO {
+	return s.transaction
+}
+
+func (s *sqlTransactionCursor) Err() error {
+	if s.err != nil {
+		return s.err
+	}
+	if err := s.rows.Err(); err != nil {
+		return fmt.Errorf("%s rows.Err error: %w", s.dialect.name(), err)
+	}
+	return nil
+}
+
+func (s *sqlTransactionCursor) Close(ctx context.Context) error {
+	return s.rows.Close()
+}
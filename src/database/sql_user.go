@@ -0,0 +1,123 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/shivanshkc/ledgerkeep/src/logger"
+	"github.com/shivanshkc/ledgerkeep/src/models"
+	"github.com/shivanshkc/ledgerkeep/src/utils/errutils"
+)
+
+// sqlUserColumns are the columns of the users table, in the order of scanUser.
+const sqlUserColumns = "id, password_hash, is_admin, tenant_id, created_at"
+
+// sqlUpdatableUserColumns maps the database names of the updatable user fields to their SQL columns.
+var sqlUpdatableUserColumns = map[string]string{
+	"password_hash": "password_hash",
+	"is_admin":      "is_admin",
+}
+
+// sqlUserRepository implements UserRepository using a SQL database.
+type sqlUserRepository struct {
+	db      *sql.DB
+	dialect sqlDialect
+}
+
+func (s *sqlUserRepository) InsertUser(ctx context.Context, user *models.UserDTO) error {
+	log := logger.Get()
+
+	query := s.dialect.rebind(fmt.Sprintf("INSERT INTO users (%s) VALUES (?, ?, ?, ?, ?)", sqlUserColumns))
+	if _, err := s.db.ExecContext(ctx, query, user.ID, user.PasswordHash, user.IsAdmin, user.TenantID,
+		user.CreatedAt); err != nil {
+		// Checking if the error is a primary key violation (already exists error).
+		if s.dialect.isUniqueViolation(err) {
+			return errutils.UserAlreadyExists()
+		}
+		err = fmt.Errorf("%s ExecContext error: %w", s.dialect.name(), err)
+		log.Error(ctx, &logger.Entry{Payload: err})
+		return err
+	}
+
+	return nil
+}
+
+func (s *sqlUserRepository) GetUser(ctx context.Context, userID string) (*models.UserDTO, error) {
+	log := logger.Get()
+
+	query := s.dialect.rebind(fmt.Sprintf("SELECT %s FROM users WHERE id = ?", sqlUserColumns))
+
+	user, err := scanUser(s.db.QueryRowContext(ctx, query, userID))
+	if err != nil {
+		// Handling the not-exists case.
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errutils.UserNotFound()
+		}
+		err = fmt.Errorf("%s QueryRowContext error: %w", s.dialect.name(), err)
+		log.Error(ctx, &logger.Entry{Payload: err})
+		return nil, err
+	}
+
+	return user, nil
+}
+
+func (s *sqlUserRepository) ListUsers(ctx context.Context) ([]*models.UserDTO, error) {
+	log := logger.Get()
+
+	rows, err := s.db.QueryContext(ctx, fmt.Sprintf("SELECT %s FROM users ORDER BY id", sqlUserColumns))
+	if err != nil {
+		err = fmt.Errorf("%s QueryContext error: %w", s.dialect.name(), err)
+		log.Error(ctx, &logger.Entry{Payload: err})
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	results := []*models.UserDTO{}
+	for rows.Next() {
+		user, err := scanUser(rows)
+		if err != nil {
+			err = fmt.Errorf("%s rows.Scan error: %w", s.dialect.name(), err)
+			log.Error(ctx, &logger.Entry{Payload: err})
+			return nil, err
+		}
+		results = append(results, user)
+	}
+
+	if err := rows.Err(); err != nil {
+		err = fmt.Errorf("%s rows.Err error: %w", s.dialect.name(), err)
+		log.Error(ctx, &logger.Entry{Payload: err})
+		return nil, err
+	}
+
+	return results, nil
+}
+
+func (s *sqlUserRepository) UpdateUser(ctx context.Context, userID string, updates map[string]interface{}) error {
+	log := logger.Get()
+
+	setClause, args, err := buildSQLUpdateClause(updates, sqlUpdatableUserColumns)
+	if err != nil {
+		return err
+	}
+
+	query := s.dialect.rebind(fmt.Sprintf("UPDATE users %s WHERE id = ?", setClause))
+	result, err := s.db.ExecContext(ctx, query, append(args, userID)...)
+	if err != nil {
+		err = fmt.Errorf("%s ExecContext error: %w", s.dialect.name(), err)
+		log.Error(ctx, &logger.Entry{Payload: err})
+		return err
+	}
+
+	return checkRowsAffected(result, errutils.UserNotFound())
+}
+
+// scanUser scans a row of the sqlUserColumns into a user.
+func scanUser(row sqlRowScanner) (*models.UserDTO, error) {
+	user := &models.UserDTO{}
+	if err := row.Scan(&user.ID, &user.PasswordHash, &user.IsAdmin, &user.TenantID, &user.CreatedAt); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
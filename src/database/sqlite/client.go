@@ -0,0 +1,62 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sync"
+
+	"github.com/shivanshkc/ledgerkeep/src/configs"
+	"github.com/shivanshkc/ledgerkeep/src/logger"
+
+	// Registers the pure Go SQLite driver, which does not require CGO.
+	_ "modernc.org/sqlite"
+)
+
+// connectionParams are the DSN params for every SQLite connection.
+//
+// Foreign keys are enforced, concurrent readers do not block the writer (WAL), busy connections wait instead of
+// failing immediately, and write transactions take the lock upfront to avoid lock upgrade deadlocks.
+const connectionParams = "_pragma=foreign_keys(1)&_pragma=journal_mode(WAL)&_pragma=busy_timeout(5000)&_txlock=immediate"
+
+var (
+	clientOnce      = &sync.Once{}
+	clientSingleton *sql.DB
+)
+
+// GetClient returns the SQLite client singleton.
+func GetClient() *sql.DB {
+	clientOnce.Do(func() {
+		logger.Get().Info(context.Background(), &logger.Entry{Payload: "Opening SQLite database..."})
+		clientSingleton = getClient()
+		logger.Get().Info(context.Background(), &logger.Entry{Payload: "Opened SQLite database."})
+	})
+
+	return clientSingleton
+}
+
+// getClient is a pure function (except for configs) to generate a SQLite client.
+func getClient() *sql.DB {
+	conf := configs.Get()
+
+	client, err := Open(conf.SQLite.Path)
+	if err != nil {
+		panic(err)
+	}
+
+	return client
+}
+
+// Open opens the SQLite database file at the provided path, creating it if it does not exist.
+func Open(path string) (*sql.DB, error) {
+	client, err := sql.Open("sqlite", fmt.Sprintf("%s?%s", path, connectionParams))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open sqlite database: %w", err)
+	}
+
+	if err := client.Ping(); err != nil {
+		return nil, fmt.Errorf("failed to ping sqlite database: %w", err)
+	}
+
+	return client, nil
+}
@@ -0,0 +1,630 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/shivanshkc/ledgerkeep/src/database/sqlite"
+
+	sqlitedriver "modernc.org/sqlite"
+	sqlitelib "modernc.org/sqlite/lib"
+)
+
+// sqliteMigrations are the versioned schema changes of the SQLite backend.
+// Applied migrations must never be modified. Schema changes should always be added as new migrations.
+var sqliteMigrations = []sqlMigration{
+	{
+		Version:     1,
+		Description: "create accounts and transactions tables",
+		Statements: []string{
+			`CREATE TABLE accounts (
+				id   TEXT PRIMARY KEY,
+				name TEXT NOT NULL
+			)`,
+			// The integer primary key is a stable rowid, which the full text search index refers to.
+			`CREATE TABLE transactions (
+				seq        INTEGER PRIMARY KEY,
+				id         TEXT    NOT NULL UNIQUE,
+				amount     REAL    NOT NULL,
+				timestamp  INTEGER NOT NULL,
+				account_id TEXT    NOT NULL,
+				category   TEXT    NOT NULL,
+				notes      TEXT    NOT NULL
+			)`,
+			`CREATE INDEX transactions_account_id_idx ON transactions (account_id)`,
+		},
+	},
+	{
+		Version:     2,
+		Description: "add full text search on transaction notes",
+		Statements: []string{
+			// The porter tokenizer provides stemming, similar to MongoDB text indexes.
+			`CREATE VIRTUAL TABLE transactions_fts USING fts5 (
+				notes,
+				content = 'transactions',
+				content_rowid = 'seq',
+				tokenize = 'porter unicode61'
+			)`,
+			// These triggers keep the full text search index in sync with the transactions table.
+			`CREATE TRIGGER transactions_fts_insert AFTER INSERT ON transactions BEGIN
+				INSERT INTO transactions_fts (rowid, notes) VALUES (new.seq, new.notes);
+			END`,
+			`CREATE TRIGGER transactions_fts_delete AFTER DELETE ON transactions BEGIN
+				INSERT INTO transactions_fts (transactions_fts, rowid, notes) VALUES ('delete', old.seq, old.notes);
+			END`,
+			`CREATE TRIGGER transactions_fts_update AFTER UPDATE OF notes ON transactions BEGIN
+				INSERT INTO transactions_fts (transactions_fts, rowid, notes) VALUES ('delete', old.seq, old.notes);
+				INSERT INTO transactions_fts (rowid, notes) VALUES (new.seq, new.notes);
+			END`,
+			// Indexing the transactions that existed before this migration.
+			`INSERT INTO transactions_fts (transactions_fts) VALUES ('rebuild')`,
+		},
+	},
+	{
+		Version:     3,
+		Description: "store transaction amounts as integer money units",
+		Statements: []string{
+			// SQLite cannot change the type of a column, so the table is rebuilt.
+			// A REAL column would convert the integer amounts back into floats.
+			`CREATE TABLE transactions_new (
+				seq        INTEGER PRIMARY KEY,
+				id         TEXT    NOT NULL UNIQUE,
+				amount     INTEGER NOT NULL,
+				timestamp  INTEGER NOT NULL,
+				account_id TEXT    NOT NULL,
+				category   TEXT    NOT NULL,
+				notes      TEXT    NOT NULL
+			)`,
+			// There are 10000 money units in one currency unit. Rounding removes the float noise.
+			// The seq values are kept, so the full text search index stays valid.
+			`INSERT INTO transactions_new (seq, id, amount, timestamp, account_id, category, notes)
+				SELECT seq, id, CAST(ROUND(amount * 10000) AS INTEGER), timestamp, account_id, category, notes
+				FROM transactions`,
+			// Dropping the table also drops its index and triggers, which are created again below.
+			`DROP TABLE transactions`,
+			`ALTER TABLE transactions_new RENAME TO transactions`,
+			`CREATE INDEX transactions_account_id_idx ON transactions (account_id)`,
+			`CREATE TRIGGER transactions_fts_insert AFTER INSERT ON transactions BEGIN
+				INSERT INTO transactions_fts (rowid, notes) VALUES (new.seq, new.notes);
+			END`,
+			`CREATE TRIGGER transactions_fts_delete AFTER DELETE ON transactions BEGIN
+				INSERT INTO transactions_fts (transactions_fts, rowid, notes) VALUES ('delete', old.seq, old.notes);
+			END`,
+			`CREATE TRIGGER transactions_fts_update AFTER UPDATE OF notes ON transactions BEGIN
+				INSERT INTO transactions_fts (transactions_fts, rowid, notes) VALUES ('delete', old.seq, old.notes);
+				INSERT INTO transactions_fts (rowid, notes) VALUES (new.seq, new.notes);
+			END`,
+		},
+	},
+	{
+		Version:     4,
+		Description: "add account currencies and exchange rates",
+		Statements: []string{
+			// Accounts created before this migration get an empty currency, which stands for the default currency.
+			`ALTER TABLE accounts ADD COLUMN currency TEXT NOT NULL DEFAULT ''`,
+			// Rates are kept as decimal strings, so they are stored without any loss.
+			`CREATE TABLE exchange_rates (
+				id        TEXT    PRIMARY KEY,
+				base      TEXT    NOT NULL,
+				quote     TEXT    NOT NULL,
+				rate      TEXT    NOT NULL,
+				timestamp INTEGER NOT NULL,
+				UNIQUE (base, quote, timestamp)
+			)`,
+		},
+	},
+	{
+		Version:     5,
+		Description: "link the transactions of transfers",
+		Statements: []string{
+			// Transactions that are not legs of a transfer have an empty transfer ID.
+			`ALTER TABLE transactions ADD COLUMN transfer_id TEXT NOT NULL DEFAULT ''`,
+			`CREATE INDEX transactions_transfer_id_idx ON transactions (transfer_id)`,
+		},
+	},
+	{
+		Version:     6,
+		Description: "add split lines of transactions",
+		Statements: []string{
+			// The position keeps the split lines in their original order.
+			`CREATE TABLE transaction_splits (
+				transaction_id TEXT    NOT NULL REFERENCES transactions (id) ON DELETE CASCADE,
+				position       INTEGER NOT NULL,
+				amount         INTEGER NOT NULL,
+				category       TEXT    NOT NULL,
+				notes          TEXT    NOT NULL,
+				PRIMARY KEY (transaction_id, position)
+			)`,
+		},
+	},
+	{
+		Version:     7,
+		Description: "create categories table",
+		Statements: []string{
+			// The default categories are created by the application, as they are the same for all backends.
+			`CREATE TABLE categories (
+				id           TEXT PRIMARY KEY,
+				name         TEXT NOT NULL,
+				kind         TEXT NOT NULL,
+				budget_group TEXT NOT NULL
+			)`,
+		},
+	},
+	{
+		Version:     8,
+		Description: "create budget plans tables",
+		Statements: []string{
+			`CREATE TABLE budget_plans (
+				id          TEXT    PRIMARY KEY,
+				name        TEXT    NOT NULL,
+				currency    TEXT    NOT NULL,
+				valid_from  INTEGER NOT NULL,
+				valid_until INTEGER NOT NULL
+			)`,
+			// Exactly one of percent and amount is set for every allocation.
+			`CREATE TABLE budget_plan_allocations (
+				plan_id      TEXT    NOT NULL REFERENCES budget_plans (id) ON DELETE CASCADE,
+				position     INTEGER NOT NULL,
+				budget_group TEXT    NOT NULL,
+				percent      INTEGER,
+				amount       INTEGER,
+				PRIMARY KEY (plan_id, position)
+			)`,
+		},
+	},
+	{
+		Version:     9,
+		Description: "create recurring templates table",
+		Statements: []string{
+			`CREATE TABLE recurring_templates (
+				id         TEXT    PRIMARY KEY,
+				name       TEXT    NOT NULL,
+				amount     INTEGER NOT NULL,
+				account_id TEXT    NOT NULL,
+				category   TEXT    NOT NULL,
+				notes      TEXT    NOT NULL,
+				rule       TEXT    NOT NULL,
+				start_time INTEGER NOT NULL,
+				end_time   INTEGER NOT NULL,
+				next_run   INTEGER NOT NULL
+			)`,
+		},
+	},
+	{
+		Version:     10,
+		Description: "create import profiles table",
+		Statements: []string{
+			// Optional columns of a profile are stored as empty strings.
+			`CREATE TABLE import_profiles (
+				id                TEXT PRIMARY KEY,
+				name              TEXT NOT NULL,
+				account_id        TEXT NOT NULL,
+				delimiter         TEXT NOT NULL,
+				decimal_separator TEXT NOT NULL,
+				date_column       TEXT NOT NULL,
+				date_format       TEXT NOT NULL,
+				amount_column     TEXT NOT NULL,
+				debit_column      TEXT NOT NULL,
+				credit_column     TEXT NOT NULL,
+				notes_column      TEXT NOT NULL,
+				category_column   TEXT NOT NULL,
+				credit_category   TEXT NOT NULL,
+				debit_category    TEXT NOT NULL
+			)`,
+		},
+	},
+	{
+		Version:     11,
+		Description: "add external IDs of imported transactions",
+		Statements: []string{
+			// Transactions that are not imported from bank statements have an empty external ID.
+			`ALTER TABLE transactions ADD COLUMN external_id TEXT NOT NULL DEFAULT ''`,
+			`CREATE INDEX transactions_external_id_idx ON transactions (account_id, external_id)`,
+		},
+	},
+	{
+		Version:     12,
+		Description: "add transaction tags and categorization rules",
+		Statements: []string{
+			// Tags are stored as a JSON array, which is an empty string for the transactions without tags.
+			`ALTER TABLE transactions ADD COLUMN tags TEXT NOT NULL DEFAULT ''`,
+			// Absent amount bounds are NULL. The weekdays are comma separated, and the tags are stored like above.
+			`CREATE TABLE rules (
+				id            TEXT    PRIMARY KEY,
+				name          TEXT    NOT NULL,
+				priority      INTEGER NOT NULL,
+				notes_pattern TEXT    NOT NULL,
+				min_amount    INTEGER,
+				max_amount    INTEGER,
+				account_id    TEXT    NOT NULL,
+				weekdays      TEXT    NOT NULL,
+				set_category  TEXT    NOT NULL,
+				set_notes     TEXT    NOT NULL,
+				add_tags      TEXT    NOT NULL
+			)`,
+		},
+	},
+	{
+		Version:     13,
+		Description: "add users and the tenants of the ledger data",
+		Statements: []string{
+			`CREATE TABLE users (
+				id            TEXT    PRIMARY KEY,
+				password_hash TEXT    NOT NULL,
+				is_admin      INTEGER NOT NULL,
+				tenant_id     TEXT    NOT NULL,
+				created_at    INTEGER NOT NULL
+			)`,
+			// The existing data belongs to the "default" tenant, which is the tenant of the admin user of the configs.
+			// The IDs of the accounts and the categories are chosen by the users, so they are unique per tenant.
+			// SQLite cannot change the primary key of a table, so the tables are rebuilt. The accounts are copied in
+			// their order, which the rowid keeps.
+			`CREATE TABLE accounts_new (
+				tenant_id TEXT NOT NULL,
+				id        TEXT NOT NULL,
+				name      TEXT NOT NULL,
+				currency  TEXT NOT NULL,
+				PRIMARY KEY (tenant_id, id)
+			)`,
+			`INSERT INTO accounts_new (tenant_id, id, name, currency)
+				SELECT 'default', id, name, currency FROM accounts ORDER BY rowid`,
+			`DROP TABLE accounts`,
+			`ALTER TABLE accounts_new RENAME TO accounts`,
+			`CREATE TABLE categories_new (
+				tenant_id    TEXT NOT NULL,
+				id           TEXT NOT NULL,
+				name         TEXT NOT NULL,
+				kind         TEXT NOT NULL,
+				budget_group TEXT NOT NULL,
+				PRIMARY KEY (tenant_id, id)
+			)`,
+			`INSERT INTO categories_new (tenant_id, id, name, kind, budget_group)
+				SELECT 'default', id, name, kind, budget_group FROM categories`,
+			`DROP TABLE categories`,
+			`ALTER TABLE categories_new RENAME TO categories`,
+			`CREATE TABLE exchange_rates_new (
+				tenant_id TEXT    NOT NULL,
+				id        TEXT    PRIMARY KEY,
+				base      TEXT    NOT NULL,
+				quote     TEXT    NOT NULL,
+				rate      TEXT    NOT NULL,
+				timestamp INTEGER NOT NULL,
+				UNIQUE (tenant_id, base, quote, timestamp)
+			)`,
+			`INSERT INTO exchange_rates_new (tenant_id, id, base, quote, rate, timestamp)
+				SELECT 'default', id, base, quote, rate, timestamp FROM exchange_rates`,
+			`DROP TABLE exchange_rates`,
+			`ALTER TABLE exchange_rates_new RENAME TO exchange_rates`,
+			`ALTER TABLE transactions ADD COLUMN tenant_id TEXT NOT NULL DEFAULT 'default'`,
+			`ALTER TABLE budget_plans ADD COLUMN tenant_id TEXT NOT NULL DEFAULT 'default'`,
+			`ALTER TABLE recurring_templates ADD COLUMN tenant_id TEXT NOT NULL DEFAULT 'default'`,
+			`ALTER TABLE import_profiles ADD COLUMN tenant_id TEXT NOT NULL DEFAULT 'default'`,
+			`ALTER TABLE rules ADD COLUMN tenant_id TEXT NOT NULL DEFAULT 'default'`,
+			`CREATE INDEX transactions_tenant_id_idx ON transactions (tenant_id, timestamp)`,
+		},
+	},
+	{
+		Version:     14,
+		Description: "create ledger members table",
+		Statements: []string{
+			`CREATE TABLE ledger_members (
+				ledger_id  TEXT    NOT NULL,
+				user_id    TEXT    NOT NULL REFERENCES users (id),
+				role       TEXT    NOT NULL,
+				created_at INTEGER NOT NULL,
+				PRIMARY KEY (ledger_id, user_id)
+			)`,
+			`CREATE INDEX ledger_members_user_id_idx ON ledger_members (user_id)`,
+		},
+	},
+	{
+		Version:     15,
+		Description: "create api tokens table",
+		Statements: []string{
+			`CREATE TABLE api_tokens (
+				id           TEXT    PRIMARY KEY,
+				user_id      TEXT    NOT NULL REFERENCES users (id),
+				name         TEXT    NOT NULL,
+				scopes       TEXT    NOT NULL,
+				token_hash   TEXT    NOT NULL UNIQUE,
+				expires_at   INTEGER NOT NULL,
+				last_used_at INTEGER NOT NULL,
+				created_at   INTEGER NOT NULL
+			)`,
+			`CREATE INDEX api_tokens_user_id_idx ON api_tokens (user_id)`,
+		},
+	},
+	{
+		Version:     16,
+		Description: "scope the ledger data IDs to their tenants",
+		Statements: []string{
+			// A restored backup keeps its IDs, so the same IDs may exist in many tenants. The split lines and the
+			// allocations get the tenants of their transactions and plans, so they can refer to them.
+			// Dropping a referenced table would delete the referencing rows through the foreign keys, so those are
+			// copied aside and dropped first.
+			`CREATE TABLE transaction_splits_old AS
+				SELECT transactions.tenant_id, transaction_splits.* FROM transaction_splits
+				JOIN transactions ON transactions.id = transaction_splits.transaction_id`,
+			`DROP TABLE transaction_splits`,
+			`CREATE TABLE budget_plan_allocations_old AS
+				SELECT budget_plans.tenant_id, budget_plan_allocations.* FROM budget_plan_allocations
+				JOIN budget_plans ON budget_plans.id = budget_plan_allocations.plan_id`,
+			`DROP TABLE budget_plan_allocations`,
+			// The seq values are kept, so the full text search index stays valid.
+			`CREATE TABLE transactions_new (
+				seq         INTEGER PRIMARY KEY,
+				tenant_id   TEXT    NOT NULL,
+				id          TEXT    NOT NULL,
+				amount      INTEGER NOT NULL,
+				timestamp   INTEGER NOT NULL,
+				account_id  TEXT    NOT NULL,
+				category    TEXT    NOT NULL,
+				notes       TEXT    NOT NULL,
+				transfer_id TEXT    NOT NULL,
+				external_id TEXT    NOT NULL,
+				tags        TEXT    NOT NULL,
+				UNIQUE (tenant_id, id)
+			)`,
+			`INSERT INTO transactions_new (seq, tenant_id, id, amount, timestamp, account_id, category, notes,
+				transfer_id, external_id, tags)
+				SELECT seq, tenant_id, id, amount, timestamp, account_id, category, notes, transfer_id, external_id, tags
+				FROM transactions`,
+			// Dropping the table also drops its indexes and triggers, which are created again below.
+			`DROP TABLE transactions`,
+			`ALTER TABLE transactions_new RENAME TO transactions`,
+			`CREATE INDEX transactions_account_id_idx ON transactions (account_id)`,
+			`CREATE INDEX transactions_transfer_id_idx ON transactions (transfer_id)`,
+			`CREATE INDEX transactions_external_id_idx ON transactions (account_id, external_id)`,
+			`CREATE INDEX transactions_tenant_id_idx ON transactions (tenant_id, timestamp)`,
+			`CREATE TRIGGER transactions_fts_insert AFTER INSERT ON transactions BEGIN
+				INSERT INTO transactions_fts (rowid, notes) VALUES (new.seq, new.notes);
+			END`,
+			`CREATE TRIGGER transactions_fts_delete AFTER DELETE ON transactions BEGIN
+				INSERT INTO transactions_fts (transactions_fts, rowid, notes) VALUES ('delete', old.seq, old.notes);
+			END`,
+			`CREATE TRIGGER transactions_fts_update AFTER UPDATE OF notes ON transactions BEGIN
+				INSERT INTO transactions_fts (transactions_fts, rowid, notes) VALUES ('delete', old.seq, old.notes);
+				INSERT INTO transactions_fts (rowid, notes) VALUES (new.seq, new.notes);
+			END`,
+			`CREATE TABLE transaction_splits (
+				tenant_id      TEXT    NOT NULL,
+				transaction_id TEXT    NOT NULL,
+				position       INTEGER NOT NULL,
+				amount         INTEGER NOT NULL,
+				category       TEXT    NOT NULL,
+				notes          TEXT    NOT NULL,
+				PRIMARY KEY (tenant_id, transaction_id, position),
+				FOREIGN KEY (tenant_id, transaction_id) REFERENCES transactions (tenant_id, id) ON DELETE CASCADE
+			)`,
+			`INSERT INTO transaction_splits (tenant_id, transaction_id, position, amount, category, notes)
+				SELECT tenant_id, transaction_id, position, amount, category, notes FROM transaction_splits_old`,
+			`DROP TABLE transaction_splits_old`,
+			`CREATE TABLE budget_plans_new (
+				tenant_id   TEXT    NOT NULL,
+				id          TEXT    NOT NULL,
+				name        TEXT    NOT NULL,
+				currency    TEXT    NOT NULL,
+				valid_from  INTEGER NOT NULL,
+				valid_until INTEGER NOT NULL,
+				PRIMARY KEY (tenant_id, id)
+			)`,
+			`INSERT INTO budget_plans_new (tenant_id, id, name, currency, valid_from, valid_until)
+				SELECT tenant_id, id, name, currency, valid_from, valid_until FROM budget_plans`,
+			`DROP TABLE budget_plans`,
+			`ALTER TABLE budget_plans_new RENAME TO budget_plans`,
+			`CREATE TABLE budget_plan_allocations (
+				tenant_id    TEXT    NOT NULL,
+				plan_id      TEXT    NOT NULL,
+				position     INTEGER NOT NULL,
+				budget_group TEXT    NOT NULL,
+				percent      INTEGER,
+				amount       INTEGER,
+				PRIMARY KEY (tenant_id, plan_id, position),
+				FOREIGN KEY (tenant_id, plan_id) REFERENCES budget_plans (tenant_id, id) ON DELETE CASCADE
+			)`,
+			`INSERT INTO budget_plan_allocations (tenant_id, plan_id, position, budget_group, percent, amount)
+				SELECT tenant_id, plan_id, position, budget_group, percent, amount FROM budget_plan_allocations_old`,
+			`DROP TABLE budget_plan_allocations_old`,
+			`CREATE TABLE exchange_rates_new (
+				tenant_id TEXT    NOT NULL,
+				id        TEXT    NOT NULL,
+				base      TEXT    NOT NULL,
+				quote     TEXT    NOT NULL,
+				rate      TEXT    NOT NULL,
+				timestamp INTEGER NOT NULL,
+				PRIMARY KEY (tenant_id, id),
+				UNIQUE (tenant_id, base, quote, timestamp)
+			)`,
+			`INSERT INTO exchange_rates_new (tenant_id, id, base, quote, rate, timestamp)
+				SELECT tenant_id, id, base, quote, rate, timestamp FROM exchange_rates`,
+			`DROP TABLE exchange_rates`,
+			`ALTER TABLE exchange_rates_new RENAME TO exchange_rates`,
+			`CREATE TABLE recurring_templates_new (
+				tenant_id  TEXT    NOT NULL,
+				id         TEXT    NOT NULL,
+				name       TEXT    NOT NULL,
+				amount     INTEGER NOT NULL,
+				account_id TEXT    NOT NULL,
+				category   TEXT    NOT NULL,
+				notes      TEXT    NOT NULL,
+				rule       TEXT    NOT NULL,
+				start_time INTEGER NOT NULL,
+				end_time   INTEGER NOT NULL,
+				next_run   INTEGER NOT NULL,
+				PRIMARY KEY (tenant_id, id)
+			)`,
+			`INSERT INTO recurring_templates_new (tenant_id, id, name, amount, account_id, category, notes, rule,
+				start_time, end_time, next_run)
+				SELECT tenant_id, id, name, amount, account_id, category, notes, rule, start_time, end_time, next_run
+				FROM recurring_templates`,
+			`DROP TABLE recurring_templates`,
+			`ALTER TABLE recurring_templates_new RENAME TO recurring_templates`,
+			`CREATE TABLE import_profiles_new (
+				tenant_id         TEXT NOT NULL,
+				id                TEXT NOT NULL,
+				name              TEXT NOT NULL,
+				account_id        TEXT NOT NULL,
+				delimiter         TEXT NOT NULL,
+				decimal_separator TEXT NOT NULL,
+				date_column       TEXT NOT NULL,
+				date_format       TEXT NOT NULL,
+				amount_column     TEXT NOT NULL,
+				debit_column      TEXT NOT NULL,
+				credit_column     TEXT NOT NULL,
+				notes_column      TEXT NOT NULL,
+				category_column   TEXT NOT NULL,
+				credit_category   TEXT NOT NULL,
+				debit_category    TEXT NOT NULL,
+				PRIMARY KEY (tenant_id, id)
+			)`,
+			`INSERT INTO import_profiles_new (tenant_id, id, name, account_id, delimiter, decimal_separator,
+				date_column, date_format, amount_column, debit_column, credit_column, notes_column, category_column,
+				credit_category, debit_category)
+				SELECT tenant_id, id, name, account_id, delimiter, decimal_separator, date_column, date_format,
+				amount_column, debit_column, credit_column, notes_column, category_column, credit_category,
+				debit_category FROM import_profiles`,
+			`DROP TABLE import_profiles`,
+			`ALTER TABLE import_profiles_new RENAME TO import_profiles`,
+			`CREATE TABLE rules_new (
+				tenant_id     TEXT    NOT NULL,
+				id            TEXT    NOT NULL,
+				name          TEXT    NOT NULL,
+				priority      INTEGER NOT NULL,
+				notes_pattern TEXT    NOT NULL,
+				min_amount    INTEGER,
+				max_amount    INTEGER,
+				account_id    TEXT    NOT NULL,
+				weekdays      TEXT    NOT NULL,
+				set_category  TEXT    NOT NULL,
+				set_notes     TEXT    NOT NULL,
+				add_tags      TEXT    NOT NULL,
+				PRIMARY KEY (tenant_id, id)
+			)`,
+			`INSERT INTO rules_new (tenant_id, id, name, priority, notes_pattern, min_amount, max_amount, account_id,
+				weekdays, set_category, set_notes, add_tags)
+				SELECT tenant_id, id, name, priority, notes_pattern, min_amount, max_amount, account_id, weekdays,
+				set_category, set_notes, add_tags FROM rules`,
+			`DROP TABLE rules`,
+			`ALTER TABLE rules_new RENAME TO rules`,
+		},
+	},
+}
+
+// newSQLiteRepositories provides the Repositories backed by the configured SQLite database.
+// Panic is allowed here because storage is crucial to the application.
+func newSQLiteRepositories() *Repositories {
+	repos, err := newSQLiteRepositoriesWithDB(context.Background(), sqlite.GetClient())
+	if err != nil {
+		panic(err)
+	}
+	return repos
+}
+
+// newSQLiteRepositoriesWithDB migrates the provided SQLite database and provides the Repositories backed by it.
+func newSQLiteRepositoriesWithDB(ctx context.Context, db *sql.DB) (*Repositories, error) {
+	if err := runSQLMigrations(ctx, db, sqliteMigrations); err != nil {
+		return nil, fmt.Errorf("failed to migrate sqlite database: %w", err)
+	}
+
+	dialect := &sqliteDialect{}
+
+	repos := &Repositories{
+		Accounts:      &sqlAccountRepository{db: db, dialect: dialect},
+		Transactions:  &sqlTransactionRepository{db: db, dialect: dialect},
+		Categories:    &sqlCategoryRepository{db: db, dialect: dialect},
+		ExchangeRates: &sqlExchangeRateRepository{db: db, dialect: dialect},
+		BudgetPlans:   &sqlBudgetPlanRepository{db: db, dialect: dialect},
+		Recurring:     &sqlRecurringTemplateRepository{db: db, dialect: dialect},
+		Imports:       &sqlImportProfileRepository{db: db, dialect: dialect},
+		Rules:         &sqlRuleRepository{db: db, dialect: dialect},
+		Backups:       &sqlBackupRepository{db: db, dialect: dialect},
+		Users:         &sqlUserRepository{db: db, dialect: dialect},
+		Members:       &sqlMemberRepository{db: db, dialect: dialect},
+		Tokens:        &sqlTokenRepository{db: db, dialect: dialect},
+	}
+
+	if err := seedAdminUser(ctx, repos); err != nil {
+		return nil, fmt.Errorf("failed to seed sqlite admin user: %w", err)
+	}
+
+	return repos, nil
+}
+
+// sqliteDialect implements sqlDialect for SQLite.
+type sqliteDialect struct{}
+
+func (s *sqliteDialect) name() string {
+	return "sqlite"
+}
+
+func (s *sqliteDialect) rebind(query string) string {
+	// SQLite supports the "?" placeholders natively.
+	return query
+}
+
+// textSearchClause translates a parsed $text search into an FTS5 query on the transaction notes.
+func (s *sqliteDialect) textSearchClause(search *textSearch) (string, []interface{}) {
+	var positive string
+	// If there are phrases, all of them must be present and the terms are irrelevant.
+	if len(search.Phrases) > 0 {
+		positive = joinFTS5Strings(search.Phrases, " AND ")
+	} else {
+		positive = joinFTS5Strings(search.Terms, " OR ")
+	}
+
+	// Without any positive terms, nothing can match, just like in MongoDB.
+	if positive == "" {
+		return "0", nil
+	}
+
+	query := positive
+	if len(search.NegatedTerms) > 0 {
+		query = fmt.Sprintf("(%s) NOT (%s)", positive, joinFTS5Strings(search.NegatedTerms, " OR "))
+	}
+
+	return "seq IN (SELECT rowid FROM transactions_fts WHERE transactions_fts MATCH ?)", []interface{}{query}
+}
+
+// joinFTS5Strings quotes the values as FTS5 strings and joins them with the provided separator.
+func joinFTS5Strings(values []string, separator string) string {
+	quoted := make([]string, len(values))
+	for idx, value := range values {
+		quoted[idx] = `"` + strings.ReplaceAll(value, `"`, `""`) + `"`
+	}
+	return strings.Join(quoted, separator)
+}
+
+func (s *sqliteDialect) noLimit() interface{} {
+	// SQLite treats a negative limit as no limit.
+	return -1
+}
+
+func (s *sqliteDialect) accountsOrderColumn() string {
+	return "rowid"
+}
+
+func (s *sqliteDialect) isUniqueViolation(err error) bool {
+	return isSQLiteConstraintError(err, sqlitelib.SQLITE_CONSTRAINT_PRIMARYKEY, sqlitelib.SQLITE_CONSTRAINT_UNIQUE)
+}
+
+func (s *sqliteDialect) isForeignKeyViolation(err error) bool {
+	return isSQLiteConstraintError(err, sqlitelib.SQLITE_CONSTRAINT_FOREIGNKEY)
+}
+
+// isSQLiteConstraintError checks if the error is a violation of any of the provided SQLite constraint codes.
+func isSQLiteConstraintError(err error, codes ...int) bool {
+	var sqliteErr *sqlitedriver.Error
+	if !errors.As(err, &sqliteErr) {
+		return false
+	}
+
+	for _, code := range codes {
+		if sqliteErr.Code() == code {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,180 @@
+package database
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/shivanshkc/ledgerkeep/src/database/sqlite"
+	"github.com/shivanshkc/ledgerkeep/src/models"
+)
+
+func TestSQLiteMigrationsAreIdempotent(t *testing.T) {
+	ctx := tenantContext(DefaultTenantID)
+	path := filepath.Join(t.TempDir(), "test.db")
+
+	// Migrating the same database file twice, as happens upon every application restart.
+	for i := 0; i < 2; i++ {
+		db, err := sqlite.Open(path)
+		if err != nil {
+			t.Fatalf("failed to open sqlite database: %+v", err)
+		}
+
+		repos, err := newSQLiteRepositoriesWithDB(ctx, db)
+		if err != nil {
+			t.Fatalf("failed to create sqlite repositories: %+v", err)
+		}
+
+		if err := repos.Accounts.InsertAccount(ctx, &models.AccountDTO{ID: fmt.Sprintf("acc%d", i)}); err != nil {
+			t.Fatalf("unexpected error in InsertAccount: %+v", err)
+		}
+		_ = db.Close()
+	}
+
+	db, err := sqlite.Open(path)
+	if err != nil {
+		t.Fatalf("failed to open sqlite database: %+v", err)
+	}
+	defer func() { _ = db.Close() }()
+
+	var version int
+	if err := db.QueryRow("SELECT MAX(version) FROM schema_migrations").Scan(&version); err != nil {
+		t.Fatalf("failed to read schema version: %+v", err)
+	}
+	if version != len(sqliteMigrations) {
+		t.Fatalf("expected schema version %d, got %d", len(sqliteMigrations), version)
+	}
+}
+
+func TestSQLiteTextSearchClause(t *testing.T) {
+	testCases := []struct {
+		search        string
+		expectedQuery interface{}
+	}{
+		{search: "rent salary", expectedQuery: `"rent" OR "salary"`},
+		{search: `"monthly rent" salary`, expectedQuery: `"monthly rent"`},
+		{search: "rent -deposit", expectedQuery: `("rent") NOT ("deposit")`},
+	}
+
+	for _, testCase := range testCases {
+		_, args := (&sqliteDialect{}).textSearchClause(parseTextSearch(testCase.search))
+		if len(args) != 1 || args[0] != testCase.expectedQuery {
+			t.Errorf("search %q: expected query %q, got %+v", testCase.search, testCase.expectedQuery, args)
+		}
+	}
+
+	// Without positive terms, nothing can match.
+	if clause, _ := (&sqliteDialect{}).textSearchClause(parseTextSearch("-deposit")); clause != "0" {
+		t.Errorf("expected a clause that matches nothing, got %q", clause)
+	}
+}
+
+func TestSQLiteAmountMigration(t *testing.T) {
+	ctx := tenantContext(DefaultTenantID)
+
+	db, err := sqlite.Open(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("failed to open sqlite database: %+v", err)
+	}
+	defer func() { _ = db.Close() }()
+
+	// Creating a database with the float amounts that were stored before the amount migration.
+	if err := runSQLMigrations(ctx, db, sqliteMigrations[:2]); err != nil {
+		t.Fatalf("failed to apply the older migrations: %+v", err)
+	}
+	legacyInserts := []string{
+		`INSERT INTO accounts (id, name) VALUES ('bank', 'Bank')`,
+		`INSERT INTO transactions (id, amount, timestamp, account_id, category, notes)
+			VALUES ('623f1d3e2b3a9c0f5e8d7a11', 0.1, 1, 'bank', 'earnings', 'Monthly salary')`,
+		`INSERT INTO transactions (id, amount, timestamp, account_id, category, notes)
+			VALUES ('623f1d3e2b3a9c0f5e8d7a12', -12.34, 2, 'bank', 'luxury', 'Cinema')`,
+	}
+	for _, query := range legacyInserts {
+		if _, err := db.ExecContext(ctx, query); err != nil {
+			t.Fatalf("failed to insert legacy data: %+v", err)
+		}
+	}
+
+	repos, err := newSQLiteRepositoriesWithDB(ctx, db)
+	if err != nil {
+		t.Fatalf("failed to create sqlite repositories: %+v", err)
+	}
+
+	balances, err := repos.Accounts.GetAccountBalances(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error in GetAccountBalances: %+v", err)
+	}
+	if expected, _ := models.ParseMoney("-12.24"); balances["bank"] != expected {
+		t.Fatalf("expected balance %s, got %s", expected, balances["bank"])
+	}
+
+	// The full text search index must still refer to the right transactions.
+	transactions, _, err := repos.Transactions.ListTransactions(ctx, &ListTransactionsParams{
+		Filter:    map[string]interface{}{"$text": map[string]interface{}{"$search": "salary"}},
+		SortField: "timestamp",
+		SortOrder: 1,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error in ListTransactions: %+v", err)
+	}
+	if len(transactions) != 1 || transactions[0].Amount != 1000 {
+		t.Fatalf("unexpected transactions: %+v", transactions)
+	}
+}
+
+func TestSQLiteTenantIDMigration(t *testing.T) {
+	ctx := tenantContext(DefaultTenantID)
+
+	db, err := sqlite.Open(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("failed to open sqlite database: %+v", err)
+	}
+	defer func() { _ = db.Close() }()
+
+	// Creating a database with the split lines and the allocations that had no tenants of their own.
+	if err := runSQLMigrations(ctx, db, sqliteMigrations[:15]); err != nil {
+		t.Fatalf("failed to apply the older migrations: %+v", err)
+	}
+	legacyInserts := []string{
+		`INSERT INTO accounts (tenant_id, id, name, currency) VALUES ('default', 'bank', 'Bank', '')`,
+		`INSERT INTO transactions (tenant_id, id, amount, timestamp, account_id, category, notes)
+			VALUES ('default', '623f1d3e2b3a9c0f5e8d7a11', -300, 1, 'bank', 'split', 'Weekly groceries')`,
+		`INSERT INTO transaction_splits (transaction_id, position, amount, category, notes)
+			VALUES ('623f1d3e2b3a9c0f5e8d7a11', 0, -100, 'food', ''), ('623f1d3e2b3a9c0f5e8d7a11', 1, -200, 'home', '')`,
+		`INSERT INTO budget_plans (tenant_id, id, name, currency, valid_from, valid_until)
+			VALUES ('default', '623f1d3e2b3a9c0f5e8d7a12', 'Plan', 'EUR', 1, 0)`,
+		`INSERT INTO budget_plan_allocations (plan_id, position, budget_group, percent)
+			VALUES ('623f1d3e2b3a9c0f5e8d7a12', 0, 'needs', 1000000)`,
+	}
+	for _, query := range legacyInserts {
+		if _, err := db.ExecContext(ctx, query); err != nil {
+			t.Fatalf("failed to insert legacy data: %+v", err)
+		}
+	}
+
+	repos, err := newSQLiteRepositoriesWithDB(ctx, db)
+	if err != nil {
+		t.Fatalf("failed to create sqlite repositories: %+v", err)
+	}
+
+	// The full text search index must still refer to the right transactions, along with their split lines.
+	transactions, _, err := repos.Transactions.ListTransactions(ctx, &ListTransactionsParams{
+		Filter:    map[string]interface{}{"$text": map[string]interface{}{"$search": "groceries"}},
+		SortField: "timestamp",
+		SortOrder: 1,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error in ListTransactions: %+v", err)
+	}
+	if len(transactions) != 1 || len(transactions[0].Splits) != 2 || transactions[0].Splits[1].Category != "home" {
+		t.Fatalf("unexpected transactions: %+v", transactions)
+	}
+
+	plans, err := repos.BudgetPlans.ListBudgetPlans(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error in ListBudgetPlans: %+v", err)
+	}
+	if len(plans) != 1 || len(plans[0].Allocations) != 1 || plans[0].Allocations[0].BudgetGroup != "needs" {
+		t.Fatalf("unexpected budget plans: %+v", plans)
+	}
+}
@@ -0,0 +1,201 @@
+// Package duplicates detects the transactions that are likely to be the same transaction entered more than once,
+// like a card payment that was entered by hand and then imported from the bank statement.
+package duplicates
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/shivanshkc/ledgerkeep/src/models"
+)
+
+// Detector flags the transactions of the same account and the same amount, whose timestamps are close to each other
+// and whose notes are similar, as duplicates.
+type Detector struct {
+	// Window is the max difference in seconds between the timestamps of duplicates.
+	Window int64
+	// MinSimilarity is the min similarity, from 0 to 1, of the notes of duplicates. Zero ignores the notes.
+	MinSimilarity float64
+}
+
+// IsDuplicate checks if the two transactions are duplicates of each other.
+// The transactions need the amount, timestamp, account ID and notes fields.
+func (d *Detector) IsDuplicate(a *models.TransactionDTO, b *models.TransactionDTO) bool {
+	if a.AccountID != b.AccountID || a.Amount != b.Amount {
+		return false
+	}
+
+	diff := a.Timestamp - b.Timestamp
+	if diff < 0 {
+		diff = -diff
+	}
+	if diff > d.Window {
+		return false
+	}
+
+	return d.MinSimilarity <= 0 || NotesSimilarity(a.Notes, b.Notes) >= d.MinSimilarity
+}
+
+// FindGroups provides the groups of the transactions that are duplicates of each other.
+//
+// A transaction is in the same group as all of its duplicates, and their duplicates in turn. The groups are in the
+// order of their first transactions, and the transactions of a group are in the order of their timestamps and IDs.
+func (d *Detector) FindGroups(transactions []*models.TransactionDTO) [][]*models.TransactionDTO {
+	sorted := make([]*models.TransactionDTO, len(transactions))
+	copy(sorted, transactions)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].Timestamp != sorted[j].Timestamp {
+			return sorted[i].Timestamp < sorted[j].Timestamp
+		}
+		return sorted[i].ID < sorted[j].ID
+	})
+
+	// Only the transactions of the same account and amount can be duplicates, so they are compared in buckets.
+	type bucketKey struct {
+		accountID string
+		amount    models.Money
+	}
+	buckets := map[bucketKey][]int{}
+	for idx, transaction := range sorted {
+		key := bucketKey{accountID: transaction.AccountID, amount: transaction.Amount}
+		buckets[key] = append(buckets[key], idx)
+	}
+
+	// parents links the duplicates into groups, as a disjoint-set forest over the indices of the sorted transactions.
+	parents := make([]int, len(sorted))
+	for idx := range parents {
+		parents[idx] = idx
+	}
+	var find func(idx int) int
+	find = func(idx int) int {
+		if parents[idx] != idx {
+			parents[idx] = find(parents[idx])
+		}
+		return parents[idx]
+	}
+
+	for _, bucket := range buckets {
+		for i, iIdx := range bucket {
+			for _, jIdx := range bucket[i+1:] {
+				// The bucket is sorted by timestamps, so none of the later transactions are in the window either.
+				if sorted[jIdx].Timestamp-sorted[iIdx].Timestamp > d.Window {
+					break
+				}
+				if d.IsDuplicate(sorted[iIdx], sorted[jIdx]) {
+					parents[find(jIdx)] = find(iIdx)
+				}
+			}
+		}
+	}
+
+	// Collecting the groups in the order of their first transactions.
+	groupIndices := map[int]int{}
+	var groups [][]*models.TransactionDTO
+	for idx, transaction := range sorted {
+		root := find(idx)
+		groupIdx, exists := groupIndices[root]
+		if !exists {
+			groupIdx = len(groups)
+			groupIndices[root] = groupIdx
+			groups = append(groups, nil)
+		}
+		groups[groupIdx] = append(groups[groupIdx], transaction)
+	}
+
+	// The transactions without duplicates are not groups.
+	duplicateGroups := make([][]*models.TransactionDTO, 0, len(groups))
+	for _, group := range groups {
+		if len(group) > 1 {
+			duplicateGroups = append(duplicateGroups, group)
+		}
+	}
+	return duplicateGroups
+}
+
+// NotesSimilarity provides the similarity of two notes, from 0 for unrelated notes to 1 for the same notes.
+//
+// The notes are compared regardless of the case, punctuation and spacing. The similarity is the higher of the edit
+// distance similarity, which suits the typos, and the share of the words of the shorter notes that the longer notes
+// have, which suits the bank statements that add details to the notes, like "ACME CORP CARD 1234" for "Acme Corp".
+// Empty notes tell nothing about a transaction, so they are similar to all notes.
+func NotesSimilarity(a string, b string) float64 {
+	aWords, bWords := normalizeNotes(a), normalizeNotes(b)
+	if len(aWords) == 0 || len(bWords) == 0 {
+		return 1
+	}
+
+	aNotes, bNotes := strings.Join(aWords, " "), strings.Join(bWords, " ")
+	maxLength := maxInt(len([]rune(aNotes)), len([]rune(bNotes)))
+	editSimilarity := 1 - float64(editDistance(aNotes, bNotes))/float64(maxLength)
+
+	// The words of the shorter notes are looked up in the longer notes.
+	if len(aWords) > len(bWords) {
+		aWords, bWords = bWords, aWords
+	}
+	longer := map[string]bool{}
+	for _, word := range bWords {
+		longer[word] = true
+	}
+	var shared int
+	for _, word := range aWords {
+		if longer[word] {
+			shared++
+		}
+	}
+	wordSimilarity := float64(shared) / float64(len(aWords))
+
+	if wordSimilarity > editSimilarity {
+		return wordSimilarity
+	}
+	return editSimilarity
+}
+
+// normalizeNotes provides the lowercase words of the notes, without the punctuation.
+func normalizeNotes(notes string) []string {
+	return strings.FieldsFunc(strings.ToLower(notes), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// editDistance provides the Levenshtein distance between two strings, in runes.
+func editDistance(a string, b string) int {
+	aRunes, bRunes := []rune(a), []rune(b)
+
+	// Only the previous row of the distance matrix is kept.
+	previous := make([]int, len(bRunes)+1)
+	current := make([]int, len(bRunes)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(aRunes); i++ {
+		current[0] = i
+		for j := 1; j <= len(bRunes); j++ {
+			cost := 1
+			if aRunes[i-1] == bRunes[j-1] {
+				cost = 0
+			}
+			current[j] = minInt(minInt(previous[j]+1, current[j-1]+1), previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+
+	return previous[len(bRunes)]
+}
+
+// minInt provides the smaller of two ints.
+func minInt(a int, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+// maxInt provides the larger of two ints.
+func maxInt(a int, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
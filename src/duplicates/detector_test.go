@@ -0,0 +1,62 @@
+package duplicates
+
+import (
+	"testing"
+
+	"github.com/shivanshkc/ledgerkeep/src/models"
+)
+
+func TestNotesSimilarity(t *testing.T) {
+	testCases := []struct {
+		a, b     string
+		expected float64
+	}{
+		{a: "Groceries", b: "groceries!", expected: 1},
+		{a: "Acme Corp", b: "ACME CORP CARD 1234", expected: 1},
+		{a: "", b: "Coffee", expected: 1},
+		{a: "Grocereis", b: "Groceries", expected: 1 - 2.0/9},
+		{a: "Rent", b: "Coffee", expected: 0},
+	}
+
+	for _, testCase := range testCases {
+		if similarity := NotesSimilarity(testCase.a, testCase.b); similarity != testCase.expected {
+			t.Errorf("expected similarity %v for %q and %q, got: %v", testCase.expected, testCase.a, testCase.b, similarity)
+		}
+	}
+}
+
+func TestFindGroups(t *testing.T) {
+	detector := &Detector{Window: 100, MinSimilarity: 0.6}
+
+	transactions := []*models.TransactionDTO{
+		{ID: "1", Amount: -10, Timestamp: 1000, AccountID: "bank", Notes: "Coffee"},
+		{ID: "2", Amount: -10, Timestamp: 1050, AccountID: "bank", Notes: "COFFEE SHOP 42"},
+		// A duplicate of the second transaction, but not of the first, is in their group too.
+		{ID: "3", Amount: -10, Timestamp: 1120, AccountID: "bank", Notes: "Coffee shop"},
+		// Outside of the window.
+		{ID: "4", Amount: -10, Timestamp: 1300, AccountID: "bank", Notes: "Coffee"},
+		// Another account, amount or notes.
+		{ID: "5", Amount: -10, Timestamp: 1000, AccountID: "card", Notes: "Coffee"},
+		{ID: "6", Amount: -11, Timestamp: 1000, AccountID: "bank", Notes: "Coffee"},
+		{ID: "7", Amount: -10, Timestamp: 1010, AccountID: "bank", Notes: "Rent"},
+		{ID: "8", Amount: 500, Timestamp: 2000, AccountID: "bank", Notes: "Salary"},
+		{ID: "9", Amount: 500, Timestamp: 2000, AccountID: "bank", Notes: ""},
+	}
+
+	groups := detector.FindGroups(transactions)
+	if len(groups) != 2 || len(groups[0]) != 3 || len(groups[1]) != 2 {
+		t.Fatalf("unexpected groups: %+v", groups)
+	}
+	for idx, expectedID := range []string{"1", "2", "3"} {
+		if groups[0][idx].ID != expectedID {
+			t.Errorf("expected transaction %s at %d, got: %s", expectedID, idx, groups[0][idx].ID)
+		}
+	}
+	if groups[1][0].ID != "8" || groups[1][1].ID != "9" {
+		t.Errorf("unexpected group: %+v", groups[1])
+	}
+
+	if detector.IsDuplicate(transactions[0], transactions[2]) {
+		t.Errorf("expected the transactions outside of the window to not be duplicates")
+	}
+}
@@ -0,0 +1,58 @@
+package exports
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// csvWriter writes the rows as a CSV file with a header row.
+type csvWriter struct {
+	writer        *csv.Writer
+	headerWritten bool
+}
+
+// NewCSVWriter provides a Writer that writes a CSV file into the provided writer.
+func NewCSVWriter(writer io.Writer) Writer {
+	return &csvWriter{writer: csv.NewWriter(writer)}
+}
+
+func (c *csvWriter) Write(row *Row) error {
+	if err := c.writeHeader(); err != nil {
+		return err
+	}
+
+	if err := c.writer.Write([]string{row.ID, row.date(), strconv.FormatInt(row.Timestamp, 10), row.AccountID,
+		row.Currency, row.Category, row.Amount.String(), row.ClosingBal.String(), row.Notes, row.joinedTags(),
+		row.TransferID, row.ExternalID}); err != nil {
+		return fmt.Errorf("failed to write csv row: %w", err)
+	}
+	return nil
+}
+
+func (c *csvWriter) Close() error {
+	// An export without rows still has the header.
+	if err := c.writeHeader(); err != nil {
+		return err
+	}
+
+	c.writer.Flush()
+	if err := c.writer.Error(); err != nil {
+		return fmt.Errorf("failed to flush csv: %w", err)
+	}
+	return nil
+}
+
+// writeHeader writes the header row, unless it is written already.
+func (c *csvWriter) writeHeader() error {
+	if c.headerWritten {
+		return nil
+	}
+	c.headerWritten = true
+
+	if err := c.writer.Write(header); err != nil {
+		return fmt.Errorf("failed to write csv header: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+// Package exports writes the transactions of the ledger into files that other tools can read, like spreadsheets.
+//
+// The writers write every row as soon as it is provided, so an export of any size can be streamed.
+package exports
+
+import (
+	"strings"
+	"time"
+
+	"github.com/shivanshkc/ledgerkeep/src/models"
+)
+
+// Row is an exported transaction.
+type Row struct {
+	ID         string       `json:"id"`
+	Timestamp  int64        `json:"timestamp"`
+	AccountID  string       `json:"account_id"`
+	Currency   string       `json:"currency"`
+	Category   string       `json:"category"`
+	Amount     models.Money `json:"amount"`
+	ClosingBal models.Money `json:"closing_bal"`
+	Notes      string       `json:"notes"`
+	Tags       models.Tags  `json:"tags"`
+	TransferID string       `json:"transfer_id"`
+	ExternalID string       `json:"external_id"`
+}
+
+// Writer writes the rows of an export.
+type Writer interface {
+	// Write writes a row.
+	Write(row *Row) error
+	// Close completes the export. It does not close the underlying writer.
+	Close() error
+}
+
+// header is the header row of the tabular exports.
+var header = []string{"id", "date", "timestamp", "account_id", "currency", "category", "amount", "closing_bal", "notes",
+	"tags", "transfer_id", "external_id"}
+
+// dateLayout is the layout of the dates of the tabular exports, which are always in UTC.
+const dateLayout = "2006-01-02 15:04:05"
+
+// date provides the date of the row, as written by the tabular exports.
+func (r *Row) date() string {
+	return time.Unix(r.Timestamp, 0).UTC().Format(dateLayout)
+}
+
+// joinedTags provides the tags of the row, as written by the tabular exports.
+func (r *Row) joinedTags() string {
+	return strings.Join(r.Tags, ",")
+}
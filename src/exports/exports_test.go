@@ -0,0 +1,145 @@
+package exports
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"io/ioutil"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shivanshkc/ledgerkeep/src/models"
+)
+
+// testRows are the rows that the tests export.
+var testRows = []*Row{
+	{ID: "tx1", Timestamp: time.Date(2022, time.March, 1, 9, 30, 0, 0, time.UTC).Unix(), AccountID: "bank",
+		Currency: "INR", Category: "earnings", Amount: 1000000, ClosingBal: 1000000, Notes: "Salary"},
+	{ID: "tx2", Timestamp: time.Date(2022, time.March, 2, 18, 0, 0, 0, time.UTC).Unix(), AccountID: "bank",
+		Currency: "INR", Category: "luxury", Amount: -12505, ClosingBal: 987495, Notes: `Dinner at "A & B" <city>`,
+		Tags: models.Tags{"food", "weekend"}, ExternalID: "ofx:123"},
+}
+
+func TestCSVWriter(t *testing.T) {
+	buffer := &bytes.Buffer{}
+	writeRows(t, NewCSVWriter(buffer), testRows)
+
+	records, err := csv.NewReader(buffer).ReadAll()
+	if err != nil {
+		t.Fatalf("unexpected error in reading csv: %+v", err)
+	}
+	if len(records) != 3 || strings.Join(records[0], ",") != strings.Join(header, ",") {
+		t.Fatalf("unexpected records: %+v", records)
+	}
+
+	expected := []string{"tx2", "2022-03-02 18:00:00", "1646244000", "bank", "INR", "luxury", "-1.2505", "98.7495",
+		`Dinner at "A & B" <city>`, "food,weekend", "", "ofx:123"}
+	if strings.Join(records[2], "|") != strings.Join(expected, "|") {
+		t.Fatalf("expected record %+v, but got %+v", expected, records[2])
+	}
+
+	// An export without rows still has the header.
+	buffer.Reset()
+	writeRows(t, NewCSVWriter(buffer), nil)
+	if buffer.String() != strings.Join(header, ",")+"\n" {
+		t.Fatalf("unexpected empty csv: %q", buffer.String())
+	}
+}
+
+func TestJSONLWriter(t *testing.T) {
+	buffer := &bytes.Buffer{}
+	writeRows(t, NewJSONLWriter(buffer), testRows)
+
+	lines := strings.Split(strings.TrimSuffix(buffer.String(), "\n"), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 lines, but got: %q", buffer.String())
+	}
+
+	first := map[string]interface{}{}
+	if err := json.Unmarshal([]byte(lines[0]), &first); err != nil {
+		t.Fatalf("unexpected error in decoding line: %+v", err)
+	}
+	if first["id"] != "tx1" || first["amount"] != float64(100) || first["closing_bal"] != float64(100) {
+		t.Fatalf("unexpected first line: %s", lines[0])
+	}
+	if tags, ok := first["tags"].([]interface{}); !ok || len(tags) != 0 {
+		t.Fatalf("expected empty tags, but got: %s", lines[0])
+	}
+
+	second := &Row{}
+	if err := json.Unmarshal([]byte(lines[1]), second); err != nil {
+		t.Fatalf("unexpected error in decoding line: %+v", err)
+	}
+	if second.Notes != testRows[1].Notes || second.Amount != -12505 || len(second.Tags) != 2 {
+		t.Fatalf("unexpected second line: %s", lines[1])
+	}
+}
+
+func TestXLSXWriter(t *testing.T) {
+	buffer := &bytes.Buffer{}
+	writeRows(t, NewXLSXWriter(buffer), testRows)
+
+	archive, err := zip.NewReader(bytes.NewReader(buffer.Bytes()), int64(buffer.Len()))
+	if err != nil {
+		t.Fatalf("unexpected error in opening xlsx: %+v", err)
+	}
+
+	parts := map[string]string{}
+	for _, file := range archive.File {
+		reader, err := file.Open()
+		if err != nil {
+			t.Fatalf("unexpected error in opening xlsx part: %+v", err)
+		}
+		content, err := ioutil.ReadAll(reader)
+		_ = reader.Close()
+		if err != nil {
+			t.Fatalf("unexpected error in reading xlsx part: %+v", err)
+		}
+		parts[file.Name] = string(content)
+	}
+
+	for _, name := range []string{"[Content_Types].xml", "_rels/.rels", "xl/workbook.xml", "xl/_rels/workbook.xml.rels"} {
+		if _, exists := parts[name]; !exists {
+			t.Fatalf("expected xlsx part %s", name)
+		}
+	}
+
+	sheet := parts["xl/worksheets/sheet1.xml"]
+	expected := []string{
+		`<row r="1"><c r="A1" t="inlineStr"><is><t xml:space="preserve">id</t></is></c>`,
+		`<c r="G3"><v>-1.2505</v></c><c r="H3"><v>98.7495</v></c>`,
+		`<t xml:space="preserve">Dinner at &#34;A &amp; B&#34; &lt;city&gt;</t>`,
+		`<row r="3">`,
+	}
+	for _, part := range expected {
+		if !strings.Contains(sheet, part) {
+			t.Fatalf("expected sheet to contain %s, but got: %s", part, sheet)
+		}
+	}
+	if strings.Contains(sheet, `<row r="4">`) || !strings.HasSuffix(sheet, xlsxSheetEnd) {
+		t.Fatalf("unexpected sheet: %s", sheet)
+	}
+}
+
+func TestXLSXColumnName(t *testing.T) {
+	for idx, expected := range map[int]string{0: "A", 11: "L", 25: "Z", 26: "AA", 51: "AZ", 52: "BA", 701: "ZZ", 702: "AAA"} {
+		if name := xlsxColumnName(idx); name != expected {
+			t.Fatalf("expected column %d to be %s, but got %s", idx, expected, name)
+		}
+	}
+}
+
+// writeRows writes the rows using the writer, and closes it.
+func writeRows(t *testing.T, writer Writer, rows []*Row) {
+	t.Helper()
+	for _, row := range rows {
+		if err := writer.Write(row); err != nil {
+			t.Fatalf("unexpected error in Write: %+v", err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatalf("unexpected error in Close: %+v", err)
+	}
+}
@@ -0,0 +1,465 @@
+package exports
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/shivanshkc/ledgerkeep/src/models"
+)
+
+// JournalFormat is the syntax of a plain-text accounting journal.
+type JournalFormat int
+
+const (
+	// JournalLedger is the syntax of ledger-cli.
+	JournalLedger JournalFormat = iota
+	// JournalHLedger is the syntax of hledger, which differs from that of ledger-cli only in the tags.
+	JournalHLedger
+	// JournalBeancount is the syntax of beancount.
+	JournalBeancount
+)
+
+// These are the accounts of the journal that do not come from the accounts or the categories of the ledger.
+const (
+	// journalOpeningAccount balances the opening balances of the accounts.
+	journalOpeningAccount = "Equity:Opening-Balances"
+	// journalTransferAccount balances the legs of the transfers whose other legs are not exported.
+	journalTransferAccount = "Equity:Transfers"
+)
+
+// journalDateLayout is the layout of the dates of the journals, which all three syntaxes accept.
+const journalDateLayout = "2006-01-02"
+
+// JournalWriter writes the accounts and the transactions of the ledger as a plain-text accounting journal.
+//
+// The accounts become the Assets accounts of the journal. The categories of the income budget group become the Income
+// accounts, and the other categories become the Expenses accounts. The notes of a transaction become its payee in
+// ledger-cli and hledger, and its narration in beancount. The legs of a transfer become a single transaction, with a
+// price if the currencies of the legs differ.
+type JournalWriter struct {
+	writer io.Writer
+	format JournalFormat
+
+	// accounts are the journal accounts of the accounts of the ledger, keyed by their IDs.
+	accounts map[string]*journalAccount
+	// accountIDs are the IDs of the accounts, in the order that they were provided.
+	accountIDs []string
+	// categories are the journal accounts of the categories, keyed by their IDs.
+	categories map[string]string
+	// names are all the journal account names in use, which keeps them unique.
+	names map[string]bool
+	// declared are the journal accounts that are declared already.
+	declared map[string]bool
+	// openDate is the date that the accounts are declared on.
+	openDate string
+
+	// pending are the transfer legs whose other legs are not written yet, keyed by their transfer IDs.
+	pending map[string]*models.TransactionDTO
+	// err is the first error of the writer, which fails all the later calls.
+	err error
+}
+
+// journalAccount is an Assets account of the journal.
+type journalAccount struct {
+	name     string
+	currency string
+}
+
+// journalEntry is a transaction of the journal.
+type journalEntry struct {
+	timestamp int64
+	notes     string
+	tags      []string
+	// metadata are the key-value pairs that link the entry to the ledger, like its ID.
+	metadata [][2]string
+	postings []*journalPosting
+}
+
+// journalPosting is a posting of a journal transaction.
+type journalPosting struct {
+	account  string
+	amount   models.Money
+	currency string
+	// totalPrice is the total price of the posting in the priceCurrency, if the entry has more than one currency.
+	totalPrice    models.Money
+	priceCurrency string
+	comment       string
+}
+
+// NewJournalWriter provides a JournalWriter that writes the journal in the provided format into the provided writer.
+// The accounts should have their currencies.
+func NewJournalWriter(writer io.Writer, format JournalFormat, accounts []*models.AccountDTO,
+	categories []*models.CategoryDTO) *JournalWriter {
+	j := &JournalWriter{
+		writer:     writer,
+		format:     format,
+		accounts:   map[string]*journalAccount{},
+		categories: map[string]string{},
+		names:      map[string]bool{journalOpeningAccount: true, journalTransferAccount: true},
+		declared:   map[string]bool{},
+		pending:    map[string]*models.TransactionDTO{},
+	}
+
+	for _, account := range accounts {
+		j.accounts[account.ID] = &journalAccount{name: j.newName("Assets", account.ID), currency: account.Currency}
+		j.accountIDs = append(j.accountIDs, account.ID)
+	}
+	for _, category := range categories {
+		root := "Expenses"
+		if category.BudgetGroup == models.BudgetGroupIncome {
+			root = "Income"
+		}
+		j.categories[category.ID] = j.newName(root, category.ID)
+	}
+
+	return j
+}
+
+// Open declares all the accounts on the date of the openTime, and writes the opening balances of the accounts as of
+// the same date. The balances are keyed by the account IDs. It should be called before any of the transactions is
+// written, and the openTime should not be after any of them.
+func (j *JournalWriter) Open(openTime int64, balances map[string]models.Money) error {
+	j.openDate = journalDate(openTime)
+
+	for _, accountID := range j.accountIDs {
+		account := j.accounts[accountID]
+		j.declare(account.name, account.currency)
+	}
+	for _, name := range j.sortedCategoryAccounts() {
+		j.declare(name, "")
+	}
+	j.declare(journalOpeningAccount, "")
+	j.declare(journalTransferAccount, "")
+	j.print("\n")
+
+	for _, accountID := range j.accountIDs {
+		balance := balances[accountID]
+		if balance == 0 {
+			continue
+		}
+		account := j.accounts[accountID]
+		j.writeEntry(&journalEntry{
+			timestamp: openTime,
+			notes:     "Opening balance",
+			postings: []*journalPosting{
+				{account: account.name, amount: balance, currency: account.currency},
+				{account: journalOpeningAccount, amount: -balance, currency: account.currency},
+			},
+		})
+	}
+
+	return j.err
+}
+
+// Write writes a transaction into the journal. The transactions with splits should have their split lines.
+//
+// A transfer leg is held back until its other leg is written, and both of them are written as one transaction.
+func (j *JournalWriter) Write(transaction *models.TransactionDTO) error {
+	if j.err != nil {
+		return j.err
+	}
+
+	if transaction.TransferID == "" {
+		j.writeTransaction(transaction)
+		return j.err
+	}
+
+	other, exists := j.pending[transaction.TransferID]
+	if !exists {
+		j.pending[transaction.TransferID] = transaction
+		return nil
+	}
+	delete(j.pending, transaction.TransferID)
+
+	j.writeTransfer(other, transaction)
+	return j.err
+}
+
+// Close writes the transfer legs whose other legs were never written, against the transfers equity account.
+// It does not close the underlying writer.
+func (j *JournalWriter) Close() error {
+	if j.err != nil {
+		return j.err
+	}
+
+	legs := make([]*models.TransactionDTO, 0, len(j.pending))
+	for _, leg := range j.pending {
+		legs = append(legs, leg)
+	}
+	sort.Slice(legs, func(i, k int) bool {
+		if legs[i].Timestamp != legs[k].Timestamp {
+			return legs[i].Timestamp < legs[k].Timestamp
+		}
+		return legs[i].ID < legs[k].ID
+	})
+
+	for _, leg := range legs {
+		j.writeTransfer(leg)
+	}
+	return j.err
+}
+
+// writeTransaction writes a transaction that is not a part of a transfer.
+func (j *JournalWriter) writeTransaction(transaction *models.TransactionDTO) {
+	account, exists := j.accounts[transaction.AccountID]
+	if !exists {
+		j.err = fmt.Errorf("unknown account %s of transaction %s", transaction.AccountID, transaction.ID)
+		return
+	}
+
+	entry := &journalEntry{
+		timestamp: transaction.Timestamp,
+		notes:     transaction.Notes,
+		tags:      transaction.Tags,
+		metadata:  [][2]string{{"id", transaction.ID}},
+		postings: []*journalPosting{
+			{account: account.name, amount: transaction.Amount, currency: account.currency},
+		},
+	}
+
+	if len(transaction.Splits) == 0 {
+		entry.postings = append(entry.postings, &journalPosting{
+			account:  j.categoryAccount(transaction.Category, transaction.Amount),
+			amount:   -transaction.Amount,
+			currency: account.currency,
+		})
+	}
+	for _, split := range transaction.Splits {
+		entry.postings = append(entry.postings, &journalPosting{
+			account:  j.categoryAccount(split.Category, split.Amount),
+			amount:   -split.Amount,
+			currency: account.currency,
+			comment:  split.Notes,
+		})
+	}
+
+	j.writeEntry(entry)
+}
+
+// writeTransfer writes the legs of a transfer as one transaction. The single legs of transfers are balanced by the
+// transfers equity account.
+func (j *JournalWriter) writeTransfer(legs ...*models.TransactionDTO) {
+	entry := &journalEntry{
+		timestamp: legs[0].Timestamp,
+		notes:     legs[0].Notes,
+		metadata:  [][2]string{{"transfer_id", legs[0].TransferID}},
+	}
+
+	for _, leg := range legs {
+		account, exists := j.accounts[leg.AccountID]
+		if !exists {
+			j.err = fmt.Errorf("unknown account %s of transaction %s", leg.AccountID, leg.ID)
+			return
+		}
+		entry.postings = append(entry.postings, &journalPosting{
+			account: account.name, amount: leg.Amount, currency: account.currency,
+		})
+		entry.tags = mergeJournalTags(entry.tags, leg.Tags)
+	}
+
+	first := entry.postings[0]
+	if len(entry.postings) == 1 {
+		entry.postings = append(entry.postings, &journalPosting{
+			account: journalTransferAccount, amount: -first.amount, currency: first.currency,
+		})
+	}
+
+	second := entry.postings[1]
+	switch {
+	case second.currency != first.currency:
+		// The other leg is priced in the currency of the first leg, which balances the transaction.
+		second.totalPrice, second.priceCurrency = absMoney(first.amount), first.currency
+	case second.amount != -first.amount:
+		// The legs of a transfer in the same currency always have the same amount, but the journal has to balance
+		// even if they do not.
+		entry.postings = append(entry.postings, &journalPosting{
+			account: journalTransferAccount, amount: -first.amount - second.amount, currency: first.currency,
+		})
+	}
+
+	j.writeEntry(entry)
+}
+
+// writeEntry writes a transaction of the journal.
+func (j *JournalWriter) writeEntry(entry *journalEntry) {
+	date, notes := journalDate(entry.timestamp), journalText(entry.notes)
+
+	if j.format == JournalBeancount {
+		j.print("%s * %s", date, beancountString(notes))
+		for _, tag := range entry.tags {
+			j.print(" #%s", tag)
+		}
+		j.print("\n")
+		for _, meta := range entry.metadata {
+			j.print("  %s: %s\n", meta[0], beancountString(meta[1]))
+		}
+	} else {
+		j.print("%s *", date)
+		// A semicolon starts the comment of the transaction in hledger, so it cannot be a part of the payee.
+		if notes = strings.ReplaceAll(notes, ";", ","); notes != "" {
+			j.print(" %s", notes)
+		}
+		j.print("\n")
+		for _, meta := range entry.metadata {
+			j.print("    ; %s: %s\n", meta[0], meta[1])
+		}
+		if len(entry.tags) > 0 {
+			j.print("    ; %s\n", j.ledgerTags(entry.tags))
+		}
+	}
+
+	indent := "    "
+	if j.format == JournalBeancount {
+		indent = "  "
+	}
+
+	for _, posting := range entry.postings {
+		j.print("%s%s  %s %s", indent, posting.account, posting.amount, posting.currency)
+		if posting.priceCurrency != "" {
+			j.print(" @@ %s %s", posting.totalPrice, posting.priceCurrency)
+		}
+		if comment := journalText(posting.comment); comment != "" {
+			j.print("  ; %s", comment)
+		}
+		j.print("\n")
+	}
+	j.print("\n")
+}
+
+// ledgerTags provides the comment that tags a transaction in ledger-cli or hledger.
+func (j *JournalWriter) ledgerTags(tags []string) string {
+	if j.format == JournalHLedger {
+		return strings.Join(tags, ":, ") + ":"
+	}
+	return ":" + strings.Join(tags, ":") + ":"
+}
+
+// declare declares a journal account, unless it is declared already. The beancount accounts with a currency only
+// allow that currency.
+func (j *JournalWriter) declare(name string, currency string) {
+	if j.declared[name] {
+		return
+	}
+	j.declared[name] = true
+
+	if j.format != JournalBeancount {
+		j.print("account %s\n", name)
+		return
+	}
+
+	j.print("%s open %s", j.openDate, name)
+	if currency != "" {
+		j.print(" %s", currency)
+	}
+	j.print("\n")
+}
+
+// categoryAccount provides the journal account of a category. The categories that no longer exist are given accounts
+// as per the sign of the amount, and are declared when they are first used.
+func (j *JournalWriter) categoryAccount(categoryID string, amount models.Money) string {
+	if name, exists := j.categories[categoryID]; exists {
+		return name
+	}
+
+	root := "Expenses"
+	if amount > 0 {
+		root = "Income"
+	}
+	name := j.newName(root, categoryID)
+	j.categories[categoryID] = name
+	j.declare(name, "")
+	// The declaration is followed by a blank line, like the other declarations.
+	j.print("\n")
+	return name
+}
+
+// sortedCategoryAccounts provides the journal accounts of the categories in alphabetical order.
+func (j *JournalWriter) sortedCategoryAccounts() []string {
+	names := make([]string, 0, len(j.categories))
+	for _, name := range j.categories {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// newName provides a unique journal account name under the root for the provided ID.
+func (j *JournalWriter) newName(root string, id string) string {
+	base := root + ":" + journalAccountComponent(id)
+	name := base
+	for suffix := 2; j.names[name]; suffix++ {
+		name = fmt.Sprintf("%s-%d", base, suffix)
+	}
+	j.names[name] = true
+	return name
+}
+
+// print writes into the underlying writer. The error, if any, is kept in the err field.
+func (j *JournalWriter) print(format string, args ...interface{}) {
+	if j.err != nil {
+		return
+	}
+	if _, err := fmt.Fprintf(j.writer, format, args...); err != nil {
+		j.err = fmt.Errorf("failed to write journal: %w", err)
+	}
+}
+
+// journalAccountComponent converts an ID, like "eating_out", into an account name component, like "Eating-Out".
+// The components of all three syntaxes start with a capital letter or a digit, and have no spaces.
+func journalAccountComponent(id string) string {
+	words := strings.FieldsFunc(id, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	if len(words) == 0 {
+		return "Unknown"
+	}
+
+	for idx, word := range words {
+		runes := []rune(word)
+		runes[0] = unicode.ToUpper(runes[0])
+		words[idx] = string(runes)
+	}
+	return strings.Join(words, "-")
+}
+
+// journalDate provides the date of the timestamp, in UTC, as written by the journals.
+func journalDate(timestamp int64) string {
+	return time.Unix(timestamp, 0).UTC().Format(journalDateLayout)
+}
+
+// journalText collapses all the whitespace of a text, including the line breaks, into single spaces. In ledger-cli and
+// hledger, two spaces or a tab end the payee of a transaction, and a line break ends anything.
+func journalText(text string) string {
+	return strings.Join(strings.Fields(text), " ")
+}
+
+// beancountString quotes a text as a beancount string.
+func beancountString(text string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(text) + `"`
+}
+
+// mergeJournalTags provides the tags along with the added tags that they do not have yet.
+func mergeJournalTags(tags []string, added []string) []string {
+	for _, tag := range added {
+		exists := false
+		for _, existing := range tags {
+			exists = exists || existing == tag
+		}
+		if !exists {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+// absMoney provides the absolute value of the amount.
+func absMoney(amount models.Money) models.Money {
+	if amount < 0 {
+		return -amount
+	}
+	return amount
+}
@@ -0,0 +1,113 @@
+package exports
+
+import (
+	"bytes"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/shivanshkc/ledgerkeep/src/models"
+)
+
+// The sample dataset of the journal tests. The journals that it makes are kept as the fixtures in the testdata
+// directory, which can be checked with bean-check, hledger check and ledger-cli.
+var (
+	sampleAccounts = []*models.AccountDTO{
+		{ID: "bank", Name: "Bank", Currency: "INR"},
+		{ID: "credit_card", Name: "Credit Card", Currency: "INR"},
+		{ID: "wallet-usd", Name: "Wallet", Currency: "USD"},
+	}
+
+	sampleCategories = []*models.CategoryDTO{
+		{ID: "earnings", Name: "Earnings", Kind: models.CategoryKindCredit, BudgetGroup: models.BudgetGroupIncome},
+		{ID: "essentials", Name: "Essentials", Kind: models.CategoryKindDebit, BudgetGroup: models.BudgetGroupEssentials},
+		{ID: "eating_out", Name: "Eating Out", Kind: models.CategoryKindDebit, BudgetGroup: models.BudgetGroupLuxury},
+		{ID: "refunds", Name: "Refunds", Kind: models.CategoryKindBoth, BudgetGroup: models.BudgetGroupIncome},
+	}
+
+	sampleOpenTime = sampleTime(1, 0)
+
+	sampleBalances = map[string]models.Money{"bank": 2500000, "credit_card": -125050}
+
+	sampleTransactions = []*models.TransactionDTO{
+		{ID: "tx1", Amount: 10000000, Timestamp: sampleTime(1, 10), AccountID: "bank", Category: "earnings",
+			Notes: "Salary for\nMarch"},
+		{ID: "tx2", Amount: -1250000, Timestamp: sampleTime(2, 9), AccountID: "credit_card", Category: "split",
+			Notes: `Groceries at "Fresh & Co"`, Tags: models.Tags{"food"}, Splits: []*models.SplitDTO{
+				{Amount: -1000000, Category: "essentials", Notes: "Vegetables"},
+				{Amount: -250000, Category: "eating_out", Notes: "Snacks;  chips"},
+			}},
+		{ID: "tx3", Amount: -3000000, Timestamp: sampleTime(3, 12), AccountID: "bank", Category: "transfer",
+			Notes: "Card bill", TransferID: "tr1"},
+		{ID: "tx4", Amount: 3000000, Timestamp: sampleTime(3, 12), AccountID: "credit_card", Category: "transfer",
+			Notes: "Card bill", TransferID: "tr1"},
+		{ID: "tx5", Amount: -8300000, Timestamp: sampleTime(4, 15), AccountID: "bank", Category: "transfer",
+			Notes: "Travel money", TransferID: "tr2", Tags: models.Tags{"travel"}},
+		{ID: "tx6", Amount: 1000000, Timestamp: sampleTime(4, 15), AccountID: "wallet-usd", Category: "transfer",
+			Notes: "Travel money", TransferID: "tr2", Tags: models.Tags{"travel"}},
+		{ID: "tx7", Amount: -125000, Timestamp: sampleTime(5, 20), AccountID: "wallet-usd", Category: "eating_out",
+			Notes: "Dinner", Tags: models.Tags{"travel", "food"}},
+		{ID: "tx8", Amount: 50000, Timestamp: sampleTime(6, 8), AccountID: "credit_card", Category: "refunds"},
+		{ID: "tx9", Amount: -200000, Timestamp: sampleTime(7, 8), AccountID: "bank", Category: "gifts",
+			Notes: "Gift; category that no longer exists"},
+		{ID: "tx10", Amount: 500000, Timestamp: sampleTime(8, 8), AccountID: "bank", Category: "transfer",
+			Notes: "Leg of a transfer from an account that is not exported", TransferID: "tr3"},
+	}
+)
+
+func TestJournalWriter(t *testing.T) {
+	for format, fixture := range map[JournalFormat]string{
+		JournalLedger:    "sample.ledger",
+		JournalHLedger:   "sample.journal",
+		JournalBeancount: "sample.beancount",
+	} {
+		buffer := &bytes.Buffer{}
+		writer := NewJournalWriter(buffer, format, sampleAccounts, sampleCategories)
+		if err := writer.Open(sampleOpenTime, sampleBalances); err != nil {
+			t.Fatalf("unexpected error in Open: %+v", err)
+		}
+		for _, transaction := range sampleTransactions {
+			if err := writer.Write(transaction); err != nil {
+				t.Fatalf("unexpected error in Write: %+v", err)
+			}
+		}
+		if err := writer.Close(); err != nil {
+			t.Fatalf("unexpected error in Close: %+v", err)
+		}
+
+		expected, err := ioutil.ReadFile(filepath.Join("testdata", fixture))
+		if err != nil {
+			t.Fatalf("unexpected error in reading fixture: %+v", err)
+		}
+		if buffer.String() != string(expected) {
+			t.Fatalf("journal does not match %s, got:\n%s", fixture, buffer.String())
+		}
+	}
+}
+
+func TestJournalWriterUnknownAccount(t *testing.T) {
+	writer := NewJournalWriter(&bytes.Buffer{}, JournalBeancount, sampleAccounts, sampleCategories)
+	if err := writer.Open(sampleOpenTime, nil); err != nil {
+		t.Fatalf("unexpected error in Open: %+v", err)
+	}
+
+	err := writer.Write(&models.TransactionDTO{ID: "tx", Amount: -1, AccountID: "deleted", Category: "essentials"})
+	if err == nil {
+		t.Fatalf("expected error for unknown account")
+	}
+}
+
+func TestJournalAccountComponent(t *testing.T) {
+	for id, expected := range map[string]string{"bank": "Bank", "credit_card": "Credit-Card", "hdfc-2": "Hdfc-2",
+		"_x__y_": "X-Y", "---": "Unknown"} {
+		if component := journalAccountComponent(id); component != expected {
+			t.Fatalf("expected component of %s to be %s, but got %s", id, expected, component)
+		}
+	}
+}
+
+// sampleTime provides the timestamp of the hour of the day of March 2022.
+func sampleTime(day int, hour int) int64 {
+	return time.Date(2022, time.March, day, hour, 0, 0, 0, time.UTC).Unix()
+}
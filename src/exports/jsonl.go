@@ -0,0 +1,39 @@
+package exports
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// jsonlWriter writes the rows as JSON Lines, which is a JSON object per line.
+type jsonlWriter struct {
+	encoder *json.Encoder
+}
+
+// NewJSONLWriter provides a Writer that writes JSON Lines into the provided writer.
+func NewJSONLWriter(writer io.Writer) Writer {
+	encoder := json.NewEncoder(writer)
+	// The notes are written as they are, without escaping the HTML characters.
+	encoder.SetEscapeHTML(false)
+	return &jsonlWriter{encoder: encoder}
+}
+
+func (j *jsonlWriter) Write(row *Row) error {
+	// An empty list is clearer than a null for the rows without tags.
+	if row.Tags == nil {
+		rowCopy := *row
+		rowCopy.Tags = []string{}
+		row = &rowCopy
+	}
+
+	// The encoder ends every object with a newline.
+	if err := j.encoder.Encode(row); err != nil {
+		return fmt.Errorf("failed to write json line: %w", err)
+	}
+	return nil
+}
+
+func (j *jsonlWriter) Close() error {
+	return nil
+}
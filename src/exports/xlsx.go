@@ -0,0 +1,188 @@
+package exports
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// The fixed parts of the XLSX package. The worksheet is the only part that depends on the rows.
+const (
+	xlsxContentTypes = xml.Header + `<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+		`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+		`<Default Extension="xml" ContentType="application/xml"/>` +
+		`<Override PartName="/xl/workbook.xml" ` +
+		`ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+		`<Override PartName="/xl/worksheets/sheet1.xml" ` +
+		`ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>` +
+		`</Types>`
+
+	xlsxRootRels = xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" ` +
+		`Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" ` +
+		`Target="xl/workbook.xml"/>` +
+		`</Relationships>`
+
+	xlsxWorkbook = xml.Header + `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" ` +
+		`xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+		`<sheets><sheet name="Transactions" sheetId="1" r:id="rId1"/></sheets>` +
+		`</workbook>`
+
+	xlsxWorkbookRels = xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" ` +
+		`Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" ` +
+		`Target="worksheets/sheet1.xml"/>` +
+		`</Relationships>`
+
+	xlsxSheetStart = xml.Header + `<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` +
+		`<sheetData>`
+
+	xlsxSheetEnd = `</sheetData></worksheet>`
+)
+
+// xlsxWriter writes the rows as a single sheet of an XLSX workbook, with a header row.
+//
+// The worksheet is the last part of the package, so its rows are written into the zip as they come. The text cells are
+// inline strings, which spares the shared strings table that would need all the rows in advance.
+type xlsxWriter struct {
+	archive *zip.Writer
+	sheet   *bufio.Writer
+	// rowNum is the number of the last written row, starting at 1.
+	rowNum int
+	// err is the first error of the writer, which fails all the later calls.
+	err error
+}
+
+// NewXLSXWriter provides a Writer that writes an XLSX workbook into the provided writer.
+func NewXLSXWriter(writer io.Writer) Writer {
+	x := &xlsxWriter{archive: zip.NewWriter(writer)}
+	x.err = x.start()
+	return x
+}
+
+func (x *xlsxWriter) Write(row *Row) error {
+	if x.err != nil {
+		return x.err
+	}
+
+	x.writeRow([]xlsxCell{
+		{value: row.ID},
+		{value: row.date()},
+		{value: strconv.FormatInt(row.Timestamp, 10), numeric: true},
+		{value: row.AccountID},
+		{value: row.Currency},
+		{value: row.Category},
+		{value: row.Amount.String(), numeric: true},
+		{value: row.ClosingBal.String(), numeric: true},
+		{value: row.Notes},
+		{value: row.joinedTags()},
+		{value: row.TransferID},
+		{value: row.ExternalID},
+	})
+	return x.err
+}
+
+func (x *xlsxWriter) Close() error {
+	if x.err != nil {
+		return x.err
+	}
+
+	if _, err := x.sheet.WriteString(xlsxSheetEnd); err != nil {
+		return fmt.Errorf("failed to write xlsx sheet: %w", err)
+	}
+	if err := x.sheet.Flush(); err != nil {
+		return fmt.Errorf("failed to write xlsx sheet: %w", err)
+	}
+	if err := x.archive.Close(); err != nil {
+		return fmt.Errorf("failed to close xlsx archive: %w", err)
+	}
+	return nil
+}
+
+// start writes the fixed parts of the package, and the start of the worksheet along with its header row.
+func (x *xlsxWriter) start() error {
+	parts := []struct{ name, content string }{
+		{name: "[Content_Types].xml", content: xlsxContentTypes},
+		{name: "_rels/.rels", content: xlsxRootRels},
+		{name: "xl/workbook.xml", content: xlsxWorkbook},
+		{name: "xl/_rels/workbook.xml.rels", content: xlsxWorkbookRels},
+	}
+
+	for _, part := range parts {
+		partWriter, err := x.archive.Create(part.name)
+		if err != nil {
+			return fmt.Errorf("failed to create xlsx part %s: %w", part.name, err)
+		}
+		if _, err := io.WriteString(partWriter, part.content); err != nil {
+			return fmt.Errorf("failed to write xlsx part %s: %w", part.name, err)
+		}
+	}
+
+	sheetWriter, err := x.archive.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		return fmt.Errorf("failed to create xlsx sheet: %w", err)
+	}
+	x.sheet = bufio.NewWriter(sheetWriter)
+
+	if _, err := x.sheet.WriteString(xlsxSheetStart); err != nil {
+		return fmt.Errorf("failed to write xlsx sheet: %w", err)
+	}
+
+	headerCells := make([]xlsxCell, len(header))
+	for idx, name := range header {
+		headerCells[idx] = xlsxCell{value: name}
+	}
+	x.writeRow(headerCells)
+	return x.err
+}
+
+// xlsxCell is a cell of the worksheet.
+type xlsxCell struct {
+	value string
+	// numeric cells are written as numbers, and the others as inline strings.
+	numeric bool
+}
+
+// writeRow writes a row of cells into the worksheet. The error, if any, is kept in the err field.
+func (x *xlsxWriter) writeRow(cells []xlsxCell) {
+	x.rowNum++
+
+	var builder strings.Builder
+	builder.WriteString(`<row r="` + strconv.Itoa(x.rowNum) + `">`)
+
+	for idx, cell := range cells {
+		// The empty cells are left out, as the spreadsheets do.
+		if cell.value == "" {
+			continue
+		}
+
+		ref := xlsxColumnName(idx) + strconv.Itoa(x.rowNum)
+		if cell.numeric {
+			builder.WriteString(`<c r="` + ref + `"><v>` + cell.value + `</v></c>`)
+			continue
+		}
+
+		builder.WriteString(`<c r="` + ref + `" t="inlineStr"><is><t xml:space="preserve">`)
+		_ = xml.EscapeText(&builder, []byte(cell.value))
+		builder.WriteString(`</t></is></c>`)
+	}
+
+	builder.WriteString(`</row>`)
+
+	if _, err := x.sheet.WriteString(builder.String()); err != nil {
+		x.err = fmt.Errorf("failed to write xlsx row: %w", err)
+	}
+}
+
+// xlsxColumnName provides the letters of the column with the provided zero-based index, like "A" for 0 and "AA" for 26.
+func xlsxColumnName(idx int) string {
+	var name string
+	for idx++; idx > 0; idx = (idx - 1) / 26 {
+		name = string(rune('A'+(idx-1)%26)) + name
+	}
+	return name
+}
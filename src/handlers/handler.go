@@ -0,0 +1,51 @@
+package handlers
+
+import (
+	"github.com/shivanshkc/ledgerkeep/src/database"
+)
+
+// Handler holds the dependencies of all the HTTP handlers of the application.
+type Handler struct {
+	// accounts is the storage for accounts.
+	accounts database.AccountRepository
+	// transactions is the storage for transactions.
+	transactions database.TransactionRepository
+	// categories is the storage for categories.
+	categories database.CategoryRepository
+	// exchangeRates is the storage for exchange rates.
+	exchangeRates database.ExchangeRateRepository
+	// budgetPlans is the storage for budget plans.
+	budgetPlans database.BudgetPlanRepository
+	// recurring is the storage for recurring transaction templates.
+	recurring database.RecurringTemplateRepository
+	// imports is the storage for import profiles.
+	imports database.ImportProfileRepository
+	// rules is the storage for categorization rules.
+	rules database.RuleRepository
+	// backups is the storage for the backups of the whole ledger.
+	backups database.BackupRepository
+	// users is the storage for users.
+	users database.UserRepository
+	// members is the storage for the memberships of the shared ledgers.
+	members database.MemberRepository
+	// tokens is the storage for API tokens.
+	tokens database.TokenRepository
+}
+
+// NewHandler provides a new Handler that uses the provided repositories for storage.
+func NewHandler(repos *database.Repositories) *Handler {
+	return &Handler{
+		accounts:      repos.Accounts,
+		transactions:  repos.Transactions,
+		categories:    repos.Categories,
+		exchangeRates: repos.ExchangeRates,
+		budgetPlans:   repos.BudgetPlans,
+		recurring:     repos.Recurring,
+		imports:       repos.Imports,
+		rules:         repos.Rules,
+		backups:       repos.Backups,
+		users:         repos.Users,
+		members:       repos.Members,
+		tokens:        repos.Tokens,
+	}
+}
@@ -3,7 +3,7 @@ package handlers
 import (
 	"net/http"
 
-	"github.com/shivanshkc/ledgerkeep/src/database"
+	"github.com/shivanshkc/ledgerkeep/src/configs"
 	"github.com/shivanshkc/ledgerkeep/src/logger"
 	"github.com/shivanshkc/ledgerkeep/src/models"
 	"github.com/shivanshkc/ledgerkeep/src/utils/errutils"
@@ -11,7 +11,7 @@ import (
 )
 
 // CreateAccountHandler creates a new account.
-func CreateAccountHandler(writer http.ResponseWriter, request *http.Request) {
+func (h *Handler) CreateAccountHandler(writer http.ResponseWriter, request *http.Request) {
 	ctx := request.Context()
 	log := logger.Get()
 
@@ -37,8 +37,20 @@ func CreateAccountHandler(writer http.ResponseWriter, request *http.Request) {
 		return
 	}
 
+	// Validating account currency. Accounts are in the default currency if they do not specify one.
+	if requestBody.Currency == "" {
+		requestBody.Currency = configs.Get().Currency.Default
+	}
+	currency, err := parseCurrency(requestBody.Currency)
+	if err != nil {
+		err = errutils.BadRequest().AddErrors(err)
+		httputils.WriteErrAndLog(ctx, writer, err, log)
+		return
+	}
+	requestBody.Currency = currency
+
 	// Database call.
-	if err := database.InsertAccount(ctx, requestBody); err != nil {
+	if err := h.accounts.InsertAccount(ctx, requestBody); err != nil {
 		httputils.WriteErrAndLog(ctx, writer, err, log)
 		return
 	}
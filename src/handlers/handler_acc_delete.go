@@ -3,8 +3,8 @@ package handlers
 import (
 	"net/http"
 
-	"github.com/shivanshkc/ledgerkeep/src/database"
 	"github.com/shivanshkc/ledgerkeep/src/logger"
+	"github.com/shivanshkc/ledgerkeep/src/models"
 	"github.com/shivanshkc/ledgerkeep/src/utils/errutils"
 	"github.com/shivanshkc/ledgerkeep/src/utils/httputils"
 
@@ -12,7 +12,7 @@ import (
 )
 
 // DeleteAccountHandler deletes an account by its ID.
-func DeleteAccountHandler(writer http.ResponseWriter, request *http.Request) {
+func (h *Handler) DeleteAccountHandler(writer http.ResponseWriter, request *http.Request) {
 	ctx := request.Context()
 	log := logger.Get()
 
@@ -25,12 +25,23 @@ func DeleteAccountHandler(writer http.ResponseWriter, request *http.Request) {
 	}
 
 	// Checking if this account is in use.
-	isUsed, err := database.IsAccountUsed(ctx, accountID)
+	isUsed, err := h.accounts.IsAccountUsed(ctx, accountID)
 	if err != nil {
 		httputils.WriteErrAndLog(ctx, writer, err, log)
 		return
 	}
 
+	// The account may also be in use by a recurring template.
+	if !isUsed {
+		isUsed, err = h.isUsedByRecurringTemplate(ctx, func(template *models.RecurringTemplateDTO) bool {
+			return template.AccountID == accountID
+		})
+		if err != nil {
+			httputils.WriteErrAndLog(ctx, writer, err, log)
+			return
+		}
+	}
+
 	// If account is in use, we cannot allow its deletion.
 	if isUsed {
 		httputils.WriteErrAndLog(ctx, writer, errutils.AccountIsInUse(), log)
@@ -38,7 +49,7 @@ func DeleteAccountHandler(writer http.ResponseWriter, request *http.Request) {
 	}
 
 	// Database call.
-	if err := database.DeleteAccount(ctx, accountID); err != nil {
+	if err := h.accounts.DeleteAccount(ctx, accountID); err != nil {
 		httputils.WriteErrAndLog(ctx, writer, err, log)
 		return
 	}
@@ -4,7 +4,6 @@ import (
 	"fmt"
 	"net/http"
 
-	"github.com/shivanshkc/ledgerkeep/src/database"
 	"github.com/shivanshkc/ledgerkeep/src/logger"
 	"github.com/shivanshkc/ledgerkeep/src/models"
 	"github.com/shivanshkc/ledgerkeep/src/utils/httputils"
@@ -15,25 +14,25 @@ import (
 // accountsListItem is an item of the account list as written by the ListAccountsHandler.
 type accountsListItem struct {
 	models.AccountDTO
-	Balance float64 `json:"balance"`
+	Balance models.Money `json:"balance"`
 }
 
 // ListAccountsHandler lists all accounts along with their balances.
-func ListAccountsHandler(writer http.ResponseWriter, request *http.Request) {
+func (h *Handler) ListAccountsHandler(writer http.ResponseWriter, request *http.Request) {
 	ctx := request.Context()
 	log := logger.Get()
 
 	errs, errCtx := errgroup.WithContext(ctx)
 	// Creating channels because we intend to make 2 database calls in parallel.
-	accountsChan := make(chan []*models.AccountDTO, 1) // One call to fetch the accounts list.
-	balancesChan := make(chan map[string]float64, 1)   // Second call to fetch the account balances.
+	accountsChan := make(chan []*models.AccountDTO, 1)    // One call to fetch the accounts list.
+	balancesChan := make(chan map[string]models.Money, 1) // Second call to fetch the account balances.
 
 	// Call 1: Fetching account list.
 	errs.Go(func() error {
 		defer close(accountsChan)
-		accounts, err := database.ListAccounts(errCtx)
+		accounts, err := h.accounts.ListAccounts(errCtx)
 		if err != nil {
-			return fmt.Errorf("failure in databae.ListAccounts: %w", err)
+			return fmt.Errorf("failure in accounts.ListAccounts: %w", err)
 		}
 		accountsChan <- accounts
 		return nil
@@ -42,9 +41,9 @@ func ListAccountsHandler(writer http.ResponseWriter, request *http.Request) {
 	// Call 2: Fetching account balances.
 	errs.Go(func() error {
 		defer close(balancesChan)
-		balances, err := database.GetAccountBalances(errCtx)
+		balances, err := h.accounts.GetAccountBalances(errCtx)
 		if err != nil {
-			return fmt.Errorf("failure in database.GetAccountBalances: %w", err)
+			return fmt.Errorf("failure in accounts.GetAccountBalances: %w", err)
 		}
 		balancesChan <- balances
 		return nil
@@ -67,6 +66,7 @@ func ListAccountsHandler(writer http.ResponseWriter, request *http.Request) {
 			AccountDTO: *acc,
 			Balance:    balances[acc.ID],
 		}
+		accountsList[idx].Currency = getAccountCurrency(acc)
 	}
 
 	// Final HTTP response.
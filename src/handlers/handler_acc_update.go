@@ -3,7 +3,6 @@ package handlers
 import (
 	"net/http"
 
-	"github.com/shivanshkc/ledgerkeep/src/database"
 	"github.com/shivanshkc/ledgerkeep/src/logger"
 	"github.com/shivanshkc/ledgerkeep/src/models"
 	"github.com/shivanshkc/ledgerkeep/src/utils/errutils"
@@ -13,7 +12,7 @@ import (
 )
 
 // UpdateAccountHandler updates an account by its ID.
-func UpdateAccountHandler(writer http.ResponseWriter, request *http.Request) {
+func (h *Handler) UpdateAccountHandler(writer http.ResponseWriter, request *http.Request) {
 	ctx := request.Context()
 	log := logger.Get()
 
@@ -41,7 +40,7 @@ func UpdateAccountHandler(writer http.ResponseWriter, request *http.Request) {
 	}
 
 	// Database call.
-	if err := database.UpdateAccount(ctx, accountID, msi{"name": requestBody.Name}); err != nil {
+	if err := h.accounts.UpdateAccount(ctx, accountID, msi{"name": requestBody.Name}); err != nil {
 		httputils.WriteErrAndLog(ctx, writer, err, log)
 		return
 	}
@@ -0,0 +1,59 @@
+package handlers
+
+import (
+	"bufio"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/shivanshkc/ledgerkeep/src/backups"
+	"github.com/shivanshkc/ledgerkeep/src/database"
+	"github.com/shivanshkc/ledgerkeep/src/logger"
+	"github.com/shivanshkc/ledgerkeep/src/utils/httputils"
+)
+
+// CreateBackupHandler downloads a backup of the whole ledger as a portable archive, which can be restored into any
+// storage backend by the RestoreBackupHandler. See the backups package for the format of the archive.
+//
+// Like the exports, the transactions are streamed from the database as they are written, so the backups of any size
+// are never loaded into memory.
+func (h *Handler) CreateBackupHandler(writer http.ResponseWriter, request *http.Request) {
+	ctx := request.Context()
+	log := logger.Get()
+
+	// The time of the backup is taken before any data is read.
+	createdAt := time.Now()
+
+	// Database call. Everything but the transactions is small enough to be read at once.
+	data, err := h.getLedgerData(ctx)
+	if err != nil {
+		httputils.WriteErrAndLog(ctx, writer, err, log)
+		return
+	}
+
+	cursor, err := h.transactions.StreamTransactions(ctx, &database.ListTransactionsParams{
+		SortField: "timestamp",
+		SortOrder: 1,
+	})
+	if err != nil {
+		httputils.WriteErrAndLog(ctx, writer, err, log)
+		return
+	}
+	defer func() { _ = cursor.Close(ctx) }()
+
+	fileName := fmt.Sprintf("ledgerkeep-backup-%s.zip", createdAt.UTC().Format("2006-01-02"))
+	writer.Header().Set("content-type", "application/zip")
+	writer.Header().Set("content-disposition", fmt.Sprintf(`attachment; filename="%s"`, fileName))
+	// Setting the status code. No more headers can be set after this, so the later errors can only be logged.
+	writer.WriteHeader(http.StatusOK)
+
+	buffered := bufio.NewWriter(writer)
+	if err := h.writeBackup(ctx, backups.NewWriter(buffered, createdAt), data, cursor); err != nil {
+		log.Error(ctx, &logger.Entry{Payload: fmt.Errorf("failed to write backup: %w", err)})
+		return
+	}
+
+	if err := buffered.Flush(); err != nil {
+		log.Error(ctx, &logger.Entry{Payload: fmt.Errorf("failed to write backup: %w", err)})
+	}
+}
@@ -0,0 +1,104 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/shivanshkc/ledgerkeep/src/backups"
+	"github.com/shivanshkc/ledgerkeep/src/logger"
+	"github.com/shivanshkc/ledgerkeep/src/utils/errutils"
+	"github.com/shivanshkc/ledgerkeep/src/utils/httputils"
+)
+
+// RestoreBackupHandler restores a backup archive, uploaded as the "file" field of a multipart form, which replaces all
+// the data of the ledger atomically. The archive is validated in full before anything is replaced, and all the IDs of
+// the backup are kept.
+//
+// A ledger that has accounts already is only replaced if the "overwrite" field is true, so the data is never lost by
+// accident.
+func (h *Handler) RestoreBackupHandler(writer http.ResponseWriter, request *http.Request) {
+	ctx := request.Context()
+	log := logger.Get()
+
+	// Limiting the upload size.
+	request.Body = http.MaxBytesReader(writer, request.Body, maxBackupFileSize)
+
+	file, header, err := request.FormFile("file")
+	if err != nil {
+		err = errutils.BadRequest().AddErrors(errMissingBackupFile, err)
+		httputils.WriteErrAndLog(ctx, writer, err, log)
+		return
+	}
+	defer func() { _ = file.Close() }()
+
+	overwrite, err := readOverwrite(request.FormValue("overwrite"))
+	if err != nil {
+		err = errutils.BadRequest().AddErrors(err)
+		httputils.WriteErrAndLog(ctx, writer, err, log)
+		return
+	}
+
+	data, _, err := backups.Read(file, header.Size)
+	if err != nil {
+		err = errutils.BadRequest().AddErrors(err)
+		httputils.WriteErrAndLog(ctx, writer, err, log)
+		return
+	}
+
+	if errs := checkLedgerData(data); len(errs) > 0 {
+		err = errutils.BadRequest().AddErrors(errs...)
+		httputils.WriteErrAndLog(ctx, writer, err, log)
+		return
+	}
+
+	// Database call. Without any accounts, a ledger has no transactions either.
+	accounts, err := h.accounts.ListAccounts(ctx)
+	if err != nil {
+		httputils.WriteErrAndLog(ctx, writer, err, log)
+		return
+	}
+	if len(accounts) > 0 && !overwrite {
+		httputils.WriteErrAndLog(ctx, writer, errutils.LedgerNotEmpty(), log)
+		return
+	}
+
+	// Database call.
+	if err := h.backups.ReplaceLedger(ctx, data); err != nil {
+		httputils.WriteErrAndLog(ctx, writer, err, log)
+		return
+	}
+
+	// Final HTTP response.
+	response := &httputils.ResponseDTO{
+		Status: http.StatusOK,
+		Body: &httputils.ResponseBodyDTO{
+			StatusCode: http.StatusOK,
+			CustomCode: "LEDGER_RESTORED",
+			Data: map[string]interface{}{
+				backups.SectionAccounts:           len(data.Accounts),
+				backups.SectionCategories:         len(data.Categories),
+				backups.SectionExchangeRates:      len(data.ExchangeRates),
+				backups.SectionBudgetPlans:        len(data.BudgetPlans),
+				backups.SectionRecurringTemplates: len(data.RecurringTemplates),
+				backups.SectionImportProfiles:     len(data.ImportProfiles),
+				backups.SectionRules:              len(data.Rules),
+				backups.SectionTransactions:       len(data.Transactions),
+			},
+		},
+	}
+
+	httputils.WriteAndLog(ctx, writer, response, log)
+}
+
+// readOverwrite reads the "overwrite" flag of a restore request, which is false if it is empty.
+func readOverwrite(value string) (bool, error) {
+	if value == "" {
+		return false, nil
+	}
+
+	overwrite, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, errInvalidOverwrite
+	}
+	return overwrite, nil
+}
@@ -9,7 +9,7 @@ import (
 )
 
 // BasicHandler serves the basic information about the application.
-func BasicHandler(writer http.ResponseWriter, request *http.Request) {
+func (h *Handler) BasicHandler(writer http.ResponseWriter, request *http.Request) {
 	conf := configs.Get()
 
 	responseBody := &httputils.ResponseBodyDTO{
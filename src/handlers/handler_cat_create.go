@@ -0,0 +1,48 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/shivanshkc/ledgerkeep/src/logger"
+	"github.com/shivanshkc/ledgerkeep/src/models"
+	"github.com/shivanshkc/ledgerkeep/src/utils/errutils"
+	"github.com/shivanshkc/ledgerkeep/src/utils/httputils"
+)
+
+// CreateCategoryHandler creates a new category.
+func (h *Handler) CreateCategoryHandler(writer http.ResponseWriter, request *http.Request) {
+	ctx := request.Context()
+	log := logger.Get()
+
+	// Decoding the request.
+	var requestBody *models.CategoryDTO
+	if err := httputils.UnmarshalBody(request, &requestBody); err != nil {
+		err = errutils.BadRequest().AddErrors(err)
+		httputils.WriteErrAndLog(ctx, writer, err, log)
+		return
+	}
+
+	// Validating user input.
+	if err := checkNewCategory(requestBody); err != nil {
+		err = errutils.BadRequest().AddErrors(err)
+		httputils.WriteErrAndLog(ctx, writer, err, log)
+		return
+	}
+
+	// Database call.
+	if err := h.categories.InsertCategory(ctx, requestBody); err != nil {
+		httputils.WriteErrAndLog(ctx, writer, err, log)
+		return
+	}
+
+	// Final HTTP response.
+	response := &httputils.ResponseDTO{
+		Status: http.StatusCreated,
+		Body: &httputils.ResponseBodyDTO{
+			StatusCode: http.StatusCreated,
+			CustomCode: "CATEGORY_CREATED",
+		},
+	}
+
+	httputils.WriteAndLog(ctx, writer, response, log)
+}
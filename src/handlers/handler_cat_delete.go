@@ -0,0 +1,67 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/shivanshkc/ledgerkeep/src/logger"
+	"github.com/shivanshkc/ledgerkeep/src/models"
+	"github.com/shivanshkc/ledgerkeep/src/utils/errutils"
+	"github.com/shivanshkc/ledgerkeep/src/utils/httputils"
+
+	"github.com/gorilla/mux"
+)
+
+// DeleteCategoryHandler deletes a category by its ID.
+func (h *Handler) DeleteCategoryHandler(writer http.ResponseWriter, request *http.Request) {
+	ctx := request.Context()
+	log := logger.Get()
+
+	categoryID := mux.Vars(request)["category_id"]
+	// Validating category ID.
+	if !categoryIDRegexp.MatchString(categoryID) {
+		err := errutils.BadRequest().AddErrors(errInvalidCategoryID)
+		httputils.WriteErrAndLog(ctx, writer, err, log)
+		return
+	}
+
+	// Checking if this category is in use.
+	isUsed, err := h.categories.IsCategoryUsed(ctx, categoryID)
+	if err != nil {
+		httputils.WriteErrAndLog(ctx, writer, err, log)
+		return
+	}
+
+	// The category may also be in use by a recurring template.
+	if !isUsed {
+		isUsed, err = h.isUsedByRecurringTemplate(ctx, func(template *models.RecurringTemplateDTO) bool {
+			return template.Category == categoryID
+		})
+		if err != nil {
+			httputils.WriteErrAndLog(ctx, writer, err, log)
+			return
+		}
+	}
+
+	// If category is in use, we cannot allow its deletion.
+	if isUsed {
+		httputils.WriteErrAndLog(ctx, writer, errutils.CategoryIsInUse(), log)
+		return
+	}
+
+	// Database call.
+	if err := h.categories.DeleteCategory(ctx, categoryID); err != nil {
+		httputils.WriteErrAndLog(ctx, writer, err, log)
+		return
+	}
+
+	// Final HTTP response.
+	response := &httputils.ResponseDTO{
+		Status: http.StatusOK,
+		Body: &httputils.ResponseBodyDTO{
+			StatusCode: http.StatusOK,
+			CustomCode: "CATEGORY_DELETED",
+		},
+	}
+
+	httputils.WriteAndLog(ctx, writer, response, log)
+}
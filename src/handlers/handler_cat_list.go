@@ -0,0 +1,33 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/shivanshkc/ledgerkeep/src/logger"
+	"github.com/shivanshkc/ledgerkeep/src/utils/httputils"
+)
+
+// ListCategoriesHandler lists all categories.
+func (h *Handler) ListCategoriesHandler(writer http.ResponseWriter, request *http.Request) {
+	ctx := request.Context()
+	log := logger.Get()
+
+	// Database call.
+	categories, err := h.categories.ListCategories(ctx)
+	if err != nil {
+		httputils.WriteErrAndLog(ctx, writer, err, log)
+		return
+	}
+
+	// Final HTTP response.
+	response := &httputils.ResponseDTO{
+		Status: http.StatusOK,
+		Body: &httputils.ResponseBodyDTO{
+			StatusCode: http.StatusOK,
+			CustomCode: "CATEGORIES_LISTED",
+			Data:       categories,
+		},
+	}
+
+	httputils.WriteAndLog(ctx, writer, response, log)
+}
@@ -0,0 +1,127 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/shivanshkc/ledgerkeep/src/logger"
+	"github.com/shivanshkc/ledgerkeep/src/models"
+	"github.com/shivanshkc/ledgerkeep/src/utils/errutils"
+	"github.com/shivanshkc/ledgerkeep/src/utils/httputils"
+
+	"github.com/gorilla/mux"
+)
+
+// updateCategoryBody is the schema of the body of the UpdateCategory API.
+type updateCategoryBody struct {
+	Name        *string `json:"name,omitempty"`
+	Kind        *string `json:"kind,omitempty"`
+	BudgetGroup *string `json:"budget_group,omitempty"`
+}
+
+// UpdateCategoryHandler updates a category by its ID.
+func (h *Handler) UpdateCategoryHandler(writer http.ResponseWriter, request *http.Request) {
+	ctx := request.Context()
+	log := logger.Get()
+
+	categoryID := mux.Vars(request)["category_id"]
+	// Validating category ID.
+	if !categoryIDRegexp.MatchString(categoryID) {
+		err := errutils.BadRequest().AddErrors(errInvalidCategoryID)
+		httputils.WriteErrAndLog(ctx, writer, err, log)
+		return
+	}
+
+	// Decoding the request.
+	var requestBody *updateCategoryBody
+	if err := httputils.UnmarshalBody(request, &requestBody); err != nil {
+		err = errutils.BadRequest().AddErrors(err)
+		httputils.WriteErrAndLog(ctx, writer, err, log)
+		return
+	}
+
+	// Validating user input and getting the updates map.
+	updates, err := prepareUpdateCategoryQuery(requestBody)
+	if err != nil {
+		err = errutils.BadRequest().AddErrors(err)
+		httputils.WriteErrAndLog(ctx, writer, err, log)
+		return
+	}
+
+	// If no updates were given, we stop execution.
+	if len(updates) == 0 {
+		err := errutils.BadRequest().AddErrors(errEmptyUpdate)
+		httputils.WriteErrAndLog(ctx, writer, err, log)
+		return
+	}
+
+	// A new kind should not invalidate the existing transactions of the category.
+	if kind, exists := updates["kind"]; exists && kind != models.CategoryKindBoth {
+		isUsed, err := h.categories.IsCategoryUsed(ctx, categoryID)
+		if err != nil {
+			httputils.WriteErrAndLog(ctx, writer, err, log)
+			return
+		}
+		// The future transactions of the recurring templates should stay valid too.
+		if !isUsed {
+			isUsed, err = h.isUsedByRecurringTemplate(ctx, func(template *models.RecurringTemplateDTO) bool {
+				return template.Category == categoryID
+			})
+			if err != nil {
+				httputils.WriteErrAndLog(ctx, writer, err, log)
+				return
+			}
+		}
+		if isUsed {
+			err := errutils.BadRequest().AddErrors(errCategoryKindInUse)
+			httputils.WriteErrAndLog(ctx, writer, err, log)
+			return
+		}
+	}
+
+	// Database call.
+	if err := h.categories.UpdateCategory(ctx, categoryID, updates); err != nil {
+		httputils.WriteErrAndLog(ctx, writer, err, log)
+		return
+	}
+
+	// Final HTTP response.
+	response := &httputils.ResponseDTO{
+		Status: http.StatusOK,
+		Body: &httputils.ResponseBodyDTO{
+			StatusCode: http.StatusOK,
+			CustomCode: "CATEGORY_UPDATED",
+		},
+	}
+
+	httputils.WriteAndLog(ctx, writer, response, log)
+}
+
+// prepareUpdateCategoryQuery validates all params of the updateCategoryBody and creates a map of updates.
+// Any nil parameters are ignored in the process.
+func prepareUpdateCategoryQuery(body *updateCategoryBody) (msi, error) {
+	updates := msi{}
+
+	if body.Name != nil {
+		if !categoryNameRegexp.MatchString(*body.Name) {
+			return nil, errInvalidCategoryName
+		}
+		updates["name"] = *body.Name
+	}
+
+	if body.Kind != nil {
+		if !stringPresentCaseInsensitive(*body.Kind, allowedCategoryKinds) {
+			return nil, errInvalidCategoryKind
+		}
+		updates["kind"] = strings.ToLower(*body.Kind)
+	}
+
+	if body.BudgetGroup != nil {
+		if !stringPresentCaseInsensitive(*body.BudgetGroup, allowedBudgetGroups) {
+			return nil, errInvalidBudgetGroup
+		}
+		updates["budget_group"] = strings.ToLower(*body.BudgetGroup)
+	}
+
+	return updates, nil
+}
@@ -0,0 +1,104 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/shivanshkc/ledgerkeep/src/journals"
+	"github.com/shivanshkc/ledgerkeep/src/logger"
+	"github.com/shivanshkc/ledgerkeep/src/models"
+	"github.com/shivanshkc/ledgerkeep/src/utils/errutils"
+	"github.com/shivanshkc/ledgerkeep/src/utils/httputils"
+
+	"github.com/gorilla/mux"
+)
+
+// ImportJournalHandler imports the accounts and the transactions of a plain-text accounting journal, uploaded as the
+// "file" field of a multipart form. The syntax of the journal, which is one of allowedJournalFormats, is the last
+// element of the path.
+//
+// The asset and liability accounts of the journal become the accounts of the ledger, which are created if they do not
+// exist. The postings to all the other accounts are mapped onto categories as per the "mapping" field, which is a JSON
+// object of the journal accounts, or their parents, to the categories. The directives that could not be translated,
+// like the prices and the balance assertions, are reported along with the transactions.
+//
+// Like ImportStatementHandler, it is a dry run by default, nothing is saved if any of the transactions is invalid,
+// and the transactions that look like duplicates are refused unless the "allow_duplicates" field is true.
+func (h *Handler) ImportJournalHandler(writer http.ResponseWriter, request *http.Request) {
+	ctx := request.Context()
+	log := logger.Get()
+
+	format := strings.ToLower(mux.Vars(request)["format"])
+	if !stringPresentCaseInsensitive(format, allowedJournalFormats) {
+		err := errutils.BadRequest().AddErrors(errInvalidJournalFormat)
+		httputils.WriteErrAndLog(ctx, writer, err, log)
+		return
+	}
+
+	// Limiting the upload size.
+	request.Body = http.MaxBytesReader(writer, request.Body, maxStatementFileSize)
+
+	file, _, err := request.FormFile("file")
+	if err != nil {
+		err = errutils.BadRequest().AddErrors(errMissingJournalFile, err)
+		httputils.WriteErrAndLog(ctx, writer, err, log)
+		return
+	}
+	defer func() { _ = file.Close() }()
+
+	dryRun, err := readDryRun(request)
+	if err != nil {
+		err = errutils.BadRequest().AddErrors(err)
+		httputils.WriteErrAndLog(ctx, writer, err, log)
+		return
+	}
+
+	allowDuplicates, err := readAllowDuplicates(request.FormValue("allow_duplicates"))
+	if err != nil {
+		err = errutils.BadRequest().AddErrors(err)
+		httputils.WriteErrAndLog(ctx, writer, err, log)
+		return
+	}
+
+	translator, err := h.readJournalTranslator(ctx, request)
+	if err != nil {
+		httputils.WriteErrAndLog(ctx, writer, err, log)
+		return
+	}
+
+	// Ledger-cli and hledger journals share their syntax, as far as it is read.
+	var journal *journals.Journal
+	var parseErrs []error
+	if format == journalFormatBeancount {
+		journal, parseErrs = journals.ParseBeancount(file)
+	} else {
+		journal, parseErrs = journals.ParseLedger(file)
+	}
+	if journal == nil {
+		err = errutils.BadRequest().AddErrors(parseErrs...)
+		httputils.WriteErrAndLog(ctx, writer, err, log)
+		return
+	}
+
+	transactions, rowErrs := translator.translate(journal)
+	imported := &statementImport{
+		transactions:    transactions,
+		rowErrs:         append(parseErrs, rowErrs...),
+		newAccounts:     append([]*models.AccountDTO{}, translator.newAccounts...),
+		untranslated:    append([]string{}, journal.Untranslated...),
+		allowDuplicates: allowDuplicates,
+	}
+
+	if imported.duplicateIDs, err = h.findDuplicates(ctx, imported.transactions); err != nil {
+		httputils.WriteErrAndLog(ctx, writer, err, log)
+		return
+	}
+
+	response, err := h.completeImport(ctx, imported, dryRun)
+	if err != nil {
+		httputils.WriteErrAndLog(ctx, writer, err, log)
+		return
+	}
+
+	httputils.WriteAndLog(ctx, writer, response, log)
+}
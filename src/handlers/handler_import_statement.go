@@ -0,0 +1,197 @@
+package handlers
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/shivanshkc/ledgerkeep/src/logger"
+	"github.com/shivanshkc/ledgerkeep/src/statements"
+	"github.com/shivanshkc/ledgerkeep/src/utils/errutils"
+	"github.com/shivanshkc/ledgerkeep/src/utils/httputils"
+
+	"github.com/gorilla/mux"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ImportStatementHandler imports the transactions of a bank statement file, uploaded as the "file" field of a
+// multipart form. The format of the file, which is one of allowedImportFormats, is the last element of the path.
+//
+// CSV files are read as per the import profile in the "profile_id" field. The other formats are imported into the
+// account in the "account_id" field, and their transactions get the default categories in the "credit_category" and
+// "debit_category" fields, unless they have a category of their own that exists. QIF files may specify the layout
+// of their dates in the "date_format" field. The balances of the camt.053 and MT940 statements are checked against the
+// ledger, and the mismatches are reported along with the transactions.
+//
+// The categorization rules are applied to the transactions of all formats. The category of the rules takes the place
+// of the default categories, but not of the categories that the transactions have of their own.
+//
+// By default, it is a dry run, which only previews the transactions and the row errors. The transactions are saved only
+// if the "dry_run" field is false, and nothing is saved if any of the rows is invalid.
+// The transactions that were already imported, as told by their external IDs, are skipped. The transactions that look
+// like duplicates of the existing ones are previewed, and refused with a conflict unless the "allow_duplicates" field
+// is true.
+func (h *Handler) ImportStatementHandler(writer http.ResponseWriter, request *http.Request) {
+	ctx := request.Context()
+	log := logger.Get()
+
+	format := strings.ToLower(mux.Vars(request)["format"])
+	if !stringPresentCaseInsensitive(format, allowedImportFormats) {
+		err := errutils.BadRequest().AddErrors(errInvalidImportFormat)
+		httputils.WriteErrAndLog(ctx, writer, err, log)
+		return
+	}
+
+	// Limiting the upload size.
+	request.Body = http.MaxBytesReader(writer, request.Body, maxStatementFileSize)
+
+	file, _, err := request.FormFile("file")
+	if err != nil {
+		err = errutils.BadRequest().AddErrors(errMissingStatementFile, err)
+		httputils.WriteErrAndLog(ctx, writer, err, log)
+		return
+	}
+	defer func() { _ = file.Close() }()
+
+	dryRun, err := readDryRun(request)
+	if err != nil {
+		err = errutils.BadRequest().AddErrors(err)
+		httputils.WriteErrAndLog(ctx, writer, err, log)
+		return
+	}
+
+	allowDuplicates, err := readAllowDuplicates(request.FormValue("allow_duplicates"))
+	if err != nil {
+		err = errutils.BadRequest().AddErrors(err)
+		httputils.WriteErrAndLog(ctx, writer, err, log)
+		return
+	}
+
+	// Reading and validating all rows.
+	var imported *statementImport
+	if format == importFormatCSV {
+		imported, err = h.readCSVImport(ctx, request, file)
+	} else {
+		imported, err = h.readStatementImport(ctx, request, format, file)
+	}
+	if err != nil {
+		httputils.WriteErrAndLog(ctx, writer, err, log)
+		return
+	}
+
+	newTransactions, skipped, err := h.skipImportedTransactions(ctx, imported.transactions)
+	if err != nil {
+		httputils.WriteErrAndLog(ctx, writer, err, log)
+		return
+	}
+
+	if imported.balances != nil {
+		imported.balanceCheck, err = h.checkStatementBalances(ctx, imported.balances, imported.transactions,
+			newTransactions)
+		if err != nil {
+			httputils.WriteErrAndLog(ctx, writer, err, log)
+			return
+		}
+	}
+	imported.transactions, imported.skipped = newTransactions, skipped
+
+	imported.allowDuplicates = allowDuplicates
+	if imported.duplicateIDs, err = h.findDuplicates(ctx, imported.transactions); err != nil {
+		httputils.WriteErrAndLog(ctx, writer, err, log)
+		return
+	}
+
+	response, err := h.completeImport(ctx, imported, dryRun)
+	if err != nil {
+		httputils.WriteErrAndLog(ctx, writer, err, log)
+		return
+	}
+
+	httputils.WriteAndLog(ctx, writer, response, log)
+}
+
+// readCSVImport reads the transactions of a bank statement CSV file as per the import profile of the request.
+func (h *Handler) readCSVImport(ctx context.Context, request *http.Request, file io.Reader) (*statementImport, error) {
+	profileID := request.FormValue("profile_id")
+	// Validating import profile ID. Import profile IDs are always ObjectID hex strings.
+	if !primitive.IsValidObjectID(profileID) {
+		return nil, errutils.BadRequest().AddErrors(errInvalidImportProfileID)
+	}
+
+	profile, err := h.imports.GetImportProfile(ctx, profileID)
+	if err != nil {
+		return nil, err
+	}
+
+	// The account provides the currency of the transactions.
+	account, err := h.accounts.GetAccount(ctx, profile.AccountID)
+	if err != nil {
+		return nil, err
+	}
+
+	// The categories are required to validate the rows.
+	categories, err := h.getCategorySet(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	engine, err := h.getRuleEngine(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	transactions, rowErrs := readStatementCSV(file, profile, categories, getAccountCurrency(account), engine)
+	return &statementImport{transactions: transactions, rowErrs: rowErrs}, nil
+}
+
+// readStatementImport reads the transactions of a bank statement file, of any format other than CSV, into the account
+// of the request.
+func (h *Handler) readStatementImport(ctx context.Context, request *http.Request, format string,
+	file io.Reader) (*statementImport, error) {
+	target, err := h.readStatementTarget(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []*statements.Entry
+	var entryErrs []error
+	var balances *statements.Balances
+
+	switch format {
+	case importFormatQIF:
+		dateFormat := request.FormValue("date_format")
+		if dateFormat != "" && !isValidDateFormat(dateFormat) {
+			return nil, errutils.BadRequest().AddErrors(errInvalidDateFormat)
+		}
+		entries, entryErrs = statements.ParseQIF(file, dateFormat)
+	case importFormatCamt053:
+		entries, balances, entryErrs = statements.ParseCamt053(file)
+	case importFormatMT940:
+		entries, balances, entryErrs = statements.ParseMT940(file)
+	default:
+		entries, entryErrs = statements.ParseOFX(file)
+	}
+
+	transactions, rowErrs := target.newTransactions(entries)
+	return &statementImport{
+		transactions: transactions,
+		rowErrs:      append(entryErrs, rowErrs...),
+		balances:     balances,
+	}, nil
+}
+
+// readDryRun reads the "dry_run" form field of an import request. Imports are dry runs unless it is false.
+func readDryRun(request *http.Request) (bool, error) {
+	value := request.FormValue("dry_run")
+	if value == "" {
+		return true, nil
+	}
+
+	dryRun, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, errInvalidDryRun
+	}
+	return dryRun, nil
+}
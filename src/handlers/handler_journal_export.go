@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"bufio"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/shivanshkc/ledgerkeep/src/database"
+	"github.com/shivanshkc/ledgerkeep/src/exports"
+	"github.com/shivanshkc/ledgerkeep/src/logger"
+	"github.com/shivanshkc/ledgerkeep/src/utils/errutils"
+	"github.com/shivanshkc/ledgerkeep/src/utils/httputils"
+)
+
+// ExportJournalHandler exports the accounts and the transactions as a plain-text accounting journal, for ledger-cli,
+// hledger or beancount. The syntax of the journal, which is one of allowedJournalFormats, is the "format" query
+// parameter, and it is ledger-cli by default.
+//
+// The export may be limited to the "start_time" and "end_time" query parameters, and to the account of the
+// "account_id" query parameter. The balances of the accounts before the start time are written as their opening
+// balances. The legs of the transfers with the accounts that are not exported are balanced by an equity account.
+func (h *Handler) ExportJournalHandler(writer http.ResponseWriter, request *http.Request) {
+	ctx := request.Context()
+	log := logger.Get()
+
+	query := request.URL.Query()
+	format := strings.ToLower(query.Get("format"))
+	if format == "" {
+		format = journalFormatLedger
+	}
+	if !stringPresentCaseInsensitive(format, allowedJournalFormats) {
+		err := errutils.BadRequest().AddErrors(errInvalidJournalFormat)
+		httputils.WriteErrAndLog(ctx, writer, err, log)
+		return
+	}
+
+	qValues := readListTransactionsQuery(query)
+	timestampFilter, err := getStartEndTimestampFilter(qValues.StartTime, qValues.EndTime)
+	if err != nil {
+		err = errutils.BadRequest().AddErrors(err)
+		httputils.WriteErrAndLog(ctx, writer, err, log)
+		return
+	}
+
+	accountID := query.Get("account_id")
+	if accountID != "" && !accountIDRegexp.MatchString(accountID) {
+		err := errutils.BadRequest().AddErrors(errInvalidAccountID)
+		httputils.WriteErrAndLog(ctx, writer, err, log)
+		return
+	}
+
+	journal, err := h.getJournalData(ctx, accountID, timestampFilter)
+	if err != nil {
+		httputils.WriteErrAndLog(ctx, writer, err, log)
+		return
+	}
+
+	cursor, err := h.transactions.StreamTransactions(ctx, &database.ListTransactionsParams{
+		Filter:    journal.filter,
+		SortField: "timestamp",
+		SortOrder: 1,
+	})
+	if err != nil {
+		httputils.WriteErrAndLog(ctx, writer, err, log)
+		return
+	}
+	defer func() { _ = cursor.Close(ctx) }()
+
+	writer.Header().Set("content-type", "text/plain; charset=utf-8")
+	writer.Header().Set("content-disposition", fmt.Sprintf(`attachment; filename="%s"`, journalFileNames[format]))
+	// Setting the status code. No more headers can be set after this, so the later errors can only be logged.
+	writer.WriteHeader(http.StatusOK)
+
+	buffered := bufio.NewWriter(writer)
+	journalWriter := exports.NewJournalWriter(buffered, journalFormats[format], journal.accounts, journal.categories)
+	if err := h.writeJournal(ctx, journalWriter, journal, cursor); err != nil {
+		log.Error(ctx, &logger.Entry{Payload: fmt.Errorf("failed to export journal: %w", err)})
+		return
+	}
+
+	if err := buffered.Flush(); err != nil {
+		log.Error(ctx, &logger.Entry{Payload: fmt.Errorf("failed to write journal: %w", err)})
+	}
+}
@@ -0,0 +1,43 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/shivanshkc/ledgerkeep/src/logger"
+	"github.com/shivanshkc/ledgerkeep/src/models"
+	"github.com/shivanshkc/ledgerkeep/src/utils/httputils"
+)
+
+// ListLedgersHandler lists all the ledgers that the user can access, along with its roles in them.
+// The first one is always the user's own ledger, which can be used without the ledger ID header.
+func (h *Handler) ListLedgersHandler(writer http.ResponseWriter, request *http.Request) {
+	ctx := request.Context()
+	log := logger.Get()
+
+	// Database calls.
+	user, err := h.getCurrentUser(ctx)
+	if err != nil {
+		httputils.WriteErrAndLog(ctx, writer, err, log)
+		return
+	}
+	memberships, err := h.members.ListUserMemberships(ctx, user.ID)
+	if err != nil {
+		httputils.WriteErrAndLog(ctx, writer, err, log)
+		return
+	}
+
+	ownLedger := &models.MemberDTO{LedgerID: user.TenantID, UserID: user.ID, Role: models.RoleOwner,
+		CreatedAt: user.CreatedAt}
+
+	// Final HTTP response.
+	response := &httputils.ResponseDTO{
+		Status: http.StatusOK,
+		Body: &httputils.ResponseBodyDTO{
+			StatusCode: http.StatusOK,
+			CustomCode: "LEDGERS_LISTED",
+			Data:       append([]*models.MemberDTO{ownLedger}, memberships...),
+		},
+	}
+
+	httputils.WriteAndLog(ctx, writer, response, log)
+}
@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/shivanshkc/ledgerkeep/src/logger"
+	"github.com/shivanshkc/ledgerkeep/src/models"
+	"github.com/shivanshkc/ledgerkeep/src/utils/ctxutils"
+	"github.com/shivanshkc/ledgerkeep/src/utils/errutils"
+	"github.com/shivanshkc/ledgerkeep/src/utils/httputils"
+)
+
+// createMemberBody is the schema of the body of the CreateMember API.
+type createMemberBody struct {
+	UserID string `json:"user_id"`
+	Role   string `json:"role"`
+}
+
+// CreateMemberHandler shares the ledger of the request with another user, which is also called an invitation.
+func (h *Handler) CreateMemberHandler(writer http.ResponseWriter, request *http.Request) {
+	ctx := request.Context()
+	log := logger.Get()
+
+	// Decoding the request.
+	var requestBody *createMemberBody
+	if err := httputils.UnmarshalBody(request, &requestBody); err != nil {
+		err = errutils.BadRequest().AddErrors(err)
+		httputils.WriteErrAndLog(ctx, writer, err, log)
+		return
+	}
+
+	// Validating user ID.
+	if !userIDRegexp.MatchString(requestBody.UserID) {
+		err := errutils.BadRequest().AddErrors(errInvalidUserID)
+		httputils.WriteErrAndLog(ctx, writer, err, log)
+		return
+	}
+
+	// Validating role.
+	if !stringPresentCaseInsensitive(requestBody.Role, allowedMemberRoles) {
+		err := errutils.BadRequest().AddErrors(errInvalidMemberRole)
+		httputils.WriteErrAndLog(ctx, writer, err, log)
+		return
+	}
+
+	// The users that the ledger belongs to own it already.
+	ledgerID := ctxutils.GetTenantID(ctx)
+	user, err := h.users.GetUser(ctx, requestBody.UserID)
+	if err != nil {
+		httputils.WriteErrAndLog(ctx, writer, err, log)
+		return
+	}
+	if user.TenantID == ledgerID {
+		err := errutils.BadRequest().AddErrors(errMemberIsLedgerUser)
+		httputils.WriteErrAndLog(ctx, writer, err, log)
+		return
+	}
+
+	member := &models.MemberDTO{
+		LedgerID:  ledgerID,
+		UserID:    user.ID,
+		Role:      strings.ToLower(requestBody.Role),
+		CreatedAt: time.Now().Unix(),
+	}
+
+	// Database call.
+	if err := h.members.InsertMember(ctx, member); err != nil {
+		httputils.WriteErrAndLog(ctx, writer, err, log)
+		return
+	}
+
+	// Final HTTP response.
+	response := &httputils.ResponseDTO{
+		Status: http.StatusCreated,
+		Body: &httputils.ResponseBodyDTO{
+			StatusCode: http.StatusCreated,
+			CustomCode: "MEMBER_CREATED",
+			Data:       member,
+		},
+	}
+
+	httputils.WriteAndLog(ctx, writer, response, log)
+}
@@ -0,0 +1,43 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/shivanshkc/ledgerkeep/src/logger"
+	"github.com/shivanshkc/ledgerkeep/src/utils/ctxutils"
+	"github.com/shivanshkc/ledgerkeep/src/utils/errutils"
+	"github.com/shivanshkc/ledgerkeep/src/utils/httputils"
+
+	"github.com/gorilla/mux"
+)
+
+// DeleteMemberHandler revokes the access of a user to the ledger of the request.
+func (h *Handler) DeleteMemberHandler(writer http.ResponseWriter, request *http.Request) {
+	ctx := request.Context()
+	log := logger.Get()
+
+	userID := mux.Vars(request)["user_id"]
+	// Validating user ID.
+	if !userIDRegexp.MatchString(userID) {
+		err := errutils.BadRequest().AddErrors(errInvalidUserID)
+		httputils.WriteErrAndLog(ctx, writer, err, log)
+		return
+	}
+
+	// Database call.
+	if err := h.members.DeleteMember(ctx, ctxutils.GetTenantID(ctx), userID); err != nil {
+		httputils.WriteErrAndLog(ctx, writer, err, log)
+		return
+	}
+
+	// Final HTTP response.
+	response := &httputils.ResponseDTO{
+		Status: http.StatusOK,
+		Body: &httputils.ResponseBodyDTO{
+			StatusCode: http.StatusOK,
+			CustomCode: "MEMBER_DELETED",
+		},
+	}
+
+	httputils.WriteAndLog(ctx, writer, response, log)
+}
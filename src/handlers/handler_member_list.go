@@ -0,0 +1,34 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/shivanshkc/ledgerkeep/src/logger"
+	"github.com/shivanshkc/ledgerkeep/src/utils/ctxutils"
+	"github.com/shivanshkc/ledgerkeep/src/utils/httputils"
+)
+
+// ListMembersHandler lists all the users that the ledger of the request is shared with.
+func (h *Handler) ListMembersHandler(writer http.ResponseWriter, request *http.Request) {
+	ctx := request.Context()
+	log := logger.Get()
+
+	// Database call.
+	members, err := h.members.ListLedgerMembers(ctx, ctxutils.GetTenantID(ctx))
+	if err != nil {
+		httputils.WriteErrAndLog(ctx, writer, err, log)
+		return
+	}
+
+	// Final HTTP response.
+	response := &httputils.ResponseDTO{
+		Status: http.StatusOK,
+		Body: &httputils.ResponseBodyDTO{
+			StatusCode: http.StatusOK,
+			CustomCode: "MEMBERS_LISTED",
+			Data:       members,
+		},
+	}
+
+	httputils.WriteAndLog(ctx, writer, response, log)
+}
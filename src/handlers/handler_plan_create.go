@@ -0,0 +1,64 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/shivanshkc/ledgerkeep/src/logger"
+	"github.com/shivanshkc/ledgerkeep/src/models"
+	"github.com/shivanshkc/ledgerkeep/src/utils/errutils"
+	"github.com/shivanshkc/ledgerkeep/src/utils/httputils"
+)
+
+// CreateBudgetPlanHandler creates a new budget plan.
+// The validity of a budget plan cannot overlap with that of another plan.
+func (h *Handler) CreateBudgetPlanHandler(writer http.ResponseWriter, request *http.Request) {
+	ctx := request.Context()
+	log := logger.Get()
+
+	// Decoding the request.
+	var requestBody *models.BudgetPlanDTO
+	if err := httputils.UnmarshalBody(request, &requestBody); err != nil {
+		err = errutils.BadRequest().AddErrors(err)
+		httputils.WriteErrAndLog(ctx, writer, err, log)
+		return
+	}
+
+	// Validating user input. IDs are always generated by the database.
+	requestBody.ID = ""
+	if err := prepareBudgetPlan(requestBody); err != nil {
+		err = errutils.BadRequest().AddErrors(err)
+		httputils.WriteErrAndLog(ctx, writer, err, log)
+		return
+	}
+
+	// Checking the validity of the new plan against the existing ones.
+	existingPlans, err := h.budgetPlans.ListBudgetPlans(ctx)
+	if err != nil {
+		httputils.WriteErrAndLog(ctx, writer, err, log)
+		return
+	}
+	if err := checkBudgetPlanOverlap(requestBody, existingPlans); err != nil {
+		err = errutils.BadRequest().AddErrors(err)
+		httputils.WriteErrAndLog(ctx, writer, err, log)
+		return
+	}
+
+	// Database call.
+	insertedID, err := h.budgetPlans.InsertBudgetPlan(ctx, requestBody)
+	if err != nil {
+		httputils.WriteErrAndLog(ctx, writer, err, log)
+		return
+	}
+
+	// Final HTTP response.
+	response := &httputils.ResponseDTO{
+		Status: http.StatusCreated,
+		Body: &httputils.ResponseBodyDTO{
+			StatusCode: http.StatusCreated,
+			CustomCode: "BUDGET_PLAN_CREATED",
+			Data:       map[string]interface{}{"id": insertedID},
+		},
+	}
+
+	httputils.WriteAndLog(ctx, writer, response, log)
+}
@@ -0,0 +1,43 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/shivanshkc/ledgerkeep/src/logger"
+	"github.com/shivanshkc/ledgerkeep/src/utils/errutils"
+	"github.com/shivanshkc/ledgerkeep/src/utils/httputils"
+
+	"github.com/gorilla/mux"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// DeleteBudgetPlanHandler deletes a budget plan by its ID.
+func (h *Handler) DeleteBudgetPlanHandler(writer http.ResponseWriter, request *http.Request) {
+	ctx := request.Context()
+	log := logger.Get()
+
+	planID := mux.Vars(request)["plan_id"]
+	// Validating budget plan ID. Budget plan IDs are always ObjectID hex strings.
+	if !primitive.IsValidObjectID(planID) {
+		err := errutils.BadRequest().AddErrors(errInvalidBudgetPlanID)
+		httputils.WriteErrAndLog(ctx, writer, err, log)
+		return
+	}
+
+	// Database call.
+	if err := h.budgetPlans.DeleteBudgetPlan(ctx, planID); err != nil {
+		httputils.WriteErrAndLog(ctx, writer, err, log)
+		return
+	}
+
+	// Final HTTP response.
+	response := &httputils.ResponseDTO{
+		Status: http.StatusOK,
+		Body: &httputils.ResponseBodyDTO{
+			StatusCode: http.StatusOK,
+			CustomCode: "BUDGET_PLAN_DELETED",
+		},
+	}
+
+	httputils.WriteAndLog(ctx, writer, response, log)
+}
@@ -0,0 +1,33 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/shivanshkc/ledgerkeep/src/logger"
+	"github.com/shivanshkc/ledgerkeep/src/utils/httputils"
+)
+
+// ListBudgetPlansHandler lists all budget plans in ascending order of their validity start times.
+func (h *Handler) ListBudgetPlansHandler(writer http.ResponseWriter, request *http.Request) {
+	ctx := request.Context()
+	log := logger.Get()
+
+	// Database call.
+	plans, err := h.budgetPlans.ListBudgetPlans(ctx)
+	if err != nil {
+		httputils.WriteErrAndLog(ctx, writer, err, log)
+		return
+	}
+
+	// Final HTTP response.
+	response := &httputils.ResponseDTO{
+		Status: http.StatusOK,
+		Body: &httputils.ResponseBodyDTO{
+			StatusCode: http.StatusOK,
+			CustomCode: "BUDGET_PLANS_LISTED",
+			Data:       plans,
+		},
+	}
+
+	httputils.WriteAndLog(ctx, writer, response, log)
+}
@@ -0,0 +1,43 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/shivanshkc/ledgerkeep/src/logger"
+	"github.com/shivanshkc/ledgerkeep/src/utils/errutils"
+	"github.com/shivanshkc/ledgerkeep/src/utils/httputils"
+
+	"github.com/gorilla/mux"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// DeleteImportProfileHandler deletes an import profile by its ID.
+func (h *Handler) DeleteImportProfileHandler(writer http.ResponseWriter, request *http.Request) {
+	ctx := request.Context()
+	log := logger.Get()
+
+	profileID := mux.Vars(request)["profile_id"]
+	// Validating import profile ID. Import profile IDs are always ObjectID hex strings.
+	if !primitive.IsValidObjectID(profileID) {
+		err := errutils.BadRequest().AddErrors(errInvalidImportProfileID)
+		httputils.WriteErrAndLog(ctx, writer, err, log)
+		return
+	}
+
+	// Database call.
+	if err := h.imports.DeleteImportProfile(ctx, profileID); err != nil {
+		httputils.WriteErrAndLog(ctx, writer, err, log)
+		return
+	}
+
+	// Final HTTP response.
+	response := &httputils.ResponseDTO{
+		Status: http.StatusOK,
+		Body: &httputils.ResponseBodyDTO{
+			StatusCode: http.StatusOK,
+			CustomCode: "IMPORT_PROFILE_DELETED",
+		},
+	}
+
+	httputils.WriteAndLog(ctx, writer, response, log)
+}
@@ -0,0 +1,33 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/shivanshkc/ledgerkeep/src/logger"
+	"github.com/shivanshkc/ledgerkeep/src/utils/httputils"
+)
+
+// ListImportProfilesHandler lists all import profiles.
+func (h *Handler) ListImportProfilesHandler(writer http.ResponseWriter, request *http.Request) {
+	ctx := request.Context()
+	log := logger.Get()
+
+	// Database call.
+	profiles, err := h.imports.ListImportProfiles(ctx)
+	if err != nil {
+		httputils.WriteErrAndLog(ctx, writer, err, log)
+		return
+	}
+
+	// Final HTTP response.
+	response := &httputils.ResponseDTO{
+		Status: http.StatusOK,
+		Body: &httputils.ResponseBodyDTO{
+			StatusCode: http.StatusOK,
+			CustomCode: "IMPORT_PROFILES_LISTED",
+			Data:       profiles,
+		},
+	}
+
+	httputils.WriteAndLog(ctx, writer, response, log)
+}
@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/shivanshkc/ledgerkeep/src/logger"
+	"github.com/shivanshkc/ledgerkeep/src/models"
+	"github.com/shivanshkc/ledgerkeep/src/utils/errutils"
+	"github.com/shivanshkc/ledgerkeep/src/utils/httputils"
+)
+
+// createExchangeRatesBody is the schema of the body of the CreateExchangeRates API.
+type createExchangeRatesBody struct {
+	Rates []*models.ExchangeRateDTO `json:"rates"`
+}
+
+// CreateExchangeRatesHandler saves a list of exchange rates.
+// A rate replaces the existing rate of the same currency pair and timestamp, if any.
+func (h *Handler) CreateExchangeRatesHandler(writer http.ResponseWriter, request *http.Request) {
+	ctx := request.Context()
+	log := logger.Get()
+
+	// Decoding the request.
+	var requestBody *createExchangeRatesBody
+	if err := httputils.UnmarshalBody(request, &requestBody); err != nil {
+		err = errutils.BadRequest().AddErrors(err)
+		httputils.WriteErrAndLog(ctx, writer, err, log)
+		return
+	}
+
+	if requestBody == nil || len(requestBody.Rates) == 0 {
+		err := errutils.BadRequest().AddErrors(errEmptyExchangeRates)
+		httputils.WriteErrAndLog(ctx, writer, err, log)
+		return
+	}
+
+	// Validating the rates. IDs are always generated by the database.
+	for _, rate := range requestBody.Rates {
+		if rate == nil {
+			err := errutils.BadRequest().AddErrors(errInvalidExchangeRate)
+			httputils.WriteErrAndLog(ctx, writer, err, log)
+			return
+		}
+		rate.ID = ""
+		if err := prepareExchangeRate(rate); err != nil {
+			err = errutils.BadRequest().AddErrors(err)
+			httputils.WriteErrAndLog(ctx, writer, err, log)
+			return
+		}
+	}
+
+	// Database call.
+	if err := h.exchangeRates.PutExchangeRates(ctx, requestBody.Rates); err != nil {
+		httputils.WriteErrAndLog(ctx, writer, err, log)
+		return
+	}
+
+	// Final HTTP response.
+	response := &httputils.ResponseDTO{
+		Status: http.StatusCreated,
+		Body: &httputils.ResponseBodyDTO{
+			StatusCode: http.StatusCreated,
+			CustomCode: "EXCHANGE_RATES_SAVED",
+			Data:       map[string]interface{}{"count": len(requestBody.Rates)},
+		},
+	}
+
+	httputils.WriteAndLog(ctx, writer, response, log)
+}
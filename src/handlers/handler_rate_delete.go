@@ -0,0 +1,43 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/shivanshkc/ledgerkeep/src/logger"
+	"github.com/shivanshkc/ledgerkeep/src/utils/errutils"
+	"github.com/shivanshkc/ledgerkeep/src/utils/httputils"
+
+	"github.com/gorilla/mux"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// DeleteExchangeRateHandler deletes an exchange rate by its ID.
+func (h *Handler) DeleteExchangeRateHandler(writer http.ResponseWriter, request *http.Request) {
+	ctx := request.Context()
+	log := logger.Get()
+
+	rateID := mux.Vars(request)["rate_id"]
+	// Validating exchange rate ID. Exchange rate IDs are always ObjectID hex strings.
+	if !primitive.IsValidObjectID(rateID) {
+		err := errutils.BadRequest().AddErrors(errInvalidExchangeRateID)
+		httputils.WriteErrAndLog(ctx, writer, err, log)
+		return
+	}
+
+	// Database call.
+	if err := h.exchangeRates.DeleteExchangeRate(ctx, rateID); err != nil {
+		httputils.WriteErrAndLog(ctx, writer, err, log)
+		return
+	}
+
+	// Final HTTP response.
+	response := &httputils.ResponseDTO{
+		Status: http.StatusOK,
+		Body: &httputils.ResponseBodyDTO{
+			StatusCode: http.StatusOK,
+			CustomCode: "EXCHANGE_RATE_DELETED",
+		},
+	}
+
+	httputils.WriteAndLog(ctx, writer, response, log)
+}
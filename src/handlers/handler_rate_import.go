@@ -0,0 +1,58 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/shivanshkc/ledgerkeep/src/logger"
+	"github.com/shivanshkc/ledgerkeep/src/utils/errutils"
+	"github.com/shivanshkc/ledgerkeep/src/utils/httputils"
+)
+
+// ImportExchangeRatesHandler saves the exchange rates of a CSV file, uploaded as the "file" field of a multipart form.
+// Nothing is saved if any of the rows is invalid.
+func (h *Handler) ImportExchangeRatesHandler(writer http.ResponseWriter, request *http.Request) {
+	ctx := request.Context()
+	log := logger.Get()
+
+	// Limiting the upload size.
+	request.Body = http.MaxBytesReader(writer, request.Body, maxExchangeRatesFileSize)
+
+	file, _, err := request.FormFile("file")
+	if err != nil {
+		err = errutils.BadRequest().AddErrors(errInvalidExchangeRatesFile, err)
+		httputils.WriteErrAndLog(ctx, writer, err, log)
+		return
+	}
+	defer func() { _ = file.Close() }()
+
+	// Reading and validating all rows.
+	rates, rowErrs := readExchangeRatesCSV(file)
+	if len(rowErrs) > 0 {
+		err := errutils.BadRequest().AddErrors(rowErrs...)
+		httputils.WriteErrAndLog(ctx, writer, err, log)
+		return
+	}
+	if len(rates) == 0 {
+		err := errutils.BadRequest().AddErrors(errEmptyExchangeRates)
+		httputils.WriteErrAndLog(ctx, writer, err, log)
+		return
+	}
+
+	// Database call.
+	if err := h.exchangeRates.PutExchangeRates(ctx, rates); err != nil {
+		httputils.WriteErrAndLog(ctx, writer, err, log)
+		return
+	}
+
+	// Final HTTP response.
+	response := &httputils.ResponseDTO{
+		Status: http.StatusCreated,
+		Body: &httputils.ResponseBodyDTO{
+			StatusCode: http.StatusCreated,
+			CustomCode: "EXCHANGE_RATES_SAVED",
+			Data:       map[string]interface{}{"count": len(rates)},
+		},
+	}
+
+	httputils.WriteAndLog(ctx, writer, response, log)
+}
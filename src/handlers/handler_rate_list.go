@@ -0,0 +1,52 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/shivanshkc/ledgerkeep/src/logger"
+	"github.com/shivanshkc/ledgerkeep/src/utils/errutils"
+	"github.com/shivanshkc/ledgerkeep/src/utils/httputils"
+)
+
+// ListExchangeRatesHandler lists the exchange rates in ascending order of their timestamps.
+// They can be filtered by the "base" and "quote" currency query parameters.
+func (h *Handler) ListExchangeRatesHandler(writer http.ResponseWriter, request *http.Request) {
+	ctx := request.Context()
+	log := logger.Get()
+
+	// Validating the optional currency filters.
+	currencies := map[string]string{"base": "", "quote": ""}
+	for param := range currencies {
+		value := request.URL.Query().Get(param)
+		if value == "" {
+			continue
+		}
+
+		currency, err := parseCurrency(value)
+		if err != nil {
+			err = errutils.BadRequest().AddErrors(err)
+			httputils.WriteErrAndLog(ctx, writer, err, log)
+			return
+		}
+		currencies[param] = currency
+	}
+
+	// Database call.
+	rates, err := h.exchangeRates.ListExchangeRates(ctx, currencies["base"], currencies["quote"])
+	if err != nil {
+		httputils.WriteErrAndLog(ctx, writer, err, log)
+		return
+	}
+
+	// Final HTTP response.
+	response := &httputils.ResponseDTO{
+		Status: http.StatusOK,
+		Body: &httputils.ResponseBodyDTO{
+			StatusCode: http.StatusOK,
+			CustomCode: "EXCHANGE_RATES_LISTED",
+			Data:       rates,
+		},
+	}
+
+	httputils.WriteAndLog(ctx, writer, response, log)
+}
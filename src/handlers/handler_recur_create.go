@@ -0,0 +1,71 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/shivanshkc/ledgerkeep/src/logger"
+	"github.com/shivanshkc/ledgerkeep/src/models"
+	"github.com/shivanshkc/ledgerkeep/src/utils/errutils"
+	"github.com/shivanshkc/ledgerkeep/src/utils/httputils"
+)
+
+// CreateRecurringTemplateHandler creates a new recurring transaction template.
+// The transactions of the template are created by the scheduler when they become due.
+func (h *Handler) CreateRecurringTemplateHandler(writer http.ResponseWriter, request *http.Request) {
+	ctx := request.Context()
+	log := logger.Get()
+
+	// Decoding the request.
+	var requestBody *models.RecurringTemplateDTO
+	if err := httputils.UnmarshalBody(request, &requestBody); err != nil {
+		err = errutils.BadRequest().AddErrors(err)
+		httputils.WriteErrAndLog(ctx, writer, err, log)
+		return
+	}
+
+	// The categories are required to validate the user input.
+	categories, err := h.getCategorySet(ctx)
+	if err != nil {
+		httputils.WriteErrAndLog(ctx, writer, err, log)
+		return
+	}
+
+	// Validating user input. IDs are always generated by the database.
+	requestBody.ID = ""
+	if err := prepareRecurringTemplate(requestBody, categories); err != nil {
+		err = errutils.BadRequest().AddErrors(err)
+		httputils.WriteErrAndLog(ctx, writer, err, log)
+		return
+	}
+
+	// Checking the account's existence and validating the amount against its currency.
+	account, err := h.accounts.GetAccount(ctx, requestBody.AccountID)
+	if err != nil {
+		httputils.WriteErrAndLog(ctx, writer, err, log)
+		return
+	}
+	if err := checkAmountPrecision(requestBody.Amount, getAccountCurrency(account)); err != nil {
+		err = errutils.BadRequest().AddErrors(err)
+		httputils.WriteErrAndLog(ctx, writer, err, log)
+		return
+	}
+
+	// Database call.
+	insertedID, err := h.recurring.InsertRecurringTemplate(ctx, requestBody)
+	if err != nil {
+		httputils.WriteErrAndLog(ctx, writer, err, log)
+		return
+	}
+
+	// Final HTTP response.
+	response := &httputils.ResponseDTO{
+		Status: http.StatusCreated,
+		Body: &httputils.ResponseBodyDTO{
+			StatusCode: http.StatusCreated,
+			CustomCode: "RECURRING_TEMPLATE_CREATED",
+			Data:       map[string]interface{}{"id": insertedID},
+		},
+	}
+
+	httputils.WriteAndLog(ctx, writer, response, log)
+}
@@ -0,0 +1,44 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/shivanshkc/ledgerkeep/src/logger"
+	"github.com/shivanshkc/ledgerkeep/src/utils/errutils"
+	"github.com/shivanshkc/ledgerkeep/src/utils/httputils"
+
+	"github.com/gorilla/mux"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// DeleteRecurringTemplateHandler deletes a recurring transaction template by its ID.
+// The transactions that were already created by the template are kept.
+func (h *Handler) DeleteRecurringTemplateHandler(writer http.ResponseWriter, request *http.Request) {
+	ctx := request.Context()
+	log := logger.Get()
+
+	templateID := mux.Vars(request)["template_id"]
+	// Validating template ID. Recurring template IDs are always ObjectID hex strings.
+	if !primitive.IsValidObjectID(templateID) {
+		err := errutils.BadRequest().AddErrors(errInvalidRecurringID)
+		httputils.WriteErrAndLog(ctx, writer, err, log)
+		return
+	}
+
+	// Database call.
+	if err := h.recurring.DeleteRecurringTemplate(ctx, templateID); err != nil {
+		httputils.WriteErrAndLog(ctx, writer, err, log)
+		return
+	}
+
+	// Final HTTP response.
+	response := &httputils.ResponseDTO{
+		Status: http.StatusOK,
+		Body: &httputils.ResponseBodyDTO{
+			StatusCode: http.StatusOK,
+			CustomCode: "RECURRING_TEMPLATE_DELETED",
+		},
+	}
+
+	httputils.WriteAndLog(ctx, writer, response, log)
+}
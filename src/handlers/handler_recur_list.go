@@ -0,0 +1,33 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/shivanshkc/ledgerkeep/src/logger"
+	"github.com/shivanshkc/ledgerkeep/src/utils/httputils"
+)
+
+// ListRecurringTemplatesHandler lists all recurring transaction templates.
+func (h *Handler) ListRecurringTemplatesHandler(writer http.ResponseWriter, request *http.Request) {
+	ctx := request.Context()
+	log := logger.Get()
+
+	// Database call.
+	templates, err := h.recurring.ListRecurringTemplates(ctx)
+	if err != nil {
+		httputils.WriteErrAndLog(ctx, writer, err, log)
+		return
+	}
+
+	// Final HTTP response.
+	response := &httputils.ResponseDTO{
+		Status: http.StatusOK,
+		Body: &httputils.ResponseBodyDTO{
+			StatusCode: http.StatusOK,
+			CustomCode: "RECURRING_TEMPLATES_LISTED",
+			Data:       templates,
+		},
+	}
+
+	httputils.WriteAndLog(ctx, writer, response, log)
+}
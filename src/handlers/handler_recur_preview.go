@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/shivanshkc/ledgerkeep/src/logger"
+	"github.com/shivanshkc/ledgerkeep/src/scheduler"
+	"github.com/shivanshkc/ledgerkeep/src/utils/errutils"
+	"github.com/shivanshkc/ledgerkeep/src/utils/httputils"
+
+	"github.com/gorilla/mux"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// PreviewRecurringTemplateHandler lists the upcoming occurrences of a recurring transaction template,
+// starting from its next run. The occurrences are unix timestamps in seconds.
+func (h *Handler) PreviewRecurringTemplateHandler(writer http.ResponseWriter, request *http.Request) {
+	ctx := request.Context()
+	log := logger.Get()
+
+	templateID := mux.Vars(request)["template_id"]
+	// Validating template ID. Recurring template IDs are always ObjectID hex strings.
+	if !primitive.IsValidObjectID(templateID) {
+		err := errutils.BadRequest().AddErrors(errInvalidRecurringID)
+		httputils.WriteErrAndLog(ctx, writer, err, log)
+		return
+	}
+
+	// Validating the limit. Fewer occurrences are previewed by default than the maximum.
+	limit := defaultOccurrencesLimit
+	if limitParam := request.URL.Query().Get("limit"); limitParam != "" {
+		parsed, err := strconv.ParseInt(limitParam, 10, 64)
+		if err != nil || parsed < 1 || parsed > defaultLimit {
+			err := errutils.BadRequest().AddErrors(errInvalidLimit)
+			httputils.WriteErrAndLog(ctx, writer, err, log)
+			return
+		}
+		limit = int(parsed)
+	}
+
+	// Database call.
+	template, err := h.recurring.GetRecurringTemplate(ctx, templateID)
+	if err != nil {
+		httputils.WriteErrAndLog(ctx, writer, err, log)
+		return
+	}
+
+	// A template with no next run has no upcoming occurrences.
+	occurrences := []int64{}
+	if template.NextRun != 0 {
+		if occurrences, err = scheduler.Occurrences(template, template.NextRun, 0, limit); err != nil {
+			httputils.WriteErrAndLog(ctx, writer, err, log)
+			return
+		}
+	}
+
+	// Final HTTP response.
+	response := &httputils.ResponseDTO{
+		Status: http.StatusOK,
+		Body: &httputils.ResponseBodyDTO{
+			StatusCode: http.StatusOK,
+			CustomCode: "RECURRING_OCCURRENCES_LISTED",
+			Data:       occurrences,
+		},
+	}
+
+	httputils.WriteAndLog(ctx, writer, response, log)
+}
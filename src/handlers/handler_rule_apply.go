@@ -0,0 +1,88 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/shivanshkc/ledgerkeep/src/database"
+	"github.com/shivanshkc/ledgerkeep/src/logger"
+	"github.com/shivanshkc/ledgerkeep/src/utils/errutils"
+	"github.com/shivanshkc/ledgerkeep/src/utils/httputils"
+)
+
+// ApplyRulesHandler re-runs the categorization rules over the existing transactions that match the filter of the
+// query, which is read just like that of the ListTransactions API.
+//
+// By default, it is a dry run, which only provides the changes that the rules would make. The changes are saved only
+// if the "dry_run" query parameter is false, in which case all of them are saved atomically.
+func (h *Handler) ApplyRulesHandler(writer http.ResponseWriter, request *http.Request) {
+	ctx := request.Context()
+	log := logger.Get()
+
+	filter, err := getListTransactionsFilter(readListTransactionsQuery(request.URL.Query()))
+	if err != nil {
+		err = errutils.BadRequest().AddErrors(err)
+		httputils.WriteErrAndLog(ctx, writer, err, log)
+		return
+	}
+
+	dryRun := true
+	if value := request.URL.Query().Get("dry_run"); value != "" {
+		if dryRun, err = strconv.ParseBool(value); err != nil {
+			err = errutils.BadRequest().AddErrors(errInvalidDryRun)
+			httputils.WriteErrAndLog(ctx, writer, err, log)
+			return
+		}
+	}
+
+	// The categories are required to check the categories of the rules against the amounts.
+	categories, err := h.getCategorySet(ctx)
+	if err != nil {
+		httputils.WriteErrAndLog(ctx, writer, err, log)
+		return
+	}
+
+	engine, err := h.getRuleEngine(ctx)
+	if err != nil {
+		httputils.WriteErrAndLog(ctx, writer, err, log)
+		return
+	}
+
+	// Database call.
+	transactions, _, err := h.transactions.ListTransactions(ctx, &database.ListTransactionsParams{
+		Filter:       filter,
+		SortField:    "timestamp",
+		SortOrder:    1,
+		ExcludeCount: true,
+	})
+	if err != nil {
+		httputils.WriteErrAndLog(ctx, writer, err, log)
+		return
+	}
+
+	changes, updates := getRuleChanges(engine, transactions, categories)
+
+	customCode := "RULES_PREVIEWED"
+	if !dryRun {
+		customCode = "RULES_APPLIED"
+		// Database call.
+		if len(updates) > 0 {
+			if err := h.transactions.UpdateTransactions(ctx, updates); err != nil {
+				httputils.WriteErrAndLog(ctx, writer, err, log)
+				return
+			}
+		}
+	}
+
+	// Final HTTP response.
+	response := &httputils.ResponseDTO{
+		Status: http.StatusOK,
+		Body: &httputils.ResponseBodyDTO{
+			StatusCode: http.StatusOK,
+			CustomCode: customCode,
+			Data:       map[string]interface{}{"changes": changes},
+		},
+	}
+
+	httputils.WriteAndLog(ctx, writer, response, log)
+}
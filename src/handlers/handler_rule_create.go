@@ -0,0 +1,67 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/shivanshkc/ledgerkeep/src/logger"
+	"github.com/shivanshkc/ledgerkeep/src/models"
+	"github.com/shivanshkc/ledgerkeep/src/utils/errutils"
+	"github.com/shivanshkc/ledgerkeep/src/utils/httputils"
+)
+
+// CreateRuleHandler creates a new categorization rule, which is applied to the transactions that are created or
+// imported from then on.
+func (h *Handler) CreateRuleHandler(writer http.ResponseWriter, request *http.Request) {
+	ctx := request.Context()
+	log := logger.Get()
+
+	// Decoding the request.
+	var requestBody *models.RuleDTO
+	if err := httputils.UnmarshalBody(request, &requestBody); err != nil {
+		err = errutils.BadRequest().AddErrors(err)
+		httputils.WriteErrAndLog(ctx, writer, err, log)
+		return
+	}
+
+	// The categories are required to validate the user input.
+	categories, err := h.getCategorySet(ctx)
+	if err != nil {
+		httputils.WriteErrAndLog(ctx, writer, err, log)
+		return
+	}
+
+	// Validating user input. IDs are always generated by the database.
+	requestBody.ID = ""
+	if err := prepareRule(requestBody, categories); err != nil {
+		err = errutils.BadRequest().AddErrors(err)
+		httputils.WriteErrAndLog(ctx, writer, err, log)
+		return
+	}
+
+	// Checking account's existence, if the rule is limited to an account.
+	if requestBody.AccountID != "" {
+		if _, err := h.accounts.GetAccount(ctx, requestBody.AccountID); err != nil {
+			httputils.WriteErrAndLog(ctx, writer, err, log)
+			return
+		}
+	}
+
+	// Database call.
+	insertedID, err := h.rules.InsertRule(ctx, requestBody)
+	if err != nil {
+		httputils.WriteErrAndLog(ctx, writer, err, log)
+		return
+	}
+
+	// Final HTTP response.
+	response := &httputils.ResponseDTO{
+		Status: http.StatusCreated,
+		Body: &httputils.ResponseBodyDTO{
+			StatusCode: http.StatusCreated,
+			CustomCode: "RULE_CREATED",
+			Data:       map[string]interface{}{"id": insertedID},
+		},
+	}
+
+	httputils.WriteAndLog(ctx, writer, response, log)
+}
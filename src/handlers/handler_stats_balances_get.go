@@ -7,18 +7,35 @@ import (
 
 	"github.com/shivanshkc/ledgerkeep/src/database"
 	"github.com/shivanshkc/ledgerkeep/src/logger"
+	"github.com/shivanshkc/ledgerkeep/src/models"
+	"github.com/shivanshkc/ledgerkeep/src/utils/errutils"
 	"github.com/shivanshkc/ledgerkeep/src/utils/httputils"
 )
 
 // GetStatsBalancesHandler serves the info about how total balance has varied over time.
-func GetStatsBalancesHandler(writer http.ResponseWriter, request *http.Request) {
+// The balances are reported in the currency of the "currency" query parameter, or the default currency.
+func (h *Handler) GetStatsBalancesHandler(writer http.ResponseWriter, request *http.Request) {
 	ctx := request.Context()
 	log := logger.Get()
 
+	// Validating the reporting currency.
+	var currencyParam *string
+	if values := request.URL.Query(); values.Has("currency") {
+		currency := values.Get("currency")
+		currencyParam = &currency
+	}
+
+	reportingCurrency, err := parseReportingCurrency(currencyParam)
+	if err != nil {
+		err = errutils.BadRequest().AddErrors(err)
+		httputils.WriteErrAndLog(ctx, writer, err, log)
+		return
+	}
+
 	// Database call.
-	transactions, _, err := database.ListTransactions(ctx, &database.ListTransactionsParams{
+	transactions, _, err := h.transactions.ListTransactions(ctx, &database.ListTransactionsParams{
 		Filter:          nil,
-		RequiredFields:  []string{"amount", "timestamp"},
+		RequiredFields:  []string{"amount", "timestamp", "account_id"},
 		PaginationLimit: math.MaxInt64,
 		PaginationSkip:  0,
 		SortField:       "timestamp",
@@ -30,24 +47,58 @@ func GetStatsBalancesHandler(writer http.ResponseWriter, request *http.Request)
 		return
 	}
 
+	accountCurrencies, rateBook, err := h.getCurrencyConversionData(ctx)
+	if err != nil {
+		httputils.WriteErrAndLog(ctx, writer, err, log)
+		return
+	}
+
 	// We will return an epoch -> balance map to the caller.
-	responseBalanceMap := map[int64]float64{}
+	responseBalanceMap := map[int64]models.Money{}
+	// The running balances are kept per currency, and converted with the exchange rates in effect at each month end.
+	currencyBalanceMap := map[string]models.Money{}
+
+	// recordBalance converts the running balances into the reporting currency and records their sum.
+	recordBalance := func(balanceTimestamp int64) error {
+		var total models.Money
+		for currency, balance := range currencyBalanceMap {
+			converted, err := rateBook.convert(balance, currency, reportingCurrency, balanceTimestamp)
+			if err != nil {
+				return err
+			}
+			total += converted
+		}
+		responseBalanceMap[balanceTimestamp] = total
+		return nil
+	}
+
 	var balanceTimestampLast *int64
 	for _, tx := range transactions {
 		// Getting the final timestamp of the month of the transaction.
 		// This means that the balances are being grouped by months.
 		balanceTimestamp := toLastDayOfMonth(time.Unix(tx.Timestamp, 0)).Unix()
 
-		// Getting the balance of the last month if available, and adding it as the starting balance of this month.
+		// Recording the balance of the last month before moving on to this month.
 		if balanceTimestampLast != nil && balanceTimestamp != *balanceTimestampLast {
-			responseBalanceMap[balanceTimestamp] = responseBalanceMap[*balanceTimestampLast]
+			if err := recordBalance(*balanceTimestampLast); err != nil {
+				httputils.WriteErrAndLog(ctx, writer, err, log)
+				return
+			}
 		}
 		// Using this transaction's balance.
-		responseBalanceMap[balanceTimestamp] += tx.Amount
+		currencyBalanceMap[accountCurrencies.get(tx.AccountID)] += tx.Amount
 		// Updating the last balance's timestamp for next iteration.
 		balanceTimestampLast = &balanceTimestamp
 	}
 
+	// Recording the balance of the final month.
+	if balanceTimestampLast != nil {
+		if err := recordBalance(*balanceTimestampLast); err != nil {
+			httputils.WriteErrAndLog(ctx, writer, err, log)
+			return
+		}
+	}
+
 	// Final HTTP response.
 	response := &httputils.ResponseDTO{
 		Status: http.StatusOK,
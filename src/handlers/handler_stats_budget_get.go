@@ -1,10 +1,9 @@
 package handlers
 
 import (
-	"math"
 	"net/http"
+	"time"
 
-	"github.com/shivanshkc/ledgerkeep/src/database"
 	"github.com/shivanshkc/ledgerkeep/src/logger"
 	"github.com/shivanshkc/ledgerkeep/src/models"
 	"github.com/shivanshkc/ledgerkeep/src/utils/errutils"
@@ -15,10 +14,11 @@ import (
 type getBudgetQuery struct {
 	StartTime *string
 	EndTime   *string
+	Currency  *string
 }
 
 // GetStatsBudgetHandler serves all the budget information.
-func GetStatsBudgetHandler(writer http.ResponseWriter, request *http.Request) {
+func (h *Handler) GetStatsBudgetHandler(writer http.ResponseWriter, request *http.Request) {
 	ctx := request.Context()
 	log := logger.Get()
 
@@ -38,48 +38,85 @@ func GetStatsBudgetHandler(writer http.ResponseWriter, request *http.Request) {
 		filter["timestamp"] = timestampFilter
 	}
 
-	// Database params for getting transactions that will calculate budget.
-	databaseCallParams := &database.ListTransactionsParams{
-		Filter:          filter,
-		RequiredFields:  nil,
-		PaginationLimit: math.MaxInt64,
-		PaginationSkip:  0,
-		SortField:       "timestamp",
-		SortOrder:       1,
-		ExcludeCount:    true,
+	// Validating the reporting currency.
+	reportingCurrency, err := parseReportingCurrency(qValues.Currency)
+	if err != nil {
+		err = errutils.BadRequest().AddErrors(err)
+		httputils.WriteErrAndLog(ctx, writer, err, log)
+		return
+	}
+
+	// All amounts are converted with the exchange rates in effect at the end of the budget period.
+	conversionTimestamp := time.Now().Unix()
+	if endTimestamp, exists := timestampFilter["$lte"]; exists {
+		conversionTimestamp = endTimestamp.(int64)
+	}
+
+	// Database call. The per-category sums are aggregated by the storage backend.
+	categoryTotals, err := h.transactions.GetCategoryTotals(ctx, filter)
+	if err != nil {
+		httputils.WriteErrAndLog(ctx, writer, err, log)
+		return
+	}
+
+	// The categories tell the budget groups of the totals.
+	categories, err := h.getCategorySet(ctx)
+	if err != nil {
+		httputils.WriteErrAndLog(ctx, writer, err, log)
+		return
 	}
 
-	// Database call.
-	transactions, _, err := database.ListTransactions(ctx, databaseCallParams)
+	accountCurrencies, rateBook, err := h.getCurrencyConversionData(ctx)
 	if err != nil {
 		httputils.WriteErrAndLog(ctx, writer, err, log)
 		return
 	}
 
 	// This is the budget that will be finally returned.
-	budget := &models.Budget{}
+	budget := &models.Budget{Currency: reportingCurrency}
 
-	for _, tx := range transactions {
-		if tx.Amount > 0 && tx.Category != categoryIgnorable {
-			budget.TotalIncome += tx.Amount
+	for _, totals := range categoryTotals {
+		// Transfers only move money between accounts, so they are neither income nor expense.
+		if totals.Category == categoryTransfer {
+			continue
 		}
 
-		switch tx.Category {
-		case categoryEssentials:
-			budget.EssentialsActual += -tx.Amount
-		case categoryInvestments:
-			budget.InvestmentsActual += -tx.Amount
-		// We do nothing for the "savings" category because it is actually the unspent amount.
+		// Converting the totals into the reporting currency.
+		currency := accountCurrencies.get(totals.AccountID)
+		credit, err := rateBook.convert(totals.Credit, currency, reportingCurrency, conversionTimestamp)
+		if err != nil {
+			httputils.WriteErrAndLog(ctx, writer, err, log)
+			return
+		}
+		debit, err := rateBook.convert(totals.Debit, currency, reportingCurrency, conversionTimestamp)
+		if err != nil {
+			httputils.WriteErrAndLog(ctx, writer, err, log)
+			return
+		}
+
+		// The actual value of an expense group is its net expense.
+		netExpense := -(credit + debit)
+
+		switch categories.budgetGroup(totals.Category) {
+		// The income is net of any debits, such as the reversal of a wrong credit.
+		case models.BudgetGroupIncome:
+			budget.TotalIncome += credit + debit
+		case models.BudgetGroupEssentials:
+			budget.EssentialsActual += netExpense
+		case models.BudgetGroupInvestments:
+			budget.InvestmentsActual += netExpense
+		// We do nothing for the "savings" group because it is actually the unspent amount.
 		// To calculate it, we will later subtract all expenses from the total income.
-		case categorySavings:
-		case categoryLuxury:
-			budget.LuxuryActual += -tx.Amount
-		// This is a special case. That's because "ignorable" is the only common category between Credit and Debit
+		case models.BudgetGroupSavings:
+		case models.BudgetGroupLuxury:
+			budget.LuxuryActual += netExpense
+		// This is a special case. That's because "ignorable" categories usually have both Credit and Debit
 		// transactions.
 		// We record this amount in the budget because ideally it should resolve to zero and the budget information
 		// helps the user to easily keep track of it.
-		case categoryIgnorable:
-			budget.IgnorableActual += -tx.Amount
+		case models.BudgetGroupIgnorable:
+			budget.IgnorableActual += netExpense
+		// Totals of unknown categories are not a part of the budget.
 		default:
 		}
 	}
@@ -88,12 +125,46 @@ func GetStatsBudgetHandler(writer http.ResponseWriter, request *http.Request) {
 	budget.SavingsActual = budget.TotalIncome -
 		(budget.EssentialsActual + budget.InvestmentsActual + budget.LuxuryActual + budget.IgnorableActual)
 
+	// The budget plan in effect at the end of the period provides the expected contributions.
+	plans, err := h.budgetPlans.ListBudgetPlans(ctx)
+	if err != nil {
+		httputils.WriteErrAndLog(ctx, writer, err, log)
+		return
+	}
+
+	allocations, planCurrency := getDefaultBudgetAllocations(), reportingCurrency
+	if plan := findEffectiveBudgetPlan(plans, conversionTimestamp); plan != nil {
+		allocations, planCurrency = plan.Allocations, plan.Currency
+		budget.PlanID, budget.PlanName = plan.ID, plan.Name
+	}
+
 	// Calculating the expected amounts using their corresponding expected contributions.
-	budget.EssentialsExpected = budget.TotalIncome * essentialsContrib
-	budget.InvestmentsExpected = budget.TotalIncome * investmentsContrib
-	budget.SavingsExpected = budget.TotalIncome * savingsContrib
-	budget.LuxuryExpected = budget.TotalIncome * luxuryContrib
-	budget.IgnorableExpected = budget.TotalIncome * ignorableContrib
+	for _, allocation := range allocations {
+		var expected models.Money
+		if allocation.Percent != nil {
+			expected = budget.TotalIncome.Percent(*allocation.Percent)
+		} else if allocation.Amount != nil {
+			expected, err = rateBook.convert(*allocation.Amount, planCurrency, reportingCurrency, conversionTimestamp)
+			if err != nil {
+				httputils.WriteErrAndLog(ctx, writer, err, log)
+				return
+			}
+		}
+
+		switch allocation.BudgetGroup {
+		case models.BudgetGroupEssentials:
+			budget.EssentialsExpected = expected
+		case models.BudgetGroupInvestments:
+			budget.InvestmentsExpected = expected
+		case models.BudgetGroupSavings:
+			budget.SavingsExpected = expected
+		case models.BudgetGroupLuxury:
+			budget.LuxuryExpected = expected
+		case models.BudgetGroupIgnorable:
+			budget.IgnorableExpected = expected
+		default:
+		}
+	}
 
 	// Final HTTP response.
 	response := &httputils.ResponseDTO{
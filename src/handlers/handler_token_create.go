@@ -0,0 +1,110 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/shivanshkc/ledgerkeep/src/logger"
+	"github.com/shivanshkc/ledgerkeep/src/models"
+	"github.com/shivanshkc/ledgerkeep/src/utils/authutils"
+	"github.com/shivanshkc/ledgerkeep/src/utils/ctxutils"
+	"github.com/shivanshkc/ledgerkeep/src/utils/errutils"
+	"github.com/shivanshkc/ledgerkeep/src/utils/httputils"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// createTokenBody is the schema of the body of the CreateToken API.
+type createTokenBody struct {
+	Name      string   `json:"name"`
+	Scopes    []string `json:"scopes"`
+	ExpiresAt int64    `json:"expires_at"`
+}
+
+// createdTokenDTO is the schema of the response of the CreateToken API. It is the only one that has the token itself.
+type createdTokenDTO struct {
+	*models.TokenDTO
+	Token string `json:"token"`
+}
+
+// CreateTokenHandler creates a new API token for the user, which can be used as a bearer token instead of the basic
+// auth credentials.
+func (h *Handler) CreateTokenHandler(writer http.ResponseWriter, request *http.Request) {
+	ctx := request.Context()
+	log := logger.Get()
+
+	// Decoding the request.
+	var requestBody *createTokenBody
+	if err := httputils.UnmarshalBody(request, &requestBody); err != nil {
+		err = errutils.BadRequest().AddErrors(err)
+		httputils.WriteErrAndLog(ctx, writer, err, log)
+		return
+	}
+
+	// Validating name.
+	if !tokenNameRegexp.MatchString(requestBody.Name) {
+		err := errutils.BadRequest().AddErrors(errInvalidTokenName)
+		httputils.WriteErrAndLog(ctx, writer, err, log)
+		return
+	}
+
+	// Validating scopes.
+	if len(requestBody.Scopes) == 0 {
+		err := errutils.BadRequest().AddErrors(errInvalidTokenScopes)
+		httputils.WriteErrAndLog(ctx, writer, err, log)
+		return
+	}
+	scopes := make([]string, len(requestBody.Scopes))
+	for idx, scope := range requestBody.Scopes {
+		if !stringPresentCaseInsensitive(scope, models.TokenScopes) {
+			err := errutils.BadRequest().AddErrors(errInvalidTokenScopes)
+			httputils.WriteErrAndLog(ctx, writer, err, log)
+			return
+		}
+		scopes[idx] = strings.ToLower(scope)
+	}
+
+	// Validating expiry.
+	now := time.Now().Unix()
+	if requestBody.ExpiresAt < 0 || (requestBody.ExpiresAt != 0 && requestBody.ExpiresAt <= now) {
+		err := errutils.BadRequest().AddErrors(errInvalidTokenExpiry)
+		httputils.WriteErrAndLog(ctx, writer, err, log)
+		return
+	}
+
+	tokenValue, err := authutils.GenerateToken()
+	if err != nil {
+		httputils.WriteErrAndLog(ctx, writer, err, log)
+		return
+	}
+
+	// Token IDs are ObjectIDs, just like the ones generated by MongoDB.
+	token := &models.TokenDTO{
+		ID:        primitive.NewObjectID().Hex(),
+		UserID:    ctxutils.GetUserID(ctx),
+		Name:      requestBody.Name,
+		Scopes:    scopes,
+		TokenHash: authutils.HashToken(tokenValue),
+		ExpiresAt: requestBody.ExpiresAt,
+		CreatedAt: now,
+	}
+
+	// Database call.
+	if err := h.tokens.InsertToken(ctx, token); err != nil {
+		httputils.WriteErrAndLog(ctx, writer, err, log)
+		return
+	}
+
+	// Final HTTP response.
+	response := &httputils.ResponseDTO{
+		Status: http.StatusCreated,
+		Body: &httputils.ResponseBodyDTO{
+			StatusCode: http.StatusCreated,
+			CustomCode: "TOKEN_CREATED",
+			Data:       &createdTokenDTO{TokenDTO: token, Token: tokenValue},
+		},
+	}
+
+	httputils.WriteAndLog(ctx, writer, response, log)
+}
@@ -0,0 +1,35 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/shivanshkc/ledgerkeep/src/logger"
+	"github.com/shivanshkc/ledgerkeep/src/utils/ctxutils"
+	"github.com/shivanshkc/ledgerkeep/src/utils/httputils"
+)
+
+// ListTokensHandler lists all the API tokens of the user, along with the times of their last use.
+// The tokens themselves are not in the response, as only their hashes are stored.
+func (h *Handler) ListTokensHandler(writer http.ResponseWriter, request *http.Request) {
+	ctx := request.Context()
+	log := logger.Get()
+
+	// Database call.
+	tokens, err := h.tokens.ListUserTokens(ctx, ctxutils.GetUserID(ctx))
+	if err != nil {
+		httputils.WriteErrAndLog(ctx, writer, err, log)
+		return
+	}
+
+	// Final HTTP response.
+	response := &httputils.ResponseDTO{
+		Status: http.StatusOK,
+		Body: &httputils.ResponseBodyDTO{
+			StatusCode: http.StatusOK,
+			CustomCode: "TOKENS_LISTED",
+			Data:       tokens,
+		},
+	}
+
+	httputils.WriteAndLog(ctx, writer, response, log)
+}
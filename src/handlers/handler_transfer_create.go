@@ -0,0 +1,108 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/shivanshkc/ledgerkeep/src/logger"
+	"github.com/shivanshkc/ledgerkeep/src/models"
+	"github.com/shivanshkc/ledgerkeep/src/utils/errutils"
+	"github.com/shivanshkc/ledgerkeep/src/utils/httputils"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// createTransferBody is the schema of the body of the CreateTransfer API.
+type createTransferBody struct {
+	// Amount is the positive amount that leaves the source account, in its currency.
+	Amount models.Money `json:"amount"`
+	// ToAmount is the positive amount that arrives in the destination account, in its currency.
+	// It is required only if the accounts have different currencies.
+	ToAmount      *models.Money `json:"to_amount"`
+	Timestamp     int64         `json:"timestamp"`
+	FromAccountID string        `json:"from_account_id"`
+	ToAccountID   string        `json:"to_account_id"`
+	Notes         string        `json:"notes"`
+}
+
+// CreateTransferHandler moves money between two accounts.
+// It creates a debit transaction in the source account and a credit transaction in the destination account,
+// which are linked through the ID of the transfer.
+func (h *Handler) CreateTransferHandler(writer http.ResponseWriter, request *http.Request) {
+	ctx := request.Context()
+	log := logger.Get()
+
+	// Decoding the request.
+	var requestBody *createTransferBody
+	if err := httputils.UnmarshalBody(request, &requestBody); err != nil {
+		err = errutils.BadRequest().AddErrors(err)
+		httputils.WriteErrAndLog(ctx, writer, err, log)
+		return
+	}
+
+	// Validating the user input that does not depend on the accounts.
+	if err := checkNewTransferBody(requestBody); err != nil {
+		err = errutils.BadRequest().AddErrors(err)
+		httputils.WriteErrAndLog(ctx, writer, err, log)
+		return
+	}
+
+	// Checking the existence of both accounts. The accounts provide the currencies of the legs.
+	fromAccount, err := h.accounts.GetAccount(ctx, requestBody.FromAccountID)
+	if err != nil {
+		httputils.WriteErrAndLog(ctx, writer, err, log)
+		return
+	}
+	toAccount, err := h.accounts.GetAccount(ctx, requestBody.ToAccountID)
+	if err != nil {
+		httputils.WriteErrAndLog(ctx, writer, err, log)
+		return
+	}
+
+	// Validating the amounts against the currencies of the accounts.
+	toAmount, err := getTransferToAmount(requestBody, getAccountCurrency(fromAccount), getAccountCurrency(toAccount))
+	if err != nil {
+		err = errutils.BadRequest().AddErrors(err)
+		httputils.WriteErrAndLog(ctx, writer, err, log)
+		return
+	}
+
+	// Transfer IDs are ObjectIDs, just like transaction IDs.
+	transferID := primitive.NewObjectID().Hex()
+	legs := []*models.TransactionDTO{
+		{
+			Amount:     -requestBody.Amount,
+			Timestamp:  requestBody.Timestamp,
+			AccountID:  requestBody.FromAccountID,
+			Category:   categoryTransfer,
+			Notes:      requestBody.Notes,
+			TransferID: transferID,
+		},
+		{
+			Amount:     toAmount,
+			Timestamp:  requestBody.Timestamp,
+			AccountID:  requestBody.ToAccountID,
+			Category:   categoryTransfer,
+			Notes:      requestBody.Notes,
+			TransferID: transferID,
+		},
+	}
+
+	// Database call. Both legs are created atomically.
+	transactionIDs, err := h.transactions.InsertTransfer(ctx, legs)
+	if err != nil {
+		httputils.WriteErrAndLog(ctx, writer, err, log)
+		return
+	}
+
+	// Final HTTP response.
+	response := &httputils.ResponseDTO{
+		Status: http.StatusCreated,
+		Body: &httputils.ResponseBodyDTO{
+			StatusCode: http.StatusCreated,
+			CustomCode: "TRANSFER_CREATED",
+			Data:       map[string]interface{}{"id": transferID, "transaction_ids": transactionIDs},
+		},
+	}
+
+	httputils.WriteAndLog(ctx, writer, response, log)
+}
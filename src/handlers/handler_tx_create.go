@@ -3,26 +3,43 @@ package handlers
 import (
 	"net/http"
 
-	"github.com/shivanshkc/ledgerkeep/src/database"
 	"github.com/shivanshkc/ledgerkeep/src/logger"
+	"github.com/shivanshkc/ledgerkeep/src/models"
 	"github.com/shivanshkc/ledgerkeep/src/utils/errutils"
 	"github.com/shivanshkc/ledgerkeep/src/utils/httputils"
 )
 
 // createTransactionBody is the schema of the body of the CreateTransaction API.
 type createTransactionBody struct {
-	Amount    float64 `json:"amount"`
-	Timestamp int64   `json:"timestamp"`
-	AccountID string  `json:"account_id"`
-	Category  string  `json:"category"`
-	Notes     string  `json:"notes"`
+	Amount    models.Money `json:"amount"`
+	Timestamp int64        `json:"timestamp"`
+	AccountID string       `json:"account_id"`
+	Category  string       `json:"category"`
+	Notes     string       `json:"notes"`
+	// Splits optionally divide the amount among several categories.
+	Splits []*models.SplitDTO `json:"splits"`
+	// Tags optionally label the transaction.
+	Tags []string `json:"tags"`
 }
 
 // CreateTransactionHandler creates a new transaction in the system.
-func CreateTransactionHandler(writer http.ResponseWriter, request *http.Request) {
+//
+// The categorization rules are applied to the transaction before it is validated, so the category may be left empty
+// for the rules to fill in.
+//
+// A transaction that looks like a duplicate of an existing one is refused with a conflict, unless the
+// "allow_duplicates" query parameter is true, in which case the IDs of its duplicates are provided as a warning.
+func (h *Handler) CreateTransactionHandler(writer http.ResponseWriter, request *http.Request) {
 	ctx := request.Context()
 	log := logger.Get()
 
+	allowDuplicates, err := readAllowDuplicates(request.URL.Query().Get("allow_duplicates"))
+	if err != nil {
+		err = errutils.BadRequest().AddErrors(err)
+		httputils.WriteErrAndLog(ctx, writer, err, log)
+		return
+	}
+
 	// Decoding the request.
 	var requestBody *createTransactionBody
 	if err := httputils.UnmarshalBody(request, &requestBody); err != nil {
@@ -31,39 +48,78 @@ func CreateTransactionHandler(writer http.ResponseWriter, request *http.Request)
 		return
 	}
 
+	// The categories are required to validate the user input.
+	categories, err := h.getCategorySet(ctx)
+	if err != nil {
+		httputils.WriteErrAndLog(ctx, writer, err, log)
+		return
+	}
+
+	engine, err := h.getRuleEngine(ctx)
+	if err != nil {
+		httputils.WriteErrAndLog(ctx, writer, err, log)
+		return
+	}
+	applyRules(engine, requestBody, categories)
+
 	// This call validates the user input.
-	transaction, err := prepareNewTransaction(requestBody)
+	transaction, err := prepareNewTransaction(requestBody, categories)
 	if err != nil {
 		err = errutils.BadRequest().AddErrors(err)
 		httputils.WriteErrAndLog(ctx, writer, err, log)
 		return
 	}
 
-	// Checking account's existence.
-	accountExists, err := database.IsAccountExists(ctx, requestBody.AccountID)
+	// Checking account's existence. The account provides the currency of the transaction.
+	account, err := h.accounts.GetAccount(ctx, requestBody.AccountID)
 	if err != nil {
 		httputils.WriteErrAndLog(ctx, writer, err, log)
 		return
 	}
-	if !accountExists {
-		httputils.WriteErrAndLog(ctx, writer, errutils.AccountNotFound(), log)
+
+	// The amounts should be valid in the currency of the account.
+	err = checkAmountPrecision(transaction.Amount, getAccountCurrency(account))
+	if err == nil {
+		err = checkSplitsPrecision(transaction.Splits, getAccountCurrency(account))
+	}
+	if err != nil {
+		err = errutils.BadRequest().AddErrors(err)
+		httputils.WriteErrAndLog(ctx, writer, err, log)
+		return
+	}
+
+	duplicateIDs, err := h.findDuplicates(ctx, []*models.TransactionDTO{transaction})
+	if err != nil {
+		httputils.WriteErrAndLog(ctx, writer, err, log)
+		return
+	}
+	if hasDuplicates(duplicateIDs) && !allowDuplicates {
+		err := duplicateTransactionError(duplicateIDs, func(int) string { return "transaction" })
+		httputils.WriteErrAndLog(ctx, writer, err, log)
 		return
 	}
 
 	// Database call.
-	insertedID, err := database.InsertTransaction(ctx, transaction)
+	// Backends with foreign keys report a concurrently deleted account as errutils.AccountNotFound here.
+	insertedID, err := h.transactions.InsertTransaction(ctx, transaction)
 	if err != nil {
-		httputils.WriteErrAndLog(ctx, writer, errutils.AccountNotFound(), log)
+		httputils.WriteErrAndLog(ctx, writer, err, log)
 		return
 	}
 
+	data := map[string]interface{}{"id": insertedID}
+	// The allowed duplicates are reported as a warning.
+	if hasDuplicates(duplicateIDs) {
+		data["duplicate_ids"] = duplicateIDs[0]
+	}
+
 	// Final HTTP response.
 	response := &httputils.ResponseDTO{
 		Status: http.StatusCreated,
 		Body: &httputils.ResponseBodyDTO{
 			StatusCode: http.StatusCreated,
 			CustomCode: "TRANSACTION_CREATED",
-			Data:       map[string]interface{}{"id": insertedID},
+			Data:       data,
 		},
 	}
 
@@ -3,7 +3,6 @@ package handlers
 import (
 	"net/http"
 
-	"github.com/shivanshkc/ledgerkeep/src/database"
 	"github.com/shivanshkc/ledgerkeep/src/logger"
 	"github.com/shivanshkc/ledgerkeep/src/utils/errutils"
 	"github.com/shivanshkc/ledgerkeep/src/utils/httputils"
@@ -13,21 +12,32 @@ import (
 )
 
 // DeleteTransactionHandler deletes a transaction by its ID.
-func DeleteTransactionHandler(writer http.ResponseWriter, request *http.Request) {
+func (h *Handler) DeleteTransactionHandler(writer http.ResponseWriter, request *http.Request) {
 	ctx := request.Context()
 	log := logger.Get()
 
-	transactionIDStr := mux.Vars(request)["transaction_id"]
-	// Converting the transactionID to an ObjectID. This conversion also validates the transaction ID.
-	transactionID, err := primitive.ObjectIDFromHex(transactionIDStr)
+	transactionID := mux.Vars(request)["transaction_id"]
+	// Validating transaction ID. Transaction IDs are always ObjectID hex strings.
+	if !primitive.IsValidObjectID(transactionID) {
+		err := errutils.BadRequest().AddErrors(errInvalidTxID)
+		httputils.WriteErrAndLog(ctx, writer, err, log)
+		return
+	}
+
+	// Getting the transaction to know if it is a leg of a transfer.
+	transaction, err := h.transactions.GetTransaction(ctx, transactionID)
 	if err != nil {
-		err = errutils.BadRequest().AddErrors(errInvalidTxID)
 		httputils.WriteErrAndLog(ctx, writer, err, log)
 		return
 	}
 
-	// Database call.
-	if err := database.DeleteTransaction(ctx, transactionID); err != nil {
+	// Database call. Deleting a leg of a transfer deletes all its legs.
+	if transaction.TransferID == "" {
+		err = h.transactions.DeleteTransaction(ctx, transactionID)
+	} else {
+		err = h.transactions.DeleteTransfer(ctx, transaction.TransferID)
+	}
+	if err != nil {
 		httputils.WriteErrAndLog(ctx, writer, err, log)
 		return
 	}
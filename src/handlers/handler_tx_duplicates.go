@@ -0,0 +1,50 @@
+package handlers
+
+import (
+	"math"
+	"net/http"
+
+	"github.com/shivanshkc/ledgerkeep/src/database"
+	"github.com/shivanshkc/ledgerkeep/src/logger"
+	"github.com/shivanshkc/ledgerkeep/src/utils/httputils"
+)
+
+// ListDuplicateTransactionsHandler lists the groups of the existing transactions that look like duplicates of each
+// other. The "account_id" query parameter optionally limits the groups to an account.
+func (h *Handler) ListDuplicateTransactionsHandler(writer http.ResponseWriter, request *http.Request) {
+	ctx := request.Context()
+	log := logger.Get()
+
+	filter := msi{}
+	// If account ID filter is provided, we use it.
+	if accountID := request.URL.Query().Get("account_id"); accountID != "" {
+		filter["account_id"] = accountID
+	}
+
+	// Database call.
+	transactions, _, err := h.transactions.ListTransactions(ctx, &database.ListTransactionsParams{
+		Filter:          filter,
+		PaginationLimit: math.MaxInt64,
+		SortField:       "timestamp",
+		SortOrder:       1,
+		ExcludeCount:    true,
+	})
+	if err != nil {
+		httputils.WriteErrAndLog(ctx, writer, err, log)
+		return
+	}
+
+	groups := getDuplicateDetector().FindGroups(transactions)
+
+	// Final HTTP response.
+	response := &httputils.ResponseDTO{
+		Status: http.StatusOK,
+		Body: &httputils.ResponseBodyDTO{
+			StatusCode: http.StatusOK,
+			CustomCode: "DUPLICATES_LISTED",
+			Data:       map[string]interface{}{"groups": groups},
+		},
+	}
+
+	httputils.WriteAndLog(ctx, writer, response, log)
+}
@@ -0,0 +1,88 @@
+package handlers
+
+import (
+	"bufio"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/shivanshkc/ledgerkeep/src/database"
+	"github.com/shivanshkc/ledgerkeep/src/logger"
+	"github.com/shivanshkc/ledgerkeep/src/utils/errutils"
+	"github.com/shivanshkc/ledgerkeep/src/utils/httputils"
+)
+
+// ExportTransactionsHandler exports all the transactions that match the filters of the ListTransactions API, along
+// with their closing balances. The format of the export, which is one of allowedExportFormats, is the "format" query
+// parameter, and it is CSV by default.
+//
+// The transactions are exported in the order of their timestamps, and the pagination and sort params are ignored.
+// They are streamed from the database as they are written, so the exports of any size are never loaded into memory.
+func (h *Handler) ExportTransactionsHandler(writer http.ResponseWriter, request *http.Request) {
+	ctx := request.Context()
+	log := logger.Get()
+
+	format := strings.ToLower(request.URL.Query().Get("format"))
+	if format == "" {
+		format = exportFormatCSV
+	}
+	if !stringPresentCaseInsensitive(format, allowedExportFormats) {
+		err := errutils.BadRequest().AddErrors(errInvalidExportFormat)
+		httputils.WriteErrAndLog(ctx, writer, err, log)
+		return
+	}
+
+	filter, err := getListTransactionsFilter(readListTransactionsQuery(request.URL.Query()))
+	if err != nil {
+		err = errutils.BadRequest().AddErrors(err)
+		httputils.WriteErrAndLog(ctx, writer, err, log)
+		return
+	}
+
+	// Database call. The accounts provide the currencies of the transactions.
+	accounts, err := h.accounts.ListAccounts(ctx)
+	if err != nil {
+		httputils.WriteErrAndLog(ctx, writer, err, log)
+		return
+	}
+
+	// The cursor of all the transactions, which provides the closing balances.
+	allCursor, err := h.transactions.StreamTransactions(ctx, &database.ListTransactionsParams{
+		RequiredFields: closingBalFields,
+		SortField:      "timestamp",
+		SortOrder:      1,
+	})
+	if err != nil {
+		httputils.WriteErrAndLog(ctx, writer, err, log)
+		return
+	}
+	defer func() { _ = allCursor.Close(ctx) }()
+
+	// The cursor of the exported transactions.
+	cursor, err := h.transactions.StreamTransactions(ctx, &database.ListTransactionsParams{
+		Filter:    filter,
+		SortField: "timestamp",
+		SortOrder: 1,
+	})
+	if err != nil {
+		httputils.WriteErrAndLog(ctx, writer, err, log)
+		return
+	}
+	defer func() { _ = cursor.Close(ctx) }()
+
+	writer.Header().Set("content-type", exportContentTypes[format])
+	writer.Header().Set("content-disposition", fmt.Sprintf(`attachment; filename="transactions.%s"`, format))
+	// Setting the status code. No more headers can be set after this, so the later errors can only be logged.
+	writer.WriteHeader(http.StatusOK)
+
+	buffered := bufio.NewWriter(writer)
+	rows := newExportRowStream(cursor, allCursor, newAccountCurrencyMap(accounts))
+	if err := writeExport(ctx, newExportWriter(format, buffered), rows); err != nil {
+		log.Error(ctx, &logger.Entry{Payload: fmt.Errorf("failed to export transactions: %w", err)})
+		return
+	}
+
+	if err := buffered.Flush(); err != nil {
+		log.Error(ctx, &logger.Entry{Payload: fmt.Errorf("failed to write export: %w", err)})
+	}
+}
@@ -3,7 +3,6 @@ package handlers
 import (
 	"net/http"
 
-	"github.com/shivanshkc/ledgerkeep/src/database"
 	"github.com/shivanshkc/ledgerkeep/src/logger"
 	"github.com/shivanshkc/ledgerkeep/src/utils/errutils"
 	"github.com/shivanshkc/ledgerkeep/src/utils/httputils"
@@ -13,21 +12,20 @@ import (
 )
 
 // GetTransactionHandler gets a transaction by its ID.
-func GetTransactionHandler(writer http.ResponseWriter, request *http.Request) {
+func (h *Handler) GetTransactionHandler(writer http.ResponseWriter, request *http.Request) {
 	ctx := request.Context()
 	log := logger.Get()
 
-	transactionIDStr := mux.Vars(request)["transaction_id"]
-	// Converting the transactionID to an ObjectID. This conversion also validates the transaction ID.
-	transactionID, err := primitive.ObjectIDFromHex(transactionIDStr)
-	if err != nil {
-		err = errutils.BadRequest().AddErrors(errInvalidTxID)
+	transactionID := mux.Vars(request)["transaction_id"]
+	// Validating transaction ID. Transaction IDs are always ObjectID hex strings.
+	if !primitive.IsValidObjectID(transactionID) {
+		err := errutils.BadRequest().AddErrors(errInvalidTxID)
 		httputils.WriteErrAndLog(ctx, writer, err, log)
 		return
 	}
 
 	// Database call.
-	transaction, err := database.GetTransaction(ctx, transactionID)
+	transaction, err := h.transactions.GetTransaction(ctx, transactionID)
 	if err != nil {
 		httputils.WriteErrAndLog(ctx, writer, err, log)
 		return
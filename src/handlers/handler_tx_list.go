@@ -32,51 +32,18 @@ type listTransactionsQuery struct {
 }
 
 // ListTransactionsHandler lists transactions as per the provided queries.
-func ListTransactionsHandler(writer http.ResponseWriter, request *http.Request) {
+func (h *Handler) ListTransactionsHandler(writer http.ResponseWriter, request *http.Request) {
 	ctx := request.Context()
 	log := logger.Get()
 
 	qValues := readListTransactionsQuery(request.URL.Query())
-	filter := msi{}
-
-	// Validating the amount values and creating the amount filter.
-	amountFilter, err := getStartEndAmountFilter(qValues.StartAmount, qValues.EndAmount)
-	if err != nil {
-		err = errutils.BadRequest().AddErrors(err)
-		httputils.WriteErrAndLog(ctx, writer, err, log)
-		return
-	}
-	// If the amount filter has any entries, we put it inside the main filter.
-	if len(amountFilter) > 0 {
-		filter["amount"] = amountFilter
-	}
 
-	// Validating the timestamp values and creating the timestamp filter.
-	timestampFilter, err := getStartEndTimestampFilter(qValues.StartTime, qValues.EndTime)
+	filter, err := getListTransactionsFilter(qValues)
 	if err != nil {
 		err = errutils.BadRequest().AddErrors(err)
 		httputils.WriteErrAndLog(ctx, writer, err, log)
 		return
 	}
-	// If the timestamp filter has any entries, we put it inside the main filter.
-	if len(timestampFilter) > 0 {
-		filter["timestamp"] = timestampFilter
-	}
-
-	// If account ID filter is provided, we use it.
-	if qValues.AccountID != nil && *qValues.AccountID != "" {
-		filter["account_id"] = *qValues.AccountID
-	}
-
-	// If category filter is provided, we use it.
-	if qValues.Category != nil && *qValues.Category != "" {
-		filter["category"] = strings.ToLower(*qValues.Category)
-	}
-
-	// Full text search on the notes field.
-	if qValues.NotesHint != nil && *qValues.NotesHint != "" {
-		filter["$text"] = msi{"$search": *qValues.NotesHint}
-	}
 
 	// Parsing limit and skip to int.
 	limit, skip, err := parseLimitSkip(qValues.Limit, qValues.Skip)
@@ -129,7 +96,7 @@ func ListTransactionsHandler(writer http.ResponseWriter, request *http.Request)
 		// Channels will be closed upon function return.
 		defer close(transactions4ClosingBalChan)
 		// Database call.
-		allTransactions, _, err := database.ListTransactions(errCtx, databaseParamsForClosingBal)
+		allTransactions, _, err := h.transactions.ListTransactions(errCtx, databaseParamsForClosingBal)
 		if err != nil {
 			return fmt.Errorf("failed to list transactions for closing bal: %w", err)
 		}
@@ -143,7 +110,7 @@ func ListTransactionsHandler(writer http.ResponseWriter, request *http.Request)
 		defer close(transactionsChan)
 		defer close(transactionsCountChan)
 		// Database call.
-		transactions, count, err := database.ListTransactions(errCtx, databaseParamsForList)
+		transactions, count, err := h.transactions.ListTransactions(errCtx, databaseParamsForList)
 		if err != nil {
 			return fmt.Errorf("failed to get origin transaction list: %w", err)
 		}
@@ -192,3 +159,46 @@ func ListTransactionsHandler(writer http.ResponseWriter, request *http.Request)
 
 	httputils.WriteAndLog(ctx, writer, response, log)
 }
+
+// getListTransactionsFilter validates the filter params of the listTransactionsQuery and creates the database filter.
+// The pagination and sort params are not a part of the filter.
+func getListTransactionsFilter(qValues *listTransactionsQuery) (msi, error) {
+	filter := msi{}
+
+	// Validating the amount values and creating the amount filter.
+	amountFilter, err := getStartEndAmountFilter(qValues.StartAmount, qValues.EndAmount)
+	if err != nil {
+		return nil, err
+	}
+	// If the amount filter has any entries, we put it inside the main filter.
+	if len(amountFilter) > 0 {
+		filter["amount"] = amountFilter
+	}
+
+	// Validating the timestamp values and creating the timestamp filter.
+	timestampFilter, err := getStartEndTimestampFilter(qValues.StartTime, qValues.EndTime)
+	if err != nil {
+		return nil, err
+	}
+	// If the timestamp filter has any entries, we put it inside the main filter.
+	if len(timestampFilter) > 0 {
+		filter["timestamp"] = timestampFilter
+	}
+
+	// If account ID filter is provided, we use it.
+	if qValues.AccountID != nil && *qValues.AccountID != "" {
+		filter["account_id"] = *qValues.AccountID
+	}
+
+	// If category filter is provided, we use it.
+	if qValues.Category != nil && *qValues.Category != "" {
+		filter["category"] = strings.ToLower(*qValues.Category)
+	}
+
+	// Full text search on the notes field.
+	if qValues.NotesHint != nil && *qValues.NotesHint != "" {
+		filter["$text"] = msi{"$search": *qValues.NotesHint}
+	}
+
+	return filter, nil
+}
@@ -1,10 +1,12 @@
 package handlers
 
 import (
+	"context"
 	"net/http"
 
 	"github.com/shivanshkc/ledgerkeep/src/database"
 	"github.com/shivanshkc/ledgerkeep/src/logger"
+	"github.com/shivanshkc/ledgerkeep/src/models"
 	"github.com/shivanshkc/ledgerkeep/src/utils/errutils"
 	"github.com/shivanshkc/ledgerkeep/src/utils/httputils"
 
@@ -14,23 +16,26 @@ import (
 
 // updateTransactionBody is the schema of the body of the UpdateTransaction API.
 type updateTransactionBody struct {
-	Amount    *float64 `json:"amount,omitempty"`
-	Timestamp *int64   `json:"timestamp,omitempty"`
-	AccountID *string  `json:"account_id,omitempty"`
-	Category  *string  `json:"category,omitempty"`
-	Notes     *string  `json:"notes,omitempty"`
+	Amount    *models.Money `json:"amount,omitempty"`
+	Timestamp *int64        `json:"timestamp,omitempty"`
+	AccountID *string       `json:"account_id,omitempty"`
+	Category  *string       `json:"category,omitempty"`
+	Notes     *string       `json:"notes,omitempty"`
+	// Splits replace all the split lines of the transaction. An empty list removes them.
+	Splits *[]*models.SplitDTO `json:"splits,omitempty"`
+	// Tags replace all the tags of the transaction. An empty list removes them.
+	Tags *[]string `json:"tags,omitempty"`
 }
 
 // UpdateTransactionHandler updates a transaction by its ID.
-func UpdateTransactionHandler(writer http.ResponseWriter, request *http.Request) {
+func (h *Handler) UpdateTransactionHandler(writer http.ResponseWriter, request *http.Request) {
 	ctx := request.Context()
 	log := logger.Get()
 
-	transactionIDStr := mux.Vars(request)["transaction_id"]
-	// Converting the transactionID to an ObjectID. This conversion also validates the transaction ID.
-	transactionID, err := primitive.ObjectIDFromHex(transactionIDStr)
-	if err != nil {
-		err = errutils.BadRequest().AddErrors(errInvalidTxID)
+	transactionID := mux.Vars(request)["transaction_id"]
+	// Validating transaction ID. Transaction IDs are always ObjectID hex strings.
+	if !primitive.IsValidObjectID(transactionID) {
+		err := errutils.BadRequest().AddErrors(errInvalidTxID)
 		httputils.WriteErrAndLog(ctx, writer, err, log)
 		return
 	}
@@ -46,32 +51,37 @@ func UpdateTransactionHandler(writer http.ResponseWriter, request *http.Request)
 	// Getting current transaction.
 	// This has to be done before validating user input because we may require some fields
 	// from the existing transaction to actually run the validation.
-	currentTransaction, err := database.GetTransaction(ctx, transactionID)
+	currentTransaction, err := h.transactions.GetTransaction(ctx, transactionID)
+	if err != nil {
+		httputils.WriteErrAndLog(ctx, writer, err, log)
+		return
+	}
+
+	// The categories are required to validate the user input.
+	categories, err := h.getCategorySet(ctx)
 	if err != nil {
 		httputils.WriteErrAndLog(ctx, writer, err, log)
 		return
 	}
 
 	// Validating user input and getting the updates map.
-	updates, err := prepareUpdateTransactionQuery(requestBody, currentTransaction)
+	// The legs of transfers have their own rules, as they are not income or expense.
+	var updates msi
+	if currentTransaction.TransferID == "" {
+		updates, err = prepareUpdateTransactionQuery(requestBody, currentTransaction, categories)
+	} else {
+		updates, err = prepareUpdateTransferLegQuery(requestBody, currentTransaction)
+	}
 	if err != nil {
 		err = errutils.BadRequest().AddErrors(err)
 		httputils.WriteErrAndLog(ctx, writer, err, log)
 		return
 	}
 
-	// If the user wants to update account_id, and it is different from the current account ID...
-	if newAccountID, exists := updates["account_id"]; exists && newAccountID != currentTransaction.AccountID {
-		// Checking account's existence.
-		accountExists, err := database.IsAccountExists(ctx, newAccountID.(string))
-		if err != nil {
-			httputils.WriteErrAndLog(ctx, writer, err, log)
-			return
-		}
-		if !accountExists {
-			httputils.WriteErrAndLog(ctx, writer, errutils.AccountNotFound(), log)
-			return
-		}
+	// Validating the updates against the currency of the account.
+	if err := h.checkTransactionUpdateCurrency(ctx, updates, currentTransaction); err != nil {
+		httputils.WriteErrAndLog(ctx, writer, err, log)
+		return
 	}
 
 	// If no updates were given, we stop execution.
@@ -81,8 +91,13 @@ func UpdateTransactionHandler(writer http.ResponseWriter, request *http.Request)
 		return
 	}
 
-	// Database call.
-	if err := database.UpdateTransaction(ctx, transactionID, updates); err != nil {
+	// Database call. Updating a leg of a transfer updates all its legs.
+	if currentTransaction.TransferID == "" {
+		err = h.transactions.UpdateTransaction(ctx, transactionID, updates)
+	} else {
+		err = h.updateTransferLegs(ctx, updates, currentTransaction)
+	}
+	if err != nil {
 		httputils.WriteErrAndLog(ctx, writer, err, log)
 		return
 	}
@@ -98,3 +113,110 @@ func UpdateTransactionHandler(writer http.ResponseWriter, request *http.Request)
 
 	httputils.WriteAndLog(ctx, writer, response, log)
 }
+
+// checkTransactionUpdateCurrency checks that the updated amounts are valid in the currency of the transaction's account,
+// and that the transaction is not moved to an account of a different currency.
+// It also checks the existence of the new account, if the account is updated.
+func (h *Handler) checkTransactionUpdateCurrency(ctx context.Context, updates msi, currentTx *models.TransactionDTO) error {
+	newAccountID, accountUpdated := updates["account_id"].(string)
+	newAmount, amountUpdated := updates["amount"].(models.Money)
+	newSplits, splitsUpdated := updates["splits"].([]*models.SplitDTO)
+	accountUpdated = accountUpdated && newAccountID != currentTx.AccountID
+
+	// If none of the amounts and the account change, the transaction stays valid.
+	if !accountUpdated && !amountUpdated && !splitsUpdated {
+		return nil
+	}
+
+	currentAccount, err := h.accounts.GetAccount(ctx, currentTx.AccountID)
+	if err != nil {
+		return err
+	}
+	currency := getAccountCurrency(currentAccount)
+
+	if accountUpdated {
+		// Checking the new account's existence.
+		newAccount, err := h.accounts.GetAccount(ctx, newAccountID)
+		if err != nil {
+			return err
+		}
+		if getAccountCurrency(newAccount) != currency {
+			return errutils.BadRequest().AddErrors(errAccountCurrencyMismatch)
+		}
+	}
+
+	if amountUpdated {
+		if err := checkAmountPrecision(newAmount, currency); err != nil {
+			return errutils.BadRequest().AddErrors(err)
+		}
+	}
+	if err := checkSplitsPrecision(newSplits, currency); err != nil {
+		return errutils.BadRequest().AddErrors(err)
+	}
+
+	return nil
+}
+
+// updateTransferLegs applies the updates of a leg of a transfer, and keeps the other legs in agreement with it.
+//
+// The other legs get the same timestamp and notes. They get the opposite amount too, but only if they are in the same
+// currency, because the amounts of a transfer between different currencies are independent of each other.
+func (h *Handler) updateTransferLegs(ctx context.Context, updates msi, currentTx *models.TransactionDTO) error {
+	legs, _, err := h.transactions.ListTransactions(ctx, &database.ListTransactionsParams{
+		Filter:       msi{"transfer_id": currentTx.TransferID},
+		SortField:    "timestamp",
+		SortOrder:    1,
+		ExcludeCount: true,
+	})
+	if err != nil {
+		return err
+	}
+
+	newAmount, amountUpdated := updates["amount"].(models.Money)
+	newAccountID, accountUpdated := updates["account_id"].(string)
+
+	var currency string
+	if amountUpdated {
+		account, err := h.accounts.GetAccount(ctx, currentTx.AccountID)
+		if err != nil {
+			return err
+		}
+		currency = getAccountCurrency(account)
+	}
+
+	legUpdates := map[string]map[string]interface{}{currentTx.ID: updates}
+	for _, leg := range legs {
+		if leg.ID == currentTx.ID {
+			continue
+		}
+
+		// The money cannot be moved into the same account that it leaves.
+		if accountUpdated && newAccountID == leg.AccountID {
+			return errutils.BadRequest().AddErrors(errTransferSameAccount)
+		}
+
+		otherUpdates := msi{}
+		if timestamp, exists := updates["timestamp"]; exists {
+			otherUpdates["timestamp"] = timestamp
+		}
+		if notes, exists := updates["notes"]; exists {
+			otherUpdates["notes"] = notes
+		}
+
+		if amountUpdated {
+			account, err := h.accounts.GetAccount(ctx, leg.AccountID)
+			if err != nil {
+				return err
+			}
+			if getAccountCurrency(account) == currency {
+				otherUpdates["amount"] = -newAmount
+			}
+		}
+
+		if len(otherUpdates) > 0 {
+			legUpdates[leg.ID] = otherUpdates
+		}
+	}
+
+	return h.transactions.UpdateTransactions(ctx, legUpdates)
+}
@@ -0,0 +1,93 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/shivanshkc/ledgerkeep/src/database"
+	"github.com/shivanshkc/ledgerkeep/src/logger"
+	"github.com/shivanshkc/ledgerkeep/src/models"
+	"github.com/shivanshkc/ledgerkeep/src/utils/authutils"
+	"github.com/shivanshkc/ledgerkeep/src/utils/ctxutils"
+	"github.com/shivanshkc/ledgerkeep/src/utils/errutils"
+	"github.com/shivanshkc/ledgerkeep/src/utils/httputils"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// createUserBody is the schema of the body of the CreateUser API.
+type createUserBody struct {
+	ID       string `json:"id"`
+	Password string `json:"password"`
+	IsAdmin  bool   `json:"is_admin"`
+}
+
+// CreateUserHandler creates a new user with a ledger of its own. Only the admins can create users.
+func (h *Handler) CreateUserHandler(writer http.ResponseWriter, request *http.Request) {
+	ctx := request.Context()
+	log := logger.Get()
+
+	if err := h.requireAdmin(ctx); err != nil {
+		httputils.WriteErrAndLog(ctx, writer, err, log)
+		return
+	}
+
+	// Decoding the request.
+	var requestBody *createUserBody
+	if err := httputils.UnmarshalBody(request, &requestBody); err != nil {
+		err = errutils.BadRequest().AddErrors(err)
+		httputils.WriteErrAndLog(ctx, writer, err, log)
+		return
+	}
+
+	// Validating user ID.
+	if !userIDRegexp.MatchString(requestBody.ID) {
+		err := errutils.BadRequest().AddErrors(errInvalidUserID)
+		httputils.WriteErrAndLog(ctx, writer, err, log)
+		return
+	}
+
+	// Validating password.
+	if !isValidPassword(requestBody.Password) {
+		err := errutils.BadRequest().AddErrors(errInvalidPassword)
+		httputils.WriteErrAndLog(ctx, writer, err, log)
+		return
+	}
+
+	passwordHash, err := authutils.HashPassword(requestBody.Password)
+	if err != nil {
+		httputils.WriteErrAndLog(ctx, writer, err, log)
+		return
+	}
+
+	// Every user gets a new tenant, so its ledger starts out empty.
+	user := &models.UserDTO{
+		ID:           requestBody.ID,
+		PasswordHash: passwordHash,
+		IsAdmin:      requestBody.IsAdmin,
+		TenantID:     primitive.NewObjectID().Hex(),
+		CreatedAt:    time.Now().Unix(),
+	}
+
+	// Database calls. The categories are required to validate any transaction of the new ledger.
+	if err := h.users.InsertUser(ctx, user); err != nil {
+		httputils.WriteErrAndLog(ctx, writer, err, log)
+		return
+	}
+	if err := database.SeedDefaultCategories(ctxutils.PutTenantID(ctx, user.TenantID), h.categories); err != nil {
+		httputils.WriteErrAndLog(ctx, writer, err, log)
+		return
+	}
+
+	// Final HTTP response.
+	response := &httputils.ResponseDTO{
+		Status: http.StatusCreated,
+		Body: &httputils.ResponseBodyDTO{
+			StatusCode: http.StatusCreated,
+			CustomCode: "USER_CREATED",
+			Data:       user,
+		},
+	}
+
+	httputils.WriteAndLog(ctx, writer, response, log)
+}
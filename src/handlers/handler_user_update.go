@@ -0,0 +1,68 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/shivanshkc/ledgerkeep/src/logger"
+	"github.com/shivanshkc/ledgerkeep/src/utils/authutils"
+	"github.com/shivanshkc/ledgerkeep/src/utils/ctxutils"
+	"github.com/shivanshkc/ledgerkeep/src/utils/errutils"
+	"github.com/shivanshkc/ledgerkeep/src/utils/httputils"
+)
+
+// updateCurrentUserBody is the schema of the body of the UpdateCurrentUser API.
+type updateCurrentUserBody struct {
+	Password *string `json:"password,omitempty"`
+}
+
+// UpdateCurrentUserHandler updates the user that the request was authenticated as. Only the password can be changed.
+func (h *Handler) UpdateCurrentUserHandler(writer http.ResponseWriter, request *http.Request) {
+	ctx := request.Context()
+	log := logger.Get()
+
+	// Decoding the request.
+	var requestBody *updateCurrentUserBody
+	if err := httputils.UnmarshalBody(request, &requestBody); err != nil {
+		err = errutils.BadRequest().AddErrors(err)
+		httputils.WriteErrAndLog(ctx, writer, err, log)
+		return
+	}
+
+	// If no updates were given, we stop execution.
+	if requestBody.Password == nil {
+		err := errutils.BadRequest().AddErrors(errEmptyUpdate)
+		httputils.WriteErrAndLog(ctx, writer, err, log)
+		return
+	}
+
+	// Validating password.
+	if !isValidPassword(*requestBody.Password) {
+		err := errutils.BadRequest().AddErrors(errInvalidPassword)
+		httputils.WriteErrAndLog(ctx, writer, err, log)
+		return
+	}
+
+	passwordHash, err := authutils.HashPassword(*requestBody.Password)
+	if err != nil {
+		httputils.WriteErrAndLog(ctx, writer, err, log)
+		return
+	}
+
+	// Database call.
+	updates := map[string]interface{}{"password_hash": passwordHash}
+	if err := h.users.UpdateUser(ctx, ctxutils.GetUserID(ctx), updates); err != nil {
+		httputils.WriteErrAndLog(ctx, writer, err, log)
+		return
+	}
+
+	// Final HTTP response.
+	response := &httputils.ResponseDTO{
+		Status: http.StatusOK,
+		Body: &httputils.ResponseBodyDTO{
+			StatusCode: http.StatusOK,
+			CustomCode: "USER_UPDATED",
+		},
+	}
+
+	httputils.WriteAndLog(ctx, writer, response, log)
+}
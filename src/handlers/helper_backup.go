@@ -0,0 +1,313 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/shivanshkc/ledgerkeep/src/backups"
+	"github.com/shivanshkc/ledgerkeep/src/database"
+	"github.com/shivanshkc/ledgerkeep/src/models"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// maxBackupFileSize is the maximum size of an uploaded backup archive in bytes.
+const maxBackupFileSize = 256 << 20
+
+// getLedgerData fetches all the data of the ledger, other than the transactions, from the database.
+func (h *Handler) getLedgerData(ctx context.Context) (*models.LedgerData, error) {
+	data := &models.LedgerData{}
+	var err error
+
+	if data.Accounts, err = h.accounts.ListAccounts(ctx); err != nil {
+		return nil, err
+	}
+	if data.Categories, err = h.categories.ListCategories(ctx); err != nil {
+		return nil, err
+	}
+	if data.ExchangeRates, err = h.exchangeRates.ListExchangeRates(ctx, "", ""); err != nil {
+		return nil, err
+	}
+	if data.BudgetPlans, err = h.budgetPlans.ListBudgetPlans(ctx); err != nil {
+		return nil, err
+	}
+	if data.RecurringTemplates, err = h.recurring.ListRecurringTemplates(ctx); err != nil {
+		return nil, err
+	}
+	if data.ImportProfiles, err = h.imports.ListImportProfiles(ctx); err != nil {
+		return nil, err
+	}
+	if data.Rules, err = h.rules.ListRules(ctx); err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
+// writeBackup writes the data, and then the transactions of the cursor, using the backups.Writer.
+func (h *Handler) writeBackup(ctx context.Context, writer *backups.Writer, data *models.LedgerData,
+	cursor database.TransactionCursor) error {
+	sections := map[string][]interface{}{}
+	for _, account := range data.Accounts {
+		sections[backups.SectionAccounts] = append(sections[backups.SectionAccounts], account)
+	}
+	for _, category := range data.Categories {
+		sections[backups.SectionCategories] = append(sections[backups.SectionCategories], category)
+	}
+	for _, rate := range data.ExchangeRates {
+		sections[backups.SectionExchangeRates] = append(sections[backups.SectionExchangeRates], rate)
+	}
+	for _, plan := range data.BudgetPlans {
+		sections[backups.SectionBudgetPlans] = append(sections[backups.SectionBudgetPlans], plan)
+	}
+	for _, template := range data.RecurringTemplates {
+		sections[backups.SectionRecurringTemplates] = append(sections[backups.SectionRecurringTemplates], template)
+	}
+	for _, profile := range data.ImportProfiles {
+		sections[backups.SectionImportProfiles] = append(sections[backups.SectionImportProfiles], profile)
+	}
+	for _, rule := range data.Rules {
+		sections[backups.SectionRules] = append(sections[backups.SectionRules], rule)
+	}
+
+	for _, section := range backups.Sections {
+		if err := writer.Section(section); err != nil {
+			return err
+		}
+		for _, record := range sections[section] {
+			if err := writer.Write(record); err != nil {
+				return err
+			}
+		}
+	}
+
+	// The transactions are the last section, which was started by the loop above.
+	for cursor.Next(ctx) {
+		transaction := cursor.Transaction()
+		// The cursor does not load the split lines, so the transactions with splits are fetched along with them.
+		if transaction.Category == categorySplit {
+			withSplits, err := h.transactions.GetTransaction(ctx, transaction.ID)
+			if err != nil {
+				return err
+			}
+			transaction = withSplits
+		}
+
+		if err := writer.Write(transaction); err != nil {
+			return err
+		}
+	}
+	if err := cursor.Err(); err != nil {
+		return err
+	}
+
+	return writer.Close()
+}
+
+// checkLedgerData validates all the data of a backup, and normalizes it just like the data that is created through the
+// other APIs. The references between the sections, like the accounts of the transactions, are validated too.
+//
+// It returns all the errors together, like readStatementCSV. The errors start with the sections and the positions of
+// their records.
+func checkLedgerData(data *models.LedgerData) []error {
+	var errs []error
+	addErr := func(section string, idx int, err error) {
+		errs = append(errs, fmt.Errorf("%s record %d: %w", section, idx+1, err))
+	}
+
+	accounts := map[string]*models.AccountDTO{}
+	for idx, account := range data.Accounts {
+		if err := checkBackupAccount(account); err != nil {
+			addErr(backups.SectionAccounts, idx, err)
+		} else if _, exists := accounts[account.ID]; exists {
+			addErr(backups.SectionAccounts, idx, errDuplicateBackupID)
+		} else {
+			accounts[account.ID] = account
+		}
+	}
+
+	categories := categorySet{}
+	for idx, category := range data.Categories {
+		if err := checkNewCategory(category); err != nil {
+			addErr(backups.SectionCategories, idx, err)
+		} else if _, exists := categories[category.ID]; exists {
+			addErr(backups.SectionCategories, idx, errDuplicateBackupID)
+		} else {
+			categories[category.ID] = category
+		}
+	}
+
+	// The IDs of all the other sections are ObjectIDs, which are unique across the sections.
+	ids := map[string]bool{}
+	checkID := func(id string) error {
+		if !primitive.IsValidObjectID(id) {
+			return errInvalidBackupID
+		}
+		if ids[id] {
+			return errDuplicateBackupID
+		}
+		ids[id] = true
+		return nil
+	}
+
+	// An exchange rate is unique for its currency pair and timestamp.
+	ratePairs := map[string]bool{}
+	for idx, rate := range data.ExchangeRates {
+		err := checkID(rate.ID)
+		if err == nil {
+			err = prepareExchangeRate(rate)
+		}
+		if err == nil {
+			key := fmt.Sprintf("%s/%s/%d", rate.Base, rate.Quote, rate.Timestamp)
+			if ratePairs[key] {
+				err = errDuplicateExchangeRate
+			}
+			ratePairs[key] = true
+		}
+		if err != nil {
+			addErr(backups.SectionExchangeRates, idx, err)
+		}
+	}
+
+	var plans []*models.BudgetPlanDTO
+	for idx, plan := range data.BudgetPlans {
+		err := checkID(plan.ID)
+		if err == nil {
+			err = prepareBudgetPlan(plan)
+		}
+		if err == nil {
+			err = checkBudgetPlanOverlap(plan, plans)
+		}
+		if err != nil {
+			addErr(backups.SectionBudgetPlans, idx, err)
+			continue
+		}
+		plans = append(plans, plan)
+	}
+
+	for idx, template := range data.RecurringTemplates {
+		// The next run is kept as it is, so the occurrences that were materialized already are not repeated.
+		nextRun := template.NextRun
+		err := checkID(template.ID)
+		if err == nil {
+			err = prepareRecurringTemplate(template, categories)
+		}
+		if _, exists := accounts[template.AccountID]; err == nil && !exists {
+			err = errBackupAccountNotFound
+		}
+		if err != nil {
+			addErr(backups.SectionRecurringTemplates, idx, err)
+		}
+		template.NextRun = nextRun
+	}
+
+	for idx, profile := range data.ImportProfiles {
+		err := checkID(profile.ID)
+		if err == nil {
+			err = prepareImportProfile(profile, categories)
+		}
+		if _, exists := accounts[profile.AccountID]; err == nil && !exists {
+			err = errBackupAccountNotFound
+		}
+		if err != nil {
+			addErr(backups.SectionImportProfiles, idx, err)
+		}
+	}
+
+	for idx, rule := range data.Rules {
+		err := checkID(rule.ID)
+		if err == nil {
+			err = prepareRule(rule, categories)
+		}
+		if _, exists := accounts[rule.AccountID]; err == nil && rule.AccountID != "" && !exists {
+			err = errBackupAccountNotFound
+		}
+		if err != nil {
+			addErr(backups.SectionRules, idx, err)
+		}
+	}
+
+	for idx, transaction := range data.Transactions {
+		err := checkID(transaction.ID)
+		if err == nil {
+			err = checkBackupTransaction(transaction, accounts, categories)
+		}
+		if err != nil {
+			addErr(backups.SectionTransactions, idx, err)
+		}
+	}
+
+	return errs
+}
+
+// checkBackupAccount validates an account of a backup, and normalizes its currency.
+func checkBackupAccount(account *models.AccountDTO) error {
+	if !accountIDRegexp.MatchString(account.ID) {
+		return errInvalidAccountID
+	}
+	if !accountNameRegexp.MatchString(account.Name) {
+		return errInvalidAccountName
+	}
+
+	// The accounts without a currency are in the default currency.
+	if account.Currency != "" {
+		currency, err := parseCurrency(account.Currency)
+		if err != nil {
+			return err
+		}
+		account.Currency = currency
+	}
+	return nil
+}
+
+// checkBackupTransaction validates a transaction of a backup, and normalizes its category and tags. The transaction
+// should belong to one of the provided accounts, and its categories should be in the provided categorySet.
+func checkBackupTransaction(transaction *models.TransactionDTO, accounts map[string]*models.AccountDTO,
+	categories categorySet) error {
+	account, exists := accounts[transaction.AccountID]
+	if !exists {
+		return errBackupAccountNotFound
+	}
+	if transaction.Amount == 0 {
+		return errInvalidTxAmount
+	}
+	if transaction.Timestamp < 0 {
+		return errInvalidTxTimestamp
+	}
+
+	tags, err := prepareTags(transaction.Tags)
+	if err != nil {
+		return err
+	}
+	transaction.Tags = tags
+
+	switch category := strings.ToLower(transaction.Category); {
+	case category == categoryTransfer:
+		if !primitive.IsValidObjectID(transaction.TransferID) {
+			return errInvalidBackupTransferID
+		}
+		if len(transaction.Splits) > 0 {
+			return errTransferSplits
+		}
+	case transaction.TransferID != "":
+		return errInvalidBackupTransferID
+	case category == categorySplit:
+		splits, err := prepareSplits(transaction.Splits, transaction.Amount, categories)
+		if err != nil {
+			return err
+		}
+		transaction.Splits = splits
+	case len(transaction.Splits) > 0:
+		return errSplitCategory
+	case !categories.allows(category, transaction.Amount):
+		return errInvalidTxCategory
+	}
+	transaction.Category = strings.ToLower(transaction.Category)
+
+	currency := getAccountCurrency(account)
+	if err := checkAmountPrecision(transaction.Amount, currency); err != nil {
+		return err
+	}
+	return checkSplitsPrecision(transaction.Splits, currency)
+}
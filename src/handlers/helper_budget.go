@@ -0,0 +1,105 @@
+package handlers
+
+import (
+	"strings"
+
+	"github.com/shivanshkc/ledgerkeep/src/configs"
+	"github.com/shivanshkc/ledgerkeep/src/models"
+)
+
+// getDefaultBudgetAllocations provides the allocations that are used when no budget plan is in effect.
+func getDefaultBudgetAllocations() []*models.BudgetAllocationDTO {
+	percent := func(value int64) *int64 { return &value }
+
+	return []*models.BudgetAllocationDTO{
+		{BudgetGroup: models.BudgetGroupEssentials, Percent: percent(essentialsContrib)},
+		{BudgetGroup: models.BudgetGroupInvestments, Percent: percent(investmentsContrib)},
+		{BudgetGroup: models.BudgetGroupSavings, Percent: percent(savingsContrib)},
+		{BudgetGroup: models.BudgetGroupLuxury, Percent: percent(luxuryContrib)},
+		{BudgetGroup: models.BudgetGroupIgnorable, Percent: percent(ignorableContrib)},
+	}
+}
+
+// prepareBudgetPlan validates the budget plan and normalizes its currency code and budget groups.
+func prepareBudgetPlan(plan *models.BudgetPlanDTO) error {
+	if !budgetPlanNameRegexp.MatchString(plan.Name) {
+		return errInvalidBudgetPlanName
+	}
+
+	// Budget plans are in the default currency if they do not specify one.
+	if plan.Currency == "" {
+		plan.Currency = configs.Get().Currency.Default
+	}
+	currency, err := parseCurrency(plan.Currency)
+	if err != nil {
+		return err
+	}
+	plan.Currency = currency
+
+	if plan.ValidFrom < 0 || (plan.ValidUntil != 0 && plan.ValidUntil <= plan.ValidFrom) {
+		return errInvalidBudgetPlanValidity
+	}
+
+	var totalPercent int64
+	seenGroups := map[string]bool{}
+
+	for _, allocation := range plan.Allocations {
+		if allocation == nil || !stringPresentCaseInsensitive(allocation.BudgetGroup, allowedAllocationGroups) {
+			return errInvalidAllocationGroup
+		}
+		allocation.BudgetGroup = strings.ToLower(allocation.BudgetGroup)
+
+		// Every budget group can only have one expected value.
+		if seenGroups[allocation.BudgetGroup] {
+			return errDuplicateAllocationGroup
+		}
+		seenGroups[allocation.BudgetGroup] = true
+
+		switch {
+		case allocation.Percent != nil && allocation.Amount == nil:
+			if *allocation.Percent < 0 || *allocation.Percent > 100 {
+				return errInvalidAllocationPercent
+			}
+			totalPercent += *allocation.Percent
+		case allocation.Amount != nil && allocation.Percent == nil:
+			if *allocation.Amount < 0 {
+				return errInvalidAllocationAmount
+			}
+			if err := checkAmountPrecision(*allocation.Amount, plan.Currency); err != nil {
+				return err
+			}
+		default:
+			return errInvalidAllocation
+		}
+	}
+
+	// The income cannot be allocated more than once.
+	if totalPercent > 100 {
+		return errAllocationPercentTotal
+	}
+
+	return nil
+}
+
+// checkBudgetPlanOverlap checks that the validity period of the new budget plan does not overlap with that of any of
+// the existing plans, so there is never more than one plan in effect.
+func checkBudgetPlanOverlap(plan *models.BudgetPlanDTO, existingPlans []*models.BudgetPlanDTO) error {
+	for _, existing := range existingPlans {
+		startsBeforeExistingEnds := existing.ValidUntil == 0 || plan.ValidFrom < existing.ValidUntil
+		endsAfterExistingStarts := plan.ValidUntil == 0 || plan.ValidUntil > existing.ValidFrom
+		if startsBeforeExistingEnds && endsAfterExistingStarts {
+			return errBudgetPlanOverlap
+		}
+	}
+	return nil
+}
+
+// findEffectiveBudgetPlan finds the budget plan that is in effect at the given timestamp. It is nil if there is none.
+func findEffectiveBudgetPlan(plans []*models.BudgetPlanDTO, timestamp int64) *models.BudgetPlanDTO {
+	for _, plan := range plans {
+		if plan.ValidFrom <= timestamp && (plan.ValidUntil == 0 || timestamp < plan.ValidUntil) {
+			return plan
+		}
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"testing"
+
+	"github.com/shivanshkc/ledgerkeep/src/models"
+)
+
+func TestPrepareBudgetPlan(t *testing.T) {
+	percent := func(value int64) *int64 { return &value }
+	amount := func(value models.Money) *models.Money { return &value }
+
+	plan := &models.BudgetPlanDTO{
+		Name: "Plan 2022", Currency: "usd", ValidFrom: 100, ValidUntil: 200,
+		Allocations: []*models.BudgetAllocationDTO{
+			{BudgetGroup: "Essentials", Percent: percent(50)},
+			{BudgetGroup: "luxury", Amount: amount(1000000)},
+		},
+	}
+	if err := prepareBudgetPlan(plan); err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+	if plan.Currency != "USD" || plan.Allocations[0].BudgetGroup != "essentials" {
+		t.Fatalf("unexpected plan: %+v", plan)
+	}
+
+	invalidPlans := []*models.BudgetPlanDTO{
+		{Name: "Plan", Currency: "USD", ValidFrom: 200, ValidUntil: 100},
+		{Name: "Plan", Currency: "USD", Allocations: []*models.BudgetAllocationDTO{{BudgetGroup: "income", Percent: percent(10)}}},
+		{Name: "Plan", Currency: "USD", Allocations: []*models.BudgetAllocationDTO{{BudgetGroup: "luxury"}}},
+		{Name: "Plan", Currency: "USD", Allocations: []*models.BudgetAllocationDTO{
+			{BudgetGroup: "luxury", Percent: percent(10), Amount: amount(10000)},
+		}},
+		{Name: "Plan", Currency: "USD", Allocations: []*models.BudgetAllocationDTO{
+			{BudgetGroup: "luxury", Percent: percent(60)}, {BudgetGroup: "savings", Percent: percent(60)},
+		}},
+		{Name: "Plan", Currency: "USD", Allocations: []*models.BudgetAllocationDTO{
+			{BudgetGroup: "luxury", Percent: percent(10)}, {BudgetGroup: "Luxury", Percent: percent(10)},
+		}},
+		{Name: "Plan", Currency: "JPY", Allocations: []*models.BudgetAllocationDTO{
+			{BudgetGroup: "luxury", Amount: amount(5000)},
+		}},
+	}
+	for idx, invalidPlan := range invalidPlans {
+		if err := prepareBudgetPlan(invalidPlan); err == nil {
+			t.Errorf("expected an error for plan at index %d", idx)
+		}
+	}
+}
+
+func TestBudgetPlanValidity(t *testing.T) {
+	plans := []*models.BudgetPlanDTO{
+		{ID: "first", ValidFrom: 100, ValidUntil: 200},
+		{ID: "second", ValidFrom: 200, ValidUntil: 0},
+	}
+
+	testCases := []struct {
+		timestamp  int64
+		expectedID string
+	}{
+		{timestamp: 50, expectedID: ""},
+		{timestamp: 100, expectedID: "first"},
+		{timestamp: 199, expectedID: "first"},
+		{timestamp: 200, expectedID: "second"},
+		{timestamp: 5000, expectedID: "second"},
+	}
+	for _, tc := range testCases {
+		plan := findEffectiveBudgetPlan(plans, tc.timestamp)
+		if (plan == nil && tc.expectedID != "") || (plan != nil && plan.ID != tc.expectedID) {
+			t.Errorf("expected plan %q at %d, got: %+v", tc.expectedID, tc.timestamp, plan)
+		}
+	}
+
+	if err := checkBudgetPlanOverlap(&models.BudgetPlanDTO{ValidFrom: 0, ValidUntil: 100}, plans); err != nil {
+		t.Errorf("unexpected error for an adjacent plan: %+v", err)
+	}
+	if err := checkBudgetPlanOverlap(&models.BudgetPlanDTO{ValidFrom: 150, ValidUntil: 160}, plans); err == nil {
+		t.Errorf("expected an error for a plan within another plan")
+	}
+	if err := checkBudgetPlanOverlap(&models.BudgetPlanDTO{ValidFrom: 0, ValidUntil: 0}, plans); err == nil {
+		t.Errorf("expected an error for a plan without an end")
+	}
+}
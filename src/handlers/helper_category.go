@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"context"
+	"strings"
+
+	"github.com/shivanshkc/ledgerkeep/src/models"
+)
+
+// categorySet holds the categories of the ledger, keyed by their IDs.
+// It is used to validate the categories of transactions and to place them in the budget.
+type categorySet map[string]*models.CategoryDTO
+
+// getCategorySet fetches all categories from the database and provides them as a categorySet.
+func (h *Handler) getCategorySet(ctx context.Context) (categorySet, error) {
+	categories, err := h.categories.ListCategories(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	set := make(categorySet, len(categories))
+	for _, category := range categories {
+		set[category.ID] = category
+	}
+	return set, nil
+}
+
+// allows checks if the category exists and if its kind allows the sign of the given amount.
+// The category is matched case-insensitively.
+func (c categorySet) allows(categoryID string, amount models.Money) bool {
+	category, exists := c[strings.ToLower(categoryID)]
+	if !exists {
+		return false
+	}
+
+	switch category.Kind {
+	case models.CategoryKindBoth:
+		return true
+	case models.CategoryKindCredit:
+		return amount > 0
+	case models.CategoryKindDebit:
+		return amount < 0
+	default:
+		return false
+	}
+}
+
+// budgetGroup provides the budget group of the category. It is empty if the category does not exist.
+func (c categorySet) budgetGroup(categoryID string) string {
+	if category, exists := c[categoryID]; exists {
+		return category.BudgetGroup
+	}
+	return ""
+}
+
+// checkNewCategory validates a category that is about to be created, and normalizes its fields.
+func checkNewCategory(category *models.CategoryDTO) error {
+	category.ID = strings.ToLower(category.ID)
+	if !categoryIDRegexp.MatchString(category.ID) {
+		return errInvalidCategoryID
+	}
+
+	// The reserved categories are assigned by the application only.
+	if stringPresentCaseInsensitive(category.ID, reservedCategories) {
+		return errReservedCategoryID
+	}
+
+	if !categoryNameRegexp.MatchString(category.Name) {
+		return errInvalidCategoryName
+	}
+	if !stringPresentCaseInsensitive(category.Kind, allowedCategoryKinds) {
+		return errInvalidCategoryKind
+	}
+	if !stringPresentCaseInsensitive(category.BudgetGroup, allowedBudgetGroups) {
+		return errInvalidBudgetGroup
+	}
+
+	category.Kind = strings.ToLower(category.Kind)
+	category.BudgetGroup = strings.ToLower(category.BudgetGroup)
+	return nil
+}
@@ -0,0 +1,358 @@
+package handlers
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shivanshkc/ledgerkeep/src/configs"
+	"github.com/shivanshkc/ledgerkeep/src/models"
+	"github.com/shivanshkc/ledgerkeep/src/utils/errutils"
+
+	"golang.org/x/sync/errgroup"
+)
+
+// currencyMinorUnits maps the ISO 4217 codes of all active currencies to the number of their decimal places.
+var currencyMinorUnits = map[string]int{
+	"AED": 2, "AFN": 2, "ALL": 2, "AMD": 2, "ANG": 2, "AOA": 2, "ARS": 2, "AUD": 2, "AWG": 2, "AZN": 2,
+	"BAM": 2, "BBD": 2, "BDT": 2, "BGN": 2, "BHD": 3, "BIF": 0, "BMD": 2, "BND": 2, "BOB": 2, "BOV": 2,
+	"BRL": 2, "BSD": 2, "BTN": 2, "BWP": 2, "BYN": 2, "BZD": 2,
+	"CAD": 2, "CDF": 2, "CHE": 2, "CHF": 2, "CHW": 2, "CLF": 4, "CLP": 0, "CNY": 2, "COP": 2, "COU": 2,
+	"CRC": 2, "CUC": 2, "CUP": 2, "CVE": 2, "CZK": 2,
+	"DJF": 0, "DKK": 2, "DOP": 2, "DZD": 2,
+	"EGP": 2, "ERN": 2, "ETB": 2, "EUR": 2,
+	"FJD": 2, "FKP": 2,
+	"GBP": 2, "GEL": 2, "GHS": 2, "GIP": 2, "GMD": 2, "GNF": 0, "GTQ": 2, "GYD": 2,
+	"HKD": 2, "HNL": 2, "HRK": 2, "HTG": 2, "HUF": 2,
+	"IDR": 2, "ILS": 2, "INR": 2, "IQD": 3, "IRR": 2, "ISK": 0,
+	"JMD": 2, "JOD": 3, "JPY": 0,
+	"KES": 2, "KGS": 2, "KHR": 2, "KMF": 0, "KPW": 2, "KRW": 0, "KWD": 3, "KYD": 2, "KZT": 2,
+	"LAK": 2, "LBP": 2, "LKR": 2, "LRD": 2, "LSL": 2, "LYD": 3,
+	"MAD": 2, "MDL": 2, "MGA": 2, "MKD": 2, "MMK": 2, "MNT": 2, "MOP": 2, "MRU": 2, "MUR": 2, "MVR": 2,
+	"MWK": 2, "MXN": 2, "MXV": 2, "MYR": 2, "MZN": 2,
+	"NAD": 2, "NGN": 2, "NIO": 2, "NOK": 2, "NPR": 2, "NZD": 2,
+	"OMR": 3,
+	"PAB": 2, "PEN": 2, "PGK": 2, "PHP": 2, "PKR": 2, "PLN": 2, "PYG": 0,
+	"QAR": 2,
+	"RON": 2, "RSD": 2, "RUB": 2, "RWF": 0,
+	"SAR": 2, "SBD": 2, "SCR": 2, "SDG": 2, "SEK": 2, "SGD": 2, "SHP": 2, "SLE": 2, "SLL": 2, "SOS": 2,
+	"SRD": 2, "SSP": 2, "STN": 2, "SVC": 2, "SYP": 2, "SZL": 2,
+	"THB": 2, "TJS": 2, "TMT": 2, "TND": 3, "TOP": 2, "TRY": 2, "TTD": 2, "TWD": 2, "TZS": 2,
+	"UAH": 2, "UGX": 0, "USD": 2, "USN": 2, "UYI": 0, "UYU": 2, "UYW": 4, "UZS": 2,
+	"VED": 2, "VES": 2, "VND": 0, "VUV": 0,
+	"WST": 2,
+	"XAF": 0, "XCD": 2, "XOF": 0, "XPF": 0,
+	"YER": 2,
+	"ZAR": 2, "ZMW": 2, "ZWL": 2,
+}
+
+// parseCurrency validates the ISO 4217 currency code and provides it in upper case.
+func parseCurrency(currency string) (string, error) {
+	currency = strings.ToUpper(strings.TrimSpace(currency))
+	if _, exists := currencyMinorUnits[currency]; !exists {
+		return "", errInvalidCurrency
+	}
+	return currency, nil
+}
+
+// parseReportingCurrency parses the optional reporting currency of a stats API.
+// If it is not provided, the default currency is used.
+func parseReportingCurrency(currency *string) (string, error) {
+	if currency == nil || *currency == "" {
+		return configs.Get().Currency.Default, nil
+	}
+	return parseCurrency(*currency)
+}
+
+// getAccountCurrency provides the currency of the account.
+// Accounts that were created before the introduction of currencies are in the default currency.
+func getAccountCurrency(account *models.AccountDTO) string {
+	if account.Currency == "" {
+		return configs.Get().Currency.Default
+	}
+	return account.Currency
+}
+
+// accountCurrencyMap is a map of account IDs to their currencies.
+type accountCurrencyMap map[string]string
+
+// newAccountCurrencyMap creates a new accountCurrencyMap out of the accounts.
+func newAccountCurrencyMap(accounts []*models.AccountDTO) accountCurrencyMap {
+	currencyMap := make(accountCurrencyMap, len(accounts))
+	for _, account := range accounts {
+		currencyMap[account.ID] = getAccountCurrency(account)
+	}
+	return currencyMap
+}
+
+// get provides the currency of the account. Unknown accounts are taken to be in the default currency.
+func (a accountCurrencyMap) get(accountID string) string {
+	if currency, exists := a[accountID]; exists {
+		return currency
+	}
+	return configs.Get().Currency.Default
+}
+
+// getCurrencyConversionData fetches the account currencies and the exchange rates,
+// which are required to convert amounts into a reporting currency.
+func (h *Handler) getCurrencyConversionData(ctx context.Context) (accountCurrencyMap, *exchangeRateBook, error) {
+	errs, errCtx := errgroup.WithContext(ctx)
+	// Creating channels because we intend to make 2 database calls in parallel.
+	accountsChan := make(chan []*models.AccountDTO, 1)
+	ratesChan := make(chan []*models.ExchangeRateDTO, 1)
+
+	// Call 1: Fetching account list.
+	errs.Go(func() error {
+		defer close(accountsChan)
+		accounts, err := h.accounts.ListAccounts(errCtx)
+		if err != nil {
+			return fmt.Errorf("failure in accounts.ListAccounts: %w", err)
+		}
+		accountsChan <- accounts
+		return nil
+	})
+
+	// Call 2: Fetching all exchange rates.
+	errs.Go(func() error {
+		defer close(ratesChan)
+		rates, err := h.exchangeRates.ListExchangeRates(errCtx, "", "")
+		if err != nil {
+			return fmt.Errorf("failure in exchangeRates.ListExchangeRates: %w", err)
+		}
+		ratesChan <- rates
+		return nil
+	})
+
+	// Checking for errors.
+	if err := errs.Wait(); err != nil {
+		return nil, nil, err
+	}
+
+	return newAccountCurrencyMap(<-accountsChan), newExchangeRateBook(<-ratesChan), nil
+}
+
+// checkAmountPrecision checks that the amount does not have more decimal places than its currency allows.
+func checkAmountPrecision(amount models.Money, currency string) error {
+	minorUnits, exists := currencyMinorUnits[currency]
+	if !exists {
+		return errInvalidCurrency
+	}
+
+	// The smallest amount of the currency, in Money units.
+	smallestAmount := int64(1)
+	for i := minorUnits; i < models.MoneyDecimalPlaces; i++ {
+		smallestAmount *= 10
+	}
+
+	if int64(amount)%smallestAmount != 0 {
+		return fmt.Errorf("amount should not have more than %d decimal places in %s", minorUnits, currency)
+	}
+	return nil
+}
+
+// checkSplitsPrecision checks that the amounts of all the split lines are valid in the given currency.
+func checkSplitsPrecision(splits []*models.SplitDTO, currency string) error {
+	for _, split := range splits {
+		if err := checkAmountPrecision(split.Amount, currency); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// prepareExchangeRate validates the exchange rate and normalizes its currency codes.
+func prepareExchangeRate(rate *models.ExchangeRateDTO) error {
+	base, err := parseCurrency(rate.Base)
+	if err != nil {
+		return err
+	}
+	quote, err := parseCurrency(rate.Quote)
+	if err != nil {
+		return err
+	}
+	if base == quote {
+		return errInvalidExchangeRatePair
+	}
+	if rate.Rate.Rat() == nil {
+		return errInvalidExchangeRate
+	}
+
+	rate.Base, rate.Quote = base, quote
+	return nil
+}
+
+// readExchangeRatesCSV reads exchange rates from a CSV file with a header row.
+// The columns are base, quote, rate, and either date (like 2022-03-31, in UTC) or timestamp (epoch seconds).
+// It returns all the row errors together, so they can be fixed in one go.
+func readExchangeRatesCSV(reader io.Reader) ([]*models.ExchangeRateDTO, []error) {
+	csvReader := csv.NewReader(reader)
+	csvReader.TrimLeadingSpace = true
+
+	header, err := csvReader.Read()
+	if err != nil {
+		return nil, []error{errInvalidExchangeRatesFile}
+	}
+
+	// Mapping the column names to their indices.
+	columns := map[string]int{}
+	for idx, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = idx
+	}
+
+	_, hasDate := columns["date"]
+	_, hasTimestamp := columns["timestamp"]
+	for _, name := range []string{"base", "quote", "rate"} {
+		if _, exists := columns[name]; !exists {
+			return nil, []error{errInvalidExchangeRatesFile}
+		}
+	}
+	if !hasDate && !hasTimestamp {
+		return nil, []error{errInvalidExchangeRatesFile}
+	}
+
+	var rates []*models.ExchangeRateDTO
+	var rowErrs []error
+
+	// The header is row 1.
+	for rowNum := 2; ; rowNum++ {
+		record, err := csvReader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			rowErrs = append(rowErrs, fmt.Errorf("row %d: %w", rowNum, err))
+			break
+		}
+
+		rate, err := parseExchangeRateRecord(record, columns, hasDate)
+		if err != nil {
+			rowErrs = append(rowErrs, fmt.Errorf("row %d: %w", rowNum, err))
+			continue
+		}
+		rates = append(rates, rate)
+	}
+
+	return rates, rowErrs
+}
+
+// parseExchangeRateRecord parses and validates a CSV record of an exchange rate.
+func parseExchangeRateRecord(record []string, columns map[string]int, hasDate bool) (*models.ExchangeRateDTO, error) {
+	value, err := models.ParseRate(record[columns["rate"]])
+	if err != nil {
+		return nil, errInvalidExchangeRate
+	}
+
+	rate := &models.ExchangeRateDTO{Base: record[columns["base"]], Quote: record[columns["quote"]], Rate: value}
+
+	if hasDate {
+		date, err := time.Parse("2006-01-02", strings.TrimSpace(record[columns["date"]]))
+		if err != nil {
+			return nil, errors.New("date should be in the YYYY-MM-DD format")
+		}
+		rate.Timestamp = date.Unix()
+	} else {
+		timestamp, err := strconv.ParseInt(strings.TrimSpace(record[columns["timestamp"]]), 10, 64)
+		if err != nil {
+			return nil, errInvalidTxTimestamp
+		}
+		rate.Timestamp = timestamp
+	}
+
+	if err := prepareExchangeRate(rate); err != nil {
+		return nil, err
+	}
+	return rate, nil
+}
+
+// currencyPair is a base currency and the quote currency that it is converted into.
+type currencyPair struct {
+	base  string
+	quote string
+}
+
+// exchangeRateBook finds the exchange rate that is in effect between two currencies at a given time.
+type exchangeRateBook struct {
+	// rates maps the currency pairs to their rates in ascending order of their timestamps.
+	rates map[currencyPair][]*models.ExchangeRateDTO
+}
+
+// newExchangeRateBook creates a new exchangeRateBook out of rates sorted in ascending order of their timestamps.
+func newExchangeRateBook(rates []*models.ExchangeRateDTO) *exchangeRateBook {
+	book := &exchangeRateBook{rates: map[currencyPair][]*models.ExchangeRateDTO{}}
+	for _, rate := range rates {
+		pair := currencyPair{base: rate.Base, quote: rate.Quote}
+		book.rates[pair] = append(book.rates[pair], rate)
+	}
+	return book
+}
+
+// getRate provides the rate to convert the base currency into the quote currency at the given time.
+//
+// The rate in effect is the latest one at or before the given time. If there is no such rate, the earliest rate is
+// used. A rate of the inverse pair is used too if it came into effect more recently.
+func (e *exchangeRateBook) getRate(base string, quote string, timestamp int64) (*big.Rat, error) {
+	if base == quote {
+		return big.NewRat(1, 1), nil
+	}
+
+	direct := findEffectiveRate(e.rates[currencyPair{base: base, quote: quote}], timestamp)
+	inverse := findEffectiveRate(e.rates[currencyPair{base: quote, quote: base}], timestamp)
+
+	if inverse != nil && isRateMoreRecent(inverse, direct, timestamp) {
+		return new(big.Rat).Inv(inverse.Rate.Rat()), nil
+	}
+	if direct != nil {
+		return direct.Rate.Rat(), nil
+	}
+
+	return nil, errutils.ExchangeRateNotFound().AddErrors(fmt.Errorf("no exchange rate from %s to %s", base, quote))
+}
+
+// convert converts the amount from the base currency into the quote currency at the given time.
+func (e *exchangeRateBook) convert(amount models.Money, base string, quote string, timestamp int64) (models.Money, error) {
+	rate, err := e.getRate(base, quote, timestamp)
+	if err != nil {
+		return 0, err
+	}
+	return amount.Convert(rate), nil
+}
+
+// findEffectiveRate provides the latest rate at or before the given time, or the earliest rate if there is no such
+// rate. The rates must be sorted in ascending order of their timestamps. It returns nil if there are no rates.
+func findEffectiveRate(rates []*models.ExchangeRateDTO, timestamp int64) *models.ExchangeRateDTO {
+	if len(rates) == 0 {
+		return nil
+	}
+
+	effective := rates[0]
+	for _, rate := range rates[1:] {
+		if rate.Timestamp > timestamp {
+			break
+		}
+		effective = rate
+	}
+	return effective
+}
+
+// isRateMoreRecent checks if the rate "a" is closer to being in effect at the given time than the rate "b".
+func isRateMoreRecent(a *models.ExchangeRateDTO, b *models.ExchangeRateDTO, timestamp int64) bool {
+	if b == nil {
+		return true
+	}
+
+	aInEffect, bInEffect := a.Timestamp <= timestamp, b.Timestamp <= timestamp
+	if aInEffect != bInEffect {
+		return aInEffect
+	}
+	// Among rates in effect, the latest one wins. Among future rates, the earliest one wins.
+	if aInEffect {
+		return a.Timestamp > b.Timestamp
+	}
+	return a.Timestamp < b.Timestamp
+}
@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/shivanshkc/ledgerkeep/src/models"
+)
+
+func TestExchangeRateBook(t *testing.T) {
+	book := newExchangeRateBook([]*models.ExchangeRateDTO{
+		{Base: "USD", Quote: "INR", Rate: "75", Timestamp: 100},
+		{Base: "USD", Quote: "INR", Rate: "80", Timestamp: 200},
+		{Base: "INR", Quote: "USD", Rate: "0.0125", Timestamp: 150},
+	})
+
+	testCases := []struct {
+		base, quote string
+		timestamp   int64
+		expected    models.Money
+	}{
+		{base: "INR", quote: "INR", timestamp: 0, expected: 100 * 10000},
+		// Before all rates, the earliest one is used.
+		{base: "USD", quote: "INR", timestamp: 50, expected: 7500 * 10000},
+		{base: "USD", quote: "INR", timestamp: 120, expected: 7500 * 10000},
+		// The inverse rate came into effect more recently.
+		{base: "USD", quote: "INR", timestamp: 170, expected: 8000 * 10000},
+		{base: "USD", quote: "INR", timestamp: 300, expected: 8000 * 10000},
+		{base: "INR", quote: "USD", timestamp: 120, expected: 13333},
+	}
+
+	for _, tc := range testCases {
+		converted, err := book.convert(100*10000, tc.base, tc.quote, tc.timestamp)
+		if err != nil {
+			t.Fatalf("unexpected error for %+v: %+v", tc, err)
+		}
+		if converted != tc.expected {
+			t.Errorf("expected %s for %+v, got %s", tc.expected, tc, converted)
+		}
+	}
+
+	if _, err := book.convert(1, "EUR", "INR", 0); err == nil {
+		t.Errorf("expected an error for a missing rate")
+	}
+}
+
+func TestCheckAmountPrecision(t *testing.T) {
+	if err := checkAmountPrecision(models.Money(10500), "INR"); err != nil {
+		t.Errorf("expected 1.05 INR to be valid, got: %+v", err)
+	}
+	if err := checkAmountPrecision(models.Money(10050), "INR"); err == nil {
+		t.Errorf("expected 1.005 INR to be invalid")
+	}
+	if err := checkAmountPrecision(models.Money(5000), "JPY"); err == nil {
+		t.Errorf("expected 0.5 JPY to be invalid")
+	}
+}
+
+func TestReadExchangeRatesCSV(t *testing.T) {
+	rates, errs := readExchangeRatesCSV(strings.NewReader("base,quote,rate,timestamp\nusd,inr,82.5,100\n"))
+	if len(errs) != 0 {
+		t.Fatalf("unexpected errors: %+v", errs)
+	}
+	if len(rates) != 1 || rates[0].Base != "USD" || rates[0].Quote != "INR" || rates[0].Rate != "82.5" ||
+		rates[0].Timestamp != 100 {
+		t.Fatalf("unexpected rates: %+v", rates[0])
+	}
+
+	// All invalid rows are reported.
+	_, errs = readExchangeRatesCSV(strings.NewReader("base,quote,rate,date\nUSD,USD,1,2022-01-01\nUSD,INR,-1,2022-01-01\n"))
+	if len(errs) != 2 {
+		t.Fatalf("expected 2 errors, got: %+v", errs)
+	}
+
+	if _, errs = readExchangeRatesCSV(strings.NewReader("base,quote,rate\n")); len(errs) == 0 {
+		t.Fatalf("expected an error for a missing timestamp column")
+	}
+}
@@ -0,0 +1,110 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/shivanshkc/ledgerkeep/src/configs"
+	"github.com/shivanshkc/ledgerkeep/src/database"
+	"github.com/shivanshkc/ledgerkeep/src/duplicates"
+	"github.com/shivanshkc/ledgerkeep/src/models"
+	"github.com/shivanshkc/ledgerkeep/src/utils/errutils"
+)
+
+// duplicateFields are the transaction fields that the duplicate detector needs.
+var duplicateFields = []string{"amount", "timestamp", "account_id", "notes"}
+
+// getDuplicateDetector provides the duplicate detector as per the configs.
+func getDuplicateDetector() *duplicates.Detector {
+	conf := configs.Get()
+	return &duplicates.Detector{Window: conf.Duplicates.WindowSec, MinSimilarity: conf.Duplicates.MinNotesSimilarity}
+}
+
+// findDuplicates provides the IDs of the existing transactions that look like duplicates of the provided new
+// transactions. The IDs are in the same order as the transactions, and are nil for the transactions without duplicates.
+func (h *Handler) findDuplicates(ctx context.Context, transactions []*models.TransactionDTO) ([][]string, error) {
+	detector := getDuplicateDetector()
+
+	// The period of the new transactions of every account, which the existing transactions are looked up in.
+	type period struct{ start, end int64 }
+	periods := map[string]*period{}
+	for _, transaction := range transactions {
+		accountPeriod, exists := periods[transaction.AccountID]
+		if !exists {
+			periods[transaction.AccountID] = &period{start: transaction.Timestamp, end: transaction.Timestamp}
+			continue
+		}
+		if transaction.Timestamp < accountPeriod.start {
+			accountPeriod.start = transaction.Timestamp
+		}
+		if transaction.Timestamp > accountPeriod.end {
+			accountPeriod.end = transaction.Timestamp
+		}
+	}
+
+	existing := map[string][]*models.TransactionDTO{}
+	for accountID, accountPeriod := range periods {
+		// Database call.
+		accountTransactions, _, err := h.transactions.ListTransactions(ctx, &database.ListTransactionsParams{
+			Filter: msi{
+				"account_id": accountID,
+				"timestamp":  msi{"$gte": accountPeriod.start - detector.Window, "$lte": accountPeriod.end + detector.Window},
+			},
+			RequiredFields: duplicateFields,
+			SortField:      "timestamp",
+			SortOrder:      1,
+			ExcludeCount:   true,
+		})
+		if err != nil {
+			return nil, err
+		}
+		existing[accountID] = accountTransactions
+	}
+
+	duplicateIDs := make([][]string, len(transactions))
+	for idx, transaction := range transactions {
+		for _, existingTx := range existing[transaction.AccountID] {
+			if detector.IsDuplicate(transaction, existingTx) {
+				duplicateIDs[idx] = append(duplicateIDs[idx], existingTx.ID)
+			}
+		}
+	}
+
+	return duplicateIDs, nil
+}
+
+// duplicateTransactionError provides the error for the new transactions that look like duplicates of the existing
+// ones. The duplicateIDs are as provided by findDuplicates. The label names a new transaction by its index.
+func duplicateTransactionError(duplicateIDs [][]string, label func(idx int) string) *errutils.HTTPError {
+	err := errutils.DuplicateTransaction()
+	for idx, ids := range duplicateIDs {
+		for _, id := range ids {
+			err.AddMessages(fmt.Sprintf("%s looks like a duplicate of transaction %s", label(idx), id))
+		}
+	}
+	return err
+}
+
+// hasDuplicates checks if any of the new transactions has duplicates, as per the findDuplicates result.
+func hasDuplicates(duplicateIDs [][]string) bool {
+	for _, ids := range duplicateIDs {
+		if len(ids) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// readAllowDuplicates reads the "allow_duplicates" flag of a request, which is false if it is empty.
+func readAllowDuplicates(value string) (bool, error) {
+	if value == "" {
+		return false, nil
+	}
+
+	allow, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, errInvalidAllowDuplicates
+	}
+	return allow, nil
+}
@@ -0,0 +1,248 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/shivanshkc/ledgerkeep/src/database"
+	"github.com/shivanshkc/ledgerkeep/src/exports"
+	"github.com/shivanshkc/ledgerkeep/src/models"
+)
+
+// closingBalFields are the transaction fields that the closing balances need.
+var closingBalFields = []string{"_id", "amount", "account_id", "timestamp"}
+
+// exportContentTypes are the content types of the export formats.
+var exportContentTypes = map[string]string{
+	exportFormatCSV:   "text/csv",
+	exportFormatJSONL: "application/x-ndjson",
+	exportFormatXLSX:  "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+}
+
+// newExportWriter provides the exports.Writer of the format, which writes into the provided writer.
+func newExportWriter(format string, writer io.Writer) exports.Writer {
+	switch format {
+	case exportFormatJSONL:
+		return exports.NewJSONLWriter(writer)
+	case exportFormatXLSX:
+		return exports.NewXLSXWriter(writer)
+	default:
+		return exports.NewCSVWriter(writer)
+	}
+}
+
+// exportRowStream reads the exported transactions from a cursor, along with their closing balances.
+//
+// The closing balance of a transaction is the sum of the amounts of all the transactions of its account up to it,
+// including those that the filters leave out. So, the stream reads a second cursor of all the transactions alongside.
+// Both cursors are in the order of the timestamps and the IDs, so the exported transactions are met in the second
+// cursor in the same order, and the balances are summed up to them, without keeping either list in memory.
+type exportRowStream struct {
+	cursor     database.TransactionCursor
+	allCursor  database.TransactionCursor
+	currencies accountCurrencyMap
+	// balances are the running balances of the accounts, up to the last read transaction of the allCursor.
+	balances map[string]models.Money
+}
+
+// newExportRowStream creates a new exportRowStream. The cursors should be in the ascending order of the timestamps.
+func newExportRowStream(cursor database.TransactionCursor, allCursor database.TransactionCursor,
+	currencies accountCurrencyMap) *exportRowStream {
+	return &exportRowStream{
+		cursor:     cursor,
+		allCursor:  allCursor,
+		currencies: currencies,
+		balances:   map[string]models.Money{},
+	}
+}
+
+// next provides the next row of the export. It returns nil once there are no more rows.
+func (e *exportRowStream) next(ctx context.Context) (*exports.Row, error) {
+	if !e.cursor.Next(ctx) {
+		return nil, e.cursor.Err()
+	}
+	transaction := e.cursor.Transaction()
+
+	// Summing up the balances up to the transaction.
+	for {
+		if !e.allCursor.Next(ctx) {
+			if err := e.allCursor.Err(); err != nil {
+				return nil, err
+			}
+			// This may happen only if the transaction was created after the cursors were opened.
+			return nil, fmt.Errorf("did not find closing balance for tx: %s", transaction.ID)
+		}
+
+		current := e.allCursor.Transaction()
+		e.balances[current.AccountID] += current.Amount
+		if current.ID == transaction.ID {
+			break
+		}
+	}
+
+	return &exports.Row{
+		ID:         transaction.ID,
+		Timestamp:  transaction.Timestamp,
+		AccountID:  transaction.AccountID,
+		Currency:   e.currencies.get(transaction.AccountID),
+		Category:   transaction.Category,
+		Amount:     transaction.Amount,
+		ClosingBal: e.balances[transaction.AccountID],
+		Notes:      transaction.Notes,
+		Tags:       transaction.Tags,
+		TransferID: transaction.TransferID,
+		ExternalID: transaction.ExternalID,
+	}, nil
+}
+
+// writeExport writes all the rows of the stream using the exports.Writer, and closes it.
+func writeExport(ctx context.Context, writer exports.Writer, rows *exportRowStream) error {
+	for {
+		row, err := rows.next(ctx)
+		if err != nil {
+			return err
+		}
+		if row == nil {
+			break
+		}
+		if err := writer.Write(row); err != nil {
+			return err
+		}
+	}
+	return writer.Close()
+}
+
+// journalFormats are the exports.JournalFormat values of the journal formats.
+var journalFormats = map[string]exports.JournalFormat{
+	journalFormatLedger:    exports.JournalLedger,
+	journalFormatHLedger:   exports.JournalHLedger,
+	journalFormatBeancount: exports.JournalBeancount,
+}
+
+// journalFileNames are the file names of the journal exports, with the extensions that the tools expect.
+var journalFileNames = map[string]string{
+	journalFormatLedger:    "ledgerkeep.ledger",
+	journalFormatHLedger:   "ledgerkeep.journal",
+	journalFormatBeancount: "ledgerkeep.beancount",
+}
+
+// journalData is all that a journal export needs, other than the transactions.
+type journalData struct {
+	// accounts are the exported accounts, along with their currencies.
+	accounts   []*models.AccountDTO
+	categories []*models.CategoryDTO
+	// filter is the database filter of the exported transactions.
+	filter msi
+	// openTime is the time that the accounts are opened at in the journal.
+	openTime int64
+	// balances are the opening balances of the accounts, keyed by their IDs.
+	balances map[string]models.Money
+}
+
+// getJournalData fetches all that a journal export needs, other than the transactions. If the accountID is not empty,
+// only that account is exported. The timestampFilter is as provided by getStartEndTimestampFilter.
+func (h *Handler) getJournalData(ctx context.Context, accountID string, timestampFilter msi) (*journalData, error) {
+	data := &journalData{filter: msi{}, balances: map[string]models.Money{}}
+
+	// Database calls.
+	if accountID == "" {
+		accounts, err := h.accounts.ListAccounts(ctx)
+		if err != nil {
+			return nil, err
+		}
+		data.accounts = accounts
+	} else {
+		account, err := h.accounts.GetAccount(ctx, accountID)
+		if err != nil {
+			return nil, err
+		}
+		data.accounts = []*models.AccountDTO{account}
+		data.filter["account_id"] = accountID
+	}
+
+	// The journal needs the currencies of all the accounts.
+	for idx, account := range data.accounts {
+		accountCopy := *account
+		accountCopy.Currency = getAccountCurrency(account)
+		data.accounts[idx] = &accountCopy
+	}
+
+	categories, err := h.categories.ListCategories(ctx)
+	if err != nil {
+		return nil, err
+	}
+	data.categories = categories
+
+	if len(timestampFilter) > 0 {
+		data.filter["timestamp"] = timestampFilter
+	}
+
+	// The accounts are opened at the start time, with the balances that they had before it.
+	if startTime, exists := timestampFilter["$gte"].(int64); exists {
+		data.openTime = startTime
+
+		balanceFilter := msi{"timestamp": msi{"$lt": startTime}}
+		if accountID != "" {
+			balanceFilter["account_id"] = accountID
+		}
+		// The sums are aggregated by the storage backend.
+		totals, err := h.transactions.GetCategoryTotals(ctx, balanceFilter)
+		if err != nil {
+			return nil, err
+		}
+		for _, total := range totals {
+			data.balances[total.AccountID] += total.Credit + total.Debit
+		}
+		return data, nil
+	}
+
+	// Without a start time, the accounts are opened at the first exported transaction.
+	first, _, err := h.transactions.ListTransactions(ctx, &database.ListTransactionsParams{
+		Filter:          data.filter,
+		RequiredFields:  []string{"timestamp"},
+		PaginationLimit: 1,
+		SortField:       "timestamp",
+		SortOrder:       1,
+		ExcludeCount:    true,
+	})
+	if err != nil {
+		return nil, err
+	}
+	data.openTime = time.Now().Unix()
+	if len(first) > 0 {
+		data.openTime = first[0].Timestamp
+	}
+
+	return data, nil
+}
+
+// writeJournal writes the journal, with the transactions of the cursor, using the exports.JournalWriter.
+func (h *Handler) writeJournal(ctx context.Context, writer *exports.JournalWriter, data *journalData,
+	cursor database.TransactionCursor) error {
+	if err := writer.Open(data.openTime, data.balances); err != nil {
+		return err
+	}
+
+	for cursor.Next(ctx) {
+		transaction := cursor.Transaction()
+		// The cursor does not load the split lines, so the transactions with splits are fetched along with them.
+		if transaction.Category == categorySplit {
+			withSplits, err := h.transactions.GetTransaction(ctx, transaction.ID)
+			if err != nil {
+				return err
+			}
+			transaction = withSplits
+		}
+
+		if err := writer.Write(transaction); err != nil {
+			return err
+		}
+	}
+	if err := cursor.Err(); err != nil {
+		return err
+	}
+
+	return writer.Close()
+}
@@ -13,7 +13,8 @@ type msi = map[string]interface{}
 
 // prepareNewTransaction validates all params of createTransactionBody struct.
 // It also creates a *models.TransactionDTO struct out of it.
-func prepareNewTransaction(body *createTransactionBody) (*models.TransactionDTO, error) {
+// The categories are validated against the provided categorySet.
+func prepareNewTransaction(body *createTransactionBody, categories categorySet) (*models.TransactionDTO, error) {
 	// Validating transaction amount.
 	if body.Amount == 0 {
 		return nil, errInvalidTxAmount
@@ -24,9 +25,35 @@ func prepareNewTransaction(body *createTransactionBody) (*models.TransactionDTO,
 		return nil, errInvalidAccountID
 	}
 
+	tags, err := prepareTags(body.Tags)
+	if err != nil {
+		return nil, err
+	}
+
+	// A transaction with splits has its categories in the split lines.
+	if len(body.Splits) > 0 {
+		if body.Category != "" && !strings.EqualFold(body.Category, categorySplit) {
+			return nil, errSplitCategory
+		}
+
+		splits, err := prepareSplits(body.Splits, body.Amount, categories)
+		if err != nil {
+			return nil, err
+		}
+
+		return &models.TransactionDTO{
+			Amount:    body.Amount,
+			Timestamp: body.Timestamp,
+			AccountID: body.AccountID,
+			Category:  categorySplit,
+			Notes:     body.Notes,
+			Splits:    splits,
+			Tags:      tags,
+		}, nil
+	}
+
 	// Validating category.
-	allowedCats := getAllowedCategoriesForTxAmount(body.Amount)
-	if !stringPresentCaseInsensitive(body.Category, allowedCats) {
+	if !categories.allows(body.Category, body.Amount) {
 		return nil, errInvalidTxCategory
 	}
 
@@ -36,9 +63,46 @@ func prepareNewTransaction(body *createTransactionBody) (*models.TransactionDTO,
 		AccountID: body.AccountID,
 		Category:  strings.ToLower(body.Category),
 		Notes:     body.Notes,
+		Tags:      tags,
 	}, nil
 }
 
+// prepareSplits validates the split lines of a transaction with the given amount.
+// It provides a copy of the split lines with normalized categories.
+//
+// Every split line has the same sign as the amount, so its category follows the same rules as the category of a
+// transaction with that amount.
+func prepareSplits(splits []*models.SplitDTO, amount models.Money, categories categorySet) ([]*models.SplitDTO, error) {
+	if len(splits) < 2 {
+		return nil, errTooFewSplits
+	}
+
+	prepared := make([]*models.SplitDTO, len(splits))
+	var sum models.Money
+
+	for idx, split := range splits {
+		if split == nil || split.Amount == 0 || (split.Amount > 0) != (amount > 0) {
+			return nil, errInvalidSplitAmount
+		}
+
+		if !categories.allows(split.Category, split.Amount) {
+			return nil, errInvalidTxCategory
+		}
+
+		prepared[idx] = &models.SplitDTO{
+			Amount:   split.Amount,
+			Category: strings.ToLower(split.Category),
+			Notes:    split.Notes,
+		}
+		sum += split.Amount
+	}
+
+	if sum != amount {
+		return nil, errSplitsSumMismatch
+	}
+	return prepared, nil
+}
+
 // prepareUpdateTransactionQuery validates all params of the updateTransactionBody and creates a map of updates.
 // Any nil parameters are ignored in the process.
 //
@@ -50,11 +114,26 @@ func prepareNewTransaction(body *createTransactionBody) (*models.TransactionDTO,
 // 2. If the user did not provide a category but provided an amount, we need the current category to determine if the
 // transaction should be a debit or credit.
 //
-// This whole mess is obviously because some categories are only valid for debit transactions and some are only valid
-// for credit transactions.
-func prepareUpdateTransactionQuery(body *updateTransactionBody, currentTx *models.TransactionDTO) (msi, error) {
+// This whole mess is obviously because the kinds of some categories make them valid only for debit transactions or
+// only for credit transactions.
+func prepareUpdateTransactionQuery(body *updateTransactionBody, currentTx *models.TransactionDTO,
+	categories categorySet) (msi, error) {
+	removesSplits := body.Splits != nil && len(*body.Splits) == 0
+	// Transactions that have or get split lines have their own rules, as their categories are in the split lines.
+	if !removesSplits && (body.Splits != nil || len(currentTx.Splits) > 0) {
+		return prepareUpdateSplitTransactionQuery(body, currentTx, categories)
+	}
+
 	updates := msi{}
 
+	// Removing the split lines makes it a single category transaction again, so a category is required.
+	if removesSplits && len(currentTx.Splits) > 0 {
+		if body.Category == nil {
+			return nil, errSplitsRemovalCategory
+		}
+		updates["splits"] = []*models.SplitDTO{}
+	}
+
 	// Validating transaction amount.
 	if body.Amount != nil {
 		if *body.Amount == 0 {
@@ -62,12 +141,8 @@ func prepareUpdateTransactionQuery(body *updateTransactionBody, currentTx *model
 		}
 		// If a new category has not been provided,
 		// then this new amount should be in agreement with the current category.
-		if body.Category == nil {
-			validCase1 := stringPresentCaseInsensitive(currentTx.Category, allowedCreditCategories) && *body.Amount > 0
-			validCase2 := stringPresentCaseInsensitive(currentTx.Category, allowedDebitCategories) && *body.Amount < 0
-			if !validCase1 && !validCase2 {
-				return nil, errAmountCategoryMismatch
-			}
+		if body.Category == nil && !categories.allows(currentTx.Category, *body.Amount) {
+			return nil, errAmountCategoryMismatch
 		}
 
 		updates["amount"] = *body.Amount
@@ -88,18 +163,17 @@ func prepareUpdateTransactionQuery(body *updateTransactionBody, currentTx *model
 
 	// Validating category.
 	if body.Category != nil {
-		var amount float64
+		var amount models.Money
 		if body.Amount != nil {
 			amount = *body.Amount
 		} else {
 			amount = currentTx.Amount
 		}
 
-		allowedCats := getAllowedCategoriesForTxAmount(amount)
-		if !stringPresentCaseInsensitive(*body.Category, allowedCats) {
+		if !categories.allows(*body.Category, amount) {
 			return nil, errInvalidTxCategory
 		}
-		updates["category"] = *body.Category
+		updates["category"] = strings.ToLower(*body.Category)
 	}
 
 	// Validating notes.
@@ -107,9 +181,179 @@ func prepareUpdateTransactionQuery(body *updateTransactionBody, currentTx *model
 		updates["notes"] = *body.Notes
 	}
 
+	if body.Tags != nil {
+		tags, err := prepareTags(*body.Tags)
+		if err != nil {
+			return nil, err
+		}
+		updates["tags"] = tags
+	}
+
+	return updates, nil
+}
+
+// prepareUpdateSplitTransactionQuery validates all params of the updateTransactionBody for a transaction that has or
+// gets split lines, and creates a map of updates. Any nil parameters are ignored in the process.
+//
+// The category of such a transaction cannot be updated, and its split lines should always add up to its amount.
+// So, if the amount is updated, the split lines have to be updated too.
+func prepareUpdateSplitTransactionQuery(body *updateTransactionBody, currentTx *models.TransactionDTO,
+	categories categorySet) (msi, error) {
+	if body.Category != nil && !strings.EqualFold(*body.Category, categorySplit) {
+		return nil, errSplitCategory
+	}
+
+	updates := msi{}
+
+	// Validating transaction amount.
+	amount := currentTx.Amount
+	if body.Amount != nil {
+		if *body.Amount == 0 {
+			return nil, errInvalidTxAmount
+		}
+		amount = *body.Amount
+		updates["amount"] = amount
+	}
+
+	// Validating the split lines against the new amount.
+	splits := currentTx.Splits
+	if body.Splits != nil {
+		splits = *body.Splits
+	}
+	prepared, err := prepareSplits(splits, amount, categories)
+	if err != nil {
+		return nil, err
+	}
+	if body.Splits != nil {
+		updates["splits"] = prepared
+		updates["category"] = categorySplit
+	}
+
+	if body.Timestamp != nil {
+		updates["timestamp"] = *body.Timestamp
+	}
+
+	// Validating account ID.
+	if body.AccountID != nil {
+		if !accountIDRegexp.MatchString(*body.AccountID) {
+			return nil, errInvalidAccountID
+		}
+		updates["account_id"] = *body.AccountID
+	}
+
+	if body.Notes != nil {
+		updates["notes"] = *body.Notes
+	}
+
+	if body.Tags != nil {
+		tags, err := prepareTags(*body.Tags)
+		if err != nil {
+			return nil, err
+		}
+		updates["tags"] = tags
+	}
+
 	return updates, nil
 }
 
+// prepareUpdateTransferLegQuery validates all params of the updateTransactionBody for a leg of a transfer and creates
+// a map of updates. Any nil parameters are ignored in the process.
+//
+// The category of a leg cannot be updated, and its amount has to keep its sign, so the legs keep moving money from
+// the same account to the other.
+func prepareUpdateTransferLegQuery(body *updateTransactionBody, currentTx *models.TransactionDTO) (msi, error) {
+	if body.Category != nil {
+		return nil, errTransferCategoryUpdate
+	}
+	if body.Splits != nil {
+		return nil, errTransferSplits
+	}
+
+	updates := msi{}
+
+	// Validating transaction amount.
+	if body.Amount != nil {
+		if *body.Amount == 0 {
+			return nil, errInvalidTxAmount
+		}
+		if (*body.Amount > 0) != (currentTx.Amount > 0) {
+			return nil, errTransferAmountSign
+		}
+		updates["amount"] = *body.Amount
+	}
+
+	if body.Timestamp != nil {
+		updates["timestamp"] = *body.Timestamp
+	}
+
+	// Validating account ID.
+	if body.AccountID != nil {
+		if !accountIDRegexp.MatchString(*body.AccountID) {
+			return nil, errInvalidAccountID
+		}
+		updates["account_id"] = *body.AccountID
+	}
+
+	if body.Notes != nil {
+		updates["notes"] = *body.Notes
+	}
+
+	if body.Tags != nil {
+		tags, err := prepareTags(*body.Tags)
+		if err != nil {
+			return nil, err
+		}
+		updates["tags"] = tags
+	}
+
+	return updates, nil
+}
+
+// checkNewTransferBody validates the params of the createTransferBody that do not depend on the accounts.
+func checkNewTransferBody(body *createTransferBody) error {
+	if body.Amount <= 0 {
+		return errInvalidTransferAmount
+	}
+	if body.ToAmount != nil && *body.ToAmount <= 0 {
+		return errInvalidTransferToAmount
+	}
+
+	// Validating account IDs.
+	if !accountIDRegexp.MatchString(body.FromAccountID) || !accountIDRegexp.MatchString(body.ToAccountID) {
+		return errInvalidAccountID
+	}
+	if body.FromAccountID == body.ToAccountID {
+		return errTransferSameAccount
+	}
+
+	return nil
+}
+
+// getTransferToAmount provides the amount that arrives in the destination account of a transfer.
+//
+// For transfers in the same currency, it is the transferred amount itself. For transfers between different currencies,
+// it has to be provided explicitly, as it depends on the exchange rate that was actually applied.
+func getTransferToAmount(body *createTransferBody, fromCurrency string, toCurrency string) (models.Money, error) {
+	if err := checkAmountPrecision(body.Amount, fromCurrency); err != nil {
+		return 0, err
+	}
+
+	if fromCurrency == toCurrency {
+		if body.ToAmount != nil && *body.ToAmount != body.Amount {
+			return 0, errTransferToAmountMismatch
+		}
+		return body.Amount, nil
+	}
+
+	if body.ToAmount == nil {
+		return 0, errTransferToAmountRequired
+	}
+	if err := checkAmountPrecision(*body.ToAmount, toCurrency); err != nil {
+		return 0, err
+	}
+	return *body.ToAmount, nil
+}
+
 // readListTransactionsQuery reads the request query values and loads them into *listTransactionsQuery type.
 func readListTransactionsQuery(values url.Values) *listTransactionsQuery {
 	qValues := &listTransactionsQuery{}
@@ -177,14 +421,14 @@ func getStartEndAmountFilter(startAmount *string, endAmount *string) (msi, error
 	filter := msi{}
 
 	if startAmount != nil && *startAmount != "" {
-		amount, err := strconv.ParseFloat(*startAmount, 64)
+		amount, err := models.ParseMoney(*startAmount)
 		if err != nil {
 			return nil, errInvalidStartAmount
 		}
 		filter["$gte"] = amount
 	}
 	if endAmount != nil && *endAmount != "" {
-		amount, err := strconv.ParseFloat(*endAmount, 64)
+		amount, err := models.ParseMoney(*endAmount)
 		if err != nil {
 			return nil, errInvalidEndAmount
 		}
@@ -293,14 +537,6 @@ func parseTransactionTimestampString(timestamp string) (int64, error) {
 	return timestampInt, nil
 }
 
-// getAllowedCategoriesForTxAmount provides the list of allowed categories of the specified transaction amount.
-func getAllowedCategoriesForTxAmount(amount float64) []string {
-	if amount > 0 {
-		return allowedCreditCategories
-	}
-	return allowedDebitCategories
-}
-
 // stringPresentCaseInsensitive checks if the "value" is present in the "others" slice case-insensitively.
 func stringPresentCaseInsensitive(value string, others []string) bool {
 	valueLower := strings.ToLower(value)
@@ -326,6 +562,11 @@ func readGetBudgetQuery(values url.Values) *getBudgetQuery {
 		qValues.EndTime = &endTime
 	}
 
+	if values.Has("currency") {
+		currency := values.Get("currency")
+		qValues.Currency = &currency
+	}
+
 	return qValues
 }
 
@@ -387,9 +628,9 @@ func parseBudgetEndTime(endTime string) (int64, error) {
 
 // generateTransactionClosingBalanceMap generates a map of transactionID -> Closing Balance
 // for a list of transactions sorted in ascending order of timestamp.
-func generateTransactionClosingBalanceMap(transactions []*models.TransactionDTO) map[string]float64 {
-	closingBalMap := map[string]float64{}
-	accountClosingBalMap := map[string]float64{}
+func generateTransactionClosingBalanceMap(transactions []*models.TransactionDTO) map[string]models.Money {
+	closingBalMap := map[string]models.Money{}
+	accountClosingBalMap := map[string]models.Money{}
 
 	for _, tx := range transactions {
 		currentClosingBal4Account := accountClosingBalMap[tx.AccountID]
@@ -0,0 +1,548 @@
+package handlers
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/shivanshkc/ledgerkeep/src/database"
+	"github.com/shivanshkc/ledgerkeep/src/models"
+	"github.com/shivanshkc/ledgerkeep/src/rules"
+	"github.com/shivanshkc/ledgerkeep/src/statements"
+	"github.com/shivanshkc/ledgerkeep/src/utils/errutils"
+	"github.com/shivanshkc/ledgerkeep/src/utils/httputils"
+)
+
+// defaultStatementDateFormat is the date format of the import profiles that do not specify one.
+const defaultStatementDateFormat = "2006-01-02"
+
+// prepareImportProfile validates the import profile and fills in the defaults of its optional fields.
+// The default categories of the profile are validated against the provided categorySet.
+func prepareImportProfile(profile *models.ImportProfileDTO, categories categorySet) error {
+	if !importProfileNameRegexp.MatchString(profile.Name) {
+		return errInvalidImportProfileName
+	}
+	if !accountIDRegexp.MatchString(profile.AccountID) {
+		return errInvalidAccountID
+	}
+
+	if profile.Delimiter == "" {
+		profile.Delimiter = ","
+	}
+	delimiter, _ := utf8.DecodeRuneInString(profile.Delimiter)
+	if utf8.RuneCountInString(profile.Delimiter) != 1 || strings.ContainsRune("\"\r\n", delimiter) ||
+		delimiter == utf8.RuneError {
+		return errInvalidDelimiter
+	}
+
+	if profile.DecimalSeparator == "" {
+		profile.DecimalSeparator = "."
+	}
+	if !stringPresentCaseInsensitive(profile.DecimalSeparator, allowedDecimalSeparators) {
+		return errInvalidDecimalSeparator
+	}
+
+	// The column names are matched with the trimmed header names.
+	for _, column := range []*string{&profile.DateColumn, &profile.AmountColumn, &profile.DebitColumn,
+		&profile.CreditColumn, &profile.NotesColumn, &profile.CategoryColumn} {
+		*column = strings.TrimSpace(*column)
+	}
+
+	if profile.DateColumn == "" {
+		return errMissingDateColumn
+	}
+	if profile.DateFormat == "" {
+		profile.DateFormat = defaultStatementDateFormat
+	}
+	if !isValidDateFormat(profile.DateFormat) {
+		return errInvalidDateFormat
+	}
+
+	hasAmount := profile.AmountColumn != ""
+	hasDebitOrCredit := profile.DebitColumn != "" || profile.CreditColumn != ""
+	if hasAmount == hasDebitOrCredit {
+		return errInvalidAmountColumns
+	}
+
+	// Without a category column, every row gets one of the default categories.
+	if profile.CategoryColumn == "" && (profile.CreditCategory == "" || profile.DebitCategory == "") {
+		return errMissingImportCategories
+	}
+	if profile.CreditCategory != "" {
+		if !categories.allows(profile.CreditCategory, 1) {
+			return errInvalidCreditCategory
+		}
+		profile.CreditCategory = strings.ToLower(profile.CreditCategory)
+	}
+	if profile.DebitCategory != "" {
+		if !categories.allows(profile.DebitCategory, -1) {
+			return errInvalidDebitCategory
+		}
+		profile.DebitCategory = strings.ToLower(profile.DebitCategory)
+	}
+
+	return nil
+}
+
+// isValidDateFormat checks if the layout holds at least one element of a date, and can parse the dates it formats.
+func isValidDateFormat(layout string) bool {
+	// Any date other than the reference date of the layouts works here.
+	sample := time.Date(2021, time.November, 23, 0, 0, 0, 0, time.UTC)
+
+	formatted := sample.Format(layout)
+	if formatted == layout {
+		return false
+	}
+
+	_, err := time.Parse(layout, formatted)
+	return err == nil
+}
+
+// readStatementCSV reads the transactions of a bank statement CSV file as per the import profile.
+//
+// Every transaction is validated with the same rules as the transactions of the CreateTransaction API, and its amount
+// should be valid in the given currency of the profile's account. The categorization rules of the engine are applied to
+// every transaction. It returns all the row errors together, so they can be fixed in one go.
+func readStatementCSV(reader io.Reader, profile *models.ImportProfileDTO, categories categorySet, currency string,
+	engine *rules.Engine) ([]*models.TransactionDTO, []error) {
+	csvReader := csv.NewReader(reader)
+	csvReader.Comma, _ = utf8.DecodeRuneInString(profile.Delimiter)
+	csvReader.TrimLeadingSpace = true
+	// Some banks end their statements with summary rows of a different length.
+	csvReader.FieldsPerRecord = -1
+
+	header, err := csvReader.Read()
+	if err != nil {
+		return nil, []error{errInvalidStatementFile}
+	}
+
+	// Mapping the column names to their indices.
+	columns := map[string]int{}
+	for idx, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = idx
+	}
+
+	for _, name := range []string{profile.DateColumn, profile.AmountColumn, profile.DebitColumn,
+		profile.CreditColumn, profile.NotesColumn, profile.CategoryColumn} {
+		if _, exists := columns[strings.ToLower(name)]; name != "" && !exists {
+			return nil, []error{fmt.Errorf("column %q is missing from the file", name)}
+		}
+	}
+
+	var transactions []*models.TransactionDTO
+	var rowErrs []error
+
+	// The header is row 1.
+	for rowNum := 2; ; rowNum++ {
+		record, err := csvReader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			rowErrs = append(rowErrs, fmt.Errorf("row %d: %w", rowNum, err))
+			break
+		}
+
+		transaction, err := parseStatementRecord(record, columns, profile, categories, engine)
+		if err == nil {
+			err = checkAmountPrecision(transaction.Amount, currency)
+		}
+		if err != nil {
+			rowErrs = append(rowErrs, fmt.Errorf("row %d: %w", rowNum, err))
+			continue
+		}
+		transactions = append(transactions, transaction)
+	}
+
+	return transactions, rowErrs
+}
+
+// parseStatementRecord parses and validates a CSV record of a bank statement as per the import profile.
+func parseStatementRecord(record []string, columns map[string]int, profile *models.ImportProfileDTO,
+	categories categorySet, engine *rules.Engine) (*models.TransactionDTO, error) {
+	// cell provides the trimmed value of a column of the record. It is empty for the missing columns and cells.
+	cell := func(name string) string {
+		idx, exists := columns[strings.ToLower(name)]
+		if name == "" || !exists || idx >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[idx])
+	}
+
+	date, err := time.Parse(profile.DateFormat, cell(profile.DateColumn))
+	if err != nil {
+		return nil, errInvalidStatementDate
+	}
+
+	amount, err := parseStatementAmounts(profile, cell(profile.AmountColumn), cell(profile.DebitColumn),
+		cell(profile.CreditColumn))
+	if err != nil {
+		return nil, err
+	}
+
+	body := &createTransactionBody{
+		Amount:    amount,
+		Timestamp: date.Unix(),
+		AccountID: profile.AccountID,
+		Category:  cell(profile.CategoryColumn),
+		Notes:     cell(profile.NotesColumn),
+	}
+
+	// Rows without a category of their own get the category of the rules, or else the default category of their sign.
+	applyRules(engine, body, categories)
+	if body.Category == "" && amount > 0 {
+		body.Category = profile.CreditCategory
+	}
+	if body.Category == "" && amount < 0 {
+		body.Category = profile.DebitCategory
+	}
+
+	return prepareNewTransaction(body, categories)
+}
+
+// parseStatementAmounts provides the signed amount of a statement row from either its amount cell, or its debit and
+// credit cells, as per the import profile.
+func parseStatementAmounts(profile *models.ImportProfileDTO, amountCell, debitCell, creditCell string) (models.Money, error) {
+	if profile.AmountColumn != "" {
+		if amountCell == "" {
+			return 0, errMissingStatementAmount
+		}
+		return parseStatementAmount(amountCell, profile.DecimalSeparator)
+	}
+
+	if debitCell == "" && creditCell == "" {
+		return 0, errMissingStatementAmount
+	}
+
+	var amount models.Money
+	if creditCell != "" {
+		credit, err := parseStatementAmount(creditCell, profile.DecimalSeparator)
+		if err != nil {
+			return 0, err
+		}
+		amount += abs(credit)
+	}
+	if debitCell != "" {
+		debit, err := parseStatementAmount(debitCell, profile.DecimalSeparator)
+		if err != nil {
+			return 0, err
+		}
+		amount -= abs(debit)
+	}
+
+	return amount, nil
+}
+
+// parseStatementAmount parses an amount of a bank statement, like "-1,234.50" or "1.234,50".
+// The thousands separators, which are the opposite of the decimal separator, and the spaces are ignored.
+func parseStatementAmount(value string, decimalSeparator string) (models.Money, error) {
+	thousandsSeparator := ","
+	if decimalSeparator == "," {
+		thousandsSeparator = "."
+	}
+
+	value = strings.ReplaceAll(value, thousandsSeparator, "")
+	value = strings.ReplaceAll(value, " ", "")
+	value = strings.ReplaceAll(value, decimalSeparator, ".")
+
+	amount, err := models.ParseMoney(value)
+	if err != nil {
+		return 0, errInvalidStatementAmount
+	}
+	return amount, nil
+}
+
+// abs provides the absolute value of the amount.
+func abs(amount models.Money) models.Money {
+	if amount < 0 {
+		return -amount
+	}
+	return amount
+}
+
+// statementTarget is the account and the default categories that the transactions of a bank statement are imported
+// into, for the formats that are imported without an import profile.
+type statementTarget struct {
+	accountID      string
+	currency       string
+	creditCategory string
+	debitCategory  string
+	categories     categorySet
+	// engine applies the categorization rules to the transactions.
+	engine *rules.Engine
+}
+
+// readStatementTarget reads and validates the "account_id", "credit_category" and "debit_category" form fields of an
+// import request.
+func (h *Handler) readStatementTarget(ctx context.Context, request *http.Request) (*statementTarget, error) {
+	target := &statementTarget{
+		accountID:      request.FormValue("account_id"),
+		creditCategory: strings.ToLower(request.FormValue("credit_category")),
+		debitCategory:  strings.ToLower(request.FormValue("debit_category")),
+	}
+
+	if !accountIDRegexp.MatchString(target.accountID) {
+		return nil, errutils.BadRequest().AddErrors(errInvalidAccountID)
+	}
+	if target.creditCategory == "" || target.debitCategory == "" {
+		return nil, errutils.BadRequest().AddErrors(errMissingDefaultCategories)
+	}
+
+	// The categories are required to validate the default categories and the entries.
+	categories, err := h.getCategorySet(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !categories.allows(target.creditCategory, 1) {
+		return nil, errutils.BadRequest().AddErrors(errInvalidCreditCategory)
+	}
+	if !categories.allows(target.debitCategory, -1) {
+		return nil, errutils.BadRequest().AddErrors(errInvalidDebitCategory)
+	}
+	target.categories = categories
+
+	// The account provides the currency of the transactions.
+	account, err := h.accounts.GetAccount(ctx, target.accountID)
+	if err != nil {
+		return nil, err
+	}
+	target.currency = getAccountCurrency(account)
+
+	if target.engine, err = h.getRuleEngine(ctx); err != nil {
+		return nil, err
+	}
+
+	return target, nil
+}
+
+// newTransactions validates the entries of a bank statement and creates their transactions.
+//
+// The entries get the category of the categorization rules, or else the default category of their sign, unless they
+// have a category of their own that exists and allows their sign. It returns all the entry errors together, like
+// readStatementCSV.
+func (t *statementTarget) newTransactions(entries []*statements.Entry) ([]*models.TransactionDTO, []error) {
+	var transactions []*models.TransactionDTO
+	var entryErrs []error
+
+	for _, entry := range entries {
+		body := &createTransactionBody{
+			Amount:    entry.Amount,
+			Timestamp: entry.Timestamp,
+			AccountID: t.accountID,
+			Notes:     entry.Notes,
+		}
+		if t.categories.allows(entry.Category, entry.Amount) {
+			body.Category = entry.Category
+		}
+
+		applyRules(t.engine, body, t.categories)
+		if body.Category == "" {
+			body.Category = t.creditCategory
+			if entry.Amount < 0 {
+				body.Category = t.debitCategory
+			}
+		}
+
+		transaction, err := prepareNewTransaction(body, t.categories)
+		if err == nil {
+			err = checkAmountPrecision(transaction.Amount, t.currency)
+		}
+		if err != nil {
+			entryErrs = append(entryErrs, fmt.Errorf("entry %d: %w", entry.Number, err))
+			continue
+		}
+
+		transaction.ExternalID = entry.ExternalID
+		transactions = append(transactions, transaction)
+	}
+
+	return transactions, entryErrs
+}
+
+// skipImportedTransactions removes the transactions whose external IDs were already imported into their accounts, or
+// repeat within the statement. It also provides the number of the removed transactions.
+func (h *Handler) skipImportedTransactions(ctx context.Context,
+	transactions []*models.TransactionDTO) ([]*models.TransactionDTO, int, error) {
+	// imported holds the external IDs of every account that has imported transactions, keyed by the account ID.
+	imported := map[string]map[string]bool{}
+	for _, transaction := range transactions {
+		if transaction.ExternalID == "" || imported[transaction.AccountID] != nil {
+			continue
+		}
+
+		// Database call. Only the transactions with an external ID are required.
+		existing, _, err := h.transactions.ListTransactions(ctx, &database.ListTransactionsParams{
+			Filter:         msi{"account_id": transaction.AccountID, "external_id": msi{"$gt": ""}},
+			RequiredFields: []string{"external_id"},
+			ExcludeCount:   true,
+		})
+		if err != nil {
+			return nil, 0, err
+		}
+
+		externalIDs := make(map[string]bool, len(existing))
+		for _, tx := range existing {
+			externalIDs[tx.ExternalID] = true
+		}
+		imported[transaction.AccountID] = externalIDs
+	}
+
+	kept := make([]*models.TransactionDTO, 0, len(transactions))
+	for _, transaction := range transactions {
+		if transaction.ExternalID == "" {
+			kept = append(kept, transaction)
+			continue
+		}
+
+		externalIDs := imported[transaction.AccountID]
+		if externalIDs[transaction.ExternalID] {
+			continue
+		}
+		externalIDs[transaction.ExternalID] = true
+		kept = append(kept, transaction)
+	}
+
+	return kept, len(transactions) - len(kept), nil
+}
+
+// statementImport is the outcome of reading a bank statement file, or a journal, which completeImport responds with.
+type statementImport struct {
+	// transactions are the valid transactions of the statement.
+	transactions []*models.TransactionDTO
+	// rowErrs are the errors of the invalid rows or entries of the statement.
+	rowErrs []error
+	// skipped is the number of the valid transactions that were left out, as they were already imported.
+	skipped int
+	// balances are the balances that the statement reports. They are nil for the formats that report none.
+	balances *statements.Balances
+	// balanceCheck is the reconciliation of the reported balances with the ledger. It is nil if there are no balances.
+	balanceCheck *balanceCheck
+	// duplicateIDs are the IDs of the existing transactions that look like duplicates of the transactions, as
+	// provided by findDuplicates.
+	duplicateIDs [][]string
+	// allowDuplicates saves the transactions that look like duplicates too.
+	allowDuplicates bool
+	// newAccounts are the accounts that a journal creates, which are saved before the transactions. They are nil for
+	// the bank statements, which are imported into the existing accounts.
+	newAccounts []*models.AccountDTO
+	// untranslated describe the directives of a journal that could not be translated. They are reported only along
+	// with the newAccounts.
+	untranslated []string
+}
+
+// importDuplicate is a transaction of an import that looks like a duplicate of the existing transactions.
+type importDuplicate struct {
+	// Index of the transaction in the previewed transactions.
+	Index int `json:"index"`
+	// DuplicateIDs are the IDs of the existing transactions that it looks like.
+	DuplicateIDs []string `json:"duplicate_ids"`
+}
+
+// completeImport provides the response of the import of a bank statement.
+//
+// A dry run previews the transactions along with the row errors. Otherwise, all the transactions are saved atomically,
+// but only if there are no row errors, and no transactions that look like duplicates unless they are allowed. Both
+// report the number of the skipped transactions, which were already imported, and the balance check, if the statement
+// reports balances. The imports of journals also report the accounts that they create and their untranslated
+// directives.
+func (h *Handler) completeImport(ctx context.Context, imported *statementImport,
+	dryRun bool) (*httputils.ResponseDTO, error) {
+	transactions := imported.transactions
+
+	if dryRun {
+		// Errors are not marshalled to JSON by themselves, so they are previewed as their messages.
+		errMessages := make([]string, len(imported.rowErrs))
+		for idx, err := range imported.rowErrs {
+			errMessages[idx] = err.Error()
+		}
+		if transactions == nil {
+			transactions = []*models.TransactionDTO{}
+		}
+
+		duplicates := []*importDuplicate{}
+		for idx, ids := range imported.duplicateIDs {
+			if len(ids) > 0 {
+				duplicates = append(duplicates, &importDuplicate{Index: idx, DuplicateIDs: ids})
+			}
+		}
+
+		data := map[string]interface{}{
+			"transactions": transactions,
+			"errors":       errMessages,
+			"skipped":      imported.skipped,
+			"duplicates":   duplicates,
+		}
+		if imported.balanceCheck != nil {
+			data["balance_check"] = imported.balanceCheck
+		}
+		if imported.newAccounts != nil {
+			data["accounts"] = imported.newAccounts
+			data["untranslated"] = imported.untranslated
+		}
+
+		return &httputils.ResponseDTO{
+			Status: http.StatusOK,
+			Body: &httputils.ResponseBodyDTO{
+				StatusCode: http.StatusOK,
+				CustomCode: "IMPORT_PREVIEWED",
+				Data:       data,
+			},
+		}, nil
+	}
+
+	if len(imported.rowErrs) > 0 {
+		return nil, errutils.BadRequest().AddErrors(imported.rowErrs...)
+	}
+	if len(transactions) == 0 && imported.skipped == 0 {
+		return nil, errutils.BadRequest().AddErrors(errEmptyStatement)
+	}
+	if hasDuplicates(imported.duplicateIDs) && !imported.allowDuplicates {
+		return nil, duplicateTransactionError(imported.duplicateIDs, func(idx int) string {
+			return fmt.Sprintf("transaction at index %d", idx)
+		})
+	}
+
+	// The accounts are required by the transactions.
+	var accountIDs []string
+	for _, account := range imported.newAccounts {
+		// Database call.
+		if err := h.accounts.InsertAccount(ctx, account); err != nil {
+			return nil, err
+		}
+		accountIDs = append(accountIDs, account.ID)
+	}
+
+	// Re-importing a statement that was fully imported already is not an error, but there is nothing to save.
+	ids := []string{}
+	if len(transactions) > 0 {
+		// Database call.
+		var err error
+		if ids, err = h.transactions.InsertTransactions(ctx, transactions); err != nil {
+			return nil, err
+		}
+	}
+
+	data := map[string]interface{}{"ids": ids, "skipped": imported.skipped}
+	if imported.balanceCheck != nil {
+		data["balance_check"] = imported.balanceCheck
+	}
+	if imported.newAccounts != nil {
+		data["account_ids"] = append([]string{}, accountIDs...)
+		data["untranslated"] = imported.untranslated
+	}
+
+	return &httputils.ResponseDTO{
+		Status: http.StatusCreated,
+		Body: &httputils.ResponseBodyDTO{
+			StatusCode: http.StatusCreated,
+			CustomCode: "TRANSACTIONS_IMPORTED",
+			Data:       data,
+		},
+	}, nil
+}
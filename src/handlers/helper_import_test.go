@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/shivanshkc/ledgerkeep/src/models"
+	"github.com/shivanshkc/ledgerkeep/src/rules"
+)
+
+// testCategories are the categories that the tests of the imports validate against.
+var testCategories = categorySet{
+	"earnings":   {ID: "earnings", Kind: models.CategoryKindCredit, BudgetGroup: models.BudgetGroupIncome},
+	"essentials": {ID: "essentials", Kind: models.CategoryKindDebit, BudgetGroup: models.BudgetGroupEssentials},
+	"ignorable":  {ID: "ignorable", Kind: models.CategoryKindBoth, BudgetGroup: models.BudgetGroupIgnorable},
+}
+
+func TestPrepareImportProfile(t *testing.T) {
+	profile := &models.ImportProfileDTO{
+		Name: "Bank", AccountID: "bank", DateColumn: " Date ", AmountColumn: "Amount", CategoryColumn: "Category",
+	}
+	if err := prepareImportProfile(profile, testCategories); err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+	if profile.Delimiter != "," || profile.DecimalSeparator != "." || profile.DateFormat != "2006-01-02" ||
+		profile.DateColumn != "Date" {
+		t.Fatalf("unexpected profile: %+v", profile)
+	}
+
+	invalidProfiles := []*models.ImportProfileDTO{
+		{Name: "Bank", AccountID: "bank", AmountColumn: "Amount", CategoryColumn: "Category"},
+		{Name: "Bank", AccountID: "bank", DateColumn: "Date", CategoryColumn: "Category"},
+		{Name: "Bank", AccountID: "bank", DateColumn: "Date", AmountColumn: "Amount", DebitColumn: "Debit", CategoryColumn: "Category"},
+		{Name: "Bank", AccountID: "bank", DateColumn: "Date", AmountColumn: "Amount"},
+		{Name: "Bank", AccountID: "bank", DateColumn: "Date", AmountColumn: "Amount", CreditCategory: "essentials", DebitCategory: "essentials"},
+		{Name: "Bank", AccountID: "bank", DateColumn: "Date", AmountColumn: "Amount", CategoryColumn: "Category", Delimiter: "\""},
+		{Name: "Bank", AccountID: "bank", DateColumn: "Date", AmountColumn: "Amount", CategoryColumn: "Category", DecimalSeparator: "'"},
+		{Name: "Bank", AccountID: "bank", DateColumn: "Date", AmountColumn: "Amount", CategoryColumn: "Category", DateFormat: "day"},
+	}
+	for idx, invalidProfile := range invalidProfiles {
+		if err := prepareImportProfile(invalidProfile, testCategories); err == nil {
+			t.Errorf("expected an error for profile at index %d", idx)
+		}
+	}
+}
+
+func TestReadStatementCSV(t *testing.T) {
+	profile := &models.ImportProfileDTO{
+		AccountID: "bank", Delimiter: ",", DecimalSeparator: ".", DateColumn: "Date", DateFormat: "01/02/2006",
+		AmountColumn: "Amount", NotesColumn: "Memo", CategoryColumn: "Category",
+		CreditCategory: "earnings", DebitCategory: "essentials",
+	}
+
+	statement := "Date,Memo,Amount,Category\n" +
+		"03/01/2022,Salary,\"1,000.00\",\n" +
+		"03/02/2022,Parking,-5.5,Ignorable\n" +
+		"03/03/2022,Zero,0,\n" +
+		"03/04/2022,Too precise,-1.005,\n" +
+		"03/05/2022,Wrong kind,-10,earnings\n" +
+		"03/06/2022,Bus ticket,-2,\n"
+
+	// The category of the rule takes the place of the default category, but not of the category of the row.
+	engine, err := rules.NewEngine([]*models.RuleDTO{
+		{ID: "transport", NotesPattern: "(?i)bus|parking", SetCategory: "ignorable", AddTags: models.Tags{"transport"}},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error in NewEngine: %+v", err)
+	}
+
+	transactions, rowErrs := readStatementCSV(strings.NewReader(statement), profile, testCategories, "USD", engine)
+	if len(transactions) != 3 || transactions[0].Amount != 10000000 || transactions[0].Category != "earnings" ||
+		transactions[1].Amount != -55000 || transactions[1].Category != "ignorable" || transactions[1].Notes != "Parking" ||
+		transactions[2].Category != "ignorable" {
+		t.Fatalf("unexpected transactions: %+v", transactions)
+	}
+	if len(transactions[0].Tags) != 0 || len(transactions[1].Tags) != 1 || transactions[2].Tags[0] != "transport" {
+		t.Fatalf("unexpected tags: %+v, %+v, %+v", transactions[0].Tags, transactions[1].Tags, transactions[2].Tags)
+	}
+	if len(rowErrs) != 3 || !strings.HasPrefix(rowErrs[0].Error(), "row 4:") || !strings.HasPrefix(rowErrs[2].Error(), "row 6:") {
+		t.Fatalf("unexpected row errors: %+v", rowErrs)
+	}
+
+	// A column of the profile that is missing from the file fails the whole file.
+	_, rowErrs = readStatementCSV(strings.NewReader("Date,Amount\n03/01/2022,10\n"), profile, testCategories, "USD",
+		engine)
+	if len(rowErrs) != 1 || !strings.Contains(rowErrs[0].Error(), "Memo") {
+		t.Fatalf("unexpected row errors: %+v", rowErrs)
+	}
+}
@@ -0,0 +1,334 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/shivanshkc/ledgerkeep/src/configs"
+	"github.com/shivanshkc/ledgerkeep/src/journals"
+	"github.com/shivanshkc/ledgerkeep/src/models"
+	"github.com/shivanshkc/ledgerkeep/src/utils/errutils"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// journalAccountRoots are the roots of the journal accounts that become the accounts of the ledger. The postings to
+// all the other accounts are mapped onto categories.
+var journalAccountRoots = []string{"Assets", "Liabilities"}
+
+// journalCurrencySymbols maps the currency symbols that the ledger-cli journals commonly use to their ISO 4217 codes.
+var journalCurrencySymbols = map[string]string{"$": "USD", "€": "EUR", "£": "GBP", "₹": "INR", "¥": "JPY"}
+
+// journalInvalidCharsRegexp matches the characters that the account IDs and the tags cannot have.
+var journalInvalidCharsRegexp = regexp.MustCompile(`[^a-z0-9-_]+`)
+
+// journalInvalidNameCharsRegexp matches the characters that the account names cannot have.
+var journalInvalidNameCharsRegexp = regexp.MustCompile(`[^a-zA-Z0-9-_ ]+`)
+
+// journalTranslator translates the transactions of a journal into the transactions of the ledger.
+type journalTranslator struct {
+	// mapping maps the journal accounts, or their parents, to the categories of the ledger.
+	mapping    map[string]string
+	categories categorySet
+	// existing holds the accounts of the ledger, keyed by their IDs.
+	existing map[string]*models.AccountDTO
+	// accounts holds the ledger accounts of the journal accounts that were translated, keyed by the journal accounts.
+	accounts map[string]*models.AccountDTO
+	// newAccounts are the accounts that do not exist yet, in the order of their first use.
+	newAccounts []*models.AccountDTO
+}
+
+// readJournalTranslator reads and validates the "mapping" form field of a journal import request. It is a JSON object
+// of the journal accounts to the IDs of the categories, like {"Expenses:Food": "essentials"}.
+func (h *Handler) readJournalTranslator(ctx context.Context, request *http.Request) (*journalTranslator, error) {
+	translator := &journalTranslator{
+		mapping:  map[string]string{},
+		existing: map[string]*models.AccountDTO{},
+		accounts: map[string]*models.AccountDTO{},
+	}
+
+	if value := request.FormValue("mapping"); value != "" {
+		if err := json.Unmarshal([]byte(value), &translator.mapping); err != nil {
+			return nil, errutils.BadRequest().AddErrors(errInvalidJournalMapping)
+		}
+	}
+
+	// The categories are required to validate the mapping and the transactions.
+	categories, err := h.getCategorySet(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for account, category := range translator.mapping {
+		if _, exists := categories[strings.ToLower(category)]; !exists {
+			return nil, errutils.BadRequest().AddErrors(errInvalidJournalMapping,
+				fmt.Errorf("category %q of account %q does not exist", category, account))
+		}
+	}
+	translator.categories = categories
+
+	// The existing accounts are reused by the journal accounts with the same IDs.
+	accounts, err := h.accounts.ListAccounts(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, account := range accounts {
+		translator.existing[account.ID] = account
+	}
+
+	return translator, nil
+}
+
+// translate translates the transactions of the journal. It also creates the accounts of the journal accounts that are
+// declared with a currency, even if they have no transactions.
+//
+// It returns all the errors together, like readStatementCSV. The errors of the transactions start with their line
+// numbers.
+func (t *journalTranslator) translate(journal *journals.Journal) ([]*models.TransactionDTO, []error) {
+	var transactions []*models.TransactionDTO
+	var errs []error
+
+	// The declared accounts are created first, so their currencies take precedence over those of the postings.
+	declared := make([]string, 0, len(journal.Currencies))
+	for name := range journal.Currencies {
+		if isJournalAccount(name) {
+			declared = append(declared, name)
+		}
+	}
+	sort.Strings(declared)
+
+	for _, name := range declared {
+		if _, err := t.getAccount(name, journal.Currencies[name]); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	for _, transaction := range journal.Transactions {
+		translated, err := t.translateTransaction(transaction)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("line %d: %w", transaction.Line, err))
+			continue
+		}
+		transactions = append(transactions, translated...)
+	}
+
+	return transactions, errs
+}
+
+// translateTransaction translates a journal transaction. A transaction between an account and categories becomes a
+// transaction, which is split if it has several categories. A transaction between two accounts becomes the two legs of
+// a transfer.
+func (t *journalTranslator) translateTransaction(transaction *journals.Transaction) ([]*models.TransactionDTO, error) {
+	var accountPostings, categoryPostings []*journals.Posting
+	for _, posting := range transaction.Postings {
+		if isJournalAccount(posting.Account) {
+			accountPostings = append(accountPostings, posting)
+		} else {
+			categoryPostings = append(categoryPostings, posting)
+		}
+	}
+
+	var tags []string
+	for _, tag := range transaction.Tags {
+		if tag = strings.Trim(journalInvalidCharsRegexp.ReplaceAllString(strings.ToLower(tag), "-"), "-"); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+
+	switch {
+	case len(accountPostings) == 2 && len(categoryPostings) == 0:
+		return t.translateTransfer(transaction, accountPostings, tags)
+	case len(accountPostings) != 1:
+		return nil, errJournalPostings
+	}
+
+	posting := accountPostings[0]
+	account, err := t.getAccount(posting.Account, posting.Commodity)
+	if err != nil {
+		return nil, err
+	}
+
+	body := &createTransactionBody{
+		Amount:    posting.Amount,
+		Timestamp: transaction.Timestamp,
+		AccountID: account.ID,
+		Notes:     transaction.Notes(),
+		Tags:      tags,
+	}
+
+	// The postings to the same category are added up. The amounts of the categories are in the currency of the
+	// account only if the postings have no prices, which matters only for the splits, as the account posting provides
+	// the amount otherwise.
+	splits := map[string]*models.SplitDTO{}
+	hasPrices := false
+	for _, categoryPosting := range categoryPostings {
+		category := t.getCategory(categoryPosting.Account)
+		if category == "" {
+			return nil, fmt.Errorf("account %s is not mapped to a category", categoryPosting.Account)
+		}
+
+		split, exists := splits[category]
+		if !exists {
+			split = &models.SplitDTO{Category: category}
+			splits[category] = split
+			body.Splits = append(body.Splits, split)
+		}
+
+		hasPrices = hasPrices || (categoryPosting.Commodity != "" &&
+			journalCurrency(categoryPosting.Commodity) != getAccountCurrency(account))
+		split.Amount -= categoryPosting.Amount
+		split.Notes = strings.TrimSpace(split.Notes + " " + categoryPosting.Comment)
+	}
+
+	if len(body.Splits) == 1 {
+		body.Category, body.Splits = body.Splits[0].Category, nil
+	}
+	if hasPrices && len(body.Splits) > 0 {
+		return nil, errJournalSplitCurrency
+	}
+
+	translated, err := prepareNewTransaction(body, t.categories)
+	if err == nil {
+		err = checkAmountPrecision(translated.Amount, getAccountCurrency(account))
+	}
+	if err != nil {
+		return nil, err
+	}
+	return []*models.TransactionDTO{translated}, nil
+}
+
+// translateTransfer translates a journal transaction between two accounts into the legs of a transfer.
+func (t *journalTranslator) translateTransfer(transaction *journals.Transaction, postings []*journals.Posting,
+	tags []string) ([]*models.TransactionDTO, error) {
+	preparedTags, err := prepareTags(tags)
+	if err != nil {
+		return nil, err
+	}
+
+	// Transfer IDs are ObjectIDs, just like transaction IDs.
+	transferID := primitive.NewObjectID().Hex()
+	legs := make([]*models.TransactionDTO, len(postings))
+
+	for idx, posting := range postings {
+		account, err := t.getAccount(posting.Account, posting.Commodity)
+		if err != nil {
+			return nil, err
+		}
+		if err := checkAmountPrecision(posting.Amount, getAccountCurrency(account)); err != nil {
+			return nil, err
+		}
+
+		legs[idx] = &models.TransactionDTO{
+			Amount:     posting.Amount,
+			Timestamp:  transaction.Timestamp,
+			AccountID:  account.ID,
+			Category:   categoryTransfer,
+			Notes:      transaction.Notes(),
+			Tags:       preparedTags,
+			TransferID: transferID,
+		}
+	}
+
+	// The money should leave one account and arrive in the other.
+	if legs[0].AccountID == legs[1].AccountID || legs[0].Amount == 0 || (legs[0].Amount > 0) == (legs[1].Amount > 0) {
+		return nil, errJournalTransfer
+	}
+	return legs, nil
+}
+
+// getAccount provides the ledger account of the journal account, and checks that the commodity of its posting is its
+// currency. The account is created if it does not exist yet, with the currency of the commodity.
+func (t *journalTranslator) getAccount(name string, commodity string) (*models.AccountDTO, error) {
+	account, exists := t.accounts[name]
+	if !exists {
+		id := journalAccountID(name)
+		if account, exists = t.existing[id]; !exists {
+			currency, err := parseCurrency(journalCurrency(commodity))
+			if err != nil {
+				return nil, fmt.Errorf("commodity %q of account %s is not a currency", commodity, name)
+			}
+
+			account = &models.AccountDTO{ID: id, Name: journalAccountName(name), Currency: currency}
+			if !accountIDRegexp.MatchString(account.ID) {
+				return nil, fmt.Errorf("account %s: %w", name, errInvalidAccountID)
+			}
+			t.newAccounts = append(t.newAccounts, account)
+			t.existing[id] = account
+		}
+		t.accounts[name] = account
+	}
+
+	// The amounts without a commodity are taken to be in the currency of the account.
+	if commodity != "" && journalCurrency(commodity) != getAccountCurrency(account) {
+		return nil, fmt.Errorf("commodity %q of account %s should be its currency %s", commodity, name,
+			getAccountCurrency(account))
+	}
+	return account, nil
+}
+
+// getCategory provides the category that the journal account is mapped to. The mapping of the account itself takes
+// precedence over those of its parents, and those of the closer parents over the farther ones. It is empty if neither
+// the account nor its parents are mapped.
+func (t *journalTranslator) getCategory(name string) string {
+	for {
+		if category, exists := t.mapping[name]; exists {
+			return strings.ToLower(category)
+		}
+
+		idx := strings.LastIndex(name, ":")
+		if idx < 0 {
+			return ""
+		}
+		name = name[:idx]
+	}
+}
+
+// isJournalAccount checks if the journal account is one of the accounts of the ledger, rather than a category.
+func isJournalAccount(name string) bool {
+	root := strings.SplitN(name, ":", 2)[0]
+	return stringPresentCaseInsensitive(root, journalAccountRoots)
+}
+
+// journalAccountID provides the ID of the ledger account of a journal account, which is its full name in lower case,
+// like "assets-bank-checking" for "Assets:Bank:Checking".
+func journalAccountID(name string) string {
+	components := strings.Split(strings.ToLower(name), ":")
+	for idx, component := range components {
+		components[idx] = strings.Trim(journalInvalidCharsRegexp.ReplaceAllString(component, "-"), "-")
+	}
+	return strings.Join(components, "-")
+}
+
+// journalAccountName provides the name of the ledger account of a journal account, which is its name without the root,
+// like "Bank Checking" for "Assets:Bank:Checking".
+func journalAccountName(name string) string {
+	components := strings.Split(name, ":")
+	if len(components) > 1 {
+		components = components[1:]
+	}
+
+	var words []string
+	for _, component := range components {
+		words = append(words, strings.Fields(journalInvalidNameCharsRegexp.ReplaceAllString(component, " "))...)
+	}
+	if len(words) == 0 {
+		return "Account"
+	}
+	return strings.Join(words, " ")
+}
+
+// journalCurrency provides the currency of a journal commodity, whose symbols are replaced by their codes. The amounts
+// without a commodity are in the default currency.
+func journalCurrency(commodity string) string {
+	if commodity == "" {
+		return configs.Get().Currency.Default
+	}
+	if code, exists := journalCurrencySymbols[commodity]; exists {
+		return code
+	}
+	return strings.ToUpper(commodity)
+}
@@ -4,6 +4,8 @@ import (
 	"errors"
 	"fmt"
 	"regexp"
+
+	"github.com/shivanshkc/ledgerkeep/src/models"
 )
 
 const (
@@ -11,26 +13,62 @@ const (
 	defaultSkip  = 0
 )
 
+// These are the length limits of the passwords of the users, in bytes. bcrypt ignores anything after 72 bytes.
+const (
+	minPasswordLength = 8
+	maxPasswordLength = 72
+)
+
+// maxExchangeRatesFileSize is the maximum size of an exchange rates CSV file in bytes.
+const maxExchangeRatesFileSize = 10 << 20
+
+// maxStatementFileSize is the maximum size of an imported bank statement file in bytes.
+const maxStatementFileSize = 10 << 20
+
+// These are the file formats of the bank statements that can be imported.
 const (
-	categoryEssentials  = "essentials"
-	categoryInvestments = "investments"
-	categorySavings     = "savings"
-	categoryLuxury      = "luxury"
+	importFormatCSV = "csv"
+	importFormatOFX = "ofx"
+	// importFormatQFX is the OFX format as written by Quicken.
+	importFormatQFX = "qfx"
+	importFormatQIF = "qif"
+	// importFormatCamt053 is the ISO 20022 bank to customer statement.
+	importFormatCamt053 = "camt053"
+	// importFormatMT940 is the SWIFT customer statement.
+	importFormatMT940 = "mt940"
+)
 
-	categoryEarnings = "earnings"
-	categoryRefunds  = "refunds"
-	categoryReturns  = "returns"
-	categoryPetty    = "petty"
+// These are the file formats that the transactions can be exported to.
+const (
+	exportFormatCSV = "csv"
+	// exportFormatJSONL is JSON Lines, which is a JSON object per line.
+	exportFormatJSONL = "jsonl"
+	exportFormatXLSX  = "xlsx"
+)
 
-	categoryIgnorable = "ignorable"
+// These are the syntaxes of the plain-text accounting journals that the ledger can be exported to and imported from.
+const (
+	journalFormatLedger    = "ledger"
+	journalFormatHLedger   = "hledger"
+	journalFormatBeancount = "beancount"
 )
 
 const (
-	essentialsContrib  = 0.4
-	investmentsContrib = 0.2
-	savingsContrib     = 0.2
-	luxuryContrib      = 0.2
-	ignorableContrib   = 0.0
+	// categorySplit is reserved for transactions with split lines, which have their own categories.
+	categorySplit = "split"
+	// categoryTransfer is reserved for the legs of transfers between accounts.
+	// Transfers are neither income nor expense, so they are left out of budgets.
+	categoryTransfer = "transfer"
+)
+
+// These are the expected contributions of the budget groups to the budget, in percentages of the total income.
+// They are used when no budget plan is in effect.
+const (
+	essentialsContrib  = 40
+	investmentsContrib = 20
+	savingsContrib     = 20
+	luxuryContrib      = 20
+	ignorableContrib   = 0
 )
 
 // TODO: Apply max and min length validations for all possible fields.
@@ -39,10 +77,61 @@ var (
 	accountIDRegexp   = regexp.MustCompile("^[a-zA-Z0-9-_]+$")
 	accountNameRegexp = regexp.MustCompile("^[a-zA-Z0-9-_ ]+$")
 
-	// allowedDebitCategories are the only categories that debit transactions can have.
-	allowedDebitCategories = []string{categoryEssentials, categoryInvestments, categorySavings, categoryLuxury, categoryIgnorable}
-	// allowedCreditCategories are the only categories that credit transactions can have.
-	allowedCreditCategories = []string{categoryEarnings, categoryRefunds, categoryReturns, categoryPetty, categoryIgnorable}
+	categoryIDRegexp   = regexp.MustCompile("^[a-z0-9-_]+$")
+	categoryNameRegexp = regexp.MustCompile("^[a-zA-Z0-9-_ ]+$")
+
+	tagRegexp = regexp.MustCompile("^[a-z0-9-_]+$")
+
+	// reservedCategories cannot be created, updated or deleted by the users.
+	reservedCategories = []string{categorySplit, categoryTransfer}
+	// allowedCategoryKinds are the kinds that a category can have.
+	allowedCategoryKinds = []string{models.CategoryKindCredit, models.CategoryKindDebit, models.CategoryKindBoth}
+	// allowedBudgetGroups are the budget groups that a category can belong to.
+	allowedBudgetGroups = []string{
+		models.BudgetGroupIncome,
+		models.BudgetGroupEssentials,
+		models.BudgetGroupInvestments,
+		models.BudgetGroupSavings,
+		models.BudgetGroupLuxury,
+		models.BudgetGroupIgnorable,
+	}
+
+	budgetPlanNameRegexp = regexp.MustCompile("^[a-zA-Z0-9-_ ]+$")
+
+	recurringNameRegexp = regexp.MustCompile("^[a-zA-Z0-9-_ ]+$")
+
+	importProfileNameRegexp = regexp.MustCompile("^[a-zA-Z0-9-_ ]+$")
+
+	ruleNameRegexp = regexp.MustCompile("^[a-zA-Z0-9-_ ]+$")
+
+	// userIDRegexp leaves out the colon, which cannot be a part of a basic auth username.
+	userIDRegexp = regexp.MustCompile("^[a-zA-Z0-9-_.@]+$")
+
+	tokenNameRegexp = regexp.MustCompile("^[a-zA-Z0-9-_ ]+$")
+
+	// allowedMemberRoles are the roles that the members of a shared ledger can have.
+	allowedMemberRoles = []string{models.RoleOwner, models.RoleEditor, models.RoleViewer}
+	// allowedDecimalSeparators are the decimal separators that the amounts of a statement can have.
+	allowedDecimalSeparators = []string{".", ","}
+	// allowedImportFormats are the file formats of the bank statements that can be imported.
+	allowedImportFormats = []string{
+		importFormatCSV, importFormatOFX, importFormatQFX, importFormatQIF, importFormatCamt053, importFormatMT940,
+	}
+	// allowedExportFormats are the file formats that the transactions can be exported to.
+	allowedExportFormats = []string{exportFormatCSV, exportFormatJSONL, exportFormatXLSX}
+	// allowedJournalFormats are the syntaxes of the plain-text accounting journals that the ledger can be exported to
+	// and imported from.
+	allowedJournalFormats = []string{journalFormatLedger, journalFormatHLedger, journalFormatBeancount}
+
+	// allowedAllocationGroups are the budget groups that can have an allocation in a budget plan.
+	// The income is what the allocations divide, so it cannot have one.
+	allowedAllocationGroups = []string{
+		models.BudgetGroupEssentials,
+		models.BudgetGroupInvestments,
+		models.BudgetGroupSavings,
+		models.BudgetGroupLuxury,
+		models.BudgetGroupIgnorable,
+	}
 
 	// allowedTransactionSortFields is the list of transaction field names that can be used for sorting.
 	allowedTransactionSortFields = []string{"amount", "timestamp", "category"}
@@ -63,10 +152,113 @@ var (
 	errInvalidTxAmount        = errors.New("amount should be non-zero")
 	errAmountCategoryMismatch = errors.New("amount not compatible with current category")
 	errInvalidTxTimestamp     = fmt.Errorf("timestamp must be valid epoch seconds")
-	errInvalidTxCategory      = fmt.Errorf("allowed categories for debits: %s, and for credits: %s", allowedDebitCategories, allowedCreditCategories)
+	errInvalidTxCategory      = errors.New("category should exist and its kind should allow the sign of the amount")
+	errInvalidTag             = fmt.Errorf("tags should satisfy regex: %s", tagRegexp.String())
+
+	errInvalidCategoryID   = fmt.Errorf("category id should satisfy regex: %s", categoryIDRegexp.String())
+	errInvalidCategoryName = fmt.Errorf("category name should satisfy regex: %s", categoryNameRegexp.String())
+	errInvalidCategoryKind = fmt.Errorf("category kind should be one of: %+v", allowedCategoryKinds)
+	errInvalidBudgetGroup  = fmt.Errorf("budget group should be one of: %+v", allowedBudgetGroups)
+	errReservedCategoryID  = fmt.Errorf("categories %+v are reserved", reservedCategories)
+	errCategoryKindInUse   = fmt.Errorf("kind of a category in use can only be changed to %s", models.CategoryKindBoth)
+
+	errTooFewSplits          = errors.New("splits should have at least 2 lines")
+	errInvalidSplitAmount    = errors.New("amounts of splits should be non-zero and have the same sign as the amount")
+	errSplitsSumMismatch     = errors.New("amounts of splits should add up to the amount")
+	errSplitCategory         = fmt.Errorf("category of a transaction with splits should be empty or %s", categorySplit)
+	errSplitsRemovalCategory = errors.New("category should be provided when the splits are removed")
+	errTransferSplits        = errors.New("a transfer transaction cannot have splits")
+
+	errInvalidTransferAmount    = errors.New("amount should be positive")
+	errInvalidTransferToAmount  = errors.New("to_amount should be positive")
+	errTransferToAmountRequired = errors.New("to_amount should be provided for transfers between different currencies")
+	errTransferToAmountMismatch = errors.New("to_amount should be equal to amount for transfers in the same currency")
+	errTransferSameAccount      = errors.New("a transfer should be between two different accounts")
+	errTransferCategoryUpdate   = errors.New("category of a transfer transaction cannot be updated")
+	errTransferAmountSign       = errors.New("amount of a transfer transaction cannot change its sign")
+
+	errInvalidCurrency          = errors.New("currency should be an active ISO 4217 currency code")
+	errAccountCurrencyMismatch  = errors.New("a transaction cannot be moved to an account with a different currency")
+	errInvalidExchangeRatePair  = errors.New("base and quote currencies of an exchange rate should be different")
+	errInvalidExchangeRateID    = errors.New("exchange rate id is invalid")
+	errInvalidExchangeRate      = errors.New("rate should be a positive decimal number")
+	errEmptyExchangeRates       = errors.New("at least one exchange rate should be provided")
+	errInvalidExchangeRatesFile = errors.New("file should be a CSV with the columns: base, quote, rate and date or timestamp")
+
+	errInvalidBudgetPlanID       = errors.New("budget plan id is invalid")
+	errInvalidBudgetPlanName     = fmt.Errorf("budget plan name should satisfy regex: %s", budgetPlanNameRegexp.String())
+	errInvalidBudgetPlanValidity = errors.New("valid_from should be non-negative and valid_until should be zero or after it")
+	errBudgetPlanOverlap         = errors.New("validity of a budget plan should not overlap with that of another plan")
+	errInvalidAllocationGroup    = fmt.Errorf("budget group of an allocation should be one of: %+v", allowedAllocationGroups)
+	errDuplicateAllocationGroup  = errors.New("a budget group should have at most one allocation")
+	errInvalidAllocation         = errors.New("an allocation should have exactly one of percent and amount")
+	errInvalidAllocationPercent  = errors.New("percent of an allocation should be between 0 and 100 inclusive")
+	errInvalidAllocationAmount   = errors.New("amount of an allocation should be non-negative")
+	errAllocationPercentTotal    = errors.New("percents of the allocations should add up to at most 100")
+
+	errInvalidRecurringID     = errors.New("recurring template id is invalid")
+	errInvalidRecurringName   = fmt.Errorf("recurring template name should satisfy regex: %s", recurringNameRegexp.String())
+	errInvalidRecurringPeriod = errors.New("start_time should be positive and end_time should be zero or not before it")
+	errInvalidRecurrenceRule  = errors.New("rule should be a valid recurrence rule, for example: FREQ=MONTHLY;BYMONTHDAY=1")
+	errNoRecurringOccurrences = errors.New("recurring template should have at least one occurrence")
+
+	errInvalidImportProfileID   = errors.New("import profile id is invalid")
+	errInvalidImportProfileName = fmt.Errorf("import profile name should satisfy regex: %s", importProfileNameRegexp.String())
+	errInvalidDelimiter         = errors.New("delimiter should be a single character other than a quote or a line break")
+	errInvalidDecimalSeparator  = fmt.Errorf("decimal_separator should be one of: %+v", allowedDecimalSeparators)
+	errMissingDateColumn        = errors.New("date_column should be provided")
+	errInvalidDateFormat        = errors.New("date_format should be a Go time layout, like 02/01/2006")
+	errInvalidAmountColumns     = errors.New("either amount_column or at least one of debit_column and credit_column should be provided")
+	errMissingImportCategories  = errors.New("credit_category and debit_category should be provided if there is no category_column")
+	errInvalidCreditCategory    = errors.New("credit_category should exist and its kind should allow credits")
+	errInvalidDebitCategory     = errors.New("debit_category should exist and its kind should allow debits")
+	errMissingStatementFile     = errors.New("statement should be uploaded as the file field")
+	errInvalidStatementFile     = errors.New("file should be a CSV file with a header row")
+	errInvalidImportFormat      = fmt.Errorf("import format should be one of: %+v", allowedImportFormats)
+	errMissingDefaultCategories = errors.New("credit_category and debit_category should be provided")
+	errEmptyStatement           = errors.New("statement should have at least one transaction")
+	errInvalidDryRun            = errors.New("dry_run should be a boolean")
+	errInvalidStatementDate     = errors.New("date does not match the date_format of the profile")
+	errInvalidStatementAmount   = errors.New("amount should be a decimal number")
+	errMissingStatementAmount   = errors.New("amount is missing")
+
+	errInvalidExportFormat  = fmt.Errorf("format should be one of: %+v", allowedExportFormats)
+	errInvalidJournalFormat = fmt.Errorf("format should be one of: %+v", allowedJournalFormats)
+
+	errMissingJournalFile    = errors.New("journal should be uploaded as the file field")
+	errInvalidJournalMapping = errors.New("mapping should be a JSON object of journal accounts to existing categories")
+	errJournalPostings       = errors.New("transaction should have one asset or liability posting, or two of them for a transfer")
+	errJournalTransfer       = errors.New("transfer should move money out of one account and into another")
+	errJournalSplitCurrency  = errors.New("postings of several categories should be in the currency of the account")
+
+	errMissingBackupFile       = errors.New("backup should be uploaded as the file field")
+	errInvalidOverwrite        = errors.New("overwrite should be a boolean")
+	errInvalidBackupID         = errors.New("id should be a valid ObjectID")
+	errDuplicateBackupID       = errors.New("id should be unique")
+	errDuplicateExchangeRate   = errors.New("exchange rate should be unique for its currency pair and timestamp")
+	errBackupAccountNotFound   = errors.New("account_id should be an account of the backup")
+	errInvalidBackupTransferID = fmt.Errorf("transfer_id should be a valid ObjectID, only if the category is %s",
+		categoryTransfer)
+
+	errInvalidRuleID       = errors.New("rule id is invalid")
+	errInvalidRuleName     = fmt.Errorf("rule name should satisfy regex: %s", ruleNameRegexp.String())
+	errInvalidRuleCategory = errors.New("set_category should be an existing category other than the reserved ones")
+
+	errInvalidStartAmount = fmt.Errorf("start_amount should be a number with at most %d decimal places", models.MoneyDecimalPlaces)
+	errInvalidEndAmount   = fmt.Errorf("end_amount should be a number with at most %d decimal places", models.MoneyDecimalPlaces)
+
+	errInvalidAllowDuplicates = errors.New("allow_duplicates should be a boolean")
+
+	errInvalidUserID   = fmt.Errorf("user id should satisfy regex: %s", userIDRegexp.String())
+	errInvalidPassword = fmt.Errorf("password should be %d to %d bytes long", minPasswordLength, maxPasswordLength)
+
+	errInvalidMemberRole  = fmt.Errorf("role should be one of: %+v", allowedMemberRoles)
+	errMemberIsLedgerUser = errors.New("user_id should not be a user that the ledger belongs to")
 
-	errInvalidStartAmount = errors.New("start_amount should be a float")
-	errInvalidEndAmount   = errors.New("end_amount should be a float")
+	errInvalidTokenID     = errors.New("token id is invalid")
+	errInvalidTokenName   = fmt.Errorf("token name should satisfy regex: %s", tokenNameRegexp.String())
+	errInvalidTokenScopes = fmt.Errorf("scopes should be a non-empty list of: %+v", models.TokenScopes)
+	errInvalidTokenExpiry = errors.New("expires_at should be a future timestamp, or zero for no expiry")
 
 	errInvalidLimit = fmt.Errorf("limit should be a positive int and less than %d inclusive", defaultLimit)
 	errInvalidSkip  = errors.New("skip should be a non-negative int")
@@ -0,0 +1,102 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/shivanshkc/ledgerkeep/src/models"
+	"github.com/shivanshkc/ledgerkeep/src/statements"
+)
+
+// balanceCheck is the reconciliation of the balances that a bank statement reports with the ledger.
+type balanceCheck struct {
+	// StatementOpening is the opening balance of the statement, if it reports one.
+	StatementOpening *models.Money `json:"statement_opening,omitempty"`
+	// StatementClosing is the closing balance of the statement, if it reports one.
+	StatementClosing *models.Money `json:"statement_closing,omitempty"`
+	// LedgerOpening is the balance of the account before the day of the first entry of the statement.
+	LedgerOpening models.Money `json:"ledger_opening"`
+	// LedgerClosing is the balance of the account after the day of the last entry, once the statement is imported.
+	LedgerClosing models.Money `json:"ledger_closing"`
+	// Mismatches describe the balances that do not agree. It is empty if the statement reconciles with the ledger.
+	Mismatches []string `json:"mismatches"`
+}
+
+// checkStatementBalances reconciles the balances that a bank statement reports with the ledger.
+//
+// The statementTransactions are all the valid transactions of the statement, and the newTransactions are those of
+// them that are not imported yet. The mismatches do not keep the statement from being imported, as the ledger may
+// be off for other reasons, like the transactions that were entered by hand.
+func (h *Handler) checkStatementBalances(ctx context.Context, balances *statements.Balances,
+	statementTransactions []*models.TransactionDTO, newTransactions []*models.TransactionDTO) (*balanceCheck, error) {
+	check := &balanceCheck{
+		StatementOpening: balances.Opening,
+		StatementClosing: balances.Closing,
+		Mismatches:       []string{},
+	}
+	// Without the transactions, there is no account or period to reconcile with.
+	if len(statementTransactions) == 0 {
+		return check, nil
+	}
+
+	// The statement covers the whole days of its entries.
+	accountID := statementTransactions[0].AccountID
+	first, last := statementTransactions[0].Timestamp, statementTransactions[0].Timestamp
+	var statementSum models.Money
+	for _, transaction := range statementTransactions {
+		if transaction.Timestamp < first {
+			first = transaction.Timestamp
+		}
+		if transaction.Timestamp > last {
+			last = transaction.Timestamp
+		}
+		statementSum += transaction.Amount
+	}
+	start := time.Unix(first, 0).UTC().Truncate(24 * time.Hour)
+	end := time.Unix(last, 0).UTC().Truncate(24*time.Hour).AddDate(0, 0, 1)
+
+	var err error
+	if check.LedgerOpening, err = h.getAccountBalanceBefore(ctx, accountID, start.Unix()); err != nil {
+		return nil, err
+	}
+	if check.LedgerClosing, err = h.getAccountBalanceBefore(ctx, accountID, end.Unix()); err != nil {
+		return nil, err
+	}
+	for _, transaction := range newTransactions {
+		check.LedgerClosing += transaction.Amount
+	}
+
+	if balances.Opening != nil && balances.Closing != nil && *balances.Opening+statementSum != *balances.Closing {
+		check.Mismatches = append(check.Mismatches, fmt.Sprintf(
+			"opening balance %s and the entries of the statement add up to %s, but its closing balance is %s",
+			balances.Opening, *balances.Opening+statementSum, balances.Closing))
+	}
+	if balances.Opening != nil && *balances.Opening != check.LedgerOpening {
+		check.Mismatches = append(check.Mismatches, fmt.Sprintf(
+			"opening balance of the statement is %s, but the balance of the account before %s is %s",
+			balances.Opening, start.Format("2006-01-02"), check.LedgerOpening))
+	}
+	if balances.Closing != nil && *balances.Closing != check.LedgerClosing {
+		check.Mismatches = append(check.Mismatches, fmt.Sprintf(
+			"closing balance of the statement is %s, but the balance of the account after %s would be %s",
+			balances.Closing, end.AddDate(0, 0, -1).Format("2006-01-02"), check.LedgerClosing))
+	}
+
+	return check, nil
+}
+
+// getAccountBalanceBefore provides the balance of the account as of the provided timestamp, exclusive.
+func (h *Handler) getAccountBalanceBefore(ctx context.Context, accountID string, timestamp int64) (models.Money, error) {
+	// Database call. The sums are aggregated by the storage backend.
+	totals, err := h.transactions.GetCategoryTotals(ctx, msi{"account_id": accountID, "timestamp": msi{"$lt": timestamp}})
+	if err != nil {
+		return 0, err
+	}
+
+	var balance models.Money
+	for _, total := range totals {
+		balance += total.Credit + total.Debit
+	}
+	return balance, nil
+}
@@ -0,0 +1,66 @@
+package handlers
+
+import (
+	"context"
+	"strings"
+
+	"github.com/shivanshkc/ledgerkeep/src/models"
+	"github.com/shivanshkc/ledgerkeep/src/recurrence"
+	"github.com/shivanshkc/ledgerkeep/src/scheduler"
+)
+
+// defaultOccurrencesLimit is the number of occurrences that are previewed if no limit is provided.
+const defaultOccurrencesLimit = 10
+
+// prepareRecurringTemplate validates the recurring template, normalizes its category and sets its first run.
+func prepareRecurringTemplate(template *models.RecurringTemplateDTO, categories categorySet) error {
+	if !recurringNameRegexp.MatchString(template.Name) {
+		return errInvalidRecurringName
+	}
+	if template.Amount == 0 {
+		return errInvalidTxAmount
+	}
+	if !accountIDRegexp.MatchString(template.AccountID) {
+		return errInvalidAccountID
+	}
+
+	if !categories.allows(template.Category, template.Amount) {
+		return errInvalidTxCategory
+	}
+	template.Category = strings.ToLower(template.Category)
+
+	if template.StartTime <= 0 || (template.EndTime != 0 && template.EndTime < template.StartTime) {
+		return errInvalidRecurringPeriod
+	}
+	if _, err := recurrence.Parse(template.Rule); err != nil {
+		return errInvalidRecurrenceRule
+	}
+
+	// The first run is the first occurrence of the template.
+	occurrences, err := scheduler.Occurrences(template, template.StartTime, 0, 1)
+	if err != nil {
+		return errInvalidRecurrenceRule
+	}
+	if len(occurrences) == 0 {
+		return errNoRecurringOccurrences
+	}
+	template.NextRun = occurrences[0]
+
+	return nil
+}
+
+// isUsedByRecurringTemplate checks if any of the recurring templates satisfies the provided function.
+// The recurring templates are not covered by the IsAccountUsed and IsCategoryUsed checks of the repositories.
+func (h *Handler) isUsedByRecurringTemplate(ctx context.Context, uses func(*models.RecurringTemplateDTO) bool) (bool, error) {
+	templates, err := h.recurring.ListRecurringTemplates(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	for _, template := range templates {
+		if uses(template) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
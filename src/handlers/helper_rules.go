@@ -0,0 +1,170 @@
+package handlers
+
+import (
+	"context"
+	"strings"
+
+	"github.com/shivanshkc/ledgerkeep/src/models"
+	"github.com/shivanshkc/ledgerkeep/src/rules"
+)
+
+// prepareRule validates the categorization rule and normalizes its fields.
+// The category of the rule is validated against the provided categorySet.
+func prepareRule(rule *models.RuleDTO, categories categorySet) error {
+	if !ruleNameRegexp.MatchString(rule.Name) {
+		return errInvalidRuleName
+	}
+	if rule.AccountID != "" && !accountIDRegexp.MatchString(rule.AccountID) {
+		return errInvalidAccountID
+	}
+
+	// The reserved categories are assigned by the application only.
+	if rule.SetCategory != "" {
+		rule.SetCategory = strings.ToLower(rule.SetCategory)
+		if _, exists := categories[rule.SetCategory]; !exists ||
+			stringPresentCaseInsensitive(rule.SetCategory, reservedCategories) {
+			return errInvalidRuleCategory
+		}
+	}
+
+	tags, err := prepareTags(rule.AddTags)
+	if err != nil {
+		return err
+	}
+	rule.AddTags = tags
+
+	for idx, weekday := range rule.Weekdays {
+		rule.Weekdays[idx] = strings.ToUpper(weekday)
+	}
+
+	return rules.Validate(rule)
+}
+
+// prepareTags validates the tags and normalizes them to lowercase. It also removes the repeated tags.
+func prepareTags(tags []string) (models.Tags, error) {
+	var prepared models.Tags
+	seen := map[string]bool{}
+
+	for _, tag := range tags {
+		tag = strings.ToLower(tag)
+		if !tagRegexp.MatchString(tag) {
+			return nil, errInvalidTag
+		}
+		if !seen[tag] {
+			seen[tag] = true
+			prepared = append(prepared, tag)
+		}
+	}
+
+	return prepared, nil
+}
+
+// getRuleEngine provides the engine of all the categorization rules, in the order of their priorities.
+func (h *Handler) getRuleEngine(ctx context.Context) (*rules.Engine, error) {
+	ruleList, err := h.rules.ListRules(ctx)
+	if err != nil {
+		return nil, err
+	}
+	// The rules are validated before they are saved, so this fails only if the validation changes.
+	return rules.NewEngine(ruleList)
+}
+
+// applyRules applies the categorization rules to the body of a new transaction, before it is validated.
+//
+// The category of the rules is used only if the body has no category and no splits, so the category that the user
+// chooses always wins. The notes of the rules replace those of the body, and their tags are added to those of the body.
+func applyRules(engine *rules.Engine, body *createTransactionBody, categories categorySet) {
+	result := engine.Evaluate(&models.TransactionDTO{
+		Amount:    body.Amount,
+		Timestamp: body.Timestamp,
+		AccountID: body.AccountID,
+		Notes:     body.Notes,
+	}, categories.allows)
+
+	if body.Category == "" && len(body.Splits) == 0 {
+		body.Category = result.Category
+	}
+	if result.Notes != nil {
+		body.Notes = *result.Notes
+	}
+	body.Tags = mergeTags(body.Tags, result.Tags)
+}
+
+// mergeTags provides the tags along with the added tags that they do not have yet.
+func mergeTags(tags []string, added models.Tags) []string {
+	merged := append([]string(nil), tags...)
+	for _, tag := range added {
+		if !stringPresentCaseInsensitive(tag, merged) {
+			merged = append(merged, tag)
+		}
+	}
+	return merged
+}
+
+// ruleChange is the change that the categorization rules make to an existing transaction.
+type ruleChange struct {
+	// ID of the changed transaction.
+	ID string `json:"id"`
+	// Category is the change of the category, if it changes.
+	Category *valueChange `json:"category,omitempty"`
+	// Notes is the change of the notes, if they change.
+	Notes *valueChange `json:"notes,omitempty"`
+	// Tags is the change of the tags, if they change.
+	Tags *tagsChange `json:"tags,omitempty"`
+	// RuleIDs are the IDs of the rules that match the transaction.
+	RuleIDs []string `json:"rule_ids"`
+}
+
+// valueChange is the old and the new value of a changed field.
+type valueChange struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// tagsChange is the old and the new tags of a transaction.
+type tagsChange struct {
+	From models.Tags `json:"from"`
+	To   models.Tags `json:"to"`
+}
+
+// getRuleChanges evaluates the categorization rules against the existing transactions, and provides the changes that
+// they make, along with the updates that apply those changes, keyed by the transaction IDs.
+//
+// Unlike for the new transactions, the category of the rules replaces the existing category, as re-running the rules
+// is meant to recategorize. The transactions with splits keep their categories, and the legs of transfers are left
+// alone, as their notes are kept in agreement with the other legs.
+func getRuleChanges(engine *rules.Engine, transactions []*models.TransactionDTO,
+	categories categorySet) ([]*ruleChange, map[string]map[string]interface{}) {
+	changes := []*ruleChange{}
+	updates := map[string]map[string]interface{}{}
+
+	for _, transaction := range transactions {
+		if transaction.TransferID != "" {
+			continue
+		}
+
+		result := engine.Evaluate(transaction, categories.allows)
+		change := &ruleChange{ID: transaction.ID, RuleIDs: result.RuleIDs}
+		txUpdates := msi{}
+
+		if result.Category != "" && result.Category != transaction.Category && len(transaction.Splits) == 0 {
+			change.Category = &valueChange{From: transaction.Category, To: result.Category}
+			txUpdates["category"] = result.Category
+		}
+		if result.Notes != nil && *result.Notes != transaction.Notes {
+			change.Notes = &valueChange{From: transaction.Notes, To: *result.Notes}
+			txUpdates["notes"] = *result.Notes
+		}
+		if tags := models.Tags(mergeTags(transaction.Tags, result.Tags)); len(tags) != len(transaction.Tags) {
+			change.Tags = &tagsChange{From: transaction.Tags, To: tags}
+			txUpdates["tags"] = tags
+		}
+
+		if len(txUpdates) > 0 {
+			changes = append(changes, change)
+			updates[transaction.ID] = txUpdates
+		}
+	}
+
+	return changes, updates
+}
@@ -0,0 +1,35 @@
+package handlers
+
+import (
+	"context"
+
+	"github.com/shivanshkc/ledgerkeep/src/models"
+	"github.com/shivanshkc/ledgerkeep/src/utils/ctxutils"
+	"github.com/shivanshkc/ledgerkeep/src/utils/errutils"
+)
+
+// getCurrentUser fetches the user that the request was authenticated as.
+func (h *Handler) getCurrentUser(ctx context.Context) (*models.UserDTO, error) {
+	userID := ctxutils.GetUserID(ctx)
+	if userID == "" {
+		return nil, errutils.Unauthorized()
+	}
+	return h.users.GetUser(ctx, userID)
+}
+
+// requireAdmin returns the Forbidden error if the user that the request was authenticated as is not an admin.
+func (h *Handler) requireAdmin(ctx context.Context) error {
+	user, err := h.getCurrentUser(ctx)
+	if err != nil {
+		return err
+	}
+	if !user.IsAdmin {
+		return errutils.Forbidden()
+	}
+	return nil
+}
+
+// isValidPassword checks if the password can be hashed without losing any of it.
+func isValidPassword(password string) bool {
+	return len(password) >= minPasswordLength && len(password) <= maxPasswordLength
+}
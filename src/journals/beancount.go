@@ -0,0 +1,224 @@
+package journals
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+)
+
+// errInvalidBeancountHeader is returned for the transactions whose first lines are not as per beancount.
+var errInvalidBeancountHeader = errors.New(`transaction should be like: 2022-03-31 * "payee" "narration" #tag`)
+
+// beancountMetadataRegexp matches the metadata lines of the beancount directives, like `id: "42"`. Unlike the account
+// names, the metadata keys start with a lowercase letter.
+var beancountMetadataRegexp = regexp.MustCompile(`^[a-z][a-zA-Z0-9_-]*:(\s|$)`)
+
+// ParseBeancount parses the transactions of a beancount journal.
+//
+// The open directives provide the currencies of the accounts, and the pushtag and poptag directives provide the tags
+// of the transactions between them. All the other directives, like close, balance, pad and price, are reported as
+// untranslated. The metadata and the links of the transactions are not read.
+func ParseBeancount(reader io.Reader) (*Journal, []error) {
+	scanner := bufio.NewScanner(reader)
+	journal := &Journal{Currencies: map[string]string{}}
+
+	var errs []error
+	var pushedTags []string
+
+	// current is the transaction being read, and currentErr is its first error.
+	var current *Transaction
+	var currentErr error
+
+	finishTransaction := func() {
+		if current == nil {
+			return
+		}
+		if currentErr == nil {
+			currentErr = fillElided(current)
+		}
+		if currentErr != nil {
+			errs = append(errs, lineError(current.Line, currentErr))
+		} else {
+			journal.Transactions = append(journal.Transactions, current)
+		}
+		current, currentErr = nil, nil
+	}
+
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if lineNum == 1 {
+			line = strings.TrimPrefix(line, "\ufeff")
+		}
+		trimmed := strings.TrimSpace(line)
+
+		// The indented lines belong to the directive above them. Only those of the transactions are read.
+		if trimmed != "" && (line[0] == ' ' || line[0] == '\t') {
+			if current == nil || currentErr != nil || trimmed[0] == ';' || beancountMetadataRegexp.MatchString(trimmed) {
+				continue
+			}
+			posting, err := parseBeancountPosting(trimmed)
+			if err != nil {
+				currentErr = err
+				continue
+			}
+			current.Postings = append(current.Postings, posting)
+			continue
+		}
+
+		finishTransaction()
+		// The org-mode headings are comments too.
+		if trimmed == "" || trimmed[0] == ';' || trimmed[0] == '*' || trimmed[0] == '#' {
+			continue
+		}
+
+		fields := strings.Fields(trimmed)
+		switch keyword := fields[0]; {
+		case keyword == "pushtag" && len(fields) == 2:
+			pushedTags = addTag(pushedTags, strings.TrimPrefix(fields[1], "#"))
+		case keyword == "poptag" && len(fields) == 2:
+			pushedTags = removeTag(pushedTags, strings.TrimPrefix(fields[1], "#"))
+		case len(fields) < 2 || !startsWithDigit(keyword):
+			journal.Untranslated = append(journal.Untranslated, untranslated(lineNum, keyword))
+		case fields[1] == "open":
+			if len(fields) > 3 {
+				journal.Currencies[fields[2]] = strings.Split(fields[3], ",")[0]
+			}
+		case fields[1] == "txn" || len(fields[1]) == 1:
+			current = &Transaction{Line: lineNum}
+			// The rest of the line follows the date and the flag.
+			rest := strings.TrimSpace(strings.TrimSpace(trimmed[len(fields[0]):])[len(fields[1]):])
+			currentErr = parseBeancountHeader(current, fields[0], rest)
+			for _, tag := range pushedTags {
+				current.Tags = addTag(current.Tags, tag)
+			}
+		default:
+			journal.Untranslated = append(journal.Untranslated, untranslated(lineNum, fields[1]))
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, []error{fmt.Errorf("failed to read file: %w", err)}
+	}
+
+	finishTransaction()
+	return journal, errs
+}
+
+// parseBeancountHeader parses the first line of a beancount transaction, which is the date, followed by the rest of
+// the line after the flag, like `"payee" "narration" #tag ^link`.
+func parseBeancountHeader(transaction *Transaction, date string, rest string) error {
+	timestamp, err := parseDate(date)
+	if err != nil {
+		return err
+	}
+	transaction.Timestamp = timestamp
+
+	var texts []string
+	for rest != "" {
+		switch rest[0] {
+		case '"':
+			text, remaining, err := readBeancountString(rest)
+			if err != nil {
+				return err
+			}
+			texts, rest = append(texts, text), remaining
+		case '#', '^':
+			end := strings.IndexAny(rest, " \t")
+			if end < 0 {
+				end = len(rest)
+			}
+			if rest[0] == '#' {
+				transaction.Tags = addTag(transaction.Tags, rest[1:end])
+			}
+			rest = rest[end:]
+		case ';':
+			rest = ""
+		default:
+			return errInvalidBeancountHeader
+		}
+		rest = strings.TrimSpace(rest)
+	}
+
+	switch len(texts) {
+	case 0:
+	case 1:
+		transaction.Narration = texts[0]
+	case 2:
+		transaction.Payee, transaction.Narration = texts[0], texts[1]
+	default:
+		return errInvalidBeancountHeader
+	}
+	return nil
+}
+
+// readBeancountString reads the quoted string at the start of the value, and provides its text along with the rest
+// of the value. The backslashes escape the characters after them.
+func readBeancountString(value string) (string, string, error) {
+	var builder strings.Builder
+	for idx := 1; idx < len(value); idx++ {
+		switch value[idx] {
+		case '\\':
+			if idx+1 < len(value) {
+				idx++
+				builder.WriteByte(value[idx])
+			}
+		case '"':
+			return builder.String(), value[idx+1:], nil
+		default:
+			builder.WriteByte(value[idx])
+		}
+	}
+	return "", "", errInvalidBeancountHeader
+}
+
+// parseBeancountPosting parses a posting line of a beancount transaction, without its indentation, like
+// "! Assets:Bank  -10.50 USD @ 83 INR ; comment".
+func parseBeancountPosting(line string) (*Posting, error) {
+	posting := &Posting{}
+
+	if idx := strings.Index(line, ";"); idx >= 0 {
+		posting.Comment = strings.TrimSpace(line[idx+1:])
+		line = strings.TrimSpace(line[:idx])
+	}
+
+	// Skipping the flag of the posting.
+	if len(line) > 1 && strings.ContainsRune("*!", rune(line[0])) && (line[1] == ' ' || line[1] == '\t') {
+		line = strings.TrimSpace(line[1:])
+	}
+
+	fields := strings.Fields(line)
+	if len(fields) == 0 || !strings.Contains(fields[0], ":") {
+		return nil, fmt.Errorf("posting %q should start with an account", line)
+	}
+	posting.Account = fields[0]
+
+	rest := strings.TrimSpace(line[len(fields[0]):])
+	if rest == "" {
+		posting.elided = true
+		return posting, nil
+	}
+
+	if err := parsePostingAmount(posting, rest); err != nil {
+		return nil, err
+	}
+	return posting, nil
+}
+
+// removeTag removes a tag from the tags.
+func removeTag(tags []string, tag string) []string {
+	kept := tags[:0]
+	for _, existing := range tags {
+		if existing != tag {
+			kept = append(kept, existing)
+		}
+	}
+	return kept
+}
+
+// startsWithDigit checks if the value starts with a digit, as the dates do.
+func startsWithDigit(value string) bool {
+	return value != "" && value[0] >= '0' && value[0] <= '9'
+}
@@ -0,0 +1,85 @@
+package journals
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseBeancount(t *testing.T) {
+	journal := "\ufeffoption \"title\" \"Personal\"\n" +
+		"* Accounts\n" +
+		"2022-01-01 open Assets:Bank:Checking INR\n" +
+		"2022-01-01 open Assets:Wallet USD,EUR\n" +
+		"2022-01-01 open Expenses:Food\n" +
+		"\n" +
+		"pushtag #trip\n" +
+		"2022-03-01 * \"ACME\" \"Salary \\\"March\\\"\" #work ^payslip-3\n" +
+		"  id: \"42\"\n" +
+		"  Assets:Bank:Checking  1,000.50 INR\n" +
+		"  Income:Salary\n" +
+		"poptag #trip\n" +
+		"\n" +
+		"2022-03-02 txn \"Groceries\"\n" +
+		"  ; a comment\n" +
+		"  Expenses:Food  70 INR ; Vegetables\n" +
+		"  Expenses:Food:Snacks  30 INR\n" +
+		"  ! Assets:Bank:Checking  -100 INR\n" +
+		"\n" +
+		"2022-03-03 * \"Travel money\"\n" +
+		"  Assets:Wallet  10 USD @ 83 INR\n" +
+		"  Assets:Bank:Checking\n" +
+		"\n" +
+		"2022-03-04 balance Assets:Bank:Checking  70.50 INR\n" +
+		"2022-03-05 price USD 83 INR\n" +
+		"2022-03-06 * \"Unbalanced\"\n" +
+		"  Assets:Bank:Checking\n" +
+		"  Expenses:Food\n" +
+		"2022-13-01 * \"Bad date\"\n" +
+		"  Assets:Bank:Checking  1 INR\n" +
+		"  Income:Salary  -1 INR\n"
+
+	parsed, errs := ParseBeancount(strings.NewReader(journal))
+	if len(errs) != 2 || !strings.HasPrefix(errs[0].Error(), "line 26:") || !strings.HasPrefix(errs[1].Error(), "line 29:") {
+		t.Fatalf("unexpected errors: %+v", errs)
+	}
+	if len(parsed.Transactions) != 3 {
+		t.Fatalf("expected 3 transactions, got: %+v", parsed.Transactions)
+	}
+
+	expectedUntranslated := []string{
+		"line 1: option directive is not translated",
+		"line 24: balance directive is not translated",
+		"line 25: price directive is not translated",
+	}
+	if strings.Join(parsed.Untranslated, "|") != strings.Join(expectedUntranslated, "|") {
+		t.Fatalf("unexpected untranslated directives: %+v", parsed.Untranslated)
+	}
+	if parsed.Currencies["Assets:Bank:Checking"] != "INR" || parsed.Currencies["Assets:Wallet"] != "USD" ||
+		parsed.Currencies["Expenses:Food"] != "" {
+		t.Fatalf("unexpected currencies: %+v", parsed.Currencies)
+	}
+
+	salary := parsed.Transactions[0]
+	if salary.Line != 8 || salary.Timestamp != time.Date(2022, time.March, 1, 0, 0, 0, 0, time.UTC).Unix() ||
+		salary.Notes() != `ACME - Salary "March"` || strings.Join(salary.Tags, ",") != "work,trip" {
+		t.Fatalf("unexpected transaction: %+v", salary)
+	}
+	if salary.Postings[1].Account != "Income:Salary" || salary.Postings[1].Amount != -10005000 ||
+		salary.Postings[1].Commodity != "INR" {
+		t.Fatalf("unexpected elided posting: %+v", salary.Postings[1])
+	}
+
+	groceries := parsed.Transactions[1]
+	if groceries.Notes() != "Groceries" || len(groceries.Tags) != 0 || len(groceries.Postings) != 3 ||
+		groceries.Postings[0].Comment != "Vegetables" || groceries.Postings[2].Amount != -1000000 {
+		t.Fatalf("unexpected transaction: %+v", groceries)
+	}
+
+	// The elided amount is the price of the other posting.
+	travel := parsed.Transactions[2]
+	if travel.Postings[0].Amount != 100000 || travel.Postings[0].Commodity != "USD" ||
+		travel.Postings[1].Amount != -8300000 || travel.Postings[1].Commodity != "INR" {
+		t.Fatalf("unexpected transaction: %+v, %+v", travel.Postings[0], travel.Postings[1])
+	}
+}
@@ -0,0 +1,230 @@
+// Package journals parses the plain-text accounting journals of beancount, ledger-cli and hledger into transactions
+// with postings, which can then be translated into the transactions of the ledger.
+//
+// Only the parts of the journals that the ledger has a place for are read. The other directives, like the prices and
+// the balance assertions, are reported as untranslated, so nothing is left out silently.
+package journals
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/shivanshkc/ledgerkeep/src/models"
+)
+
+// Journal is the contents of a journal file.
+type Journal struct {
+	// Transactions are the transactions of the journal, in the order of the file.
+	Transactions []*Transaction
+	// Currencies are the currencies that the accounts are limited to by their declarations, keyed by the account
+	// names. Only beancount journals declare them.
+	Currencies map[string]string
+	// Untranslated describe the directives that could not be translated, along with their line numbers.
+	Untranslated []string
+}
+
+// Transaction is a transaction of a journal.
+type Transaction struct {
+	// Line is the line number of the transaction in the journal, starting from 1. It identifies the transaction in the
+	// errors.
+	Line int
+	// Timestamp is the start of the date of the transaction in UTC, in epoch seconds.
+	Timestamp int64
+	// Payee of the transaction, if any. Ledger-cli and hledger journals have only the payee.
+	Payee string
+	// Narration of the transaction, if any. Only beancount journals have it.
+	Narration string
+	// Tags of the transaction and its postings.
+	Tags []string
+	// Postings of the transaction. Their elided amounts are filled in, so they always have amounts.
+	Postings []*Posting
+}
+
+// Posting is a posting of a journal transaction.
+type Posting struct {
+	// Account is the full name of the account of the posting, like "Expenses:Food".
+	Account string
+	// Amount of the posting.
+	Amount models.Money
+	// Commodity of the amount, like "USD". It is empty if the journal does not tell it.
+	Commodity string
+	// Comment of the posting, if any.
+	Comment string
+
+	// weight is the amount that the posting adds to the balance of the transaction, in the weightCommodity. It is the
+	// total price of the posting, if it has one, or else its amount.
+	weight          models.Money
+	weightCommodity string
+	// elided tells if the amount of the posting is left for the other postings to tell.
+	elided bool
+}
+
+// Notes provides the payee and the narration of the transaction, joined by a " - " if it has both.
+func (t *Transaction) Notes() string {
+	if t.Payee != "" && t.Narration != "" {
+		return t.Payee + " - " + t.Narration
+	}
+	return t.Payee + t.Narration
+}
+
+// Errors of the postings.
+var (
+	errInvalidAmount    = errors.New("amount should be a decimal number with a commodity")
+	errTooManyElided    = errors.New("at most one posting may leave out its amount")
+	errCannotInferElide = errors.New("amount that is left out cannot be inferred from the other postings")
+	errInvalidDate      = errors.New("date should be like 2022-03-31")
+	errTooFewPostings   = errors.New("transaction should have at least two postings")
+)
+
+// dateLayouts are the layouts of the dates that the journals may have. The ledger-cli dates may use slashes or dots.
+var dateLayouts = []string{"2006-1-2", "2006/1/2", "2006.1.2"}
+
+// amountRegexp matches an amount with its commodity before or after the number, like "-$1,000.50", "$-5", "12 USD" or
+// "EUR -3.5". The commodities with spaces or digits are quoted.
+var amountRegexp = regexp.MustCompile(
+	`^([-+]?)\s*("[^"]*"|[^\s\d"+.,-]*)\s*([-+]?\d[\d,]*(?:\.\d*)?|[-+]?\.\d+)\s*("[^"]*"|[^\s\d"]*)$`)
+
+// parseDate parses the date of a transaction.
+func parseDate(value string) (int64, error) {
+	for _, layout := range dateLayouts {
+		if date, err := time.Parse(layout, value); err == nil {
+			return date.Unix(), nil
+		}
+	}
+	return 0, errInvalidDate
+}
+
+// parseAmount parses an amount of a posting, along with its commodity. The commas of the numbers are ignored.
+func parseAmount(value string) (models.Money, string, error) {
+	matches := amountRegexp.FindStringSubmatch(strings.TrimSpace(value))
+	if matches == nil || (matches[2] != "" && matches[4] != "") {
+		return 0, "", errInvalidAmount
+	}
+
+	amount, err := models.ParseMoney(strings.ReplaceAll(strings.TrimPrefix(matches[3], "+"), ",", ""))
+	if err != nil {
+		return 0, "", fmt.Errorf("%w: %s", errInvalidAmount, err.Error())
+	}
+	if matches[1] == "-" {
+		amount = -amount
+	}
+
+	return amount, strings.Trim(matches[2]+matches[4], `"`), nil
+}
+
+// parsePostingAmount parses the amount of a posting, along with its price, like "10 USD @ 83 INR" or
+// "10 USD @@ 830 INR", and sets the amount and the weight of the posting. The lot costs in braces are ignored, as the
+// ledger has no place for them.
+func parsePostingAmount(posting *Posting, value string) error {
+	value = lotRegexp.ReplaceAllString(value, " ")
+
+	amountPart, pricePart, isTotal := value, "", false
+	if idx := strings.Index(value, "@"); idx >= 0 {
+		amountPart, pricePart = value[:idx], value[idx+1:]
+		if strings.HasPrefix(pricePart, "@") {
+			pricePart, isTotal = pricePart[1:], true
+		}
+	}
+
+	amount, commodity, err := parseAmount(amountPart)
+	if err != nil {
+		return err
+	}
+	posting.Amount, posting.Commodity = amount, commodity
+	posting.weight, posting.weightCommodity = amount, commodity
+
+	if pricePart == "" {
+		return nil
+	}
+
+	price, priceCommodity, err := parseAmount(pricePart)
+	if err != nil {
+		return err
+	}
+
+	// The total price has the sign of the amount, whatever its own sign is.
+	if isTotal {
+		if price < 0 {
+			price = -price
+		}
+		if amount < 0 {
+			price = -price
+		}
+		posting.weight, posting.weightCommodity = price, priceCommodity
+		return nil
+	}
+
+	// The unit price is converted into the total price, which is rounded to the precision of Money.
+	scale := new(big.Int).Exp(big.NewInt(10), big.NewInt(models.MoneyDecimalPlaces), nil)
+	unitPrice := new(big.Rat).SetFrac(big.NewInt(int64(price)), scale)
+	posting.weight, posting.weightCommodity = amount.Convert(unitPrice), priceCommodity
+	return nil
+}
+
+// lotRegexp matches the lot costs and dates of a posting, like "{150 USD}", "{{1500 USD}}" or "[2022-03-01]".
+var lotRegexp = regexp.MustCompile(`\{\{?[^}]*\}?\}|\[[^\]]*\]`)
+
+// fillElided fills in the amount of the posting of the transaction that leaves it out, which is what balances the
+// transaction. It also checks that the transaction has at least two postings.
+func fillElided(transaction *Transaction) error {
+	if len(transaction.Postings) < 2 {
+		return errTooFewPostings
+	}
+
+	var elided *Posting
+	sums := map[string]models.Money{}
+	for _, posting := range transaction.Postings {
+		if !posting.elided {
+			sums[posting.weightCommodity] += posting.weight
+			continue
+		}
+		if elided != nil {
+			return errTooManyElided
+		}
+		elided = posting
+	}
+	if elided == nil {
+		return nil
+	}
+
+	// Only a transaction that is off in a single commodity can be balanced by a single amount.
+	var unbalanced []string
+	for commodity, sum := range sums {
+		if sum != 0 {
+			unbalanced = append(unbalanced, commodity)
+		}
+	}
+	if len(unbalanced) != 1 {
+		return errCannotInferElide
+	}
+
+	commodity := unbalanced[0]
+	elided.Amount, elided.Commodity = -sums[commodity], commodity
+	elided.weight, elided.weightCommodity = elided.Amount, commodity
+	elided.elided = false
+	return nil
+}
+
+// addTag adds a tag to the tags, unless they have it already.
+func addTag(tags []string, tag string) []string {
+	for _, existing := range tags {
+		if existing == tag {
+			return tags
+		}
+	}
+	return append(tags, tag)
+}
+
+// untranslated describes a directive that could not be translated.
+func untranslated(lineNum int, directive string) string {
+	return fmt.Sprintf("line %d: %s directive is not translated", lineNum, directive)
+}
+
+// lineError provides the error of a transaction at the line.
+func lineError(lineNum int, err error) error {
+	return fmt.Errorf("line %d: %w", lineNum, err)
+}
@@ -0,0 +1,223 @@
+package journals
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+)
+
+// Errors of the ledger-cli postings.
+var (
+	errVirtualPosting    = errors.New("virtual postings, in parentheses or brackets, are not supported")
+	errBalanceAssignment = errors.New("balance assignments, without an amount before the =, are not supported")
+)
+
+// ledgerTagsRegexp matches the ledger-cli tags of a comment, like ":food:travel:".
+var ledgerTagsRegexp = regexp.MustCompile(`:(?:[^\s:]+:)+`)
+
+// hledgerTagRegexp matches an hledger tag of a comment, which is a word followed by a colon, like "food:". The words
+// with a value after the colon, like "id: 42", are metadata.
+var hledgerTagRegexp = regexp.MustCompile(`(?:^|\s)([^\s:,]+):(.*)$`)
+
+// ParseLedger parses the transactions of a ledger-cli or hledger journal.
+//
+// The account directives are read as they are, as the accounts are created out of the postings. All the other
+// directives, like the prices, the periodic and the automated transactions, are reported as untranslated. The tags of
+// the transactions are read from their comments, in the syntax of either ledger-cli or hledger.
+func ParseLedger(reader io.Reader) (*Journal, []error) {
+	scanner := bufio.NewScanner(reader)
+	journal := &Journal{Currencies: map[string]string{}}
+
+	var errs []error
+
+	// current is the transaction being read, and currentErr is its first error.
+	var current *Transaction
+	var currentErr error
+	// blockEnd is the line that ends the comment or test block being skipped, if any.
+	var blockEnd string
+
+	finishTransaction := func() {
+		if current == nil {
+			return
+		}
+		if currentErr == nil {
+			currentErr = fillElided(current)
+		}
+		if currentErr != nil {
+			errs = append(errs, lineError(current.Line, currentErr))
+		} else {
+			journal.Transactions = append(journal.Transactions, current)
+		}
+		current, currentErr = nil, nil
+	}
+
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if lineNum == 1 {
+			line = strings.TrimPrefix(line, "\ufeff")
+		}
+		trimmed := strings.TrimSpace(line)
+
+		if blockEnd != "" {
+			if trimmed == blockEnd {
+				blockEnd = ""
+			}
+			continue
+		}
+
+		// The indented lines belong to the directive above them. Only those of the transactions are read.
+		if trimmed != "" && (line[0] == ' ' || line[0] == '\t') {
+			if current == nil || currentErr != nil {
+				continue
+			}
+			if trimmed[0] == ';' {
+				for _, tag := range commentTags(trimmed[1:]) {
+					current.Tags = addTag(current.Tags, tag)
+				}
+				continue
+			}
+			posting, tags, err := parseLedgerPosting(trimmed)
+			if err != nil {
+				currentErr = err
+				continue
+			}
+			current.Postings = append(current.Postings, posting)
+			for _, tag := range tags {
+				current.Tags = addTag(current.Tags, tag)
+			}
+			continue
+		}
+
+		finishTransaction()
+		if trimmed == "" || strings.ContainsRune(";#%|*", rune(trimmed[0])) {
+			continue
+		}
+
+		keyword := strings.Fields(trimmed)[0]
+		switch {
+		case startsWithDigit(keyword):
+			current = &Transaction{Line: lineNum}
+			currentErr = parseLedgerHeader(current, trimmed)
+		case keyword == "account":
+		case keyword == "comment" || keyword == "test":
+			blockEnd = "end " + keyword
+		default:
+			journal.Untranslated = append(journal.Untranslated, untranslated(lineNum, keyword))
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, []error{fmt.Errorf("failed to read file: %w", err)}
+	}
+
+	finishTransaction()
+	return journal, errs
+}
+
+// parseLedgerHeader parses the first line of a ledger-cli transaction, like
+// "2022/03/31=2022/04/01 * (42) Payee ; :tag:".
+func parseLedgerHeader(transaction *Transaction, line string) error {
+	if idx := strings.Index(line, ";"); idx >= 0 {
+		transaction.Tags = commentTags(line[idx+1:])
+		line = line[:idx]
+	}
+
+	date := strings.Fields(line)[0]
+	rest := strings.TrimSpace(line[len(date):])
+
+	// The auxiliary date, after the =, is not read.
+	date = strings.SplitN(date, "=", 2)[0]
+	timestamp, err := parseDate(date)
+	if err != nil {
+		return err
+	}
+	transaction.Timestamp = timestamp
+
+	// Skipping the status and the code.
+	if rest != "" && (rest[0] == '*' || rest[0] == '!') {
+		rest = strings.TrimSpace(rest[1:])
+	}
+	if strings.HasPrefix(rest, "(") {
+		if end := strings.Index(rest, ")"); end >= 0 {
+			rest = strings.TrimSpace(rest[end+1:])
+		}
+	}
+
+	transaction.Payee = rest
+	return nil
+}
+
+// parseLedgerPosting parses a posting line of a ledger-cli transaction, without its indentation, like
+// "* Assets:Bank  -$10.50 @ 83 INR = $100 ; comment". It also provides the tags of the comment of the posting.
+//
+// The account name ends at two spaces or a tab, as it may have single spaces. The balance assertions, after the =,
+// are not checked.
+func parseLedgerPosting(line string) (*Posting, []string, error) {
+	posting := &Posting{}
+
+	var tags []string
+	if idx := strings.Index(line, ";"); idx >= 0 {
+		comment := line[idx+1:]
+		if tags = commentTags(comment); len(tags) == 0 {
+			posting.Comment = strings.TrimSpace(comment)
+		}
+		line = strings.TrimSpace(line[:idx])
+	}
+
+	// Skipping the status of the posting.
+	if len(line) > 1 && strings.ContainsRune("*!", rune(line[0])) && (line[1] == ' ' || line[1] == '\t') {
+		line = strings.TrimSpace(line[1:])
+	}
+
+	account, rest := line, ""
+	end := strings.Index(line, "  ")
+	if tabIdx := strings.Index(line, "\t"); tabIdx >= 0 && (end < 0 || tabIdx < end) {
+		end = tabIdx
+	}
+	if end >= 0 {
+		account, rest = line[:end], strings.TrimSpace(line[end:])
+	}
+	if strings.HasPrefix(account, "(") || strings.HasPrefix(account, "[") {
+		return nil, nil, errVirtualPosting
+	}
+	posting.Account = account
+
+	if idx := strings.Index(rest, "="); idx >= 0 {
+		if rest = strings.TrimSpace(rest[:idx]); rest == "" {
+			return nil, nil, errBalanceAssignment
+		}
+	}
+	if rest == "" {
+		posting.elided = true
+		return posting, tags, nil
+	}
+
+	if err := parsePostingAmount(posting, rest); err != nil {
+		return nil, nil, err
+	}
+	return posting, tags, nil
+}
+
+// commentTags provides the tags of a comment, which may be in the syntax of either ledger-cli, like ":food:travel:",
+// or hledger, like "food:, travel:".
+func commentTags(comment string) []string {
+	var tags []string
+
+	for _, match := range ledgerTagsRegexp.FindAllString(comment, -1) {
+		for _, tag := range strings.Split(strings.Trim(match, ":"), ":") {
+			tags = addTag(tags, tag)
+		}
+	}
+
+	for _, part := range strings.Split(comment, ",") {
+		matches := hledgerTagRegexp.FindStringSubmatch(strings.TrimSpace(part))
+		if matches != nil && strings.TrimSpace(matches[2]) == "" {
+			tags = addTag(tags, matches[1])
+		}
+	}
+
+	return tags
+}
@@ -0,0 +1,103 @@
+package journals
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseLedger(t *testing.T) {
+	journal := "; A ledger-cli journal\n" +
+		"account Assets:Bank\n" +
+		"    note The main account\n" +
+		"P 2022/03/01 EUR $1.10\n" +
+		"\n" +
+		"2022/03/01=2022/03/02 * (1001) Salary for March  ; :work:\n" +
+		"    ; id: 42\n" +
+		"    Assets:Bank        $1,000.00\n" +
+		"    Income:Salary\n" +
+		"\n" +
+		"2022-03-02 Grocery Store\n" +
+		"    ; food:, store: Fresh Co\n" +
+		"    Expenses:Food:Vegetables    $70  ; Weekly vegetables\n" +
+		"    Expenses:Food:Snacks\t30 USD\n" +
+		"    * Assets:Bank  -$100 = $900\n" +
+		"\n" +
+		"comment\n" +
+		"2022-03-03 Commented out\n" +
+		"    Assets:Bank  $1\n" +
+		"end comment\n" +
+		"~ Monthly\n" +
+		"    Expenses:Rent  $500\n" +
+		"    Assets:Bank\n" +
+		"\n" +
+		"2022-03-04 Europe trip\n" +
+		"    Assets:Wallet  EUR 100 @@ $110\n" +
+		"    Assets:Bank\n" +
+		"\n" +
+		"2022-03-05 Virtual\n" +
+		"    (Budget:Food)  $-10\n" +
+		"    Assets:Bank  $10\n" +
+		"    Income:Other\n"
+
+	parsed, errs := ParseLedger(strings.NewReader(journal))
+	if len(errs) != 1 || errs[0].Error() != "line 29: "+errVirtualPosting.Error() {
+		t.Fatalf("unexpected errors: %+v", errs)
+	}
+	if len(parsed.Transactions) != 3 {
+		t.Fatalf("expected 3 transactions, got: %+v", parsed.Transactions)
+	}
+	if strings.Join(parsed.Untranslated, "|") != "line 4: P directive is not translated|"+
+		"line 21: ~ directive is not translated" {
+		t.Fatalf("unexpected untranslated directives: %+v", parsed.Untranslated)
+	}
+
+	salary := parsed.Transactions[0]
+	if salary.Timestamp != time.Date(2022, time.March, 1, 0, 0, 0, 0, time.UTC).Unix() ||
+		salary.Notes() != "Salary for March" || strings.Join(salary.Tags, ",") != "work" {
+		t.Fatalf("unexpected transaction: %+v", salary)
+	}
+	if salary.Postings[0].Amount != 10000000 || salary.Postings[0].Commodity != "$" ||
+		salary.Postings[1].Amount != -10000000 || salary.Postings[1].Commodity != "$" {
+		t.Fatalf("unexpected postings: %+v, %+v", salary.Postings[0], salary.Postings[1])
+	}
+
+	groceries := parsed.Transactions[1]
+	if groceries.Notes() != "Grocery Store" || strings.Join(groceries.Tags, ",") != "food" ||
+		groceries.Postings[0].Account != "Expenses:Food:Vegetables" || groceries.Postings[0].Comment != "Weekly vegetables" ||
+		groceries.Postings[1].Commodity != "USD" || groceries.Postings[2].Amount != -1000000 {
+		t.Fatalf("unexpected transaction: %+v", groceries)
+	}
+
+	trip := parsed.Transactions[2]
+	if trip.Postings[0].Amount != 1000000 || trip.Postings[0].Commodity != "EUR" ||
+		trip.Postings[1].Amount != -1100000 || trip.Postings[1].Commodity != "$" {
+		t.Fatalf("unexpected postings: %+v, %+v", trip.Postings[0], trip.Postings[1])
+	}
+}
+
+func TestParseAmount(t *testing.T) {
+	for value, expected := range map[string]struct {
+		amount    int64
+		commodity string
+	}{
+		"-$1,000.50":  {-10005000, "$"},
+		"$-5":         {-50000, "$"},
+		"12 USD":      {120000, "USD"},
+		"EUR -3.5":    {-35000, "EUR"},
+		"+7":          {70000, ""},
+		`10 "ACME 1"`: {100000, "ACME 1"},
+		"-0.0001 INR": {-1, "INR"},
+	} {
+		amount, commodity, err := parseAmount(value)
+		if err != nil || int64(amount) != expected.amount || commodity != expected.commodity {
+			t.Errorf("unexpected amount of %s: %d, %s, %+v", value, amount, commodity, err)
+		}
+	}
+
+	for _, value := range []string{"0.00001 USD", "$5 USD", "USD", "1.5 USD {2 INR}"} {
+		if _, _, err := parseAmount(value); err == nil {
+			t.Errorf("expected error for %s", value)
+		}
+	}
+}
@@ -1,49 +1,230 @@
 package middlewares
 
 import (
+	"context"
 	"crypto/sha256"
-	"crypto/subtle"
+	"errors"
+	"fmt"
 	"net/http"
+	"strings"
+	"sync"
+	"time"
 
-	"github.com/shivanshkc/ledgerkeep/src/configs"
+	"github.com/shivanshkc/ledgerkeep/src/database"
 	"github.com/shivanshkc/ledgerkeep/src/logger"
+	"github.com/shivanshkc/ledgerkeep/src/models"
+	"github.com/shivanshkc/ledgerkeep/src/oidc"
+	"github.com/shivanshkc/ledgerkeep/src/utils/authutils"
+	"github.com/shivanshkc/ledgerkeep/src/utils/ctxutils"
 	"github.com/shivanshkc/ledgerkeep/src/utils/errutils"
 	"github.com/shivanshkc/ledgerkeep/src/utils/httputils"
 )
 
-// Auth middleware verifies if the x-password header in the request matches the one in configs.
-func Auth(next http.Handler) http.Handler {
-	conf := configs.Get()
+const (
+	// authCacheSize is the maximum number of verified credentials that the Auth middleware remembers.
+	authCacheSize = 1024
+	// bearerPrefix is the prefix of the Authorization headers with bearer tokens.
+	bearerPrefix = "Bearer "
+	// tokenLastUsedPrecision is how often the last use of an API token is stored, so most requests do not write it.
+	tokenLastUsedPrecision = int64(time.Minute / time.Second)
+	// dummyPasswordHash is a bcrypt hash, of the same cost as the password hashes of the users, which the passwords
+	// of the unknown users are checked against.
+	dummyPasswordHash = "$2a$10$Aq/aani5hkQ56h.8q00Gwu5jpicngkb7QtJ2NBLsPIjGy88S7K70S"
+)
+
+// Auth middleware verifies the credentials of the request against the stored users. The credentials can be the basic
+// auth username and password, or an API token as a bearer token.
+//
+// If the OIDC verifier is not nil, the access tokens of its issuer are accepted as bearer tokens instead of the basic
+// auth credentials. Their verified email or subject claims are the IDs of the users.
+//
+// It puts the ID of the user and of its tenant into the request context, so the storage is scoped to the user's
+// ledger. For the API tokens, it also puts their scopes, which the Permissions middleware checks.
+func Auth(users database.UserRepository, tokens database.TokenRepository,
+	verifier *oidc.Verifier) func(http.Handler) http.Handler {
 	log := logger.Get()
+	cache := &credentialsCache{mutex: &sync.Mutex{}, entries: map[[sha256.Size]byte]string{}}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+			ctx := request.Context()
+
+			var user *models.UserDTO
+			var err error
+
+			// The auth scheme is case-insensitive.
+			authorization := request.Header.Get("Authorization")
+			isBearer := len(authorization) > len(bearerPrefix) &&
+				strings.EqualFold(authorization[:len(bearerPrefix)], bearerPrefix)
+
+			switch {
+			case isBearer && (verifier == nil || authutils.IsToken(authorization[len(bearerPrefix):])):
+				var token *models.TokenDTO
+				if token, err = verifyToken(ctx, tokens, authorization[len(bearerPrefix):]); err == nil {
+					user, err = getAuthUser(ctx, users, token.UserID)
+					ctx = ctxutils.PutTokenScopes(ctx, token.Scopes)
+				}
+			case isBearer:
+				user, err = verifyAccessToken(ctx, verifier, users, authorization[len(bearerPrefix):])
+			case verifier != nil:
+				// The issuer authenticates the users in the OIDC mode, so the passwords are not accepted.
+				err = errutils.Unauthorized()
+			default:
+				user, err = verifyBasicAuth(request, users, cache)
+			}
+
+			if err != nil {
+				httputils.WriteErrAndLog(ctx, writer, err, log)
+				return
+			}
+
+			ctx = ctxutils.PutUserID(ctx, user.ID)
+			ctx = ctxutils.PutTenantID(ctx, user.TenantID)
+			*request = *request.WithContext(ctx)
+
+			next.ServeHTTP(writer, request)
+		})
+	}
+}
+
+// verifyAccessToken provides the user of the access token of the OIDC issuer.
+func verifyAccessToken(ctx context.Context, verifier *oidc.Verifier, users database.UserRepository,
+	accessToken string) (*models.UserDTO, error) {
+	claims, err := verifier.Verify(ctx, accessToken)
+	if err != nil {
+		// The invalid tokens are no different from wrong passwords. The other errors are of the issuer being unavailable.
+		if errors.Is(err, oidc.ErrInvalidToken) {
+			logger.Get().Debug(ctx, &logger.Entry{Payload: fmt.Errorf("rejected access token: %w", err)})
+			return nil, errutils.Unauthorized()
+		}
+		return nil, err
+	}
+
+	// The first of the claims that is the ID of a user wins.
+	for _, userID := range claims.UserIDs() {
+		user, err := users.GetUser(ctx, userID)
+		if err == nil {
+			return user, nil
+		}
+		var errHTTP *errutils.HTTPError
+		if !errors.As(err, &errHTTP) || errHTTP.CustomCode != errutils.UserNotFound().CustomCode {
+			return nil, err
+		}
+	}
+
+	return nil, errutils.Unauthorized()
+}
+
+// verifyBasicAuth provides the user of the basic auth credentials of the request.
+func verifyBasicAuth(request *http.Request, users database.UserRepository, cache *credentialsCache) (*models.UserDTO,
+	error) {
+	// Retrieving user provided username and password.
+	username, password, ok := request.BasicAuth()
+	if !ok {
+		return nil, errutils.Unauthorized()
+	}
+
+	user, err := getAuthUser(request.Context(), users, username)
+	if err != nil {
+		// An unknown user takes as long as a wrong password, so the response times do not tell which users exist.
+		var errHTTP *errutils.HTTPError
+		if errors.As(err, &errHTTP) && errHTTP.CustomCode == errutils.Unauthorized().CustomCode {
+			_, _ = authutils.CheckPassword(dummyPasswordHash, password)
+		}
+		return nil, err
+	}
 
-	// Calculating the expected Username and Password.
-	expectedUsernameHash := sha256.Sum256([]byte(conf.Auth.Username))
-	expectedPasswordHash := sha256.Sum256([]byte(conf.Auth.Password))
+	// If the password doesn't match, it's 401.
+	valid, err := cache.verify(username, password, user.PasswordHash)
+	if err != nil {
+		return nil, err
+	}
+	if !valid {
+		return nil, errutils.Unauthorized()
+	}
 
-	return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
-		ctx := request.Context()
+	return user, nil
+}
 
-		// Retrieving user provided username and password.
-		username, password, ok := request.BasicAuth()
-		if !ok {
-			httputils.WriteErrAndLog(ctx, writer, errutils.Unauthorized(), log)
-			return
+// verifyToken provides the stored API token that matches the provided one, if it has not expired.
+// It also records the use of the token.
+func verifyToken(ctx context.Context, tokens database.TokenRepository, tokenValue string) (*models.TokenDTO, error) {
+	token, err := tokens.GetTokenByHash(ctx, authutils.HashToken(tokenValue))
+	if err != nil {
+		// An unknown token is no different from a wrong password.
+		var errHTTP *errutils.HTTPError
+		if errors.As(err, &errHTTP) && errHTTP.CustomCode == errutils.TokenNotFound().CustomCode {
+			err = errutils.Unauthorized()
 		}
+		return nil, err
+	}
+
+	now := time.Now().Unix()
+	if token.ExpiresAt != 0 && now >= token.ExpiresAt {
+		return nil, errutils.Unauthorized()
+	}
 
-		// Hashing the provided username and password for comparison with the expected ones.
-		usernameHash := sha256.Sum256([]byte(username))
-		passwordHash := sha256.Sum256([]byte(password))
+	lastUsedAt := now - now%tokenLastUsedPrecision
+	if token.LastUsedAt != lastUsedAt {
+		if err := tokens.UpdateTokenLastUsed(ctx, token.ID, lastUsedAt); err != nil {
+			return nil, err
+		}
+	}
 
-		// Comparing user provided credentials with the expected ones.
-		usernameMatch := subtle.ConstantTimeCompare(usernameHash[:], expectedUsernameHash[:]) == 1
-		passwordMatch := subtle.ConstantTimeCompare(passwordHash[:], expectedPasswordHash[:]) == 1
+	return token, nil
+}
 
-		// If they don't match, it's 401.
-		if !usernameMatch || !passwordMatch {
-			httputils.WriteErrAndLog(ctx, writer, errutils.Unauthorized(), log)
-			return
+// getAuthUser provides the user that the request is being authenticated as.
+func getAuthUser(ctx context.Context, users database.UserRepository, userID string) (*models.UserDTO, error) {
+	user, err := users.GetUser(ctx, userID)
+	if err != nil {
+		// An unknown user is no different from a wrong password.
+		var errHTTP *errutils.HTTPError
+		if errors.As(err, &errHTTP) && errHTTP.CustomCode == errutils.UserNotFound().CustomCode {
+			err = errutils.Unauthorized()
 		}
+		return nil, err
+	}
+	return user, nil
+}
+
+// credentialsCache remembers the credentials that were verified recently, along with the password hashes that they
+// were verified against. bcrypt is slow by design, so it is skipped for the credentials that every request repeats.
+type credentialsCache struct {
+	// mutex guards the entries map.
+	mutex *sync.Mutex
+	// entries is a map of the SHA-256 hashes of the credentials to the password hashes.
+	entries map[[sha256.Size]byte]string
+}
+
+// verify checks if the password matches the password hash of the user.
+//
+// A cached entry is only valid for the password hash that it was verified against, so changing the password of a user
+// makes the cached entries of the old password useless.
+func (c *credentialsCache) verify(username string, password string, passwordHash string) (bool, error) {
+	// Basic auth usernames cannot contain a colon, so the key is unambiguous.
+	key := sha256.Sum256([]byte(username + ":" + password))
+
+	c.mutex.Lock()
+	cachedHash, exists := c.entries[key]
+	c.mutex.Unlock()
+
+	if exists && cachedHash == passwordHash {
+		return true, nil
+	}
+
+	valid, err := authutils.CheckPassword(passwordHash, password)
+	if err != nil || !valid {
+		return false, err
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
 
-		next.ServeHTTP(writer, request)
-	})
+	// The cache is simply emptied when it is full, as it only saves time.
+	if len(c.entries) >= authCacheSize {
+		c.entries = map[[sha256.Size]byte]string{}
+	}
+	c.entries[key] = passwordHash
+	return true, nil
 }
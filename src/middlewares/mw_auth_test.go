@@ -0,0 +1,15 @@
+package middlewares
+
+import (
+	"testing"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestDummyPasswordHash(t *testing.T) {
+	// The unknown users take as long as the known ones only if the dummy hash is as costly as the stored ones.
+	cost, err := bcrypt.Cost([]byte(dummyPasswordHash))
+	if err != nil || cost != bcrypt.DefaultCost {
+		t.Fatalf("expected a bcrypt hash of cost %d, got cost %d, error: %+v", bcrypt.DefaultCost, cost, err)
+	}
+}
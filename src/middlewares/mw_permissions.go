@@ -0,0 +1,222 @@
+package middlewares
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/shivanshkc/ledgerkeep/src/database"
+	"github.com/shivanshkc/ledgerkeep/src/logger"
+	"github.com/shivanshkc/ledgerkeep/src/models"
+	"github.com/shivanshkc/ledgerkeep/src/utils/ctxutils"
+	"github.com/shivanshkc/ledgerkeep/src/utils/errutils"
+	"github.com/shivanshkc/ledgerkeep/src/utils/httputils"
+
+	"github.com/gorilla/mux"
+)
+
+const (
+	// scopeReadSuffix is the suffix of the scopes that allow the read routes of a resource.
+	scopeReadSuffix = ":read"
+	// scopeWriteSuffix is the suffix of the scopes that allow all the routes of a resource.
+	scopeWriteSuffix = ":write"
+)
+
+// LedgerIDHeader is the request header that selects the ledger that the request operates on.
+// Without it, a request operates on the ledger of the user itself.
+const LedgerIDHeader = "x-ledger-id"
+
+// Permission is what a route requires of the role of the user in the ledger of the request.
+// Every permission includes all the lower ones.
+type Permission int
+
+const (
+	// PermissionNone is for the routes that do not operate on any ledger.
+	PermissionNone Permission = iota
+	// PermissionRead is for the routes that read the data of the ledger.
+	PermissionRead
+	// PermissionWrite is for the routes that change the data of the ledger.
+	PermissionWrite
+	// PermissionManage is for the routes that change who can access the ledger, or that export or replace all of its
+	// data.
+	PermissionManage
+)
+
+// rolePermissions maps the roles of the ledger members to the highest permissions that they grant.
+var rolePermissions = map[string]Permission{
+	models.RoleViewer: PermissionRead,
+	models.RoleEditor: PermissionWrite,
+	models.RoleOwner:  PermissionManage,
+}
+
+// routeRule is what a route requires of the requests.
+type routeRule struct {
+	// permission is what the route requires of the role of the user in the ledger of the request.
+	permission Permission
+	// scope is what the route requires of the API token of the request. The routes without a scope cannot be used
+	// with API tokens, like the ones that manage the tokens themselves or change the password.
+	scope string
+}
+
+// routeRules maps the routes, as their methods and path templates, to what they require.
+var routeRules = map[string]routeRule{
+	"GET /api": {permission: PermissionNone, scope: ""},
+
+	"POST /api/users":     {permission: PermissionNone, scope: ""},
+	"GET /api/users":      {permission: PermissionNone, scope: "users:read"},
+	"GET /api/users/me":   {permission: PermissionNone, scope: "users:read"},
+	"PATCH /api/users/me": {permission: PermissionNone, scope: ""},
+
+	"POST /api/tokens":              {permission: PermissionNone, scope: ""},
+	"GET /api/tokens":               {permission: PermissionNone, scope: ""},
+	"DELETE /api/tokens/{token_id}": {permission: PermissionNone, scope: ""},
+
+	"GET /api/ledgers":              {permission: PermissionNone, scope: "ledgers:read"},
+	"GET /api/members":              {permission: PermissionRead, scope: "members:read"},
+	"POST /api/members":             {permission: PermissionManage, scope: "members:write"},
+	"DELETE /api/members/{user_id}": {permission: PermissionManage, scope: "members:write"},
+
+	"POST /api/accounts":                {permission: PermissionWrite, scope: "accounts:write"},
+	"GET /api/accounts":                 {permission: PermissionRead, scope: "accounts:read"},
+	"PATCH /api/accounts/{account_id}":  {permission: PermissionWrite, scope: "accounts:write"},
+	"DELETE /api/accounts/{account_id}": {permission: PermissionWrite, scope: "accounts:write"},
+
+	"POST /api/transactions":                    {permission: PermissionWrite, scope: "transactions:write"},
+	"GET /api/transactions/duplicates":          {permission: PermissionRead, scope: "transactions:read"},
+	"GET /api/transactions/{transaction_id}":    {permission: PermissionRead, scope: "transactions:read"},
+	"GET /api/transactions":                     {permission: PermissionRead, scope: "transactions:read"},
+	"PATCH /api/transactions/{transaction_id}":  {permission: PermissionWrite, scope: "transactions:write"},
+	"DELETE /api/transactions/{transaction_id}": {permission: PermissionWrite, scope: "transactions:write"},
+	"POST /api/transfers":                       {permission: PermissionWrite, scope: "transactions:write"},
+
+	"POST /api/categories":                 {permission: PermissionWrite, scope: "categories:write"},
+	"GET /api/categories":                  {permission: PermissionRead, scope: "categories:read"},
+	"PATCH /api/categories/{category_id}":  {permission: PermissionWrite, scope: "categories:write"},
+	"DELETE /api/categories/{category_id}": {permission: PermissionWrite, scope: "categories:write"},
+
+	"POST /api/exchange-rates":             {permission: PermissionWrite, scope: "exchange-rates:write"},
+	"POST /api/exchange-rates/csv":         {permission: PermissionWrite, scope: "exchange-rates:write"},
+	"GET /api/exchange-rates":              {permission: PermissionRead, scope: "exchange-rates:read"},
+	"DELETE /api/exchange-rates/{rate_id}": {permission: PermissionWrite, scope: "exchange-rates:write"},
+
+	"POST /api/budget-plans":             {permission: PermissionWrite, scope: "budget-plans:write"},
+	"GET /api/budget-plans":              {permission: PermissionRead, scope: "budget-plans:read"},
+	"DELETE /api/budget-plans/{plan_id}": {permission: PermissionWrite, scope: "budget-plans:write"},
+
+	"POST /api/recurring-templates":                          {permission: PermissionWrite, scope: "recurring-templates:write"},
+	"GET /api/recurring-templates":                           {permission: PermissionRead, scope: "recurring-templates:read"},
+	"DELETE /api/recurring-templates/{template_id}":          {permission: PermissionWrite, scope: "recurring-templates:write"},
+	"GET /api/recurring-templates/{template_id}/occurrences": {permission: PermissionRead, scope: "recurring-templates:read"},
+
+	"POST /api/import-profiles":                {permission: PermissionWrite, scope: "import-profiles:write"},
+	"GET /api/import-profiles":                 {permission: PermissionRead, scope: "import-profiles:read"},
+	"DELETE /api/import-profiles/{profile_id}": {permission: PermissionWrite, scope: "import-profiles:write"},
+	"POST /api/imports/{format}":               {permission: PermissionWrite, scope: "imports:write"},
+	"POST /api/imports/journal/{format}":       {permission: PermissionWrite, scope: "imports:write"},
+	"GET /api/exports/transactions":            {permission: PermissionRead, scope: "exports:read"},
+	"GET /api/exports/journal":                 {permission: PermissionRead, scope: "exports:read"},
+
+	"POST /api/rules":             {permission: PermissionWrite, scope: "rules:write"},
+	"GET /api/rules":              {permission: PermissionRead, scope: "rules:read"},
+	"POST /api/rules/apply":       {permission: PermissionWrite, scope: "rules:write"},
+	"DELETE /api/rules/{rule_id}": {permission: PermissionWrite, scope: "rules:write"},
+
+	"GET /api/admin/backup":   {permission: PermissionManage, scope: "backups:read"},
+	"POST /api/admin/restore": {permission: PermissionManage, scope: "backups:write"},
+
+	"GET /api/stats/budget":   {permission: PermissionRead, scope: "stats:read"},
+	"GET /api/stats/balances": {permission: PermissionRead, scope: "stats:read"},
+}
+
+// RoutePermission provides the permission that the route with the provided method and path template requires.
+// It returns false if the route is unknown, in which case the Permissions middleware forbids it.
+func RoutePermission(method string, pathTemplate string) (Permission, bool) {
+	rule, exists := routeRules[method+" "+pathTemplate]
+	return rule.permission, exists
+}
+
+// RouteScope provides the scope that an API token needs for the route with the provided method and path template.
+// It returns an empty string if the route is unknown, or if it cannot be used with API tokens.
+func RouteScope(method string, pathTemplate string) string {
+	return routeRules[method+" "+pathTemplate].scope
+}
+
+// hasScope checks if the scopes of an API token include the required scope.
+// A write scope also includes the read scope of its resource.
+func hasScope(scopes []string, required string) bool {
+	for _, scope := range scopes {
+		if scope == required {
+			return true
+		}
+		if strings.HasSuffix(required, scopeReadSuffix) &&
+			scope == strings.TrimSuffix(required, scopeReadSuffix)+scopeWriteSuffix {
+			return true
+		}
+	}
+	return false
+}
+
+// Permissions middleware selects the ledger of the request, and checks if the role of the user in it allows the route.
+// For the requests with API tokens, it also checks if the scopes of the token allow the route.
+//
+// It must run after the Auth middleware, which puts the user and its own ledger into the request context. The routes
+// are identified by their path templates, so it must be attached to the router, which runs it after routing.
+func Permissions(members database.MemberRepository) func(http.Handler) http.Handler {
+	log := logger.Get()
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+			ctx := request.Context()
+
+			// Unknown routes are forbidden, so a new route cannot be left open by mistake.
+			var pathTemplate string
+			if route := mux.CurrentRoute(request); route != nil {
+				pathTemplate, _ = route.GetPathTemplate()
+			}
+			rule, exists := routeRules[request.Method+" "+pathTemplate]
+			if !exists {
+				httputils.WriteErrAndLog(ctx, writer, errutils.Forbidden(), log)
+				return
+			}
+
+			// The API tokens can only use the routes of their scopes.
+			if scopes := ctxutils.GetTokenScopes(ctx); scopes != nil && (rule.scope == "" || !hasScope(scopes, rule.scope)) {
+				httputils.WriteErrAndLog(ctx, writer, errutils.Forbidden(), log)
+				return
+			}
+
+			// The routes that do not operate on any ledger keep the ledger of the user.
+			if rule.permission == PermissionNone {
+				next.ServeHTTP(writer, request)
+				return
+			}
+
+			// Users own their own ledgers. Any other ledger must be shared with the user.
+			role := models.RoleOwner
+			ledgerID := request.Header.Get(LedgerIDHeader)
+			if ledgerID != "" && ledgerID != ctxutils.GetTenantID(ctx) {
+				member, err := members.GetMember(ctx, ledgerID, ctxutils.GetUserID(ctx))
+				if err != nil {
+					// The ledgers that are not shared with the user are no different from the ones that do not exist.
+					var errHTTP *errutils.HTTPError
+					if errors.As(err, &errHTTP) && errHTTP.CustomCode == errutils.MemberNotFound().CustomCode {
+						err = errutils.Forbidden()
+					}
+					httputils.WriteErrAndLog(ctx, writer, err, log)
+					return
+				}
+
+				role = member.Role
+				ctx = ctxutils.PutTenantID(ctx, ledgerID)
+				*request = *request.WithContext(ctx)
+			}
+
+			if rolePermissions[role] < rule.permission {
+				httputils.WriteErrAndLog(ctx, writer, errutils.Forbidden(), log)
+				return
+			}
+
+			next.ServeHTTP(writer, request)
+		})
+	}
+}
@@ -0,0 +1,329 @@
+package middlewares
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/shivanshkc/ledgerkeep/src/logger"
+	"github.com/shivanshkc/ledgerkeep/src/utils/errutils"
+	"github.com/shivanshkc/ledgerkeep/src/utils/httputils"
+)
+
+// rateLimitSweepInterval is the interval at which the buckets and the lockouts that have no effect anymore are
+// forgotten, so they do not pile up in memory.
+const rateLimitSweepInterval = time.Minute
+
+// BucketLimit is the limit of a token bucket. Every request takes a token out of the bucket, which refills at a
+// constant rate. A zero rate disables the limit.
+type BucketLimit struct {
+	// RequestsPerSec is the rate at which the bucket refills.
+	RequestsPerSec float64
+	// Burst is the capacity of the bucket, which is the number of requests that can be made at once.
+	Burst int
+}
+
+// RouteGroupLimits are the rate limits of a group of routes.
+type RouteGroupLimits struct {
+	// Name identifies the group. Every group has its own buckets.
+	Name string
+	// PathPrefix is what the paths of the routes of the group start with.
+	PathPrefix string
+	// PerIP limits the requests of every client IP address.
+	PerIP BucketLimit
+	// PerCredential limits the requests of every username or bearer token, from any client IP address.
+	PerCredential BucketLimit
+}
+
+// LockoutPolicy tells how the client IP addresses and the credentials are locked out after repeated 401 responses.
+type LockoutPolicy struct {
+	// MaxFailures is the number of 401 responses after which the lockouts start. Zero disables them.
+	// The failures of a credential end with its next accepted request. Those of a client IP address only end after
+	// MaxDuration without any.
+	MaxFailures int
+	// BaseDuration is the duration of the first lockout. Every further failure doubles it.
+	BaseDuration time.Duration
+	// MaxDuration is the maximum duration of a lockout. The failures are forgotten after this much time without any.
+	MaxDuration time.Duration
+}
+
+// RateLimiter middleware limits the requests of every client IP address and every credential with token buckets, and
+// locks them out after repeated 401 responses. The rejected requests get the TooManyRequests error, along with the
+// Retry-After header.
+//
+// A request is limited by the first of the groups whose path prefix matches it. The requests that match no group are
+// only subject to the lockouts. It must run before the Auth middleware, so it can see the 401 responses.
+//
+// The client IP address is the remote address of the request. The x-real-ip and x-forwarded-for headers are only
+// believed for the requests that come from the trusted proxies, as any client can set them.
+func RateLimiter(groups []RouteGroupLimits, lockout LockoutPolicy,
+	trustedProxies []*net.IPNet) func(http.Handler) http.Handler {
+	return newRateLimiter(groups, lockout, trustedProxies).middleware
+}
+
+// newRateLimiter provides a new rateLimiter without any buckets or lockouts.
+func newRateLimiter(groups []RouteGroupLimits, lockout LockoutPolicy, trustedProxies []*net.IPNet) *rateLimiter {
+	return &rateLimiter{
+		groups:         groups,
+		lockout:        lockout,
+		trustedProxies: trustedProxies,
+		now:            time.Now,
+		mutex:          &sync.Mutex{},
+		buckets:        map[string]*tokenBucket{},
+		lockouts:       map[string]*lockoutState{},
+	}
+}
+
+// middleware is the RateLimiter middleware.
+func (r *rateLimiter) middleware(next http.Handler) http.Handler {
+	log := logger.Get()
+
+	return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		ctx := request.Context()
+
+		// Getting client's IP address. The requests with unknown addresses share a bucket.
+		clientIP, err := httputils.GetTrustedClientIP(request, r.trustedProxies)
+		if err != nil {
+			log.Error(ctx, &logger.Entry{Payload: fmt.Errorf("failed to get client IP address: %w", err)})
+			clientIP = "unknown"
+		}
+
+		keys := []string{"ip:" + clientIP}
+		if credential := getCredentialKey(request); credential != "" {
+			keys = append(keys, credential)
+		}
+
+		if retryAfter := r.take(request.URL.Path, keys, r.now()); retryAfter > 0 {
+			// Retry-After is in whole seconds, so it is rounded up to not be too early.
+			writer.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+			httputils.WriteErrAndLog(ctx, writer, errutils.TooManyRequests(), log)
+			return
+		}
+
+		// Wrapping the writer with a custom writer for persisting statusCode.
+		customWriter := &responseWriterWithCode{ResponseWriter: writer}
+		next.ServeHTTP(customWriter, request)
+
+		r.record(keys, customWriter.statusCode == http.StatusUnauthorized, r.now())
+	})
+}
+
+// getCredentialKey provides the key of the credentials of the request, which is empty if it has none.
+// The bearer tokens are hashed, so they are not kept in memory.
+func getCredentialKey(request *http.Request) string {
+	if username, _, ok := request.BasicAuth(); ok {
+		return "user:" + username
+	}
+
+	authorization := request.Header.Get("Authorization")
+	if len(authorization) > len(bearerPrefix) && strings.EqualFold(authorization[:len(bearerPrefix)], bearerPrefix) {
+		hash := sha256.Sum256([]byte(authorization[len(bearerPrefix):]))
+		return "bearer:" + hex.EncodeToString(hash[:])
+	}
+
+	return ""
+}
+
+// tokenBucket is the state of a token bucket.
+type tokenBucket struct {
+	// tokens is the number of tokens in the bucket, as of the updatedAt time.
+	tokens    float64
+	updatedAt time.Time
+	limit     BucketLimit
+}
+
+// refill adds the tokens that the bucket gained since the last update.
+func (t *tokenBucket) refill(now time.Time) {
+	t.tokens = math.Min(float64(t.limit.Burst), t.tokens+now.Sub(t.updatedAt).Seconds()*t.limit.RequestsPerSec)
+	t.updatedAt = now
+}
+
+// lockoutState is the state of the lockout of a client IP address or a credential.
+type lockoutState struct {
+	// failures is the number of 401 responses since the streak of failures started.
+	failures      int
+	lastFailureAt time.Time
+	lockedUntil   time.Time
+}
+
+// rateLimiter keeps the buckets and the lockouts of the RateLimiter middleware.
+type rateLimiter struct {
+	groups  []RouteGroupLimits
+	lockout LockoutPolicy
+	// trustedProxies are the networks of the proxies whose x-real-ip and x-forwarded-for headers are believed.
+	trustedProxies []*net.IPNet
+	// now provides the current time. Tests replace it to move the time forward.
+	now func() time.Time
+
+	// mutex guards the fields below it.
+	mutex *sync.Mutex
+	// buckets is a map of the group names and the keys to the buckets.
+	buckets map[string]*tokenBucket
+	// lockouts is a map of the keys to their lockouts.
+	lockouts map[string]*lockoutState
+	sweptAt  time.Time
+}
+
+// take takes a token out of every bucket of the keys for the path, and provides the time after which the request
+// can be retried if any of the keys is locked out or out of tokens. It provides zero if the request can proceed.
+//
+// The first key is of the client IP address, and the second one, if any, is of the credentials.
+func (r *rateLimiter) take(path string, keys []string, now time.Time) time.Duration {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.sweep(now)
+
+	// The locked out requests do not take any tokens.
+	var retryAfter time.Duration
+	for _, key := range keys {
+		if state, exists := r.lockouts[key]; exists && state.lockedUntil.After(now) {
+			retryAfter = maxDuration(retryAfter, state.lockedUntil.Sub(now))
+		}
+	}
+	if retryAfter > 0 {
+		return retryAfter
+	}
+
+	group := r.getGroup(path)
+	if group == nil {
+		return 0
+	}
+
+	// All the buckets are checked before any token is taken, so a rejected request does not use up any bucket.
+	buckets := make([]*tokenBucket, 0, len(keys))
+	for idx, key := range keys {
+		limit := group.PerIP
+		if idx > 0 {
+			limit = group.PerCredential
+		}
+		if limit.RequestsPerSec <= 0 {
+			continue
+		}
+
+		bucket := r.getBucket(group.Name+"|"+key, limit, now)
+		if bucket.tokens < 1 {
+			missingSec := (1 - bucket.tokens) / limit.RequestsPerSec
+			retryAfter = maxDuration(retryAfter, time.Duration(missingSec*float64(time.Second)))
+		}
+		buckets = append(buckets, bucket)
+	}
+	if retryAfter > 0 {
+		return retryAfter
+	}
+
+	for _, bucket := range buckets {
+		bucket.tokens--
+	}
+	return 0
+}
+
+// record updates the lockouts of the keys with the outcome of a request.
+//
+// The first key is of the client IP address, and the second one, if any, is of the credentials.
+func (r *rateLimiter) record(keys []string, isFailure bool, now time.Time) {
+	if r.lockout.MaxFailures <= 0 {
+		return
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if !isFailure {
+		// Any other response ends the streak of failures of the credentials, which were just accepted. The failures of
+		// the client IP address are only forgotten over time, or a client could reset them with its own credentials
+		// in between the guesses of the passwords of others.
+		for _, key := range keys[1:] {
+			delete(r.lockouts, key)
+		}
+		return
+	}
+
+	for _, key := range keys {
+		state, exists := r.lockouts[key]
+		// The failures are forgotten after the max duration without any.
+		if !exists || now.Sub(state.lastFailureAt) > r.lockout.MaxDuration {
+			state = &lockoutState{}
+			r.lockouts[key] = state
+		}
+
+		state.failures++
+		state.lastFailureAt = now
+		if state.failures < r.lockout.MaxFailures {
+			continue
+		}
+
+		// The lockouts double with every further failure. The shift is capped, so it cannot overflow.
+		doublings := state.failures - r.lockout.MaxFailures
+		if doublings > 30 {
+			doublings = 30
+		}
+		duration := r.lockout.BaseDuration << doublings
+		if duration <= 0 || duration > r.lockout.MaxDuration {
+			duration = r.lockout.MaxDuration
+		}
+		state.lockedUntil = now.Add(duration)
+	}
+}
+
+// getGroup provides the first group whose path prefix matches the path, or nil if none does.
+func (r *rateLimiter) getGroup(path string) *RouteGroupLimits {
+	for idx := range r.groups {
+		if strings.HasPrefix(path, r.groups[idx].PathPrefix) {
+			return &r.groups[idx]
+		}
+	}
+	return nil
+}
+
+// getBucket provides the refilled bucket of the key. The new buckets start full.
+func (r *rateLimiter) getBucket(key string, limit BucketLimit, now time.Time) *tokenBucket {
+	// A bucket must be able to hold at least one token, or it would reject everything.
+	if limit.Burst < 1 {
+		limit.Burst = 1
+	}
+
+	bucket, exists := r.buckets[key]
+	if !exists {
+		bucket = &tokenBucket{tokens: float64(limit.Burst), updatedAt: now, limit: limit}
+		r.buckets[key] = bucket
+	}
+
+	bucket.refill(now)
+	return bucket
+}
+
+// sweep forgets the buckets that are full and the lockouts that are over, as they have the same effect as the
+// missing ones. It does so at most once per rateLimitSweepInterval.
+func (r *rateLimiter) sweep(now time.Time) {
+	if now.Sub(r.sweptAt) < rateLimitSweepInterval {
+		return
+	}
+	r.sweptAt = now
+
+	for key, bucket := range r.buckets {
+		if bucket.refill(now); bucket.tokens >= float64(bucket.limit.Burst) {
+			delete(r.buckets, key)
+		}
+	}
+	for key, state := range r.lockouts {
+		if state.lockedUntil.Before(now) && now.Sub(state.lastFailureAt) > r.lockout.MaxDuration {
+			delete(r.lockouts, key)
+		}
+	}
+}
+
+// maxDuration provides the larger of the two durations.
+func maxDuration(a time.Duration, b time.Duration) time.Duration {
+	if a > b {
+		return a
+	}
+	return b
+}
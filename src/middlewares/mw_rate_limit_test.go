@@ -0,0 +1,185 @@
+package middlewares
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// testPassword is the only password that the handler behind the rate limiter of the tests accepts.
+const testPassword = "right-pass"
+
+// testRateLimiter is a RateLimiter middleware whose clock the tests move forward.
+type testRateLimiter struct {
+	handler http.Handler
+	now     time.Time
+}
+
+// newTestRateLimiter provides a RateLimiter middleware in front of a handler that responds with 401 to the requests
+// with a wrong password, like the Auth middleware does.
+func newTestRateLimiter(groups []RouteGroupLimits, lockout LockoutPolicy,
+	trustedProxies []*net.IPNet) *testRateLimiter {
+	testLimiter := &testRateLimiter{now: time.Date(2022, time.March, 1, 0, 0, 0, 0, time.UTC)}
+
+	limiter := newRateLimiter(groups, lockout, trustedProxies)
+	limiter.now = func() time.Time { return testLimiter.now }
+
+	testLimiter.handler = limiter.middleware(http.HandlerFunc(
+		func(writer http.ResponseWriter, request *http.Request) {
+			if _, password, ok := request.BasicAuth(); ok && password != testPassword {
+				writer.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			writer.WriteHeader(http.StatusOK)
+		}))
+	return testLimiter
+}
+
+// advance moves the clock of the rate limiter forward.
+func (t *testRateLimiter) advance(duration time.Duration) {
+	t.now = t.now.Add(duration)
+}
+
+// send sends a GET request from the remote IP address, with the basic auth credentials unless the username is
+// empty, and provides the recorded response.
+func (t *testRateLimiter) send(remoteIP, username, password, path string,
+	headers map[string]string) *httptest.ResponseRecorder {
+	request := httptest.NewRequest(http.MethodGet, path, nil)
+	request.RemoteAddr = net.JoinHostPort(remoteIP, "1234")
+	if username != "" {
+		request.SetBasicAuth(username, password)
+	}
+	for name, value := range headers {
+		request.Header.Set(name, value)
+	}
+
+	recorder := httptest.NewRecorder()
+	t.handler.ServeHTTP(recorder, request)
+	return recorder
+}
+
+// expectStatus fails the test if the response does not have the status code and the Retry-After header.
+// The Retry-After header is expected to be missing if it is empty.
+func expectStatus(t *testing.T, recorder *httptest.ResponseRecorder, statusCode int, retryAfter string) {
+	t.Helper()
+
+	if recorder.Code != statusCode || recorder.Header().Get("Retry-After") != retryAfter {
+		t.Fatalf("expected status %d with Retry-After %q, got: %d, %q", statusCode, retryAfter, recorder.Code,
+			recorder.Header().Get("Retry-After"))
+	}
+}
+
+func TestRateLimiterBuckets(t *testing.T) {
+	limiter := newTestRateLimiter([]RouteGroupLimits{{
+		Name:          "exports",
+		PathPrefix:    "/api/exports",
+		PerIP:         BucketLimit{RequestsPerSec: 0.5, Burst: 2},
+		PerCredential: BucketLimit{RequestsPerSec: 0.5, Burst: 2},
+	}}, LockoutPolicy{}, nil)
+
+	for i := 0; i < 2; i++ {
+		expectStatus(t, limiter.send("10.0.0.1", "alice", testPassword, "/api/exports", nil), http.StatusOK, "")
+	}
+
+	// The bucket refills one token in 2 seconds, and Retry-After is rounded up to whole seconds.
+	expectStatus(t, limiter.send("10.0.0.1", "alice", testPassword, "/api/exports", nil),
+		http.StatusTooManyRequests, "2")
+	limiter.advance(1500 * time.Millisecond)
+	expectStatus(t, limiter.send("10.0.0.1", "alice", testPassword, "/api/exports", nil),
+		http.StatusTooManyRequests, "1")
+	limiter.advance(500 * time.Millisecond)
+	expectStatus(t, limiter.send("10.0.0.1", "alice", testPassword, "/api/exports", nil), http.StatusOK, "")
+
+	// The other routes are not limited.
+	expectStatus(t, limiter.send("10.0.0.1", "alice", testPassword, "/api/accounts", nil), http.StatusOK, "")
+
+	// The bucket of the credentials, which has no tokens left, is shared by all the clients.
+	expectStatus(t, limiter.send("10.0.0.2", "alice", testPassword, "/api/exports", nil),
+		http.StatusTooManyRequests, "2")
+	// The rejected request did not take a token from the bucket of the new client.
+	for i := 0; i < 2; i++ {
+		expectStatus(t, limiter.send("10.0.0.2", "bob", testPassword, "/api/exports", nil), http.StatusOK, "")
+	}
+}
+
+func TestRateLimiterLockoutEscalation(t *testing.T) {
+	lockout := LockoutPolicy{MaxFailures: 3, BaseDuration: time.Minute, MaxDuration: 5 * time.Minute}
+	limiter := newTestRateLimiter(nil, lockout, nil)
+
+	for i := 0; i < 3; i++ {
+		expectStatus(t, limiter.send("10.0.0.1", "alice", "wrong-pass", "/api", nil), http.StatusUnauthorized, "")
+	}
+
+	// Every further failure doubles the lockout, up to the max duration.
+	for _, retryAfter := range []string{"60", "120", "240", "300", "300"} {
+		expectStatus(t, limiter.send("10.0.0.1", "alice", testPassword, "/api", nil),
+			http.StatusTooManyRequests, retryAfter)
+		// The credentials are locked out from the other clients too.
+		expectStatus(t, limiter.send("10.0.0.2", "alice", testPassword, "/api", nil),
+			http.StatusTooManyRequests, retryAfter)
+
+		duration, _ := time.ParseDuration(retryAfter + "s")
+		limiter.advance(duration)
+		expectStatus(t, limiter.send("10.0.0.1", "alice", "wrong-pass", "/api", nil), http.StatusUnauthorized, "")
+	}
+
+	// The failures are forgotten after the max duration without any.
+	limiter.advance(5*time.Minute + time.Second)
+	expectStatus(t, limiter.send("10.0.0.1", "alice", "wrong-pass", "/api", nil), http.StatusUnauthorized, "")
+	expectStatus(t, limiter.send("10.0.0.1", "alice", testPassword, "/api", nil), http.StatusOK, "")
+}
+
+func TestRateLimiterLockoutReset(t *testing.T) {
+	lockout := LockoutPolicy{MaxFailures: 3, BaseDuration: time.Minute, MaxDuration: 5 * time.Minute}
+	limiter := newTestRateLimiter(nil, lockout, nil)
+
+	// The accepted credentials of a client do not reset the failures of its IP address.
+	expectStatus(t, limiter.send("10.0.0.1", "bob", "wrong-pass", "/api", nil), http.StatusUnauthorized, "")
+	expectStatus(t, limiter.send("10.0.0.1", "carol", "wrong-pass", "/api", nil), http.StatusUnauthorized, "")
+	expectStatus(t, limiter.send("10.0.0.1", "mallory", testPassword, "/api", nil), http.StatusOK, "")
+	expectStatus(t, limiter.send("10.0.0.1", "dave", "wrong-pass", "/api", nil), http.StatusUnauthorized, "")
+	expectStatus(t, limiter.send("10.0.0.1", "mallory", testPassword, "/api", nil), http.StatusTooManyRequests, "60")
+
+	// Only the client is locked out, as every credential failed once.
+	expectStatus(t, limiter.send("10.0.0.2", "bob", testPassword, "/api", nil), http.StatusOK, "")
+
+	// The accepted credentials reset their own failures.
+	expectStatus(t, limiter.send("10.0.0.3", "alice", "wrong-pass", "/api", nil), http.StatusUnauthorized, "")
+	expectStatus(t, limiter.send("10.0.0.4", "alice", "wrong-pass", "/api", nil), http.StatusUnauthorized, "")
+	expectStatus(t, limiter.send("10.0.0.5", "alice", testPassword, "/api", nil), http.StatusOK, "")
+	expectStatus(t, limiter.send("10.0.0.6", "alice", "wrong-pass", "/api", nil), http.StatusUnauthorized, "")
+	expectStatus(t, limiter.send("10.0.0.7", "alice", testPassword, "/api", nil), http.StatusOK, "")
+}
+
+func TestRateLimiterTrustedProxies(t *testing.T) {
+	groups := []RouteGroupLimits{{Name: "api", PathPrefix: "/api", PerIP: BucketLimit{RequestsPerSec: 0.001, Burst: 1}}}
+	_, proxies, _ := net.ParseCIDR("10.0.0.0/8")
+
+	// Without trusted proxies, the headers that the clients set are ignored.
+	limiter := newTestRateLimiter(groups, LockoutPolicy{}, nil)
+	expectStatus(t, limiter.send("203.0.113.1", "", "", "/api", map[string]string{"x-real-ip": "198.51.100.1"}),
+		http.StatusOK, "")
+	expectStatus(t, limiter.send("203.0.113.1", "", "", "/api", map[string]string{"x-real-ip": "198.51.100.2"}),
+		http.StatusTooManyRequests, "1000")
+	expectStatus(t, limiter.send("203.0.113.1", "", "", "/api", map[string]string{"x-forwarded-for": "198.51.100.3"}),
+		http.StatusTooManyRequests, "1000")
+
+	// Behind the trusted proxies, the client is the last address of x-forwarded-for that is not of a trusted proxy.
+	limiter = newTestRateLimiter(groups, LockoutPolicy{}, []*net.IPNet{proxies})
+	expectStatus(t, limiter.send("10.0.0.1", "", "", "/api",
+		map[string]string{"x-forwarded-for": "198.51.100.1, 203.0.113.1, 10.0.0.2"}), http.StatusOK, "")
+	expectStatus(t, limiter.send("10.0.0.1", "", "", "/api",
+		map[string]string{"x-forwarded-for": "198.51.100.2, 203.0.113.1"}), http.StatusTooManyRequests, "1000")
+	expectStatus(t, limiter.send("10.0.0.3", "", "", "/api", map[string]string{"x-real-ip": "203.0.113.1"}),
+		http.StatusTooManyRequests, "1000")
+	expectStatus(t, limiter.send("10.0.0.3", "", "", "/api", map[string]string{"x-real-ip": "203.0.113.2"}),
+		http.StatusOK, "")
+
+	// The requests that do not come from the trusted proxies are limited by their remote addresses.
+	expectStatus(t, limiter.send("203.0.113.3", "", "", "/api", map[string]string{"x-real-ip": "203.0.113.4"}),
+		http.StatusOK, "")
+	expectStatus(t, limiter.send("203.0.113.3", "", "", "/api", map[string]string{"x-real-ip": "203.0.113.5"}),
+		http.StatusTooManyRequests, "1000")
+}
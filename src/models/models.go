@@ -6,14 +6,17 @@ type AccountDTO struct {
 	ID string `bson:"_id" json:"id"`
 	// Name is displayable name of the account.
 	Name string `bson:"name" json:"name"`
+	// Currency is the ISO 4217 code of the currency of the account.
+	// The amounts of all transactions of the account are in this currency.
+	Currency string `bson:"currency" json:"currency"`
 }
 
 // TransactionDTO is the schema of a transaction object as stored in the database.
 type TransactionDTO struct {
 	// ID is the identifier of the transaction.
 	ID string `bson:"_id,omitempty" json:"id,omitempty"`
-	// Amount of the transaction.
-	Amount float64 `bson:"amount" json:"amount"`
+	// Amount of the transaction. It is positive for credits and negative for debits.
+	Amount Money `bson:"amount" json:"amount"`
 	// Timestamp of the transaction.
 	Timestamp int64 `bson:"timestamp" json:"timestamp"`
 	// AccountID is the ID of the account to which the transaction belongs.
@@ -22,29 +25,317 @@ type TransactionDTO struct {
 	Category string `bson:"category" json:"category"`
 	// Notes are any details about the transaction.
 	Notes string `bson:"notes" json:"notes"`
+	// TransferID links the legs of a transfer between accounts. It is empty for all other transactions.
+	TransferID string `bson:"transfer_id,omitempty" json:"transfer_id,omitempty"`
+	// ExternalID is the identifier of the transaction in the bank statement that it was imported from, like the FITID
+	// of OFX. It keeps the same statement transaction from being imported twice. It is empty for all other transactions.
+	ExternalID string `bson:"external_id,omitempty" json:"external_id,omitempty"`
+	// Splits divide the amount of the transaction among several categories.
+	// If present, their amounts add up to the amount of the transaction.
+	Splits []*SplitDTO `bson:"splits,omitempty" json:"splits,omitempty"`
+	// Tags label the transaction, independently of its category.
+	Tags Tags `bson:"tags,omitempty" json:"tags,omitempty"`
 
 	// ClosingBal for this transaction.
 	// This is calculated before returning a response, and not stored in the database.
-	ClosingBal float64 `bson:"-" json:"closing_bal"`
+	ClosingBal Money `bson:"-" json:"closing_bal"`
+}
+
+// These are the kinds of categories, which tell the signs of the amounts that a category allows.
+const (
+	CategoryKindCredit = "credit"
+	CategoryKindDebit  = "debit"
+	CategoryKindBoth   = "both"
+)
+
+// These are the budget groups, which tell how the amounts of a category contribute to the budget.
+const (
+	BudgetGroupIncome      = "income"
+	BudgetGroupEssentials  = "essentials"
+	BudgetGroupInvestments = "investments"
+	BudgetGroupSavings     = "savings"
+	BudgetGroupLuxury      = "luxury"
+	BudgetGroupIgnorable   = "ignorable"
+)
+
+// CategoryDTO is the schema of a category object as stored in the database.
+type CategoryDTO struct {
+	// ID is the identifier of the category, which the transactions use as their category.
+	ID string `bson:"_id" json:"id"`
+	// Name is displayable name of the category.
+	Name string `bson:"name" json:"name"`
+	// Kind is one of the category kinds. It tells if the category is for credits, debits or both.
+	Kind string `bson:"kind" json:"kind"`
+	// BudgetGroup is one of the budget groups. It tells how the category contributes to the budget.
+	BudgetGroup string `bson:"budget_group" json:"budget_group"`
+}
+
+// SplitDTO is the schema of a split line of a transaction as stored in the database.
+type SplitDTO struct {
+	// Amount of the split line. It has the same sign as the amount of its transaction.
+	Amount Money `bson:"amount" json:"amount"`
+	// Category is one of the waterfall categories.
+	Category string `bson:"category" json:"category"`
+	// Notes are any details about the split line.
+	Notes string `bson:"notes" json:"notes"`
+}
+
+// ExchangeRateDTO is the schema of an exchange rate object as stored in the database.
+type ExchangeRateDTO struct {
+	// ID is the identifier of the exchange rate.
+	ID string `bson:"_id,omitempty" json:"id,omitempty"`
+	// Base is the ISO 4217 code of the currency that is converted.
+	Base string `bson:"base" json:"base"`
+	// Quote is the ISO 4217 code of the currency that the base currency is converted into.
+	Quote string `bson:"quote" json:"quote"`
+	// Rate is the amount of the quote currency that one unit of the base currency is worth.
+	Rate Rate `bson:"rate" json:"rate"`
+	// Timestamp is the time from which the rate is in effect.
+	Timestamp int64 `bson:"timestamp" json:"timestamp"`
+}
+
+// BudgetPlanDTO is the schema of a budget plan object as stored in the database.
+// A budget plan tells the expected contributions of the budget groups for the period in which it is valid.
+type BudgetPlanDTO struct {
+	// ID is the identifier of the budget plan.
+	ID string `bson:"_id,omitempty" json:"id,omitempty"`
+	// Name is the displayable name of the budget plan.
+	Name string `bson:"name" json:"name"`
+	// Currency is the ISO 4217 code of the currency of the fixed amounts of the allocations.
+	Currency string `bson:"currency" json:"currency"`
+	// ValidFrom is the time from which the budget plan is in effect.
+	ValidFrom int64 `bson:"valid_from" json:"valid_from"`
+	// ValidUntil is the time until which the budget plan is in effect, exclusive. Zero means that it never ends.
+	ValidUntil int64 `bson:"valid_until" json:"valid_until"`
+	// Allocations are the expected contributions of the budget groups.
+	// Budget groups without an allocation are not expected to have any expense.
+	Allocations []*BudgetAllocationDTO `bson:"allocations" json:"allocations"`
+}
+
+// BudgetAllocationDTO is the schema of the expected contribution of a budget group in a budget plan.
+// Exactly one of Percent and Amount is set.
+type BudgetAllocationDTO struct {
+	// BudgetGroup is one of the expense budget groups.
+	BudgetGroup string `bson:"budget_group" json:"budget_group"`
+	// Percent is the expected contribution as a percentage of the total income.
+	Percent *int64 `bson:"percent,omitempty" json:"percent,omitempty"`
+	// Amount is the expected contribution as a fixed amount for the budget period.
+	Amount *Money `bson:"amount,omitempty" json:"amount,omitempty"`
+}
+
+// RecurringTemplateDTO is the schema of a recurring transaction template object as stored in the database.
+// The occurrences of a template are materialized as transactions by the scheduler.
+type RecurringTemplateDTO struct {
+	// ID is the identifier of the recurring template.
+	ID string `bson:"_id,omitempty" json:"id,omitempty"`
+	// Name is the displayable name of the recurring template.
+	Name string `bson:"name" json:"name"`
+
+	// Amount, AccountID, Category and Notes are copied into every materialized transaction.
+	Amount    Money  `bson:"amount" json:"amount"`
+	AccountID string `bson:"account_id" json:"account_id"`
+	Category  string `bson:"category" json:"category"`
+	Notes     string `bson:"notes" json:"notes"`
+
+	// Rule is the RRULE style recurrence rule, like "FREQ=MONTHLY;BYMONTHDAY=1".
+	Rule string `bson:"rule" json:"rule"`
+	// StartTime is the time from which the rule applies. Its time of day is the time of day of all occurrences.
+	StartTime int64 `bson:"start_time" json:"start_time"`
+	// EndTime is the time after which there are no occurrences. Zero means that the template never ends.
+	EndTime int64 `bson:"end_time" json:"end_time"`
+	// NextRun is the time of the earliest occurrence that is not materialized yet.
+	// Zero means that all the occurrences are materialized.
+	NextRun int64 `bson:"next_run" json:"next_run"`
+}
+
+// ImportProfileDTO is the schema of a CSV import mapping profile object as stored in the database.
+// A profile describes the layout of the CSV statements of a bank, so they can be imported into an account.
+// Columns are referred to by their header names, which are matched case-insensitively.
+type ImportProfileDTO struct {
+	// ID is the identifier of the import profile.
+	ID string `bson:"_id,omitempty" json:"id,omitempty"`
+	// Name is the displayable name of the import profile.
+	Name string `bson:"name" json:"name"`
+	// AccountID is the account into which the transactions are imported.
+	AccountID string `bson:"account_id" json:"account_id"`
+
+	// Delimiter is the field delimiter of the CSV file, like "," or ";".
+	Delimiter string `bson:"delimiter" json:"delimiter"`
+	// DecimalSeparator is the decimal separator of the amounts, which is either "." or ",".
+	// The other one of the two is treated as a thousands separator.
+	DecimalSeparator string `bson:"decimal_separator" json:"decimal_separator"`
+
+	// DateColumn is the column of the transaction dates.
+	DateColumn string `bson:"date_column" json:"date_column"`
+	// DateFormat is the layout of the dates in the format of the Go time package, like "02/01/2006".
+	// Dates without a time zone are in UTC.
+	DateFormat string `bson:"date_format" json:"date_format"`
+
+	// AmountColumn is the column of the signed amounts.
+	// If it is empty, the amounts are read from the DebitColumn and the CreditColumn instead.
+	AmountColumn string `bson:"amount_column" json:"amount_column"`
+	// DebitColumn is the column of the debited amounts. The amounts are debits irrespective of their signs.
+	DebitColumn string `bson:"debit_column" json:"debit_column"`
+	// CreditColumn is the column of the credited amounts. The amounts are credits irrespective of their signs.
+	CreditColumn string `bson:"credit_column" json:"credit_column"`
+
+	// NotesColumn is the optional column of the transaction notes.
+	NotesColumn string `bson:"notes_column" json:"notes_column"`
+	// CategoryColumn is the optional column of the transaction categories.
+	CategoryColumn string `bson:"category_column" json:"category_column"`
+	// CreditCategory and DebitCategory are the categories of the credits and debits without a category of their own.
+	CreditCategory string `bson:"credit_category" json:"credit_category"`
+	DebitCategory  string `bson:"debit_category" json:"debit_category"`
+}
+
+// RuleDTO is the schema of an automatic categorization rule object as stored in the database.
+//
+// A rule matches the transactions that satisfy all of its conditions, and its actions are applied to them. The
+// conditions that are empty match all transactions, but a rule needs at least one action.
+type RuleDTO struct {
+	// ID is the identifier of the rule.
+	ID string `bson:"_id,omitempty" json:"id,omitempty"`
+	// Name is the displayable name of the rule.
+	Name string `bson:"name" json:"name"`
+	// Priority orders the rules. The rules with lower priorities are applied first.
+	Priority int `bson:"priority" json:"priority"`
+
+	// NotesPattern is a regular expression, in the syntax of the Go regexp package, that the notes should match.
+	NotesPattern string `bson:"notes_pattern" json:"notes_pattern"`
+	// MinAmount and MaxAmount are the inclusive bounds of the amount. Either of them may be absent.
+	MinAmount *Money `bson:"min_amount,omitempty" json:"min_amount,omitempty"`
+	MaxAmount *Money `bson:"max_amount,omitempty" json:"max_amount,omitempty"`
+	// AccountID is the account that the transactions should belong to.
+	AccountID string `bson:"account_id" json:"account_id"`
+	// Weekdays are the days of the week, in UTC, that the transactions should be on, as the two letter codes of
+	// the recurrence rules, like "MO" or "SA".
+	Weekdays []string `bson:"weekdays,omitempty" json:"weekdays,omitempty"`
+
+	// SetCategory is the category that the rule sets.
+	SetCategory string `bson:"set_category" json:"set_category"`
+	// SetNotes is the template of the notes that the rule sets. It may refer to the submatches of the NotesPattern,
+	// like "$1" or "${name}".
+	SetNotes string `bson:"set_notes" json:"set_notes"`
+	// AddTags are the tags that the rule adds.
+	AddTags Tags `bson:"add_tags,omitempty" json:"add_tags,omitempty"`
+}
+
+// UserDTO is the schema of a user object as stored in the database.
+type UserDTO struct {
+	// ID is the identifier of the user, which is also its basic auth username.
+	ID string `bson:"_id" json:"id"`
+	// PasswordHash is the bcrypt hash of the password of the user. It is never sent in responses.
+	PasswordHash string `bson:"password_hash" json:"-"`
+	// IsAdmin is true for the users that can manage the other users.
+	IsAdmin bool `bson:"is_admin" json:"is_admin"`
+	// TenantID is the identifier of the ledger of the user. All the data of the ledger, like the accounts and the
+	// transactions, is only accessible to this user.
+	TenantID string `bson:"tenant_id" json:"tenant_id"`
+	// CreatedAt is the time of creation of the user.
+	CreatedAt int64 `bson:"created_at" json:"created_at"`
+}
+
+// These are the roles that the members of a ledger can have.
+const (
+	// RoleOwner can do everything, including the invitation and revocation of the members.
+	RoleOwner = "owner"
+	// RoleEditor can read and change the data of the ledger.
+	RoleEditor = "editor"
+	// RoleViewer can only read the data of the ledger.
+	RoleViewer = "viewer"
+)
+
+// MemberDTO is the schema of a ledger membership object as stored in the database. It shares the ledger of a tenant
+// with a user of another tenant.
+type MemberDTO struct {
+	// LedgerID is the tenant ID of the shared ledger.
+	LedgerID string `bson:"ledger_id" json:"ledger_id"`
+	// UserID is the ID of the user that the ledger is shared with.
+	UserID string `bson:"user_id" json:"user_id"`
+	// Role tells what the user can do with the ledger.
+	Role string `bson:"role" json:"role"`
+	// CreatedAt is the time of the invitation of the user.
+	CreatedAt int64 `bson:"created_at" json:"created_at"`
+}
+
+// TokenScopes are the scopes that the API tokens can have. A scope allows an API token to use the routes of a resource,
+// and a write scope also allows the read routes of its resource.
+var TokenScopes = []string{
+	"ledgers:read",
+	"members:read", "members:write",
+	"users:read",
+	"accounts:read", "accounts:write",
+	"transactions:read", "transactions:write",
+	"categories:read", "categories:write",
+	"exchange-rates:read", "exchange-rates:write",
+	"budget-plans:read", "budget-plans:write",
+	"recurring-templates:read", "recurring-templates:write",
+	"import-profiles:read", "import-profiles:write",
+	"imports:write",
+	"exports:read",
+	"rules:read", "rules:write",
+	"backups:read", "backups:write",
+	"stats:read",
+}
+
+// TokenDTO is the schema of an API token object as stored in the database. The token itself is only shown at its
+// creation, as just its hash is stored.
+type TokenDTO struct {
+	// ID is the identifier of the token, which is used to list and revoke it.
+	ID string `bson:"_id" json:"id"`
+	// UserID is the ID of the user that the token authenticates as.
+	UserID string `bson:"user_id" json:"user_id"`
+	// Name tells what the token is for, like the script or the device that uses it.
+	Name string `bson:"name" json:"name"`
+	// Scopes are the TokenScopes that the token allows.
+	Scopes []string `bson:"scopes" json:"scopes"`
+	// TokenHash is the hex encoded SHA-256 hash of the token. It is never sent in responses.
+	TokenHash string `bson:"token_hash" json:"-"`
+	// ExpiresAt is the time after which the token is rejected. It is zero for the tokens that never expire.
+	ExpiresAt int64 `bson:"expires_at" json:"expires_at"`
+	// LastUsedAt is the time of the last request with the token, to the nearest minute. It is zero for the unused ones.
+	LastUsedAt int64 `bson:"last_used_at" json:"last_used_at"`
+	// CreatedAt is the time of creation of the token.
+	CreatedAt int64 `bson:"created_at" json:"created_at"`
+}
+
+// LedgerData is all the data of a ledger, as saved in its backups.
+type LedgerData struct {
+	Accounts           []*AccountDTO
+	Transactions       []*TransactionDTO
+	Categories         []*CategoryDTO
+	ExchangeRates      []*ExchangeRateDTO
+	BudgetPlans        []*BudgetPlanDTO
+	RecurringTemplates []*RecurringTemplateDTO
+	ImportProfiles     []*ImportProfileDTO
+	Rules              []*RuleDTO
 }
 
 // Budget is the schema of a budget object.
 // A budget provides information on the planned expense and the actual expense for a period.
 type Budget struct {
-	TotalIncome float64 `json:"total_income"`
+	// Currency is the ISO 4217 code of the currency of all the amounts of the budget.
+	Currency string `json:"currency"`
+	// PlanID is the ID of the budget plan that provides the expected amounts.
+	// It is empty if no budget plan is in effect, in which case the default contributions are used.
+	PlanID string `json:"plan_id,omitempty"`
+	// PlanName is the name of the budget plan that provides the expected amounts.
+	PlanName string `json:"plan_name,omitempty"`
+
+	TotalIncome Money `json:"total_income"`
 
-	EssentialsExpected float64 `json:"essentials_expected"`
-	EssentialsActual   float64 `json:"essentials_actual"`
+	EssentialsExpected Money `json:"essentials_expected"`
+	EssentialsActual   Money `json:"essentials_actual"`
 
-	InvestmentsExpected float64 `json:"investments_expected"`
-	InvestmentsActual   float64 `json:"investments_actual"`
+	InvestmentsExpected Money `json:"investments_expected"`
+	InvestmentsActual   Money `json:"investments_actual"`
 
-	SavingsExpected float64 `json:"savings_expected"`
-	SavingsActual   float64 `json:"savings_actual"`
+	SavingsExpected Money `json:"savings_expected"`
+	SavingsActual   Money `json:"savings_actual"`
 
-	LuxuryExpected float64 `json:"luxury_expected"`
-	LuxuryActual   float64 `json:"luxury_actual"`
+	LuxuryExpected Money `json:"luxury_expected"`
+	LuxuryActual   Money `json:"luxury_actual"`
 
-	IgnorableExpected float64 `json:"ignorable_expected"`
-	IgnorableActual   float64 `json:"ignorable_actual"`
+	IgnorableExpected Money `json:"ignorable_expected"`
+	IgnorableActual   Money `json:"ignorable_actual"`
 }
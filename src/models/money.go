@@ -0,0 +1,145 @@
+package models
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"math/big"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// MoneyDecimalPlaces is the number of decimal places that a Money value can hold.
+// Four places are enough for the minor units of every ISO 4217 currency.
+const MoneyDecimalPlaces = 4
+
+// moneyScale is the number of Money units in one currency unit.
+const moneyScale = 10000
+
+// maxMoneyLength is the maximum length of a decimal string that ParseMoney accepts. The longest Money value,
+// "-922337203685477.5808", is well within it.
+const maxMoneyLength = 32
+
+// moneyRegexp matches the plain decimal numbers, like "-12.34".
+// Unlike big.Rat, it does not accept the exponents, the fractions, the hex, octal and binary numbers, or the
+// underscores between the digits.
+var moneyRegexp = regexp.MustCompile(`^[+-]?\d+(\.\d+)?$`)
+
+// Money is an exact amount of money, stored as an integer number of ten-thousandths of the currency unit.
+//
+// Unlike float64, sums of Money values never drift. In JSON, it is written as a plain decimal number (like 12.5),
+// so the API stays compatible with the float64 amounts that it replaced.
+type Money int64
+
+// ParseMoney parses a plain decimal string, like "-12.34", into Money.
+// It returns an error if the value is not a plain decimal number, has more than MoneyDecimalPlaces decimal places or
+// does not fit into Money.
+func ParseMoney(value string) (Money, error) {
+	value = strings.TrimSpace(value)
+	// The length is checked first, so the huge values are rejected before they are matched or parsed.
+	if len(value) > maxMoneyLength {
+		return 0, fmt.Errorf("money value %q is out of range", value)
+	}
+	if !moneyRegexp.MatchString(value) {
+		return 0, fmt.Errorf("invalid money value: %q", value)
+	}
+	if dot := strings.IndexByte(value, '.'); dot >= 0 && len(value)-dot-1 > MoneyDecimalPlaces {
+		return 0, fmt.Errorf("money value %q has more than %d decimal places", value, MoneyDecimalPlaces)
+	}
+
+	rat, ok := new(big.Rat).SetString(value)
+	if !ok {
+		return 0, fmt.Errorf("invalid money value: %q", value)
+	}
+
+	rat.Mul(rat, big.NewRat(moneyScale, 1))
+	if !rat.IsInt() {
+		return 0, fmt.Errorf("money value %q has more than %d decimal places", value, MoneyDecimalPlaces)
+	}
+	if !rat.Num().IsInt64() {
+		return 0, fmt.Errorf("money value %q is out of range", value)
+	}
+
+	return Money(rat.Num().Int64()), nil
+}
+
+// Percent provides the given percentage of the amount, rounded half away from zero.
+func (m Money) Percent(percent int64) Money {
+	rat := new(big.Rat).SetFrac(new(big.Int).Mul(big.NewInt(int64(m)), big.NewInt(percent)), big.NewInt(100))
+	return Money(roundRat(rat))
+}
+
+// Convert provides the amount multiplied by the provided exchange rate, rounded half away from zero.
+func (m Money) Convert(rate *big.Rat) Money {
+	rat := new(big.Rat).Mul(new(big.Rat).SetInt64(int64(m)), rate)
+	return Money(roundRat(rat))
+}
+
+// String formats the amount as a decimal number without trailing zeros, like "-12.5".
+func (m Money) String() string {
+	sign := ""
+	units := new(big.Int).SetInt64(int64(m))
+	if units.Sign() < 0 {
+		sign = "-"
+		units.Neg(units)
+	}
+
+	whole, fraction := new(big.Int).QuoRem(units, big.NewInt(moneyScale), new(big.Int))
+	if fraction.Sign() == 0 {
+		return sign + whole.String()
+	}
+
+	fractionStr := fmt.Sprintf("%0*d", MoneyDecimalPlaces, fraction.Int64())
+	return sign + whole.String() + "." + strings.TrimRight(fractionStr, "0")
+}
+
+// MarshalJSON writes the amount as a JSON number.
+func (m Money) MarshalJSON() ([]byte, error) {
+	return []byte(m.String()), nil
+}
+
+// UnmarshalJSON reads the amount from a JSON number or a JSON string holding a decimal number.
+// The number is parsed from its decimal representation, so it never goes through float64.
+func (m *Money) UnmarshalJSON(data []byte) error {
+	value := string(data)
+	if value == "null" {
+		return nil
+	}
+
+	if strings.HasPrefix(value, `"`) {
+		unquoted, err := strconv.Unquote(value)
+		if err != nil {
+			return errors.New("money value should be a number or a numeric string")
+		}
+		value = unquoted
+	}
+
+	parsed, err := ParseMoney(value)
+	if err != nil {
+		return err
+	}
+
+	*m = parsed
+	return nil
+}
+
+// Value stores the amount in SQL databases as an integer number of Money units.
+func (m Money) Value() (driver.Value, error) {
+	return int64(m), nil
+}
+
+// roundRat rounds the rational number to the nearest integer, with halves rounded away from zero.
+func roundRat(rat *big.Rat) int64 {
+	num, denom := new(big.Int).Abs(rat.Num()), rat.Denom()
+
+	// Adding half of the denominator before the integer division rounds halves up.
+	rounded := new(big.Int).Mul(num, big.NewInt(2))
+	rounded.Add(rounded, denom)
+	rounded.Quo(rounded, new(big.Int).Mul(denom, big.NewInt(2)))
+
+	if rat.Sign() < 0 {
+		rounded.Neg(rounded)
+	}
+	return rounded.Int64()
+}
@@ -0,0 +1,116 @@
+package models
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestParseMoney(t *testing.T) {
+	testCases := []struct {
+		value    string
+		expected Money
+		isErr    bool
+	}{
+		{value: "0", expected: 0},
+		{value: "12.34", expected: 123400},
+		{value: "-0.0001", expected: -1},
+		{value: " 7 ", expected: 70000},
+		{value: "+5.5", expected: 55000},
+		{value: "-922337203685477.5808", expected: -9223372036854775808},
+		{value: "0.00001", isErr: true},
+		{value: "1.23450", isErr: true},
+		{value: "1/3", isErr: true},
+		{value: "abc", isErr: true},
+		{value: "", isErr: true},
+		{value: "922337203685477.5808", isErr: true},
+		{value: "1e3", isErr: true},
+		{value: "1E3", isErr: true},
+		{value: "1e100000", isErr: true},
+		{value: "0x10", isErr: true},
+		{value: "0b101", isErr: true},
+		{value: "0o17", isErr: true},
+		{value: "0x1p-2", isErr: true},
+		{value: "1_000", isErr: true},
+		{value: ".5", isErr: true},
+		{value: "5.", isErr: true},
+		{value: "--5", isErr: true},
+		{value: strings.Repeat("0", 40) + "1", isErr: true},
+	}
+
+	for _, testCase := range testCases {
+		parsed, err := ParseMoney(testCase.value)
+		if (err != nil) != testCase.isErr {
+			t.Errorf("value %q: expected error: %t, got: %+v", testCase.value, testCase.isErr, err)
+			continue
+		}
+		if parsed != testCase.expected {
+			t.Errorf("value %q: expected %d, got %d", testCase.value, testCase.expected, parsed)
+		}
+	}
+}
+
+func TestMoneyString(t *testing.T) {
+	testCases := []struct {
+		money    Money
+		expected string
+	}{
+		{money: 0, expected: "0"},
+		{money: 123400, expected: "12.34"},
+		{money: -5, expected: "-0.0005"},
+		{money: -10000, expected: "-1"},
+		{money: -9223372036854775808, expected: "-922337203685477.5808"},
+	}
+
+	for _, testCase := range testCases {
+		if str := testCase.money.String(); str != testCase.expected {
+			t.Errorf("money %d: expected %q, got %q", testCase.money, testCase.expected, str)
+		}
+	}
+}
+
+func TestMoneyJSON(t *testing.T) {
+	var decoded struct {
+		Number Money  `json:"number"`
+		String Money  `json:"string"`
+		Null   *Money `json:"null"`
+	}
+	if err := json.Unmarshal([]byte(`{"number":0.1,"string":"-0.2","null":null}`), &decoded); err != nil {
+		t.Fatalf("unexpected error in Unmarshal: %+v", err)
+	}
+	if decoded.Number != 1000 || decoded.String != -2000 || decoded.Null != nil {
+		t.Fatalf("unexpected decoded value: %+v", decoded)
+	}
+
+	// Summing decimals must not drift, unlike float64 where 0.1 + 0.2 != 0.3.
+	encoded, err := json.Marshal(decoded.Number + decoded.Number + decoded.Number)
+	if err != nil {
+		t.Fatalf("unexpected error in Marshal: %+v", err)
+	}
+	if string(encoded) != "0.3" {
+		t.Fatalf("expected 0.3, got %s", encoded)
+	}
+
+	if err := json.Unmarshal([]byte(`0.00001`), &decoded.Number); err == nil {
+		t.Fatalf("expected an error for too many decimal places")
+	}
+}
+
+func TestMoneyPercent(t *testing.T) {
+	testCases := []struct {
+		money    Money
+		percent  int64
+		expected Money
+	}{
+		{money: 1000, percent: 40, expected: 400},
+		{money: 5, percent: 50, expected: 3},
+		{money: -5, percent: 50, expected: -3},
+		{money: 1001, percent: 0, expected: 0},
+	}
+
+	for _, testCase := range testCases {
+		if result := testCase.money.Percent(testCase.percent); result != testCase.expected {
+			t.Errorf("%d%% of %d: expected %d, got %d", testCase.percent, testCase.money, testCase.expected, result)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// rateMaxDecimalPlaces is the maximum number of decimal places that a Rate can have.
+const rateMaxDecimalPlaces = 12
+
+// Rate is an exact positive decimal number, such as an exchange rate.
+//
+// It is kept as its normalized decimal representation (like "83.125"), so it is stored without any loss in every
+// database. In JSON, it is written as a plain decimal number.
+type Rate string
+
+// ParseRate parses a positive decimal string, like "0.0121", into a Rate.
+func ParseRate(value string) (Rate, error) {
+	value = strings.TrimSpace(value)
+	// big.Rat would also accept fractions like "1/3", which are not decimal numbers.
+	if value == "" || strings.Contains(value, "/") {
+		return "", fmt.Errorf("invalid rate: %q", value)
+	}
+
+	rat, ok := new(big.Rat).SetString(value)
+	if !ok {
+		return "", fmt.Errorf("invalid rate: %q", value)
+	}
+	if rat.Sign() <= 0 {
+		return "", fmt.Errorf("rate %q should be positive", value)
+	}
+
+	// Checking the number of decimal places by scaling the value up to an integer.
+	scaled := new(big.Rat).Mul(rat, new(big.Rat).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(rateMaxDecimalPlaces), nil)))
+	if !scaled.IsInt() {
+		return "", fmt.Errorf("rate %q has more than %d decimal places", value, rateMaxDecimalPlaces)
+	}
+
+	// Normalizing the representation, for example, "1.50" and "1.5" are the same rate.
+	normalized := rat.FloatString(rateMaxDecimalPlaces)
+	normalized = strings.TrimRight(strings.TrimRight(normalized, "0"), ".")
+
+	return Rate(normalized), nil
+}
+
+// Rat provides the rate as a rational number. It is nil if the rate is not a valid decimal number.
+func (r Rate) Rat() *big.Rat {
+	rat, ok := new(big.Rat).SetString(string(r))
+	if !ok {
+		return nil
+	}
+	return rat
+}
+
+// MarshalJSON writes the rate as a JSON number.
+func (r Rate) MarshalJSON() ([]byte, error) {
+	if r.Rat() == nil {
+		return nil, fmt.Errorf("invalid rate: %q", string(r))
+	}
+	return []byte(r), nil
+}
+
+// UnmarshalJSON reads the rate from a JSON number or a JSON string holding a decimal number.
+func (r *Rate) UnmarshalJSON(data []byte) error {
+	value := string(data)
+	if value == "null" {
+		return nil
+	}
+
+	if strings.HasPrefix(value, `"`) {
+		unquoted, err := strconv.Unquote(value)
+		if err != nil {
+			return errors.New("rate should be a number or a numeric string")
+		}
+		value = unquoted
+	}
+
+	parsed, err := ParseRate(value)
+	if err != nil {
+		return err
+	}
+
+	*r = parsed
+	return nil
+}
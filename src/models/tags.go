@@ -0,0 +1,51 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+)
+
+// Tags are the free-form labels of a transaction, like "vacation" or "reimbursable".
+//
+// In SQL databases, they are stored in a single column as a JSON array, which is empty for no tags.
+type Tags []string
+
+// Value stores the tags in SQL databases as a JSON array, or as an empty string if there are none.
+func (t Tags) Value() (driver.Value, error) {
+	if len(t) == 0 {
+		return "", nil
+	}
+
+	encoded, err := json.Marshal([]string(t))
+	if err != nil {
+		return nil, fmt.Errorf("error in json.Marshal call: %w", err)
+	}
+	return string(encoded), nil
+}
+
+// Scan reads the tags from SQL databases, as stored by Value.
+func (t *Tags) Scan(src interface{}) error {
+	var encoded []byte
+	switch asserted := src.(type) {
+	case nil:
+	case string:
+		encoded = []byte(asserted)
+	case []byte:
+		encoded = asserted
+	default:
+		return fmt.Errorf("cannot scan %T into tags", src)
+	}
+
+	if len(encoded) == 0 {
+		*t = nil
+		return nil
+	}
+
+	var tags []string
+	if err := json.Unmarshal(encoded, &tags); err != nil {
+		return fmt.Errorf("error in json.Unmarshal call: %w", err)
+	}
+	*t = tags
+	return nil
+}
@@ -0,0 +1,230 @@
+package oidc
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/shivanshkc/ledgerkeep/src/logger"
+)
+
+const (
+	// discoveryPath is the path of the discovery document of an issuer, relative to the URL of the issuer.
+	discoveryPath = "/.well-known/openid-configuration"
+	// minKeyRefreshInterval is the minimum interval between the fetches of the keys. Without it, the tokens with unknown
+	// key IDs, which anyone can make, could flood the issuer with requests.
+	minKeyRefreshInterval = 10 * time.Second
+	// maxFetchSize is the maximum size of the documents that are fetched from the issuer.
+	maxFetchSize = 1 << 20
+)
+
+// jwkCurves maps the "crv" values of the elliptic curve keys to their curves.
+var jwkCurves = map[string]elliptic.Curve{
+	"P-256": elliptic.P256(),
+	"P-384": elliptic.P384(),
+	"P-521": elliptic.P521(),
+}
+
+// signingKey is a public key of the issuer.
+type signingKey struct {
+	// algorithm is the only signing algorithm that the key can be used with. It is empty if the key does not say.
+	algorithm string
+	publicKey crypto.PublicKey
+}
+
+// jsonWebKey is the schema of a key of a JSON Web Key Set.
+type jsonWebKey struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	// N and E are the modulus and the exponent of the RSA keys.
+	N string `json:"n"`
+	E string `json:"e"`
+	// Curve, X and Y are the curve and the coordinates of the elliptic curve keys.
+	Curve string `json:"crv"`
+	X     string `json:"x"`
+	Y     string `json:"y"`
+}
+
+// keySet caches the signing keys of the issuer, by their key IDs.
+type keySet struct {
+	// mutex guards the fields below it, and makes sure that the keys are fetched by one request at a time.
+	mutex *sync.Mutex
+
+	issuer          string
+	client          *http.Client
+	refreshInterval time.Duration
+	// now provides the current time. Tests replace it to move the time forward.
+	now func() time.Time
+
+	// jwksURI is the URL of the key set, as per the discovery document of the issuer.
+	jwksURI string
+	keys    map[string]*signingKey
+	// fetchedAt is the time of the last successful fetch of the keys.
+	fetchedAt time.Time
+	// attemptedAt is the time of the last attempt to fetch the keys.
+	attemptedAt time.Time
+}
+
+// getKey provides the signing key with the provided key ID, fetching the keys again if they are stale or if it is
+// unknown.
+func (k *keySet) getKey(ctx context.Context, keyID string) (*signingKey, error) {
+	k.mutex.Lock()
+	defer k.mutex.Unlock()
+
+	now := k.now()
+	key, exists := k.keys[keyID]
+	if exists && now.Sub(k.fetchedAt) < k.refreshInterval {
+		return key, nil
+	}
+
+	if now.Sub(k.attemptedAt) >= minKeyRefreshInterval {
+		k.attemptedAt = now
+		if err := k.refresh(ctx); err != nil {
+			// The keys that were fetched before can still be used while the issuer is unavailable.
+			if !exists {
+				return nil, err
+			}
+			logger.Get().Warn(ctx, &logger.Entry{Payload: fmt.Errorf("using stale oidc keys: %w", err)})
+		} else {
+			k.fetchedAt = now
+		}
+		key, exists = k.keys[keyID]
+	}
+
+	if !exists {
+		return nil, fmt.Errorf("%w: unknown key ID: %s", ErrInvalidToken, keyID)
+	}
+	return key, nil
+}
+
+// refresh fetches the keys of the issuer, replacing the cached ones.
+func (k *keySet) refresh(ctx context.Context) error {
+	// The location of the keys is discovered only once.
+	if k.jwksURI == "" {
+		var discovery struct {
+			Issuer  string `json:"issuer"`
+			JWKSURI string `json:"jwks_uri"`
+		}
+		if err := k.fetchJSON(ctx, strings.TrimSuffix(k.issuer, "/")+discoveryPath, &discovery); err != nil {
+			return err
+		}
+		if strings.TrimSuffix(discovery.Issuer, "/") != strings.TrimSuffix(k.issuer, "/") {
+			return fmt.Errorf("discovery document is of another issuer: %s", discovery.Issuer)
+		}
+		if discovery.JWKSURI == "" {
+			return fmt.Errorf("discovery document has no jwks_uri")
+		}
+		k.jwksURI = discovery.JWKSURI
+	}
+
+	var jwks struct {
+		Keys []*jsonWebKey `json:"keys"`
+	}
+	if err := k.fetchJSON(ctx, k.jwksURI, &jwks); err != nil {
+		return err
+	}
+
+	keys := map[string]*signingKey{}
+	for _, jwk := range jwks.Keys {
+		// The encryption keys, and the ones of unsupported types, are of no use to verify the signatures.
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		publicKey, err := jwk.publicKey()
+		if err != nil {
+			logger.Get().Warn(ctx, &logger.Entry{Payload: fmt.Errorf("skipping oidc key %s: %w", jwk.KeyID, err)})
+			continue
+		}
+		keys[jwk.KeyID] = &signingKey{algorithm: jwk.Algorithm, publicKey: publicKey}
+	}
+
+	k.keys = keys
+	return nil
+}
+
+// fetchJSON fetches the JSON document at the URL into the target.
+func (k *keySet) fetchJSON(ctx context.Context, url string, target interface{}) error {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return fmt.Errorf("error in http.NewRequestWithContext call: %w", err)
+	}
+
+	response, err := k.client.Do(request)
+	if err != nil {
+		return fmt.Errorf("error in http.Client.Do call: %w", err)
+	}
+	defer func() { _ = response.Body.Close() }()
+
+	if response.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status code of %s: %d", url, response.StatusCode)
+	}
+	if err := json.NewDecoder(io.LimitReader(response.Body, maxFetchSize)).Decode(target); err != nil {
+		return fmt.Errorf("failed to decode %s: %w", url, err)
+	}
+	return nil
+}
+
+// publicKey decodes the public key of the JSON Web Key.
+func (j *jsonWebKey) publicKey() (crypto.PublicKey, error) {
+	switch j.KeyType {
+	case "RSA":
+		modulus, err := decodeBigInt(j.N)
+		if err != nil {
+			return nil, err
+		}
+		exponent, err := decodeBigInt(j.E)
+		if err != nil {
+			return nil, err
+		}
+		if !exponent.IsInt64() || exponent.Int64() > 1<<31-1 {
+			return nil, fmt.Errorf("exponent is too large")
+		}
+		return &rsa.PublicKey{N: modulus, E: int(exponent.Int64())}, nil
+
+	case "EC":
+		curve, exists := jwkCurves[j.Curve]
+		if !exists {
+			return nil, fmt.Errorf("unsupported curve: %s", j.Curve)
+		}
+		x, err := decodeBigInt(j.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBigInt(j.Y)
+		if err != nil {
+			return nil, err
+		}
+		if !curve.IsOnCurve(x, y) {
+			return nil, fmt.Errorf("point is not on the curve")
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+
+	default:
+		return nil, fmt.Errorf("unsupported key type: %s", j.KeyType)
+	}
+}
+
+// decodeBigInt decodes a base64url encoded big-endian integer.
+func decodeBigInt(value string) (*big.Int, error) {
+	decoded, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, fmt.Errorf("error in base64.RawURLEncoding.DecodeString call: %w", err)
+	}
+	if len(decoded) == 0 {
+		return nil, fmt.Errorf("empty integer")
+	}
+	return new(big.Int).SetBytes(decoded), nil
+}
@@ -0,0 +1,109 @@
+package oidc
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+
+	// The hash functions of the signing algorithms must be linked into the binary.
+	_ "crypto/sha256"
+	_ "crypto/sha512"
+)
+
+// signingHashes maps the supported signing algorithms to their hash functions.
+// The symmetric algorithms, and "none", are not supported, as only the issuer must be able to sign the tokens.
+var signingHashes = map[string]crypto.Hash{
+	"RS256": crypto.SHA256,
+	"RS384": crypto.SHA384,
+	"RS512": crypto.SHA512,
+	"ES256": crypto.SHA256,
+	"ES384": crypto.SHA384,
+	"ES512": crypto.SHA512,
+}
+
+// tokenHeader is the JOSE header of a token.
+type tokenHeader struct {
+	Algorithm string `json:"alg"`
+	KeyID     string `json:"kid"`
+}
+
+// token is a JSON Web Token in the compact serialization, whose signature is not verified yet.
+type token struct {
+	header tokenHeader
+	// payload is the decoded JSON of the claims.
+	payload []byte
+	// signingInput is the part of the token that the signature is of.
+	signingInput string
+	signature    []byte
+}
+
+// parseToken decodes the parts of the token, without verifying it.
+func parseToken(rawToken string) (*token, error) {
+	parts := strings.Split(rawToken, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("%w: expected 3 parts, got %d", ErrInvalidToken, len(parts))
+	}
+
+	headerJSON, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return nil, fmt.Errorf("%w: malformed header: %s", ErrInvalidToken, err.Error())
+	}
+	parsed := &token{signingInput: parts[0] + "." + parts[1]}
+	if err := json.Unmarshal(headerJSON, &parsed.header); err != nil {
+		return nil, fmt.Errorf("%w: malformed header: %s", ErrInvalidToken, err.Error())
+	}
+	if _, exists := signingHashes[parsed.header.Algorithm]; !exists {
+		return nil, fmt.Errorf("%w: unsupported algorithm: %s", ErrInvalidToken, parsed.header.Algorithm)
+	}
+
+	if parsed.payload, err = base64.RawURLEncoding.DecodeString(parts[1]); err != nil {
+		return nil, fmt.Errorf("%w: malformed payload: %s", ErrInvalidToken, err.Error())
+	}
+	if parsed.signature, err = base64.RawURLEncoding.DecodeString(parts[2]); err != nil {
+		return nil, fmt.Errorf("%w: malformed signature: %s", ErrInvalidToken, err.Error())
+	}
+
+	return parsed, nil
+}
+
+// verifySignature checks if the token is signed by the key with the algorithm of its header.
+func (t *token) verifySignature(key *signingKey) error {
+	// The algorithm of the header cannot be trusted before the verification, so it must suit the key.
+	if key.algorithm != "" && key.algorithm != t.header.Algorithm {
+		return fmt.Errorf("%w: algorithm %s does not suit the key", ErrInvalidToken, t.header.Algorithm)
+	}
+
+	hash := signingHashes[t.header.Algorithm]
+	hasher := hash.New()
+	_, _ = hasher.Write([]byte(t.signingInput))
+	digest := hasher.Sum(nil)
+
+	switch publicKey := key.publicKey.(type) {
+	case *rsa.PublicKey:
+		if !strings.HasPrefix(t.header.Algorithm, "RS") {
+			return fmt.Errorf("%w: algorithm %s does not suit the key", ErrInvalidToken, t.header.Algorithm)
+		}
+		if err := rsa.VerifyPKCS1v15(publicKey, hash, digest, t.signature); err != nil {
+			return fmt.Errorf("%w: bad signature", ErrInvalidToken)
+		}
+	case *ecdsa.PublicKey:
+		// The signature is the two integers, each of the size of the curve, one after the other.
+		size := (publicKey.Curve.Params().BitSize + 7) / 8
+		if !strings.HasPrefix(t.header.Algorithm, "ES") || len(t.signature) != 2*size {
+			return fmt.Errorf("%w: algorithm %s does not suit the key", ErrInvalidToken, t.header.Algorithm)
+		}
+		r, s := new(big.Int).SetBytes(t.signature[:size]), new(big.Int).SetBytes(t.signature[size:])
+		if !ecdsa.Verify(publicKey, digest, r, s) {
+			return fmt.Errorf("%w: bad signature", ErrInvalidToken)
+		}
+	default:
+		return fmt.Errorf("%w: unsupported key type %T", ErrInvalidToken, key.publicKey)
+	}
+
+	return nil
+}
@@ -0,0 +1,19 @@
+package oidc
+
+import (
+	"os"
+	"testing"
+
+	"github.com/shivanshkc/ledgerkeep/src/utils/testutils"
+)
+
+func TestMain(m *testing.M) {
+	cleanup, err := testutils.UseConfigs(testutils.DefaultConfigs)
+	if err != nil {
+		panic(err)
+	}
+
+	code := m.Run()
+	cleanup()
+	os.Exit(code)
+}
@@ -0,0 +1,166 @@
+// Package oidc verifies the access tokens of an OpenID Connect issuer, which are JSON Web Tokens signed by the keys of
+// the JSON Web Key Set of the issuer.
+package oidc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// clockSkew is the leeway of the time based claims, for the clocks of the issuer and the application to differ.
+	clockSkew = time.Minute
+	// defaultKeyRefreshInterval is the interval at which the keys are fetched again, if the configs do not specify one.
+	defaultKeyRefreshInterval = time.Hour
+	// fetchTimeout is the timeout of the requests to the issuer.
+	fetchTimeout = 10 * time.Second
+)
+
+// ErrInvalidToken is the error for the tokens that are malformed, forged, expired or meant for others.
+// The other errors of the Verifier are the failures to fetch the keys of the issuer.
+var ErrInvalidToken = errors.New("invalid token")
+
+// Claims are the claims of an access token that the application uses.
+type Claims struct {
+	// Issuer is the URL of the issuer of the token.
+	Issuer string `json:"iss"`
+	// Subject is the identifier of the user at the issuer.
+	Subject string `json:"sub"`
+	// Audience are the identifiers of the recipients that the token is meant for.
+	Audience audience `json:"aud"`
+	// ExpiresAt is the time after which the token is rejected.
+	ExpiresAt int64 `json:"exp"`
+	// NotBefore is the time before which the token is rejected. It is zero if the token does not have it.
+	NotBefore int64 `json:"nbf"`
+	// Email is the email address of the user, if the issuer includes it in the token.
+	Email string `json:"email"`
+	// EmailVerified tells if the issuer verified the email address. It is nil if the token does not have it.
+	EmailVerified *bool `json:"email_verified"`
+}
+
+// UserIDs provides the IDs of the users that the claims can belong to, in the order of preference.
+// The email address comes first, but only if the issuer says it is verified, and then the subject.
+// Some issuers let the users set any email address, and leave out the "email_verified" claim.
+func (c *Claims) UserIDs() []string {
+	var userIDs []string
+	if c.Email != "" && c.EmailVerified != nil && *c.EmailVerified {
+		userIDs = append(userIDs, c.Email)
+	}
+	if c.Subject != "" {
+		userIDs = append(userIDs, c.Subject)
+	}
+	return userIDs
+}
+
+// audience is the "aud" claim, which is either a single string or an array of strings.
+type audience []string
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (a *audience) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*a = audience{single}
+		return nil
+	}
+
+	var multiple []string
+	if err := json.Unmarshal(data, &multiple); err != nil {
+		return fmt.Errorf("aud should be a string or an array of strings: %w", err)
+	}
+	*a = multiple
+	return nil
+}
+
+// contains checks if the audience has the provided recipient.
+func (a audience) contains(recipient string) bool {
+	for _, value := range a {
+		if value == recipient {
+			return true
+		}
+	}
+	return false
+}
+
+// Verifier verifies the access tokens of an OpenID Connect issuer. It is safe for concurrent use.
+type Verifier struct {
+	// issuer is the URL of the issuer, which the tokens must have as their "iss" claim.
+	issuer string
+	// audience is what the tokens must have in their "aud" claim. It is not checked if empty.
+	audience string
+	// keys are the signing keys of the issuer.
+	keys *keySet
+}
+
+// NewVerifier provides a new Verifier for the tokens of the issuer.
+//
+// The keys of the issuer are fetched on the first verification, and again at the provided interval. They are also
+// fetched when a token is signed by an unknown key, which happens when the issuer rotates its keys.
+func NewVerifier(issuer string, audience string, keyRefreshInterval time.Duration) *Verifier {
+	if keyRefreshInterval <= 0 {
+		keyRefreshInterval = defaultKeyRefreshInterval
+	}
+
+	return &Verifier{
+		issuer:   issuer,
+		audience: audience,
+		keys: &keySet{
+			mutex:           &sync.Mutex{},
+			issuer:          issuer,
+			client:          &http.Client{Timeout: fetchTimeout},
+			refreshInterval: keyRefreshInterval,
+			now:             time.Now,
+		},
+	}
+}
+
+// Verify checks the signature and the claims of the access token, and provides the claims.
+func (v *Verifier) Verify(ctx context.Context, rawToken string) (*Claims, error) {
+	token, err := parseToken(rawToken)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := v.keys.getKey(ctx, token.header.KeyID)
+	if err != nil {
+		return nil, err
+	}
+	if err := token.verifySignature(key); err != nil {
+		return nil, err
+	}
+
+	claims := &Claims{}
+	if err := json.Unmarshal(token.payload, claims); err != nil {
+		return nil, fmt.Errorf("%w: malformed claims: %s", ErrInvalidToken, err.Error())
+	}
+	if err := v.checkClaims(claims); err != nil {
+		return nil, err
+	}
+
+	return claims, nil
+}
+
+// checkClaims checks if the claims are of a token of the issuer that is meant for the application, and is valid now.
+func (v *Verifier) checkClaims(claims *Claims) error {
+	if strings.TrimSuffix(claims.Issuer, "/") != strings.TrimSuffix(v.issuer, "/") {
+		return fmt.Errorf("%w: unexpected issuer: %s", ErrInvalidToken, claims.Issuer)
+	}
+	if v.audience != "" && !claims.Audience.contains(v.audience) {
+		return fmt.Errorf("%w: unexpected audience: %v", ErrInvalidToken, claims.Audience)
+	}
+
+	now := v.keys.now()
+	if claims.ExpiresAt == 0 || now.Add(-clockSkew).Unix() >= claims.ExpiresAt {
+		return fmt.Errorf("%w: expired or no expiry", ErrInvalidToken)
+	}
+	if claims.NotBefore != 0 && now.Add(clockSkew).Unix() < claims.NotBefore {
+		return fmt.Errorf("%w: not valid yet", ErrInvalidToken)
+	}
+
+	return nil
+}
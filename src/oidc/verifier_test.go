@@ -0,0 +1,154 @@
+package oidc
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shivanshkc/ledgerkeep/src/utils/testutils"
+)
+
+// newTestIssuer starts a stand-in issuer, which is closed at the end of the test.
+func newTestIssuer(t *testing.T) *testutils.OIDCIssuer {
+	t.Helper()
+
+	issuer, err := testutils.NewOIDCIssuer()
+	if err != nil {
+		t.Fatalf("failed to start the issuer: %+v", err)
+	}
+	t.Cleanup(issuer.Close)
+	return issuer
+}
+
+// signTestToken signs a token with the provided claims, on top of the valid claims of the issuer.
+func signTestToken(t *testing.T, issuer *testutils.OIDCIssuer, claims map[string]interface{}) string {
+	t.Helper()
+
+	allClaims := map[string]interface{}{
+		"iss": issuer.URL(),
+		"sub": "subject-1",
+		"aud": []string{"ledgerkeep", "other"},
+		"exp": time.Now().Add(time.Hour).Unix(),
+	}
+	for name, value := range claims {
+		if value == nil {
+			delete(allClaims, name)
+			continue
+		}
+		allClaims[name] = value
+	}
+
+	token, err := issuer.SignToken(allClaims)
+	if err != nil {
+		t.Fatalf("failed to sign the token: %+v", err)
+	}
+	return token
+}
+
+func TestVerify(t *testing.T) {
+	issuer := newTestIssuer(t)
+	verifier := NewVerifier(issuer.URL(), "ledgerkeep", 0)
+	ctx := context.Background()
+
+	claims, err := verifier.Verify(ctx, signTestToken(t, issuer, map[string]interface{}{
+		"email": "alice@example.com", "email_verified": true,
+	}))
+	if err != nil {
+		t.Fatalf("unexpected error in Verify: %+v", err)
+	}
+	if userIDs := claims.UserIDs(); len(userIDs) != 2 || userIDs[0] != "alice@example.com" || userIDs[1] != "subject-1" {
+		t.Fatalf("unexpected user IDs: %+v", userIDs)
+	}
+
+	claims, err = verifier.Verify(ctx, signTestToken(t, issuer, map[string]interface{}{
+		"aud": "ledgerkeep", "email": "alice@example.com", "email_verified": false,
+	}))
+	if err != nil {
+		t.Fatalf("unexpected error in Verify: %+v", err)
+	}
+	if userIDs := claims.UserIDs(); len(userIDs) != 1 || userIDs[0] != "subject-1" {
+		t.Fatalf("expected only the subject for an unverified email, got: %+v", userIDs)
+	}
+
+	// An email address is not trusted unless the issuer says it is verified.
+	claims, err = verifier.Verify(ctx, signTestToken(t, issuer, map[string]interface{}{"email": "alice@example.com"}))
+	if err != nil {
+		t.Fatalf("unexpected error in Verify: %+v", err)
+	}
+	if userIDs := claims.UserIDs(); len(userIDs) != 1 || userIDs[0] != "subject-1" {
+		t.Fatalf("expected only the subject for an email without email_verified, got: %+v", userIDs)
+	}
+
+	valid := signTestToken(t, issuer, nil)
+	parts := strings.Split(valid, ".")
+	forgedPayload := base64.RawURLEncoding.EncodeToString([]byte(`{"iss":"` + issuer.URL() + `","sub":"admin"}`))
+	unsignedHeader := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"none","kid":"key-1"}`))
+
+	invalidTokens := map[string]string{
+		"expired":        signTestToken(t, issuer, map[string]interface{}{"exp": time.Now().Add(-time.Hour).Unix()}),
+		"without expiry": signTestToken(t, issuer, map[string]interface{}{"exp": nil}),
+		"not yet valid":  signTestToken(t, issuer, map[string]interface{}{"nbf": time.Now().Add(time.Hour).Unix()}),
+		"other issuer":   signTestToken(t, issuer, map[string]interface{}{"iss": "https://example.com"}),
+		"other audience": signTestToken(t, issuer, map[string]interface{}{"aud": "other"}),
+		"forged":         parts[0] + "." + forgedPayload + "." + parts[2],
+		"unsigned":       unsignedHeader + "." + parts[1] + ".",
+		"malformed":      "not-a-token",
+	}
+	for name, token := range invalidTokens {
+		if _, err := verifier.Verify(ctx, token); !errors.Is(err, ErrInvalidToken) {
+			t.Errorf("expected ErrInvalidToken for the %s token, got: %+v", name, err)
+		}
+	}
+}
+
+func TestVerifyKeyRotation(t *testing.T) {
+	issuer := newTestIssuer(t)
+	verifier := NewVerifier(issuer.URL(), "", 10*time.Minute)
+	ctx := context.Background()
+
+	now := time.Now()
+	verifier.keys.now = func() time.Time { return now }
+
+	oldToken := signTestToken(t, issuer, nil)
+	for i := 0; i < 3; i++ {
+		if _, err := verifier.Verify(ctx, oldToken); err != nil {
+			t.Fatalf("unexpected error in Verify: %+v", err)
+		}
+	}
+	if requests := issuer.JWKSRequests(); requests != 1 {
+		t.Fatalf("expected the keys to be cached, got %d requests", requests)
+	}
+
+	if err := issuer.RotateKey(); err != nil {
+		t.Fatalf("failed to rotate the key: %+v", err)
+	}
+	newToken := signTestToken(t, issuer, nil)
+
+	// The unknown keys are fetched again, but not too often.
+	if _, err := verifier.Verify(ctx, newToken); !errors.Is(err, ErrInvalidToken) {
+		t.Fatalf("expected ErrInvalidToken right after the last fetch, got: %+v", err)
+	}
+
+	now = now.Add(minKeyRefreshInterval)
+	if _, err := verifier.Verify(ctx, newToken); err != nil {
+		t.Fatalf("unexpected error in Verify after the rotation: %+v", err)
+	}
+	if _, err := verifier.Verify(ctx, oldToken); !errors.Is(err, ErrInvalidToken) {
+		t.Fatalf("expected ErrInvalidToken for the rotated key, got: %+v", err)
+	}
+	if requests := issuer.JWKSRequests(); requests != 2 {
+		t.Fatalf("expected 2 requests, got %d", requests)
+	}
+
+	// The known keys are fetched again at the refresh interval.
+	now = now.Add(10 * time.Minute)
+	if _, err := verifier.Verify(ctx, newToken); err != nil {
+		t.Fatalf("unexpected error in Verify: %+v", err)
+	}
+	if requests := issuer.JWKSRequests(); requests != 3 {
+		t.Fatalf("expected 3 requests, got %d", requests)
+	}
+}
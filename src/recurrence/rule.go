@@ -0,0 +1,405 @@
+// Package recurrence implements the subset of the iCalendar recurrence rules (RFC 5545) that suits recurring
+// transactions, like "monthly on day 1", "every 2 weeks" or "on the last business day of every month".
+package recurrence
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// frequency is the period by which a rule repeats.
+type frequency int
+
+const (
+	daily frequency = iota
+	weekly
+	monthly
+	yearly
+)
+
+// maxPeriods is the maximum number of periods that are scanned for occurrences.
+// It stops the scan for rules that never occur again, like "BYMONTH=2;BYMONTHDAY=30".
+const maxPeriods = 10000
+
+// weekdayCodes maps the weekday codes of the rules to the weekdays.
+var weekdayCodes = map[string]time.Weekday{
+	"MO": time.Monday, "TU": time.Tuesday, "WE": time.Wednesday, "TH": time.Thursday,
+	"FR": time.Friday, "SA": time.Saturday, "SU": time.Sunday,
+}
+
+var (
+	errMissingFreq  = errors.New("rule should have a FREQ of DAILY, WEEKLY, MONTHLY or YEARLY")
+	errInvalidPart  = errors.New("rule parts should be KEY=VALUE pairs separated by semicolons")
+	errOrdinalByDay = errors.New("BYDAY can have ordinals like 1MO or -1FR only with MONTHLY or YEARLY frequency")
+	errLoneSetPos   = errors.New("BYSETPOS should be used along with BYDAY or BYMONTHDAY")
+)
+
+// weekdayNum is a weekday with an optional ordinal, like "-1FR" for the last Friday.
+// A zero ordinal stands for every such weekday of the period.
+type weekdayNum struct {
+	ordinal int
+	weekday time.Weekday
+}
+
+// Rule is a parsed recurrence rule.
+//
+// The supported parts are FREQ, INTERVAL, COUNT, BYDAY, BYMONTHDAY, BYMONTH and BYSETPOS. Unlike RFC 5545, the BYDAY
+// ordinals of a YEARLY rule count within the months of the rule, and not within the whole year.
+type Rule struct {
+	freq       frequency
+	interval   int
+	count      int
+	byDay      []weekdayNum
+	byMonthDay []int
+	byMonth    []time.Month
+	bySetPos   []int
+}
+
+// Parse parses a recurrence rule like "FREQ=MONTHLY;BYDAY=MO,TU,WE,TH,FR;BYSETPOS=-1".
+// The "RRULE:" prefix is optional, and the rule is case-insensitive.
+func Parse(rule string) (*Rule, error) {
+	rule = strings.TrimPrefix(strings.ToUpper(strings.TrimSpace(rule)), "RRULE:")
+	parsed := &Rule{freq: -1, interval: 1}
+
+	for _, part := range strings.Split(rule, ";") {
+		key, value, found := cut(part, "=")
+		if !found || value == "" {
+			return nil, errInvalidPart
+		}
+
+		var err error
+		switch key {
+		case "FREQ":
+			err = parsed.parseFreq(value)
+		case "INTERVAL":
+			parsed.interval, err = parseInt(key, value, 1, 1000)
+		case "COUNT":
+			parsed.count, err = parseInt(key, value, 1, 100000)
+		case "BYDAY":
+			parsed.byDay, err = parseByDay(value)
+		case "BYMONTHDAY":
+			parsed.byMonthDay, err = parseIntList(key, value, 31)
+		case "BYMONTH":
+			var months []int
+			months, err = parseIntList(key, value, 12)
+			for _, month := range months {
+				if month < 0 {
+					return nil, fmt.Errorf("%s values should be between 1 and 12", key)
+				}
+				parsed.byMonth = append(parsed.byMonth, time.Month(month))
+			}
+		case "BYSETPOS":
+			parsed.bySetPos, err = parseIntList(key, value, 366)
+		default:
+			return nil, fmt.Errorf("unsupported rule part: %s", key)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if parsed.freq < 0 {
+		return nil, errMissingFreq
+	}
+	for _, day := range parsed.byDay {
+		if day.ordinal != 0 && parsed.freq != monthly && parsed.freq != yearly {
+			return nil, errOrdinalByDay
+		}
+	}
+	if len(parsed.bySetPos) > 0 && len(parsed.byDay) == 0 && len(parsed.byMonthDay) == 0 {
+		return nil, errLoneSetPos
+	}
+
+	return parsed, nil
+}
+
+// Occurrences provides the occurrences of the rule that starts at the given time, in ascending order.
+// Only the occurrences at or after "from" and at or before "until" are provided, up to the given limit.
+// A zero "until" does not limit the occurrences by time.
+//
+// Every occurrence has the same time of day and location as the start time. The start time itself is an occurrence
+// only if it satisfies the rule.
+func (r *Rule) Occurrences(start time.Time, from time.Time, until time.Time, limit int) []time.Time {
+	var results []time.Time
+	counted := 0
+
+	for period := 0; period < maxPeriods && len(results) < limit; period++ {
+		// The periods and their days are in ascending order, so the first occurrence after "until" ends the scan.
+		for _, day := range r.expandPeriod(start, period*r.interval) {
+			occurrence := time.Date(day.Year(), day.Month(), day.Day(), start.Hour(), start.Minute(), start.Second(),
+				0, start.Location())
+			if occurrence.Before(start) {
+				continue
+			}
+			if !until.IsZero() && occurrence.After(until) {
+				return results
+			}
+
+			counted++
+			if r.count > 0 && counted > r.count {
+				return results
+			}
+			if !occurrence.Before(from) {
+				results = append(results, occurrence)
+				if len(results) == limit {
+					return results
+				}
+			}
+		}
+	}
+
+	return results
+}
+
+// expandPeriod provides the days of the period with the given offset from the period of the start time, in ascending
+// order. The days are in the location of the start time, at midnight.
+func (r *Rule) expandPeriod(start time.Time, offset int) []time.Time {
+	startDay := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, start.Location())
+
+	var days []time.Time
+	switch r.freq {
+	case daily:
+		day := startDay.AddDate(0, 0, offset)
+		if r.matchesMonth(day.Month()) && r.matchesMonthDay(day) && r.matchesWeekday(day.Weekday()) {
+			days = append(days, day)
+		}
+	case weekly:
+		// Weeks start on Monday.
+		weekStart := startDay.AddDate(0, 0, -((int(startDay.Weekday())+6)%7)+offset*7)
+		for i := 0; i < 7; i++ {
+			day := weekStart.AddDate(0, 0, i)
+			weekdayMatches := r.matchesWeekday(day.Weekday())
+			if len(r.byDay) == 0 {
+				weekdayMatches = day.Weekday() == start.Weekday()
+			}
+			if weekdayMatches && r.matchesMonth(day.Month()) {
+				days = append(days, day)
+			}
+		}
+	case monthly:
+		month := time.Date(start.Year(), start.Month()+time.Month(offset), 1, 0, 0, 0, 0, start.Location())
+		if r.matchesMonth(month.Month()) {
+			days = r.expandMonth(month, start.Day())
+		}
+	case yearly:
+		months := r.byMonth
+		if len(months) == 0 {
+			months = []time.Month{start.Month()}
+		}
+		for _, monthNumber := range sortedMonths(months) {
+			month := time.Date(start.Year()+offset, monthNumber, 1, 0, 0, 0, 0, start.Location())
+			days = append(days, r.expandMonth(month, start.Day())...)
+		}
+	}
+
+	return r.applySetPos(days)
+}
+
+// expandMonth provides the days of the month that satisfy the BYMONTHDAY and BYDAY parts, in ascending order.
+// Without these parts, the month has a single day with the given default day number, if the month is long enough.
+func (r *Rule) expandMonth(month time.Time, defaultDay int) []time.Time {
+	daysInMonth := month.AddDate(0, 1, -1).Day()
+
+	var days []time.Time
+	for dayNumber := 1; dayNumber <= daysInMonth; dayNumber++ {
+		day := month.AddDate(0, 0, dayNumber-1)
+
+		if len(r.byMonthDay) == 0 && len(r.byDay) == 0 {
+			if dayNumber == defaultDay {
+				days = append(days, day)
+			}
+			continue
+		}
+
+		if r.matchesMonthDay(day) && r.matchesMonthWeekday(day, daysInMonth) {
+			days = append(days, day)
+		}
+	}
+
+	return days
+}
+
+// applySetPos picks the days at the BYSETPOS positions out of the days of a period.
+// Negative positions count from the end of the period.
+func (r *Rule) applySetPos(days []time.Time) []time.Time {
+	if len(r.bySetPos) == 0 || len(days) == 0 {
+		return days
+	}
+
+	picked := map[int]bool{}
+	for _, position := range r.bySetPos {
+		index := position - 1
+		if position < 0 {
+			index = len(days) + position
+		}
+		if index >= 0 && index < len(days) {
+			picked[index] = true
+		}
+	}
+
+	var results []time.Time
+	for index, day := range days {
+		if picked[index] {
+			results = append(results, day)
+		}
+	}
+	return results
+}
+
+// matchesMonth checks the month against the BYMONTH part. Every month matches if the part is absent.
+func (r *Rule) matchesMonth(month time.Month) bool {
+	if len(r.byMonth) == 0 {
+		return true
+	}
+	for _, byMonth := range r.byMonth {
+		if byMonth == month {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesMonthDay checks the day against the BYMONTHDAY part. Every day matches if the part is absent.
+// Negative values count from the end of the month.
+func (r *Rule) matchesMonthDay(day time.Time) bool {
+	if len(r.byMonthDay) == 0 {
+		return true
+	}
+
+	daysInMonth := time.Date(day.Year(), day.Month()+1, 0, 0, 0, 0, 0, day.Location()).Day()
+	for _, monthDay := range r.byMonthDay {
+		if monthDay == day.Day() || (monthDay < 0 && daysInMonth+monthDay+1 == day.Day()) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesWeekday checks the weekday against the BYDAY part, ignoring any ordinals.
+// Every weekday matches if the part is absent.
+func (r *Rule) matchesWeekday(weekday time.Weekday) bool {
+	if len(r.byDay) == 0 {
+		return true
+	}
+	for _, day := range r.byDay {
+		if day.weekday == weekday {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesMonthWeekday checks the day against the BYDAY part, with the ordinals counting within the month.
+// Every day matches if the part is absent.
+func (r *Rule) matchesMonthWeekday(day time.Time, daysInMonth int) bool {
+	if len(r.byDay) == 0 {
+		return true
+	}
+
+	// The position of the day among the days of the same weekday in the month, from the start and from the end.
+	fromStart := (day.Day()-1)/7 + 1
+	fromEnd := -((daysInMonth-day.Day())/7 + 1)
+
+	for _, byDay := range r.byDay {
+		if byDay.weekday != day.Weekday() {
+			continue
+		}
+		if byDay.ordinal == 0 || byDay.ordinal == fromStart || byDay.ordinal == fromEnd {
+			return true
+		}
+	}
+	return false
+}
+
+// parseFreq parses the value of the FREQ part into the rule.
+func (r *Rule) parseFreq(value string) error {
+	switch value {
+	case "DAILY":
+		r.freq = daily
+	case "WEEKLY":
+		r.freq = weekly
+	case "MONTHLY":
+		r.freq = monthly
+	case "YEARLY":
+		r.freq = yearly
+	default:
+		return errMissingFreq
+	}
+	return nil
+}
+
+// parseByDay parses the value of the BYDAY part, like "MO,WE" or "-1FR".
+func parseByDay(value string) ([]weekdayNum, error) {
+	var results []weekdayNum
+	for _, item := range strings.Split(value, ",") {
+		if len(item) < 2 {
+			return nil, fmt.Errorf("invalid BYDAY value: %s", item)
+		}
+
+		weekday, exists := weekdayCodes[item[len(item)-2:]]
+		if !exists {
+			return nil, fmt.Errorf("invalid BYDAY value: %s", item)
+		}
+
+		ordinal := 0
+		if prefix := item[:len(item)-2]; prefix != "" {
+			parsed, err := strconv.Atoi(prefix)
+			if err != nil || parsed == 0 || parsed < -5 || parsed > 5 {
+				return nil, fmt.Errorf("invalid BYDAY value: %s", item)
+			}
+			ordinal = parsed
+		}
+
+		results = append(results, weekdayNum{ordinal: ordinal, weekday: weekday})
+	}
+	return results, nil
+}
+
+// parseIntList parses a comma separated list of non-zero integers between -maxAbs and maxAbs.
+func parseIntList(key string, value string, maxAbs int) ([]int, error) {
+	var results []int
+	for _, item := range strings.Split(value, ",") {
+		parsed, err := strconv.Atoi(item)
+		if err != nil || parsed == 0 || parsed < -maxAbs || parsed > maxAbs {
+			return nil, fmt.Errorf("%s values should be non-zero integers between -%d and %d", key, maxAbs, maxAbs)
+		}
+		results = append(results, parsed)
+	}
+	return results, nil
+}
+
+// parseInt parses an integer between min and max inclusive.
+func parseInt(key string, value string, min int, max int) (int, error) {
+	parsed, err := strconv.Atoi(value)
+	if err != nil || parsed < min || parsed > max {
+		return 0, fmt.Errorf("%s should be an integer between %d and %d", key, min, max)
+	}
+	return parsed, nil
+}
+
+// sortedMonths provides a sorted copy of the months without any duplicates.
+func sortedMonths(months []time.Month) []time.Month {
+	var sorted []time.Month
+	seen := map[time.Month]bool{}
+	for _, month := range months {
+		if !seen[month] {
+			seen[month] = true
+			sorted = append(sorted, month)
+		}
+	}
+
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	return sorted
+}
+
+// cut slices the value around the first instance of the separator, like strings.Cut of newer Go versions.
+func cut(value string, separator string) (string, string, bool) {
+	if index := strings.Index(value, separator); index >= 0 {
+		return value[:index], value[index+len(separator):], true
+	}
+	return value, "", false
+}
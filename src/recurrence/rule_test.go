@@ -0,0 +1,90 @@
+package recurrence
+
+import (
+	"testing"
+	"time"
+)
+
+func TestOccurrences(t *testing.T) {
+	// 2022-01-15 was a Saturday.
+	start := time.Date(2022, time.January, 15, 9, 30, 0, 0, time.UTC)
+
+	testCases := []struct {
+		rule     string
+		expected []string
+	}{
+		{rule: "FREQ=MONTHLY;BYMONTHDAY=1", expected: []string{"2022-02-01", "2022-03-01", "2022-04-01"}},
+		{rule: "FREQ=MONTHLY", expected: []string{"2022-01-15", "2022-02-15", "2022-03-15"}},
+		{rule: "FREQ=WEEKLY;INTERVAL=2", expected: []string{"2022-01-15", "2022-01-29", "2022-02-12"}},
+		{rule: "FREQ=WEEKLY;BYDAY=MO,TH", expected: []string{"2022-01-17", "2022-01-20", "2022-01-24"}},
+		{rule: "FREQ=DAILY;INTERVAL=10", expected: []string{"2022-01-15", "2022-01-25", "2022-02-04"}},
+		// The last day of every month, however long the month is.
+		{rule: "FREQ=MONTHLY;BYMONTHDAY=-1", expected: []string{"2022-01-31", "2022-02-28", "2022-03-31"}},
+		// Months without a 31st day are skipped.
+		{rule: "FREQ=MONTHLY;BYMONTHDAY=31", expected: []string{"2022-01-31", "2022-03-31", "2022-05-31"}},
+		// The last business day of every month. 2022-04-30 was a Saturday.
+		{rule: "RRULE:FREQ=MONTHLY;BYDAY=MO,TU,WE,TH,FR;BYSETPOS=-1", expected: []string{"2022-01-31", "2022-02-28", "2022-03-31", "2022-04-29"}},
+		{rule: "FREQ=MONTHLY;BYDAY=-1FR", expected: []string{"2022-01-28", "2022-02-25", "2022-03-25"}},
+		{rule: "FREQ=MONTHLY;BYDAY=2TU", expected: []string{"2022-02-08", "2022-03-08", "2022-04-12"}},
+		{rule: "FREQ=YEARLY;BYMONTH=4,1;BYMONTHDAY=1", expected: []string{"2022-04-01", "2023-01-01", "2023-04-01"}},
+		{rule: "freq=monthly;count=2", expected: []string{"2022-01-15", "2022-02-15"}},
+	}
+
+	for _, testCase := range testCases {
+		rule, err := Parse(testCase.rule)
+		if err != nil {
+			t.Fatalf("unexpected error for %s: %+v", testCase.rule, err)
+		}
+
+		occurrences := rule.Occurrences(start, start, time.Time{}, len(testCase.expected))
+		if len(occurrences) != len(testCase.expected) {
+			t.Errorf("expected %d occurrences for %s, got: %v", len(testCase.expected), testCase.rule, occurrences)
+			continue
+		}
+		for idx, occurrence := range occurrences {
+			if occurrence.Format("2006-01-02") != testCase.expected[idx] || occurrence.Hour() != 9 || occurrence.Minute() != 30 {
+				t.Errorf("expected %s at index %d for %s, got: %s", testCase.expected[idx], idx, testCase.rule, occurrence)
+			}
+		}
+	}
+}
+
+func TestOccurrencesWindow(t *testing.T) {
+	start := time.Date(2022, time.January, 1, 0, 0, 0, 0, time.UTC)
+	rule, err := Parse("FREQ=MONTHLY;COUNT=5")
+	if err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+
+	// The occurrences before "from" still count towards the COUNT.
+	from := time.Date(2022, time.March, 1, 0, 0, 0, 0, time.UTC)
+	if occurrences := rule.Occurrences(start, from, time.Time{}, 10); len(occurrences) != 3 {
+		t.Errorf("expected 3 occurrences, got: %v", occurrences)
+	}
+
+	until := time.Date(2022, time.February, 1, 0, 0, 0, 0, time.UTC)
+	if occurrences := rule.Occurrences(start, start, until, 10); len(occurrences) != 2 {
+		t.Errorf("expected 2 occurrences, got: %v", occurrences)
+	}
+
+	// A rule that can never occur again ends the scan.
+	never, err := Parse("FREQ=YEARLY;BYMONTH=2;BYMONTHDAY=30")
+	if err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+	if occurrences := never.Occurrences(start, start, time.Time{}, 1); len(occurrences) != 0 {
+		t.Errorf("expected no occurrences, got: %v", occurrences)
+	}
+}
+
+func TestParseInvalidRules(t *testing.T) {
+	for _, rule := range []string{
+		"", "BYMONTHDAY=1", "FREQ=HOURLY", "FREQ=MONTHLY;INTERVAL=0", "FREQ=MONTHLY;BYMONTHDAY=32",
+		"FREQ=WEEKLY;BYDAY=1MO", "FREQ=MONTHLY;BYDAY=XX", "FREQ=MONTHLY;BYSETPOS=1", "FREQ=MONTHLY;UNTIL=20220101",
+		"FREQ=MONTHLY;BYMONTH=-1",
+	} {
+		if _, err := Parse(rule); err == nil {
+			t.Errorf("expected an error for rule %q", rule)
+		}
+	}
+}
@@ -0,0 +1,169 @@
+// Package rules categorizes transactions automatically as per the rules of the user, like "the card payments whose
+// notes match 'UBER' are transport, tagged 'travel'".
+package rules
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/shivanshkc/ledgerkeep/src/models"
+)
+
+// weekdayCodes maps the weekday codes of the rules, which are those of the recurrence rules, to the weekdays.
+var weekdayCodes = map[string]time.Weekday{
+	"MO": time.Monday, "TU": time.Tuesday, "WE": time.Wednesday, "TH": time.Thursday,
+	"FR": time.Friday, "SA": time.Saturday, "SU": time.Sunday,
+}
+
+var (
+	errMissingAction = errors.New("rule should set a category, set notes or add tags")
+	errInvalidBounds = errors.New("min amount of the rule should not be greater than its max amount")
+)
+
+// compiledRule is a rule whose conditions are parsed, so they are quick to evaluate.
+type compiledRule struct {
+	*models.RuleDTO
+	pattern  *regexp.Regexp
+	weekdays map[time.Weekday]bool
+}
+
+// Engine evaluates a set of rules against transactions.
+type Engine struct {
+	rules []*compiledRule
+}
+
+// Result is the outcome of the rules for a transaction.
+type Result struct {
+	// Category is set by the first matching rule that sets an allowed category. It is empty if there is none.
+	Category string
+	// Notes are set by the first matching rule that sets notes. They are nil if there is none.
+	Notes *string
+	// Tags are the tags that the matching rules add, in the order of the rules and without repetitions.
+	Tags models.Tags
+	// RuleIDs are the IDs of all the matching rules.
+	RuleIDs []string
+}
+
+// NewEngine compiles the rules into an engine. The rules are applied in the provided order, which is usually that of
+// their priorities.
+func NewEngine(rules []*models.RuleDTO) (*Engine, error) {
+	engine := &Engine{rules: make([]*compiledRule, 0, len(rules))}
+	for _, rule := range rules {
+		compiled, err := compile(rule)
+		if err != nil {
+			return nil, fmt.Errorf("rule %s: %w", rule.ID, err)
+		}
+		engine.rules = append(engine.rules, compiled)
+	}
+	return engine, nil
+}
+
+// Validate checks that the conditions and the actions of the rule are well-formed.
+// It does not check that the category or the account of the rule exist.
+func Validate(rule *models.RuleDTO) error {
+	_, err := compile(rule)
+	return err
+}
+
+// Evaluate applies the rules to the transaction, which needs the amount, timestamp, account ID and notes fields.
+//
+// All the conditions of the rules are evaluated against the transaction as it is, so the notes that a rule sets do not
+// affect the other rules. The allowsCategory function tells if a category suits the amount of the transaction. The
+// categories that it does not allow are skipped, so a later rule may set the category.
+func (e *Engine) Evaluate(transaction *models.TransactionDTO, allowsCategory func(category string, amount models.Money) bool) *Result {
+	result := &Result{}
+	seenTags := map[string]bool{}
+
+	for _, rule := range e.rules {
+		submatches, matches := rule.match(transaction)
+		if !matches {
+			continue
+		}
+		result.RuleIDs = append(result.RuleIDs, rule.ID)
+
+		if result.Category == "" && rule.SetCategory != "" && allowsCategory(rule.SetCategory, transaction.Amount) {
+			result.Category = rule.SetCategory
+		}
+		if result.Notes == nil && rule.SetNotes != "" {
+			notes := rule.expandNotes(transaction.Notes, submatches)
+			result.Notes = &notes
+		}
+		for _, tag := range rule.AddTags {
+			if !seenTags[tag] {
+				seenTags[tag] = true
+				result.Tags = append(result.Tags, tag)
+			}
+		}
+	}
+
+	return result
+}
+
+// compile parses the conditions of the rule.
+func compile(rule *models.RuleDTO) (*compiledRule, error) {
+	if rule.SetCategory == "" && rule.SetNotes == "" && len(rule.AddTags) == 0 {
+		return nil, errMissingAction
+	}
+	if rule.MinAmount != nil && rule.MaxAmount != nil && *rule.MinAmount > *rule.MaxAmount {
+		return nil, errInvalidBounds
+	}
+
+	compiled := &compiledRule{RuleDTO: rule}
+
+	if rule.NotesPattern != "" {
+		pattern, err := regexp.Compile(rule.NotesPattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid notes pattern: %w", err)
+		}
+		compiled.pattern = pattern
+	}
+
+	if len(rule.Weekdays) > 0 {
+		compiled.weekdays = map[time.Weekday]bool{}
+		for _, code := range rule.Weekdays {
+			weekday, exists := weekdayCodes[strings.ToUpper(code)]
+			if !exists {
+				return nil, fmt.Errorf("invalid weekday: %q, it should be one of MO, TU, WE, TH, FR, SA and SU", code)
+			}
+			compiled.weekdays[weekday] = true
+		}
+	}
+
+	return compiled, nil
+}
+
+// match checks if the transaction satisfies all the conditions of the rule. If the rule has a notes pattern, it also
+// provides the indices of the submatches of the notes.
+func (c *compiledRule) match(transaction *models.TransactionDTO) ([]int, bool) {
+	if c.AccountID != "" && c.AccountID != transaction.AccountID {
+		return nil, false
+	}
+	if c.MinAmount != nil && transaction.Amount < *c.MinAmount {
+		return nil, false
+	}
+	if c.MaxAmount != nil && transaction.Amount > *c.MaxAmount {
+		return nil, false
+	}
+	// The weekdays are those of UTC, like the days of the recurrence rules and the bank statements.
+	if c.weekdays != nil && !c.weekdays[time.Unix(transaction.Timestamp, 0).UTC().Weekday()] {
+		return nil, false
+	}
+
+	if c.pattern == nil {
+		return nil, true
+	}
+	submatches := c.pattern.FindStringSubmatchIndex(transaction.Notes)
+	return submatches, submatches != nil
+}
+
+// expandNotes provides the notes that the rule sets, with the references to the submatches of its notes pattern,
+// like "$1" or "${name}", replaced. Without a notes pattern, the notes are set as they are.
+func (c *compiledRule) expandNotes(notes string, submatches []int) string {
+	if c.pattern == nil {
+		return c.SetNotes
+	}
+	return string(c.pattern.ExpandString(nil, c.SetNotes, notes, submatches))
+}
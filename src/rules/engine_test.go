@@ -0,0 +1,82 @@
+package rules
+
+import (
+	"testing"
+	"time"
+
+	"github.com/shivanshkc/ledgerkeep/src/models"
+)
+
+func TestEvaluate(t *testing.T) {
+	maxAmount := models.Money(-1)
+	rules := []*models.RuleDTO{
+		{ID: "uber", NotesPattern: `(?i)^uber\s+(\w+)`, MaxAmount: &maxAmount, SetCategory: "essentials",
+			SetNotes: "Uber ride to $1", AddTags: models.Tags{"travel"}},
+		{ID: "weekend", Weekdays: []string{"SA", "SU"}, SetCategory: "luxury", AddTags: models.Tags{"weekend", "travel"}},
+		{ID: "salary", AccountID: "bank", NotesPattern: "(?i)salary", SetCategory: "essentials"},
+		{ID: "fallback", SetCategory: "earnings", SetNotes: "Unknown"},
+	}
+
+	engine, err := NewEngine(rules)
+	if err != nil {
+		t.Fatalf("unexpected error in NewEngine: %+v", err)
+	}
+
+	// "essentials" is allowed only for debits, and "earnings" only for credits, like the default categories.
+	allows := func(category string, amount models.Money) bool {
+		return (category == "earnings") == (amount > 0)
+	}
+
+	// 2022-01-15 was a Saturday.
+	saturday := time.Date(2022, time.January, 15, 23, 0, 0, 0, time.UTC).Unix()
+	monday := time.Date(2022, time.January, 17, 10, 0, 0, 0, time.UTC).Unix()
+
+	result := engine.Evaluate(&models.TransactionDTO{
+		Amount: -150000, Timestamp: saturday, AccountID: "card", Notes: "UBER airport 1234",
+	}, allows)
+	if result.Category != "essentials" || result.Notes == nil || *result.Notes != "Uber ride to airport" {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+	if len(result.Tags) != 2 || result.Tags[0] != "travel" || result.Tags[1] != "weekend" {
+		t.Fatalf("unexpected tags: %+v", result.Tags)
+	}
+	if len(result.RuleIDs) != 3 || result.RuleIDs[2] != "fallback" {
+		t.Fatalf("unexpected matching rules: %+v", result.RuleIDs)
+	}
+
+	// The salary rule matches, but its category is not allowed for a credit, so the fallback rule sets it.
+	result = engine.Evaluate(&models.TransactionDTO{
+		Amount: 5000000, Timestamp: monday, AccountID: "bank", Notes: "Salary January",
+	}, allows)
+	if result.Category != "earnings" || result.Notes == nil || *result.Notes != "Unknown" || len(result.Tags) != 0 {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+
+	// The amount bound keeps the uber rule from matching a refund.
+	result = engine.Evaluate(&models.TransactionDTO{
+		Amount: 150000, Timestamp: monday, AccountID: "card", Notes: "Uber refund",
+	}, allows)
+	if len(result.RuleIDs) != 1 || result.RuleIDs[0] != "fallback" {
+		t.Fatalf("unexpected matching rules: %+v", result.RuleIDs)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	minAmount, maxAmount := models.Money(100), models.Money(10)
+
+	invalidRules := []*models.RuleDTO{
+		{NotesPattern: "salary"},
+		{NotesPattern: "(salary", SetCategory: "earnings"},
+		{Weekdays: []string{"XX"}, SetCategory: "earnings"},
+		{MinAmount: &minAmount, MaxAmount: &maxAmount, SetCategory: "earnings"},
+	}
+	for _, rule := range invalidRules {
+		if err := Validate(rule); err == nil {
+			t.Errorf("expected an error for rule: %+v", rule)
+		}
+	}
+
+	if err := Validate(&models.RuleDTO{Weekdays: []string{"mo"}, AddTags: models.Tags{"work"}}); err != nil {
+		t.Errorf("unexpected error: %+v", err)
+	}
+}
@@ -0,0 +1,206 @@
+// Package scheduler materializes the occurrences of the recurring transaction templates as transactions.
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/shivanshkc/ledgerkeep/src/database"
+	"github.com/shivanshkc/ledgerkeep/src/logger"
+	"github.com/shivanshkc/ledgerkeep/src/models"
+	"github.com/shivanshkc/ledgerkeep/src/recurrence"
+	"github.com/shivanshkc/ledgerkeep/src/rules"
+	"github.com/shivanshkc/ledgerkeep/src/utils/ctxutils"
+)
+
+// maxOccurrencesPerRun is the maximum number of occurrences of a template that a single run materializes.
+// A template that is further behind than this is caught up over the next runs.
+const maxOccurrencesPerRun = 1000
+
+// Scheduler periodically materializes the due occurrences of all the recurring templates of all the tenants.
+//
+// Every run moves the next run time of a template past the occurrences that it materializes, and the storage makes
+// sure that only one run gets to do that. So, the runs are idempotent, and any number of application instances can
+// run a Scheduler at the same time.
+//
+// The categorization rules apply to the materialized transactions, just like to the ones that are created through the
+// API, so a template leads to the same transactions either way.
+type Scheduler struct {
+	// users provides the tenants, whose templates are run.
+	users     database.UserRepository
+	templates database.RecurringTemplateRepository
+	rules     database.RuleRepository
+	interval  time.Duration
+}
+
+// New provides a new Scheduler that runs at the given interval.
+func New(users database.UserRepository, templates database.RecurringTemplateRepository,
+	ruleRepo database.RuleRepository, interval time.Duration) *Scheduler {
+	return &Scheduler{users: users, templates: templates, rules: ruleRepo, interval: interval}
+}
+
+// Start runs the scheduler in the background until the context is cancelled.
+// The first run is immediate, so the occurrences that were missed while the application was down are caught up.
+func (s *Scheduler) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(s.interval)
+		defer ticker.Stop()
+
+		for {
+			if err := s.Run(ctx, time.Now()); err != nil {
+				logger.Get().Error(ctx, &logger.Entry{Payload: fmt.Errorf("failed to run scheduler: %w", err)})
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Run materializes all the occurrences of all the templates of all the tenants that are due at the given time.
+// The failures of individual tenants and templates are logged, and do not stop the others from running.
+func (s *Scheduler) Run(ctx context.Context, now time.Time) error {
+	log := logger.Get()
+
+	users, err := s.users.ListUsers(ctx)
+	if err != nil {
+		return fmt.Errorf("failure in users.ListUsers: %w", err)
+	}
+
+	// A tenant may have more than one user.
+	tenantsDone := map[string]bool{}
+	for _, user := range users {
+		if tenantsDone[user.TenantID] {
+			continue
+		}
+		tenantsDone[user.TenantID] = true
+
+		if err := s.runTenant(ctxutils.PutTenantID(ctx, user.TenantID), now); err != nil {
+			err = fmt.Errorf("failed to run tenant %s: %w", user.TenantID, err)
+			log.Error(ctx, &logger.Entry{Payload: err})
+		}
+	}
+
+	return nil
+}
+
+// runTenant materializes all the occurrences of all the templates of the tenant in the context that are due at the
+// given time.
+func (s *Scheduler) runTenant(ctx context.Context, now time.Time) error {
+	log := logger.Get()
+
+	templates, err := s.templates.ListRecurringTemplates(ctx)
+	if err != nil {
+		return fmt.Errorf("failure in templates.ListRecurringTemplates: %w", err)
+	}
+
+	ruleList, err := s.rules.ListRules(ctx)
+	if err != nil {
+		return fmt.Errorf("failure in rules.ListRules: %w", err)
+	}
+	// The rules are validated before they are saved, so this fails only if the validation changes.
+	engine, err := rules.NewEngine(ruleList)
+	if err != nil {
+		return fmt.Errorf("failure in rules.NewEngine: %w", err)
+	}
+
+	for _, template := range templates {
+		if err := s.runTemplate(ctx, template, engine, now.Unix()); err != nil {
+			err = fmt.Errorf("failed to run recurring template %s: %w", template.ID, err)
+			log.Error(ctx, &logger.Entry{Payload: err})
+		}
+	}
+
+	return nil
+}
+
+// runTemplate materializes the occurrences of the template that are due at the given time, and applies the
+// categorization rules of the engine to them.
+func (s *Scheduler) runTemplate(ctx context.Context, template *models.RecurringTemplateDTO, engine *rules.Engine,
+	now int64) error {
+	// A zero next run time means that the template has no occurrences left.
+	if template.NextRun == 0 || template.NextRun > now {
+		return nil
+	}
+
+	due, err := Occurrences(template, template.NextRun, now, maxOccurrencesPerRun+1)
+	if err != nil {
+		return err
+	}
+
+	// The next run time is the earliest occurrence that this run leaves out.
+	var nextRun int64
+	if len(due) > maxOccurrencesPerRun {
+		nextRun, due = due[maxOccurrencesPerRun], due[:maxOccurrencesPerRun]
+	} else {
+		upcoming, err := Occurrences(template, now+1, 0, 1)
+		if err != nil {
+			return err
+		}
+		if len(upcoming) > 0 {
+			nextRun = upcoming[0]
+		}
+	}
+
+	transactions := make([]*models.TransactionDTO, len(due))
+	for idx, timestamp := range due {
+		transactions[idx] = &models.TransactionDTO{
+			Amount:    template.Amount,
+			Timestamp: timestamp,
+			AccountID: template.AccountID,
+			Category:  template.Category,
+			Notes:     template.Notes,
+		}
+		applyRules(engine, transactions[idx])
+	}
+
+	// If another run advanced the template already, there is nothing left to do.
+	_, err = s.templates.AdvanceRecurringTemplate(ctx, template.ID, template.NextRun, nextRun, transactions)
+	return err
+}
+
+// applyRules applies the categorization rules to a materialized transaction, like the create-transaction API does.
+//
+// Every template has a category, which wins over that of the rules just like the category that the user chooses, so
+// the categories of the rules are never used. The notes of the rules replace those of the template, and their tags are
+// the tags of the transaction, as the templates have none.
+func applyRules(engine *rules.Engine, transaction *models.TransactionDTO) {
+	result := engine.Evaluate(transaction, func(string, models.Money) bool { return false })
+
+	if result.Notes != nil {
+		transaction.Notes = *result.Notes
+	}
+	transaction.Tags = result.Tags
+}
+
+// Occurrences provides the timestamps of the occurrences of the template at or after "from" and at or before "until",
+// up to the given limit. A zero "until" does not limit the occurrences by time, but the end time of the template does.
+//
+// The occurrences are computed in UTC.
+func Occurrences(template *models.RecurringTemplateDTO, from int64, until int64, limit int) ([]int64, error) {
+	rule, err := recurrence.Parse(template.Rule)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse recurrence rule: %w", err)
+	}
+
+	if template.EndTime != 0 && (until == 0 || template.EndTime < until) {
+		until = template.EndTime
+	}
+
+	var untilTime time.Time
+	if until != 0 {
+		untilTime = time.Unix(until, 0).UTC()
+	}
+
+	occurrences := rule.Occurrences(time.Unix(template.StartTime, 0).UTC(), time.Unix(from, 0).UTC(), untilTime, limit)
+
+	timestamps := make([]int64, len(occurrences))
+	for idx, occurrence := range occurrences {
+		timestamps[idx] = occurrence.Unix()
+	}
+	return timestamps, nil
+}
@@ -0,0 +1,170 @@
+package scheduler
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/shivanshkc/ledgerkeep/src/database"
+	"github.com/shivanshkc/ledgerkeep/src/models"
+	"github.com/shivanshkc/ledgerkeep/src/utils/ctxutils"
+)
+
+func TestRunCatchesUpIdempotently(t *testing.T) {
+	ctx := ctxutils.PutTenantID(context.Background(), database.DefaultTenantID)
+	repos := database.NewMemoryRepositories()
+
+	if err := repos.Accounts.InsertAccount(ctx, &models.AccountDTO{ID: "bank", Name: "Bank"}); err != nil {
+		t.Fatalf("unexpected error in InsertAccount: %+v", err)
+	}
+
+	start := time.Date(2022, time.January, 1, 9, 0, 0, 0, time.UTC).Unix()
+	templateID, err := repos.Recurring.InsertRecurringTemplate(ctx, &models.RecurringTemplateDTO{
+		Name: "Rent", Amount: -500, AccountID: "bank", Category: "essentials", Notes: "Rent",
+		Rule: "FREQ=MONTHLY;BYMONTHDAY=1", StartTime: start, NextRun: start,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error in InsertRecurringTemplate: %+v", err)
+	}
+
+	// The scheduler was down for a few months, so all the missed occurrences are due.
+	now := time.Date(2022, time.April, 15, 0, 0, 0, 0, time.UTC)
+	scheduler := New(repos.Users, repos.Recurring, repos.Rules, time.Minute)
+	for run := 0; run < 2; run++ {
+		if err := scheduler.Run(ctx, now); err != nil {
+			t.Fatalf("unexpected error in Run: %+v", err)
+		}
+	}
+
+	transactions, _, err := repos.Transactions.ListTransactions(ctx, &database.ListTransactionsParams{
+		Filter: map[string]interface{}{"account_id": "bank"}, SortField: "timestamp", SortOrder: 1, ExcludeCount: true,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error in ListTransactions: %+v", err)
+	}
+	if len(transactions) != 4 {
+		t.Fatalf("expected 4 transactions, got: %d", len(transactions))
+	}
+	for idx, tx := range transactions {
+		expected := time.Date(2022, time.Month(idx+1), 1, 9, 0, 0, 0, time.UTC).Unix()
+		if tx.Timestamp != expected || tx.Amount != -500 || tx.Category != "essentials" {
+			t.Fatalf("unexpected transaction at index %d: %+v", idx, tx)
+		}
+	}
+
+	template, err := repos.Recurring.GetRecurringTemplate(ctx, templateID)
+	if err != nil {
+		t.Fatalf("unexpected error in GetRecurringTemplate: %+v", err)
+	}
+	if expected := time.Date(2022, time.May, 1, 9, 0, 0, 0, time.UTC).Unix(); template.NextRun != expected {
+		t.Fatalf("expected next run to be %d, got: %d", expected, template.NextRun)
+	}
+}
+
+func TestRunAppliesRules(t *testing.T) {
+	ctx := ctxutils.PutTenantID(context.Background(), database.DefaultTenantID)
+	repos := database.NewMemoryRepositories()
+
+	if err := repos.Accounts.InsertAccount(ctx, &models.AccountDTO{ID: "bank", Name: "Bank"}); err != nil {
+		t.Fatalf("unexpected error in InsertAccount: %+v", err)
+	}
+
+	// Only the first of the occurrences, on the 1st of January 2022, is on a Saturday.
+	if _, err := repos.Rules.InsertRule(ctx, &models.RuleDTO{
+		Name: "Weekend rent", NotesPattern: "Rent", Weekdays: []string{"SA"}, SetCategory: "luxury",
+		SetNotes: "Weekend rent", AddTags: models.Tags{"housing"},
+	}); err != nil {
+		t.Fatalf("unexpected error in InsertRule: %+v", err)
+	}
+
+	start := time.Date(2022, time.January, 1, 9, 0, 0, 0, time.UTC).Unix()
+	if _, err := repos.Recurring.InsertRecurringTemplate(ctx, &models.RecurringTemplateDTO{
+		Name: "Rent", Amount: -500, AccountID: "bank", Category: "essentials", Notes: "Rent",
+		Rule: "FREQ=MONTHLY;BYMONTHDAY=1", StartTime: start, NextRun: start,
+	}); err != nil {
+		t.Fatalf("unexpected error in InsertRecurringTemplate: %+v", err)
+	}
+
+	now := time.Date(2022, time.February, 15, 0, 0, 0, 0, time.UTC)
+	if err := New(repos.Users, repos.Recurring, repos.Rules, time.Minute).Run(ctx, now); err != nil {
+		t.Fatalf("unexpected error in Run: %+v", err)
+	}
+
+	transactions, _, err := repos.Transactions.ListTransactions(ctx, &database.ListTransactionsParams{
+		Filter: map[string]interface{}{}, SortField: "timestamp", SortOrder: 1, ExcludeCount: true,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error in ListTransactions: %+v", err)
+	}
+	if len(transactions) != 2 {
+		t.Fatalf("expected 2 transactions, got: %d", len(transactions))
+	}
+
+	// The category of the template wins over that of the rule, like the category that the user chooses.
+	first, second := transactions[0], transactions[1]
+	if first.Category != "essentials" || first.Notes != "Weekend rent" || len(first.Tags) != 1 ||
+		first.Tags[0] != "housing" {
+		t.Fatalf("unexpected transaction that matches the rule: %+v", first)
+	}
+	if second.Category != "essentials" || second.Notes != "Rent" || len(second.Tags) != 0 {
+		t.Fatalf("unexpected transaction that does not match the rule: %+v", second)
+	}
+}
+
+func TestRunCoversAllTenants(t *testing.T) {
+	repos := database.NewMemoryRepositories()
+
+	// The admin user of the configs is in the default tenant, and this user has a tenant of its own.
+	user := &models.UserDTO{ID: "alice", TenantID: "tenant-a"}
+	if err := repos.Users.InsertUser(context.Background(), user); err != nil {
+		t.Fatalf("unexpected error in InsertUser: %+v", err)
+	}
+
+	start := time.Date(2022, time.January, 1, 9, 0, 0, 0, time.UTC).Unix()
+	for _, tenantID := range []string{database.DefaultTenantID, user.TenantID} {
+		ctx := ctxutils.PutTenantID(context.Background(), tenantID)
+		if err := repos.Accounts.InsertAccount(ctx, &models.AccountDTO{ID: "bank", Name: "Bank"}); err != nil {
+			t.Fatalf("unexpected error in InsertAccount: %+v", err)
+		}
+		if _, err := repos.Recurring.InsertRecurringTemplate(ctx, &models.RecurringTemplateDTO{
+			Name: "Rent", Amount: -500, AccountID: "bank", Category: "essentials",
+			Rule: "FREQ=MONTHLY;BYMONTHDAY=1", StartTime: start, NextRun: start,
+		}); err != nil {
+			t.Fatalf("unexpected error in InsertRecurringTemplate: %+v", err)
+		}
+	}
+
+	now := time.Date(2022, time.January, 15, 0, 0, 0, 0, time.UTC)
+	if err := New(repos.Users, repos.Recurring, repos.Rules, time.Minute).Run(context.Background(), now); err != nil {
+		t.Fatalf("unexpected error in Run: %+v", err)
+	}
+
+	for _, tenantID := range []string{database.DefaultTenantID, user.TenantID} {
+		ctx := ctxutils.PutTenantID(context.Background(), tenantID)
+		transactions, _, err := repos.Transactions.ListTransactions(ctx, &database.ListTransactionsParams{
+			Filter: map[string]interface{}{}, SortField: "timestamp", SortOrder: 1, ExcludeCount: true,
+		})
+		if err != nil {
+			t.Fatalf("unexpected error in ListTransactions: %+v", err)
+		}
+		if len(transactions) != 1 || transactions[0].Timestamp != start {
+			t.Fatalf("unexpected transactions of tenant %s: %+v", tenantID, transactions)
+		}
+	}
+}
+
+func TestOccurrencesRespectEndTime(t *testing.T) {
+	template := &models.RecurringTemplateDTO{Rule: "FREQ=DAILY;INTERVAL=2", StartTime: 0, EndTime: 5 * 86400}
+
+	occurrences, err := Occurrences(template, 86400, 0, 10)
+	if err != nil {
+		t.Fatalf("unexpected error in Occurrences: %+v", err)
+	}
+	if len(occurrences) != 2 || occurrences[0] != 2*86400 || occurrences[1] != 4*86400 {
+		t.Fatalf("unexpected occurrences: %+v", occurrences)
+	}
+
+	if _, err := Occurrences(&models.RecurringTemplateDTO{Rule: "FREQ=SOMETIMES"}, 0, 0, 1); err == nil {
+		t.Fatalf("expected an error for an invalid rule")
+	}
+}
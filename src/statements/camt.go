@@ -0,0 +1,253 @@
+package statements
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/shivanshkc/ledgerkeep/src/models"
+)
+
+// ErrInvalidCamt053 is returned for files that are not camt.053 statements.
+var ErrInvalidCamt053 = errors.New("file should be an ISO 20022 camt.053 statement")
+
+// These are the ISO 20022 codes that the statements are read with.
+const (
+	camtDebit          = "DBIT"
+	camtBooked         = "BOOK"
+	camtOpeningBalance = "OPBD"
+	camtClosingBalance = "CLBD"
+	// camtPreviousBalance is the closing balance of the previous statement, which some banks report instead of OPBD.
+	camtPreviousBalance = "PRCD"
+	// camtNoReference is put in place of the references that the bank does not have.
+	camtNoReference = "NONREF"
+)
+
+// camtDocument is the part of a camt.053 document that is read. The XML namespaces, which differ between the
+// versions of camt.053, are ignored, so all of its versions are read alike.
+type camtDocument struct {
+	Statements []*camtStatement `xml:"BkToCstmrStmt>Stmt"`
+}
+
+// camtStatement is a statement of a camt.053 document.
+type camtStatement struct {
+	Balances []*camtBalance `xml:"Bal"`
+	Entries  []*camtEntry   `xml:"Ntry"`
+}
+
+// camtBalance is a balance of a camt.053 statement.
+type camtBalance struct {
+	Type      string `xml:"Tp>CdOrPrtry>Cd"`
+	Amount    string `xml:"Amt"`
+	Indicator string `xml:"CdtDbtInd"`
+}
+
+// camtEntry is an entry of a camt.053 statement.
+type camtEntry struct {
+	Reference  string   `xml:"NtryRef"`
+	Amount     string   `xml:"Amt"`
+	Indicator  string   `xml:"CdtDbtInd"`
+	Status     camtCode `xml:"Sts"`
+	BookedOn   camtDate `xml:"BookgDt"`
+	ValuedOn   camtDate `xml:"ValDt"`
+	BankRef    string   `xml:"AcctSvcrRef"`
+	Additional string   `xml:"AddtlNtryInf"`
+	// Details are the transactions that make up the entry. Most entries have exactly one.
+	Details []*camtDetails `xml:"NtryDtls>TxDtls"`
+}
+
+// camtDetails are the details of a transaction of a camt.053 entry.
+type camtDetails struct {
+	BankRef      string   `xml:"Refs>AcctSvcrRef"`
+	Unstructured []string `xml:"RmtInf>Ustrd"`
+	Structured   []string `xml:"RmtInf>Strd>CdtrRefInf>Ref"`
+	Additional   string   `xml:"AddtlTxInf"`
+}
+
+// camtCode is a code that is either the text of its element, as in camt.053.001.02, or the text of its Cd child
+// element, as in the later versions.
+type camtCode struct {
+	Text string `xml:",chardata"`
+	Code string `xml:"Cd"`
+}
+
+// camtDate is a date element, which holds either a date (Dt) or a date-time (DtTm).
+type camtDate struct {
+	Date     string `xml:"Dt"`
+	DateTime string `xml:"DtTm"`
+}
+
+// ParseCamt053 parses the booked entries and the balances of an ISO 20022 camt.053 statement file.
+//
+// The booking date of an entry is its timestamp, or the value date if it has none. Its notes are the remittance
+// information, or the additional information if it has none. The pending entries are left out, as they may change.
+func ParseCamt053(reader io.Reader) ([]*Entry, *Balances, []error) {
+	document := &camtDocument{}
+	if err := xml.NewDecoder(reader).Decode(document); err != nil || len(document.Statements) == 0 {
+		return nil, nil, []error{ErrInvalidCamt053}
+	}
+
+	var entries []*Entry
+	var errs []error
+	balances := &Balances{}
+
+	number := 0
+	for _, statement := range document.Statements {
+		opening, closing, err := getCamtBalances(statement.Balances)
+		if err != nil {
+			errs = append(errs, err)
+		}
+		// The opening balance of the file is that of its first statement, and the closing balance is that of the last.
+		if balances.Opening == nil {
+			balances.Opening = opening
+		}
+		if closing != nil {
+			balances.Closing = closing
+		}
+
+		for _, camtEntry := range statement.Entries {
+			number++
+
+			status := strings.TrimSpace(camtEntry.Status.Code + camtEntry.Status.Text)
+			if status != "" && !strings.EqualFold(status, camtBooked) {
+				continue
+			}
+
+			entry, err := newCamtEntry(number, camtEntry)
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			entries = append(entries, entry)
+		}
+	}
+
+	return entries, balances, errs
+}
+
+// getCamtBalances provides the opening and closing balances of a camt.053 statement, if it reports them.
+func getCamtBalances(camtBalances []*camtBalance) (*models.Money, *models.Money, error) {
+	var opening, closing, previous *models.Money
+
+	for _, balance := range camtBalances {
+		amount, err := parseCamtAmount(balance.Amount, balance.Indicator)
+		if err != nil {
+			return nil, nil, fmt.Errorf("balance %s: %w", balance.Type, err)
+		}
+
+		switch strings.ToUpper(strings.TrimSpace(balance.Type)) {
+		case camtOpeningBalance:
+			opening = &amount
+		case camtPreviousBalance:
+			previous = &amount
+		case camtClosingBalance:
+			closing = &amount
+		}
+	}
+
+	if opening == nil {
+		opening = previous
+	}
+	return opening, closing, nil
+}
+
+// newCamtEntry creates the statement entry out of a camt.053 entry.
+func newCamtEntry(number int, camtEntry *camtEntry) (*Entry, error) {
+	entry := &Entry{Number: number}
+
+	date := camtEntry.BookedOn
+	if date.Date == "" && date.DateTime == "" {
+		date = camtEntry.ValuedOn
+	}
+	timestamp, err := date.parse()
+	if err != nil {
+		return nil, fmt.Errorf("entry %d: %w", number, err)
+	}
+	entry.Timestamp = timestamp
+
+	amount, err := parseCamtAmount(camtEntry.Amount, camtEntry.Indicator)
+	if err != nil {
+		return nil, fmt.Errorf("entry %d: %w", number, err)
+	}
+	entry.Amount = amount
+
+	var remittance, additional []string
+	var bankRefs []string
+	for _, details := range camtEntry.Details {
+		remittance = append(remittance, details.Unstructured...)
+		remittance = append(remittance, details.Structured...)
+		additional = append(additional, details.Additional)
+		bankRefs = append(bankRefs, details.BankRef)
+	}
+
+	entry.Notes = joinNotes(remittance...)
+	if entry.Notes == "" {
+		entry.Notes = joinNotes(append([]string{camtEntry.Additional}, additional...)...)
+	}
+
+	// The reference of the bank identifies the entry. The entry reference is only unique within the statement.
+	// A batch entry has no reference of its own, so the reference of its only transaction is taken, if it has one.
+	for _, reference := range []string{camtEntry.BankRef, firstIfOnly(bankRefs), camtEntry.Reference} {
+		reference = strings.TrimSpace(reference)
+		if reference != "" && !strings.EqualFold(reference, camtNoReference) {
+			entry.ExternalID = reference
+			break
+		}
+	}
+
+	return entry, nil
+}
+
+// parseCamtAmount parses a camt.053 amount, which is always positive, and signs it as per its indicator.
+func parseCamtAmount(value string, indicator string) (models.Money, error) {
+	if strings.TrimSpace(value) == "" {
+		return 0, errMissingAmount
+	}
+
+	amount, err := models.ParseMoney(value)
+	if err != nil {
+		return 0, err
+	}
+	if strings.EqualFold(strings.TrimSpace(indicator), camtDebit) {
+		amount = -amount
+	}
+	return amount, nil
+}
+
+// parse parses the date, like "2022-03-01", or the date-time, like "2022-03-01T10:00:00+01:00", into epoch seconds.
+// The dates and the date-times without a time zone are in UTC.
+func (c camtDate) parse() (int64, error) {
+	if date := strings.TrimSpace(c.Date); date != "" {
+		// Some banks add the time zone to the dates too.
+		if len(date) > len("2006-01-02") {
+			date = date[:len("2006-01-02")]
+		}
+		parsed, err := time.Parse("2006-01-02", date)
+		if err != nil {
+			return 0, fmt.Errorf("invalid date: %q", c.Date)
+		}
+		return parsed.Unix(), nil
+	}
+
+	dateTime := strings.TrimSpace(c.DateTime)
+	if dateTime == "" {
+		return 0, errMissingDate
+	}
+	for _, layout := range []string{time.RFC3339, "2006-01-02T15:04:05"} {
+		if parsed, err := time.Parse(layout, dateTime); err == nil {
+			return parsed.Unix(), nil
+		}
+	}
+	return 0, fmt.Errorf("invalid date: %q", c.DateTime)
+}
+
+// firstIfOnly provides the only element of the values, or an empty string if there are none or several.
+func firstIfOnly(values []string) string {
+	if len(values) != 1 {
+		return ""
+	}
+	return values[0]
+}
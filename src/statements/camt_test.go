@@ -0,0 +1,66 @@
+package statements
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseCamt053(t *testing.T) {
+	camt := `<?xml version="1.0" encoding="UTF-8"?>
+<Document xmlns="urn:iso:std:iso:20022:tech:xsd:camt.053.001.08">
+<BkToCstmrStmt><GrpHdr><MsgId>MSG1</MsgId></GrpHdr>
+<Stmt>
+  <Id>STMT1</Id>
+  <Bal><Tp><CdOrPrtry><Cd>OPBD</Cd></CdOrPrtry></Tp><Amt Ccy="EUR">100.00</Amt><CdtDbtInd>DBIT</CdtDbtInd>
+    <Dt><Dt>2022-03-01</Dt></Dt></Bal>
+  <Bal><Tp><CdOrPrtry><Cd>CLBD</Cd></CdOrPrtry></Tp><Amt Ccy="EUR">1107.90</Amt><CdtDbtInd>CRDT</CdtDbtInd>
+    <Dt><Dt>2022-03-31</Dt></Dt></Bal>
+  <Ntry>
+    <Amt Ccy="EUR">1250.00</Amt><CdtDbtInd>CRDT</CdtDbtInd><Sts><Cd>BOOK</Cd></Sts>
+    <BookgDt><Dt>2022-03-01</Dt></BookgDt><ValDt><Dt>2022-03-02</Dt></ValDt>
+    <AcctSvcrRef>REF-1</AcctSvcrRef>
+    <NtryDtls><TxDtls><RmtInf><Ustrd>Invoice 42</Ustrd><Ustrd>March</Ustrd></RmtInf></TxDtls></NtryDtls>
+  </Ntry>
+  <Ntry>
+    <Amt Ccy="EUR">42.10</Amt><CdtDbtInd>DBIT</CdtDbtInd><Sts><Cd>BOOK</Cd></Sts>
+    <BookgDt><DtTm>2022-03-02T10:00:00+01:00</DtTm></BookgDt>
+    <AcctSvcrRef>NONREF</AcctSvcrRef><AddtlNtryInf>Card payment</AddtlNtryInf>
+    <NtryDtls><TxDtls><Refs><AcctSvcrRef>REF-2</AcctSvcrRef></Refs></TxDtls></NtryDtls>
+  </Ntry>
+  <Ntry>
+    <Amt Ccy="EUR">5.00</Amt><CdtDbtInd>DBIT</CdtDbtInd><Sts><Cd>PDNG</Cd></Sts>
+    <BookgDt><Dt>2022-03-03</Dt></BookgDt>
+  </Ntry>
+  <Ntry>
+    <Amt Ccy="EUR">1.00</Amt><CdtDbtInd>DBIT</CdtDbtInd><Sts><Cd>BOOK</Cd></Sts>
+  </Ntry>
+</Stmt>
+</BkToCstmrStmt>
+</Document>`
+
+	entries, balances, errs := ParseCamt053(strings.NewReader(camt))
+	if len(entries) != 2 || len(errs) != 1 || !strings.HasPrefix(errs[0].Error(), "entry 4:") {
+		t.Fatalf("unexpected entries and errors: %+v, %+v", entries, errs)
+	}
+
+	expected := []Entry{
+		{Number: 1, Timestamp: time.Date(2022, time.March, 1, 0, 0, 0, 0, time.UTC).Unix(), Amount: 12500000,
+			ExternalID: "REF-1", Notes: "Invoice 42 - March"},
+		{Number: 2, Timestamp: time.Date(2022, time.March, 2, 9, 0, 0, 0, time.UTC).Unix(), Amount: -421000,
+			ExternalID: "REF-2", Notes: "Card payment"},
+	}
+	for idx, entry := range entries {
+		if *entry != expected[idx] {
+			t.Errorf("expected %+v, got: %+v", expected[idx], entry)
+		}
+	}
+
+	if balances.Opening == nil || *balances.Opening != -1000000 || balances.Closing == nil || *balances.Closing != 11079000 {
+		t.Errorf("unexpected balances: %+v", balances)
+	}
+
+	if _, _, errs := ParseCamt053(strings.NewReader("<OFX></OFX>")); len(errs) != 1 || errs[0] != ErrInvalidCamt053 {
+		t.Errorf("expected ErrInvalidCamt053, got: %+v", errs)
+	}
+}
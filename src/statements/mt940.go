@@ -0,0 +1,235 @@
+package statements
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/shivanshkc/ledgerkeep/src/models"
+)
+
+// ErrInvalidMT940 is returned for files that are not MT940 statements.
+var ErrInvalidMT940 = errors.New("file should be a SWIFT MT940 statement")
+
+var (
+	// mt940TagRegexp matches the tag that starts a field of an MT940 statement, like ":61:" or ":60F:".
+	mt940TagRegexp = regexp.MustCompile(`^:([0-9]{2}[A-Z]?):`)
+	// mt940BalanceRegexp matches a balance field, like "C220301EUR1234,56".
+	mt940BalanceRegexp = regexp.MustCompile(`^([CD])([0-9]{6})([A-Z]{3})([0-9]+,?[0-9]*)`)
+	// mt940LineRegexp matches a statement line field. Its groups are the value date, the optional booking date,
+	// the debit or credit mark, the amount, the transaction type, and the references of the customer and the bank.
+	mt940LineRegexp = regexp.MustCompile(
+		`^([0-9]{6})([0-9]{4})?(RC|RD|C|D)[A-Z]?([0-9]+,?[0-9]*)([A-Z][A-Z0-9]{3})([^/\n]*)(?://([^\n]*))?`)
+	// mt940SubfieldRegexp matches the subfields of a structured information field, like "?20" or "?32".
+	mt940SubfieldRegexp = regexp.MustCompile(`\?([0-9]{2})`)
+)
+
+// mt940NoReference is put in place of the references that the bank or the customer does not have.
+const mt940NoReference = "NONREF"
+
+// mt940Field is a field of an MT940 statement, along with its continuation lines.
+type mt940Field struct {
+	tag   string
+	value string
+}
+
+// ParseMT940 parses the transactions and the balances of a SWIFT MT940 statement file.
+//
+// A transaction is a statement line (field 61) along with its information field (field 86). The booking date of the
+// transaction is its timestamp, or the value date if it has none. Its notes are the remittance information of the
+// information field. The SWIFT blocks around the statements and the separators between them are skipped.
+func ParseMT940(reader io.Reader) ([]*Entry, *Balances, []error) {
+	fields, err := readMT940Fields(reader)
+	if err != nil {
+		return nil, nil, []error{err}
+	}
+	if len(fields) == 0 {
+		return nil, nil, []error{ErrInvalidMT940}
+	}
+
+	var entries []*Entry
+	var errs []error
+	balances := &Balances{}
+
+	number := 0
+	for idx, field := range fields {
+		switch field.tag {
+		case "60F", "60M", "62F", "62M":
+			balance, err := parseMT940Balance(field.value)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("balance %s: %w", field.tag, err))
+				continue
+			}
+			// The opening balance of the file is that of its first statement, and the closing balance is that of the last.
+			if field.tag[:2] == "60" && balances.Opening == nil {
+				balances.Opening = &balance
+			}
+			if field.tag[:2] == "62" {
+				balances.Closing = &balance
+			}
+		case "61":
+			number++
+
+			// The information field, if any, follows the statement line.
+			var information string
+			if idx+1 < len(fields) && fields[idx+1].tag == "86" {
+				information = fields[idx+1].value
+			}
+
+			entry, err := newMT940Entry(number, field.value, information)
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			entries = append(entries, entry)
+		}
+	}
+
+	return entries, balances, errs
+}
+
+// readMT940Fields reads the fields of all the statements of an MT940 file.
+func readMT940Fields(reader io.Reader) ([]*mt940Field, error) {
+	scanner := bufio.NewScanner(reader)
+
+	var fields []*mt940Field
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if lineNum == 1 {
+			line = strings.TrimPrefix(line, "\ufeff")
+		}
+
+		// The SWIFT blocks put the headers before the text block, like "{1:F01...}{2:I940...}{4:".
+		if textBlock := strings.Index(line, "{4:"); textBlock >= 0 {
+			line = line[textBlock+len("{4:"):]
+		}
+		// The statements end with a "-" or, in the SWIFT blocks, with a "-}".
+		if trimmed := strings.TrimSpace(line); trimmed == "" || trimmed == "-" || strings.HasPrefix(trimmed, "-}") {
+			continue
+		}
+
+		if match := mt940TagRegexp.FindStringSubmatch(line); match != nil {
+			fields = append(fields, &mt940Field{tag: match[1], value: line[len(match[0]):]})
+			continue
+		}
+
+		// The lines without a tag continue the previous field.
+		if len(fields) == 0 {
+			return nil, ErrInvalidMT940
+		}
+		fields[len(fields)-1].value += "\n" + line
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+	return fields, nil
+}
+
+// newMT940Entry creates the statement entry out of a statement line and its information field.
+func newMT940Entry(number int, line string, information string) (*Entry, error) {
+	match := mt940LineRegexp.FindStringSubmatch(line)
+	if match == nil {
+		return nil, fmt.Errorf("entry %d: invalid statement line: %q", number, strings.SplitN(line, "\n", 2)[0])
+	}
+
+	valueDate, err := time.Parse("060102", match[1])
+	if err != nil {
+		return nil, fmt.Errorf("entry %d: invalid date: %q", number, match[1])
+	}
+
+	date := valueDate
+	if match[2] != "" {
+		// The booking date has no year, so it takes the year of the value date, or the adjacent one across a new year.
+		bookingDate, err := time.Parse("0102", match[2])
+		if err != nil {
+			return nil, fmt.Errorf("entry %d: invalid date: %q", number, match[2])
+		}
+		date = time.Date(valueDate.Year(), bookingDate.Month(), bookingDate.Day(), 0, 0, 0, 0, time.UTC)
+		switch {
+		case valueDate.Month() == time.December && date.Month() == time.January:
+			date = date.AddDate(1, 0, 0)
+		case valueDate.Month() == time.January && date.Month() == time.December:
+			date = date.AddDate(-1, 0, 0)
+		}
+	}
+
+	amount, err := parseMT940Amount(match[4])
+	if err != nil {
+		return nil, fmt.Errorf("entry %d: %w", number, err)
+	}
+	// A reversal of a credit (RC) is a debit, and a reversal of a debit (RD) is a credit.
+	if match[3] == "D" || match[3] == "RC" {
+		amount = -amount
+	}
+
+	entry := &Entry{
+		Number:    number,
+		Timestamp: date.Unix(),
+		Amount:    amount,
+		Notes:     getMT940Remittance(information),
+	}
+
+	// The reference of the bank identifies the entry. The reference of the customer is not unique.
+	if reference := strings.TrimSpace(match[7]); !strings.EqualFold(reference, mt940NoReference) {
+		entry.ExternalID = reference
+	}
+
+	return entry, nil
+}
+
+// parseMT940Balance parses a balance field into a signed amount.
+func parseMT940Balance(value string) (models.Money, error) {
+	match := mt940BalanceRegexp.FindStringSubmatch(value)
+	if match == nil {
+		return 0, fmt.Errorf("invalid balance: %q", value)
+	}
+
+	amount, err := parseMT940Amount(match[4])
+	if err != nil {
+		return 0, err
+	}
+	if match[1] == "D" {
+		amount = -amount
+	}
+	return amount, nil
+}
+
+// parseMT940Amount parses an MT940 amount, which has a "," decimal separator and may end with it, like "1234,".
+func parseMT940Amount(value string) (models.Money, error) {
+	return models.ParseMoney(strings.ReplaceAll(strings.TrimSuffix(value, ","), ",", "."))
+}
+
+// getMT940Remittance provides the remittance information of an information field.
+//
+// Many banks structure the field with subfields, like "166?00TRANSFER?20Invoice 42?32ACME Corp", of which the
+// subfields 20 to 29 and 60 to 63 hold the remittance information. Otherwise, the whole field is the information.
+func getMT940Remittance(information string) string {
+	if !mt940SubfieldRegexp.MatchString(information) {
+		return strings.Join(strings.Fields(information), " ")
+	}
+
+	// The subfields are split into lines at fixed lengths too.
+	information = strings.ReplaceAll(information, "\n", "")
+	subfields := mt940SubfieldRegexp.FindAllStringSubmatchIndex(information, -1)
+
+	var remittance strings.Builder
+	for idx, subfield := range subfields {
+		end := len(information)
+		if idx+1 < len(subfields) {
+			end = subfields[idx+1][0]
+		}
+
+		code := information[subfield[2]:subfield[3]]
+		if (code >= "20" && code <= "29") || (code >= "60" && code <= "63") {
+			// The remittance information is split at fixed lengths, so its parts are joined without separators.
+			remittance.WriteString(information[subfield[1]:end])
+		}
+	}
+
+	return strings.Join(strings.Fields(remittance.String()), " ")
+}
@@ -0,0 +1,52 @@
+package statements
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseMT940(t *testing.T) {
+	mt940 := "{1:F01BANKDEFFXXXX0000000000}{2:I940BANKDEFFXXXXN}{4:\n" +
+		":20:STMT1\n" +
+		":25:10020030/1234567\n" +
+		":28C:1/1\n" +
+		":60F:D211231EUR100,00\n" +
+		":61:2112311231C1250,NTRFNONREF//REF-1\n" +
+		"Supplementary details\n" +
+		":86:166?00CREDIT TRANSFER?20Invoice 42 fo?21r March?32ACME Corp\n" +
+		":61:2201011231D42,10NMSCNONREF\n" +
+		":86:Card payment\nat the grocery store\n" +
+		":61:220104RC5,00NCHK123//REF-3\n" +
+		":61:2201XXD1,00NMSC\n" +
+		":62F:C220104EUR1102,90\n" +
+		"-}\n"
+
+	entries, balances, errs := ParseMT940(strings.NewReader(mt940))
+	if len(entries) != 3 || len(errs) != 1 || !strings.HasPrefix(errs[0].Error(), "entry 4:") {
+		t.Fatalf("unexpected entries and errors: %+v, %+v", entries, errs)
+	}
+
+	expected := []Entry{
+		{Number: 1, Timestamp: time.Date(2021, time.December, 31, 0, 0, 0, 0, time.UTC).Unix(), Amount: 12500000,
+			ExternalID: "REF-1", Notes: "Invoice 42 for March"},
+		// The booking date is in the year before the value date.
+		{Number: 2, Timestamp: time.Date(2021, time.December, 31, 0, 0, 0, 0, time.UTC).Unix(), Amount: -421000,
+			Notes: "Card payment at the grocery store"},
+		{Number: 3, Timestamp: time.Date(2022, time.January, 4, 0, 0, 0, 0, time.UTC).Unix(), Amount: -50000,
+			ExternalID: "REF-3"},
+	}
+	for idx, entry := range entries {
+		if *entry != expected[idx] {
+			t.Errorf("expected %+v, got: %+v", expected[idx], entry)
+		}
+	}
+
+	if balances.Opening == nil || *balances.Opening != -1000000 || balances.Closing == nil || *balances.Closing != 11029000 {
+		t.Errorf("unexpected balances: %+v", balances)
+	}
+
+	if _, _, errs := ParseMT940(strings.NewReader("date,amount\n")); len(errs) != 1 || errs[0] != ErrInvalidMT940 {
+		t.Errorf("expected ErrInvalidMT940, got: %+v", errs)
+	}
+}
@@ -0,0 +1,197 @@
+package statements
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"io/ioutil"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrInvalidOFX is returned for files that are not OFX or QFX statements.
+var ErrInvalidOFX = errors.New("file should be an OFX or QFX statement")
+
+// ParseOFX parses the transactions of an OFX or QFX statement file.
+//
+// Both the SGML based OFX 1.x, in which the closing tags of the values are optional, and the XML based OFX 2.x are
+// supported. The transactions of all the statements in the file are provided, along with the errors of the invalid
+// ones, so they can be fixed in one go.
+func ParseOFX(reader io.Reader) ([]*Entry, []error) {
+	content, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return nil, []error{fmt.Errorf("failed to read file: %w", err)}
+	}
+
+	// The OFX 1.x headers are not tags, so the parsing starts from the root element.
+	body := strings.ToValidUTF8(string(content), "")
+	start := strings.Index(strings.ToUpper(body), "<OFX>")
+	if start < 0 {
+		return nil, []error{ErrInvalidOFX}
+	}
+
+	var entries []*Entry
+	var errs []error
+
+	// fields holds the values of the transaction being read. It is nil outside of the transactions.
+	var fields map[string]string
+
+	finishEntry := func() {
+		entry, err := newOFXEntry(len(entries)+len(errs)+1, fields)
+		if err != nil {
+			errs = append(errs, err)
+		} else {
+			entries = append(entries, entry)
+		}
+		fields = nil
+	}
+
+	for _, element := range tokenizeOFX(body[start:]) {
+		switch {
+		case element.name == "STMTTRN" && !element.closing:
+			// An unclosed transaction ends where the next one starts.
+			if fields != nil {
+				finishEntry()
+			}
+			fields = map[string]string{}
+		case element.name == "STMTTRN" && element.closing:
+			if fields != nil {
+				finishEntry()
+			}
+		case fields != nil && !element.closing && element.value != "":
+			// The payee aggregate has its own NAME, which is kept only if the transaction has none.
+			if _, exists := fields[element.name]; !exists {
+				fields[element.name] = element.value
+			}
+		}
+	}
+
+	// A transaction may be left unclosed at the end of a truncated file.
+	if fields != nil {
+		finishEntry()
+	}
+
+	return entries, errs
+}
+
+// ofxElement is an opening or closing tag of an OFX file, along with the value that follows an opening tag.
+type ofxElement struct {
+	name    string
+	value   string
+	closing bool
+}
+
+// tokenizeOFX splits the OFX body into its tags. Processing instructions and comments are skipped.
+func tokenizeOFX(body string) []*ofxElement {
+	var elements []*ofxElement
+
+	for {
+		open := strings.IndexByte(body, '<')
+		if open < 0 {
+			return elements
+		}
+		closeIdx := strings.IndexByte(body[open:], '>')
+		if closeIdx < 0 {
+			return elements
+		}
+
+		tag := strings.TrimSpace(body[open+1 : open+closeIdx])
+		body = body[open+closeIdx+1:]
+
+		if tag == "" || tag[0] == '?' || tag[0] == '!' {
+			continue
+		}
+
+		element := &ofxElement{}
+		if tag[0] == '/' {
+			element.closing = true
+			tag = tag[1:]
+		}
+		// The tag names of OFX have no attributes, but XML writers may still add some.
+		element.name = strings.ToUpper(strings.Fields(tag + " ")[0])
+
+		// In OFX 1.x, the value of an element runs until the next tag.
+		if !element.closing {
+			valueEnd := strings.IndexByte(body, '<')
+			if valueEnd < 0 {
+				valueEnd = len(body)
+			}
+			element.value = html.UnescapeString(strings.TrimSpace(body[:valueEnd]))
+		}
+
+		elements = append(elements, element)
+	}
+}
+
+// newOFXEntry creates the statement entry out of the values of an OFX transaction.
+func newOFXEntry(number int, fields map[string]string) (*Entry, error) {
+	entry := &Entry{Number: number, ExternalID: fields["FITID"], Notes: joinNotes(fields["NAME"], fields["MEMO"])}
+
+	// The posting date is required by the specification, but some banks only provide the user date.
+	date := fields["DTPOSTED"]
+	if date == "" {
+		date = fields["DTUSER"]
+	}
+	if date == "" {
+		return nil, fmt.Errorf("entry %d: %w", number, errMissingDate)
+	}
+	timestamp, err := parseOFXDate(date)
+	if err != nil {
+		return nil, fmt.Errorf("entry %d: %w", number, err)
+	}
+	entry.Timestamp = timestamp
+
+	if fields["TRNAMT"] == "" {
+		return nil, fmt.Errorf("entry %d: %w", number, errMissingAmount)
+	}
+	amount, err := parseAmount(fields["TRNAMT"])
+	if err != nil {
+		return nil, fmt.Errorf("entry %d: %w", number, err)
+	}
+	entry.Amount = amount
+
+	return entry, nil
+}
+
+// parseOFXDate parses an OFX date-time, like "20220301", "20220301120000.000" or "20220301120000[-5:EST]",
+// into epoch seconds. The date-times without a time zone are in UTC, as per the specification.
+func parseOFXDate(value string) (int64, error) {
+	invalidErr := fmt.Errorf("invalid date: %q", value)
+
+	location := time.UTC
+	if open := strings.IndexByte(value, '['); open >= 0 {
+		zone := strings.TrimSuffix(value[open+1:], "]")
+		value = value[:open]
+
+		// The zone is an offset in hours, optionally followed by the name of the zone.
+		offsetStr := strings.SplitN(zone, ":", 2)[0]
+		offset, err := strconv.ParseFloat(offsetStr, 64)
+		if err != nil {
+			return 0, invalidErr
+		}
+		location = time.FixedZone(zone, int(offset*3600))
+	}
+
+	// The milliseconds are irrelevant for the ledger.
+	value = strings.SplitN(strings.TrimSpace(value), ".", 2)[0]
+
+	var layout string
+	switch len(value) {
+	case 8:
+		layout = "20060102"
+	case 12:
+		layout = "200601021504"
+	case 14:
+		layout = "20060102150405"
+	default:
+		return 0, invalidErr
+	}
+
+	parsed, err := time.ParseInLocation(layout, value, location)
+	if err != nil {
+		return 0, invalidErr
+	}
+	return parsed.Unix(), nil
+}
@@ -0,0 +1,83 @@
+package statements
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseOFX(t *testing.T) {
+	// SGML based OFX 1.x, with unclosed value elements.
+	sgml := `OFXHEADER:100
+DATA:OFXSGML
+VERSION:102
+ENCODING:USASCII
+
+<OFX>
+<BANKMSGSRSV1><STMTTRNRS><STMTRS>
+<CURDEF>USD
+<BANKTRANLIST>
+<STMTTRN>
+<TRNTYPE>CREDIT
+<DTPOSTED>20220301120000.000[-5:EST]
+<TRNAMT>1500.00
+<FITID>2022030101
+<NAME>ACME Corp
+<MEMO>Salary &amp; bonus
+</STMTTRN>
+<STMTTRN>
+<TRNTYPE>DEBIT
+<DTPOSTED>20220302
+<TRNAMT>-42.10
+<FITID>2022030201
+<NAME>Grocery Store
+</STMTTRN>
+<STMTTRN>
+<TRNTYPE>DEBIT
+<DTPOSTED>2022
+<TRNAMT>-1
+<FITID>2022030301
+</STMTTRN>
+</BANKTRANLIST>
+</STMTRS></STMTTRNRS></BANKMSGSRSV1>
+</OFX>
+`
+
+	// XML based OFX 2.x, with closed value elements.
+	xml := `<?xml version="1.0" encoding="UTF-8"?>
+<?OFX OFXHEADER="200" VERSION="220" SECURITY="NONE"?>
+<OFX><CREDITCARDMSGSRSV1><CCSTMTTRNRS><CCSTMTRS><BANKTRANLIST>
+<STMTTRN><TRNTYPE>DEBIT</TRNTYPE><DTPOSTED>20220302</DTPOSTED><TRNAMT>-42.10</TRNAMT>
+<FITID>2022030201</FITID><PAYEE><NAME>Grocery Store</NAME></PAYEE><MEMO>Grocery Store</MEMO></STMTTRN>
+</BANKTRANLIST></CCSTMTRS></CCSTMTTRNRS></CREDITCARDMSGSRSV1></OFX>`
+
+	entries, errs := ParseOFX(strings.NewReader(sgml))
+	if len(entries) != 2 || len(errs) != 1 || !strings.HasPrefix(errs[0].Error(), "entry 3:") {
+		t.Fatalf("unexpected entries and errors: %+v, %+v", entries, errs)
+	}
+
+	expected := &Entry{
+		Number:     1,
+		Timestamp:  time.Date(2022, time.March, 1, 17, 0, 0, 0, time.UTC).Unix(),
+		Amount:     15000000,
+		ExternalID: "2022030101",
+		Notes:      "ACME Corp - Salary & bonus",
+	}
+	if *entries[0] != *expected {
+		t.Errorf("expected %+v, got: %+v", expected, entries[0])
+	}
+
+	xmlEntries, errs := ParseOFX(strings.NewReader(xml))
+	if len(errs) != 0 || len(xmlEntries) != 1 {
+		t.Fatalf("unexpected entries and errors: %+v, %+v", xmlEntries, errs)
+	}
+	// Both versions of the same transaction should be read alike.
+	xmlEntries[0].Number = entries[1].Number
+	if *xmlEntries[0] != *entries[1] {
+		t.Errorf("expected %+v, got: %+v", entries[1], xmlEntries[0])
+	}
+
+	if _, errs := ParseOFX(strings.NewReader("date,amount\n")); len(errs) != 1 || errs[0] != ErrInvalidOFX {
+		t.Errorf("expected ErrInvalidOFX, got: %+v", errs)
+	}
+}
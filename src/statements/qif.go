@@ -0,0 +1,162 @@
+package statements
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// ErrInvalidQIF is returned for files that are not QIF files.
+var ErrInvalidQIF = errors.New("file should be a QIF file that starts with a !Type header")
+
+// errQIFInvestments is returned for QIF files of investment accounts, whose records are trades, not transactions.
+var errQIFInvestments = errors.New("QIF files of investment accounts are not supported")
+
+// qifTransactionTypes are the QIF account types whose records are transactions.
+var qifTransactionTypes = map[string]bool{"bank": true, "cash": true, "ccard": true, "oth a": true, "oth l": true}
+
+// qifDateLayouts are the layouts that are tried, in order, for the QIF dates if no date layout is provided.
+// Quicken writes the years after 1999 with an apostrophe, like "1/ 2'05", which is read like "1/2/05".
+var qifDateLayouts = []string{"2006-01-02", "1/2/2006", "1/2/06"}
+
+// ParseQIF parses the transactions of the bank, cash and credit card accounts of a QIF file.
+//
+// QIF dates have no fixed format. If dateLayout is empty, the dates are read as US dates, like "03/31/2022",
+// "3/31'22" or "3/31/22", or as ISO dates. Otherwise, dateLayout is the layout of the dates in the format of the Go
+// time package. The split lines of the transactions are not read, so a split transaction is read as a whole.
+func ParseQIF(reader io.Reader, dateLayout string) ([]*Entry, []error) {
+	scanner := bufio.NewScanner(reader)
+
+	var entries []*Entry
+	var errs []error
+
+	// fields holds the values of the record being read.
+	fields := map[string]string{}
+	// readingTransactions tells if the current section holds transactions. The other sections are skipped.
+	var readingTransactions, hasHeader bool
+
+	finishRecord := func() {
+		if readingTransactions && len(fields) > 0 {
+			entry, err := newQIFEntry(len(entries)+len(errs)+1, fields, dateLayout)
+			if err != nil {
+				errs = append(errs, err)
+			} else {
+				entries = append(entries, entry)
+			}
+		}
+		fields = map[string]string{}
+	}
+
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if lineNum == 1 {
+			line = strings.TrimPrefix(line, "\ufeff")
+		}
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		// Headers start the sections of the file. The options, like "!Option:AutoSwitch", are not sections.
+		if line[0] == '!' {
+			header := strings.ToLower(strings.TrimSpace(line[1:]))
+			if strings.HasPrefix(header, "option:") || strings.HasPrefix(header, "clear:") {
+				continue
+			}
+
+			finishRecord()
+			hasHeader = true
+
+			accountType := strings.TrimSpace(strings.TrimPrefix(header, "type:"))
+			readingTransactions = strings.HasPrefix(header, "type:") && qifTransactionTypes[accountType]
+			if accountType == "invst" {
+				return nil, []error{errQIFInvestments}
+			}
+			continue
+		}
+
+		if !hasHeader {
+			return nil, []error{ErrInvalidQIF}
+		}
+
+		// A caret ends the record.
+		if line[0] == '^' {
+			finishRecord()
+			continue
+		}
+
+		// The split lines (S, E and $) are not read. Of the repeated fields, the first one is kept.
+		code := strings.ToUpper(line[:1])
+		if _, exists := fields[code]; !exists {
+			fields[code] = strings.TrimSpace(line[1:])
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, []error{fmt.Errorf("failed to read file: %w", err)}
+	}
+
+	// The caret of the last record is optional for some writers.
+	finishRecord()
+	return entries, errs
+}
+
+// newQIFEntry creates the statement entry out of the fields of a QIF record.
+func newQIFEntry(number int, fields map[string]string, dateLayout string) (*Entry, error) {
+	entry := &Entry{Number: number, Notes: joinNotes(fields["P"], fields["M"])}
+
+	if fields["D"] == "" {
+		return nil, fmt.Errorf("entry %d: %w", number, errMissingDate)
+	}
+	timestamp, err := parseQIFDate(fields["D"], dateLayout)
+	if err != nil {
+		return nil, fmt.Errorf("entry %d: %w", number, err)
+	}
+	entry.Timestamp = timestamp
+
+	// Both T and U hold the amount. U is the newer one, which some writers use alone.
+	amountStr := fields["T"]
+	if amountStr == "" {
+		amountStr = fields["U"]
+	}
+	if amountStr == "" {
+		return nil, fmt.Errorf("entry %d: %w", number, errMissingAmount)
+	}
+	amount, err := parseAmount(amountStr)
+	if err != nil {
+		return nil, fmt.Errorf("entry %d: %w", number, err)
+	}
+	entry.Amount = amount
+
+	// The category may have a subcategory and a class, like "Food:Groceries/Vacation".
+	// Only the top level category is kept. Transfers, like "[Savings]", have no category.
+	category := fields["L"]
+	if !strings.HasPrefix(category, "[") {
+		category = strings.SplitN(strings.SplitN(category, "/", 2)[0], ":", 2)[0]
+		entry.Category = strings.TrimSpace(category)
+	}
+
+	return entry, nil
+}
+
+// parseQIFDate parses a QIF date into epoch seconds. The dates are in UTC.
+func parseQIFDate(value string, layout string) (int64, error) {
+	if layout != "" {
+		parsed, err := time.Parse(layout, value)
+		if err != nil {
+			return 0, fmt.Errorf("invalid date: %q", value)
+		}
+		return parsed.Unix(), nil
+	}
+
+	normalized := strings.ReplaceAll(strings.ReplaceAll(value, " ", ""), "'", "/")
+	for _, qifLayout := range qifDateLayouts {
+		if parsed, err := time.Parse(qifLayout, normalized); err == nil {
+			return parsed.Unix(), nil
+		}
+	}
+
+	return 0, fmt.Errorf("invalid date: %q", value)
+}
@@ -0,0 +1,57 @@
+package statements
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseQIF(t *testing.T) {
+	qif := "!Option:AutoSwitch\n" +
+		"!Account\nNChecking\nTBank\n^\n" +
+		"!Type:Bank\n" +
+		"D3/ 1'22\nT1,500.00\nPACME Corp\nLEarnings\n^\n" +
+		"D03/02/2022\nU-42.10\nPGrocery Store\nMWeekly\nLFood:Groceries/Home\nSFood:Groceries\n$-40.00\nSHousehold\n$-2.10\n^\n" +
+		"D3/3/22\nT-100\nL[Savings]\n^\n" +
+		"D13/13/22\nT-1\n^\n" +
+		"!Type:Cat\nNFood\nE\n^\n"
+
+	entries, errs := ParseQIF(strings.NewReader(qif), "")
+	if len(entries) != 3 || len(errs) != 1 || !strings.HasPrefix(errs[0].Error(), "entry 4:") {
+		t.Fatalf("unexpected entries and errors: %+v, %+v", entries, errs)
+	}
+
+	expected := []Entry{
+		{Number: 1, Timestamp: time.Date(2022, time.March, 1, 0, 0, 0, 0, time.UTC).Unix(), Amount: 15000000,
+			Notes: "ACME Corp", Category: "Earnings"},
+		{Number: 2, Timestamp: time.Date(2022, time.March, 2, 0, 0, 0, 0, time.UTC).Unix(), Amount: -421000,
+			Notes: "Grocery Store - Weekly", Category: "Food"},
+		{Number: 3, Timestamp: time.Date(2022, time.March, 3, 0, 0, 0, 0, time.UTC).Unix(), Amount: -1000000},
+	}
+	for idx, entry := range entries {
+		if *entry != expected[idx] {
+			t.Errorf("expected %+v, got: %+v", expected[idx], entry)
+		}
+	}
+
+	// Day first dates need a date layout.
+	entries, errs = ParseQIF(strings.NewReader("!Type:CCard\nD31.03.2022\nT-5\n^\n"), "02.01.2006")
+	if len(errs) != 0 || len(entries) != 1 || entries[0].Timestamp != time.Date(2022, time.March, 31, 0, 0, 0, 0, time.UTC).Unix() {
+		t.Fatalf("unexpected entries and errors: %+v, %+v", entries, errs)
+	}
+
+	// A "," is the decimal separator if there is no ".", unless the amount may be grouped into thousands.
+	entries, errs = ParseQIF(strings.NewReader("!Type:Bank\nD3/1/22\nT-1234,5\n^\nD3/1/22\nT1,234\n^\n"), "")
+	if len(entries) != 1 || entries[0].Amount != -12345000 || len(errs) != 1 ||
+		!errors.Is(errs[0], errAmbiguousAmount) {
+		t.Fatalf("unexpected entries and errors: %+v, %+v", entries, errs)
+	}
+
+	if _, errs := ParseQIF(strings.NewReader("!Type:Invst\nD3/1/22\n^\n"), ""); len(errs) != 1 {
+		t.Errorf("expected an error for an investment account, got: %+v", errs)
+	}
+	if _, errs := ParseQIF(strings.NewReader("D3/1/22\n^\n"), ""); len(errs) != 1 || errs[0] != ErrInvalidQIF {
+		t.Errorf("expected ErrInvalidQIF, got: %+v", errs)
+	}
+}
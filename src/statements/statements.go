@@ -0,0 +1,80 @@
+// Package statements parses the bank statement file formats, other than CSV, into statement entries.
+//
+// OFX, QFX and QIF are the formats of the consumer banks and desktop finance tools, while ISO 20022 camt.053 and
+// SWIFT MT940 are the formats of the business accounts, which also report the balances of the statements.
+package statements
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/shivanshkc/ledgerkeep/src/models"
+)
+
+// Entry is a transaction of a bank statement.
+type Entry struct {
+	// Number is the position of the entry in the statement, starting from 1. It identifies the entry in the errors.
+	Number int
+	// Timestamp of the entry in epoch seconds.
+	Timestamp int64
+	// Amount of the entry. It is positive for credits and negative for debits.
+	Amount models.Money
+	// ExternalID is the identifier of the entry assigned by the bank, if the format has one.
+	ExternalID string
+	// Notes are the payee and memo of the entry, as far as the format has them.
+	Notes string
+	// Category is the category of the entry, if the format has one. It may not exist in the ledger.
+	Category string
+}
+
+// Balances are the opening and closing balances that a statement reports.
+//
+// A file may hold several consecutive statements of the same account, in which case the opening balance is that of
+// the first statement and the closing balance is that of the last one.
+type Balances struct {
+	// Opening is the balance before the first entry. It is nil if the statement does not report it.
+	Opening *models.Money
+	// Closing is the balance after the last entry. It is nil if the statement does not report it.
+	Closing *models.Money
+}
+
+// errMissingAmount is returned for entries without an amount.
+var errMissingAmount = errors.New("amount is missing")
+
+// errMissingDate is returned for entries without a date.
+var errMissingDate = errors.New("date is missing")
+
+// errAmbiguousAmount is returned for the amounts whose only "," can be either a decimal or a thousands separator.
+var errAmbiguousAmount = errors.New(`amount is ambiguous, like "1,234", which can be 1.234 or 1234`)
+
+// joinNotes joins the non-empty parts of the notes of an entry, skipping the parts that repeat the previous one.
+func joinNotes(parts ...string) string {
+	var notes []string
+	for _, part := range parts {
+		part = strings.TrimSpace(part)
+		if part == "" || (len(notes) > 0 && strings.EqualFold(notes[len(notes)-1], part)) {
+			continue
+		}
+		notes = append(notes, part)
+	}
+	return strings.Join(notes, " - ")
+}
+
+// parseAmount parses an amount with a "." decimal separator, like "-1,234.50".
+// A "," is taken as the decimal separator instead if the amount has no ".", like "-1234,50". The amounts with a
+// single "," followed by three digits, like "1,234", are rejected, as they may have been grouped into thousands.
+func parseAmount(value string) (models.Money, error) {
+	value = strings.ReplaceAll(strings.TrimSpace(value), " ", "")
+	if strings.Contains(value, ".") {
+		value = strings.ReplaceAll(value, ",", "")
+	} else {
+		if comma := strings.IndexByte(value, ','); comma >= 0 && strings.Count(value, ",") == 1 &&
+			len(value)-comma-1 == 3 {
+			return 0, errAmbiguousAmount
+		}
+		value = strings.ReplaceAll(value, ",", ".")
+	}
+
+	// Some writers put the plus sign of credits explicitly.
+	return models.ParseMoney(strings.TrimPrefix(value, "+"))
+}
@@ -0,0 +1,30 @@
+package authutils
+
+import (
+	"errors"
+	"fmt"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// HashPassword provides the bcrypt hash of the password, which is salted, so it is different for every call.
+func HashPassword(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", fmt.Errorf("error in bcrypt.GenerateFromPassword call: %w", err)
+	}
+	return string(hash), nil
+}
+
+// CheckPassword checks if the password matches the bcrypt hash.
+// It returns an error only if the hash is malformed, and not for a wrong password.
+func CheckPassword(hash string, password string) (bool, error) {
+	err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
+	if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("error in bcrypt.CompareHashAndPassword call: %w", err)
+	}
+	return true, nil
+}
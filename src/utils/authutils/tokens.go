@@ -0,0 +1,39 @@
+package authutils
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+const (
+	// tokenPrefix makes the API tokens recognizable, for example by secret scanners.
+	tokenPrefix = "lk_"
+	// tokenSize is the number of random bytes in an API token.
+	tokenSize = 32
+)
+
+// GenerateToken provides a new random API token.
+func GenerateToken() (string, error) {
+	randomBytes := make([]byte, tokenSize)
+	if _, err := rand.Read(randomBytes); err != nil {
+		return "", fmt.Errorf("error in rand.Read call: %w", err)
+	}
+	return tokenPrefix + hex.EncodeToString(randomBytes), nil
+}
+
+// IsToken checks if the value looks like an API token, rather than like any other bearer token.
+func IsToken(value string) bool {
+	return strings.HasPrefix(value, tokenPrefix)
+}
+
+// HashToken provides the hex encoded SHA-256 hash of the API token.
+//
+// Unlike the passwords, the tokens are random and long enough to not need a slow, salted hash. So, the tokens can be
+// looked up by their hashes.
+func HashToken(token string) string {
+	hash := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(hash[:])
+}
@@ -6,6 +6,9 @@ import (
 
 const (
 	requestContextKey contextKey = iota
+	userIDContextKey
+	tenantIDContextKey
+	tokenScopesContextKey
 )
 
 // PutRequestContextData puts the request's context data into the target context and returns the new context.
@@ -21,3 +24,41 @@ func GetRequestContextData(targetCtx context.Context) *RequestContextData {
 	}
 	return data
 }
+
+// PutUserID puts the ID of the authenticated user into the target context and returns the new context.
+func PutUserID(targetCtx context.Context, userID string) context.Context {
+	return context.WithValue(targetCtx, userIDContextKey, userID)
+}
+
+// GetUserID extracts the ID of the authenticated user from the target context.
+// It returns an empty string if the context has no user.
+func GetUserID(targetCtx context.Context) string {
+	userID, _ := targetCtx.Value(userIDContextKey).(string)
+	return userID
+}
+
+// PutTenantID puts the ID of the tenant, whose data the storage operations can access, into the target context and
+// returns the new context.
+func PutTenantID(targetCtx context.Context, tenantID string) context.Context {
+	return context.WithValue(targetCtx, tenantIDContextKey, tenantID)
+}
+
+// GetTenantID extracts the ID of the tenant from the target context.
+// It returns an empty string if the context has no tenant.
+func GetTenantID(targetCtx context.Context) string {
+	tenantID, _ := targetCtx.Value(tenantIDContextKey).(string)
+	return tenantID
+}
+
+// PutTokenScopes puts the scopes of the API token, that the request was authenticated with, into the target context
+// and returns the new context.
+func PutTokenScopes(targetCtx context.Context, scopes []string) context.Context {
+	return context.WithValue(targetCtx, tokenScopesContextKey, scopes)
+}
+
+// GetTokenScopes extracts the scopes of the API token from the target context.
+// It returns nil if the request was not authenticated with an API token.
+func GetTokenScopes(targetCtx context.Context) []string {
+	scopes, _ := targetCtx.Value(tokenScopesContextKey).([]string)
+	return scopes
+}
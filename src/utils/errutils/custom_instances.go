@@ -19,7 +19,89 @@ func AccountIsInUse() *HTTPError {
 	return &HTTPError{StatusCode: http.StatusConflict, CustomCode: "ACCOUNT_IS_IN_USE"}
 }
 
+// CategoryNotFound is for requests that want to access a non-existent category.
+func CategoryNotFound() *HTTPError {
+	return &HTTPError{StatusCode: http.StatusNotFound, CustomCode: "CATEGORY_NOT_FOUND"}
+}
+
+// CategoryAlreadyExists is for requests that want to duplicate an existing category.
+func CategoryAlreadyExists() *HTTPError {
+	return &HTTPError{StatusCode: http.StatusConflict, CustomCode: "CATEGORY_ALREADY_EXISTS"}
+}
+
+// CategoryIsInUse is for requests that want to delete a category that is being used by transactions.
+func CategoryIsInUse() *HTTPError {
+	return &HTTPError{StatusCode: http.StatusConflict, CustomCode: "CATEGORY_IS_IN_USE"}
+}
+
+// BudgetPlanNotFound is for requests that want to access a non-existent budget plan.
+func BudgetPlanNotFound() *HTTPError {
+	return &HTTPError{StatusCode: http.StatusNotFound, CustomCode: "BUDGET_PLAN_NOT_FOUND"}
+}
+
+// RecurringTemplateNotFound is for requests that want to access a non-existent recurring template.
+func RecurringTemplateNotFound() *HTTPError {
+	return &HTTPError{StatusCode: http.StatusNotFound, CustomCode: "RECURRING_TEMPLATE_NOT_FOUND"}
+}
+
+// ImportProfileNotFound is for requests that want to access a non-existent import profile.
+func ImportProfileNotFound() *HTTPError {
+	return &HTTPError{StatusCode: http.StatusNotFound, CustomCode: "IMPORT_PROFILE_NOT_FOUND"}
+}
+
+// RuleNotFound is for requests that want to access a non-existent categorization rule.
+func RuleNotFound() *HTTPError {
+	return &HTTPError{StatusCode: http.StatusNotFound, CustomCode: "RULE_NOT_FOUND"}
+}
+
+// ExchangeRateNotFound is for requests that want to access a non-existent exchange rate,
+// or that need a currency conversion for which no exchange rate is available.
+func ExchangeRateNotFound() *HTTPError {
+	return &HTTPError{StatusCode: http.StatusNotFound, CustomCode: "EXCHANGE_RATE_NOT_FOUND"}
+}
+
 // TransactionNotFound is for requests that want to access a non-existent transaction.
 func TransactionNotFound() *HTTPError {
 	return &HTTPError{StatusCode: http.StatusNotFound, CustomCode: "TRANSACTION_NOT_FOUND"}
 }
+
+// DuplicateTransaction is for requests that want to create a transaction that looks like a duplicate of an existing one.
+func DuplicateTransaction() *HTTPError {
+	return &HTTPError{StatusCode: http.StatusConflict, CustomCode: "DUPLICATE_TRANSACTION"}
+}
+
+// LedgerNotEmpty is for requests that want to restore a backup over the existing data without overwriting it.
+func LedgerNotEmpty() *HTTPError {
+	return &HTTPError{StatusCode: http.StatusConflict, CustomCode: "LEDGER_NOT_EMPTY"}
+}
+
+// TransactionsNotSupported is for requests that need many documents to be written atomically into a database that
+// cannot do so, like a standalone MongoDB server.
+func TransactionsNotSupported() *HTTPError {
+	return &HTTPError{StatusCode: http.StatusNotImplemented, CustomCode: "TRANSACTIONS_NOT_SUPPORTED"}
+}
+
+// UserNotFound is for requests that want to access a non-existent user.
+func UserNotFound() *HTTPError {
+	return &HTTPError{StatusCode: http.StatusNotFound, CustomCode: "USER_NOT_FOUND"}
+}
+
+// UserAlreadyExists is for requests that want to duplicate an existing user.
+func UserAlreadyExists() *HTTPError {
+	return &HTTPError{StatusCode: http.StatusConflict, CustomCode: "USER_ALREADY_EXISTS"}
+}
+
+// MemberNotFound is for requests that want to access a non-existent ledger membership.
+func MemberNotFound() *HTTPError {
+	return &HTTPError{StatusCode: http.StatusNotFound, CustomCode: "MEMBER_NOT_FOUND"}
+}
+
+// MemberAlreadyExists is for requests that want to share a ledger with one of its members again.
+func MemberAlreadyExists() *HTTPError {
+	return &HTTPError{StatusCode: http.StatusConflict, CustomCode: "MEMBER_ALREADY_EXISTS"}
+}
+
+// TokenNotFound is for requests that want to access a non-existent API token.
+func TokenNotFound() *HTTPError {
+	return &HTTPError{StatusCode: http.StatusNotFound, CustomCode: "TOKEN_NOT_FOUND"}
+}
@@ -44,6 +44,11 @@ func PreconditionFailed() *HTTPError {
 	return &HTTPError{StatusCode: http.StatusPreconditionFailed, CustomCode: "PRECONDITION_FAILED"}
 }
 
+// TooManyRequests is for requests that exceed the rate limits, or that come while their client is locked out.
+func TooManyRequests() *HTTPError {
+	return &HTTPError{StatusCode: http.StatusTooManyRequests, CustomCode: "TOO_MANY_REQUESTS"}
+}
+
 // InternalServerError is for requests that cause an unexpected misbehaviour.
 func InternalServerError() *HTTPError {
 	return &HTTPError{StatusCode: http.StatusInternalServerError, CustomCode: "INTERNAL_SERVER_ERROR"}
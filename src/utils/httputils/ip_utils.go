@@ -73,3 +73,56 @@ func GetClientIP(req *http.Request) (string, error) {
 
 	return "", fmt.Errorf("no method worked")
 }
+
+// GetTrustedClientIP extracts the client IP Address from the given HTTP request, like GetClientIP, but it only
+// believes the x-real-ip and x-forwarded-for headers if the request comes from one of the trusted proxies. Otherwise,
+// any client could pick its address by setting them.
+//
+// The proxies append the address that they got the request from to x-forwarded-for, so its addresses are read from
+// the right, and the first one that is not of a trusted proxy is the client.
+func GetTrustedClientIP(req *http.Request, trustedProxies []*net.IPNet) (string, error) {
+	ip, _, err := net.SplitHostPort(req.RemoteAddr)
+	if err != nil {
+		return "", fmt.Errorf("error in net.SplitHostPort call: %w", err)
+	}
+
+	clientIP := net.ParseIP(ip)
+	if clientIP == nil {
+		return "", fmt.Errorf("invalid remote address: %s", req.RemoteAddr)
+	}
+	if !isTrustedProxy(clientIP, trustedProxies) {
+		return clientIP.String(), nil
+	}
+
+	// Using x-real-ip header, unless it is the address of another trusted proxy.
+	if parsedIP := net.ParseIP(strings.TrimSpace(req.Header.Get("x-real-ip"))); parsedIP != nil &&
+		!isTrustedProxy(parsedIP, trustedProxies) {
+		return parsedIP.String(), nil
+	}
+
+	// Using x-forwarded-for headers, of which there may be many.
+	ipArr := strings.Split(strings.Join(req.Header.Values("x-forwarded-for"), ","), ",")
+	for idx := len(ipArr) - 1; idx >= 0; idx-- {
+		parsedIP := net.ParseIP(strings.TrimSpace(ipArr[idx]))
+		// The addresses before an invalid one cannot be told apart from the ones that the client made up.
+		if parsedIP == nil {
+			break
+		}
+		clientIP = parsedIP
+		if !isTrustedProxy(parsedIP, trustedProxies) {
+			break
+		}
+	}
+
+	return clientIP.String(), nil
+}
+
+// isTrustedProxy checks if the IP address is of one of the trusted proxies.
+func isTrustedProxy(ip net.IP, trustedProxies []*net.IPNet) bool {
+	for _, proxy := range trustedProxies {
+		if proxy.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,61 @@
+package testutils
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+const (
+	// Username is the basic auth username in the DefaultConfigs.
+	Username = "test-user"
+	// Password is the basic auth password in the DefaultConfigs.
+	Password = "test-pass"
+)
+
+// DefaultConfigs are the configs that suit most tests. They use the in-memory storage.
+const DefaultConfigs = `
+application:
+  name: ledgerkeep
+  version: test
+auth:
+  username: ` + Username + `
+  password: ` + Password + `
+http_server:
+  addr: 127.0.0.1:0
+logger:
+  level: fatal
+currency:
+  default: INR
+duplicates:
+  window_sec: 259200
+  min_notes_similarity: 0.6
+storage:
+  driver: memory
+`
+
+// UseConfigs makes the provided configs available to the configs package, which reads them from the working
+// directory. So, it changes the working directory to a new temporary one that contains the configs file.
+//
+// It must be called before the first configs.Get call, which is usually from the TestMain function.
+// The returned function removes the temporary directory.
+func UseConfigs(contents string) (func(), error) {
+	dir, err := os.MkdirTemp("", "ledgerkeep-test-*")
+	if err != nil {
+		return nil, fmt.Errorf("error in os.MkdirTemp call: %w", err)
+	}
+
+	cleanup := func() { _ = os.RemoveAll(dir) }
+
+	if err := os.WriteFile(filepath.Join(dir, "configs.yaml"), []byte(contents), 0o600); err != nil {
+		cleanup()
+		return nil, fmt.Errorf("error in os.WriteFile call: %w", err)
+	}
+
+	if err := os.Chdir(dir); err != nil {
+		cleanup()
+		return nil, fmt.Errorf("error in os.Chdir call: %w", err)
+	}
+
+	return cleanup, nil
+}
@@ -0,0 +1,127 @@
+package testutils
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+)
+
+// oidcKeySize is the size in bits of the RSA keys of the OIDCIssuer. It is small to keep the tests fast.
+const oidcKeySize = 1024
+
+// OIDCIssuer is a local stand-in for an OpenID Connect issuer. It serves the discovery document and the JSON Web Key
+// Set, and signs the access tokens with RS256.
+type OIDCIssuer struct {
+	server *httptest.Server
+
+	// mutex guards the fields below it.
+	mutex *sync.Mutex
+	key   *rsa.PrivateKey
+	keyID string
+	// keyCount is the number of keys that the issuer has had, which makes the key IDs unique.
+	keyCount int
+	// jwksRequests is the number of times that the key set was fetched.
+	jwksRequests int
+}
+
+// NewOIDCIssuer starts a new OIDCIssuer. It must be closed after use.
+func NewOIDCIssuer() (*OIDCIssuer, error) {
+	issuer := &OIDCIssuer{mutex: &sync.Mutex{}}
+	if err := issuer.RotateKey(); err != nil {
+		return nil, err
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(writer http.ResponseWriter, request *http.Request) {
+		writeJSON(writer, map[string]interface{}{"issuer": issuer.URL(), "jwks_uri": issuer.URL() + "/jwks"})
+	})
+	mux.HandleFunc("/jwks", func(writer http.ResponseWriter, request *http.Request) {
+		issuer.mutex.Lock()
+		defer issuer.mutex.Unlock()
+
+		issuer.jwksRequests++
+		publicKey := issuer.key.PublicKey
+		writeJSON(writer, map[string]interface{}{"keys": []map[string]string{{
+			"kty": "RSA",
+			"kid": issuer.keyID,
+			"use": "sig",
+			"alg": "RS256",
+			"n":   base64.RawURLEncoding.EncodeToString(publicKey.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(publicKey.E)).Bytes()),
+		}}})
+	})
+
+	issuer.server = httptest.NewServer(mux)
+	return issuer, nil
+}
+
+// URL provides the URL of the issuer, which is also the "iss" claim of its tokens.
+func (o *OIDCIssuer) URL() string {
+	return o.server.URL
+}
+
+// Close stops the issuer.
+func (o *OIDCIssuer) Close() {
+	o.server.Close()
+}
+
+// RotateKey replaces the signing key of the issuer with a new one, which has a new key ID.
+func (o *OIDCIssuer) RotateKey() error {
+	key, err := rsa.GenerateKey(rand.Reader, oidcKeySize)
+	if err != nil {
+		return fmt.Errorf("error in rsa.GenerateKey call: %w", err)
+	}
+
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
+	o.keyCount++
+	o.key, o.keyID = key, fmt.Sprintf("key-%d", o.keyCount)
+	return nil
+}
+
+// JWKSRequests provides the number of times that the key set of the issuer was fetched.
+func (o *OIDCIssuer) JWKSRequests() int {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+	return o.jwksRequests
+}
+
+// SignToken provides a token with the provided claims, signed by the current key of the issuer.
+func (o *OIDCIssuer) SignToken(claims map[string]interface{}) (string, error) {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
+	header, err := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT", "kid": o.keyID})
+	if err != nil {
+		return "", fmt.Errorf("error in json.Marshal call: %w", err)
+	}
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", fmt.Errorf("error in json.Marshal call: %w", err)
+	}
+
+	signingInput := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	digest := sha256.Sum256([]byte(signingInput))
+
+	signature, err := rsa.SignPKCS1v15(rand.Reader, o.key, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", fmt.Errorf("error in rsa.SignPKCS1v15 call: %w", err)
+	}
+
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+// writeJSON writes the value as the JSON body of the response.
+func writeJSON(writer http.ResponseWriter, value interface{}) {
+	writer.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(writer).Encode(value)
+}